@@ -6,19 +6,35 @@ import (
 	"github.com/gin-gonic/gin"
 
 	interfaces "example.com/interfaces/controllers"
+	middlewareInterfaces "example.com/interfaces/middlewares"
+	serviceInterfaces "example.com/interfaces/services"
+	"example.com/lib"
 	"example.com/routes"
 )
 
 type App struct {
-	server       *gin.Engine
-	httpHandlers *HTTPHandlers
+	server             *gin.Engine
+	httpHandlers       *HTTPHandlers
+	authenticator      middlewareInterfaces.IAuthenticator
+	verifiedEmailGuard middlewareInterfaces.IVerifiedEmailGuard
+	auditTrail         middlewareInterfaces.IAuditTrail
+	migrationService   serviceInterfaces.IMigrationService
+	//nil unless the in-process oidc provider is enabled
+	mockOidcProvider *lib.MockOidcProvider
 }
 
 func (app App) Start(port string) error {
 
+	//the schema is brought up to date before serving, "migrate status" tells what is pending beforehand
+	_, err := app.migrationService.MigrateUp()
+
+	if err != nil {
+		return err
+	}
+
 	app.InitializeRoutes(*app.httpHandlers)
 
-	err := app.server.Run(fmt.Sprintf(":%v", port))
+	err = app.server.Run(fmt.Sprintf(":%v", port))
 
 	if err != nil {
 		return err
@@ -28,28 +44,104 @@ func (app App) Start(port string) error {
 }
 
 func (app App) InitializeRoutes(httpHandlers HTTPHandlers) {
-	routes.RegisterEventRoutes(app.server, app.httpHandlers.eventsController)
-	routes.RegisterUserRoutes(app.server, app.httpHandlers.usersController)
-	routes.RegisterRegistrationRoutes(app.server, app.httpHandlers.registrationsController)
+	routes.RegisterEventRoutes(app.server, app.httpHandlers.eventsController, app.authenticator, app.verifiedEmailGuard)
+	routes.RegisterUserRoutes(app.server, app.httpHandlers.usersController, app.authenticator)
+	routes.RegisterRegistrationRoutes(app.server, app.httpHandlers.registrationsController, app.authenticator)
+	routes.RegisterEventStreamRoutes(app.server, app.httpHandlers.eventStreamController, app.authenticator)
+	routes.RegisterEventChannelRoutes(app.server, app.httpHandlers.eventChannelController, app.authenticator)
+	routes.RegisterInvitationRoutes(app.server, app.httpHandlers.invitationsController, app.authenticator)
+	routes.RegisterCommentRoutes(app.server, app.httpHandlers.commentsController, app.authenticator)
+	routes.RegisterEmailVerificationRoutes(app.server, app.httpHandlers.emailVerificationController, app.authenticator)
+	routes.RegisterPasswordResetRoutes(app.server, app.httpHandlers.passwordResetController)
+	routes.RegisterTwoFactorRoutes(app.server, app.httpHandlers.twoFactorController, app.authenticator)
+	routes.RegisterPersonalAccessTokenRoutes(app.server, app.httpHandlers.personalAccessTokensController, app.authenticator)
+	routes.RegisterSessionRoutes(app.server, app.httpHandlers.sessionsController, app.authenticator)
+	routes.RegisterOrganizationRoutes(app.server, app.httpHandlers.organizationsController, app.authenticator)
+	routes.RegisterDataExportRoutes(app.server, app.httpHandlers.dataExportsController, app.authenticator)
+	routes.RegisterJsonWebKeyRoutes(app.server, app.httpHandlers.jsonWebKeysController)
+	routes.RegisterOidcRoutes(app.server, app.httpHandlers.oidcController)
+	routes.RegisterMockOidcProviderRoutes(app.server, app.mockOidcProvider)
+	routes.RegisterAdminRoutes(app.server, app.httpHandlers.adminController, app.httpHandlers.eventsController, app.authenticator, app.auditTrail)
+	routes.RegisterAuditLogRoutes(app.server, app.httpHandlers.auditLogController, app.authenticator, app.auditTrail)
 }
 
-func NewApp(httpServer *gin.Engine, httpHandlers *HTTPHandlers) *App {
+func NewApp(
+	httpServer *gin.Engine,
+	httpHandlers *HTTPHandlers,
+	authenticator middlewareInterfaces.IAuthenticator,
+	verifiedEmailGuard middlewareInterfaces.IVerifiedEmailGuard,
+	auditTrail middlewareInterfaces.IAuditTrail,
+	migrationService serviceInterfaces.IMigrationService,
+	mockOidcProvider *lib.MockOidcProvider) *App {
 	return &App{
-		server:       httpServer,
-		httpHandlers: httpHandlers,
+		server:             httpServer,
+		httpHandlers:       httpHandlers,
+		authenticator:      authenticator,
+		verifiedEmailGuard: verifiedEmailGuard,
+		auditTrail:         auditTrail,
+		migrationService:   migrationService,
+		mockOidcProvider:   mockOidcProvider,
 	}
 }
 
 type HTTPHandlers struct {
-	eventsController        interfaces.IEventsController
-	usersController         interfaces.IUsersController
-	registrationsController interfaces.IRegistrationsController
+	eventsController               interfaces.IEventsController
+	usersController                interfaces.IUsersController
+	registrationsController        interfaces.IRegistrationsController
+	eventStreamController          interfaces.IEventStreamController
+	eventChannelController         interfaces.IEventChannelController
+	invitationsController          interfaces.IInvitationsController
+	commentsController             interfaces.ICommentsController
+	adminController                interfaces.IAdminController
+	passwordResetController        interfaces.IPasswordResetController
+	emailVerificationController    interfaces.IEmailVerificationController
+	twoFactorController            interfaces.ITwoFactorController
+	oidcController                 interfaces.IOidcController
+	personalAccessTokensController interfaces.IPersonalAccessTokensController
+	dataExportsController          interfaces.IDataExportsController
+	jsonWebKeysController          interfaces.IJsonWebKeysController
+	sessionsController             interfaces.ISessionsController
+	organizationsController        interfaces.IOrganizationsController
+	auditLogController             interfaces.IAuditLogController
 }
 
-func NewHTTPHandlers(eventsController interfaces.IEventsController, usersController interfaces.IUsersController, registrationsConroller interfaces.IRegistrationsController) *HTTPHandlers {
+func NewHTTPHandlers(
+	eventsController interfaces.IEventsController,
+	usersController interfaces.IUsersController,
+	registrationsConroller interfaces.IRegistrationsController,
+	eventStreamController interfaces.IEventStreamController,
+	eventChannelController interfaces.IEventChannelController,
+	invitationsController interfaces.IInvitationsController,
+	commentsController interfaces.ICommentsController,
+	adminController interfaces.IAdminController,
+	passwordResetController interfaces.IPasswordResetController,
+	emailVerificationController interfaces.IEmailVerificationController,
+	twoFactorController interfaces.ITwoFactorController,
+	oidcController interfaces.IOidcController,
+	personalAccessTokensController interfaces.IPersonalAccessTokensController,
+	dataExportsController interfaces.IDataExportsController,
+	jsonWebKeysController interfaces.IJsonWebKeysController,
+	sessionsController interfaces.ISessionsController,
+	organizationsController interfaces.IOrganizationsController,
+	auditLogController interfaces.IAuditLogController) *HTTPHandlers {
 	return &HTTPHandlers{
-		eventsController:        eventsController,
-		usersController:         usersController,
-		registrationsController: registrationsConroller,
+		eventsController:               eventsController,
+		usersController:                usersController,
+		registrationsController:        registrationsConroller,
+		eventStreamController:          eventStreamController,
+		eventChannelController:         eventChannelController,
+		invitationsController:          invitationsController,
+		commentsController:             commentsController,
+		adminController:                adminController,
+		passwordResetController:        passwordResetController,
+		emailVerificationController:    emailVerificationController,
+		twoFactorController:            twoFactorController,
+		oidcController:                 oidcController,
+		personalAccessTokensController: personalAccessTokensController,
+		dataExportsController:          dataExportsController,
+		jsonWebKeysController:          jsonWebKeysController,
+		sessionsController:             sessionsController,
+		organizationsController:        organizationsController,
+		auditLogController:             auditLogController,
 	}
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	serviceInterfaces "example.com/interfaces/services"
+)
+
+const commandsUsage = `usage:
+	assign-role <email> <role>	assigns the role to the user, e.g. to bootstrap the first administrator
+	migrate up			applies every pending migration
+	migrate down [steps]		reverts the latest applied migrations, 1 by default
+	migrate status			lists the migrations and whether they were applied`
+
+// Administrative commands run from the command line instead of starting the server
+type Commands struct {
+	userService      serviceInterfaces.IUserService
+	migrationService serviceInterfaces.IMigrationService
+}
+
+func (commands Commands) Run(args []string) error {
+	switch args[0] {
+	case "assign-role":
+		if len(args) != 3 {
+			return errors.New(commandsUsage)
+		}
+
+		err := commands.userService.AssignRole(args[1], args[2])
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Assigned the %v role to %v\n", args[2], args[1])
+
+		return nil
+	case "migrate":
+		return commands.migrate(args[1:])
+	default:
+		return fmt.Errorf("unknown command %v\n%v", args[0], commandsUsage)
+	}
+}
+
+func (commands Commands) migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(commandsUsage)
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		migrations, err := commands.migrationService.MigrateUp()
+
+		for _, migration := range migrations {
+			fmt.Printf("Applied %04d_%v\n", migration.Version, migration.Name)
+		}
+
+		if err == nil && len(migrations) == 0 {
+			fmt.Println("The database is up to date")
+		}
+
+		return err
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+
+		if len(args) == 2 {
+			var err error
+
+			steps, err = strconv.Atoi(args[1])
+
+			if err != nil {
+				return fmt.Errorf("invalid number of steps %v\n%v", args[1], commandsUsage)
+			}
+		}
+
+		migrations, err := commands.migrationService.MigrateDown(steps)
+
+		for _, migration := range migrations {
+			fmt.Printf("Reverted %04d_%v\n", migration.Version, migration.Name)
+		}
+
+		if err == nil && len(migrations) == 0 {
+			fmt.Println("No migration to revert")
+		}
+
+		return err
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := commands.migrationService.GetStatus()
+
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(writer, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+
+		for _, status := range statuses {
+			appliedAt := "-"
+
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Fprintf(writer, "%04d\t%v\t%v\t%v\n", status.Version, status.Name, status.State, appliedAt)
+		}
+
+		return writer.Flush()
+	default:
+		return errors.New(commandsUsage)
+	}
+}
+
+func NewCommands(userService serviceInterfaces.IUserService, migrationService serviceInterfaces.IMigrationService) *Commands {
+	return &Commands{
+		userService:      userService,
+		migrationService: migrationService,
+	}
+}
@@ -2,14 +2,77 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+const (
+	// Deleted accounts are scrubbed of personal data, their events and registrations are kept
+	ANONYMIZE_ACCOUNT_DELETION = "anonymize"
+	// Deleted accounts are removed along with their events and registrations
+	CASCADE_ACCOUNT_DELETION = "cascade"
+)
+
+const (
+	ARGON2ID_PASSWORD_HASHING = "argon2id"
+	BCRYPT_PASSWORD_HASHING   = "bcrypt"
+)
+
+const (
+	SQLITE_DATABASE_DRIVER = "sqlite"
+)
+
 type Configuration struct {
-	httpPort     string
-	jwtSecretKey string
+	httpPort                 string
+	jwtSecretKey             string
+	mailOutputFile           string
+	publicUrl                string
+	requireEmailVerification bool
+	totpIssuer               string
+	totpEncryptionKey        string
+	oidcProviders            []OidcProviderConfiguration
+	mockOidcProvider         bool
+	accountDeletionPolicy    string
+	jwtSigningKeyFile        string
+	jwtVerificationKeyFiles  []string
+	jwtIssuer                string
+	jwtAudience              string
+	passwordHashing          PasswordHashingConfiguration
+	database                 DatabaseConfiguration
+}
+
+// Database the api stores its data in
+type DatabaseConfiguration struct {
+	Driver string
+	// File of the SQLite database, query parameters are passed on to the driver
+	Url string
+}
+
+// Algorithm new password hashes are created with and its parameters. Hashes created with other
+// settings keep working and get upgraded when their user logs in
+type PasswordHashingConfiguration struct {
+	Algorithm  string
+	BcryptCost int
+	// Memory used by argon2id in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// Client registration of an OpenID Connect provider users can log in with
+type OidcProviderConfiguration struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
 }
 
 var config Configuration
@@ -22,9 +85,58 @@ func LoadConfiguration() error {
 		return err
 	}
 
+	//verification is required unless explicitly turned off
+	requireEmailVerification, err := strconv.ParseBool(os.Getenv("REQUIRE_EMAIL_VERIFICATION"))
+
+	if err != nil {
+		requireEmailVerification = true
+	}
+
+	mockOidcProvider, _ := strconv.ParseBool(os.Getenv("OIDC_MOCK_PROVIDER"))
+
 	config = Configuration{
-		httpPort:     os.Getenv("HTTP_PORT"),
-		jwtSecretKey: os.Getenv("TOKEN_SECRET"),
+		httpPort:                 os.Getenv("HTTP_PORT"),
+		jwtSecretKey:             os.Getenv("TOKEN_SECRET"),
+		mailOutputFile:           os.Getenv("MAIL_OUTPUT_FILE"),
+		publicUrl:                os.Getenv("PUBLIC_URL"),
+		requireEmailVerification: requireEmailVerification,
+		totpIssuer:               os.Getenv("TOTP_ISSUER"),
+		totpEncryptionKey:        os.Getenv("TOTP_ENCRYPTION_KEY"),
+		mockOidcProvider:         mockOidcProvider,
+		jwtSigningKeyFile:        os.Getenv("JWT_SIGNING_KEY_FILE"),
+		jwtVerificationKeyFiles:  splitList(os.Getenv("JWT_VERIFICATION_KEY_FILES")),
+		jwtIssuer:                os.Getenv("JWT_ISSUER"),
+		jwtAudience:              os.Getenv("JWT_AUDIENCE"),
+	}
+
+	config.oidcProviders, err = loadOidcProviders(os.Getenv("OIDC_PROVIDERS"))
+
+	if err != nil {
+		return err
+	}
+
+	err = checkMockOidcProvider(mockOidcProvider, config.publicUrl)
+
+	if err != nil {
+		return err
+	}
+
+	config.accountDeletionPolicy, err = loadAccountDeletionPolicy(os.Getenv("ACCOUNT_DELETION_POLICY"))
+
+	if err != nil {
+		return err
+	}
+
+	config.passwordHashing, err = loadPasswordHashing()
+
+	if err != nil {
+		return err
+	}
+
+	config.database, err = loadDatabase(os.Getenv("DATABASE_DRIVER"), os.Getenv("DATABASE_URL"))
+
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -46,6 +158,262 @@ func (config Configuration) JwtSecretKey() (string, error) {
 	return config.jwtSecretKey, nil
 }
 
+// File development emails are appended to, optional
+func (config Configuration) MailOutputFile() string {
+	return config.mailOutputFile
+}
+
+// Base url of the api used in links sent by email, links are relative when not configured
+func (config Configuration) PublicUrl() string {
+	return config.publicUrl
+}
+
+// Whether users have to verify their email before creating events
+func (config Configuration) RequireEmailVerification() bool {
+	return config.requireEmailVerification
+}
+
+// Name authenticator apps display next to the account
+func (config Configuration) TotpIssuer() string {
+	if config.totpIssuer == "" {
+		return "Events API"
+	}
+
+	return config.totpIssuer
+}
+
+// Key the TOTP secrets of users are encrypted with, TOKEN_SECRET when not configured. Changing it
+// makes the stored secrets unreadable, users then log in with a recovery code and enroll again
+func (config Configuration) TotpEncryptionKey() (string, error) {
+	if config.totpEncryptionKey != "" {
+		return config.totpEncryptionKey, nil
+	}
+
+	return config.JwtSecretKey()
+}
+
+// OpenID Connect providers users can log in with, none when not configured
+func (config Configuration) OidcProviders() []OidcProviderConfiguration {
+	return config.oidcProviders
+}
+
+// Whether the in-process OpenID Connect provider meant for development and tests is served, it is
+// refused in release mode and unless the public url is a loopback address
+func (config Configuration) MockOidcProvider() bool {
+	return config.mockOidcProvider
+}
+
+// What happens to the events and registrations of users deleting their account
+func (config Configuration) AccountDeletionPolicy() string {
+	if config.accountDeletionPolicy == "" {
+		return ANONYMIZE_ACCOUNT_DELETION
+	}
+
+	return config.accountDeletionPolicy
+}
+
+// PEM file of the private key access tokens are signed with, RSA, ECDSA and Ed25519 keys are
+// supported. Tokens are signed with TOKEN_SECRET when not configured. Once it is configured, access
+// tokens signed with TOKEN_SECRET are refused right away, clients get new ones with their refresh
+// tokens which do not depend on the signing key
+func (config Configuration) JwtSigningKeyFile() string {
+	return config.jwtSigningKeyFile
+}
+
+// PEM files of the other keys access tokens are accepted from, listed in JWT_VERIFICATION_KEY_FILES
+// separated by commas. Keys are published before they sign tokens and kept after they stop, so
+// the signing key can be rotated without rejecting tokens issued with the previous one
+func (config Configuration) JwtVerificationKeyFiles() []string {
+	return config.jwtVerificationKeyFiles
+}
+
+// Issuer of access tokens, tokens from other issuers are refused
+func (config Configuration) JwtIssuer() string {
+	if config.jwtIssuer == "" {
+		return "events-api"
+	}
+
+	return config.jwtIssuer
+}
+
+// Audience of access tokens, tokens meant for other audiences are refused
+func (config Configuration) JwtAudience() string {
+	if config.jwtAudience == "" {
+		return "events-api"
+	}
+
+	return config.jwtAudience
+}
+
+// Argon2id with the parameters OWASP recommends unless configured otherwise
+func (config Configuration) PasswordHashing() PasswordHashingConfiguration {
+	if config.passwordHashing.Algorithm == "" {
+		return defaultPasswordHashing()
+	}
+
+	return config.passwordHashing
+}
+
+// The SQLite database in api.db unless configured otherwise
+func (config Configuration) Database() DatabaseConfiguration {
+	if config.database.Driver == "" {
+		return DatabaseConfiguration{Driver: SQLITE_DATABASE_DRIVER, Url: "api.db"}
+	}
+
+	return config.database
+}
+
+// Providers are listed by name in OIDC_PROVIDERS, separated by commas. The settings of each
+// provider are read from variables prefixed with its name, e.g. OIDC_COMPANY_ISSUER
+func loadOidcProviders(names string) ([]OidcProviderConfiguration, error) {
+	providers := []OidcProviderConfiguration{}
+
+	for _, name := range splitList(names) {
+		name = strings.ToLower(name)
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OidcProviderConfiguration{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientId:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectUrl:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+
+		if provider.Issuer == "" || provider.ClientId == "" {
+			return nil, fmt.Errorf("missing issuer or client id configuration for the %v oidc provider", name)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+// The mock provider logs anyone in as whatever email they ask for, so it is refused unless the api
+// runs in development on the local machine
+func checkMockOidcProvider(enabled bool, publicUrl string) error {
+	if !enabled {
+		return nil
+	}
+
+	if gin.Mode() == gin.ReleaseMode || strings.EqualFold(os.Getenv(gin.EnvGinMode), gin.ReleaseMode) {
+		return errors.New("the mock oidc provider cannot be enabled in release mode")
+	}
+
+	if publicUrl == "" {
+		return nil
+	}
+
+	parsedUrl, err := url.Parse(publicUrl)
+
+	if err != nil {
+		return fmt.Errorf("invalid public url configuration: %v", err)
+	}
+
+	host := parsedUrl.Hostname()
+
+	if ip := net.ParseIP(host); !strings.EqualFold(host, "localhost") && (ip == nil || !ip.IsLoopback()) {
+		return errors.New("the mock oidc provider can only be enabled when the public url is a loopback address")
+	}
+
+	return nil
+}
+
+// Splits a comma separated list, leaving out blank entries
+func splitList(list string) []string {
+	values := []string{}
+
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func loadAccountDeletionPolicy(policy string) (string, error) {
+	switch policy = strings.ToLower(strings.TrimSpace(policy)); policy {
+	case "", ANONYMIZE_ACCOUNT_DELETION, CASCADE_ACCOUNT_DELETION:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown account deletion policy %v, expected %v or %v", policy, ANONYMIZE_ACCOUNT_DELETION, CASCADE_ACCOUNT_DELETION)
+	}
+}
+
+// SQLite is the only driver so far, the database file defaults to api.db
+func loadDatabase(driver, url string) (DatabaseConfiguration, error) {
+	switch driver = strings.ToLower(strings.TrimSpace(driver)); driver {
+	case "", SQLITE_DATABASE_DRIVER:
+		if url == "" {
+			url = "api.db"
+		}
+
+		return DatabaseConfiguration{Driver: SQLITE_DATABASE_DRIVER, Url: url}, nil
+	default:
+		return DatabaseConfiguration{}, fmt.Errorf("unknown database driver %v, expected %v", driver, SQLITE_DATABASE_DRIVER)
+	}
+}
+
+func defaultPasswordHashing() PasswordHashingConfiguration {
+	return PasswordHashingConfiguration{
+		Algorithm:         ARGON2ID_PASSWORD_HASHING,
+		BcryptCost:        12,
+		Argon2Memory:      19 * 1024,
+		Argon2Iterations:  2,
+		Argon2Parallelism: 1,
+	}
+}
+
+// Reads PASSWORD_HASH_ALGORITHM along with BCRYPT_COST, ARGON2_MEMORY_KIB, ARGON2_ITERATIONS and
+// ARGON2_PARALLELISM, unset values keep their default
+func loadPasswordHashing() (PasswordHashingConfiguration, error) {
+	hashing := defaultPasswordHashing()
+
+	algorithm := strings.ToLower(strings.TrimSpace(os.Getenv("PASSWORD_HASH_ALGORITHM")))
+
+	switch algorithm {
+	case "":
+	case ARGON2ID_PASSWORD_HASHING, BCRYPT_PASSWORD_HASHING:
+		hashing.Algorithm = algorithm
+	default:
+		return hashing, fmt.Errorf("unknown password hash algorithm %v, expected %v or %v", algorithm, ARGON2ID_PASSWORD_HASHING, BCRYPT_PASSWORD_HASHING)
+	}
+
+	parameters := []struct {
+		variable string
+		min, max uint64
+		set      func(value uint64)
+	}{
+		{"BCRYPT_COST", 4, 31, func(value uint64) { hashing.BcryptCost = int(value) }},
+		{"ARGON2_MEMORY_KIB", 8 * 1024, 4 * 1024 * 1024, func(value uint64) { hashing.Argon2Memory = uint32(value) }},
+		{"ARGON2_ITERATIONS", 1, 100, func(value uint64) { hashing.Argon2Iterations = uint32(value) }},
+		{"ARGON2_PARALLELISM", 1, 255, func(value uint64) { hashing.Argon2Parallelism = uint8(value) }},
+	}
+
+	for _, parameter := range parameters {
+		rawValue := strings.TrimSpace(os.Getenv(parameter.variable))
+
+		if rawValue == "" {
+			continue
+		}
+
+		value, err := strconv.ParseUint(rawValue, 10, 64)
+
+		if err != nil || value < parameter.min || value > parameter.max {
+			return hashing, fmt.Errorf("invalid %v configuration, expected a number between %v and %v", parameter.variable, parameter.min, parameter.max)
+		}
+
+		parameter.set(value)
+	}
+
+	return hashing, nil
+}
+
 func AppConfiguration() Configuration {
 	return config
 }
@@ -2,14 +2,21 @@ package config
 
 import (
 	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
+//go:embed migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
 func InitializeDatabase() *sql.DB {
-	var err error
-	var database *sql.DB
-	database, err = sql.Open("sqlite", "api.db")
+	databaseConfiguration := AppConfiguration().Database()
+
+	database, err := OpenDatabase(databaseConfiguration.Driver, databaseConfiguration.Url)
 
 	if err != nil {
 		panic("Unable to connect to the database")
@@ -18,11 +25,28 @@ func InitializeDatabase() *sql.DB {
 	database.SetMaxOpenConns(10)
 	database.SetMaxIdleConns(5)
 
-	setupTables(database)
-
 	return database
 }
 
+// Opens the database with the sql driver registered for the configured driver
+func OpenDatabase(driver, url string) (*sql.DB, error) {
+	switch driver {
+	case SQLITE_DATABASE_DRIVER:
+		separator := "?"
+
+		if strings.Contains(url, "?") {
+			separator = "&"
+		}
+
+		//pragmas only apply to the connection running them, the driver runs these on every new connection.
+		//SQLite ignores foreign keys unless told otherwise, and fails right away on a locked database
+		//instead of waiting for the other writer
+		return sql.Open("sqlite", url+separator+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	default:
+		return nil, fmt.Errorf("unknown database driver %v", driver)
+	}
+}
+
 func CloseDatabaseConnection(database *sql.DB) {
 	err := database.Close()
 
@@ -31,50 +55,20 @@ func CloseDatabaseConnection(database *sql.DB) {
 	}
 }
 
-func setupTables(database *sql.DB) {
-
-	createUserTableSql := `
-	CREATE TABLE IF NOT EXISTS Users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		email TEXT NOT NULL UNIQUE,
-		password TEXT NOT NULL
-	)`
-
-	_, err := database.Exec(createUserTableSql)
-
-	if err != nil {
-		panic("Unable to create user table")
-	}
-
-	createEventsTableSql := `
-	CREATE TABLE IF NOT EXISTS Events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		description TEXT NOT NULL,
-		location TEXT NOT NULL,
-		date DATETIME NOT NULL,
-		user_id INTEGER,
-		FOREIGN KEY(user_id) REFERENCES Users(id)
-	)`
+// Numbered schema migrations of the configured driver shipped with the build
+func MigrationFiles() fs.FS {
+	return DriverMigrationFiles(AppConfiguration().Database().Driver)
+}
 
-	_, err = database.Exec(createEventsTableSql)
+// Numbered schema migrations of the driver, each version has a <version>_<name>.up.sql file and a
+// <version>_<name>.down.sql file reverting it. The drivers have their own history, a version of
+// one driver has nothing to do with the same version of the other
+func DriverMigrationFiles(driver string) fs.FS {
+	files, err := fs.Sub(migrationFiles, "migrations/"+driver)
 
 	if err != nil {
-		panic("Unable to create table")
+		panic("Unable to read the migrations")
 	}
 
-	createRegistrationsTableSql := `
-	CREATE TABLE IF NOT EXISTS Registrations (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		event_id INTEGER,
-		user_id INTEGER,
-		FOREIGN KEY(event_id) REFERENCES Events(id)
-		FOREIGN KEY(user_id) REFERENCES User(id)
-	)`
-
-	_, err = database.Exec(createRegistrationsTableSql)
-
-	if err != nil {
-		panic("Unable to create registrations table")
-	}
+	return files
 }
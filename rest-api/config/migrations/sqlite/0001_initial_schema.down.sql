@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS AuditLog;
+DROP TABLE IF EXISTS OrganizationInvitations;
+DROP TABLE IF EXISTS OrganizationMembers;
+DROP TABLE IF EXISTS Sessions;
+DROP TABLE IF EXISTS DataExports;
+DROP TABLE IF EXISTS UserProfiles;
+DROP TABLE IF EXISTS PersonalAccessTokens;
+DROP TABLE IF EXISTS UserIdentities;
+DROP TABLE IF EXISTS OidcLoginStates;
+DROP TABLE IF EXISTS MfaChallenges;
+DROP TABLE IF EXISTS RecoveryCodes;
+DROP TABLE IF EXISTS TotpCredentials;
+DROP TABLE IF EXISTS LoginThrottles;
+DROP TABLE IF EXISTS PasswordResetTokens;
+DROP TABLE IF EXISTS RevokedAccessTokens;
+DROP TABLE IF EXISTS RefreshTokens;
+DROP TABLE IF EXISTS Comments;
+DROP TABLE IF EXISTS Invitations;
+DROP TABLE IF EXISTS Registrations;
+DROP TABLE IF EXISTS Events;
+DROP TABLE IF EXISTS Organizations;
+DROP TABLE IF EXISTS Users;
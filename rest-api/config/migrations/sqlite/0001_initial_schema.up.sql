@@ -0,0 +1,272 @@
+-- Schema of the databases created at startup before migrations were introduced. Statements only
+-- create what is missing, the columns older tables lack are added by the migration repository
+-- beforehand, in the same transaction.
+
+CREATE TABLE IF NOT EXISTS Users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT NOT NULL UNIQUE,
+	password TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'user',
+	disabled_at DATETIME,
+	verified_at DATETIME,
+	verification_sent_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS Events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	location TEXT NOT NULL,
+	date DATETIME NOT NULL,
+	user_id INTEGER,
+	latitude REAL,
+	longitude REAL,
+	visibility TEXT NOT NULL DEFAULT 'public',
+	end_date DATETIME,
+	organization_id INTEGER,
+	FOREIGN KEY(user_id) REFERENCES Users(id),
+	FOREIGN KEY(organization_id) REFERENCES Organizations(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_coordinates ON Events(latitude, longitude);
+
+CREATE INDEX IF NOT EXISTS idx_events_organization ON Events(organization_id);
+
+CREATE TABLE IF NOT EXISTS Registrations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	user_id INTEGER,
+	FOREIGN KEY(event_id) REFERENCES Events(id)
+	FOREIGN KEY(user_id) REFERENCES User(id)
+);
+
+CREATE TABLE IF NOT EXISTS Invitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	email TEXT,
+	user_id INTEGER,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	revoked_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS Comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	parent_id INTEGER,
+	root_id INTEGER,
+	body TEXT NOT NULL,
+	pinned INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME,
+	deleted_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id),
+	FOREIGN KEY(parent_id) REFERENCES Comments(id),
+	FOREIGN KEY(root_id) REFERENCES Comments(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_event ON Comments(event_id, root_id);
+
+CREATE TABLE IF NOT EXISTS RefreshTokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	family_id TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	used_at DATETIME,
+	revoked_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON RefreshTokens(family_id);
+
+CREATE TABLE IF NOT EXISTS RevokedAccessTokens (
+	token_id TEXT PRIMARY KEY,
+	expires_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS PasswordResetTokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	used_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS LoginThrottles (
+	key TEXT PRIMARY KEY,
+	failures INTEGER NOT NULL,
+	last_failure_at DATETIME NOT NULL,
+	locked_until DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS TotpCredentials (
+	user_id INTEGER PRIMARY KEY,
+	secret TEXT NOT NULL,
+	last_used_step INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	confirmed_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS RecoveryCodes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash TEXT NOT NULL,
+	used_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user ON RecoveryCodes(user_id, code_hash);
+
+CREATE TABLE IF NOT EXISTS MfaChallenges (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	failed_attempts INTEGER NOT NULL DEFAULT 0,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	used_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS OidcLoginStates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	provider TEXT NOT NULL,
+	state_hash TEXT NOT NULL UNIQUE,
+	nonce TEXT NOT NULL,
+	code_verifier TEXT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS UserIdentities (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	provider TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	email TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	UNIQUE(provider, subject),
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS PersonalAccessTokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	scopes TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	last_used_at DATETIME,
+	revoked_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS UserProfiles (
+	user_id INTEGER PRIMARY KEY,
+	display_name TEXT NOT NULL DEFAULT '',
+	avatar_url TEXT NOT NULL DEFAULT '',
+	notify_event_updates INTEGER NOT NULL DEFAULT 1,
+	notify_announcements INTEGER NOT NULL DEFAULT 1,
+	notify_comment_replies INTEGER NOT NULL DEFAULT 1,
+	updated_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS DataExports (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	archive BLOB,
+	created_at DATETIME NOT NULL,
+	completed_at DATETIME,
+	expires_at DATETIME NOT NULL,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE IF NOT EXISTS Sessions (
+	id TEXT PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	ip_address TEXT NOT NULL,
+	user_agent TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	last_seen_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME,
+	organization_id INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON Sessions(user_id);
+
+CREATE TABLE IF NOT EXISTS Organizations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS OrganizationMembers (
+	organization_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	role TEXT NOT NULL,
+	joined_at DATETIME NOT NULL,
+	PRIMARY KEY(organization_id, user_id),
+	FOREIGN KEY(organization_id) REFERENCES Organizations(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user ON OrganizationMembers(user_id);
+
+CREATE TABLE IF NOT EXISTS OrganizationInvitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	organization_id INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	role TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	invited_by INTEGER NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	FOREIGN KEY(organization_id) REFERENCES Organizations(id),
+	FOREIGN KEY(invited_by) REFERENCES Users(id)
+);
+
+-- the actor is not a foreign key, entries outlive the accounts they mention
+CREATE TABLE IF NOT EXISTS AuditLog (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	occurred_at DATETIME NOT NULL,
+	action TEXT NOT NULL,
+	outcome TEXT NOT NULL,
+	actor_id INTEGER,
+	actor_email TEXT,
+	ip_address TEXT NOT NULL,
+	user_agent TEXT NOT NULL,
+	resource TEXT NOT NULL,
+	reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON AuditLog(occurred_at);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON AuditLog(actor_id);
+
+-- the log is append-only, entries cannot be altered or removed once written
+CREATE TRIGGER IF NOT EXISTS audit_log_prevent_update BEFORE UPDATE ON AuditLog
+BEGIN
+	SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_prevent_delete BEFORE DELETE ON AuditLog
+BEGIN
+	SELECT RAISE(ABORT, 'the audit log is append-only');
+END;
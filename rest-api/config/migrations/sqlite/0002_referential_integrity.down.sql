@@ -0,0 +1,96 @@
+-- Restores the constraints of the initial schema, except the user of a registration still references
+-- the Users table: the User table it used to reference does not exist, so no registration could be
+-- inserted while foreign keys are enforced.
+
+ALTER TABLE OrganizationInvitations RENAME TO OrganizationInvitations_new;
+
+CREATE TABLE OrganizationInvitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	organization_id INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	role TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	invited_by INTEGER NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	FOREIGN KEY(organization_id) REFERENCES Organizations(id),
+	FOREIGN KEY(invited_by) REFERENCES Users(id)
+);
+
+INSERT INTO OrganizationInvitations(id, organization_id, email, role, token_hash, invited_by, expires_at, created_at, accepted_at)
+SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, created_at, accepted_at FROM OrganizationInvitations_new;
+
+DROP TABLE OrganizationInvitations_new;
+
+-- the initial schema requires an author, comments of deleted accounts cannot be kept and neither
+-- can the replies below them
+ALTER TABLE Comments RENAME TO Comments_new;
+
+CREATE TABLE Comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	parent_id INTEGER,
+	root_id INTEGER,
+	body TEXT NOT NULL,
+	pinned INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME,
+	deleted_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id),
+	FOREIGN KEY(parent_id) REFERENCES Comments(id),
+	FOREIGN KEY(root_id) REFERENCES Comments(id)
+);
+
+WITH RECURSIVE removed(id) AS (
+	SELECT id FROM Comments_new WHERE user_id IS NULL
+	UNION
+	SELECT Comments_new.id FROM Comments_new JOIN removed ON Comments_new.parent_id = removed.id
+)
+INSERT INTO Comments(id, event_id, user_id, parent_id, root_id, body, pinned, created_at, updated_at, deleted_at)
+SELECT id, event_id, user_id, parent_id, root_id, body, pinned, created_at, updated_at, deleted_at FROM Comments_new
+WHERE id NOT IN (SELECT id FROM removed) AND (root_id IS NULL OR root_id NOT IN (SELECT id FROM removed));
+
+DROP TABLE Comments_new;
+
+CREATE INDEX idx_comments_event ON Comments(event_id, root_id);
+
+ALTER TABLE Invitations RENAME TO Invitations_new;
+
+CREATE TABLE Invitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	email TEXT,
+	user_id INTEGER,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	revoked_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+INSERT INTO Invitations(id, event_id, email, user_id, token_hash, expires_at, created_at, accepted_at, revoked_at)
+SELECT id, event_id, email, user_id, token_hash, expires_at, created_at, accepted_at, revoked_at FROM Invitations_new;
+
+DROP TABLE Invitations_new;
+
+DROP INDEX idx_registrations_event_user;
+
+ALTER TABLE Registrations RENAME TO Registrations_new;
+
+CREATE TABLE Registrations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	user_id INTEGER,
+	FOREIGN KEY(event_id) REFERENCES Events(id),
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+INSERT INTO Registrations(id, event_id, user_id)
+SELECT id, event_id, user_id FROM Registrations_new;
+
+DROP TABLE Registrations_new;
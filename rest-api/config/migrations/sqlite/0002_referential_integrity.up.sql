@@ -0,0 +1,105 @@
+-- SQLite cannot change the constraints of an existing table, so the tables whose foreign keys are
+-- wrong or have to follow deletions are rebuilt. Rows pointing at records deleted while foreign keys
+-- were not enforced are dropped, and so are duplicate registrations.
+
+ALTER TABLE Registrations RENAME TO Registrations_old;
+
+CREATE TABLE Registrations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	FOREIGN KEY(event_id) REFERENCES Events(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES Users(id) ON DELETE CASCADE
+);
+
+INSERT INTO Registrations(id, event_id, user_id)
+SELECT MIN(id), event_id, user_id FROM Registrations_old
+WHERE event_id IN (SELECT id FROM Events) AND user_id IN (SELECT id FROM Users)
+GROUP BY event_id, user_id;
+
+DROP TABLE Registrations_old;
+
+CREATE UNIQUE INDEX idx_registrations_event_user ON Registrations(event_id, user_id);
+
+ALTER TABLE Invitations RENAME TO Invitations_old;
+
+CREATE TABLE Invitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	email TEXT,
+	user_id INTEGER,
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	revoked_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES Users(id) ON DELETE CASCADE
+);
+
+INSERT INTO Invitations(id, event_id, email, user_id, token_hash, expires_at, created_at, accepted_at, revoked_at)
+SELECT id, event_id, email, user_id, token_hash, expires_at, created_at, accepted_at, revoked_at FROM Invitations_old
+WHERE event_id IN (SELECT id FROM Events) AND (user_id IS NULL OR user_id IN (SELECT id FROM Users));
+
+DROP TABLE Invitations_old;
+
+-- comments left by deleted accounts on other events stay in their thread without an author
+ALTER TABLE Comments RENAME TO Comments_old;
+
+CREATE TABLE Comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	user_id INTEGER,
+	parent_id INTEGER,
+	root_id INTEGER,
+	body TEXT NOT NULL,
+	pinned INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME,
+	deleted_at DATETIME,
+	FOREIGN KEY(event_id) REFERENCES Events(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES Users(id) ON DELETE SET NULL,
+	FOREIGN KEY(parent_id) REFERENCES Comments(id) ON DELETE CASCADE,
+	FOREIGN KEY(root_id) REFERENCES Comments(id) ON DELETE CASCADE
+);
+
+INSERT INTO Comments(id, event_id, user_id, parent_id, root_id, body, pinned, created_at, updated_at, deleted_at)
+SELECT
+	id,
+	event_id,
+	CASE WHEN user_id IN (SELECT id FROM Users) THEN user_id END,
+	parent_id,
+	root_id,
+	body,
+	pinned,
+	created_at,
+	updated_at,
+	deleted_at
+FROM Comments_old
+WHERE event_id IN (SELECT id FROM Events);
+
+DROP TABLE Comments_old;
+
+CREATE INDEX idx_comments_event ON Comments(event_id, root_id);
+
+ALTER TABLE OrganizationInvitations RENAME TO OrganizationInvitations_old;
+
+CREATE TABLE OrganizationInvitations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	organization_id INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	role TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	invited_by INTEGER NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	accepted_at DATETIME,
+	FOREIGN KEY(organization_id) REFERENCES Organizations(id) ON DELETE CASCADE,
+	FOREIGN KEY(invited_by) REFERENCES Users(id) ON DELETE CASCADE
+);
+
+INSERT INTO OrganizationInvitations(id, organization_id, email, role, token_hash, invited_by, expires_at, created_at, accepted_at)
+SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, created_at, accepted_at FROM OrganizationInvitations_old
+WHERE organization_id IN (SELECT id FROM Organizations) AND invited_by IN (SELECT id FROM Users);
+
+DROP TABLE OrganizationInvitations_old;
@@ -1,3 +1,97 @@
 package constants
 
 const NO_EVENT_FOR_ID_ERROR = "no event exists with provided id"
+
+const NOT_EVENT_ORGANIZER_ERROR = "user is not the organizer of the event"
+
+const NOT_INVITED_ERROR = "user is not invited to the event"
+
+const INVALID_INVITATION_ERROR = "invitation is invalid, expired or revoked"
+
+const NO_INVITATION_FOR_ID_ERROR = "no invitation exists with provided id"
+
+const NO_COMMENT_FOR_ID_ERROR = "no comment exists with provided id"
+
+const NOT_COMMENT_AUTHOR_ERROR = "user is not the author of the comment"
+
+const INVALID_PARENT_COMMENT_ERROR = "parent comment does not exist or was deleted"
+
+const PIN_REPLY_ERROR = "only top level comments can be pinned"
+
+const EMPTY_COMMENT_ERROR = "comment is empty after sanitization"
+
+const SCHEDULE_CONFLICT_ERROR = "event overlaps with other events the user is registered for"
+
+const INVALID_REFRESH_TOKEN_ERROR = "refresh token is invalid or expired"
+
+const REFRESH_TOKEN_REUSE_ERROR = "refresh token was already used, every token of its family was revoked"
+
+const REVOKED_ACCESS_TOKEN_ERROR = "access token was revoked"
+
+const ORIGIN_NOT_ALLOWED_ERROR = "the connection was opened from an origin that is not allowed"
+
+const NO_USER_FOR_ID_ERROR = "no user exists with provided id"
+
+const INVALID_ROLE_ERROR = "role does not exist"
+
+const ACCOUNT_DISABLED_ERROR = "user account is disabled"
+
+const NO_USER_FOR_EMAIL_ERROR = "no user found associated with provided email address"
+
+const INVALID_VERIFICATION_TOKEN_ERROR = "verification token is invalid or expired"
+
+const VERIFICATION_EMAIL_THROTTLED_ERROR = "a verification email was sent recently"
+
+const INVALID_PASSWORD_RESET_TOKEN_ERROR = "password reset token is invalid, expired or already used"
+
+const TOTP_ALREADY_ENABLED_ERROR = "two factor authentication is already enabled"
+
+const TOTP_NOT_ENROLLED_ERROR = "two factor authentication enrollment was not started"
+
+const TOTP_NOT_ENABLED_ERROR = "two factor authentication is not enabled"
+
+const INVALID_TOTP_CODE_ERROR = "two factor authentication code is invalid"
+
+const INVALID_MFA_TOKEN_ERROR = "mfa token is invalid, expired or already used"
+
+const UNKNOWN_OIDC_PROVIDER_ERROR = "oidc provider is not configured"
+
+const INVALID_OIDC_STATE_ERROR = "oidc login state is invalid or expired"
+
+const OIDC_AUTHENTICATION_ERROR = "oidc provider did not authenticate the user"
+
+const OIDC_EMAIL_NOT_VERIFIED_ERROR = "oidc provider did not verify the email of the user"
+
+const INVALID_PERSONAL_ACCESS_TOKEN_ERROR = "personal access token is invalid, expired or revoked"
+
+const INVALID_SCOPE_ERROR = "scope does not exist"
+
+const NO_PERSONAL_ACCESS_TOKEN_FOR_ID_ERROR = "no personal access token exists with provided id"
+
+const INVALID_PASSWORD_ERROR = "password is invalid"
+
+const PASSWORD_NOT_SET_ERROR = "account has no password, set one through the password reset first"
+
+const EMAIL_ALREADY_IN_USE_ERROR = "email address is already in use"
+
+const NO_DATA_EXPORT_FOR_ID_ERROR = "no data export exists with provided id"
+
+const DATA_EXPORT_NOT_READY_ERROR = "data export is not ready to be downloaded"
+
+const NO_SESSION_FOR_ID_ERROR = "no active session exists with provided id"
+
+const NO_ORGANIZATION_FOR_ID_ERROR = "no organization exists with provided id"
+
+const NOT_ORGANIZATION_ADMIN_ERROR = "user is not an admin of the organization"
+
+const NO_ORGANIZATION_MEMBER_FOR_ID_ERROR = "no member of the organization exists with provided id"
+
+const LAST_ORGANIZATION_ADMIN_ERROR = "the organization needs at least one admin"
+
+const INVALID_ORGANIZATION_INVITATION_ERROR = "organization invitation is invalid, expired or meant for another user"
+
+const MIGRATION_LOCKED_ERROR = "another process is already migrating the database"
+
+const DUPLICATE_RECORD_ERROR = "a record with the same unique values already exists"
+
+const MISSING_REFERENCED_RECORD_ERROR = "the record references a record that does not exist"
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	userService          interfaces.IUserService
+	loginThrottleService interfaces.ILoginThrottleService
+}
+
+func (controller AdminController) GetUsers(context *gin.Context) {
+	users, err := controller.userService.GetUsers()
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	summaries := make([]models.UserSummary, 0, len(users))
+
+	for _, user := range users {
+		summaries = append(summaries, models.NewUserSummary(user))
+	}
+
+	context.JSON(http.StatusOK, summaries)
+}
+
+func (controller AdminController) DisableUser(context *gin.Context) {
+	controller.setUserDisabled(context, true)
+}
+
+func (controller AdminController) EnableUser(context *gin.Context) {
+	controller.setUserDisabled(context, false)
+}
+
+// Lifts the lock placed on the account after too many failed login attempts
+func (controller AdminController) UnlockUser(context *gin.Context) {
+	userId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid user id",
+		})
+		return
+	}
+
+	err := controller.loginThrottleService.UnlockAccount(userId)
+
+	if err != nil && err.Error() == constants.NO_USER_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked",
+	})
+}
+
+func (controller AdminController) setUserDisabled(context *gin.Context, disabled bool) {
+	userId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid user id",
+		})
+		return
+	}
+
+	//an administrator disabling their own account could lock everyone out
+	if disabled && userId == context.GetInt64("userId") {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unable to disable your own account",
+		})
+		return
+	}
+
+	err := controller.userService.SetUserDisabled(userId, disabled)
+
+	if err != nil && err.Error() == constants.NO_USER_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	message := "User enabled"
+
+	if disabled {
+		message = "User disabled"
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
+func NewAdminController(userService interfaces.IUserService, loginThrottleService interfaces.ILoginThrottleService) *AdminController {
+	return &AdminController{
+		userService:          userService,
+		loginThrottleService: loginThrottleService,
+	}
+}
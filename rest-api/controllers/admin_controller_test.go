@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext              *gin.Context
+	userServiceMock          mocks.IUserService
+	loginThrottleServiceMock mocks.ILoginThrottleService
+	mockResponseWriter       *httptest.ResponseRecorder
+	controller               *AdminController
+}
+
+func TestAdminControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AdminControllerUnitTestSuite{})
+}
+
+func (suite *AdminControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/admin/users/2/disable", nil)
+
+	suite.mockContext.Set("userId", int64(1))
+
+	suite.userServiceMock = mocks.IUserService{}
+	suite.loginThrottleServiceMock = mocks.ILoginThrottleService{}
+
+	suite.controller = NewAdminController(&suite.userServiceMock, &suite.loginThrottleServiceMock)
+}
+
+// Password hashes are never part of the listing
+func (suite *AdminControllerUnitTestSuite) TestGetUsers_ReturnsTheUsersWithoutPasswords() {
+
+	disabledAt := time.Now()
+
+	suite.userServiceMock.On("GetUsers").Return([]models.User{
+		{Id: 1, Email: "admin email", Password: "some hash", Role: models.ADMIN_ROLE},
+		{Id: 2, Email: "user email", Password: "some hash", Role: models.USER_ROLE, DisabledAt: &disabledAt},
+	}, nil)
+
+	suite.controller.GetUsers(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"role":"admin"`)
+	suite.Contains(response.Body, `"users:manage"`)
+	suite.Contains(response.Body, `"disabled_at"`)
+	suite.NotContains(response.Body, "some hash")
+}
+
+// When failing to fetch the users return internal server error
+func (suite *AdminControllerUnitTestSuite) TestGetUsers_ReturnsInternalServerError() {
+
+	suite.userServiceMock.On("GetUsers").Return(nil, errors.New("test"))
+
+	suite.controller.GetUsers(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// When the request param is not a valid id, return a bad request
+func (suite *AdminControllerUnitTestSuite) TestDisableUserInvalidParam_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "bar"}}
+
+	suite.controller.DisableUser(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When administrators attempt to disable themselves, return a bad request
+func (suite *AdminControllerUnitTestSuite) TestDisableUserOwnAccount_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	suite.controller.DisableUser(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.userServiceMock.AssertNotCalled(suite.T(), "SetUserDisabled", mock.Anything, mock.Anything)
+}
+
+// When no user exists for the id, return not found
+func (suite *AdminControllerUnitTestSuite) TestDisableUserUnknownUser_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	suite.userServiceMock.On("SetUserDisabled", mock.Anything, mock.Anything).Return(errors.New(constants.NO_USER_FOR_ID_ERROR))
+
+	suite.controller.DisableUser(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *AdminControllerUnitTestSuite) TestDisableUser_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	suite.userServiceMock.On("SetUserDisabled", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.DisableUser(suite.mockContext)
+
+	suite.userServiceMock.AssertCalled(suite.T(), "SetUserDisabled", int64(2), true)
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+func (suite *AdminControllerUnitTestSuite) TestEnableUser_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	suite.userServiceMock.On("SetUserDisabled", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.EnableUser(suite.mockContext)
+
+	suite.userServiceMock.AssertCalled(suite.T(), "SetUserDisabled", int64(1), false)
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+// When no user exists for the id, return not found
+func (suite *AdminControllerUnitTestSuite) TestUnlockUserUnknownUser_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	suite.loginThrottleServiceMock.On("UnlockAccount", mock.Anything).Return(errors.New(constants.NO_USER_FOR_ID_ERROR))
+
+	suite.controller.UnlockUser(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *AdminControllerUnitTestSuite) TestUnlockUser_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	suite.loginThrottleServiceMock.On("UnlockAccount", mock.Anything).Return(nil)
+
+	suite.controller.UnlockUser(suite.mockContext)
+
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "UnlockAccount", int64(2))
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
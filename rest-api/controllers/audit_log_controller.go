@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLogPageSize = 100
+	maxAuditLogPageSize     = 1000
+)
+
+type AuditLogController struct {
+	auditLogService interfaces.IAuditLogService
+}
+
+// Entries matching the query parameters, oldest first. The next page starts after next_after_id
+func (controller AuditLogController) GetAuditLog(context *gin.Context) {
+	filter, ok := parseAuditLogFilter(context)
+
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(defaultAuditLogPageSize)))
+
+	if err != nil || limit < 1 || limit > maxAuditLogPageSize {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("Invalid limit parameter, expected a value between 1 and %v", maxAuditLogPageSize),
+		})
+		return
+	}
+
+	filter.Limit = limit
+
+	entries, err := controller.auditLogService.GetEntries(filter)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	nextAfterId := filter.AfterId
+
+	if len(entries) > 0 {
+		nextAfterId = entries[len(entries)-1].Id
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"entries":       entries,
+		"next_after_id": nextAfterId,
+	})
+}
+
+// Streams every entry matching the query parameters as newline delimited JSON, for SIEM ingestion
+func (controller AuditLogController) ExportAuditLog(context *gin.Context) {
+	filter, ok := parseAuditLogFilter(context)
+
+	if !ok {
+		return
+	}
+
+	fileName := fmt.Sprintf("audit-log-%v.ndjson", time.Now().UTC().Format("20060102T150405"))
+
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v"`, fileName))
+	context.Header("Content-Type", "application/x-ndjson")
+
+	err := controller.auditLogService.ExportEntries(filter, context.Writer)
+
+	//once entries were sent the status cannot change anymore, the truncated export is the best we can do
+	if err != nil && !context.Writer.Written() {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if err != nil {
+		context.Abort()
+		return
+	}
+
+	context.Status(http.StatusOK)
+}
+
+func parseAuditLogFilter(context *gin.Context) (models.AuditLogFilter, bool) {
+	filter := models.AuditLogFilter{
+		Action:  context.Query("action"),
+		Outcome: context.Query("outcome"),
+	}
+
+	var err error
+
+	if actorId := context.Query("actor_id"); actorId != "" {
+		filter.ActorId, err = strconv.ParseInt(actorId, 10, 64)
+
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid actor_id parameter",
+			})
+			return filter, false
+		}
+	}
+
+	if afterId := context.Query("after_id"); afterId != "" {
+		filter.AfterId, err = strconv.ParseInt(afterId, 10, 64)
+
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid after_id parameter",
+			})
+			return filter, false
+		}
+	}
+
+	from, ok := parseAuditLogDate(context, "from")
+
+	if !ok {
+		return filter, false
+	}
+
+	to, ok := parseAuditLogDate(context, "to")
+
+	if !ok {
+		return filter, false
+	}
+
+	filter.From = from
+	filter.To = to
+
+	return filter, true
+}
+
+// Returns nil when the parameter is missing
+func parseAuditLogDate(context *gin.Context, name string) (*time.Time, bool) {
+	value := context.Query(name)
+
+	if value == "" {
+		return nil, true
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("Invalid %v parameter, expected an RFC 3339 date", name),
+		})
+		return nil, false
+	}
+
+	date = date.UTC()
+
+	return &date, true
+}
+
+func NewAuditLogController(auditLogService interfaces.IAuditLogService) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+	}
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext         *gin.Context
+	auditLogServiceMock mocks.IAuditLogService
+	mockResponseWriter  *httptest.ResponseRecorder
+	controller          *AuditLogController
+}
+
+func TestAuditLogControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AuditLogControllerUnitTestSuite{})
+}
+
+func (suite *AuditLogControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/admin/audit-log", nil)
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+
+	suite.controller = NewAuditLogController(&suite.auditLogServiceMock)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) setQuery(query string) {
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/admin/audit-log?"+query, nil)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLogInvalidDate_ReturnsBadRequest() {
+
+	suite.setQuery("from=yesterday")
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.auditLogServiceMock.AssertNotCalled(suite.T(), "GetEntries", mock.Anything)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLogInvalidActor_ReturnsBadRequest() {
+
+	suite.setQuery("actor_id=me")
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLogLimitTooLarge_ReturnsBadRequest() {
+
+	suite.setQuery("limit=5000")
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.auditLogServiceMock.AssertNotCalled(suite.T(), "GetEntries", mock.Anything)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLog_FiltersTheEntries() {
+
+	suite.setQuery("action=login&outcome=failure&actor_id=12&after_id=40&from=2024-01-01T02:00:00%2B02:00&to=2024-01-02T00:00:00Z")
+
+	suite.auditLogServiceMock.On("GetEntries", mock.Anything).Return([]models.AuditLogEntry{}, nil)
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.auditLogServiceMock.AssertCalled(suite.T(), "GetEntries", models.AuditLogFilter{
+		Action:  models.LOGIN_AUDIT_ACTION,
+		Outcome: models.FAILURE_AUDIT_OUTCOME,
+		ActorId: 12,
+		From:    &from,
+		To:      &to,
+		AfterId: 40,
+		Limit:   defaultAuditLogPageSize,
+	})
+}
+
+// The cursor of the next page is the last entry returned, or the current one on the last page
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLog_ReturnsTheNextCursor() {
+
+	suite.setQuery("limit=2")
+
+	suite.auditLogServiceMock.On("GetEntries", mock.Anything).Return([]models.AuditLogEntry{
+		{Id: 3, Action: models.LOGIN_AUDIT_ACTION},
+		{Id: 5, Action: models.LOGOUT_AUDIT_ACTION},
+	}, nil)
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"next_after_id":5`)
+	suite.Contains(response.Body, `"action":"logout"`)
+	suite.auditLogServiceMock.AssertCalled(suite.T(), "GetEntries", models.AuditLogFilter{Limit: 2})
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestGetAuditLog_ReturnsInternalServerError() {
+
+	suite.auditLogServiceMock.On("GetEntries", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.GetAuditLog(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestExportAuditLog_StreamsNewlineDelimitedJson() {
+
+	suite.setQuery("outcome=denied")
+
+	suite.auditLogServiceMock.On("ExportEntries", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			io.WriteString(args.Get(1).(io.Writer), "{\"id\":1}\n")
+		}).
+		Return(nil)
+
+	suite.controller.ExportAuditLog(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("application/x-ndjson", suite.mockResponseWriter.Header().Get("Content-Type"))
+	suite.Contains(suite.mockResponseWriter.Header().Get("Content-Disposition"), ".ndjson")
+	suite.Equal("{\"id\":1}\n", response.Body)
+	suite.auditLogServiceMock.AssertCalled(suite.T(), "ExportEntries", models.AuditLogFilter{Outcome: models.DENIED_AUDIT_OUTCOME}, mock.Anything)
+}
+
+func (suite *AuditLogControllerUnitTestSuite) TestExportAuditLog_ReturnsInternalServerError() {
+
+	suite.auditLogServiceMock.On("ExportEntries", mock.Anything, mock.Anything).Return(errors.New("test"))
+
+	suite.controller.ExportAuditLog(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
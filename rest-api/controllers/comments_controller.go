@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 100
+)
+
+type CommentsController struct {
+	eventService   interfaces.IEventService
+	commentService interfaces.ICommentService
+}
+
+func (controller CommentsController) GetComments(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(context.DefaultQuery("page", "1"))
+
+	if err != nil || page < 1 {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid page parameter",
+		})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(context.DefaultQuery("page_size", strconv.Itoa(defaultCommentPageSize)))
+
+	if err != nil || pageSize < 1 || pageSize > maxCommentPageSize {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("Invalid page_size parameter, expected a value between 1 and %v", maxCommentPageSize),
+		})
+		return
+	}
+
+	if !controller.ensureVisible(context, eventId, context.GetInt64("userId"), context.Query("invite")) {
+		return
+	}
+
+	comments, err := controller.commentService.GetComments(eventId, page, pageSize)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, comments)
+}
+
+func (controller CommentsController) CreateComment(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	var request models.CommentRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid comment",
+		})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+
+	if !controller.ensureVisible(context, eventId, userId, "") {
+		return
+	}
+
+	comment, err := controller.commentService.CreateComment(eventId, userId, request)
+
+	if err != nil {
+		respondToCommentError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{
+		"message": "Created",
+		"comment": comment,
+	})
+}
+
+func (controller CommentsController) UpdateComment(context *gin.Context) {
+	eventId, commentId, ok := parseCommentParams(context)
+
+	if !ok {
+		return
+	}
+
+	var request models.UpdateCommentRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid comment",
+		})
+		return
+	}
+
+	comment, err := controller.commentService.UpdateComment(eventId, commentId, context.GetInt64("userId"), request)
+
+	if err != nil {
+		respondToCommentError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+		"comment": comment,
+	})
+}
+
+func (controller CommentsController) DeleteComment(context *gin.Context) {
+	eventId, commentId, ok := parseCommentParams(context)
+
+	if !ok {
+		return
+	}
+
+	err := controller.commentService.DeleteComment(eventId, commentId, context.GetInt64("userId"), context.GetInt64("organizationId"))
+
+	if err != nil {
+		respondToCommentError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Comment Deleted",
+	})
+}
+
+func (controller CommentsController) PinComment(context *gin.Context) {
+	controller.setPinned(context, true)
+}
+
+func (controller CommentsController) UnpinComment(context *gin.Context) {
+	controller.setPinned(context, false)
+}
+
+func (controller CommentsController) setPinned(context *gin.Context, pinned bool) {
+	eventId, commentId, ok := parseCommentParams(context)
+
+	if !ok {
+		return
+	}
+
+	err := controller.commentService.PinComment(eventId, commentId, context.GetInt64("userId"), context.GetInt64("organizationId"), pinned)
+
+	if err != nil {
+		respondToCommentError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+	})
+}
+
+// Responds with not found when the event does not exist or is hidden from the user
+func (controller CommentsController) ensureVisible(context *gin.Context, eventId, userId int64, invitationToken string) bool {
+	event, err := controller.eventService.GetVisibleEventById(eventId, userId, context.GetInt64("organizationId"), invitationToken)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Error trying to fetch event by id, error: %v\n", err),
+		})
+		return false
+	}
+
+	if event.Id == 0 {
+		context.JSON(http.StatusNotFound, nil)
+		return false
+	}
+
+	return true
+}
+
+func parseCommentParams(context *gin.Context) (int64, int64, bool) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return 0, 0, false
+	}
+
+	commentId, parsingError := strconv.ParseInt(context.Param("commentId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid comment id",
+		})
+		return 0, 0, false
+	}
+
+	return eventId, commentId, true
+}
+
+func respondToCommentError(context *gin.Context, err error) {
+	switch err.Error() {
+	case constants.NO_EVENT_FOR_ID_ERROR, constants.NO_COMMENT_FOR_ID_ERROR:
+		context.JSON(http.StatusNotFound, nil)
+	case constants.NOT_COMMENT_AUTHOR_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the author can edit the comment",
+		})
+	case constants.NOT_EVENT_ORGANIZER_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the organizer can moderate comments",
+		})
+	case constants.INVALID_PARENT_COMMENT_ERROR, constants.PIN_REPLY_ERROR, constants.EMPTY_COMMENT_ERROR:
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+	//the event, the parent comment or the account was deleted while commenting
+	case constants.MISSING_REFERENCED_RECORD_ERROR:
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "The event, the parent comment or the account no longer exists",
+		})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+	}
+}
+
+func NewCommentsController(eventService interfaces.IEventService, commentService interfaces.ICommentService) *CommentsController {
+	return &CommentsController{
+		eventService:   eventService,
+		commentService: commentService,
+	}
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentsControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext        *gin.Context
+	eventServiceMock   mocks.IEventService
+	commentServiceMock mocks.ICommentService
+	mockResponseWriter *httptest.ResponseRecorder
+	controller         *CommentsController
+}
+
+func TestCommentsControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &CommentsControllerUnitTestSuite{})
+}
+
+func (suite *CommentsControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/comments", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+		{
+			Key:   "commentId",
+			Value: "2",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.eventServiceMock = mocks.IEventService{}
+	suite.commentServiceMock = mocks.ICommentService{}
+
+	suite.controller = NewCommentsController(&suite.eventServiceMock, &suite.commentServiceMock)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestGetCommentsInvalidPageSize_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/comments?page_size=500", nil)
+
+	suite.controller.GetComments(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the event is hidden from the viewer, its comments are hidden as well
+func (suite *CommentsControllerUnitTestSuite) TestGetCommentsOfHiddenEvent_ReturnsNotFound() {
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	suite.controller.GetComments(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+	suite.commentServiceMock.AssertNotCalled(suite.T(), "GetComments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestGetComments_ReturnsThePage() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/comments?page=2&page_size=10", nil)
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.commentServiceMock.On("GetComments", mock.Anything, mock.Anything, mock.Anything).Return(&models.CommentPage{
+		Comments: []*models.Comment{},
+		Page:     2,
+		PageSize: 10,
+		Total:    11,
+	}, nil)
+
+	suite.controller.GetComments(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.commentServiceMock.AssertCalled(suite.T(), "GetComments", int64(1), 2, 10)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"total":11`)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestCreateCommentMissingBody_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.CommentRequest{}, suite.mockContext)
+
+	suite.controller.CreateComment(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the parent comment is invalid, it should return bad request
+func (suite *CommentsControllerUnitTestSuite) TestCreateCommentInvalidParent_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.CommentRequest{Body: "some body"}, suite.mockContext)
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.commentServiceMock.On("CreateComment", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.INVALID_PARENT_COMMENT_ERROR))
+
+	suite.controller.CreateComment(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the event or the parent comment was deleted meanwhile, return a conflict
+func (suite *CommentsControllerUnitTestSuite) TestCreateCommentMissingReference_ReturnsConflict() {
+
+	test_utils.SetRequestBody(models.CommentRequest{Body: "some body"}, suite.mockContext)
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.commentServiceMock.On("CreateComment", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.MISSING_REFERENCED_RECORD_ERROR))
+
+	suite.controller.CreateComment(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestCreateComment_ReturnsCreated() {
+
+	test_utils.SetRequestBody(models.CommentRequest{Body: "some body"}, suite.mockContext)
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.commentServiceMock.On("CreateComment", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Comment{Id: 2, Body: "some body"}, nil)
+
+	suite.controller.CreateComment(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetVisibleEventById", int64(1), int64(12), int64(0), "")
+	suite.commentServiceMock.AssertCalled(suite.T(), "CreateComment", int64(1), int64(12), models.CommentRequest{Body: "some body"})
+	suite.Equal(http.StatusCreated, suite.mockResponseWriter.Code)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestUpdateCommentMalformedCommentId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+		{
+			Key:   "commentId",
+			Value: "bar",
+		},
+	}
+
+	suite.controller.UpdateComment(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the user is not the author of the comment, it should return forbidden
+func (suite *CommentsControllerUnitTestSuite) TestUpdateCommentNotTheAuthor_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.UpdateCommentRequest{Body: "edited"}, suite.mockContext)
+
+	suite.commentServiceMock.On("UpdateComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.NOT_COMMENT_AUTHOR_ERROR))
+
+	suite.controller.UpdateComment(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestDeleteCommentNotFound_ReturnsNotFound() {
+
+	suite.commentServiceMock.On("DeleteComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.NO_COMMENT_FOR_ID_ERROR))
+
+	suite.controller.DeleteComment(suite.mockContext)
+
+	suite.commentServiceMock.AssertCalled(suite.T(), "DeleteComment", int64(1), int64(2), int64(12), int64(0))
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestPinComment_PinsTheComment() {
+
+	suite.commentServiceMock.On("PinComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.PinComment(suite.mockContext)
+
+	suite.commentServiceMock.AssertCalled(suite.T(), "PinComment", int64(1), int64(2), int64(12), int64(0), true)
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+func (suite *CommentsControllerUnitTestSuite) TestUnpinCommentNotTheOrganizer_ReturnsForbidden() {
+
+	suite.commentServiceMock.On("PinComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.NOT_EVENT_ORGANIZER_ERROR))
+
+	suite.controller.UnpinComment(suite.mockContext)
+
+	suite.commentServiceMock.AssertCalled(suite.T(), "PinComment", int64(1), int64(2), int64(12), int64(0), false)
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
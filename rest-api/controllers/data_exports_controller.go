@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DataExportsController struct {
+	dataExportService interfaces.IDataExportService
+}
+
+// Sends the archive right away for small accounts, otherwise accepts the request and points to
+// the export being generated
+func (controller DataExportsController) ExportData(context *gin.Context) {
+	userId := context.GetInt64("userId")
+
+	result, err := controller.dataExportService.RequestDataExport(userId)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if result.Export == nil {
+		sendDataExportArchive(context, userId, result.Archive)
+		return
+	}
+
+	context.JSON(http.StatusAccepted, gin.H{
+		"id":         result.Export.Id,
+		"status":     result.Export.Status,
+		"status_url": fmt.Sprintf("/users/me/exports/%v", result.Export.Id),
+	})
+}
+
+func (controller DataExportsController) GetDataExport(context *gin.Context) {
+	exportId, parsingError := strconv.ParseInt(context.Param("exportId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid export id",
+		})
+		return
+	}
+
+	export, err := controller.dataExportService.GetDataExport(exportId, context.GetInt64("userId"))
+
+	if err != nil && err.Error() == constants.NO_DATA_EXPORT_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	response := gin.H{
+		"id":           export.Id,
+		"status":       export.Status,
+		"created_at":   export.CreatedAt,
+		"completed_at": export.CompletedAt,
+		"expires_at":   export.ExpiresAt,
+	}
+
+	if export.IsDownloadable(time.Now().UTC()) {
+		response["download_url"] = fmt.Sprintf("/users/me/exports/%v/download", export.Id)
+	}
+
+	context.JSON(http.StatusOK, response)
+}
+
+func (controller DataExportsController) DownloadDataExport(context *gin.Context) {
+	exportId, parsingError := strconv.ParseInt(context.Param("exportId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid export id",
+		})
+		return
+	}
+
+	userId := context.GetInt64("userId")
+
+	archive, err := controller.dataExportService.GetDataExportArchive(exportId, userId)
+
+	if err != nil && err.Error() == constants.NO_DATA_EXPORT_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil && err.Error() == constants.DATA_EXPORT_NOT_READY_ERROR {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	sendDataExportArchive(context, userId, archive)
+}
+
+func sendDataExportArchive(context *gin.Context, userId int64, archive []byte) {
+	fileName := fmt.Sprintf("export-%v-%v.zip", userId, time.Now().UTC().Format("20060102"))
+
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v"`, fileName))
+	context.Data(http.StatusOK, "application/zip", archive)
+}
+
+func NewDataExportsController(dataExportService interfaces.IDataExportService) *DataExportsController {
+	return &DataExportsController{
+		dataExportService: dataExportService,
+	}
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataExportsControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext           *gin.Context
+	dataExportServiceMock mocks.IDataExportService
+	mockResponseWriter    *httptest.ResponseRecorder
+	controller            *DataExportsController
+}
+
+func TestDataExportsControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &DataExportsControllerUnitTestSuite{})
+}
+
+func (suite *DataExportsControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/users/me/export", nil)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.dataExportServiceMock = mocks.IDataExportService{}
+
+	suite.controller = NewDataExportsController(&suite.dataExportServiceMock)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestExportDataSmallAccount_SendsTheArchive() {
+
+	suite.dataExportServiceMock.On("RequestDataExport", int64(12)).Return(&models.DataExportResult{Archive: []byte("archive")}, nil)
+
+	suite.controller.ExportData(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.Equal("application/zip", suite.mockResponseWriter.Header().Get("Content-Type"))
+	suite.Contains(suite.mockResponseWriter.Header().Get("Content-Disposition"), `attachment; filename="export-12-`)
+	suite.Equal("archive", suite.mockResponseWriter.Body.String())
+}
+
+// Large accounts get pointed to the export being generated
+func (suite *DataExportsControllerUnitTestSuite) TestExportDataLargeAccount_ReturnsAccepted() {
+
+	suite.dataExportServiceMock.On("RequestDataExport", int64(12)).Return(&models.DataExportResult{
+		Export: &models.DataExport{Id: 7, UserId: 12, Status: models.DATA_EXPORT_PENDING},
+	}, nil)
+
+	suite.controller.ExportData(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusAccepted, response.StatusCode)
+	suite.Contains(response.Body, `"status":"pending"`)
+	suite.Contains(response.Body, `"status_url":"/users/me/exports/7"`)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestExportData_ReturnsInternalServerError() {
+
+	suite.dataExportServiceMock.On("RequestDataExport", int64(12)).Return(nil, errors.New("test"))
+
+	suite.controller.ExportData(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// Ready exports link to their download
+func (suite *DataExportsControllerUnitTestSuite) TestGetReadyDataExport_ReturnsTheDownloadUrl() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{
+		Id:        7,
+		UserId:    12,
+		Status:    models.DATA_EXPORT_READY,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	suite.controller.GetDataExport(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"download_url":"/users/me/exports/7/download"`)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestGetPendingDataExport_ReturnsTheStatus() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{
+		Id:        7,
+		UserId:    12,
+		Status:    models.DATA_EXPORT_PENDING,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	suite.controller.GetDataExport(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"status":"pending"`)
+	suite.NotContains(response.Body, "download_url")
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestGetMissingDataExport_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExport", int64(7), int64(12)).Return(nil, errors.New(constants.NO_DATA_EXPORT_FOR_ID_ERROR))
+
+	suite.controller.GetDataExport(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestGetDataExportInvalidId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "abc"}}
+
+	suite.controller.GetDataExport(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.dataExportServiceMock.AssertNotCalled(suite.T(), "GetDataExport", mock.Anything, mock.Anything)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestDownloadDataExport_SendsTheArchive() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExportArchive", int64(7), int64(12)).Return([]byte("archive"), nil)
+
+	suite.controller.DownloadDataExport(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.Equal("application/zip", suite.mockResponseWriter.Header().Get("Content-Type"))
+	suite.Equal("archive", suite.mockResponseWriter.Body.String())
+}
+
+// Exports still being generated cannot be downloaded
+func (suite *DataExportsControllerUnitTestSuite) TestDownloadPendingDataExport_ReturnsConflict() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExportArchive", int64(7), int64(12)).Return(nil, errors.New(constants.DATA_EXPORT_NOT_READY_ERROR))
+
+	suite.controller.DownloadDataExport(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+func (suite *DataExportsControllerUnitTestSuite) TestDownloadMissingDataExport_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "exportId", Value: "7"}}
+
+	suite.dataExportServiceMock.On("GetDataExportArchive", int64(7), int64(12)).Return(nil, errors.New(constants.NO_DATA_EXPORT_FOR_ID_ERROR))
+
+	suite.controller.DownloadDataExport(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationController struct {
+	emailVerificationService interfaces.IEmailVerificationService
+}
+
+// Opened from the link sent by email, so the token is read from the query string
+func (controller EmailVerificationController) VerifyEmail(context *gin.Context) {
+	token := context.Query("token")
+
+	if token == "" {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Missing verification token",
+		})
+		return
+	}
+
+	err := controller.emailVerificationService.VerifyEmail(token)
+
+	if err != nil && err.Error() == constants.INVALID_VERIFICATION_TOKEN_ERROR {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	//another account took the new email since the link was sent
+	if err != nil && err.Error() == constants.EMAIL_ALREADY_IN_USE_ERROR {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Email verified",
+	})
+}
+
+func (controller EmailVerificationController) ResendVerificationEmail(context *gin.Context) {
+	sent, err := controller.emailVerificationService.ResendVerificationEmail(context.GetInt64("userId"))
+
+	if err != nil && err.Error() == constants.VERIFICATION_EMAIL_THROTTLED_ERROR {
+		context.JSON(http.StatusTooManyRequests, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if !sent {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "Email already verified",
+		})
+		return
+	}
+
+	context.JSON(http.StatusAccepted, gin.H{
+		"message": "Verification email sent",
+	})
+}
+
+func NewEmailVerificationController(emailVerificationService interfaces.IEmailVerificationService) *EmailVerificationController {
+	return &EmailVerificationController{
+		emailVerificationService: emailVerificationService,
+	}
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailVerificationControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext                  *gin.Context
+	emailVerificationServiceMock mocks.IEmailVerificationService
+	mockResponseWriter           *httptest.ResponseRecorder
+	controller                   *EmailVerificationController
+}
+
+func TestEmailVerificationControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &EmailVerificationControllerUnitTestSuite{})
+}
+
+func (suite *EmailVerificationControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/email/verify?token=some-token", nil)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.emailVerificationServiceMock = mocks.IEmailVerificationService{}
+
+	suite.controller = NewEmailVerificationController(&suite.emailVerificationServiceMock)
+}
+
+// When the token is missing, return a bad request
+func (suite *EmailVerificationControllerUnitTestSuite) TestVerifyEmailMissingToken_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/email/verify", nil)
+
+	suite.controller.VerifyEmail(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the token is invalid or expired, return a bad request
+func (suite *EmailVerificationControllerUnitTestSuite) TestVerifyEmailInvalidToken_ReturnsBadRequest() {
+
+	suite.emailVerificationServiceMock.On("VerifyEmail", mock.Anything).Return(errors.New(constants.INVALID_VERIFICATION_TOKEN_ERROR))
+
+	suite.controller.VerifyEmail(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When another account took the new email since the link was sent, return a conflict
+func (suite *EmailVerificationControllerUnitTestSuite) TestVerifyEmailChangeEmailInUse_ReturnsConflict() {
+
+	suite.emailVerificationServiceMock.On("VerifyEmail", mock.Anything).Return(errors.New(constants.EMAIL_ALREADY_IN_USE_ERROR))
+
+	suite.controller.VerifyEmail(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+func (suite *EmailVerificationControllerUnitTestSuite) TestVerifyEmail_ReturnsOk() {
+
+	suite.emailVerificationServiceMock.On("VerifyEmail", mock.Anything).Return(nil)
+
+	suite.controller.VerifyEmail(suite.mockContext)
+
+	suite.emailVerificationServiceMock.AssertCalled(suite.T(), "VerifyEmail", "some-token")
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+// When an email was sent too recently, return too many requests
+func (suite *EmailVerificationControllerUnitTestSuite) TestResendVerificationEmailThrottled_ReturnsTooManyRequests() {
+
+	suite.emailVerificationServiceMock.On("ResendVerificationEmail", mock.Anything).Return(false, errors.New(constants.VERIFICATION_EMAIL_THROTTLED_ERROR))
+
+	suite.controller.ResendVerificationEmail(suite.mockContext)
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+}
+
+// When the email is already verified, return a conflict
+func (suite *EmailVerificationControllerUnitTestSuite) TestResendVerificationEmailAlreadyVerified_ReturnsConflict() {
+
+	suite.emailVerificationServiceMock.On("ResendVerificationEmail", mock.Anything).Return(false, nil)
+
+	suite.controller.ResendVerificationEmail(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+func (suite *EmailVerificationControllerUnitTestSuite) TestResendVerificationEmail_ReturnsAccepted() {
+
+	suite.emailVerificationServiceMock.On("ResendVerificationEmail", mock.Anything).Return(true, nil)
+
+	suite.controller.ResendVerificationEmail(suite.mockContext)
+
+	suite.emailVerificationServiceMock.AssertCalled(suite.T(), "ResendVerificationEmail", int64(12))
+	suite.Equal(http.StatusAccepted, suite.mockResponseWriter.Code)
+}
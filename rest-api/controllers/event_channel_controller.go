@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	serviceInterfaces "example.com/interfaces/services"
+	"example.com/lib"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	channelPingInterval = time.Second * 30
+	//clients have to answer pings (or send anything else) within this window
+	channelReadTimeout  = channelPingInterval * 2
+	channelWriteTimeout = time.Second * 10
+	//matches the binding on models.Announcement
+	maxAnnouncementLength = 1000
+)
+
+type EventChannelController struct {
+	eventService        serviceInterfaces.IEventService
+	registrationService serviceInterfaces.IRegistrationService
+	tokenService        serviceInterfaces.ITokenService
+	eventChannelHub     libInterfaces.IEventChannelHub
+	eventBroadcaster    libInterfaces.IEventBroadcaster
+	//origin browsers may open the channel from, the api's own host when not configured
+	allowedOrigin string
+}
+
+func (controller EventChannelController) Connect(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	//browsers cannot set headers when opening a websocket, so the token may be sent as a query parameter
+	authToken, ok := lib.ExtractBearerToken(context.GetHeader("Authorization"))
+
+	if !ok {
+		authToken = context.Query("access_token")
+	}
+
+	if authToken == "" {
+		context.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := controller.tokenService.ValidateAccessToken(authToken)
+
+	if err != nil {
+		context.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	userId := claims.UserId
+
+	event, err := controller.eventService.GetEventById(eventId, claims.OrganizationId)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Error trying to fetch event by id, error: %v\n", err),
+		})
+		return
+	}
+
+	if event.Id == 0 {
+		context.JSON(http.StatusNotFound, nil)
+		return
+	}
+
+	organizer := event.UserId == userId
+
+	if !organizer {
+		registered, err := controller.registrationService.IsRegistered(eventId, userId, claims.OrganizationId)
+
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+			return
+		}
+
+		if !registered {
+			context.JSON(http.StatusForbidden, gin.H{
+				"error": "Only registered users can join the event channel",
+			})
+			return
+		}
+	}
+
+	server := websocket.Server{
+		Handshake: controller.checkOrigin,
+		Handler: func(connection *websocket.Conn) {
+			controller.serve(connection, eventId, userId, organizer)
+		},
+	}
+
+	server.ServeHTTP(context.Writer, context.Request)
+}
+
+func (controller EventChannelController) Announce(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	var announcement models.Announcement
+
+	err := context.ShouldBindJSON(&announcement)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid announcement",
+		})
+		return
+	}
+
+	event, err := controller.eventService.GetEventById(eventId, context.GetInt64("organizationId"))
+
+	if err != nil {
+		context.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if event.Id == 0 {
+		context.JSON(http.StatusNotFound, nil)
+		return
+	}
+
+	if event.UserId != context.GetInt64("userId") {
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the organizer can make announcements",
+		})
+		return
+	}
+
+	controller.eventChannelHub.Broadcast(eventId, models.EventChannelMessage{
+		Type:    models.CHANNEL_ANNOUNCEMENT_MESSAGE,
+		Message: announcement.Message,
+	})
+
+	context.JSON(http.StatusAccepted, gin.H{
+		"message":         "Announcement sent",
+		"connected_count": controller.eventChannelHub.ConnectedCount(eventId),
+	})
+}
+
+// Browsers attach cookies and credentials to websockets opened by any page, so connections opened
+// from other sites are refused. Clients other than browsers send no origin and are let through,
+// they authenticate with the token like any other request
+func (controller EventChannelController) checkOrigin(websocketConfig *websocket.Config, request *http.Request) error {
+	origin, err := websocket.Origin(websocketConfig, request)
+
+	if err != nil {
+		return err
+	}
+
+	if origin == nil {
+		return nil
+	}
+
+	allowedHost := request.Host
+
+	if controller.allowedOrigin != "" {
+		allowedOrigin, err := url.Parse(controller.allowedOrigin)
+
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(origin.Scheme, allowedOrigin.Scheme) {
+			return errors.New(constants.ORIGIN_NOT_ALLOWED_ERROR)
+		}
+
+		allowedHost = allowedOrigin.Host
+	}
+
+	if !strings.EqualFold(origin.Host, allowedHost) {
+		return errors.New(constants.ORIGIN_NOT_ALLOWED_ERROR)
+	}
+
+	return nil
+}
+
+func (controller EventChannelController) serve(connection *websocket.Conn, eventId, userId int64, organizer bool) {
+	defer connection.Close()
+
+	client := controller.eventChannelHub.Join(eventId, userId, organizer)
+
+	defer controller.eventChannelHub.Leave(client)
+
+	var lastNotificationId uint64
+
+	subscription := controller.eventBroadcaster.Subscribe(eventId, lastNotificationId)
+
+	defer func() {
+		controller.eventBroadcaster.Unsubscribe(subscription)
+	}()
+
+	//replies to a single client are funneled through the writer so only one goroutine writes
+	replies := make(chan models.EventChannelMessage, 1)
+	disconnected := make(chan struct{})
+
+	go controller.receive(connection, client, replies, disconnected)
+
+	ping := time.NewTicker(channelPingInterval)
+
+	defer ping.Stop()
+
+	for {
+		var message models.EventChannelMessage
+
+		select {
+		case <-disconnected:
+			return
+		case reply := <-replies:
+			message = reply
+		case channelMessage, open := <-client.Messages:
+			if !open {
+				send(connection, models.EventChannelMessage{
+					Type:    models.CHANNEL_ERROR_MESSAGE,
+					Message: "disconnected for not keeping up with the channel",
+				})
+				return
+			}
+
+			message = channelMessage
+		case notification, open := <-subscription.Notifications:
+			//the broadcaster drops subscribers that fall behind, the missed registration
+			//counts are superseded by later ones so resubscribing is enough
+			if !open {
+				subscription = controller.eventBroadcaster.Subscribe(eventId, lastNotificationId)
+				continue
+			}
+
+			lastNotificationId = notification.Id
+
+			if notification.Type == models.EVENT_DELETED_NOTIFICATION {
+				send(connection, models.EventChannelMessage{
+					Type:    models.CHANNEL_ERROR_MESSAGE,
+					Message: "the event has been deleted",
+				})
+				return
+			}
+
+			if notification.Type != models.EVENT_REGISTRATIONS_NOTIFICATION {
+				continue
+			}
+
+			connectedCount := controller.eventChannelHub.ConnectedCount(eventId)
+
+			message = models.EventChannelMessage{
+				Type:              models.CHANNEL_ATTENDANCE_MESSAGE,
+				ConnectedCount:    &connectedCount,
+				RegistrationCount: notification.RegistrationCount,
+			}
+		case <-ping.C:
+			message = models.EventChannelMessage{
+				Type: models.CHANNEL_PING_MESSAGE,
+			}
+		}
+
+		if send(connection, message) != nil {
+			return
+		}
+	}
+}
+
+func (controller EventChannelController) receive(
+	connection *websocket.Conn,
+	client *models.EventChannelClient,
+	replies chan<- models.EventChannelMessage,
+	disconnected chan<- struct{}) {
+	defer close(disconnected)
+
+	for {
+		connection.SetReadDeadline(time.Now().Add(channelReadTimeout))
+
+		var message models.EventChannelMessage
+
+		err := websocket.JSON.Receive(connection, &message)
+
+		if err != nil {
+			return
+		}
+
+		var reply models.EventChannelMessage
+
+		switch message.Type {
+		case models.CHANNEL_PONG_MESSAGE:
+			continue
+		case models.CHANNEL_PING_MESSAGE:
+			reply = models.EventChannelMessage{Type: models.CHANNEL_PONG_MESSAGE}
+		case models.CHANNEL_ANNOUNCEMENT_MESSAGE:
+			if !client.Organizer {
+				reply = models.EventChannelMessage{
+					Type:    models.CHANNEL_ERROR_MESSAGE,
+					Message: "only the organizer can make announcements",
+				}
+				break
+			}
+
+			if message.Message == "" || len(message.Message) > maxAnnouncementLength {
+				reply = models.EventChannelMessage{
+					Type:    models.CHANNEL_ERROR_MESSAGE,
+					Message: "invalid announcement",
+				}
+				break
+			}
+
+			controller.eventChannelHub.Broadcast(client.EventId, models.EventChannelMessage{
+				Type:    models.CHANNEL_ANNOUNCEMENT_MESSAGE,
+				Message: message.Message,
+			})
+			continue
+		default:
+			reply = models.EventChannelMessage{
+				Type:    models.CHANNEL_ERROR_MESSAGE,
+				Message: "unknown message type",
+			}
+		}
+
+		reply.Timestamp = time.Now().UTC()
+
+		//a client flooding the channel faster than replies are written only loses replies
+		select {
+		case replies <- reply:
+		default:
+		}
+	}
+}
+
+func send(connection *websocket.Conn, message models.EventChannelMessage) error {
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now().UTC()
+	}
+
+	connection.SetWriteDeadline(time.Now().Add(channelWriteTimeout))
+
+	return websocket.JSON.Send(connection, message)
+}
+
+func NewEventChannelController(
+	eventService serviceInterfaces.IEventService,
+	registrationService serviceInterfaces.IRegistrationService,
+	tokenService serviceInterfaces.ITokenService,
+	eventChannelHub libInterfaces.IEventChannelHub,
+	eventBroadcaster libInterfaces.IEventBroadcaster) *EventChannelController {
+	return &EventChannelController{
+		eventService:        eventService,
+		registrationService: registrationService,
+		tokenService:        tokenService,
+		eventChannelHub:     eventChannelHub,
+		eventBroadcaster:    eventBroadcaster,
+		allowedOrigin:       config.AppConfiguration().PublicUrl(),
+	}
+}
@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/websocket"
+)
+
+type EventChannelControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext             *gin.Context
+	eventServiceMock        mocks.IEventService
+	registrationServiceMock mocks.IRegistrationService
+	tokenServiceMock        mocks.ITokenService
+	eventChannelHubMock     mocks.IEventChannelHub
+	eventBroadcasterMock    mocks.IEventBroadcaster
+	mockResponseWriter      *httptest.ResponseRecorder
+	controller              *EventChannelController
+}
+
+func TestEventChannelControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &EventChannelControllerUnitTestSuite{})
+}
+
+func (suite *EventChannelControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/channel", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.eventServiceMock = mocks.IEventService{}
+	suite.registrationServiceMock = mocks.IRegistrationService{}
+	suite.tokenServiceMock = mocks.ITokenService{}
+	suite.eventChannelHubMock = mocks.IEventChannelHub{}
+	suite.eventBroadcasterMock = mocks.IEventBroadcaster{}
+
+	suite.controller = NewEventChannelController(
+		&suite.eventServiceMock,
+		&suite.registrationServiceMock,
+		&suite.tokenServiceMock,
+		&suite.eventChannelHubMock,
+		&suite.eventBroadcasterMock)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestConnectMalformedParam_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "bar",
+		},
+	}
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When no token is provided in either the header or the query, the connection is refused
+func (suite *EventChannelControllerUnitTestSuite) TestConnectWithoutToken_ReturnsUnauthorized() {
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestConnectWithInvalidToken_ReturnsUnauthorized() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "ValidateAccessToken", "some-token")
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestConnectWithQueryToken_ValidatesTheToken() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/channel?access_token=some%20token", nil)
+
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "ValidateAccessToken", "some token")
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestConnect_ReturnsNotFound() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+// When the user neither organizes nor is registered for the event, it should return forbidden
+func (suite *EventChannelControllerUnitTestSuite) TestConnectNotRegistered_ReturnsForbidden() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.registrationServiceMock.On("IsRegistered", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.registrationServiceMock.AssertCalled(suite.T(), "IsRegistered", int64(1), int64(12), int64(0))
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestConnectWhenUnableToCheckRegistration_ReturnsInternalServerError() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.registrationServiceMock.On("IsRegistered", mock.Anything, mock.Anything, mock.Anything).Return(false, errors.New("test"))
+
+	suite.controller.Connect(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// When the user is not the organizer, the announcement should be refused
+func (suite *EventChannelControllerUnitTestSuite) TestAnnounceNotTheOrganizer_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.Announcement{Message: "room changed to 4B"}, suite.mockContext)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	suite.controller.Announce(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+	suite.eventChannelHubMock.AssertNotCalled(suite.T(), "Broadcast", mock.Anything, mock.Anything)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestAnnounceMissingMessage_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.Announcement{}, suite.mockContext)
+
+	suite.controller.Announce(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *EventChannelControllerUnitTestSuite) TestAnnounce_BroadcastsTheAnnouncement() {
+
+	test_utils.SetRequestBody(models.Announcement{Message: "room changed to 4B"}, suite.mockContext)
+
+	suite.mockContext.Set("userId", int64(3))
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.eventChannelHubMock.On("Broadcast", mock.Anything, mock.Anything).Return()
+	suite.eventChannelHubMock.On("ConnectedCount", mock.Anything).Return(int64(4))
+
+	suite.controller.Announce(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusAccepted, response.StatusCode)
+	suite.Contains(response.Body, `"connected_count":4`)
+	suite.eventChannelHubMock.AssertCalled(suite.T(), "Broadcast", int64(1), models.EventChannelMessage{
+		Type:    models.CHANNEL_ANNOUNCEMENT_MESSAGE,
+		Message: "room changed to 4B",
+	})
+}
+
+// Runs the channel over a real connection, verifying organizers can broadcast to the channel
+// while attendees cannot
+func (suite *EventChannelControllerUnitTestSuite) TestConnect_RestrictsAnnouncementsToOrganizers() {
+
+	controller := NewEventChannelController(
+		&suite.eventServiceMock,
+		&suite.registrationServiceMock,
+		&suite.tokenServiceMock,
+		lib.NewEventChannelHub(),
+		lib.NewEventBroadcaster())
+
+	suite.tokenServiceMock.On("ValidateAccessToken", "organizer").Return(&models.AccessTokenClaims{UserId: 3}, nil)
+	suite.tokenServiceMock.On("ValidateAccessToken", "attendee").Return(&models.AccessTokenClaims{UserId: 12}, nil)
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.registrationServiceMock.On("IsRegistered", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	server := gin.New()
+	server.GET("/events/:id/channel", controller.Connect)
+
+	httpServer := httptest.NewServer(server)
+
+	defer httpServer.Close()
+
+	connect := func(token string) *websocket.Conn {
+		url := fmt.Sprintf("%v/events/1/channel?access_token=%v", strings.Replace(httpServer.URL, "http", "ws", 1), token)
+
+		connection, err := websocket.Dial(url, "", httpServer.URL)
+
+		suite.Require().Nil(err)
+
+		connection.SetDeadline(time.Now().Add(time.Second * 5))
+
+		return connection
+	}
+
+	//skips messages until one of the expected type arrives
+	receive := func(connection *websocket.Conn, messageType string) models.EventChannelMessage {
+		for {
+			var message models.EventChannelMessage
+
+			suite.Require().Nil(websocket.JSON.Receive(connection, &message))
+
+			if message.Type == messageType {
+				return message
+			}
+		}
+	}
+
+	organizer := connect("organizer")
+
+	defer organizer.Close()
+
+	receive(organizer, models.CHANNEL_ATTENDANCE_MESSAGE)
+
+	attendee := connect("attendee")
+
+	defer attendee.Close()
+
+	attendance := receive(organizer, models.CHANNEL_ATTENDANCE_MESSAGE)
+
+	suite.Equal(int64(2), *attendance.ConnectedCount)
+
+	websocket.JSON.Send(attendee, models.EventChannelMessage{
+		Type:    models.CHANNEL_ANNOUNCEMENT_MESSAGE,
+		Message: "not allowed",
+	})
+
+	suite.Equal("only the organizer can make announcements", receive(attendee, models.CHANNEL_ERROR_MESSAGE).Message)
+
+	websocket.JSON.Send(organizer, models.EventChannelMessage{
+		Type:    models.CHANNEL_ANNOUNCEMENT_MESSAGE,
+		Message: "room changed to 4B",
+	})
+
+	suite.Equal("room changed to 4B", receive(attendee, models.CHANNEL_ANNOUNCEMENT_MESSAGE).Message)
+}
+
+// Pages of other sites cannot open the channel with the credentials of the user
+func (suite *EventChannelControllerUnitTestSuite) TestConnectFromAnotherOrigin_RefusesTheConnection() {
+
+	suite.tokenServiceMock.On("ValidateAccessToken", "organizer").Return(&models.AccessTokenClaims{UserId: 3}, nil)
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	server := gin.New()
+	server.GET("/events/:id/channel", suite.controller.Connect)
+
+	httpServer := httptest.NewServer(server)
+
+	defer httpServer.Close()
+
+	url := fmt.Sprintf("%v/events/1/channel?access_token=organizer", strings.Replace(httpServer.URL, "http", "ws", 1))
+
+	_, err := websocket.Dial(url, "", "https://attacker.example")
+
+	suite.NotNil(err)
+	suite.eventChannelHubMock.AssertNotCalled(suite.T(), "Join", mock.Anything, mock.Anything, mock.Anything)
+}
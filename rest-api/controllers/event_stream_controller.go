@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	libInterfaces "example.com/interfaces/lib"
+	serviceInterfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const streamHeartbeatInterval = time.Second * 15
+
+type EventStreamController struct {
+	eventService     serviceInterfaces.IEventService
+	eventBroadcaster libInterfaces.IEventBroadcaster
+}
+
+func (controller EventStreamController) StreamEvents(context *gin.Context) {
+	controller.stream(context, 0)
+}
+
+func (controller EventStreamController) StreamEvent(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	//authentication is optional, anonymous viewers need an invitation token to stream private events
+	//and stay in the personal space, events of organizations are streamed to their members only
+	event, err := controller.eventService.GetVisibleEventById(eventId, context.GetInt64("userId"), context.GetInt64("organizationId"), context.Query("invite"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Error trying to fetch event by id, error: %v\n", err),
+		})
+		return
+	}
+
+	if event.Id == 0 {
+		context.JSON(http.StatusNotFound, nil)
+		return
+	}
+
+	controller.stream(context, eventId)
+}
+
+func (controller EventStreamController) stream(context *gin.Context, eventId int64) {
+	subscription := controller.eventBroadcaster.Subscribe(eventId, lastNotificationId(context))
+
+	defer controller.eventBroadcaster.Unsubscribe(subscription)
+
+	context.Header("Cache-Control", "no-cache")
+	context.Header("Connection", "keep-alive")
+	//disables response buffering when running behind nginx
+	context.Header("X-Accel-Buffering", "no")
+	context.Status(http.StatusOK)
+
+	if subscription.Incomplete {
+		context.Render(-1, sse.Event{
+			Event: "reset",
+			Data:  "notifications were missed, reload the current state",
+		})
+	}
+
+	for _, notification := range subscription.Backlog {
+		if streamable(eventId, notification) {
+			renderNotification(context, notification)
+		}
+	}
+
+	context.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-context.Request.Context().Done():
+			return
+		case notification, open := <-subscription.Notifications:
+			//closed by the broadcaster when the client is unable to keep up, the
+			//client is expected to reconnect using the last id it received
+			if !open {
+				return
+			}
+
+			if !streamable(eventId, notification) {
+				continue
+			}
+
+			renderNotification(context, notification)
+		case <-heartbeat.C:
+			//comment lines are ignored by clients, but keep proxies from closing the connection
+			context.Writer.WriteString(": heartbeat\n\n")
+		}
+
+		context.Writer.Flush()
+	}
+}
+
+// The stream of all events only carries public events of the personal space, streams of a single
+// event were already checked for visibility when opened
+func streamable(eventId int64, notification models.EventNotification) bool {
+	return eventId != 0 || (notification.Visibility == models.PUBLIC_VISIBILITY && notification.OrganizationId == 0)
+}
+
+func renderNotification(context *gin.Context, notification models.EventNotification) {
+	context.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(notification.Id, 10),
+		Event: notification.Type,
+		Data:  notification,
+	})
+}
+
+// Browsers send the Last-Event-ID header on reconnect, the query parameter allows
+// clients that cannot set headers to resume a stream as well
+func lastNotificationId(context *gin.Context) uint64 {
+	lastEventId := context.GetHeader("Last-Event-ID")
+
+	if lastEventId == "" {
+		lastEventId = context.Query("last_event_id")
+	}
+
+	id, err := strconv.ParseUint(lastEventId, 10, 64)
+
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+func NewEventStreamController(
+	eventService serviceInterfaces.IEventService,
+	eventBroadcaster libInterfaces.IEventBroadcaster) *EventStreamController {
+	return &EventStreamController{
+		eventService:     eventService,
+		eventBroadcaster: eventBroadcaster,
+	}
+}
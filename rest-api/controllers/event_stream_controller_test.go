@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventStreamControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext          *gin.Context
+	eventServiceMock     mocks.IEventService
+	eventBroadcasterMock mocks.IEventBroadcaster
+	mockResponseWriter   *httptest.ResponseRecorder
+	controller           *EventStreamController
+}
+
+func TestEventStreamControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &EventStreamControllerUnitTestSuite{})
+}
+
+func (suite *EventStreamControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/stream", nil)
+
+	suite.eventServiceMock = mocks.IEventService{}
+	suite.eventBroadcasterMock = mocks.IEventBroadcaster{}
+
+	suite.eventBroadcasterMock.On("Unsubscribe", mock.Anything).Return()
+
+	suite.controller = NewEventStreamController(&suite.eventServiceMock, &suite.eventBroadcasterMock)
+}
+
+// Builds a subscription whose live feed is already closed, so the stream ends after the
+// provided notifications have been written
+func closedSubscription(backlog []models.EventNotification, live ...models.EventNotification) *models.EventSubscription {
+	notifications := make(chan models.EventNotification, len(live))
+
+	for _, notification := range live {
+		notifications <- notification
+	}
+
+	close(notifications)
+
+	return &models.EventSubscription{
+		Backlog:       backlog,
+		Notifications: notifications,
+	}
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvents_SubscribesToAllEvents() {
+
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(nil))
+
+	suite.controller.StreamEvents(suite.mockContext)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Subscribe", int64(0), uint64(0))
+	suite.eventBroadcasterMock.AssertNumberOfCalls(suite.T(), "Unsubscribe", 1)
+}
+
+// When reconnecting, the Last-Event-ID header should be used to resume the stream
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEventsWithLastEventId_ResumesTheStream() {
+
+	suite.mockContext.Request.Header.Set("Last-Event-ID", "42")
+
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(nil))
+
+	suite.controller.StreamEvents(suite.mockContext)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Subscribe", int64(0), uint64(42))
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvents_WritesBacklogAndLiveNotifications() {
+
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(
+		[]models.EventNotification{
+			{Id: 1, Type: models.EVENT_CREATED_NOTIFICATION, EventId: 3, Visibility: models.PUBLIC_VISIBILITY},
+		},
+		models.EventNotification{Id: 2, Type: models.EVENT_DELETED_NOTIFICATION, EventId: 3, Visibility: models.PUBLIC_VISIBILITY},
+	))
+
+	suite.controller.StreamEvents(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("text/event-stream", suite.mockResponseWriter.Header().Get("Content-Type"))
+	suite.Contains(response.Body, "id:1\nevent:created\n")
+	suite.Contains(response.Body, "id:2\nevent:deleted\n")
+}
+
+// Notifications of unlisted and private events should be left out of the stream of all events
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvents_SkipsNotificationsOfHiddenEvents() {
+
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(
+		[]models.EventNotification{
+			{Id: 1, Type: models.EVENT_CREATED_NOTIFICATION, EventId: 3, Visibility: models.PRIVATE_VISIBILITY},
+		},
+		models.EventNotification{Id: 2, Type: models.EVENT_CREATED_NOTIFICATION, EventId: 4, Visibility: models.UNLISTED_VISIBILITY},
+	))
+
+	suite.controller.StreamEvents(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.NotContains(response.Body, "id:1\n")
+	suite.NotContains(response.Body, "id:2\n")
+}
+
+// When the requested notifications are no longer buffered, the client should be told to reload
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEventsWhenIncomplete_WritesReset() {
+
+	subscription := closedSubscription(nil)
+	subscription.Incomplete = true
+
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(subscription)
+
+	suite.controller.StreamEvents(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Contains(response.Body, "event:reset\n")
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEventMalformedParam_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "bar",
+		},
+	}
+
+	suite.controller.StreamEvent(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvent_ReturnsInternalServerError() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.StreamEvent(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusInternalServerError, response.StatusCode)
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvent_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	suite.controller.StreamEvent(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusNotFound, response.StatusCode)
+	suite.eventBroadcasterMock.AssertNotCalled(suite.T(), "Subscribe", mock.Anything, mock.Anything)
+}
+
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEvent_SubscribesToTheEvent() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(nil))
+
+	suite.controller.StreamEvent(suite.mockContext)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Subscribe", int64(1), uint64(0))
+}
+
+// Organizers stream their private events without an invitation token
+func (suite *EventStreamControllerUnitTestSuite) TestStreamEventAuthenticated_ChecksVisibilityForTheViewer() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(3))
+	suite.mockContext.Set("organizationId", int64(4))
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.eventBroadcasterMock.On("Subscribe", mock.Anything, mock.Anything).Return(closedSubscription(nil))
+
+	suite.controller.StreamEvent(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetVisibleEventById", int64(1), int64(3), int64(4), "")
+}
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	interfaces "example.com/interfaces/services"
 	"example.com/models"
@@ -14,9 +18,22 @@ type EventsController struct {
 	eventService interfaces.IEventService
 }
 
+const (
+	defaultSearchRadiusKm = 10
+	//half of the earth's circumference, any larger radius covers the whole planet
+	maxSearchRadiusKm = 20038
+)
+
 func (controller EventsController) GetEvents(context *gin.Context) {
 
-	events, err := controller.eventService.GetEvents()
+	if context.Query("near") != "" {
+		controller.getEventsNear(context)
+		return
+	}
+
+	//the viewer is anonymous unless a user id was set by an authentication middleware, anonymous
+	//viewers only see events of the personal space
+	events, err := controller.eventService.GetEvents(context.GetInt64("userId"), context.GetInt64("organizationId"))
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -34,6 +51,14 @@ func (controller EventsController) AddEvent(context *gin.Context) {
 
 	err := context.ShouldBindJSON(&event)
 
+	if err == nil && (event.Latitude == nil) != (event.Longitude == nil) {
+		err = errors.New("latitude and longitude must be provided together")
+	}
+
+	if err == nil && event.EndDate != nil && !event.EndDate.After(event.Date) {
+		err = errors.New("end_date must be after date")
+	}
+
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{
 			"message": "Invalid request",
@@ -42,6 +67,8 @@ func (controller EventsController) AddEvent(context *gin.Context) {
 	}
 
 	event.UserId = context.GetInt64("userId")
+	//events are created in the organization the token acts in
+	event.OrganizationId = context.GetInt64("organizationId")
 
 	err = controller.eventService.SaveEvent(&event)
 
@@ -49,6 +76,7 @@ func (controller EventsController) AddEvent(context *gin.Context) {
 		context.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
 		})
+		return
 	}
 
 	context.JSON(http.StatusCreated, gin.H{
@@ -67,7 +95,12 @@ func (controller EventsController) GetEventById(context *gin.Context) {
 		return
 	}
 
-	event, err := controller.eventService.GetEventById(eventId)
+	//private events can be opened through an invitation link
+	event, err := controller.eventService.GetVisibleEventById(
+		eventId,
+		context.GetInt64("userId"),
+		context.GetInt64("organizationId"),
+		context.Query("invite"))
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -77,6 +110,7 @@ func (controller EventsController) GetEventById(context *gin.Context) {
 	}
 
 	//Given that sqlite will auto create ids, if it is 0, then it "does not exist"
+	//hidden events are reported the same way
 	if event.Id == 0 {
 		context.JSON(http.StatusNotFound, nil)
 		return
@@ -97,29 +131,47 @@ func (controller EventsController) UpdateEvent(context *gin.Context) {
 	var event models.Event
 	err := context.ShouldBindJSON(&event)
 
+	if err == nil && (event.Latitude == nil) != (event.Longitude == nil) {
+		err = errors.New("latitude and longitude must be provided together")
+	}
+
+	if err == nil && event.EndDate != nil && !event.EndDate.After(event.Date) {
+		err = errors.New("end_date must be after date")
+	}
+
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid event",
 		})
+		return
 	}
 
-	savedEvent, err := controller.eventService.GetEventById(eventId)
+	savedEvent, err := controller.eventService.GetEventById(eventId, context.GetInt64("organizationId"))
 
 	if err != nil {
 		context.Status(http.StatusInternalServerError)
 		return
 	}
 
-	requestingUserId := context.GetInt64("userId")
+	if savedEvent.Id == 0 {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": "Event not found",
+		})
+		return
+	}
 
-	if savedEvent.UserId != requestingUserId {
-		context.JSON(http.StatusUnauthorized, gin.H{
+	if !canManageEvent(context, *savedEvent) {
+		context.JSON(http.StatusForbidden, gin.H{
 			"error": "User unable to update event",
 		})
 		return
 	}
 
-	err = controller.eventService.UpdateEvent(eventId, event)
+	if event.Visibility == "" {
+		event.Visibility = savedEvent.Visibility
+	}
+
+	err = controller.eventService.UpdateEvent(eventId, context.GetInt64("organizationId"), event)
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -143,23 +195,28 @@ func (controller EventsController) DeleteEvent(context *gin.Context) {
 		return
 	}
 
-	savedEvent, err := controller.eventService.GetEventById(eventId)
+	savedEvent, err := controller.eventService.GetEventById(eventId, context.GetInt64("organizationId"))
 
 	if err != nil {
 		context.Status(http.StatusInternalServerError)
 		return
 	}
 
-	requestingUserId := context.GetInt64("userId")
+	if savedEvent.Id == 0 {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": "Event not found",
+		})
+		return
+	}
 
-	if savedEvent.UserId != requestingUserId {
-		context.JSON(http.StatusUnauthorized, gin.H{
-			"error": "User unable to update event",
+	if !canManageEvent(context, *savedEvent) {
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "User unable to delete event",
 		})
 		return
 	}
 
-	err = controller.eventService.DeleteEvent(eventId)
+	err = controller.eventService.DeleteEvent(eventId, context.GetInt64("organizationId"))
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -173,6 +230,96 @@ func (controller EventsController) DeleteEvent(context *gin.Context) {
 	})
 }
 
+// Organizers manage their own events, admins of an organization manage the events of the
+// organization and users granted the permission manage any event of the tenant they act in
+func canManageEvent(context *gin.Context, event models.Event) bool {
+	claims := getTokenClaims(context)
+
+	return event.UserId == context.GetInt64("userId") ||
+		(event.OrganizationId != 0 && claims.OrganizationRole == models.ORGANIZATION_ADMIN_ROLE) ||
+		claims.HasPermission(models.MANAGE_ANY_EVENT_PERMISSION)
+}
+
+func (controller EventsController) getEventsNear(context *gin.Context) {
+	latitude, longitude, err := parseCoordinates(context.Query("near"))
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid near parameter, expected latitude,longitude",
+		})
+		return
+	}
+
+	radiusKm, err := strconv.ParseFloat(context.DefaultQuery("radius_km", strconv.Itoa(defaultSearchRadiusKm)), 64)
+
+	//NaN passes no comparison, so it is refused before the range is checked
+	if err != nil || !isFinite(radiusKm) || radiusKm <= 0 || radiusKm > maxSearchRadiusKm {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid radius_km parameter",
+		})
+		return
+	}
+
+	sortBy := context.DefaultQuery("sort", "distance")
+
+	if sortBy != "distance" && sortBy != "date" {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid sort parameter, expected distance or date",
+		})
+		return
+	}
+
+	events, err := controller.eventService.GetEventsNear(
+		latitude,
+		longitude,
+		radiusKm,
+		context.GetInt64("userId"),
+		context.GetInt64("organizationId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	//events are returned closest first
+	if sortBy == "date" {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Date.Before(events[j].Date)
+		})
+	}
+
+	context.JSON(http.StatusOK, events)
+}
+
+func parseCoordinates(coordinates string) (float64, float64, error) {
+	parts := strings.Split(coordinates, ",")
+
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid coordinates")
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+
+	if err != nil || !isFinite(latitude) || latitude < -90 || latitude > 90 {
+		return 0, 0, errors.New("invalid latitude")
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+
+	if err != nil || !isFinite(longitude) || longitude < -180 || longitude > 180 {
+		return 0, 0, errors.New("invalid longitude")
+	}
+
+	return latitude, longitude, nil
+}
+
+// ParseFloat accepts "NaN" and "Inf"
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
 func NewEventsController(eventService interfaces.IEventService) *EventsController {
 	return &EventsController{
 		eventService: eventService,
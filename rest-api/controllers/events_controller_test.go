@@ -42,7 +42,7 @@ func (suite *EventsControllerUnitTestSuite) SetupTest() {
 // Verify an internal server error is returned when an error occurs fetching events
 func (suite *EventsControllerUnitTestSuite) TestGetEvents_ReturnsInternalServerError() {
 
-	suite.eventServiceMock.On("GetEvents").Return(nil, errors.New("test error"))
+	suite.eventServiceMock.On("GetEvents", mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
 
 	suite.controller.GetEvents(suite.mockContext)
 
@@ -52,14 +52,26 @@ func (suite *EventsControllerUnitTestSuite) TestGetEvents_ReturnsInternalServerE
 
 func (suite *EventsControllerUnitTestSuite) TestGetEvents_FetchesEvents() {
 
-	suite.eventServiceMock.On("GetEvents").Return([]models.Event{}, nil)
+	suite.eventServiceMock.On("GetEvents", mock.Anything, mock.Anything).Return([]models.Event{}, nil)
 
 	suite.controller.GetEvents(suite.mockContext)
 
-	suite.eventServiceMock.AssertCalled(suite.T(), "GetEvents")
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEvents", int64(0), int64(0))
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "GetEvents", 1)
 }
 
+func (suite *EventsControllerUnitTestSuite) TestGetEventsInAnOrganization_FetchesTheOrganizationEvents() {
+
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("organizationId", int64(4))
+
+	suite.eventServiceMock.On("GetEvents", mock.Anything, mock.Anything).Return([]models.Event{}, nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEvents", int64(12), int64(4))
+}
+
 func (suite *EventsControllerUnitTestSuite) TestGetEvents_ReturnsOk() {
 
 	expectedDate, _ := time.Parse(time.RFC3339, "1990-01-01T00:00:00.000Z")
@@ -70,7 +82,7 @@ func (suite *EventsControllerUnitTestSuite) TestGetEvents_ReturnsOk() {
 		Date:        expectedDate,
 	}
 
-	suite.eventServiceMock.On("GetEvents").Return([]models.Event{
+	suite.eventServiceMock.On("GetEvents", mock.Anything, mock.Anything).Return([]models.Event{
 		mockEvent,
 	}, nil)
 
@@ -121,6 +133,27 @@ func (suite *EventsControllerUnitTestSuite) TestAddEvents_AttemptsToSaveTheNewEv
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "SaveEvent", 1)
 }
 
+// Events are created in the organization the token acts in
+func (suite *EventsControllerUnitTestSuite) TestAddEventsInAnOrganization_SavesTheOrganization() {
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+	}, suite.mockContext)
+
+	suite.mockContext.Set("organizationId", int64(4))
+
+	suite.eventServiceMock.On("SaveEvent", mock.Anything).Return(nil)
+
+	suite.controller.AddEvent(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "SaveEvent", mock.MatchedBy(func(event *models.Event) bool {
+		return event.OrganizationId == 4
+	}))
+}
+
 // When failing to save the new event, we have to return an error
 func (suite *EventsControllerUnitTestSuite) TestAddEvents_ReturnsInternalServerError() {
 
@@ -140,6 +173,7 @@ func (suite *EventsControllerUnitTestSuite) TestAddEvents_ReturnsInternalServerE
 	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
 
 	suite.Equal(response.StatusCode, http.StatusInternalServerError)
+	suite.NotContains(response.Body, `"message":"Created"`)
 }
 
 func (suite *EventsControllerUnitTestSuite) TestAddEvents_ReturnsCreated() {
@@ -208,16 +242,38 @@ func (suite *EventsControllerUnitTestSuite) TestGetEventById_AttemptsToFetchTheE
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.GetEventById(suite.mockContext)
 
 	//Type is validated as well, so we need to have the right type of int
-	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventById", int64(1))
-	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetVisibleEventById", int64(1), int64(0), int64(0), "")
+	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "GetVisibleEventById", 1)
 }
 
 // When an error is returned trying to fetch the event, returns an internal server error
+// The invitation token and the viewer are passed on, so private events can be opened through invitation links
+func (suite *EventsControllerUnitTestSuite) TestGetEventByIdWithInvitation_PassesTheToken() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1?invite=some-token", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	suite.controller.GetEventById(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetVisibleEventById", int64(1), int64(12), int64(0), "some-token")
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
 func (suite *EventsControllerUnitTestSuite) TestGetEventById_ReturnsInternalServerError() {
 
 	suite.mockContext.Params = gin.Params{
@@ -227,7 +283,7 @@ func (suite *EventsControllerUnitTestSuite) TestGetEventById_ReturnsInternalServ
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.GetEventById(suite.mockContext)
 
@@ -247,7 +303,7 @@ func (suite *EventsControllerUnitTestSuite) TestGetEventById_ReturnsNotFound() {
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{}, nil)
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.Event{}, nil)
 
 	suite.controller.GetEventById(suite.mockContext)
 
@@ -270,7 +326,7 @@ func (suite *EventsControllerUnitTestSuite) TestGetEventById_ReturnsOk() {
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&expectedEvent, nil)
+	suite.eventServiceMock.On("GetVisibleEventById", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&expectedEvent, nil)
 
 	suite.controller.GetEventById(suite.mockContext)
 
@@ -346,18 +402,25 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_AttemptsToFetchTheEv
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+	}, suite.mockContext)
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.UpdateEvent(suite.mockContext)
 
 	//Type is validated as well, so we need to have the right type of int
-	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventById", int64(1))
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventById", int64(1), int64(0))
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
 }
 
 // When the user attempting to update the event, attempts to update the event, it should return
 // unauthorized
-func (suite *EventsControllerUnitTestSuite) TestUpdateEventNotTheCreator_ReturnsUnauthorized() {
+func (suite *EventsControllerUnitTestSuite) TestUpdateEventNotTheCreator_ReturnsForbidden() {
 
 	suite.mockContext.Params = gin.Params{
 		{
@@ -377,7 +440,7 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEventNotTheCreator_Returns
 
 	suite.mockContext.Set("userId", int64(1))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
@@ -386,7 +449,42 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEventNotTheCreator_Returns
 
 	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
 
-	suite.Equal(http.StatusUnauthorized, response.StatusCode)
+	suite.Equal(http.StatusForbidden, response.StatusCode)
+}
+
+// Users allowed to manage any event can update events organized by others
+func (suite *EventsControllerUnitTestSuite) TestUpdateEventWithManagePermission_UpdatesTheEvent() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+	}, suite.mockContext)
+
+	suite.mockContext.Set("userId", int64(1))
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{
+		UserId:      1,
+		Permissions: []string{models.MANAGE_ANY_EVENT_PERMISSION},
+	})
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
+		Id:     123,
+		UserId: 12,
+	}, nil)
+	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.UpdateEvent(suite.mockContext)
+
+	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "UpdateEvent", 1)
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
 }
 
 func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_AttemptsToUpdateTheEvent() {
@@ -409,19 +507,19 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_AttemptsToUpdateTheE
 
 	test_utils.SetRequestBody(expectedEvent, suite.mockContext)
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
 
 	suite.controller.UpdateEvent(suite.mockContext)
 
 	//Type is validated as well, so we need to have the right type of int
-	suite.eventServiceMock.AssertCalled(suite.T(), "UpdateEvent", int64(1), expectedEvent)
+	suite.eventServiceMock.AssertCalled(suite.T(), "UpdateEvent", int64(1), int64(0), expectedEvent)
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "UpdateEvent", 1)
 }
 
@@ -448,12 +546,12 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_ReturnsInternalServe
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
-	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
 
 	suite.controller.UpdateEvent(suite.mockContext)
 
@@ -482,12 +580,12 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_ReturnsOk() {
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
-	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(nil)
+	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	suite.controller.UpdateEvent(suite.mockContext)
 
@@ -496,6 +594,40 @@ func (suite *EventsControllerUnitTestSuite) TestUpdateEvent_ReturnsOk() {
 	suite.Equal(http.StatusOK, response.StatusCode)
 }
 
+// When no visibility is provided, the event keeps its current visibility
+func (suite *EventsControllerUnitTestSuite) TestUpdateEventWithoutVisibility_KeepsTheVisibility() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+	}, suite.mockContext)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
+		Id:         123,
+		UserId:     12,
+		Visibility: models.PRIVATE_VISIBILITY,
+	}, nil)
+
+	suite.eventServiceMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.UpdateEvent(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "UpdateEvent", int64(1), int64(0), mock.MatchedBy(func(event models.Event) bool {
+		return event.Visibility == models.PRIVATE_VISIBILITY
+	}))
+}
+
 // When there is a malformed or missing id param, it should return bad request
 func (suite *EventsControllerUnitTestSuite) TestDeleteEventMissingParam_ReturnsBadRequest() {
 
@@ -538,18 +670,18 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_AttemptsToFetchTheEv
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.DeleteEvent(suite.mockContext)
 
 	//Type is validated as well, so we need to have the right type of int
-	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventById", int64(1))
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventById", int64(1), int64(0))
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
 }
 
 // When the user is attempting to delete the event, attempts to update the event, it should return
 // unauthorized
-func (suite *EventsControllerUnitTestSuite) TestDeleteEventNotTheCreator_ReturnsUnauthorized() {
+func (suite *EventsControllerUnitTestSuite) TestDeleteEventNotTheCreator_ReturnsForbidden() {
 
 	suite.mockContext.Params = gin.Params{
 		{
@@ -560,7 +692,7 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEventNotTheCreator_Returns
 
 	suite.mockContext.Set("userId", int64(1))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
@@ -569,7 +701,7 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEventNotTheCreator_Returns
 
 	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
 
-	suite.Equal(http.StatusUnauthorized, response.StatusCode)
+	suite.Equal(http.StatusForbidden, response.StatusCode)
 }
 
 func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_AttemptsToDeleteTheEvent() {
@@ -581,22 +713,70 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_AttemptsToDeleteTheE
 		},
 	}
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("DeleteEvent", mock.Anything).Return(errors.New("test"))
+	suite.eventServiceMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
 
 	suite.controller.DeleteEvent(suite.mockContext)
 
 	//Type is validated as well, so we need to have the right type of int
-	suite.eventServiceMock.AssertCalled(suite.T(), "DeleteEvent", int64(1))
+	suite.eventServiceMock.AssertCalled(suite.T(), "DeleteEvent", int64(1), int64(0))
 	suite.eventServiceMock.AssertNumberOfCalls(suite.T(), "DeleteEvent", 1)
 }
 
+// When the event does not exist, return not found
+func (suite *EventsControllerUnitTestSuite) TestDeleteEventNotFound_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	suite.controller.DeleteEvent(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "DeleteEvent", mock.Anything, mock.Anything)
+}
+
+// Users allowed to manage any event can delete events organized by others
+func (suite *EventsControllerUnitTestSuite) TestDeleteEventWithManagePermission_DeletesTheEvent() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(1))
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{
+		UserId:      1,
+		Permissions: []string{models.MANAGE_ANY_EVENT_PERMISSION},
+	})
+
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
+		Id:     123,
+		UserId: 12,
+	}, nil)
+	suite.eventServiceMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.DeleteEvent(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "DeleteEvent", int64(1), int64(0))
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
 // When failing to update the event, return an internal server error
 func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_ReturnsInternalServerError() {
 
@@ -609,12 +789,12 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_ReturnsInternalServe
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
-	suite.eventServiceMock.On("DeleteEvent", mock.Anything).Return(errors.New("test"))
+	suite.eventServiceMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
 
 	suite.controller.DeleteEvent(suite.mockContext)
 
@@ -634,12 +814,12 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_ReturnsOk() {
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.eventServiceMock.On("GetEventById", mock.Anything).Return(&models.Event{
+	suite.eventServiceMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{
 		Id:     123,
 		UserId: 12,
 	}, nil)
 
-	suite.eventServiceMock.On("DeleteEvent", mock.Anything).Return(nil)
+	suite.eventServiceMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
 
 	suite.controller.DeleteEvent(suite.mockContext)
 
@@ -647,3 +827,196 @@ func (suite *EventsControllerUnitTestSuite) TestDeleteEvent_ReturnsOk() {
 
 	suite.Equal(http.StatusOK, response.StatusCode)
 }
+
+// When the near parameter is not a latitude,longitude pair, it should return bad request
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearMalformedCoordinates_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=foo", nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearInvalidRadius_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,13.405&radius_km=-1", nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// ParseFloat accepts NaN, which passes every range check
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearNaNLatitude_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=NaN,13.405", nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearInfiniteLongitude_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,-Inf", nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearNaNRadius_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,13.405&radius_km=NaN", nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNear_SearchesAroundTheCoordinates() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,13.405&radius_km=5", nil)
+
+	suite.eventServiceMock.On("GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventsNear", 52.52, 13.405, 5.0, int64(0), int64(0))
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "GetEvents", mock.Anything, mock.Anything)
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// Without a radius, the default radius should be used
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearWithoutRadius_UsesTheDefaultRadius() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,13.405", nil)
+
+	suite.eventServiceMock.On("GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.EventWithDistance{}, nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	suite.eventServiceMock.AssertCalled(suite.T(), "GetEventsNear", 52.52, 13.405, 10.0, int64(0), int64(0))
+}
+
+func (suite *EventsControllerUnitTestSuite) TestGetEventsNearSortedByDate_ReturnsEventsInDateOrder() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events?near=52.52,13.405&sort=date", nil)
+
+	earlierDate, _ := time.Parse(time.RFC3339, "1990-01-01T00:00:00.000Z")
+	laterDate, _ := time.Parse(time.RFC3339, "1990-01-02T00:00:00.000Z")
+
+	suite.eventServiceMock.On("GetEventsNear", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.EventWithDistance{
+		{Event: models.Event{Name: "later", Date: laterDate}, DistanceKm: 1},
+		{Event: models.Event{Name: "earlier", Date: earlierDate}, DistanceKm: 2},
+	}, nil)
+
+	suite.controller.GetEvents(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	var events []models.EventWithDistance
+
+	json.Unmarshal([]byte(response.Body), &events)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal("earlier", events[0].Name)
+	suite.Equal(2.0, events[0].DistanceKm)
+}
+
+// When only one of the coordinates is provided, it should return a bad request
+func (suite *EventsControllerUnitTestSuite) TestAddEventsWithPartialCoordinates_ReturnsBadRequest() {
+
+	latitude := 52.52
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+		Latitude:    &latitude,
+	}, suite.mockContext)
+
+	suite.controller.AddEvent(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "SaveEvent", mock.Anything)
+}
+
+// When only one of the coordinates is provided, the event should be left untouched
+func (suite *EventsControllerUnitTestSuite) TestUpdateEventWithPartialCoordinates_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	latitude := 52.52
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        time.Now(),
+		Latitude:    &latitude,
+	}, suite.mockContext)
+
+	suite.controller.UpdateEvent(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "UpdateEvent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the event ends before it starts, it should return a bad request
+func (suite *EventsControllerUnitTestSuite) TestAddEventsEndingBeforeTheStart_ReturnsBadRequest() {
+
+	date := time.Now()
+	endDate := date.Add(-time.Hour)
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        date,
+		EndDate:     &endDate,
+	}, suite.mockContext)
+
+	suite.controller.AddEvent(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "SaveEvent", mock.Anything)
+}
+
+// When the event would end before it starts, the event should be left untouched
+func (suite *EventsControllerUnitTestSuite) TestUpdateEventEndingBeforeTheStart_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	date := time.Now()
+	endDate := date.Add(-time.Hour)
+
+	test_utils.SetRequestBody(models.Event{
+		Name:        "some name",
+		Description: "some description",
+		Location:    "some location",
+		Date:        date,
+		EndDate:     &endDate,
+	}, suite.mockContext)
+
+	suite.controller.UpdateEvent(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.eventServiceMock.AssertNotCalled(suite.T(), "UpdateEvent", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationsController struct {
+	invitationService interfaces.IInvitationService
+}
+
+func (controller InvitationsController) CreateInvitation(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	var request models.InvitationRequest
+
+	//an empty body creates a link anyone can use
+	if context.Request.ContentLength != 0 {
+		err := context.ShouldBindJSON(&request)
+
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid invitation",
+			})
+			return
+		}
+	}
+
+	invitation, token, err := controller.invitationService.CreateInvitation(eventId, context.GetInt64("userId"), context.GetInt64("organizationId"), request)
+
+	if err != nil {
+		respondToInvitationError(context, err)
+		return
+	}
+
+	//the token is only ever returned here, the organizer has to share the link
+	context.JSON(http.StatusCreated, gin.H{
+		"message":    "Created",
+		"invitation": invitation,
+		"token":      token,
+		"link":       fmt.Sprintf("/events/%v?invite=%v", eventId, token),
+	})
+}
+
+func (controller InvitationsController) GetInvitations(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	invitations, err := controller.invitationService.GetInvitations(eventId, context.GetInt64("userId"), context.GetInt64("organizationId"))
+
+	if err != nil {
+		respondToInvitationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, invitations)
+}
+
+func (controller InvitationsController) RevokeInvitation(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	invitationId, parsingError := strconv.ParseInt(context.Param("invitationId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid invitation id",
+		})
+		return
+	}
+
+	err := controller.invitationService.RevokeInvitation(eventId, invitationId, context.GetInt64("userId"), context.GetInt64("organizationId"))
+
+	if err != nil {
+		respondToInvitationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Invitation Revoked",
+	})
+}
+
+func (controller InvitationsController) AcceptInvitation(context *gin.Context) {
+	eventId, parsingError := strconv.ParseInt(context.Param("id"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid event id",
+		})
+		return
+	}
+
+	var request models.AcceptInvitationRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	err = controller.invitationService.AcceptInvitation(eventId, context.GetInt64("userId"), context.GetInt64("organizationId"), request.Token)
+
+	if err != nil {
+		respondToInvitationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Invitation Accepted",
+	})
+}
+
+func respondToInvitationError(context *gin.Context, err error) {
+	switch err.Error() {
+	case constants.NO_EVENT_FOR_ID_ERROR, constants.NO_INVITATION_FOR_ID_ERROR:
+		context.JSON(http.StatusNotFound, nil)
+	case constants.NOT_EVENT_ORGANIZER_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only the organizer can manage invitations",
+		})
+	case constants.INVALID_INVITATION_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Invitation is invalid, expired or revoked",
+		})
+	case constants.DUPLICATE_RECORD_ERROR:
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "An invitation with the same token already exists",
+		})
+	//the event or the account was deleted while inviting or accepting
+	case constants.MISSING_REFERENCED_RECORD_ERROR:
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "The event or the account no longer exists",
+		})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+	}
+}
+
+func NewInvitationsController(invitationService interfaces.IInvitationService) *InvitationsController {
+	return &InvitationsController{
+		invitationService: invitationService,
+	}
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type InvitationsControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext           *gin.Context
+	invitationServiceMock mocks.IInvitationService
+	mockResponseWriter    *httptest.ResponseRecorder
+	controller            *InvitationsController
+}
+
+func TestInvitationsControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &InvitationsControllerUnitTestSuite{})
+}
+
+func (suite *InvitationsControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(3))
+
+	suite.invitationServiceMock = mocks.IInvitationService{}
+
+	suite.controller = NewInvitationsController(&suite.invitationServiceMock)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestCreateInvitationMalformedParam_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "bar",
+		},
+	}
+
+	suite.controller.CreateInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestCreateInvitationInvalidEmail_ReturnsBadRequest() {
+
+	email := "not an email"
+
+	test_utils.SetRequestBody(models.InvitationRequest{Email: &email}, suite.mockContext)
+
+	suite.controller.CreateInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.invitationServiceMock.AssertNotCalled(suite.T(), "CreateInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the user is not the organizer of the event, it should return forbidden
+func (suite *InvitationsControllerUnitTestSuite) TestCreateInvitationNotTheOrganizer_ReturnsForbidden() {
+
+	suite.invitationServiceMock.On("CreateInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, "", errors.New(constants.NOT_EVENT_ORGANIZER_ERROR))
+
+	suite.controller.CreateInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+// When the event was deleted meanwhile, return a conflict
+func (suite *InvitationsControllerUnitTestSuite) TestCreateInvitationMissingReference_ReturnsConflict() {
+
+	suite.invitationServiceMock.On("CreateInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, "", errors.New(constants.MISSING_REFERENCED_RECORD_ERROR))
+
+	suite.controller.CreateInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+// Without a body, an invitation anyone holding the link can use is created
+func (suite *InvitationsControllerUnitTestSuite) TestCreateInvitation_ReturnsTheLink() {
+
+	suite.invitationServiceMock.On("CreateInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Invitation{Id: 2, EventId: 1}, "some-token", nil)
+
+	suite.controller.CreateInvitation(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.invitationServiceMock.AssertCalled(suite.T(), "CreateInvitation", int64(1), int64(3), int64(0), models.InvitationRequest{})
+	suite.Equal(http.StatusCreated, response.StatusCode)
+	suite.Contains(response.Body, `"token":"some-token"`)
+	suite.Contains(response.Body, `"link":"/events/1?invite=some-token"`)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestGetInvitationsWhenNoEventFound_ReturnsNotFound() {
+
+	suite.invitationServiceMock.On("GetInvitations", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.NO_EVENT_FOR_ID_ERROR))
+
+	suite.controller.GetInvitations(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestGetInvitations_ReturnsOk() {
+
+	suite.invitationServiceMock.On("GetInvitations", mock.Anything, mock.Anything, mock.Anything).
+		Return([]models.Invitation{{Id: 2, EventId: 1}}, nil)
+
+	suite.controller.GetInvitations(suite.mockContext)
+
+	suite.invitationServiceMock.AssertCalled(suite.T(), "GetInvitations", int64(1), int64(3), int64(0))
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestRevokeInvitationMalformedInvitationId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{
+		Key:   "invitationId",
+		Value: "bar",
+	})
+
+	suite.controller.RevokeInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the invitation does not exist or was already revoked, it should return not found
+func (suite *InvitationsControllerUnitTestSuite) TestRevokeInvitationNotFound_ReturnsNotFound() {
+
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{
+		Key:   "invitationId",
+		Value: "2",
+	})
+
+	suite.invitationServiceMock.On("RevokeInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.NO_INVITATION_FOR_ID_ERROR))
+
+	suite.controller.RevokeInvitation(suite.mockContext)
+
+	suite.invitationServiceMock.AssertCalled(suite.T(), "RevokeInvitation", int64(1), int64(2), int64(3), int64(0))
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestAcceptInvitationMissingToken_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.AcceptInvitationRequest{}, suite.mockContext)
+
+	suite.controller.AcceptInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the invitation is expired, revoked or meant for someone else, it should return forbidden
+func (suite *InvitationsControllerUnitTestSuite) TestAcceptInvalidInvitation_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.AcceptInvitationRequest{Token: "some-token"}, suite.mockContext)
+
+	suite.invitationServiceMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.INVALID_INVITATION_ERROR))
+
+	suite.controller.AcceptInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *InvitationsControllerUnitTestSuite) TestAcceptInvitation_ReturnsOk() {
+
+	test_utils.SetRequestBody(models.AcceptInvitationRequest{Token: "some-token"}, suite.mockContext)
+	suite.mockContext.Set("organizationId", int64(4))
+
+	suite.invitationServiceMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.AcceptInvitation(suite.mockContext)
+
+	suite.invitationServiceMock.AssertCalled(suite.T(), "AcceptInvitation", int64(1), int64(3), int64(4), "some-token")
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	interfaces "example.com/interfaces/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Keys are cached for a while by verifiers, new keys have to be published this long before they
+// start signing tokens
+const jsonWebKeySetMaxAge = "max-age=300"
+
+type JsonWebKeysController struct {
+	tokenService interfaces.ITokenService
+}
+
+func (controller JsonWebKeysController) GetJsonWebKeySet(context *gin.Context) {
+	context.Header("Cache-Control", "public, "+jsonWebKeySetMaxAge)
+	context.JSON(http.StatusOK, controller.tokenService.GetJsonWebKeySet())
+}
+
+func NewJsonWebKeysController(tokenService interfaces.ITokenService) *JsonWebKeysController {
+	return &JsonWebKeysController{
+		tokenService: tokenService,
+	}
+}
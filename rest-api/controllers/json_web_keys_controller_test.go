@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type JsonWebKeysControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext        *gin.Context
+	tokenServiceMock   mocks.ITokenService
+	mockResponseWriter *httptest.ResponseRecorder
+	controller         *JsonWebKeysController
+}
+
+func TestJsonWebKeysControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &JsonWebKeysControllerUnitTestSuite{})
+}
+
+func (suite *JsonWebKeysControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/.well-known/jwks.json", nil)
+
+	suite.tokenServiceMock = mocks.ITokenService{}
+
+	suite.controller = NewJsonWebKeysController(&suite.tokenServiceMock)
+}
+
+func (suite *JsonWebKeysControllerUnitTestSuite) TestGetJsonWebKeySet_ReturnsTheKeys() {
+
+	suite.tokenServiceMock.On("GetJsonWebKeySet").Return(models.JsonWebKeySet{Keys: []models.JsonWebKey{{
+		KeyType:   "EC",
+		KeyId:     "key id",
+		Use:       "sig",
+		Algorithm: "ES256",
+		Curve:     "P-256",
+		X:         "x",
+		Y:         "y",
+	}}})
+
+	suite.controller.GetJsonWebKeySet(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal(`{"keys":[{"kty":"EC","kid":"key id","use":"sig","alg":"ES256","crv":"P-256","x":"x","y":"y"}]}`, response.Body)
+	suite.Contains(suite.mockResponseWriter.Header().Get("Cache-Control"), "max-age=300")
+}
+
+// When tokens are signed with the shared secret, there are no keys to publish
+func (suite *JsonWebKeysControllerUnitTestSuite) TestGetJsonWebKeySetWithSharedSecret_ReturnsNoKeys() {
+
+	suite.tokenServiceMock.On("GetJsonWebKeySet").Return(models.JsonWebKeySet{Keys: []models.JsonWebKey{}})
+
+	suite.controller.GetJsonWebKeySet(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(`{"keys":[]}`, response.Body)
+}
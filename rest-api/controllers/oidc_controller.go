@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"example.com/config"
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+const oidcStateCookieName = "oidc_state"
+
+type OidcController struct {
+	oidcService          interfaces.IOidcService
+	tokenService         interfaces.ITokenService
+	twoFactorService     interfaces.ITwoFactorService
+	loginThrottleService interfaces.ILoginThrottleService
+	auditLogService      interfaces.IAuditLogService
+}
+
+// Redirects the user to the provider to log in
+func (controller OidcController) StartLogin(context *gin.Context) {
+	loginStart, err := controller.oidcService.StartLogin(context.Param("provider"))
+
+	if err != nil && err.Error() == constants.UNKNOWN_OIDC_PROVIDER_ERROR {
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	//binds the login to this browser, a callback url handed to someone else cannot log them in
+	setOidcStateCookie(context, loginStart.State, int(loginStart.ExpiresIn))
+
+	context.Redirect(http.StatusFound, loginStart.AuthorizationUrl)
+}
+
+// The provider redirects back here, with either a code or the error that ended the login
+func (controller OidcController) Callback(context *gin.Context) {
+	if providerError := context.Query("error"); providerError != "" {
+		recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, providerError)
+
+		context.JSON(http.StatusUnauthorized, gin.H{
+			"error": constants.OIDC_AUTHENTICATION_ERROR,
+			"cause": providerError,
+		})
+		return
+	}
+
+	state, code := context.Query("state"), context.Query("code")
+
+	if state == "" || code == "" {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Missing state or code",
+		})
+		return
+	}
+
+	//the user is only known once the provider answered, until then the ip alone is throttled
+	if !controller.allowLogin(context, models.User{}) {
+		return
+	}
+
+	stateCookie, _ := context.Cookie(oidcStateCookieName)
+
+	//the state is single use either way
+	setOidcStateCookie(context, "", -1)
+
+	if subtle.ConstantTimeCompare([]byte(stateCookie), []byte(state)) != 1 {
+		recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, "The login was started by another browser")
+
+		if err := controller.loginThrottleService.RecordFailedLogin("", context.ClientIP()); err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+			return
+		}
+
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": constants.INVALID_OIDC_STATE_ERROR,
+		})
+		return
+	}
+
+	user, err := controller.oidcService.CompleteLogin(context.Param("provider"), state, code)
+
+	if err != nil && (err.Error() == constants.INVALID_OIDC_STATE_ERROR || err.Error() == constants.OIDC_AUTHENTICATION_ERROR) {
+		if recordErr := controller.loginThrottleService.RecordFailedLogin("", context.ClientIP()); recordErr != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+			return
+		}
+	}
+
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_OIDC_STATE_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+			context.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case constants.OIDC_AUTHENTICATION_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+			context.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+		case constants.OIDC_EMAIL_NOT_VERIFIED_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+			context.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+		case constants.ACCOUNT_DISABLED_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, models.User{}, "Account is disabled")
+
+			context.JSON(http.StatusForbidden, gin.H{
+				"error": "Account is disabled",
+			})
+		default:
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+		}
+		return
+	}
+
+	//a locked account stays locked, whichever way the user logs in
+	if !controller.allowLogin(context, *user) {
+		return
+	}
+
+	//two factor authentication set up in the api still applies, whatever the provider checked
+	twoFactorEnabled, err := controller.twoFactorService.IsTwoFactorEnabled(user.Id)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if twoFactorEnabled {
+		challenge, err := controller.twoFactorService.CreateMfaChallenge(user.Id)
+
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+			return
+		}
+
+		respondWithMfaChallenge(context, *challenge)
+		return
+	}
+
+	err = controller.loginThrottleService.RecordSuccessfulLogin(user.Email)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	tokens, err := controller.tokenService.IssueTokens(*user, sessionClient(context))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, *user, "")
+
+	respondWithTokens(context, *tokens)
+}
+
+// Responds with the delay to wait and returns false while logins of the user or the ip are throttled,
+// an empty user checks the ip only
+func (controller OidcController) allowLogin(context *gin.Context, user models.User) bool {
+	retryAfter, err := controller.loginThrottleService.CheckLogin(user.Email, context.ClientIP())
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return false
+	}
+
+	if retryAfter > 0 {
+		recordAuditEntry(controller.auditLogService, context, models.OIDC_LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, user, "Too many failed login attempts")
+
+		respondWithRetryAfter(context, retryAfter)
+		return false
+	}
+
+	return true
+}
+
+// Scoped to the routes of the provider, sent back by the browser on the redirect of the provider
+// since SameSite=Lax cookies are kept on top level navigations
+func setOidcStateCookie(context *gin.Context, state string, maxAge int) {
+	secure := context.Request.TLS != nil || strings.HasPrefix(config.AppConfiguration().PublicUrl(), "https://")
+
+	context.SetSameSite(http.SameSiteLaxMode)
+	context.SetCookie(oidcStateCookieName, state, maxAge, "/auth/oidc/"+context.Param("provider"), "", secure, true)
+}
+
+func NewOidcController(
+	oidcService interfaces.IOidcService,
+	tokenService interfaces.ITokenService,
+	twoFactorService interfaces.ITwoFactorService,
+	loginThrottleService interfaces.ILoginThrottleService,
+	auditLogService interfaces.IAuditLogService) *OidcController {
+	return &OidcController{
+		oidcService:          oidcService,
+		tokenService:         tokenService,
+		twoFactorService:     twoFactorService,
+		loginThrottleService: loginThrottleService,
+		auditLogService:      auditLogService,
+	}
+}
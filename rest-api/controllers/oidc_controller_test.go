@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OidcControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext          *gin.Context
+	oidcServiceMock      mocks.IOidcService
+	tokenServiceMock     mocks.ITokenService
+	twoFactorServiceMock mocks.ITwoFactorService
+	throttleServiceMock  mocks.ILoginThrottleService
+	auditLogServiceMock  mocks.IAuditLogService
+	mockResponseWriter   *httptest.ResponseRecorder
+	controller           *OidcController
+}
+
+func TestOidcControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OidcControllerUnitTestSuite{})
+}
+
+func (suite *OidcControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/auth/oidc/company/callback?state=some-state&code=some-code", nil)
+	suite.mockContext.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "some-state"})
+
+	suite.mockContext.Params = gin.Params{{Key: "provider", Value: "company"}}
+
+	suite.oidcServiceMock = mocks.IOidcService{}
+	suite.tokenServiceMock = mocks.ITokenService{}
+	suite.twoFactorServiceMock = mocks.ITwoFactorService{}
+	suite.throttleServiceMock = mocks.ILoginThrottleService{}
+
+	suite.throttleServiceMock.On("CheckLogin", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Maybe()
+	suite.throttleServiceMock.On("RecordFailedLogin", mock.Anything, mock.Anything).Return(nil).Maybe()
+	suite.throttleServiceMock.On("RecordSuccessfulLogin", mock.Anything).Return(nil).Maybe()
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewOidcController(&suite.oidcServiceMock, &suite.tokenServiceMock, &suite.twoFactorServiceMock, &suite.throttleServiceMock, &suite.auditLogServiceMock)
+}
+
+func (suite *OidcControllerUnitTestSuite) TestStartLogin_RedirectsToTheProvider() {
+
+	suite.oidcServiceMock.On("StartLogin", mock.Anything).Return(&models.OidcLoginStart{
+		AuthorizationUrl: "https://provider/authorize",
+		State:            "some-state",
+		ExpiresIn:        600,
+	}, nil)
+
+	suite.controller.StartLogin(suite.mockContext)
+
+	suite.Equal(http.StatusFound, suite.mockResponseWriter.Code)
+	suite.Equal("https://provider/authorize", suite.mockResponseWriter.Header().Get("Location"))
+	suite.oidcServiceMock.AssertCalled(suite.T(), "StartLogin", "company")
+}
+
+// The state is handed to the browser in a cookie scripts cannot read, and which is still sent on
+// the redirect of the provider
+func (suite *OidcControllerUnitTestSuite) TestStartLogin_SetsTheStateCookie() {
+
+	suite.oidcServiceMock.On("StartLogin", mock.Anything).Return(&models.OidcLoginStart{
+		AuthorizationUrl: "https://provider/authorize",
+		State:            "some-state",
+		ExpiresIn:        600,
+	}, nil)
+
+	suite.controller.StartLogin(suite.mockContext)
+
+	cookie := suite.mockResponseWriter.Header().Get("Set-Cookie")
+
+	suite.Contains(cookie, "oidc_state=some-state")
+	suite.Contains(cookie, "Path=/auth/oidc/company")
+	suite.Contains(cookie, "Max-Age=600")
+	suite.Contains(cookie, "HttpOnly")
+	suite.Contains(cookie, "SameSite=Lax")
+}
+
+// When the provider is not configured, return not found
+func (suite *OidcControllerUnitTestSuite) TestStartLoginUnknownProvider_ReturnsNotFound() {
+
+	suite.oidcServiceMock.On("StartLogin", mock.Anything).Return(nil, errors.New(constants.UNKNOWN_OIDC_PROVIDER_ERROR))
+
+	suite.controller.StartLogin(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+// When the provider reports an error, return unauthorized without completing the login
+func (suite *OidcControllerUnitTestSuite) TestCallbackProviderError_ReturnsUnauthorized() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/auth/oidc/company/callback?error=access_denied&state=some-state", nil)
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.oidcServiceMock.AssertNotCalled(suite.T(), "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the state is invalid, return a bad request
+func (suite *OidcControllerUnitTestSuite) TestCallbackInvalidState_ReturnsBadRequest() {
+
+	suite.oidcServiceMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.INVALID_OIDC_STATE_ERROR))
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.throttleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "", mock.Anything)
+}
+
+// When the login was started by another browser, return a bad request without contacting the provider
+func (suite *OidcControllerUnitTestSuite) TestCallbackMissingStateCookie_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/auth/oidc/company/callback?state=some-state&code=some-code", nil)
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.Contains(suite.mockResponseWriter.Body.String(), constants.INVALID_OIDC_STATE_ERROR)
+	suite.throttleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "", mock.Anything)
+	suite.oidcServiceMock.AssertNotCalled(suite.T(), "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the state does not match the one of the browser, return a bad request without contacting the provider
+func (suite *OidcControllerUnitTestSuite) TestCallbackOtherStateCookie_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/auth/oidc/company/callback?state=some-state&code=some-code", nil)
+	suite.mockContext.Request.AddCookie(&http.Cookie{Name: "oidc_state", Value: "other-state"})
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.Contains(suite.mockResponseWriter.Header().Get("Set-Cookie"), "Max-Age=0")
+	suite.oidcServiceMock.AssertNotCalled(suite.T(), "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the ip is throttled, return too many requests without contacting the provider
+func (suite *OidcControllerUnitTestSuite) TestCallbackThrottledIp_ReturnsTooManyRequests() {
+
+	suite.throttleServiceMock = mocks.ILoginThrottleService{}
+	suite.throttleServiceMock.On("CheckLogin", "", mock.Anything).Return(time.Minute, nil)
+	suite.controller = NewOidcController(&suite.oidcServiceMock, &suite.tokenServiceMock, &suite.twoFactorServiceMock, &suite.throttleServiceMock, &suite.auditLogServiceMock)
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+	suite.Equal("60", suite.mockResponseWriter.Header().Get("Retry-After"))
+	suite.oidcServiceMock.AssertNotCalled(suite.T(), "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the account is locked, return too many requests without issuing tokens
+func (suite *OidcControllerUnitTestSuite) TestCallbackLockedAccount_ReturnsTooManyRequests() {
+
+	suite.throttleServiceMock = mocks.ILoginThrottleService{}
+	suite.throttleServiceMock.On("CheckLogin", "", mock.Anything).Return(time.Duration(0), nil)
+	suite.throttleServiceMock.On("CheckLogin", "test@test.com", mock.Anything).Return(time.Minute, nil)
+	suite.controller = NewOidcController(&suite.oidcServiceMock, &suite.tokenServiceMock, &suite.twoFactorServiceMock, &suite.throttleServiceMock, &suite.auditLogServiceMock)
+
+	suite.oidcServiceMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+}
+
+// When the provider did not verify the email, return forbidden
+func (suite *OidcControllerUnitTestSuite) TestCallbackUnverifiedEmail_ReturnsForbidden() {
+
+	suite.oidcServiceMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.OIDC_EMAIL_NOT_VERIFIED_ERROR))
+
+	suite.controller.Callback(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+// When two factor authentication is enabled, return an mfa token instead of the auth token
+func (suite *OidcControllerUnitTestSuite) TestCallbackWithTwoFactor_ReturnsAnMfaToken() {
+
+	suite.oidcServiceMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(&models.User{Id: 12}, nil)
+	suite.twoFactorServiceMock.On("IsTwoFactorEnabled", mock.Anything).Return(true, nil)
+	suite.twoFactorServiceMock.On("CreateMfaChallenge", mock.Anything).Return(&models.MfaChallengeToken{Token: "mfa token"}, nil)
+
+	suite.controller.Callback(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"mfa_token":"mfa token"`)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+}
+
+func (suite *OidcControllerUnitTestSuite) TestCallback_ReturnsTheTokens() {
+
+	user := models.User{Id: 12, Email: "test@test.com"}
+
+	suite.oidcServiceMock.On("CompleteLogin", mock.Anything, mock.Anything, mock.Anything).Return(&user, nil)
+	suite.twoFactorServiceMock.On("IsTwoFactorEnabled", mock.Anything).Return(false, nil)
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{
+		AccessToken:  "auth token",
+		RefreshToken: "refresh token",
+	}, nil)
+
+	suite.controller.Callback(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, "auth token")
+	suite.oidcServiceMock.AssertCalled(suite.T(), "CompleteLogin", "company", "some-state", "some-code")
+	suite.tokenServiceMock.AssertCalled(suite.T(), "IssueTokens", user, mock.Anything)
+	suite.throttleServiceMock.AssertCalled(suite.T(), "RecordSuccessfulLogin", "test@test.com")
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.OIDC_LOGIN_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(int64(12), entry.ActorId)
+}
@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationsController struct {
+	organizationService interfaces.IOrganizationService
+	tokenService        interfaces.ITokenService
+	auditLogService     interfaces.IAuditLogService
+}
+
+func (controller OrganizationsController) CreateOrganization(context *gin.Context) {
+	var organization models.Organization
+
+	err := context.ShouldBindJSON(&organization)
+
+	if err != nil || strings.TrimSpace(organization.Name) == "" {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	createdOrganization, err := controller.organizationService.CreateOrganization(organization.Name, context.GetInt64("userId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusCreated, gin.H{
+		"message":      "Created",
+		"organization": createdOrganization,
+	})
+}
+
+func (controller OrganizationsController) GetOrganizations(context *gin.Context) {
+	organizations, err := controller.organizationService.GetOrganizations(context.GetInt64("userId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, organizations)
+}
+
+func (controller OrganizationsController) GetMembers(context *gin.Context) {
+	organizationId, parsingError := strconv.ParseInt(context.Param("organizationId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid organization id",
+		})
+		return
+	}
+
+	members, err := controller.organizationService.GetMembers(organizationId, context.GetInt64("userId"))
+
+	if err != nil {
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, members)
+}
+
+func (controller OrganizationsController) InviteMember(context *gin.Context) {
+	organizationId, parsingError := strconv.ParseInt(context.Param("organizationId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid organization id",
+		})
+		return
+	}
+
+	var request models.OrganizationInvitationRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid invitation",
+		})
+		return
+	}
+
+	invitation, token, err := controller.organizationService.InviteMember(organizationId, context.GetInt64("userId"), request)
+
+	if err != nil {
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	//the token is only ever returned here, the admin has to send it to the invitee
+	context.JSON(http.StatusCreated, gin.H{
+		"message":    "Created",
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+func (controller OrganizationsController) AcceptInvitation(context *gin.Context) {
+	organizationId, parsingError := strconv.ParseInt(context.Param("organizationId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid organization id",
+		})
+		return
+	}
+
+	var request models.AcceptInvitationRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	err = controller.organizationService.AcceptInvitation(organizationId, context.GetInt64("userId"), request.Token)
+
+	if err != nil {
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Invitation Accepted",
+	})
+}
+
+func (controller OrganizationsController) UpdateMemberRole(context *gin.Context) {
+	organizationId, memberId, ok := parseOrganizationMemberParams(context)
+
+	if !ok {
+		return
+	}
+
+	var request models.OrganizationRoleRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	err = controller.organizationService.UpdateMemberRole(organizationId, context.GetInt64("userId"), memberId, request.Role)
+
+	if err != nil {
+		switch err.Error() {
+		case constants.NOT_ORGANIZATION_ADMIN_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.ORGANIZATION_ROLE_CHANGE_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, models.User{}, err.Error())
+		case constants.LAST_ORGANIZATION_ADMIN_ERROR, constants.NO_ORGANIZATION_MEMBER_FOR_ID_ERROR, constants.NO_ORGANIZATION_FOR_ID_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.ORGANIZATION_ROLE_CHANGE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+		}
+
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.ORGANIZATION_ROLE_CHANGE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "Role changed to "+request.Role)
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Member Updated",
+	})
+}
+
+func (controller OrganizationsController) RemoveMember(context *gin.Context) {
+	organizationId, memberId, ok := parseOrganizationMemberParams(context)
+
+	if !ok {
+		return
+	}
+
+	err := controller.organizationService.RemoveMember(organizationId, context.GetInt64("userId"), memberId)
+
+	if err != nil {
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Member Removed",
+	})
+}
+
+// Issues an access token acting in the organization for the current session, its refresh tokens
+// keep acting in it until the next switch
+func (controller OrganizationsController) SwitchOrganization(context *gin.Context) {
+	var request models.SwitchOrganizationRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	tokens, err := controller.tokenService.SwitchOrganization(getTokenClaims(context), *request.OrganizationId)
+
+	if err != nil && err.Error() == constants.NO_SESSION_FOR_ID_ERROR {
+		context.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Organizations can only be switched with the tokens issued at login",
+		})
+		return
+	}
+
+	if err != nil {
+		respondToOrganizationError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"token":      tokens.AccessToken,
+		"expires_in": tokens.ExpiresIn,
+	})
+}
+
+func parseOrganizationMemberParams(context *gin.Context) (int64, int64, bool) {
+	organizationId, parsingError := strconv.ParseInt(context.Param("organizationId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid organization id",
+		})
+		return 0, 0, false
+	}
+
+	memberId, parsingError := strconv.ParseInt(context.Param("userId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid user id",
+		})
+		return 0, 0, false
+	}
+
+	return organizationId, memberId, true
+}
+
+func respondToOrganizationError(context *gin.Context, err error) {
+	switch err.Error() {
+	case constants.NO_ORGANIZATION_FOR_ID_ERROR, constants.NO_ORGANIZATION_MEMBER_FOR_ID_ERROR, constants.NO_USER_FOR_ID_ERROR:
+		context.JSON(http.StatusNotFound, nil)
+	case constants.NOT_ORGANIZATION_ADMIN_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only organization admins can manage members",
+		})
+	case constants.LAST_ORGANIZATION_ADMIN_ERROR:
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "Organizations need at least one admin",
+		})
+	case constants.INVALID_ORGANIZATION_INVITATION_ERROR:
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invitation is invalid, expired or was sent to another email",
+		})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+	}
+}
+
+func NewOrganizationsController(
+	organizationService interfaces.IOrganizationService,
+	tokenService interfaces.ITokenService,
+	auditLogService interfaces.IAuditLogService) *OrganizationsController {
+	return &OrganizationsController{
+		organizationService: organizationService,
+		tokenService:        tokenService,
+		auditLogService:     auditLogService,
+	}
+}
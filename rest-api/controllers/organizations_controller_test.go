@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrganizationsControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext             *gin.Context
+	organizationServiceMock mocks.IOrganizationService
+	tokenServiceMock        mocks.ITokenService
+	auditLogServiceMock     mocks.IAuditLogService
+	mockResponseWriter      *httptest.ResponseRecorder
+	controller              *OrganizationsController
+}
+
+func TestOrganizationsControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OrganizationsControllerUnitTestSuite{})
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com", nil)
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}}
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{UserId: 12, SessionId: "current"})
+
+	suite.organizationServiceMock = mocks.IOrganizationService{}
+	suite.tokenServiceMock = mocks.ITokenService{}
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewOrganizationsController(&suite.organizationServiceMock, &suite.tokenServiceMock, &suite.auditLogServiceMock)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestCreateOrganizationWithoutName_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.Organization{Name: "   "}, suite.mockContext)
+
+	suite.controller.CreateOrganization(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertNotCalled(suite.T(), "CreateOrganization", mock.Anything, mock.Anything)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestCreateOrganization_ReturnsCreated() {
+
+	test_utils.SetRequestBody(models.Organization{Name: "Acme"}, suite.mockContext)
+
+	suite.organizationServiceMock.On("CreateOrganization", mock.Anything, mock.Anything).
+		Return(&models.Organization{Id: 4, Name: "Acme"}, nil)
+
+	suite.controller.CreateOrganization(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusCreated, response.StatusCode)
+	suite.Contains(response.Body, `"id":4`)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "CreateOrganization", "Acme", int64(12))
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestCreateOrganization_ReturnsInternalServerError() {
+
+	test_utils.SetRequestBody(models.Organization{Name: "Acme"}, suite.mockContext)
+
+	suite.organizationServiceMock.On("CreateOrganization", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.CreateOrganization(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestGetOrganizations_ReturnsTheMemberships() {
+
+	suite.organizationServiceMock.On("GetOrganizations", mock.Anything).Return([]models.OrganizationMembership{
+		{OrganizationId: 4, Name: "Acme", Role: models.ORGANIZATION_ADMIN_ROLE},
+	}, nil)
+
+	suite.controller.GetOrganizations(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"role":"admin"`)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "GetOrganizations", int64(12))
+}
+
+// When there is a malformed organization id param, it should return bad request
+func (suite *OrganizationsControllerUnitTestSuite) TestGetMembersInvalidId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "abc"}}
+
+	suite.controller.GetMembers(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertNotCalled(suite.T(), "GetMembers", mock.Anything, mock.Anything)
+}
+
+// Organizations the user is not a member of are reported as missing
+func (suite *OrganizationsControllerUnitTestSuite) TestGetMembersNotAMember_ReturnsNotFound() {
+
+	suite.organizationServiceMock.On("GetMembers", mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.NO_ORGANIZATION_FOR_ID_ERROR))
+
+	suite.controller.GetMembers(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "GetMembers", int64(4), int64(12))
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestInviteMemberInvalidEmail_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.OrganizationInvitationRequest{Email: "not an email"}, suite.mockContext)
+
+	suite.controller.InviteMember(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertNotCalled(suite.T(), "InviteMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestInviteMemberNotAnAdmin_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.OrganizationInvitationRequest{Email: "invitee@test.com"}, suite.mockContext)
+
+	suite.organizationServiceMock.On("InviteMember", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, "", errors.New(constants.NOT_ORGANIZATION_ADMIN_ERROR))
+
+	suite.controller.InviteMember(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestInviteMember_ReturnsTheToken() {
+
+	request := models.OrganizationInvitationRequest{Email: "invitee@test.com"}
+
+	test_utils.SetRequestBody(request, suite.mockContext)
+
+	suite.organizationServiceMock.On("InviteMember", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.OrganizationInvitation{Id: 7, OrganizationId: 4}, "some-token", nil)
+
+	suite.controller.InviteMember(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusCreated, response.StatusCode)
+	suite.Contains(response.Body, `"token":"some-token"`)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "InviteMember", int64(4), int64(12), request)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestAcceptInvitationInvalid_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.AcceptInvitationRequest{Token: "some-token"}, suite.mockContext)
+
+	suite.organizationServiceMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.INVALID_ORGANIZATION_INVITATION_ERROR))
+
+	suite.controller.AcceptInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestAcceptInvitation_ReturnsOk() {
+
+	test_utils.SetRequestBody(models.AcceptInvitationRequest{Token: "some-token"}, suite.mockContext)
+
+	suite.organizationServiceMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.AcceptInvitation(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "AcceptInvitation", int64(4), int64(12), "some-token")
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestUpdateMemberRoleInvalidRole_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "13"}}
+
+	test_utils.SetRequestBody(models.OrganizationRoleRequest{Role: "owner"}, suite.mockContext)
+
+	suite.controller.UpdateMemberRole(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertNotCalled(suite.T(), "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Demoting the last admin is a conflict
+func (suite *OrganizationsControllerUnitTestSuite) TestUpdateMemberRoleLastAdmin_ReturnsConflict() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "12"}}
+
+	test_utils.SetRequestBody(models.OrganizationRoleRequest{Role: models.ORGANIZATION_MEMBER_ROLE}, suite.mockContext)
+
+	suite.organizationServiceMock.On("UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.LAST_ORGANIZATION_ADMIN_ERROR))
+
+	suite.controller.UpdateMemberRole(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "UpdateMemberRole", int64(4), int64(12), int64(12), models.ORGANIZATION_MEMBER_ROLE)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestUpdateMemberRole_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "13"}}
+
+	test_utils.SetRequestBody(models.OrganizationRoleRequest{Role: models.ORGANIZATION_ADMIN_ROLE}, suite.mockContext)
+
+	suite.organizationServiceMock.On("UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.UpdateMemberRole(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "UpdateMemberRole", int64(4), int64(12), int64(13), models.ORGANIZATION_ADMIN_ROLE)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.ORGANIZATION_ROLE_CHANGE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// When there is a malformed user id param, it should return bad request
+func (suite *OrganizationsControllerUnitTestSuite) TestRemoveMemberInvalidUserId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "abc"}}
+
+	suite.controller.RemoveMember(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertNotCalled(suite.T(), "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestRemoveMemberNotAMember_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "13"}}
+
+	suite.organizationServiceMock.On("RemoveMember", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New(constants.NO_ORGANIZATION_MEMBER_FOR_ID_ERROR))
+
+	suite.controller.RemoveMember(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestRemoveMember_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "organizationId", Value: "4"}, {Key: "userId", Value: "13"}}
+
+	suite.organizationServiceMock.On("RemoveMember", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.RemoveMember(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.organizationServiceMock.AssertCalled(suite.T(), "RemoveMember", int64(4), int64(12), int64(13))
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestSwitchOrganizationWithoutOrganization_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(map[string]any{}, suite.mockContext)
+
+	suite.controller.SwitchOrganization(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "SwitchOrganization", mock.Anything, mock.Anything)
+}
+
+// Personal access tokens are not bound to a session and cannot switch
+func (suite *OrganizationsControllerUnitTestSuite) TestSwitchOrganizationWithoutSession_ReturnsUnauthorized() {
+
+	test_utils.SetRequestBody(map[string]any{"organization_id": 4}, suite.mockContext)
+
+	suite.tokenServiceMock.On("SwitchOrganization", mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.NO_SESSION_FOR_ID_ERROR))
+
+	suite.controller.SwitchOrganization(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestSwitchOrganizationNotAMember_ReturnsNotFound() {
+
+	test_utils.SetRequestBody(map[string]any{"organization_id": 4}, suite.mockContext)
+
+	suite.tokenServiceMock.On("SwitchOrganization", mock.Anything, mock.Anything).
+		Return(nil, errors.New(constants.NO_ORGANIZATION_FOR_ID_ERROR))
+
+	suite.controller.SwitchOrganization(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *OrganizationsControllerUnitTestSuite) TestSwitchOrganization_ReturnsTheToken() {
+
+	test_utils.SetRequestBody(map[string]any{"organization_id": 0}, suite.mockContext)
+
+	suite.tokenServiceMock.On("SwitchOrganization", mock.Anything, mock.Anything).
+		Return(&models.TokenPair{AccessToken: "access", ExpiresIn: 900}, nil)
+
+	suite.controller.SwitchOrganization(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"token":"access"`)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "SwitchOrganization", models.AccessTokenClaims{UserId: 12, SessionId: "current"}, int64(0))
+}
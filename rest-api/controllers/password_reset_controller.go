@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetController struct {
+	passwordResetService interfaces.IPasswordResetService
+	auditLogService      interfaces.IAuditLogService
+}
+
+func (controller PasswordResetController) ForgotPassword(context *gin.Context) {
+
+	var request models.ForgotPasswordRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	err = controller.passwordResetService.RequestPasswordReset(request.Email)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	//the same response is returned whether the email is registered or not
+	context.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for this email, a password reset token was sent to it",
+	})
+}
+
+func (controller PasswordResetController) ResetPassword(context *gin.Context) {
+
+	var request models.ResetPasswordRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	userId, err := controller.passwordResetService.ResetPassword(request.Token, request.Password)
+
+	if err != nil && err.Error() == constants.INVALID_PASSWORD_RESET_TOKEN_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.PASSWORD_RESET_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.PASSWORD_RESET_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{Id: userId}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Password was reset, every session was logged out",
+	})
+}
+
+func NewPasswordResetController(
+	passwordResetService interfaces.IPasswordResetService,
+	auditLogService interfaces.IAuditLogService) *PasswordResetController {
+	return &PasswordResetController{
+		passwordResetService: passwordResetService,
+		auditLogService:      auditLogService,
+	}
+}
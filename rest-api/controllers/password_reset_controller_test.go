@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordResetControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext              *gin.Context
+	passwordResetServiceMock mocks.IPasswordResetService
+	auditLogServiceMock      mocks.IAuditLogService
+	mockResponseWriter       *httptest.ResponseRecorder
+	controller               *PasswordResetController
+}
+
+func TestPasswordResetControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PasswordResetControllerUnitTestSuite{})
+}
+
+func (suite *PasswordResetControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.passwordResetServiceMock = mocks.IPasswordResetService{}
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewPasswordResetController(&suite.passwordResetServiceMock, &suite.auditLogServiceMock)
+}
+
+// When the email is malformed, return a bad request
+func (suite *PasswordResetControllerUnitTestSuite) TestForgotPasswordInvalidEmail_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.ForgotPasswordRequest{Email: "not an email"}, suite.mockContext)
+
+	suite.controller.ForgotPassword(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.passwordResetServiceMock.AssertNotCalled(suite.T(), "RequestPasswordReset", mock.Anything)
+}
+
+func (suite *PasswordResetControllerUnitTestSuite) TestForgotPassword_ReturnsAccepted() {
+
+	test_utils.SetRequestBody(models.ForgotPasswordRequest{Email: "test@test.com"}, suite.mockContext)
+
+	suite.passwordResetServiceMock.On("RequestPasswordReset", mock.Anything).Return(nil)
+
+	suite.controller.ForgotPassword(suite.mockContext)
+
+	suite.passwordResetServiceMock.AssertCalled(suite.T(), "RequestPasswordReset", "test@test.com")
+	suite.Equal(http.StatusAccepted, suite.mockResponseWriter.Code)
+}
+
+// When failing to issue the token, return internal server error
+func (suite *PasswordResetControllerUnitTestSuite) TestForgotPassword_ReturnsInternalServerError() {
+
+	test_utils.SetRequestBody(models.ForgotPasswordRequest{Email: "test@test.com"}, suite.mockContext)
+
+	suite.passwordResetServiceMock.On("RequestPasswordReset", mock.Anything).Return(errors.New("test"))
+
+	suite.controller.ForgotPassword(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// When the password is missing, return a bad request
+func (suite *PasswordResetControllerUnitTestSuite) TestResetPasswordMissingPassword_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.ResetPasswordRequest{Token: "token"}, suite.mockContext)
+
+	suite.controller.ResetPassword(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the token is invalid, expired or used, return a bad request
+func (suite *PasswordResetControllerUnitTestSuite) TestResetPasswordInvalidToken_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.ResetPasswordRequest{Token: "token", Password: "new password"}, suite.mockContext)
+
+	suite.passwordResetServiceMock.On("ResetPassword", mock.Anything, mock.Anything).Return(int64(0), errors.New(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR))
+
+	suite.controller.ResetPassword(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *PasswordResetControllerUnitTestSuite) TestResetPassword_ReturnsOk() {
+
+	test_utils.SetRequestBody(models.ResetPasswordRequest{Token: "token", Password: "new password"}, suite.mockContext)
+
+	suite.passwordResetServiceMock.On("ResetPassword", mock.Anything, mock.Anything).Return(int64(12), nil)
+
+	suite.controller.ResetPassword(suite.mockContext)
+
+	suite.passwordResetServiceMock.AssertCalled(suite.T(), "ResetPassword", "token", "new password")
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.PASSWORD_RESET_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(int64(12), entry.ActorId)
+}
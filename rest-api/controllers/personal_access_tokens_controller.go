@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalAccessTokensController struct {
+	personalAccessTokenService interfaces.IPersonalAccessTokenService
+	auditLogService            interfaces.IAuditLogService
+}
+
+func (controller PersonalAccessTokensController) GetPersonalAccessTokens(context *gin.Context) {
+	tokens, err := controller.personalAccessTokenService.GetPersonalAccessTokens(context.GetInt64("userId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, tokens)
+}
+
+func (controller PersonalAccessTokensController) CreatePersonalAccessToken(context *gin.Context) {
+
+	var request models.CreatePersonalAccessTokenRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	token, err := controller.personalAccessTokenService.CreatePersonalAccessToken(context.GetInt64("userId"), request)
+
+	if err != nil && err.Error() == constants.INVALID_SCOPE_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.PERSONAL_ACCESS_TOKEN_CREATE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.PERSONAL_ACCESS_TOKEN_CREATE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusCreated, token)
+}
+
+func (controller PersonalAccessTokensController) RevokePersonalAccessToken(context *gin.Context) {
+	tokenId, parsingError := strconv.ParseInt(context.Param("tokenId"), 10, 64)
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid token id",
+		})
+		return
+	}
+
+	err := controller.personalAccessTokenService.RevokePersonalAccessToken(tokenId, context.GetInt64("userId"))
+
+	if err != nil && err.Error() == constants.NO_PERSONAL_ACCESS_TOKEN_FOR_ID_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.PERSONAL_ACCESS_TOKEN_REVOKE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.PERSONAL_ACCESS_TOKEN_REVOKE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Personal access token revoked",
+	})
+}
+
+func NewPersonalAccessTokensController(
+	personalAccessTokenService interfaces.IPersonalAccessTokenService,
+	auditLogService interfaces.IAuditLogService) *PersonalAccessTokensController {
+	return &PersonalAccessTokensController{
+		personalAccessTokenService: personalAccessTokenService,
+		auditLogService:            auditLogService,
+	}
+}
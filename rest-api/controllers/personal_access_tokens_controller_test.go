@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersonalAccessTokensControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext                    *gin.Context
+	personalAccessTokenServiceMock mocks.IPersonalAccessTokenService
+	auditLogServiceMock            mocks.IAuditLogService
+	mockResponseWriter             *httptest.ResponseRecorder
+	controller                     *PersonalAccessTokensController
+}
+
+func TestPersonalAccessTokensControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PersonalAccessTokensControllerUnitTestSuite{})
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/users/me/tokens", nil)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.personalAccessTokenServiceMock = mocks.IPersonalAccessTokenService{}
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewPersonalAccessTokensController(&suite.personalAccessTokenServiceMock, &suite.auditLogServiceMock)
+}
+
+// The hash of the tokens is never returned
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestGetPersonalAccessTokens_ReturnsTheTokens() {
+
+	suite.personalAccessTokenServiceMock.On("GetPersonalAccessTokens", int64(12)).Return([]models.PersonalAccessToken{
+		{Id: 3, Name: "deploy script", Scopes: []string{models.EVENTS_READ_SCOPE}, TokenHash: "token hash"},
+	}, nil)
+
+	suite.controller.GetPersonalAccessTokens(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"name":"deploy script"`)
+	suite.NotContains(response.Body, "token hash")
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestCreatePersonalAccessToken_ReturnsTheToken() {
+
+	request := models.CreatePersonalAccessTokenRequest{Name: "deploy script", Scopes: []string{models.EVENTS_READ_SCOPE}}
+
+	test_utils.SetRequestBody(request, suite.mockContext)
+
+	suite.personalAccessTokenServiceMock.On("CreatePersonalAccessToken", int64(12), request).Return(&models.CreatedPersonalAccessToken{
+		PersonalAccessToken: models.PersonalAccessToken{Id: 3, Name: "deploy script"},
+		Token:               "pat_token",
+	}, nil)
+
+	suite.controller.CreatePersonalAccessToken(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusCreated, response.StatusCode)
+	suite.Contains(response.Body, `"token":"pat_token"`)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.PERSONAL_ACCESS_TOKEN_CREATE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// When no scope is requested, return a bad request
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestCreatePersonalAccessTokenWithoutScopes_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.CreatePersonalAccessTokenRequest{Name: "deploy script", Scopes: []string{}}, suite.mockContext)
+
+	suite.controller.CreatePersonalAccessToken(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.personalAccessTokenServiceMock.AssertNotCalled(suite.T(), "CreatePersonalAccessToken", mock.Anything, mock.Anything)
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestCreatePersonalAccessTokenWithUnknownScope_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.CreatePersonalAccessTokenRequest{Name: "deploy script", Scopes: []string{"users:write"}}, suite.mockContext)
+
+	suite.personalAccessTokenServiceMock.On("CreatePersonalAccessToken", mock.Anything, mock.Anything).Return(nil, errors.New(constants.INVALID_SCOPE_ERROR))
+
+	suite.controller.CreatePersonalAccessToken(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestRevokePersonalAccessToken_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "tokenId", Value: "3"}}
+
+	suite.personalAccessTokenServiceMock.On("RevokePersonalAccessToken", int64(3), int64(12)).Return(nil)
+
+	suite.controller.RevokePersonalAccessToken(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.PERSONAL_ACCESS_TOKEN_REVOKE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestRevokeMissingPersonalAccessToken_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "tokenId", Value: "3"}}
+
+	suite.personalAccessTokenServiceMock.On("RevokePersonalAccessToken", mock.Anything, mock.Anything).Return(errors.New(constants.NO_PERSONAL_ACCESS_TOKEN_FOR_ID_ERROR))
+
+	suite.controller.RevokePersonalAccessToken(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *PersonalAccessTokensControllerUnitTestSuite) TestRevokePersonalAccessTokenInvalidId_ReturnsBadRequest() {
+
+	suite.mockContext.Params = gin.Params{{Key: "tokenId", Value: "abc"}}
+
+	suite.controller.RevokePersonalAccessToken(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"example.com/constants"
 	interfaces "example.com/interfaces/services"
+	"example.com/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,9 +24,60 @@ func (controller RegistrationsController) RegisterForEvent(context *gin.Context)
 		return
 	}
 
+	allowConflict, parsingError := strconv.ParseBool(context.DefaultQuery("allow_conflict", "false"))
+
+	if parsingError != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid allow_conflict parameter",
+		})
+		return
+	}
+
 	userId := context.GetInt64("userId")
 
-	err := controller.registrationService.CreateRegistration(eventId, userId)
+	conflicts, err := controller.registrationService.CreateRegistration(eventId, userId, context.GetInt64("organizationId"), allowConflict)
+
+	if err != nil && err.Error() == constants.SCHEDULE_CONFLICT_ERROR {
+		scheduledEvents := make([]models.ScheduledEvent, 0, len(conflicts))
+
+		for _, conflict := range conflicts {
+			scheduledEvents = append(scheduledEvents, models.NewScheduledEvent(conflict))
+		}
+
+		context.JSON(http.StatusConflict, gin.H{
+			"error":     "Event overlaps with events you are already registered for, pass allow_conflict=true to register anyway",
+			"conflicts": scheduledEvents,
+		})
+		return
+	}
+
+	//events of other organizations are reported as missing
+	if err != nil && err.Error() == constants.NO_EVENT_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, nil)
+		return
+	}
+
+	if err != nil && err.Error() == constants.NOT_INVITED_ERROR {
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Only invitees can register for a private event",
+		})
+		return
+	}
+
+	if err != nil && err.Error() == constants.DUPLICATE_RECORD_ERROR {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "Already registered for this event",
+		})
+		return
+	}
+
+	//the event or the account was deleted while registering
+	if err != nil && err.Error() == constants.MISSING_REFERENCED_RECORD_ERROR {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": "The event or the account no longer exists",
+		})
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -51,7 +104,12 @@ func (controller RegistrationsController) CancelEventRegistration(context *gin.C
 
 	userId := context.GetInt64("userId")
 
-	err := controller.registrationService.DeleteRegistration(eventId, userId)
+	err := controller.registrationService.DeleteRegistration(eventId, userId, context.GetInt64("organizationId"))
+
+	if err != nil && err.Error() == constants.NO_EVENT_FOR_ID_ERROR {
+		context.JSON(http.StatusNotFound, nil)
+		return
+	}
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -65,6 +123,19 @@ func (controller RegistrationsController) CancelEventRegistration(context *gin.C
 	})
 }
 
+func (controller RegistrationsController) GetScheduleConflicts(context *gin.Context) {
+	conflicts, err := controller.registrationService.GetScheduleConflicts(context.GetInt64("userId"), context.GetInt64("organizationId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, conflicts)
+}
+
 func NewRegistrationsController(registrationService interfaces.IRegistrationService) *RegistrationsController {
 	return &RegistrationsController{
 		registrationService: registrationService,
@@ -7,7 +7,10 @@ import (
 	"strconv"
 	"testing"
 
+	"example.com/constants"
 	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -31,6 +34,8 @@ func (suite *RegistrationsControllerUnitTestSuite) SetupTest() {
 
 	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
 
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/events/1/register", nil)
+
 	suite.registrationServiceMock = mocks.IRegistrationService{}
 
 	suite.controller = NewRegistrationsController(&suite.registrationServiceMock)
@@ -72,11 +77,11 @@ func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEvent_CreatesR
 
 	suite.mockContext.Set("userId", expectedUserId)
 
-	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(errors.New("test error"))
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
 
 	suite.controller.RegisterForEvent(suite.mockContext)
 
-	suite.registrationServiceMock.AssertCalled(suite.T(), "CreateRegistration", expectedEventId, expectedUserId)
+	suite.registrationServiceMock.AssertCalled(suite.T(), "CreateRegistration", expectedEventId, expectedUserId, int64(0), false)
 }
 
 // When failing to create a registration return internal server error
@@ -91,13 +96,90 @@ func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEvent_ReturnsI
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(errors.New("test error"))
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
 
 	suite.controller.RegisterForEvent(suite.mockContext)
 
 	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
 }
 
+// Events of another organization cannot be found
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventNotFound_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("organizationId", int64(4))
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.NO_EVENT_FOR_ID_ERROR))
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+	suite.registrationServiceMock.AssertCalled(suite.T(), "CreateRegistration", int64(1), int64(12), int64(4), false)
+}
+
+// When the event is private and the user was not invited, return forbidden
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventNotInvited_ReturnsForbidden() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.NOT_INVITED_ERROR))
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+// When the user is registered already, return conflict
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventTwice_ReturnsConflict() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.DUPLICATE_RECORD_ERROR))
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusConflict, response.StatusCode)
+	suite.Contains(response.Body, "Already registered")
+}
+
+// When the event or the account was deleted while registering, return conflict
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForDeletedEvent_ReturnsConflict() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.MISSING_REFERENCED_RECORD_ERROR))
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
 func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEvent_ReturnsCreated() {
 
 	suite.mockContext.Params = gin.Params{
@@ -109,13 +191,75 @@ func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEvent_ReturnsC
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(nil)
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	suite.Equal(http.StatusCreated, suite.mockResponseWriter.Code)
+}
+
+// When the event overlaps with other registrations, return a conflict along with the overlapping events
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventWithConflicts_ReturnsConflict() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]models.Event{{Id: 2, Name: "overlapping"}}, errors.New(constants.SCHEDULE_CONFLICT_ERROR))
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusConflict, response.StatusCode)
+	suite.Contains(response.Body, `"id":2`)
+	suite.Contains(response.Body, `"name":"overlapping"`)
+}
+
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventAllowingConflicts_PassesTheFlag() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/events/1/register?allow_conflict=true", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.registrationServiceMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 
 	suite.controller.RegisterForEvent(suite.mockContext)
 
+	suite.registrationServiceMock.AssertCalled(suite.T(), "CreateRegistration", int64(1), int64(12), int64(0), true)
 	suite.Equal(http.StatusCreated, suite.mockResponseWriter.Code)
 }
 
+func (suite *RegistrationsControllerUnitTestSuite) TestRegisterForEventInvalidAllowConflict_ReturnsBadRequest() {
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/events/1/register?allow_conflict=maybe", nil)
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.controller.RegisterForEvent(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.registrationServiceMock.AssertNotCalled(suite.T(), "CreateRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // When the request param is missing, return a bad request
 func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistrationWhenParamIsMissing_ReturnsBadRequest() {
 
@@ -152,11 +296,11 @@ func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistration_D
 
 	suite.mockContext.Set("userId", expectedUserId)
 
-	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(errors.New("test error"))
+	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test error"))
 
 	suite.controller.CancelEventRegistration(suite.mockContext)
 
-	suite.registrationServiceMock.AssertCalled(suite.T(), "DeleteRegistration", expectedEventId, expectedUserId)
+	suite.registrationServiceMock.AssertCalled(suite.T(), "DeleteRegistration", expectedEventId, expectedUserId, int64(0))
 }
 
 // When failing to delete a registration return internal server error
@@ -171,13 +315,31 @@ func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistration_R
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(errors.New("test error"))
+	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test error"))
 
 	suite.controller.CancelEventRegistration(suite.mockContext)
 
 	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
 }
 
+func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistrationNotFound_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{
+		{
+			Key:   "id",
+			Value: "1",
+		},
+	}
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(errors.New(constants.NO_EVENT_FOR_ID_ERROR))
+
+	suite.controller.CancelEventRegistration(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
 func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistration_ReturnsOk() {
 
 	suite.mockContext.Params = gin.Params{
@@ -189,9 +351,39 @@ func (suite *RegistrationsControllerUnitTestSuite) TestCancelEventRegistration_R
 
 	suite.mockContext.Set("userId", int64(12))
 
-	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(nil)
+	suite.registrationServiceMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	suite.controller.CancelEventRegistration(suite.mockContext)
 
 	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
 }
+
+func (suite *RegistrationsControllerUnitTestSuite) TestGetScheduleConflicts_ReturnsTheConflicts() {
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.registrationServiceMock.On("GetScheduleConflicts", mock.Anything, mock.Anything).Return([]models.ScheduleConflict{
+		{
+			First:  models.ScheduledEvent{Id: 1},
+			Second: models.ScheduledEvent{Id: 2},
+		},
+	}, nil)
+
+	suite.controller.GetScheduleConflicts(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.registrationServiceMock.AssertCalled(suite.T(), "GetScheduleConflicts", int64(12), int64(0))
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"second":{"id":2`)
+}
+
+// When failing to fetch the conflicts return internal server error
+func (suite *RegistrationsControllerUnitTestSuite) TestGetScheduleConflicts_ReturnsInternalServerError() {
+
+	suite.registrationServiceMock.On("GetScheduleConflicts", mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+
+	suite.controller.GetScheduleConflicts(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
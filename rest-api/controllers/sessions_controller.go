@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionsController struct {
+	tokenService    interfaces.ITokenService
+	auditLogService interfaces.IAuditLogService
+}
+
+func (controller SessionsController) GetSessions(context *gin.Context) {
+	sessions, err := controller.tokenService.GetSessions(context.GetInt64("userId"), getTokenClaims(context).SessionId)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, sessions)
+}
+
+// Signs out of the session, for instance on a lost device, without waiting for its tokens to expire
+func (controller SessionsController) RevokeSession(context *gin.Context) {
+	err := controller.tokenService.RevokeSession(context.Param("sessionId"), context.GetInt64("userId"))
+
+	if err != nil && err.Error() == constants.NO_SESSION_FOR_ID_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.SESSION_REVOKE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.SESSION_REVOKE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked",
+	})
+}
+
+func NewSessionsController(tokenService interfaces.ITokenService, auditLogService interfaces.IAuditLogService) *SessionsController {
+	return &SessionsController{
+		tokenService:    tokenService,
+		auditLogService: auditLogService,
+	}
+}
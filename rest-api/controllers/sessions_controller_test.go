@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionsControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext         *gin.Context
+	tokenServiceMock    mocks.ITokenService
+	auditLogServiceMock mocks.IAuditLogService
+	mockResponseWriter  *httptest.ResponseRecorder
+	controller          *SessionsController
+}
+
+func TestSessionsControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &SessionsControllerUnitTestSuite{})
+}
+
+func (suite *SessionsControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/users/me/sessions", nil)
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{UserId: 12, SessionId: "current"})
+
+	suite.tokenServiceMock = mocks.ITokenService{}
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewSessionsController(&suite.tokenServiceMock, &suite.auditLogServiceMock)
+}
+
+func (suite *SessionsControllerUnitTestSuite) TestGetSessions_ReturnsTheSessions() {
+
+	suite.tokenServiceMock.On("GetSessions", mock.Anything, mock.Anything).Return([]models.Session{
+		{Id: "current", IpAddress: "10.0.0.1", UserAgent: "curl", Current: true},
+	}, nil)
+
+	suite.controller.GetSessions(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"id":"current"`)
+	suite.Contains(response.Body, `"ip_address":"10.0.0.1"`)
+	suite.Contains(response.Body, `"current":true`)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "GetSessions", int64(12), "current")
+}
+
+func (suite *SessionsControllerUnitTestSuite) TestGetSessions_ReturnsAnError() {
+
+	suite.tokenServiceMock.On("GetSessions", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
+
+	suite.controller.GetSessions(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+func (suite *SessionsControllerUnitTestSuite) TestRevokeSession_ReturnsOk() {
+
+	suite.mockContext.Params = gin.Params{{Key: "sessionId", Value: "other"}}
+
+	suite.tokenServiceMock.On("RevokeSession", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.RevokeSession(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "RevokeSession", "other", int64(12))
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.SESSION_REVOKE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// When the user has no such active session, return not found
+func (suite *SessionsControllerUnitTestSuite) TestRevokeSessionUnknownSession_ReturnsNotFound() {
+
+	suite.mockContext.Params = gin.Params{{Key: "sessionId", Value: "other"}}
+
+	suite.tokenServiceMock.On("RevokeSession", mock.Anything, mock.Anything).Return(errors.New(constants.NO_SESSION_FOR_ID_ERROR))
+
+	suite.controller.RevokeSession(suite.mockContext)
+
+	suite.Equal(http.StatusNotFound, suite.mockResponseWriter.Code)
+}
+
+func (suite *SessionsControllerUnitTestSuite) TestRevokeSession_ReturnsAnError() {
+
+	suite.mockContext.Params = gin.Params{{Key: "sessionId", Value: "other"}}
+
+	suite.tokenServiceMock.On("RevokeSession", mock.Anything, mock.Anything).Return(errors.New("test"))
+
+	suite.controller.RevokeSession(suite.mockContext)
+
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
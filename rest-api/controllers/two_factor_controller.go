@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"net/http"
+
+	"example.com/constants"
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorController struct {
+	twoFactorService     interfaces.ITwoFactorService
+	userService          interfaces.IUserService
+	loginThrottleService interfaces.ILoginThrottleService
+	auditLogService      interfaces.IAuditLogService
+}
+
+func (controller TwoFactorController) StartEnrollment(context *gin.Context) {
+	enrollment, err := controller.twoFactorService.StartEnrollment(context.GetInt64("userId"))
+
+	if err != nil && err.Error() == constants.TOTP_ALREADY_ENABLED_ERROR {
+		context.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, enrollment)
+}
+
+func (controller TwoFactorController) ConfirmEnrollment(context *gin.Context) {
+
+	var request models.TotpCodeRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	recoveryCodes, err := controller.twoFactorService.ConfirmEnrollment(context.GetInt64("userId"), request.Code)
+
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_TOTP_CODE_ERROR, constants.TOTP_NOT_ENROLLED_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_ENABLE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+			context.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case constants.TOTP_ALREADY_ENABLED_ERROR:
+			context.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+		}
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_ENABLE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message":        "Two factor authentication enabled, store the recovery codes somewhere safe",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (controller TwoFactorController) DisableTwoFactor(context *gin.Context) {
+
+	var request models.TotpCodeRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	profile, err := controller.userService.GetProfile(context.GetInt64("userId"))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	user := models.User{Id: profile.UserId, Email: profile.Email}
+
+	//wrong codes count as failed logins of the account, a stolen access token cannot be used to
+	//guess the code any faster than to guess it while logging in
+	retryAfter, err := controller.loginThrottleService.CheckLogin(user.Email, context.ClientIP())
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if retryAfter > 0 {
+		recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_DISABLE_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, user, "Too many failed login attempts")
+
+		respondWithRetryAfter(context, retryAfter)
+		return
+	}
+
+	err = controller.twoFactorService.DisableTwoFactor(user.Id, request.Code)
+
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_TOTP_CODE_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_DISABLE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, user, err.Error())
+
+			err = controller.loginThrottleService.RecordFailedLogin(user.Email, context.ClientIP())
+
+			if err != nil {
+				context.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Unexpected error occurred",
+				})
+				return
+			}
+
+			context.JSON(http.StatusBadRequest, gin.H{
+				"error": constants.INVALID_TOTP_CODE_ERROR,
+			})
+		case constants.TOTP_NOT_ENABLED_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_DISABLE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, user, err.Error())
+
+			context.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+		}
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.TWO_FACTOR_DISABLE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, user, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Two factor authentication disabled",
+	})
+}
+
+func NewTwoFactorController(
+	twoFactorService interfaces.ITwoFactorService,
+	userService interfaces.IUserService,
+	loginThrottleService interfaces.ILoginThrottleService,
+	auditLogService interfaces.IAuditLogService) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorService:     twoFactorService,
+		userService:          userService,
+		loginThrottleService: loginThrottleService,
+		auditLogService:      auditLogService,
+	}
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorControllerUnitTestSuite struct {
+	suite.Suite
+	mockContext              *gin.Context
+	twoFactorServiceMock     mocks.ITwoFactorService
+	userServiceMock          mocks.IUserService
+	loginThrottleServiceMock mocks.ILoginThrottleService
+	auditLogServiceMock      mocks.IAuditLogService
+	mockResponseWriter       *httptest.ResponseRecorder
+	controller               *TwoFactorController
+}
+
+func TestTwoFactorControllerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &TwoFactorControllerUnitTestSuite{})
+}
+
+func (suite *TwoFactorControllerUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/users/me/2fa", nil)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.twoFactorServiceMock = mocks.ITwoFactorService{}
+	suite.userServiceMock = mocks.IUserService{}
+	suite.loginThrottleServiceMock = mocks.ILoginThrottleService{}
+
+	suite.userServiceMock.On("GetProfile", mock.Anything).Return(&models.UserProfile{UserId: 12, Email: "test@test.com"}, nil).Maybe()
+	suite.loginThrottleServiceMock.On("CheckLogin", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Maybe()
+	suite.loginThrottleServiceMock.On("RecordFailedLogin", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewTwoFactorController(&suite.twoFactorServiceMock, &suite.userServiceMock, &suite.loginThrottleServiceMock, &suite.auditLogServiceMock)
+}
+
+// When two factor authentication is already enabled, return a conflict
+func (suite *TwoFactorControllerUnitTestSuite) TestStartEnrollmentAlreadyEnabled_ReturnsConflict() {
+
+	suite.twoFactorServiceMock.On("StartEnrollment", mock.Anything).Return(nil, errors.New(constants.TOTP_ALREADY_ENABLED_ERROR))
+
+	suite.controller.StartEnrollment(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+}
+
+func (suite *TwoFactorControllerUnitTestSuite) TestStartEnrollment_ReturnsTheUri() {
+
+	suite.twoFactorServiceMock.On("StartEnrollment", mock.Anything).Return(&models.TotpEnrollment{
+		Secret:     "SECRET",
+		OtpauthUri: "otpauth://totp/test",
+		QrCode:     "data:image/png;base64,",
+	}, nil)
+
+	suite.controller.StartEnrollment(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"otpauth_uri":"otpauth://totp/test"`)
+	suite.twoFactorServiceMock.AssertCalled(suite.T(), "StartEnrollment", int64(12))
+}
+
+// When the code is invalid, return a bad request
+func (suite *TwoFactorControllerUnitTestSuite) TestConfirmEnrollmentInvalidCode_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.TotpCodeRequest{Code: "123456"}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("ConfirmEnrollment", mock.Anything, mock.Anything).Return(nil, errors.New(constants.INVALID_TOTP_CODE_ERROR))
+
+	suite.controller.ConfirmEnrollment(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *TwoFactorControllerUnitTestSuite) TestConfirmEnrollment_ReturnsTheRecoveryCodes() {
+
+	test_utils.SetRequestBody(models.TotpCodeRequest{Code: "123456"}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("ConfirmEnrollment", mock.Anything, mock.Anything).Return([]string{"abcd-efgh-ijkl-mnop"}, nil)
+
+	suite.controller.ConfirmEnrollment(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, "abcd-efgh-ijkl-mnop")
+	suite.twoFactorServiceMock.AssertCalled(suite.T(), "ConfirmEnrollment", int64(12), "123456")
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.TWO_FACTOR_ENABLE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// When two factor authentication is not enabled, return a bad request
+func (suite *TwoFactorControllerUnitTestSuite) TestDisableTwoFactorNotEnabled_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.TotpCodeRequest{Code: "123456"}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("DisableTwoFactor", mock.Anything, mock.Anything).Return(errors.New(constants.TOTP_NOT_ENABLED_ERROR))
+
+	suite.controller.DisableTwoFactor(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *TwoFactorControllerUnitTestSuite) TestDisableTwoFactor_ReturnsOk() {
+
+	test_utils.SetRequestBody(models.TotpCodeRequest{Code: "123456"}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("DisableTwoFactor", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.DisableTwoFactor(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.TWO_FACTOR_DISABLE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// A wrong code counts as a failed login of the account
+func (suite *TwoFactorControllerUnitTestSuite) TestDisableTwoFactorInvalidCode_RecordsAFailedLogin() {
+
+	test_utils.SetRequestBody(models.TotpCodeRequest{Code: "000000"}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("DisableTwoFactor", mock.Anything, mock.Anything).Return(errors.New(constants.INVALID_TOTP_CODE_ERROR))
+
+	suite.controller.DisableTwoFactor(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "test@test.com", mock.Anything)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.TWO_FACTOR_DISABLE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.FAILURE_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(int64(12), entry.ActorId)
+}
+
+// Once the account is locked by wrong codes, further codes are not checked anymore
+func (suite *TwoFactorControllerUnitTestSuite) TestDisableTwoFactorRepeatedInvalidCodes_LocksTheAccount() {
+
+	const lockoutThreshold = 5
+
+	failures := 0
+
+	suite.loginThrottleServiceMock = mocks.ILoginThrottleService{}
+	suite.loginThrottleServiceMock.On("CheckLogin", mock.Anything, mock.Anything).Return(
+		func(email, ip string) time.Duration {
+			if failures >= lockoutThreshold {
+				return time.Minute
+			}
+
+			return 0
+		},
+		func(email, ip string) error {
+			return nil
+		})
+	suite.loginThrottleServiceMock.On("RecordFailedLogin", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		failures++
+	})
+	suite.twoFactorServiceMock.On("DisableTwoFactor", mock.Anything, mock.Anything).Return(errors.New(constants.INVALID_TOTP_CODE_ERROR))
+
+	suite.controller = NewTwoFactorController(&suite.twoFactorServiceMock, &suite.userServiceMock, &suite.loginThrottleServiceMock, &suite.auditLogServiceMock)
+
+	for range lockoutThreshold + 1 {
+		suite.mockResponseWriter = httptest.NewRecorder()
+		suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+		suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/users/me/2fa/disable", nil)
+		suite.mockContext.Set("userId", int64(12))
+
+		test_utils.SetRequestBody(models.TotpCodeRequest{Code: "000000"}, suite.mockContext)
+
+		suite.controller.DisableTwoFactor(suite.mockContext)
+	}
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+	suite.twoFactorServiceMock.AssertNumberOfCalls(suite.T(), "DisableTwoFactor", lockoutThreshold)
+}
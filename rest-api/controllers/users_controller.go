@@ -1,26 +1,34 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
-	libInterfaces "example.com/interfaces/lib"
+	"example.com/constants"
 	serviceInterfaces "example.com/interfaces/services"
 	"example.com/models"
 	"github.com/gin-gonic/gin"
 )
 
 type UsersController struct {
-	userService   serviceInterfaces.IUserService
-	jwtAuthorizer libInterfaces.IJwtAuthorizer
+	userService              serviceInterfaces.IUserService
+	tokenService             serviceInterfaces.ITokenService
+	emailVerificationService serviceInterfaces.IEmailVerificationService
+	loginThrottleService     serviceInterfaces.ILoginThrottleService
+	twoFactorService         serviceInterfaces.ITwoFactorService
+	auditLogService          serviceInterfaces.IAuditLogService
 }
 
 func (controller UsersController) CreateUser(context *gin.Context) {
 
-	var user models.User
+	var request models.SignupRequest
 
-	err := context.ShouldBindJSON(&user)
+	err := context.ShouldBindJSON(&request)
 
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{
@@ -29,15 +37,27 @@ func (controller UsersController) CreateUser(context *gin.Context) {
 		return
 	}
 
+	user := models.User{
+		Email:    request.Email,
+		Password: request.Password,
+	}
+
 	err = controller.userService.CreateUser(&user)
 
 	if err != nil {
+		recordAuditEntry(controller.auditLogService, context, models.SIGNUP_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, user, "Unable to create user")
+
 		context.JSON(http.StatusInternalServerError, gin.H{
 			"Error": fmt.Sprintf("Unable to create user, error: %v\n", err),
 		})
 		return
 	}
 
+	recordAuditEntry(controller.auditLogService, context, models.SIGNUP_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, user, "")
+
+	//the account exists at this point, the user can ask for another email if this one fails
+	controller.emailVerificationService.SendVerificationEmail(user)
+
 	context.JSON(http.StatusCreated, user)
 }
 
@@ -54,8 +74,33 @@ func (controller UsersController) Login(context *gin.Context) {
 		return
 	}
 
+	retryAfter, err := controller.loginThrottleService.CheckLogin(user.Email, context.ClientIP())
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
+		})
+		return
+	}
+
+	if retryAfter > 0 {
+		recordAuditEntry(controller.auditLogService, context, models.LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, user, "Too many failed login attempts")
+
+		respondWithRetryAfter(context, retryAfter)
+		return
+	}
+
 	successfulValidation, err := controller.userService.ValidateCredentials(&user)
 
+	if err != nil && err.Error() == constants.ACCOUNT_DISABLED_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, user, "Account is disabled")
+
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": "Account is disabled",
+		})
+		return
+	}
+
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
 			"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
@@ -64,11 +109,387 @@ func (controller UsersController) Login(context *gin.Context) {
 	}
 
 	if !successfulValidation {
+		recordAuditEntry(controller.auditLogService, context, models.LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, user, "Invalid credentials")
+
+		err = controller.loginThrottleService.RecordFailedLogin(user.Email, context.ClientIP())
+
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
+			})
+			return
+		}
+
 		context.JSON(http.StatusUnauthorized, gin.H{})
 		return
 	}
 
-	token, err := controller.jwtAuthorizer.GenerateToken(user.Email, strconv.FormatInt(user.Id, 10))
+	twoFactorEnabled, err := controller.twoFactorService.IsTwoFactorEnabled(user.Id)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
+		})
+		return
+	}
+
+	//failed attempts are only forgotten once the second factor is validated as well
+	if twoFactorEnabled {
+		challenge, err := controller.twoFactorService.CreateMfaChallenge(user.Id)
+
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
+			})
+			return
+		}
+
+		respondWithMfaChallenge(context, *challenge)
+		return
+	}
+
+	controller.completeLogin(context, user, models.LOGIN_AUDIT_ACTION)
+}
+
+// Exchanges the token returned by Login, along with a code of the authenticator app or a
+// recovery code, for the access token
+func (controller UsersController) CompleteMfaLogin(context *gin.Context) {
+
+	var request models.MfaLoginRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	challengeUser, err := controller.twoFactorService.GetMfaChallengeUser(request.MfaToken)
+
+	if err != nil && err.Error() == constants.INVALID_MFA_TOKEN_ERROR {
+		recordAuditEntry(controller.auditLogService, context, models.MFA_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+		context.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	//wrong codes count as failed logins of the account, so the limit holds across challenges
+	retryAfter, err := controller.loginThrottleService.CheckLogin(challengeUser.Email, context.ClientIP())
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if retryAfter > 0 {
+		recordAuditEntry(controller.auditLogService, context, models.MFA_LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, *challengeUser, "Too many failed login attempts")
+
+		respondWithRetryAfter(context, retryAfter)
+		return
+	}
+
+	user, err := controller.twoFactorService.CompleteMfaChallenge(request.MfaToken, request.Code)
+
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_TOTP_CODE_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.MFA_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, *challengeUser, err.Error())
+
+			err = controller.loginThrottleService.RecordFailedLogin(challengeUser.Email, context.ClientIP())
+
+			if err != nil {
+				context.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Unexpected error occurred",
+				})
+				return
+			}
+
+			context.JSON(http.StatusUnauthorized, gin.H{
+				"error": constants.INVALID_TOTP_CODE_ERROR,
+			})
+		case constants.INVALID_MFA_TOKEN_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.MFA_LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, *challengeUser, err.Error())
+
+			context.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+		case constants.ACCOUNT_DISABLED_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.MFA_LOGIN_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, *challengeUser, "Account is disabled")
+
+			context.JSON(http.StatusForbidden, gin.H{
+				"error": "Account is disabled",
+			})
+		default:
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+		}
+		return
+	}
+
+	controller.completeLogin(context, *user, models.MFA_LOGIN_AUDIT_ACTION)
+}
+
+func (controller UsersController) RefreshToken(context *gin.Context) {
+
+	var request models.RefreshTokenRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	tokens, err := controller.tokenService.RefreshTokens(request.RefreshToken, sessionClient(context))
+
+	if err != nil {
+		switch err.Error() {
+		case constants.INVALID_REFRESH_TOKEN_ERROR, constants.REFRESH_TOKEN_REUSE_ERROR:
+			recordAuditEntry(controller.auditLogService, context, models.TOKEN_REFRESH_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+
+			context.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			context.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unexpected error occurred",
+			})
+		}
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.TOKEN_REFRESH_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{Id: tokens.UserId}, "")
+
+	context.JSON(http.StatusOK, tokens)
+}
+
+func (controller UsersController) Logout(context *gin.Context) {
+
+	var request models.LogoutRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	//the refresh token is optional, so an empty body is accepted
+	if err != nil && !errors.Is(err, io.EOF) {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	err = controller.tokenService.Logout(getTokenClaims(context), request.RefreshToken)
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.LOGOUT_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Logged out",
+	})
+}
+
+func (controller UsersController) LogoutEverywhere(context *gin.Context) {
+
+	err := controller.tokenService.LogoutEverywhere(getTokenClaims(context))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.LOGOUT_EVERYWHERE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Logged out of every session",
+	})
+}
+
+func (controller UsersController) GetProfile(context *gin.Context) {
+	profile, err := controller.userService.GetProfile(context.GetInt64("userId"))
+
+	if err != nil {
+		respondToAccountError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, profile)
+}
+
+func (controller UsersController) UpdateProfile(context *gin.Context) {
+
+	var request models.UpdateProfileRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	profile, err := controller.userService.UpdateProfile(context.GetInt64("userId"), request)
+
+	if err != nil {
+		respondToAccountError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, profile)
+}
+
+// Other sessions are logged out, the current one gets new tokens so it can carry on
+func (controller UsersController) ChangePassword(context *gin.Context) {
+
+	var request models.ChangePasswordRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	user, err := controller.userService.ChangePassword(context.GetInt64("userId"), request.CurrentPassword, request.NewPassword)
+
+	if err != nil {
+		recordAuditEntry(controller.auditLogService, context, models.PASSWORD_CHANGE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+		respondToAccountError(context, err)
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.PASSWORD_CHANGE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	err = controller.tokenService.Logout(getTokenClaims(context), "")
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	tokens, err := controller.tokenService.IssueTokens(*user, sessionClient(context))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{
+		"message":       "Password changed, other sessions were logged out",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// The email only changes once the link sent to the new address is opened
+func (controller UsersController) ChangeEmail(context *gin.Context) {
+
+	var request models.ChangeEmailRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	user, err := controller.userService.RequestEmailChange(context.GetInt64("userId"), request.Password, request.Email)
+
+	if err != nil {
+		recordAuditEntry(controller.auditLogService, context, models.EMAIL_CHANGE_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+		respondToAccountError(context, err)
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.EMAIL_CHANGE_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	err = controller.emailVerificationService.SendEmailChangeVerification(*user, request.Email)
+
+	if err != nil && err.Error() == constants.VERIFICATION_EMAIL_THROTTLED_ERROR {
+		context.JSON(http.StatusTooManyRequests, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	context.JSON(http.StatusAccepted, gin.H{
+		"message": "Open the link sent to the new email address to confirm the change",
+	})
+}
+
+func (controller UsersController) DeleteAccount(context *gin.Context) {
+
+	var request models.DeleteAccountRequest
+
+	err := context.ShouldBindJSON(&request)
+
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid payload data",
+		})
+		return
+	}
+
+	err = controller.userService.DeleteAccount(context.GetInt64("userId"), request.Password)
+
+	if err != nil {
+		recordAuditEntry(controller.auditLogService, context, models.ACCOUNT_DELETION_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, models.User{}, err.Error())
+		respondToAccountError(context, err)
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, models.ACCOUNT_DELETION_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, models.User{}, "")
+
+	//the account is gone at this point, the access token would be refused by most endpoints anyway
+	controller.tokenService.Logout(getTokenClaims(context), "")
+
+	context.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted",
+	})
+}
+
+// Forgets the failed attempts of the account and issues the tokens
+func (controller UsersController) completeLogin(context *gin.Context, user models.User, action string) {
+	err := controller.loginThrottleService.RecordSuccessfulLogin(user.Email)
 
 	if err != nil {
 		context.JSON(http.StatusInternalServerError, gin.H{
@@ -77,15 +498,119 @@ func (controller UsersController) Login(context *gin.Context) {
 		return
 	}
 
+	tokens, err := controller.tokenService.IssueTokens(user, sessionClient(context))
+
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"Error": fmt.Sprintf("Unable to validate credentials: %v\n", err),
+		})
+		return
+	}
+
+	recordAuditEntry(controller.auditLogService, context, action, models.SUCCESS_AUDIT_OUTCOME, user, "")
+
+	respondWithTokens(context, *tokens)
+}
+
+// Appends an entry about the request to the audit log. The actor is the user passed in when known,
+// the authenticated user otherwise. Failing to record the entry does not fail the request
+func recordAuditEntry(auditLogService serviceInterfaces.IAuditLogService, context *gin.Context, action, outcome string, user models.User, reason string) {
+	entry := models.NewAuditLogEntry(action, outcome, sessionClient(context), auditResource(context))
+
+	entry.ActorId = user.Id
+	entry.ActorEmail = user.Email
+	entry.Reason = reason
+
+	if entry.ActorId == 0 {
+		entry.ActorId = context.GetInt64("userId")
+	}
+
+	auditLogService.Record(entry)
+}
+
+func respondWithRetryAfter(context *gin.Context, retryAfter time.Duration) {
+	retryAfterSeconds := int64(math.Ceil(retryAfter.Seconds()))
+
+	context.Header("Retry-After", strconv.FormatInt(retryAfterSeconds, 10))
+	context.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts",
+		"retry_after": retryAfterSeconds,
+	})
+}
+
+func respondWithMfaChallenge(context *gin.Context, challenge models.MfaChallengeToken) {
+	context.JSON(http.StatusOK, gin.H{
+		"message":      "Two factor authentication code required",
+		"mfa_required": true,
+		"mfa_token":    challenge.Token,
+		"expires_in":   challenge.ExpiresIn,
+	})
+}
+
+func respondWithTokens(context *gin.Context, tokens models.TokenPair) {
 	context.JSON(http.StatusOK, gin.H{
-		"message": "Logged in successfully",
-		"token":   token,
+		"message":       "Logged in successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
-func NewUsersController(userService serviceInterfaces.IUserService, jwtAuthorizer libInterfaces.IJwtAuthorizer) *UsersController {
+func respondToAccountError(context *gin.Context, err error) {
+	switch err.Error() {
+	case constants.INVALID_PASSWORD_ERROR:
+		context.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	case constants.PASSWORD_NOT_SET_ERROR:
+		context.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case constants.EMAIL_ALREADY_IN_USE_ERROR:
+		context.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case constants.NO_USER_FOR_ID_ERROR:
+		context.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		context.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+	}
+}
+
+// Claims of the access token, as set by the authentication middleware
+func getTokenClaims(context *gin.Context) models.AccessTokenClaims {
+	claims, _ := context.Get("tokenClaims")
+
+	tokenClaims, _ := claims.(models.AccessTokenClaims)
+
+	return tokenClaims
+}
+
+func auditResource(context *gin.Context) string {
+	return context.Request.Method + " " + context.Request.URL.Path
+}
+
+func sessionClient(context *gin.Context) models.SessionClient {
+	return models.NewSessionClient(context.ClientIP(), context.Request.UserAgent())
+}
+
+func NewUsersController(
+	userService serviceInterfaces.IUserService,
+	tokenService serviceInterfaces.ITokenService,
+	emailVerificationService serviceInterfaces.IEmailVerificationService,
+	loginThrottleService serviceInterfaces.ILoginThrottleService,
+	twoFactorService serviceInterfaces.ITwoFactorService,
+	auditLogService serviceInterfaces.IAuditLogService) *UsersController {
 	return &UsersController{
-		userService:   userService,
-		jwtAuthorizer: jwtAuthorizer,
+		userService:              userService,
+		tokenService:             tokenService,
+		emailVerificationService: emailVerificationService,
+		loginThrottleService:     loginThrottleService,
+		twoFactorService:         twoFactorService,
+		auditLogService:          auditLogService,
 	}
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"example.com/constants"
 	"example.com/mocks"
 	"example.com/models"
 	"example.com/test_utils"
@@ -18,11 +20,15 @@ import (
 
 type UsersControllerUnitTestSuite struct {
 	suite.Suite
-	mockContext        *gin.Context
-	userServiceMock    mocks.IUserService
-	jwtAuthorizerMock  mocks.IJwtAuthorizer
-	mockResponseWriter *httptest.ResponseRecorder
-	controller         *UsersController
+	mockContext                  *gin.Context
+	userServiceMock              mocks.IUserService
+	tokenServiceMock             mocks.ITokenService
+	emailVerificationServiceMock mocks.IEmailVerificationService
+	loginThrottleServiceMock     mocks.ILoginThrottleService
+	twoFactorServiceMock         mocks.ITwoFactorService
+	auditLogServiceMock          mocks.IAuditLogService
+	mockResponseWriter           *httptest.ResponseRecorder
+	controller                   *UsersController
 }
 
 func TestUsersControllerUnitTestSuite(t *testing.T) {
@@ -36,9 +42,26 @@ func (suite *UsersControllerUnitTestSuite) SetupTest() {
 	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
 
 	suite.userServiceMock = mocks.IUserService{}
-	suite.jwtAuthorizerMock = mocks.IJwtAuthorizer{}
-
-	suite.controller = NewUsersController(&suite.userServiceMock, &suite.jwtAuthorizerMock)
+	suite.tokenServiceMock = mocks.ITokenService{}
+	suite.emailVerificationServiceMock = mocks.IEmailVerificationService{}
+	suite.loginThrottleServiceMock = mocks.ILoginThrottleService{}
+	suite.twoFactorServiceMock = mocks.ITwoFactorService{}
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+
+	//logins are not throttled unless a test says otherwise
+	suite.loginThrottleServiceMock.On("CheckLogin", mock.Anything, mock.Anything).Return(time.Duration(0), nil)
+	suite.loginThrottleServiceMock.On("RecordFailedLogin", mock.Anything, mock.Anything).Return(nil)
+	suite.loginThrottleServiceMock.On("RecordSuccessfulLogin", mock.Anything).Return(nil)
+	suite.twoFactorServiceMock.On("IsTwoFactorEnabled", mock.Anything).Return(false, nil)
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.controller = NewUsersController(
+		&suite.userServiceMock,
+		&suite.tokenServiceMock,
+		&suite.emailVerificationServiceMock,
+		&suite.loginThrottleServiceMock,
+		&suite.twoFactorServiceMock,
+		&suite.auditLogServiceMock)
 }
 
 // When provided an invalid payload, should return bad request
@@ -58,7 +81,7 @@ func (suite *UsersControllerUnitTestSuite) TestCreateUser_ReturnsBadRequest() {
 func (suite *UsersControllerUnitTestSuite) TestCreateUser_AttemptsToCreateAUser() {
 
 	var mockUser = models.User{
-		Email:    "some email",
+		Email:    "test@test.com",
 		Password: "some password",
 	}
 
@@ -77,7 +100,7 @@ func (suite *UsersControllerUnitTestSuite) TestCreateUser_AttemptsToCreateAUser(
 func (suite *UsersControllerUnitTestSuite) TestCreateUser_RetrurnsInternalServerError() {
 
 	test_utils.SetRequestBody(models.User{
-		Email:    "some email",
+		Email:    "test@test.com",
 		Password: "some password",
 	}, suite.mockContext)
 
@@ -93,17 +116,49 @@ func (suite *UsersControllerUnitTestSuite) TestCreateUser_RetrurnsInternalServer
 func (suite *UsersControllerUnitTestSuite) TestCreateUser_RetrurnsCreated() {
 
 	test_utils.SetRequestBody(models.User{
-		Email:    "some email",
+		Email:    "test@test.com",
 		Password: "some password",
 	}, suite.mockContext)
 
 	suite.userServiceMock.On("CreateUser", mock.Anything).Return(nil)
+	suite.emailVerificationServiceMock.On("SendVerificationEmail", mock.Anything).Return(nil)
 
 	suite.controller.CreateUser(suite.mockContext)
 
 	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
 
 	suite.Equal(http.StatusCreated, response.StatusCode)
+	suite.emailVerificationServiceMock.AssertNumberOfCalls(suite.T(), "SendVerificationEmail", 1)
+}
+
+// When the verification email cannot be sent, the user is still created
+func (suite *UsersControllerUnitTestSuite) TestCreateUserFailingToSendTheVerification_ReturnsCreated() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "test@test.com",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.userServiceMock.On("CreateUser", mock.Anything).Return(nil)
+	suite.emailVerificationServiceMock.On("SendVerificationEmail", mock.Anything).Return(errors.New("test"))
+
+	suite.controller.CreateUser(suite.mockContext)
+
+	suite.Equal(http.StatusCreated, suite.mockResponseWriter.Code)
+}
+
+// When the email is malformed, return a bad request
+func (suite *UsersControllerUnitTestSuite) TestCreateUserInvalidEmail_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.controller.CreateUser(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.userServiceMock.AssertNotCalled(suite.T(), "CreateUser", mock.Anything)
 }
 
 // When provided an invalid payload, should return bad request
@@ -171,6 +226,62 @@ func (suite *UsersControllerUnitTestSuite) TestLogin_ReturnsUnauthorized() {
 	suite.Equal(http.StatusUnauthorized, response.StatusCode)
 }
 
+func (suite *UsersControllerUnitTestSuite) TestLoginInvalidCredentials_RecordsTheFailure() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(false, nil)
+
+	suite.controller.Login(suite.mockContext)
+
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "some email", mock.Anything)
+	suite.loginThrottleServiceMock.AssertNotCalled(suite.T(), "RecordSuccessfulLogin", mock.Anything)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.LOGIN_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.FAILURE_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal("some email", entry.ActorEmail)
+	suite.Equal("192.0.2.1", entry.IpAddress)
+}
+
+// When too many attempts failed, return too many requests without checking the password
+func (suite *UsersControllerUnitTestSuite) TestLoginThrottled_ReturnsTooManyRequests() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.loginThrottleServiceMock.ExpectedCalls = nil
+	suite.loginThrottleServiceMock.On("CheckLogin", mock.Anything, mock.Anything).Return(time.Millisecond*1500, nil)
+
+	suite.controller.Login(suite.mockContext)
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+	suite.Equal("2", suite.mockResponseWriter.Header().Get("Retry-After"))
+	suite.userServiceMock.AssertNotCalled(suite.T(), "ValidateCredentials", mock.Anything)
+}
+
+// When the account is disabled, return forbidden
+func (suite *UsersControllerUnitTestSuite) TestLoginDisabledAccount_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(false, errors.New(constants.ACCOUNT_DISABLED_ERROR))
+
+	suite.controller.Login(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+}
+
 func (suite *UsersControllerUnitTestSuite) TestLogin_AttemptsToCreateAnAuthToken() {
 
 	var mockUser = models.User{
@@ -183,12 +294,12 @@ func (suite *UsersControllerUnitTestSuite) TestLogin_AttemptsToCreateAnAuthToken
 	test_utils.SetRequestBody(mockUser, suite.mockContext)
 
 	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(true, nil)
-	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything).Return("", errors.New("test"))
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.Login(suite.mockContext)
 
-	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", mockUser.Email, strconv.FormatInt(mockUser.Id, 10))
-	suite.jwtAuthorizerMock.AssertNumberOfCalls(suite.T(), "GenerateToken", 1)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "IssueTokens", mockUser, mock.Anything)
+	suite.tokenServiceMock.AssertNumberOfCalls(suite.T(), "IssueTokens", 1)
 }
 
 // When failing to generat an auth token, return internal server error
@@ -200,7 +311,7 @@ func (suite *UsersControllerUnitTestSuite) TestLogin_ReturnsInternalServerError(
 	}, suite.mockContext)
 
 	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(true, nil)
-	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything).Return("", errors.New("test"))
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
 	suite.controller.Login(suite.mockContext)
 
@@ -219,7 +330,10 @@ func (suite *UsersControllerUnitTestSuite) TestLogin_ReturnsOk() {
 	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(true, nil)
 
 	var expectedAuthToken string = "auth token"
-	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything).Return(expectedAuthToken, nil)
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{
+		AccessToken:  expectedAuthToken,
+		RefreshToken: "refresh token",
+	}, nil)
 
 	suite.controller.Login(suite.mockContext)
 
@@ -227,4 +341,408 @@ func (suite *UsersControllerUnitTestSuite) TestLogin_ReturnsOk() {
 
 	suite.Equal(http.StatusOK, response.StatusCode)
 	suite.Contains(response.Body, expectedAuthToken)
+	suite.Contains(response.Body, `"refresh_token":"refresh token"`)
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordSuccessfulLogin", "some email")
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.LOGIN_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+}
+
+// The session is started with the address and user agent of the client
+func (suite *UsersControllerUnitTestSuite) TestLogin_StartsTheSessionOfTheClient() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+	suite.mockContext.Request.Header.Set("User-Agent", strings.Repeat("a", 600))
+
+	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(true, nil)
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{AccessToken: "auth token"}, nil)
+
+	suite.controller.Login(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "IssueTokens", mock.Anything, models.SessionClient{
+		IpAddress: "192.0.2.1",
+		UserAgent: strings.Repeat("a", 512),
+	})
+}
+
+func (suite *UsersControllerUnitTestSuite) TestRefreshTokenMissingToken_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.RefreshTokenRequest{}, suite.mockContext)
+
+	suite.controller.RefreshToken(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+// When the refresh token was already used, return unauthorized
+func (suite *UsersControllerUnitTestSuite) TestRefreshTokenReused_ReturnsUnauthorized() {
+
+	test_utils.SetRequestBody(models.RefreshTokenRequest{RefreshToken: "refresh token"}, suite.mockContext)
+
+	suite.tokenServiceMock.On("RefreshTokens", mock.Anything, mock.Anything).Return(nil, errors.New(constants.REFRESH_TOKEN_REUSE_ERROR))
+
+	suite.controller.RefreshToken(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "RefreshTokens", "refresh token", mock.Anything)
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.TOKEN_REFRESH_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.FAILURE_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(constants.REFRESH_TOKEN_REUSE_ERROR, entry.Reason)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestRefreshToken_ReturnsTheNewTokens() {
+
+	test_utils.SetRequestBody(models.RefreshTokenRequest{RefreshToken: "refresh token"}, suite.mockContext)
+
+	suite.tokenServiceMock.On("RefreshTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{
+		AccessToken:  "new auth token",
+		RefreshToken: "new refresh token",
+		ExpiresIn:    300,
+	}, nil)
+
+	suite.controller.RefreshToken(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"token":"new auth token"`)
+	suite.Contains(response.Body, `"refresh_token":"new refresh token"`)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestLogout_RevokesTheTokens() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	test_utils.SetRequestBody(models.LogoutRequest{RefreshToken: "refresh token"}, suite.mockContext)
+	suite.mockContext.Set("tokenClaims", claims)
+
+	suite.tokenServiceMock.On("Logout", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.Logout(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "Logout", claims, "refresh token")
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+// The refresh token is optional, logging out without a body only revokes the access token
+func (suite *UsersControllerUnitTestSuite) TestLogoutWithoutBody_RevokesTheAccessToken() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/logout", nil)
+	suite.mockContext.Set("tokenClaims", claims)
+
+	suite.tokenServiceMock.On("Logout", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.Logout(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "Logout", claims, "")
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+// When failing to revoke the tokens, return internal server error
+func (suite *UsersControllerUnitTestSuite) TestLogoutEverywhere_ReturnsInternalServerError() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/logout/all", nil)
+	suite.mockContext.Set("tokenClaims", claims)
+
+	suite.tokenServiceMock.On("LogoutEverywhere", mock.Anything).Return(errors.New("test"))
+
+	suite.controller.LogoutEverywhere(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "LogoutEverywhere", claims)
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+// When two factor authentication is enabled, return an mfa token instead of the auth token
+func (suite *UsersControllerUnitTestSuite) TestLoginWithTwoFactor_ReturnsAnMfaToken() {
+
+	test_utils.SetRequestBody(models.User{
+		Email:    "some email",
+		Password: "some password",
+	}, suite.mockContext)
+
+	suite.userServiceMock.On("ValidateCredentials", mock.Anything).Return(true, nil)
+	suite.twoFactorServiceMock.ExpectedCalls = nil
+	suite.twoFactorServiceMock.On("IsTwoFactorEnabled", mock.Anything).Return(true, nil)
+	suite.twoFactorServiceMock.On("CreateMfaChallenge", mock.Anything).Return(&models.MfaChallengeToken{
+		Token:     "mfa token",
+		ExpiresIn: 300,
+	}, nil)
+
+	suite.controller.Login(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"mfa_token":"mfa token"`)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+	suite.loginThrottleServiceMock.AssertNotCalled(suite.T(), "RecordSuccessfulLogin", mock.Anything)
+}
+
+// When the mfa token or the code is invalid, return unauthorized
+func (suite *UsersControllerUnitTestSuite) TestCompleteMfaLoginInvalidCode_ReturnsUnauthorized() {
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "mfa token",
+		Code:     "123456",
+	}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("GetMfaChallengeUser", mock.Anything).Return(&models.User{Id: 12, Email: "some email"}, nil)
+	suite.twoFactorServiceMock.On("CompleteMfaChallenge", mock.Anything, mock.Anything).Return(nil, errors.New(constants.INVALID_TOTP_CODE_ERROR))
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "some email", mock.Anything)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.FAILURE_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(int64(12), entry.ActorId)
+	suite.Equal("some email", entry.ActorEmail)
+}
+
+// When the challenge expired or was used, the code is not checked
+func (suite *UsersControllerUnitTestSuite) TestCompleteMfaLoginInvalidToken_ReturnsUnauthorized() {
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "mfa token",
+		Code:     "123456",
+	}, suite.mockContext)
+
+	suite.twoFactorServiceMock.On("GetMfaChallengeUser", mock.Anything).Return(nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR))
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.twoFactorServiceMock.AssertNotCalled(suite.T(), "CompleteMfaChallenge", mock.Anything, mock.Anything)
+}
+
+// Wrong codes are counted against the account rather than the challenge, so logging in with the
+// password again to open a new challenge does not allow guessing more codes
+func (suite *UsersControllerUnitTestSuite) TestCompleteMfaLoginNewChallengeAfterFailures_ReturnsTooManyRequests() {
+
+	suite.twoFactorServiceMock.On("GetMfaChallengeUser", mock.Anything).Return(&models.User{Id: 12, Email: "some email"}, nil)
+	suite.twoFactorServiceMock.On("CompleteMfaChallenge", "first token", mock.Anything).Return(nil, errors.New(constants.INVALID_TOTP_CODE_ERROR))
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "first token",
+		Code:     "123456",
+	}, suite.mockContext)
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordFailedLogin", "some email", mock.Anything)
+
+	//the failures recorded so far throttle the account
+	suite.loginThrottleServiceMock.ExpectedCalls = nil
+	suite.loginThrottleServiceMock.On("CheckLogin", "some email", mock.Anything).Return(time.Second*30, nil)
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "second token",
+		Code:     "654321",
+	}, suite.mockContext)
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	suite.Equal(http.StatusTooManyRequests, suite.mockResponseWriter.Code)
+	suite.Equal("30", suite.mockResponseWriter.Header().Get("Retry-After"))
+	suite.twoFactorServiceMock.AssertNotCalled(suite.T(), "CompleteMfaChallenge", "second token", mock.Anything)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestCompleteMfaLoginMissingCode_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "mfa token",
+	}, suite.mockContext)
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestCompleteMfaLogin_ReturnsOk() {
+
+	test_utils.SetRequestBody(models.MfaLoginRequest{
+		MfaToken: "mfa token",
+		Code:     "123456",
+	}, suite.mockContext)
+
+	user := models.User{Id: 12, Email: "some email"}
+
+	suite.twoFactorServiceMock.On("GetMfaChallengeUser", mock.Anything).Return(&user, nil)
+	suite.twoFactorServiceMock.On("CompleteMfaChallenge", mock.Anything, mock.Anything).Return(&user, nil)
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{
+		AccessToken:  "auth token",
+		RefreshToken: "refresh token",
+	}, nil)
+
+	suite.controller.CompleteMfaLogin(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, "auth token")
+	suite.twoFactorServiceMock.AssertCalled(suite.T(), "CompleteMfaChallenge", "mfa token", "123456")
+	suite.tokenServiceMock.AssertCalled(suite.T(), "IssueTokens", user, mock.Anything)
+	suite.loginThrottleServiceMock.AssertCalled(suite.T(), "RecordSuccessfulLogin", "some email")
+}
+
+func (suite *UsersControllerUnitTestSuite) TestGetProfile_ReturnsTheProfile() {
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.userServiceMock.On("GetProfile", int64(12)).Return(&models.UserProfile{
+		Email:                   "test@test.com",
+		DisplayName:             "Test",
+		NotificationPreferences: models.DefaultNotificationPreferences(),
+	}, nil)
+
+	suite.controller.GetProfile(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"display_name":"Test"`)
+	suite.Contains(response.Body, `"event_updates":true`)
+}
+
+// When the avatar is not an http url, return bad request
+func (suite *UsersControllerUnitTestSuite) TestUpdateProfileInvalidAvatar_ReturnsBadRequest() {
+
+	avatarUrl := "javascript:alert(1)"
+
+	test_utils.SetRequestBody(models.UpdateProfileRequest{AvatarUrl: &avatarUrl}, suite.mockContext)
+
+	suite.controller.UpdateProfile(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.userServiceMock.AssertNotCalled(suite.T(), "UpdateProfile", mock.Anything, mock.Anything)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestUpdateProfile_ReturnsTheProfile() {
+
+	displayName := "Test"
+
+	suite.mockContext.Set("userId", int64(12))
+	test_utils.SetRequestBody(models.UpdateProfileRequest{DisplayName: &displayName}, suite.mockContext)
+
+	suite.userServiceMock.On("UpdateProfile", int64(12), mock.Anything).Return(&models.UserProfile{DisplayName: "Test"}, nil)
+
+	suite.controller.UpdateProfile(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+}
+
+// The current session gets new tokens once the password changed
+func (suite *UsersControllerUnitTestSuite) TestChangePassword_ReturnsNewTokens() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+	user := models.User{Id: 12, Email: "test@test.com"}
+
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("tokenClaims", claims)
+	test_utils.SetRequestBody(models.ChangePasswordRequest{CurrentPassword: "current", NewPassword: "new"}, suite.mockContext)
+
+	suite.userServiceMock.On("ChangePassword", int64(12), "current", "new").Return(&user, nil)
+	suite.tokenServiceMock.On("Logout", mock.Anything, mock.Anything).Return(nil)
+	suite.tokenServiceMock.On("IssueTokens", mock.Anything, mock.Anything).Return(&models.TokenPair{AccessToken: "access token", RefreshToken: "refresh token"}, nil)
+
+	suite.controller.ChangePassword(suite.mockContext)
+
+	response := test_utils.GetHttpResponse(suite.mockResponseWriter)
+
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Contains(response.Body, `"token":"access token"`)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "Logout", claims, "")
+	suite.tokenServiceMock.AssertCalled(suite.T(), "IssueTokens", user, mock.Anything)
+}
+
+// When the current password is wrong, return forbidden
+func (suite *UsersControllerUnitTestSuite) TestChangePasswordInvalidPassword_ReturnsForbidden() {
+
+	test_utils.SetRequestBody(models.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new"}, suite.mockContext)
+
+	suite.userServiceMock.On("ChangePassword", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.INVALID_PASSWORD_ERROR))
+
+	suite.controller.ChangePassword(suite.mockContext)
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "IssueTokens", mock.Anything, mock.Anything)
+}
+
+func (suite *UsersControllerUnitTestSuite) TestChangeEmail_SendsTheConfirmationLink() {
+
+	user := models.User{Id: 12, Email: "old@test.com"}
+
+	suite.mockContext.Set("userId", int64(12))
+	test_utils.SetRequestBody(models.ChangeEmailRequest{Email: "new@test.com", Password: "password"}, suite.mockContext)
+
+	suite.userServiceMock.On("RequestEmailChange", int64(12), "password", "new@test.com").Return(&user, nil)
+	suite.emailVerificationServiceMock.On("SendEmailChangeVerification", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.ChangeEmail(suite.mockContext)
+
+	suite.Equal(http.StatusAccepted, suite.mockResponseWriter.Code)
+	suite.emailVerificationServiceMock.AssertCalled(suite.T(), "SendEmailChangeVerification", user, "new@test.com")
+}
+
+// When another account uses the email, return a conflict
+func (suite *UsersControllerUnitTestSuite) TestChangeEmailInUse_ReturnsConflict() {
+
+	test_utils.SetRequestBody(models.ChangeEmailRequest{Email: "new@test.com", Password: "password"}, suite.mockContext)
+
+	suite.userServiceMock.On("RequestEmailChange", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New(constants.EMAIL_ALREADY_IN_USE_ERROR))
+
+	suite.controller.ChangeEmail(suite.mockContext)
+
+	suite.Equal(http.StatusConflict, suite.mockResponseWriter.Code)
+	suite.emailVerificationServiceMock.AssertNotCalled(suite.T(), "SendEmailChangeVerification", mock.Anything, mock.Anything)
+}
+
+// The access token used to delete the account is revoked along with it
+func (suite *UsersControllerUnitTestSuite) TestDeleteAccount_RevokesTheAccessToken() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	suite.mockContext.Set("userId", int64(12))
+	suite.mockContext.Set("tokenClaims", claims)
+	test_utils.SetRequestBody(models.DeleteAccountRequest{Password: "password"}, suite.mockContext)
+
+	suite.userServiceMock.On("DeleteAccount", int64(12), "password").Return(nil)
+	suite.tokenServiceMock.On("Logout", mock.Anything, mock.Anything).Return(nil)
+
+	suite.controller.DeleteAccount(suite.mockContext)
+
+	suite.Equal(http.StatusOK, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertCalled(suite.T(), "Logout", claims, "")
+}
+
+// Users without a password are told to set one first
+func (suite *UsersControllerUnitTestSuite) TestDeleteAccountWithoutPassword_ReturnsBadRequest() {
+
+	test_utils.SetRequestBody(models.DeleteAccountRequest{Password: "password"}, suite.mockContext)
+
+	suite.userServiceMock.On("DeleteAccount", mock.Anything, mock.Anything).Return(errors.New(constants.PASSWORD_NOT_SET_ERROR))
+
+	suite.controller.DeleteAccount(suite.mockContext)
+
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "Logout", mock.Anything, mock.Anything)
 }
@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IAdminController interface {
+	GetUsers(context *gin.Context)
+	DisableUser(context *gin.Context)
+	EnableUser(context *gin.Context)
+	UnlockUser(context *gin.Context)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IAuditLogController interface {
+	GetAuditLog(context *gin.Context)
+	ExportAuditLog(context *gin.Context)
+}
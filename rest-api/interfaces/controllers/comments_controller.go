@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type ICommentsController interface {
+	GetComments(context *gin.Context)
+	CreateComment(context *gin.Context)
+	UpdateComment(context *gin.Context)
+	DeleteComment(context *gin.Context)
+	PinComment(context *gin.Context)
+	UnpinComment(context *gin.Context)
+}
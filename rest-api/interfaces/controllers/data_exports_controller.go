@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IDataExportsController interface {
+	ExportData(context *gin.Context)
+	GetDataExport(context *gin.Context)
+	DownloadDataExport(context *gin.Context)
+}
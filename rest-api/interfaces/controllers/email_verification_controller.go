@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IEmailVerificationController interface {
+	VerifyEmail(context *gin.Context)
+	ResendVerificationEmail(context *gin.Context)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IEventChannelController interface {
+	Connect(context *gin.Context)
+	Announce(context *gin.Context)
+}
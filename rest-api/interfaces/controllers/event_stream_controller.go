@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IEventStreamController interface {
+	StreamEvents(context *gin.Context)
+	StreamEvent(context *gin.Context)
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IInvitationsController interface {
+	CreateInvitation(context *gin.Context)
+	GetInvitations(context *gin.Context)
+	RevokeInvitation(context *gin.Context)
+	AcceptInvitation(context *gin.Context)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IOidcController interface {
+	StartLogin(context *gin.Context)
+	Callback(context *gin.Context)
+}
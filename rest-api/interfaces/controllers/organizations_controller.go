@@ -0,0 +1,14 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IOrganizationsController interface {
+	CreateOrganization(context *gin.Context)
+	GetOrganizations(context *gin.Context)
+	GetMembers(context *gin.Context)
+	InviteMember(context *gin.Context)
+	AcceptInvitation(context *gin.Context)
+	UpdateMemberRole(context *gin.Context)
+	RemoveMember(context *gin.Context)
+	SwitchOrganization(context *gin.Context)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IPasswordResetController interface {
+	ForgotPassword(context *gin.Context)
+	ResetPassword(context *gin.Context)
+}
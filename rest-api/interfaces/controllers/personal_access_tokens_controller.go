@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IPersonalAccessTokensController interface {
+	GetPersonalAccessTokens(context *gin.Context)
+	CreatePersonalAccessToken(context *gin.Context)
+	RevokePersonalAccessToken(context *gin.Context)
+}
@@ -5,4 +5,5 @@ import "github.com/gin-gonic/gin"
 type IRegistrationsController interface {
 	RegisterForEvent(context *gin.Context)
 	CancelEventRegistration(context *gin.Context)
+	GetScheduleConflicts(context *gin.Context)
 }
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type ISessionsController interface {
+	GetSessions(context *gin.Context)
+	RevokeSession(context *gin.Context)
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type ITwoFactorController interface {
+	StartEnrollment(context *gin.Context)
+	ConfirmEnrollment(context *gin.Context)
+	DisableTwoFactor(context *gin.Context)
+}
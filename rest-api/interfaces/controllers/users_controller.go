@@ -5,4 +5,13 @@ import "github.com/gin-gonic/gin"
 type IUsersController interface {
 	CreateUser(context *gin.Context)
 	Login(context *gin.Context)
+	CompleteMfaLogin(context *gin.Context)
+	RefreshToken(context *gin.Context)
+	Logout(context *gin.Context)
+	LogoutEverywhere(context *gin.Context)
+	GetProfile(context *gin.Context)
+	UpdateProfile(context *gin.Context)
+	ChangePassword(context *gin.Context)
+	ChangeEmail(context *gin.Context)
+	DeleteAccount(context *gin.Context)
 }
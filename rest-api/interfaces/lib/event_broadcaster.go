@@ -0,0 +1,9 @@
+package interfaces
+
+import "example.com/models"
+
+type IEventBroadcaster interface {
+	Publish(notification models.EventNotification)
+	Subscribe(eventId int64, lastNotificationId uint64) *models.EventSubscription
+	Unsubscribe(subscription *models.EventSubscription)
+}
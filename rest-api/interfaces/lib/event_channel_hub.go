@@ -0,0 +1,10 @@
+package interfaces
+
+import "example.com/models"
+
+type IEventChannelHub interface {
+	Join(eventId, userId int64, organizer bool) *models.EventChannelClient
+	Leave(client *models.EventChannelClient)
+	Broadcast(eventId int64, message models.EventChannelMessage)
+	ConnectedCount(eventId int64) int64
+}
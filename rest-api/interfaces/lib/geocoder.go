@@ -0,0 +1,5 @@
+package interfaces
+
+type IGeocoder interface {
+	Geocode(location string) (latitude float64, longitude float64, found bool)
+}
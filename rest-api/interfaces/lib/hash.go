@@ -3,4 +3,6 @@ package interfaces
 type IHasher interface {
 	HashPassword(password string) (string, error)
 	ValidatePasswordHash(password, hashedPassword string) bool
+	NeedsRehash(hashedPassword string) bool
+	DummyPasswordHash() string
 }
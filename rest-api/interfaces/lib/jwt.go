@@ -1,6 +1,9 @@
 package interfaces
 
+import "example.com/models"
+
 type IJwtAuthorizer interface {
-	GenerateToken(email, userId string) (string, error)
-	ValidateToken(token string) (int64, error)
+	GenerateToken(user models.User, sessionId string, membership models.OrganizationMembership) (string, error)
+	ValidateToken(token string) (*models.AccessTokenClaims, error)
+	JsonWebKeySet() models.JsonWebKeySet
 }
@@ -0,0 +1,7 @@
+package interfaces
+
+import "example.com/models"
+
+type IMailSender interface {
+	Send(message models.MailMessage) error
+}
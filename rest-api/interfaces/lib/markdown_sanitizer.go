@@ -0,0 +1,5 @@
+package interfaces
+
+type IMarkdownSanitizer interface {
+	Sanitize(markdown string) string
+}
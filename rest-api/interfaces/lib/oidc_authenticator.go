@@ -0,0 +1,8 @@
+package interfaces
+
+import "example.com/models"
+
+type IOidcAuthenticator interface {
+	AuthorizationUrl(request models.OidcAuthorizationRequest) (string, error)
+	Authenticate(request models.OidcAuthorizationRequest, code string) (*models.OidcIdentity, error)
+}
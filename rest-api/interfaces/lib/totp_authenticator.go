@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type ITotpAuthenticator interface {
+	GenerateKey(accountName string) (*models.TotpKey, error)
+	ValidateCode(secret, code string, now time.Time) (int64, bool)
+}
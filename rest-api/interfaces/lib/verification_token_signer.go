@@ -0,0 +1,8 @@
+package interfaces
+
+import "example.com/models"
+
+type IVerificationTokenSigner interface {
+	Sign(claims models.EmailVerificationClaims) (string, error)
+	Verify(token string) (*models.EmailVerificationClaims, error)
+}
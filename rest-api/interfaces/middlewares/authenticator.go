@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IAuthenticator interface {
+	Authenticate(context *gin.Context)
+	OptionalAuthenticate(context *gin.Context)
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+import "github.com/gin-gonic/gin"
+
+type IVerifiedEmailGuard interface {
+	RequireVerifiedEmail(context *gin.Context)
+}
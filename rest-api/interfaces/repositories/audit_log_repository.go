@@ -0,0 +1,8 @@
+package interfaces
+
+import "example.com/models"
+
+type IAuditLogRepository interface {
+	CreateEntry(entry models.AuditLogEntry) error
+	GetEntries(filter models.AuditLogFilter) ([]models.AuditLogEntry, error)
+}
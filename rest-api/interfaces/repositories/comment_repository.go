@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type ICommentRepository interface {
+	CreateComment(comment *models.Comment) error
+	GetCommentById(id int64) (*models.Comment, error)
+	GetRootComments(eventId int64, limit, offset int) ([]models.Comment, error)
+	CountRootComments(eventId int64) (int64, error)
+	GetReplies(rootIds []int64) ([]models.Comment, error)
+	GetCommentsByUserId(userId int64) ([]models.Comment, error)
+	UpdateCommentBody(id int64, body string, updatedAt time.Time) error
+	SoftDeleteComment(id int64, deletedAt time.Time) error
+	SetCommentPinned(id int64, pinned bool) error
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IDataExportRepository interface {
+	CreateDataExport(export *models.DataExport) error
+	GetDataExport(id, userId int64) (*models.DataExport, error)
+	GetPendingDataExport(userId int64, createdAfter time.Time) (*models.DataExport, error)
+	GetDataExportsByUserId(userId int64) ([]models.DataExport, error)
+	GetDataExportArchive(id int64) ([]byte, error)
+	CompleteDataExport(id int64, archive []byte, completedAt time.Time) error
+	FailDataExport(id int64, failedAt time.Time) error
+	DeleteExpiredDataExports(now time.Time) error
+	CountUserRecords(userId int64) (int64, error)
+}
@@ -6,8 +6,12 @@ import (
 
 type IEventRepository interface {
 	AddEvent(event *models.Event) error
-	GetEvents() ([]models.Event, error)
-	GetEventById(id int64) (*models.Event, error)
-	UpdateEvent(id int64, event models.Event) error
-	DeleteEvent(id int64) error
+	GetEvents(viewerId, organizationId int64) ([]models.Event, error)
+	GetEventById(id, organizationId int64) (*models.Event, error)
+	UpdateEvent(id, organizationId int64, event models.Event) error
+	DeleteEvent(id, organizationId int64) error
+	GetEventsWithinBounds(bounds models.BoundingBox, viewerId, organizationId int64) ([]models.Event, error)
+	GetEventsByRegistrant(userId, organizationId int64) ([]models.Event, error)
+	GetEventsByRegistrantInAllOrganizations(userId int64) ([]models.Event, error)
+	GetEventsByOrganizer(userId, organizationId int64) ([]models.Event, error)
 }
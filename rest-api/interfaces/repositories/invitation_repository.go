@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IInvitationRepository interface {
+	CreateInvitation(invitation *models.Invitation) error
+	GetInvitationsByEventId(eventId int64) ([]models.Invitation, error)
+	GetInvitationByTokenHash(eventId int64, tokenHash string) (*models.Invitation, error)
+	AcceptInvitation(id, userId int64, acceptedAt time.Time) error
+	RevokeInvitation(eventId, id int64, revokedAt time.Time) error
+	IsInvited(eventId, userId int64) (bool, error)
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type ILoginThrottleRepository interface {
+	GetLoginThrottle(key string) (*models.LoginThrottle, error)
+	RecordLoginFailure(key string, failedAt, windowStart time.Time) (*models.LoginThrottle, error)
+	LockLogin(key string, lockedUntil time.Time) error
+	DeleteLoginThrottle(key string) error
+}
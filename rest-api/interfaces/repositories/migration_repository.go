@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IMigrationRepository interface {
+	CreateMigrationTables() error
+	AcquireMigrationLock(lockedAt time.Time, staleBefore time.Time) error
+	ReleaseMigrationLock(lockedAt time.Time) error
+	GetAppliedMigrations() ([]models.AppliedMigration, error)
+	ApplyMigration(migration models.Migration, appliedAt time.Time) error
+	RevertMigration(migration models.Migration) error
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IOidcRepository interface {
+	CreateOidcLoginState(state *models.OidcLoginState) error
+	ConsumeOidcLoginState(stateHash string) (*models.OidcLoginState, error)
+	DeleteExpiredOidcLoginStates(now time.Time) error
+	GetUserIdentity(provider, subject string) (*models.UserIdentity, error)
+	GetUserIdentitiesByUserId(userId int64) ([]models.UserIdentity, error)
+	CreateUserIdentity(identity *models.UserIdentity) error
+}
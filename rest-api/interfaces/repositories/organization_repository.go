@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IOrganizationRepository interface {
+	CreateOrganization(organization *models.Organization, creatorId int64) error
+	GetMembership(organizationId, userId int64) (*models.OrganizationMembership, error)
+	GetMembershipsByUserId(userId int64) ([]models.OrganizationMembership, error)
+	GetMembers(organizationId int64) ([]models.OrganizationMember, error)
+	CountAdmins(organizationId int64) (int64, error)
+	UpdateMemberRole(organizationId, userId int64, role string) error
+	RemoveMember(organizationId, userId int64) error
+	CreateInvitation(invitation *models.OrganizationInvitation) error
+	GetInvitationByTokenHash(organizationId int64, tokenHash string) (*models.OrganizationInvitation, error)
+	AcceptInvitation(invitation models.OrganizationInvitation, userId int64, acceptedAt time.Time) (bool, error)
+}
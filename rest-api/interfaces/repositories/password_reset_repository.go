@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IPasswordResetRepository interface {
+	CreatePasswordResetToken(token *models.PasswordResetToken) error
+	GetPasswordResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error)
+	ResetPassword(tokenId, userId int64, password string, usedAt time.Time) (bool, error)
+}
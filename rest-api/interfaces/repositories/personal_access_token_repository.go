@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IPersonalAccessTokenRepository interface {
+	CreatePersonalAccessToken(token *models.PersonalAccessToken) error
+	GetPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	GetPersonalAccessTokensByUserId(userId int64) ([]models.PersonalAccessToken, error)
+	RevokePersonalAccessToken(id, userId int64, revokedAt time.Time) (bool, error)
+	MarkPersonalAccessTokenUsed(id int64, usedAt, throttledBefore time.Time) error
+}
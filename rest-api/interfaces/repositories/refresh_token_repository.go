@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type IRefreshTokenRepository interface {
+	CreateRefreshToken(refreshToken *models.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error)
+	GetRefreshTokensByUserId(userId int64) ([]models.RefreshToken, error)
+	MarkRefreshTokenUsed(id int64, usedAt time.Time) (bool, error)
+	RevokeRefreshTokenFamily(familyId string, revokedAt time.Time) error
+	RevokeRefreshTokensByUserId(userId int64, revokedAt time.Time) error
+}
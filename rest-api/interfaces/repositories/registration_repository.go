@@ -1,6 +1,8 @@
 package interfaces
 
 type IRegistrationRepository interface {
-	CreateRegistration(eventId, userId int64) error
-	DeleteRegistration(eventId, userId int64) error
+	CreateRegistration(eventId, userId, organizationId int64) error
+	DeleteRegistration(eventId, userId, organizationId int64) error
+	CountRegistrations(eventId, organizationId int64) (int64, error)
+	IsRegistered(eventId, userId, organizationId int64) (bool, error)
 }
@@ -0,0 +1,9 @@
+package interfaces
+
+import "time"
+
+type IRevokedTokenRepository interface {
+	RevokeAccessToken(tokenId string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenId string) (bool, error)
+	DeleteExpiredAccessTokens(now time.Time) error
+}
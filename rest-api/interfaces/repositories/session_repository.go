@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type ISessionRepository interface {
+	SaveSession(session models.Session) error
+	UpdateSessionOrganization(id string, organizationId int64) error
+	GetSession(id string) (*models.Session, error)
+	GetActiveSessionsByUserId(userId int64, now time.Time) ([]models.Session, error)
+	MarkSessionSeen(id string, seenAt, throttledBefore time.Time) error
+	RevokeSession(id string, userId int64, revokedAt time.Time) (bool, error)
+}
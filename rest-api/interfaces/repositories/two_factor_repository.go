@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"time"
+
+	"example.com/models"
+)
+
+type ITwoFactorRepository interface {
+	GetTotpCredential(userId int64) (*models.TotpCredential, error)
+	SaveTotpCredential(credential *models.TotpCredential) error
+	ConfirmTotpCredential(userId int64, confirmedAt time.Time) error
+	UseTotpStep(userId int64, step int64) (bool, error)
+	DeleteTotpCredential(userId int64) error
+	ReplaceRecoveryCodes(userId int64, codeHashes []string) error
+	UseRecoveryCode(userId int64, codeHash string, usedAt time.Time) (bool, error)
+	DeleteRecoveryCodes(userId int64) error
+	CreateMfaChallenge(challenge *models.MfaChallenge) error
+	GetMfaChallengeByHash(tokenHash string) (*models.MfaChallenge, error)
+	MarkMfaChallengeUsed(id int64, usedAt time.Time) (bool, error)
+	RecordMfaChallengeFailure(id int64) error
+}
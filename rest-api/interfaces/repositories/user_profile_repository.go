@@ -0,0 +1,8 @@
+package interfaces
+
+import "example.com/models"
+
+type IUserProfileRepository interface {
+	GetUserProfile(userId int64) (*models.UserProfile, error)
+	SaveUserProfile(profile models.UserProfile) error
+}
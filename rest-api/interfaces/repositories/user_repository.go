@@ -1,8 +1,23 @@
 package interfaces
 
-import "example.com/models"
+import (
+	"time"
+
+	"example.com/models"
+)
 
 type IUserRepository interface {
 	CreateUser(user *models.User) error
 	GetUserByEmail(email string) (*models.User, error)
+	GetUserById(id int64) (*models.User, error)
+	GetUsers() ([]models.User, error)
+	UpdateUserRole(id int64, role string) error
+	UpdateUserPassword(id int64, password string) error
+	ReplaceUserPasswordHash(id int64, currentHash, newHash string) error
+	SetUserDisabledAt(id int64, disabledAt *time.Time) error
+	SetUserVerifiedAt(id int64, verifiedAt time.Time) error
+	MarkVerificationEmailSent(id int64, sentAt, throttledBefore time.Time) (bool, error)
+	UpdateUserEmail(id int64, email string, verifiedAt time.Time) error
+	AnonymizeUser(id int64, anonymizedEmail string, deletedAt time.Time) error
+	DeleteUser(id int64, deletedAt time.Time) error
 }
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"io"
+
+	"example.com/models"
+)
+
+type IAuditLogService interface {
+	Record(entry models.AuditLogEntry) error
+	GetEntries(filter models.AuditLogFilter) ([]models.AuditLogEntry, error)
+	ExportEntries(filter models.AuditLogFilter, writer io.Writer) error
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+import "example.com/models"
+
+type ICommentService interface {
+	GetComments(eventId int64, page, pageSize int) (*models.CommentPage, error)
+	CreateComment(eventId, userId int64, request models.CommentRequest) (*models.Comment, error)
+	UpdateComment(eventId, commentId, userId int64, request models.UpdateCommentRequest) (*models.Comment, error)
+	DeleteComment(eventId, commentId, userId, organizationId int64) error
+	PinComment(eventId, commentId, userId, organizationId int64, pinned bool) error
+}
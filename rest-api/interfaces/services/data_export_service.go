@@ -0,0 +1,9 @@
+package interfaces
+
+import "example.com/models"
+
+type IDataExportService interface {
+	RequestDataExport(userId int64) (*models.DataExportResult, error)
+	GetDataExport(id, userId int64) (*models.DataExport, error)
+	GetDataExportArchive(id, userId int64) ([]byte, error)
+}
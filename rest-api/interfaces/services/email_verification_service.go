@@ -0,0 +1,11 @@
+package interfaces
+
+import "example.com/models"
+
+type IEmailVerificationService interface {
+	SendVerificationEmail(user models.User) error
+	SendEmailChangeVerification(user models.User, newEmail string) error
+	ResendVerificationEmail(userId int64) (bool, error)
+	VerifyEmail(token string) error
+	IsEmailVerified(userId int64) (bool, error)
+}
@@ -4,8 +4,10 @@ import "example.com/models"
 
 type IEventService interface {
 	SaveEvent(event *models.Event) error
-	GetEvents() ([]models.Event, error)
-	GetEventById(id int64) (*models.Event, error)
-	UpdateEvent(id int64, event models.Event) error
-	DeleteEvent(id int64) error
+	GetEvents(viewerId, organizationId int64) ([]models.Event, error)
+	GetEventById(id, organizationId int64) (*models.Event, error)
+	GetVisibleEventById(id, viewerId, organizationId int64, invitationToken string) (*models.Event, error)
+	UpdateEvent(id, organizationId int64, event models.Event) error
+	DeleteEvent(id, organizationId int64) error
+	GetEventsNear(latitude, longitude, radiusKm float64, viewerId, organizationId int64) ([]models.EventWithDistance, error)
 }
@@ -0,0 +1,10 @@
+package interfaces
+
+import "example.com/models"
+
+type IInvitationService interface {
+	CreateInvitation(eventId, organizerId, organizationId int64, request models.InvitationRequest) (*models.Invitation, string, error)
+	GetInvitations(eventId, organizerId, organizationId int64) ([]models.Invitation, error)
+	RevokeInvitation(eventId, invitationId, organizerId, organizationId int64) error
+	AcceptInvitation(eventId, userId, organizationId int64, token string) error
+}
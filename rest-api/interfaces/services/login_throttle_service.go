@@ -0,0 +1,10 @@
+package interfaces
+
+import "time"
+
+type ILoginThrottleService interface {
+	CheckLogin(email, ip string) (time.Duration, error)
+	RecordFailedLogin(email, ip string) error
+	RecordSuccessfulLogin(email string) error
+	UnlockAccount(userId int64) error
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "example.com/models"
+
+type IMigrationService interface {
+	MigrateUp() ([]models.Migration, error)
+	MigrateDown(steps int) ([]models.Migration, error)
+	GetStatus() ([]models.MigrationStatus, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "example.com/models"
+
+type IOidcService interface {
+	StartLogin(provider string) (*models.OidcLoginStart, error)
+	CompleteLogin(provider, state, code string) (*models.User, error)
+}
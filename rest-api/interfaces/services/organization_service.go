@@ -0,0 +1,13 @@
+package interfaces
+
+import "example.com/models"
+
+type IOrganizationService interface {
+	CreateOrganization(name string, creatorId int64) (*models.Organization, error)
+	GetOrganizations(userId int64) ([]models.OrganizationMembership, error)
+	GetMembers(organizationId, userId int64) ([]models.OrganizationMember, error)
+	InviteMember(organizationId, adminId int64, request models.OrganizationInvitationRequest) (*models.OrganizationInvitation, string, error)
+	AcceptInvitation(organizationId, userId int64, token string) error
+	UpdateMemberRole(organizationId, adminId, memberId int64, role string) error
+	RemoveMember(organizationId, userId, memberId int64) error
+}
@@ -0,0 +1,6 @@
+package interfaces
+
+type IPasswordResetService interface {
+	RequestPasswordReset(email string) error
+	ResetPassword(token, password string) (int64, error)
+}
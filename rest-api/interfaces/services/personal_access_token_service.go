@@ -0,0 +1,10 @@
+package interfaces
+
+import "example.com/models"
+
+type IPersonalAccessTokenService interface {
+	CreatePersonalAccessToken(userId int64, request models.CreatePersonalAccessTokenRequest) (*models.CreatedPersonalAccessToken, error)
+	GetPersonalAccessTokens(userId int64) ([]models.PersonalAccessToken, error)
+	RevokePersonalAccessToken(id, userId int64) error
+	ValidatePersonalAccessToken(token string) (*models.AccessTokenClaims, error)
+}
@@ -1,6 +1,10 @@
 package interfaces
 
+import "example.com/models"
+
 type IRegistrationService interface {
-	CreateRegistration(eventId, userId int64) error
-	DeleteRegistration(eventId, userId int64) error
+	CreateRegistration(eventId, userId, organizationId int64, allowConflict bool) ([]models.Event, error)
+	DeleteRegistration(eventId, userId, organizationId int64) error
+	IsRegistered(eventId, userId, organizationId int64) (bool, error)
+	GetScheduleConflicts(userId, organizationId int64) ([]models.ScheduleConflict, error)
 }
@@ -0,0 +1,15 @@
+package interfaces
+
+import "example.com/models"
+
+type ITokenService interface {
+	IssueTokens(user models.User, client models.SessionClient) (*models.TokenPair, error)
+	RefreshTokens(refreshToken string, client models.SessionClient) (*models.TokenPair, error)
+	ValidateAccessToken(token string) (*models.AccessTokenClaims, error)
+	Logout(claims models.AccessTokenClaims, refreshToken string) error
+	LogoutEverywhere(claims models.AccessTokenClaims) error
+	SwitchOrganization(claims models.AccessTokenClaims, organizationId int64) (*models.TokenPair, error)
+	GetSessions(userId int64, currentSessionId string) ([]models.Session, error)
+	RevokeSession(id string, userId int64) error
+	GetJsonWebKeySet() models.JsonWebKeySet
+}
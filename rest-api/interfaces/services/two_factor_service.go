@@ -0,0 +1,13 @@
+package interfaces
+
+import "example.com/models"
+
+type ITwoFactorService interface {
+	IsTwoFactorEnabled(userId int64) (bool, error)
+	StartEnrollment(userId int64) (*models.TotpEnrollment, error)
+	ConfirmEnrollment(userId int64, code string) ([]string, error)
+	DisableTwoFactor(userId int64, code string) error
+	CreateMfaChallenge(userId int64) (*models.MfaChallengeToken, error)
+	GetMfaChallengeUser(token string) (*models.User, error)
+	CompleteMfaChallenge(token, code string) (*models.User, error)
+}
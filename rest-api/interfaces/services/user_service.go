@@ -5,4 +5,12 @@ import "example.com/models"
 type IUserService interface {
 	CreateUser(user *models.User) error
 	ValidateCredentials(user *models.User) (bool, error)
+	GetUsers() ([]models.User, error)
+	SetUserDisabled(id int64, disabled bool) error
+	AssignRole(email, role string) error
+	GetProfile(userId int64) (*models.UserProfile, error)
+	UpdateProfile(userId int64, request models.UpdateProfileRequest) (*models.UserProfile, error)
+	ChangePassword(userId int64, currentPassword, newPassword string) (*models.User, error)
+	RequestEmailChange(userId int64, password, newEmail string) (*models.User, error)
+	DeleteAccount(userId int64, password string) error
 }
@@ -0,0 +1,26 @@
+package lib
+
+import "strings"
+
+const bearerScheme = "Bearer"
+
+// Extracts the token from an authorization header using the Bearer scheme. Headers holding
+// only the token, as sent by clients predating the Bearer scheme, are still accepted.
+// Returns false when the header uses another scheme or holds no token
+func ExtractBearerToken(authorization string) (string, bool) {
+	authorization = strings.TrimSpace(authorization)
+
+	scheme, token, hasScheme := strings.Cut(authorization, " ")
+
+	if !hasScheme {
+		return authorization, authorization != ""
+	}
+
+	if !strings.EqualFold(scheme, bearerScheme) {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != "" && !strings.Contains(token, " ")
+}
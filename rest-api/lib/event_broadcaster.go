@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"sync"
+	"time"
+
+	"example.com/models"
+)
+
+const (
+	notificationReplayBufferSize = 256
+	subscriberBufferSize         = 32
+)
+
+type EventBroadcaster struct {
+	mutex              sync.Mutex
+	lastNotificationId uint64
+	//ring buffer of the most recent notifications, used to resume streams via Last-Event-ID
+	replayBuffer []models.EventNotification
+	subscribers  map[*models.EventSubscription]chan models.EventNotification
+}
+
+func (b *EventBroadcaster) Publish(notification models.EventNotification) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastNotificationId++
+	notification.Id = b.lastNotificationId
+
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now().UTC()
+	}
+
+	if len(b.replayBuffer) == notificationReplayBufferSize {
+		b.replayBuffer = b.replayBuffer[1:]
+	}
+	b.replayBuffer = append(b.replayBuffer, notification)
+
+	for subscription, channel := range b.subscribers {
+		if subscription.EventId != 0 && subscription.EventId != notification.EventId {
+			continue
+		}
+
+		select {
+		case channel <- notification:
+		default:
+			//slow consumers get disconnected rather than blocking every other stream,
+			//they can resume from the replay buffer with their last received id
+			delete(b.subscribers, subscription)
+			close(channel)
+		}
+	}
+}
+
+// Subscribes to notifications for a single event, or every event when eventId is 0.
+// Notifications published after lastNotificationId still in the replay buffer are
+// returned as the subscription backlog. An id the broadcaster never published was issued
+// before a restart, the notifications missed since cannot be told apart and the
+// subscription is incomplete
+func (b *EventBroadcaster) Subscribe(eventId int64, lastNotificationId uint64) *models.EventSubscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	channel := make(chan models.EventNotification, subscriberBufferSize)
+
+	subscription := &models.EventSubscription{
+		EventId:       eventId,
+		Notifications: channel,
+		Backlog:       make([]models.EventNotification, 0),
+	}
+
+	if lastNotificationId > b.lastNotificationId {
+		subscription.Incomplete = true
+	}
+
+	if lastNotificationId > 0 && lastNotificationId < b.lastNotificationId {
+		oldestBufferedId := b.lastNotificationId - uint64(len(b.replayBuffer)) + 1
+
+		subscription.Incomplete = lastNotificationId+1 < oldestBufferedId
+
+		for _, notification := range b.replayBuffer {
+			if notification.Id <= lastNotificationId {
+				continue
+			}
+
+			if eventId != 0 && notification.EventId != eventId {
+				continue
+			}
+
+			subscription.Backlog = append(subscription.Backlog, notification)
+		}
+	}
+
+	b.subscribers[subscription] = channel
+
+	return subscription
+}
+
+func (b *EventBroadcaster) Unsubscribe(subscription *models.EventSubscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	channel, exists := b.subscribers[subscription]
+
+	if !exists {
+		return
+	}
+
+	delete(b.subscribers, subscription)
+	close(channel)
+}
+
+func NewEventBroadcaster() *EventBroadcaster {
+	return &EventBroadcaster{
+		replayBuffer: make([]models.EventNotification, 0, notificationReplayBufferSize),
+		subscribers:  make(map[*models.EventSubscription]chan models.EventNotification),
+	}
+}
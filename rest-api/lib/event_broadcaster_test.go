@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"testing"
+
+	"example.com/models"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventBroadcasterUnitTestSuite struct {
+	suite.Suite
+	broadcaster *EventBroadcaster
+}
+
+func TestEventBroadcasterUnitTestSuite(t *testing.T) {
+	suite.Run(t, &EventBroadcasterUnitTestSuite{})
+}
+
+func (suite *EventBroadcasterUnitTestSuite) SetupTest() {
+	suite.broadcaster = NewEventBroadcaster()
+
+	suite.broadcaster.Publish(models.EventNotification{Type: models.EVENT_CREATED_NOTIFICATION, EventId: 3})
+	suite.broadcaster.Publish(models.EventNotification{Type: models.EVENT_UPDATED_NOTIFICATION, EventId: 3})
+}
+
+// Notifications published after the last received one are replayed
+func (suite *EventBroadcasterUnitTestSuite) TestSubscribe_ReplaysTheMissedNotifications() {
+
+	subscription := suite.broadcaster.Subscribe(0, 1)
+
+	suite.False(subscription.Incomplete)
+	suite.Len(subscription.Backlog, 1)
+	suite.Equal(uint64(2), subscription.Backlog[0].Id)
+}
+
+// An id from before a restart cannot be resumed, the client has to reload
+func (suite *EventBroadcasterUnitTestSuite) TestSubscribeWithUnknownLastId_IsIncomplete() {
+
+	subscription := suite.broadcaster.Subscribe(0, 40)
+
+	suite.True(subscription.Incomplete)
+	suite.Empty(subscription.Backlog)
+}
+
+func (suite *EventBroadcasterUnitTestSuite) TestSubscribeUpToDate_IsComplete() {
+
+	subscription := suite.broadcaster.Subscribe(0, 2)
+
+	suite.False(subscription.Incomplete)
+	suite.Empty(subscription.Backlog)
+}
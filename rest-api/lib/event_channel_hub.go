@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"sync"
+	"time"
+
+	"example.com/models"
+)
+
+const channelClientBufferSize = 16
+
+type EventChannelHub struct {
+	mutex sync.Mutex
+	rooms map[int64]map[*models.EventChannelClient]chan models.EventChannelMessage
+}
+
+func (h *EventChannelHub) Join(eventId, userId int64, organizer bool) *models.EventChannelClient {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	channel := make(chan models.EventChannelMessage, channelClientBufferSize)
+
+	client := &models.EventChannelClient{
+		EventId:   eventId,
+		UserId:    userId,
+		Organizer: organizer,
+		Messages:  channel,
+	}
+
+	room, exists := h.rooms[eventId]
+
+	if !exists {
+		room = make(map[*models.EventChannelClient]chan models.EventChannelMessage)
+		h.rooms[eventId] = room
+	}
+
+	room[client] = channel
+
+	h.broadcastAttendance(eventId)
+
+	return client
+}
+
+func (h *EventChannelHub) Leave(client *models.EventChannelClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.remove(client)
+}
+
+func (h *EventChannelHub) Broadcast(eventId int64, message models.EventChannelMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.broadcast(eventId, message)
+}
+
+func (h *EventChannelHub) ConnectedCount(eventId int64) int64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return int64(len(h.rooms[eventId]))
+}
+
+// Must be called while holding the mutex
+func (h *EventChannelHub) broadcast(eventId int64, message models.EventChannelMessage) {
+	if message.Timestamp.IsZero() {
+		message.Timestamp = time.Now().UTC()
+	}
+
+	for client, channel := range h.rooms[eventId] {
+		select {
+		case channel <- message:
+		default:
+			//attendance counts are superseded by the next update, so they can be skipped
+			//for a slow client, anything else means the client would miss an announcement
+			if message.Type == models.CHANNEL_ATTENDANCE_MESSAGE {
+				continue
+			}
+
+			h.remove(client)
+		}
+	}
+}
+
+// Must be called while holding the mutex
+func (h *EventChannelHub) remove(client *models.EventChannelClient) {
+	room := h.rooms[client.EventId]
+
+	channel, exists := room[client]
+
+	if !exists {
+		return
+	}
+
+	delete(room, client)
+	close(channel)
+
+	if len(room) == 0 {
+		delete(h.rooms, client.EventId)
+		return
+	}
+
+	h.broadcastAttendance(client.EventId)
+}
+
+// Must be called while holding the mutex
+func (h *EventChannelHub) broadcastAttendance(eventId int64) {
+	connectedCount := int64(len(h.rooms[eventId]))
+
+	h.broadcast(eventId, models.EventChannelMessage{
+		Type:           models.CHANNEL_ATTENDANCE_MESSAGE,
+		ConnectedCount: &connectedCount,
+	})
+}
+
+func NewEventChannelHub() *EventChannelHub {
+	return &EventChannelHub{
+		rooms: make(map[int64]map[*models.EventChannelClient]chan models.EventChannelMessage),
+	}
+}
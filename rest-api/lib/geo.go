@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"math"
+
+	"example.com/models"
+)
+
+const earthRadiusKm = 6371.0088
+
+// Great-circle distance between two coordinates, in kilometers
+func HaversineDistance(fromLatitude, fromLongitude, toLatitude, toLongitude float64) float64 {
+	latitudeDelta := toRadians(toLatitude - fromLatitude)
+	longitudeDelta := toRadians(toLongitude - fromLongitude)
+
+	a := math.Pow(math.Sin(latitudeDelta/2), 2) +
+		math.Cos(toRadians(fromLatitude))*math.Cos(toRadians(toLatitude))*math.Pow(math.Sin(longitudeDelta/2), 2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Smallest latitude / longitude rectangle containing every point within radiusKm of the
+// center, used to narrow down candidates before computing exact distances
+func BoundingBoxAround(latitude, longitude, radiusKm float64) models.BoundingBox {
+	angularRadius := radiusKm / earthRadiusKm
+
+	minLatitude := toDegrees(toRadians(latitude) - angularRadius)
+	maxLatitude := toDegrees(toRadians(latitude) + angularRadius)
+
+	//close to the poles every longitude is within the radius
+	if minLatitude <= -90 || maxLatitude >= 90 {
+		return models.BoundingBox{
+			MinLatitude:  math.Max(minLatitude, -90),
+			MaxLatitude:  math.Min(maxLatitude, 90),
+			MinLongitude: -180,
+			MaxLongitude: 180,
+		}
+	}
+
+	longitudeRatio := math.Sin(angularRadius) / math.Cos(toRadians(latitude))
+
+	if longitudeRatio >= 1 {
+		return models.BoundingBox{
+			MinLatitude:  minLatitude,
+			MaxLatitude:  maxLatitude,
+			MinLongitude: -180,
+			MaxLongitude: 180,
+		}
+	}
+
+	longitudeDelta := toDegrees(math.Asin(longitudeRatio))
+
+	return models.BoundingBox{
+		MinLatitude:  minLatitude,
+		MaxLatitude:  maxLatitude,
+		MinLongitude: normalizeLongitude(longitude - longitudeDelta),
+		MaxLongitude: normalizeLongitude(longitude + longitudeDelta),
+	}
+}
+
+func normalizeLongitude(longitude float64) float64 {
+	if longitude < -180 {
+		return longitude + 360
+	}
+
+	if longitude > 180 {
+		return longitude - 360
+	}
+
+	return longitude
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
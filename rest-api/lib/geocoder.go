@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/gazetteer.csv
+var gazetteerFile []byte
+
+type gazetteerEntry struct {
+	name      string
+	latitude  float64
+	longitude float64
+}
+
+// Resolves free text locations to coordinates using the bundled gazetteer, without
+// relying on any external geocoding service
+type Geocoder struct {
+	entries []gazetteerEntry
+}
+
+// Returns the coordinates of the longest gazetteer place name contained in the location,
+// so "Conference room 2, Berlin" resolves to Berlin
+func (g *Geocoder) Geocode(location string) (float64, float64, bool) {
+	normalizedLocation := " " + normalizePlaceName(location) + " "
+
+	var match *gazetteerEntry
+
+	for index, entry := range g.entries {
+		if !strings.Contains(normalizedLocation, " "+entry.name+" ") {
+			continue
+		}
+
+		if match == nil || len(entry.name) > len(match.name) {
+			match = &g.entries[index]
+		}
+	}
+
+	if match == nil {
+		return 0, 0, false
+	}
+
+	return match.latitude, match.longitude, true
+}
+
+func normalizePlaceName(name string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return ' '
+	}, name)
+
+	return strings.Join(strings.Fields(normalized), " ")
+}
+
+func parseGazetteer(file []byte) ([]gazetteerEntry, error) {
+	records, err := csv.NewReader(bytes.NewReader(file)).ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]gazetteerEntry, 0, len(records))
+
+	//skipping the header row
+	for _, record := range records[1:] {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("malformed gazetteer entry: %v", record)
+		}
+
+		latitude, err := strconv.ParseFloat(record[1], 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		longitude, err := strconv.ParseFloat(record[2], 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, gazetteerEntry{
+			name:      normalizePlaceName(record[0]),
+			latitude:  latitude,
+			longitude: longitude,
+		})
+	}
+
+	return entries, nil
+}
+
+func NewGeocoder() *Geocoder {
+	entries, err := parseGazetteer(gazetteerFile)
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load the gazetteer, error: %v\n", err))
+	}
+
+	return &Geocoder{
+		entries: entries,
+	}
+}
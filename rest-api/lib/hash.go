@@ -1,24 +1,174 @@
 package lib
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-type Hasher struct{}
+	"example.com/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
 
+const (
+	argon2idHashPrefix = "$argon2id$"
+	argon2SaltLength   = 16
+	argon2KeyLength    = 32
+)
+
+type Hasher struct {
+	hashing config.PasswordHashingConfiguration
+	//hash of a random password created with the configured settings
+	dummyPasswordHash string
+}
+
+type argon2idParameters struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// Hashes the password with the configured algorithm. Argon2id hashes are PHC strings such as
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>, bcrypt hashes keep the modular crypt format the
+// PHC format derives from, such as $2a$12$<salt and hash>
 func (h *Hasher) HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if h.hashing.Algorithm == config.BCRYPT_PASSWORD_HASHING {
+		hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.hashing.BcryptCost)
+
+		if err != nil {
+			return "", err
+		}
+
+		return string(hashedBytes), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+
+	_, err := rand.Read(salt)
 
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
-	return string(hashedBytes), nil
+	parameters := h.argon2idParameters()
+
+	key := argon2.IDKey([]byte(password), salt, parameters.iterations, parameters.memory, parameters.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf(
+		"%vv=%v$m=%v,t=%v,p=%v$%v$%v",
+		argon2idHashPrefix,
+		argon2.Version,
+		parameters.memory,
+		parameters.iterations,
+		parameters.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+// Validates the password against a hash of either algorithm, using the parameters of the hash
 func (h *Hasher) ValidatePasswordHash(password, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	if !strings.HasPrefix(hashedPassword, argon2idHashPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		return err == nil
+	}
+
+	parameters, salt, key, err := decodeArgon2idHash(hashedPassword)
+
+	if err != nil {
+		return false
+	}
+
+	computedKey := argon2.IDKey([]byte(password), salt, parameters.iterations, parameters.memory, parameters.parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(computedKey, key) == 1
+}
+
+// Whether the hash was created with another algorithm or other parameters than the configured ones
+func (h *Hasher) NeedsRehash(hashedPassword string) bool {
+	if !strings.HasPrefix(hashedPassword, argon2idHashPrefix) {
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+
+		return err != nil || h.hashing.Algorithm != config.BCRYPT_PASSWORD_HASHING || cost != h.hashing.BcryptCost
+	}
+
+	parameters, _, key, err := decodeArgon2idHash(hashedPassword)
+
+	return err != nil ||
+		h.hashing.Algorithm != config.ARGON2ID_PASSWORD_HASHING ||
+		parameters != h.argon2idParameters() ||
+		len(key) != argon2KeyLength
+}
+
+// Comparing a password against this hash takes as long as against the hash of an actual user
+func (h *Hasher) DummyPasswordHash() string {
+	return h.dummyPasswordHash
+}
+
+func (h *Hasher) argon2idParameters() argon2idParameters {
+	return argon2idParameters{
+		memory:      h.hashing.Argon2Memory,
+		iterations:  h.hashing.Argon2Iterations,
+		parallelism: h.hashing.Argon2Parallelism,
+	}
 }
 
-func NewHasher() *Hasher {
-	return &Hasher{}
+func decodeArgon2idHash(hashedPassword string) (argon2idParameters, []byte, []byte, error) {
+	var parameters argon2idParameters
+	var version int
+
+	//the hash starts with a separator, so the first part is empty
+	parts := strings.Split(hashedPassword, "$")
+
+	if len(parts) != 6 {
+		return parameters, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+
+	if err != nil || version != argon2.Version {
+		return parameters, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parameters.memory, &parameters.iterations, &parameters.parallelism)
+
+	if err != nil || parameters.iterations == 0 || parameters.parallelism == 0 {
+		return parameters, nil, nil, errors.New("malformed argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+
+	if err != nil {
+		return parameters, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+
+	if err != nil || len(key) == 0 {
+		return parameters, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	return parameters, salt, key, nil
+}
+
+func NewHasher() (*Hasher, error) {
+	hasher := &Hasher{
+		hashing: config.AppConfiguration().PasswordHashing(),
+	}
+
+	dummyPassword, err := GenerateTokenId()
+
+	if err != nil {
+		return nil, err
+	}
+
+	hasher.dummyPasswordHash, err = hasher.HashPassword(dummyPassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hasher, nil
 }
@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/test_utils"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type HasherUnitTestSuite struct {
+	suite.Suite
+	hasher *Hasher
+}
+
+func TestHasherUnitTestSuite(t *testing.T) {
+	suite.Run(t, &HasherUnitTestSuite{})
+}
+
+func (suite *HasherUnitTestSuite) SetupTest() {
+	suite.hasher = suite.newHasher(map[string]string{})
+}
+
+// Hasher with the smallest parameters allowed, so the tests stay fast
+func (suite *HasherUnitTestSuite) newHasher(variables map[string]string) *Hasher {
+	configuration := map[string]string{
+		"TOKEN_SECRET":            "token secret",
+		"PASSWORD_HASH_ALGORITHM": "argon2id",
+		"BCRYPT_COST":             "4",
+		"ARGON2_MEMORY_KIB":       "8192",
+		"ARGON2_ITERATIONS":       "1",
+		"ARGON2_PARALLELISM":      "1",
+	}
+
+	for name, value := range variables {
+		configuration[name] = value
+	}
+
+	test_utils.LoadConfiguration(suite.T(), configuration)
+
+	hasher, err := NewHasher()
+
+	suite.Require().Nil(err)
+
+	return hasher
+}
+
+func (suite *HasherUnitTestSuite) TestHashPassword_ValidatesThePassword() {
+
+	hashedPassword, err := suite.hasher.HashPassword("password")
+
+	suite.Require().Nil(err)
+	suite.True(strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=8192,t=1,p=1$"))
+	suite.True(suite.hasher.ValidatePasswordHash("password", hashedPassword))
+	suite.False(suite.hasher.NeedsRehash(hashedPassword))
+}
+
+// When the password is wrong, refuse it
+func (suite *HasherUnitTestSuite) TestValidatePasswordHashWrongPassword_RefusesThePassword() {
+
+	hashedPassword, err := suite.hasher.HashPassword("password")
+
+	suite.Require().Nil(err)
+	suite.False(suite.hasher.ValidatePasswordHash("other password", hashedPassword))
+}
+
+// Passwords hashed before argon2id was the default keep working
+func (suite *HasherUnitTestSuite) TestValidatePasswordHashBcrypt_ValidatesThePassword() {
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	suite.Require().Nil(err)
+	suite.True(suite.hasher.ValidatePasswordHash("password", string(hashedPassword)))
+	suite.False(suite.hasher.ValidatePasswordHash("other password", string(hashedPassword)))
+	suite.True(suite.hasher.NeedsRehash(string(hashedPassword)))
+}
+
+// When the hash was created with other parameters, it is replaced on the next login
+func (suite *HasherUnitTestSuite) TestNeedsRehashOutdatedParameters_ReturnsTrue() {
+
+	hashedPassword, err := suite.newHasher(map[string]string{"ARGON2_ITERATIONS": "2"}).HashPassword("password")
+
+	suite.Require().Nil(err)
+	suite.True(suite.hasher.ValidatePasswordHash("password", hashedPassword))
+	suite.True(suite.hasher.NeedsRehash(hashedPassword))
+}
+
+// When the hash is not a valid PHC string, refuse every password
+func (suite *HasherUnitTestSuite) TestValidatePasswordHashMalformedHash_RefusesThePassword() {
+
+	hashedPassword, err := suite.hasher.HashPassword("password")
+
+	suite.Require().Nil(err)
+
+	for _, malformedHash := range []string{
+		"$argon2id$v=19$m=8192,t=1,p=1$",
+		strings.Replace(hashedPassword, "v=19", "v=16", 1),
+		strings.Replace(hashedPassword, "t=1", "t=0", 1),
+		hashedPassword[:strings.LastIndex(hashedPassword, "$")] + "$",
+		hashedPassword + "$extra",
+	} {
+		suite.False(suite.hasher.ValidatePasswordHash("password", malformedHash), malformedHash)
+		suite.True(suite.hasher.NeedsRehash(malformedHash), malformedHash)
+	}
+}
+
+// The dummy hash is an actual hash of the configured settings, which no password matches
+func (suite *HasherUnitTestSuite) TestDummyPasswordHash_UsesTheConfiguredParameters() {
+
+	dummyPasswordHash := suite.hasher.DummyPasswordHash()
+
+	suite.True(strings.HasPrefix(dummyPasswordHash, "$argon2id$v=19$m=8192,t=1,p=1$"))
+	suite.False(suite.hasher.NeedsRehash(dummyPasswordHash))
+	suite.False(suite.hasher.ValidatePasswordHash("", dummyPasswordHash))
+}
+
+// When bcrypt is configured, only bcrypt hashes of the configured cost are kept
+func (suite *HasherUnitTestSuite) TestHashPasswordBcrypt_ValidatesThePassword() {
+
+	hasher := suite.newHasher(map[string]string{"PASSWORD_HASH_ALGORITHM": "bcrypt"})
+
+	hashedPassword, err := hasher.HashPassword("password")
+
+	suite.Require().Nil(err)
+	suite.True(strings.HasPrefix(hashedPassword, "$2a$04$"))
+	suite.True(hasher.ValidatePasswordHash("password", hashedPassword))
+	suite.False(hasher.NeedsRehash(hashedPassword))
+
+	argon2idHash, err := suite.hasher.HashPassword("password")
+
+	suite.Require().Nil(err)
+	suite.True(hasher.NeedsRehash(argon2idHash))
+}
@@ -6,75 +6,245 @@ import (
 	"time"
 
 	"example.com/config"
+	"example.com/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type JwtAuthorizer struct{}
+const ACCESS_TOKEN_LIFETIME = time.Minute * 5
 
-func (j *JwtAuthorizer) GenerateToken(email, userId string) (string, error) {
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		jwt.MapClaims{
-			"email":  email,
-			"userId": userId,
-			"exp":    time.Now().Add(time.Minute * 5).Unix(),
-		})
-
-	appConfig := config.AppConfiguration()
+type JwtAuthorizer struct {
+	//nil when tokens are signed with the shared secret
+	signingKey *JwtKey
+	//keys tokens are accepted from, the signing key first
+	verificationKeys []*JwtKey
+	issuer           string
+	audience         string
+}
 
-	secretKey, err := appConfig.JwtSecretKey()
+// Issues an access token embedding the role of the user along with the permissions it grants, tied
+// to the login session it is issued for. The token acts in the organization of the membership, an
+// empty membership meaning the personal space
+func (j *JwtAuthorizer) GenerateToken(user models.User, sessionId string, membership models.OrganizationMembership) (string, error) {
+	//the token id allows revoking a single token before it expires
+	tokenId, err := GenerateTokenId()
 
 	if err != nil {
 		return "", err
 	}
 
-	return token.SignedString([]byte(secretKey))
-}
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"email":       user.Email,
+		"userId":      strconv.FormatInt(user.Id, 10),
+		"role":        user.Role,
+		"permissions": user.Permissions(),
+		"iss":         j.issuer,
+		"aud":         j.audience,
+		"jti":         tokenId,
+		"sid":         sessionId,
+		"org":         strconv.FormatInt(membership.OrganizationId, 10),
+		"org_role":    membership.Role,
+		"iat":         now.Unix(),
+		"nbf":         now.Unix(),
+		"exp":         now.Add(ACCESS_TOKEN_LIFETIME).Unix(),
+	}
 
-func (j *JwtAuthorizer) ValidateToken(token string) (int64, error) {
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
-		_, correctSigningType := token.Method.(*jwt.SigningMethodHMAC)
+	if j.signingKey == nil {
+		secretKey, err := config.AppConfiguration().JwtSecretKey()
 
-		if !correctSigningType {
-			return nil, errors.New("invalid token signing method")
+		if err != nil {
+			return "", err
 		}
 
-		appConfig := config.AppConfiguration()
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+	}
 
-		secretKey, err := appConfig.JwtSecretKey()
+	token := jwt.NewWithClaims(j.signingKey.SigningMethod, claims)
 
-		if err != nil {
-			return "", err
-		}
+	token.Header["kid"] = j.signingKey.Id
 
-		return []byte(secretKey), nil
-	})
+	return token.SignedString(j.signingKey.PrivateKey)
+}
+
+func (j *JwtAuthorizer) ValidateToken(token string) (*models.AccessTokenClaims, error) {
+	parsedToken, err := jwt.Parse(
+		token,
+		j.verificationKey,
+		jwt.WithIssuer(j.issuer),
+		jwt.WithAudience(j.audience),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired())
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if !parsedToken.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
 	claims, validClaimsType := parsedToken.Claims.(jwt.MapClaims)
 
 	if !validClaimsType {
-		return 0, errors.New("malformed token")
+		return nil, errors.New("malformed token")
 	}
 
-	userId := claims["userId"].(string)
+	userId, _ := claims["userId"].(string)
 
 	parsedUserId, err := strconv.ParseInt(userId, 10, 64)
 
 	if err != nil {
-		return 0, errors.New("invalid user id format")
+		return nil, errors.New("invalid user id format")
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+
+	if err != nil || expiresAt == nil {
+		return nil, errors.New("missing token expiration")
+	}
+
+	//the parser checks the not before time when present, every token issued here has one
+	notBefore, err := claims.GetNotBefore()
+
+	if err != nil || notBefore == nil {
+		return nil, errors.New("missing token not before time")
+	}
+
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+	tokenId, _ := claims["jti"].(string)
+	sessionId, _ := claims["sid"].(string)
+	organizationRole, _ := claims["org_role"].(string)
+	organizationId, _ := claims["org"].(string)
+
+	//tokens issued before organizations existed act in the personal space
+	parsedOrganizationId := int64(0)
+
+	if organizationId != "" {
+		parsedOrganizationId, err = strconv.ParseInt(organizationId, 10, 64)
+
+		if err != nil {
+			return nil, errors.New("invalid organization id format")
+		}
+	}
+
+	if tokenId == "" {
+		return nil, errors.New("missing token id")
+	}
+
+	return &models.AccessTokenClaims{
+		UserId:           parsedUserId,
+		Email:            email,
+		Role:             role,
+		Permissions:      parsePermissions(claims["permissions"]),
+		TokenId:          tokenId,
+		SessionId:        sessionId,
+		OrganizationId:   parsedOrganizationId,
+		OrganizationRole: organizationRole,
+		ExpiresAt:        expiresAt.Time,
+	}, nil
+}
+
+// Public keys tokens can be verified with, empty when tokens are signed with the shared secret
+func (j *JwtAuthorizer) JsonWebKeySet() models.JsonWebKeySet {
+	keys := make([]models.JsonWebKey, 0, len(j.verificationKeys))
+
+	for _, key := range j.verificationKeys {
+		keys = append(keys, key.Jwk)
+	}
+
+	return models.JsonWebKeySet{Keys: keys}
+}
+
+// Picks the key named by the kid header. The algorithm has to be the one of the key, otherwise a
+// public key could be passed off as an HMAC secret. Tokens signed with the shared secret are refused
+// as soon as a signing key is configured
+func (j *JwtAuthorizer) verificationKey(token *jwt.Token) (any, error) {
+	_, isHmac := token.Method.(*jwt.SigningMethodHMAC)
+
+	if isHmac {
+		if j.signingKey != nil {
+			return nil, errors.New("invalid token signing method")
+		}
+
+		secretKey, err := config.AppConfiguration().JwtSecretKey()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(secretKey), nil
 	}
 
-	return parsedUserId, nil
+	keyId, _ := token.Header["kid"].(string)
+
+	for _, key := range j.verificationKeys {
+		if key.Id != keyId {
+			continue
+		}
+
+		if token.Method.Alg() != key.SigningMethod.Alg() {
+			return nil, errors.New("invalid token signing method")
+		}
+
+		return key.PublicKey, nil
+	}
+
+	return nil, errors.New("unknown token signing key")
 }
 
-func NewJwtAuthorizer() *JwtAuthorizer {
-	return &JwtAuthorizer{}
+// Decoded JSON arrays hold values of any type, anything but strings is ignored
+func parsePermissions(claim any) []string {
+	values, _ := claim.([]any)
+
+	permissions := []string{}
+
+	for _, value := range values {
+		permission, isString := value.(string)
+
+		if isString {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions
+}
+
+// Signs tokens with the configured signing key, or with the shared secret when there is none
+func NewJwtAuthorizer() (*JwtAuthorizer, error) {
+	appConfig := config.AppConfiguration()
+
+	authorizer := &JwtAuthorizer{
+		verificationKeys: []*JwtKey{},
+		issuer:           appConfig.JwtIssuer(),
+		audience:         appConfig.JwtAudience(),
+	}
+
+	if appConfig.JwtSigningKeyFile() != "" {
+		signingKey, err := LoadJwtKey(appConfig.JwtSigningKeyFile())
+
+		if err != nil {
+			return nil, err
+		}
+
+		if signingKey.PrivateKey == nil {
+			return nil, errors.New("the jwt signing key file holds a public key, a private key is needed to sign tokens")
+		}
+
+		authorizer.signingKey = signingKey
+		authorizer.verificationKeys = append(authorizer.verificationKeys, signingKey)
+	}
+
+	for _, keyFile := range appConfig.JwtVerificationKeyFiles() {
+		key, err := LoadJwtKey(keyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		authorizer.verificationKeys = append(authorizer.verificationKeys, key)
+	}
+
+	return authorizer, nil
 }
@@ -0,0 +1,192 @@
+package lib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"example.com/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Shorter RSA keys can be factored, tokens signed with them could be forged
+const minimumRsaKeyBits = 2048
+
+// Asymmetric key access tokens are signed or verified with. Its id is the RFC 7638 thumbprint of
+// the public key, so the same key always gets the same id wherever it is loaded
+type JwtKey struct {
+	Id            string
+	SigningMethod jwt.SigningMethod
+	// nil for keys only used to verify tokens
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+	Jwk        models.JsonWebKey
+}
+
+// Loads a PEM encoded private or public key, private keys are PKCS #8, PKCS #1 or SEC 1 encoded,
+// public keys are PKIX or PKCS #1 encoded
+func LoadJwtKey(path string) (*JwtKey, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("no pem encoded key found in %v", path)
+	}
+
+	var parsedKey any
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsedKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsedKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsedKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block %v in %v", block.Type, path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the key in %v: %w", path, err)
+	}
+
+	key, err := newJwtKey(parsedKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to use the key in %v: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func newJwtKey(parsedKey any) (*JwtKey, error) {
+	key := &JwtKey{}
+
+	privateKey, isPrivateKey := parsedKey.(crypto.Signer)
+
+	if isPrivateKey {
+		key.PrivateKey = privateKey
+		key.PublicKey = privateKey.Public()
+	} else {
+		key.PublicKey = parsedKey
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if publicKey.N.BitLen() < minimumRsaKeyBits {
+			return nil, fmt.Errorf("rsa keys need at least %v bits", minimumRsaKeyBits)
+		}
+
+		key.SigningMethod = jwt.SigningMethodRS256
+		key.Jwk = models.JsonWebKey{
+			KeyType:  "RSA",
+			Modulus:  encodeKeyBytes(publicKey.N.Bytes()),
+			Exponent: encodeKeyBytes(big.NewInt(int64(publicKey.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		signingMethod, err := ecdsaSigningMethod(publicKey.Curve)
+
+		if err != nil {
+			return nil, err
+		}
+
+		//coordinates are padded to the size of the curve, as RFC 7518 requires
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+
+		key.SigningMethod = signingMethod
+		key.Jwk = models.JsonWebKey{
+			KeyType: "EC",
+			Curve:   publicKey.Curve.Params().Name,
+			X:       encodeKeyBytes(publicKey.X.FillBytes(make([]byte, size))),
+			Y:       encodeKeyBytes(publicKey.Y.FillBytes(make([]byte, size))),
+		}
+	case ed25519.PublicKey:
+		key.SigningMethod = jwt.SigningMethodEdDSA
+		key.Jwk = models.JsonWebKey{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       encodeKeyBytes(publicKey),
+		}
+	default:
+		return nil, errors.New("unsupported key type, expected an rsa, ecdsa or ed25519 key")
+	}
+
+	thumbprint, err := jwkThumbprint(key.Jwk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key.Id = thumbprint
+	key.Jwk.KeyId = thumbprint
+	key.Jwk.Use = "sig"
+	key.Jwk.Algorithm = key.SigningMethod.Alg()
+
+	return key, nil
+}
+
+func ecdsaSigningMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, errors.New("unsupported ecdsa curve, expected P-256, P-384 or P-521")
+	}
+}
+
+// Hash of the required members of the key, serialized in lexicographic order without whitespace
+// as RFC 7638 describes, which encoding a map produces
+func jwkThumbprint(jwk models.JsonWebKey) (string, error) {
+	members := map[string]string{"kty": jwk.KeyType}
+
+	switch jwk.KeyType {
+	case "RSA":
+		members["n"] = jwk.Modulus
+		members["e"] = jwk.Exponent
+	case "EC":
+		members["crv"] = jwk.Curve
+		members["x"] = jwk.X
+		members["y"] = jwk.Y
+	case "OKP":
+		members["crv"] = jwk.Curve
+		members["x"] = jwk.X
+	}
+
+	serializedMembers, err := json.Marshal(members)
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(serializedMembers)
+
+	return encodeKeyBytes(hash[:]), nil
+}
+
+func encodeKeyBytes(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
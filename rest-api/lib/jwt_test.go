@@ -0,0 +1,246 @@
+package lib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"example.com/test_utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type JwtAuthorizerUnitTestSuite struct {
+	suite.Suite
+	rsaKey *rsa.PrivateKey
+	//key files written for the current test
+	rsaKeyFile string
+	ecKeyFile  string
+	user       models.User
+}
+
+func TestJwtAuthorizerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &JwtAuthorizerUnitTestSuite{})
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) SetupSuite() {
+	//generating RSA keys is slow, the same one is used by every test
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minimumRsaKeyBits)
+
+	suite.Require().Nil(err)
+
+	suite.rsaKey = rsaKey
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) SetupTest() {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	suite.Require().Nil(err)
+
+	suite.rsaKeyFile = suite.writeKeyFile(suite.rsaKey)
+	suite.ecKeyFile = suite.writeKeyFile(ecKey)
+	suite.user = models.User{Id: 12, Email: "test@test.com", Role: models.USER_ROLE}
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) writeKeyFile(privateKey any) string {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+
+	suite.Require().Nil(err)
+
+	path := filepath.Join(suite.T().TempDir(), "key.pem")
+
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	suite.Require().Nil(err)
+
+	return path
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) newAuthorizer(variables map[string]string) *JwtAuthorizer {
+	variables["TOKEN_SECRET"] = "token secret"
+
+	test_utils.LoadConfiguration(suite.T(), variables)
+
+	authorizer, err := NewJwtAuthorizer()
+
+	suite.Require().Nil(err)
+
+	return authorizer
+}
+
+// Claims of a token the authorizer would issue, for tests to tamper with
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"userId": "12",
+		"iss":    "events-api",
+		"aud":    "events-api",
+		"jti":    "token id",
+		"iat":    now.Unix(),
+		"nbf":    now.Unix(),
+		"exp":    now.Add(ACCESS_TOKEN_LIFETIME).Unix(),
+	}
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) signClaims(keyFile string, claims jwt.MapClaims) string {
+	key, err := LoadJwtKey(keyFile)
+
+	suite.Require().Nil(err)
+
+	token := jwt.NewWithClaims(key.SigningMethod, claims)
+
+	token.Header["kid"] = key.Id
+
+	signedToken, err := token.SignedString(key.PrivateKey)
+
+	suite.Require().Nil(err)
+
+	return signedToken
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateToken_ReturnsTheClaims() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	token, err := authorizer.GenerateToken(suite.user, "session", models.OrganizationMembership{OrganizationId: 4, Role: models.ORGANIZATION_ADMIN_ROLE})
+
+	suite.Require().Nil(err)
+
+	claims, err := authorizer.ValidateToken(token)
+
+	suite.Require().Nil(err)
+	suite.Equal(int64(12), claims.UserId)
+	suite.Equal("session", claims.SessionId)
+	suite.Equal(int64(4), claims.OrganizationId)
+	suite.NotEmpty(claims.TokenId)
+}
+
+// A token signed with the shared secret is refused once a signing key is configured, whether the
+// secret or the public key was used as the HMAC key
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenHs256WithSigningKey_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.rsaKeyFile})
+
+	secretToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("token secret"))
+
+	suite.Require().Nil(err)
+
+	_, err = authorizer.ValidateToken(secretToken)
+
+	suite.NotNil(err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&suite.rsaKey.PublicKey)
+
+	suite.Require().Nil(err)
+
+	publicKeyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).
+		SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	suite.Require().Nil(err)
+
+	_, err = authorizer.ValidateToken(publicKeyToken)
+
+	suite.NotNil(err)
+}
+
+// A token signed with a key that is not configured is refused
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenUnknownKeyId_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.rsaKeyFile})
+
+	token := suite.signClaims(suite.ecKeyFile, validClaims())
+
+	_, err := authorizer.ValidateToken(token)
+
+	suite.NotNil(err)
+}
+
+// Tokens signed with the previous key are accepted while it is listed as a verification key
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenAfterRotation_AcceptsThePreviousKey() {
+
+	previousAuthorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	token, err := previousAuthorizer.GenerateToken(suite.user, "session", models.OrganizationMembership{})
+
+	suite.Require().Nil(err)
+
+	rotatedAuthorizer := suite.newAuthorizer(map[string]string{
+		"JWT_SIGNING_KEY_FILE":       suite.rsaKeyFile,
+		"JWT_VERIFICATION_KEY_FILES": suite.ecKeyFile,
+	})
+
+	claims, err := rotatedAuthorizer.ValidateToken(token)
+
+	suite.Require().Nil(err)
+	suite.Equal(int64(12), claims.UserId)
+	suite.Len(rotatedAuthorizer.JsonWebKeySet().Keys, 2)
+
+	//once the previous key is removed, its tokens are refused
+	retiredAuthorizer := suite.newAuthorizer(map[string]string{
+		"JWT_SIGNING_KEY_FILE":       suite.rsaKeyFile,
+		"JWT_VERIFICATION_KEY_FILES": "",
+	})
+
+	_, err = retiredAuthorizer.ValidateToken(token)
+
+	suite.NotNil(err)
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenWrongIssuer_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	claims := validClaims()
+	claims["iss"] = "another-api"
+
+	_, err := authorizer.ValidateToken(suite.signClaims(suite.ecKeyFile, claims))
+
+	suite.NotNil(err)
+}
+
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenWrongAudience_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	claims := validClaims()
+	claims["aud"] = "another-api"
+
+	_, err := authorizer.ValidateToken(suite.signClaims(suite.ecKeyFile, claims))
+
+	suite.NotNil(err)
+}
+
+// A token is refused before its not before time
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenFutureNotBefore_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	claims := validClaims()
+	claims["nbf"] = time.Now().Add(time.Hour).Unix()
+
+	_, err := authorizer.ValidateToken(suite.signClaims(suite.ecKeyFile, claims))
+
+	suite.NotNil(err)
+}
+
+// Without a token id the token could not be revoked, so it is refused
+func (suite *JwtAuthorizerUnitTestSuite) TestValidateTokenMissingTokenId_ReturnsAnError() {
+
+	authorizer := suite.newAuthorizer(map[string]string{"JWT_SIGNING_KEY_FILE": suite.ecKeyFile})
+
+	claims := validClaims()
+	delete(claims, "jti")
+
+	_, err := authorizer.ValidateToken(suite.signClaims(suite.ecKeyFile, claims))
+
+	suite.Equal("missing token id", err.Error())
+}
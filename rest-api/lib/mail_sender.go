@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"example.com/config"
+	"example.com/models"
+)
+
+// Mail sender meant for development, messages are printed to the standard output and appended
+// to the file set by MAIL_OUTPUT_FILE when configured, instead of being delivered
+type LogMailSender struct {
+	outputFile string
+	mutex      sync.Mutex
+}
+
+func (sender *LogMailSender) Send(message models.MailMessage) error {
+	formattedMessage := fmt.Sprintf(
+		"Date: %v\nTo: %v\nSubject: %v\n\n%v\n\n",
+		time.Now().UTC().Format(time.RFC1123Z),
+		message.To,
+		message.Subject,
+		message.Body)
+
+	fmt.Print(formattedMessage)
+
+	if sender.outputFile == "" {
+		return nil
+	}
+
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	file, err := os.OpenFile(sender.outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = file.WriteString(formattedMessage)
+
+	return err
+}
+
+func NewLogMailSender() *LogMailSender {
+	return &LogMailSender{
+		outputFile: config.AppConfiguration().MailOutputFile(),
+	}
+}
@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	//only matches things that look like tags, so comparisons such as "a < b" are kept
+	htmlTagPattern = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^<>]*)?/?>`)
+	//start of the target of inline links and images, [text](target) or ![alt](target)
+	inlineLinkPattern = regexp.MustCompile(`(?i)\]\(\s*<?\s*(javascript|vbscript|data|file):`)
+	//reference definitions, [id]: target
+	referenceLinkPattern = regexp.MustCompile(`(?im)^(\s*\[[^\]]+\]:\s*<?)\s*(javascript|vbscript|data|file):\S*`)
+)
+
+// Sanitizes user provided markdown so it can safely be rendered by clients. Markdown is kept as
+// is, but embedded html is removed and links using scriptable schemes are neutralized
+type MarkdownSanitizer struct{}
+
+func (s *MarkdownSanitizer) Sanitize(markdown string) string {
+	sanitized := strings.ReplaceAll(markdown, "\r\n", "\n")
+
+	sanitized = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' && r != '\t' {
+			return -1
+		}
+
+		return r
+	}, sanitized)
+
+	sanitized = htmlCommentPattern.ReplaceAllString(sanitized, "")
+
+	//removing a tag can form a new one, e.g. <scr<script>ipt>
+	for htmlTagPattern.MatchString(sanitized) {
+		sanitized = htmlTagPattern.ReplaceAllString(sanitized, "")
+	}
+
+	sanitized = neutralizeInlineLinks(sanitized)
+	sanitized = referenceLinkPattern.ReplaceAllString(sanitized, "${1}#")
+
+	return strings.TrimSpace(sanitized)
+}
+
+// Replaces the targets of inline links using scriptable schemes with "#". Targets may contain
+// balanced parentheses, e.g. javascript:alert(1), so the end of the target is found by counting them
+func neutralizeInlineLinks(markdown string) string {
+	var builder strings.Builder
+
+	for {
+		location := inlineLinkPattern.FindStringIndex(markdown)
+
+		if location == nil {
+			builder.WriteString(markdown)
+			return builder.String()
+		}
+
+		builder.WriteString(markdown[:location[0]])
+		builder.WriteString("](#")
+
+		end := location[1]
+
+		for depth := 0; end < len(markdown); end++ {
+			character := markdown[end]
+
+			if character == '(' {
+				depth++
+			} else if character == ')' {
+				if depth == 0 {
+					break
+				}
+
+				depth--
+			} else if character == ' ' || character == '\n' || character == '>' {
+				break
+			}
+		}
+
+		//targets may be wrapped in angle brackets, which are dropped along with the target
+		if end < len(markdown) && markdown[end] == '>' && strings.Contains(markdown[location[0]:location[1]], "<") {
+			end++
+		}
+
+		markdown = markdown[end:]
+	}
+}
+
+func NewMarkdownSanitizer() *MarkdownSanitizer {
+	return &MarkdownSanitizer{}
+}
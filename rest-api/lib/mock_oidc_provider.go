@@ -0,0 +1,277 @@
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/config"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	MOCK_OIDC_PROVIDER_NAME = "mock"
+	MOCK_OIDC_PATH          = "/mock-oidc"
+	MOCK_OIDC_CLIENT_ID     = "mock-client"
+	MOCK_OIDC_CLIENT_SECRET = "mock-secret"
+	// Email of the user signed in when the authorization request has no login_hint
+	mockOidcDefaultEmail      = "mock.user@example.com"
+	mockOidcCodeLifetime      = time.Minute
+	mockOidcIdTokenLifetime   = time.Minute * 5
+	mockOidcSigningKeyId      = "mock-signing-key"
+	mockOidcDiscoveryPath     = "/.well-known/openid-configuration"
+	mockOidcAuthorizationPath = "/authorize"
+	mockOidcTokenPath         = "/token"
+	mockOidcJwksPath          = "/jwks"
+)
+
+// OpenID Connect provider served by the api itself, so the login flow can be used in development
+// and tests without a real provider. Every authorization request is approved right away for the
+// email passed as login_hint, PKCE is required like real providers should
+type MockOidcProvider struct {
+	issuer     string
+	signingKey *rsa.PrivateKey
+	mutex      sync.Mutex
+	codes      map[string]mockAuthorizationCode
+}
+
+type mockAuthorizationCode struct {
+	email         string
+	redirectUri   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+func (provider *MockOidcProvider) Issuer() string {
+	return provider.issuer
+}
+
+// Client sending requests straight to the provider handler rather than over the network
+func (provider *MockOidcProvider) HttpClient() *http.Client {
+	return &http.Client{Transport: inProcessTransport{handler: provider}}
+}
+
+// Serves the provider endpoints, paths are expected to start with the path of the issuer
+func (provider *MockOidcProvider) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	issuerUrl, _ := url.Parse(provider.issuer)
+
+	switch strings.TrimPrefix(request.URL.Path, issuerUrl.Path) {
+	case mockOidcDiscoveryPath:
+		provider.discovery(writer)
+	case mockOidcAuthorizationPath:
+		provider.authorize(writer, request)
+	case mockOidcTokenPath:
+		provider.token(writer, request)
+	case mockOidcJwksPath:
+		provider.jwks(writer)
+	default:
+		http.NotFound(writer, request)
+	}
+}
+
+func (provider *MockOidcProvider) discovery(writer http.ResponseWriter) {
+	writeJson(writer, http.StatusOK, map[string]any{
+		"issuer":                                provider.issuer,
+		"authorization_endpoint":                provider.issuer + mockOidcAuthorizationPath,
+		"token_endpoint":                        provider.issuer + mockOidcTokenPath,
+		"jwks_uri":                              provider.issuer + mockOidcJwksPath,
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+func (provider *MockOidcProvider) authorize(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+
+	redirectUri, err := url.Parse(query.Get("redirect_uri"))
+
+	if err != nil || !redirectUri.IsAbs() || query.Get("client_id") != MOCK_OIDC_CLIENT_ID {
+		http.Error(writer, "invalid client or redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("response_type") != "code" {
+		redirectWithParameters(writer, request, redirectUri, "error", "unsupported_response_type", "state", query.Get("state"))
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		redirectWithParameters(writer, request, redirectUri, "error", "invalid_request", "state", query.Get("state"))
+		return
+	}
+
+	email := query.Get("login_hint")
+
+	if email == "" {
+		email = mockOidcDefaultEmail
+	}
+
+	code, err := GenerateTokenId()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	provider.mutex.Lock()
+	provider.codes[code] = mockAuthorizationCode{
+		email:         email,
+		redirectUri:   redirectUri.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(mockOidcCodeLifetime),
+	}
+	provider.mutex.Unlock()
+
+	redirectWithParameters(writer, request, redirectUri, "code", code, "state", query.Get("state"))
+}
+
+func (provider *MockOidcProvider) token(writer http.ResponseWriter, request *http.Request) {
+	err := request.ParseForm()
+
+	if err != nil || request.PostForm.Get("grant_type") != "authorization_code" {
+		writeJson(writer, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	clientId, clientSecret, basicAuth := request.BasicAuth()
+
+	if !basicAuth {
+		clientId, clientSecret = request.PostForm.Get("client_id"), request.PostForm.Get("client_secret")
+	}
+
+	if clientId != MOCK_OIDC_CLIENT_ID || clientSecret != MOCK_OIDC_CLIENT_SECRET {
+		writeJson(writer, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	//codes are single use, they are removed whether the exchange succeeds or not
+	provider.mutex.Lock()
+	code, found := provider.codes[request.PostForm.Get("code")]
+	delete(provider.codes, request.PostForm.Get("code"))
+	provider.mutex.Unlock()
+
+	if !found ||
+		time.Now().After(code.expiresAt) ||
+		code.redirectUri != request.PostForm.Get("redirect_uri") ||
+		code.codeChallenge != oauth2.S256ChallengeFromVerifier(request.PostForm.Get("code_verifier")) {
+		writeJson(writer, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            provider.issuer,
+		"sub":            "mock|" + code.email,
+		"aud":            MOCK_OIDC_CLIENT_ID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(mockOidcIdTokenLifetime).Unix(),
+		"nonce":          code.nonce,
+		"email":          code.email,
+		"email_verified": true,
+	})
+
+	idToken.Header["kid"] = mockOidcSigningKeyId
+
+	signedIdToken, err := idToken.SignedString(provider.signingKey)
+
+	if err != nil {
+		writeJson(writer, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, _ := GenerateTokenId()
+
+	writer.Header().Set("Cache-Control", "no-store")
+	writeJson(writer, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(mockOidcIdTokenLifetime.Seconds()),
+		"id_token":     signedIdToken,
+	})
+}
+
+func (provider *MockOidcProvider) jwks(writer http.ResponseWriter) {
+	publicKey := provider.signingKey.PublicKey
+
+	writeJson(writer, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": mockOidcSigningKeyId,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func redirectWithParameters(writer http.ResponseWriter, request *http.Request, redirectUri *url.URL, keyValues ...string) {
+	query := redirectUri.Query()
+
+	for index := 0; index+1 < len(keyValues); index += 2 {
+		query.Set(keyValues[index], keyValues[index+1])
+	}
+
+	redirectUri.RawQuery = query.Encode()
+
+	http.Redirect(writer, request, redirectUri.String(), http.StatusFound)
+}
+
+func writeJson(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(body)
+}
+
+// Hands requests to a handler in the same process, without opening a connection
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (transport inProcessTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+
+	transport.handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	response.Request = request
+
+	return response, nil
+}
+
+// Returns nil unless the mock provider is enabled by the configuration
+func NewMockOidcProvider() (*MockOidcProvider, error) {
+	if !config.AppConfiguration().MockOidcProvider() {
+		return nil, nil
+	}
+
+	log.Println("WARNING: the mock oidc provider is enabled, anyone can log in as any user. It is meant for development and tests only")
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MockOidcProvider{
+		issuer:     PublicBaseUrl() + MOCK_OIDC_PATH,
+		signingKey: signingKey,
+		codes:      map[string]mockAuthorizationCode{},
+	}, nil
+}
@@ -0,0 +1,207 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
+	"example.com/models"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const oidcRequestTimeout = time.Second * 10
+
+// Runs the authorization code flow with PKCE against the configured OpenID Connect providers.
+// Providers are discovered on first use, so the api starts even when a provider is unreachable
+type OidcAuthenticator struct {
+	providers map[string]oidcProviderClient
+	mutex     sync.Mutex
+	// Providers already discovered, by name
+	discovered map[string]*oidc.Provider
+}
+
+type oidcProviderClient struct {
+	configuration config.OidcProviderConfiguration
+	// Client used to reach the provider, nil for the default client
+	httpClient *http.Client
+}
+
+// Returns the url of the provider the user has to be redirected to
+func (authenticator *OidcAuthenticator) AuthorizationUrl(request models.OidcAuthorizationRequest) (string, error) {
+	oauthConfig, _, err := authenticator.oauthConfig(context.Background(), request.Provider)
+
+	if err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(
+		request.State,
+		oidc.Nonce(request.Nonce),
+		oauth2.S256ChallengeOption(request.CodeVerifier)), nil
+}
+
+// Exchanges the code the provider redirected back with, validating the id token signature against
+// the keys the provider publishes along with its issuer, audience, expiration and nonce
+func (authenticator *OidcAuthenticator) Authenticate(request models.OidcAuthorizationRequest, code string) (*models.OidcIdentity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTimeout)
+
+	defer cancel()
+
+	oauthConfig, provider, err := authenticator.oauthConfig(ctx, request.Provider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(authenticator.clientContext(ctx, request.Provider), code, oauth2.VerifierOption(request.CodeVerifier))
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawIdToken, found := token.Extra("id_token").(string)
+
+	if !found {
+		return nil, errors.New("token response does not contain an id token")
+	}
+
+	verifier := provider.Verifier(&oidc.Config{ClientID: oauthConfig.ClientID})
+
+	idToken, err := verifier.Verify(authenticator.clientContext(ctx, request.Provider), rawIdToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if idToken.Nonce != request.Nonce {
+		return nil, errors.New("id token nonce does not match the login")
+	}
+
+	var claims struct {
+		Email string `json:"email"`
+		// Some providers send the flag as a string
+		EmailVerified any `json:"email_verified"`
+	}
+
+	err = idToken.Claims(&claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OidcIdentity{
+		Provider:      request.Provider,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+	}, nil
+}
+
+func (authenticator *OidcAuthenticator) oauthConfig(ctx context.Context, providerName string) (*oauth2.Config, *oidc.Provider, error) {
+	client, found := authenticator.providers[providerName]
+
+	if !found {
+		return nil, nil, errors.New(constants.UNKNOWN_OIDC_PROVIDER_ERROR)
+	}
+
+	provider, err := authenticator.discover(ctx, providerName)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     client.configuration.ClientId,
+		ClientSecret: client.configuration.ClientSecret,
+		RedirectURL:  client.configuration.RedirectUrl,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email"},
+	}, provider, nil
+}
+
+// Failed discoveries are not cached, so a provider that was down is retried on the next login
+func (authenticator *OidcAuthenticator) discover(ctx context.Context, providerName string) (*oidc.Provider, error) {
+	authenticator.mutex.Lock()
+
+	defer authenticator.mutex.Unlock()
+
+	provider, found := authenticator.discovered[providerName]
+
+	if found {
+		return provider, nil
+	}
+
+	provider, err := oidc.NewProvider(
+		authenticator.clientContext(ctx, providerName),
+		authenticator.providers[providerName].configuration.Issuer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator.discovered[providerName] = provider
+
+	return provider, nil
+}
+
+func (authenticator *OidcAuthenticator) clientContext(ctx context.Context, providerName string) context.Context {
+	httpClient := authenticator.providers[providerName].httpClient
+
+	if httpClient == nil {
+		return ctx
+	}
+
+	return oidc.ClientContext(ctx, httpClient)
+}
+
+// Base url of the api, used for the urls providers redirect to
+func PublicBaseUrl() string {
+	publicUrl := strings.TrimRight(config.AppConfiguration().PublicUrl(), "/")
+
+	if publicUrl != "" {
+		return publicUrl
+	}
+
+	httpPort, _ := config.AppConfiguration().HttpPort()
+
+	return "http://localhost:" + httpPort
+}
+
+// Registers the configured providers, along with the mock provider when it is enabled
+func NewOidcAuthenticator(mockProvider *MockOidcProvider) *OidcAuthenticator {
+	providers := map[string]oidcProviderClient{}
+
+	for _, provider := range config.AppConfiguration().OidcProviders() {
+		providers[provider.Name] = oidcProviderClient{configuration: provider}
+	}
+
+	if mockProvider != nil {
+		providers[MOCK_OIDC_PROVIDER_NAME] = oidcProviderClient{
+			configuration: config.OidcProviderConfiguration{
+				Name:         MOCK_OIDC_PROVIDER_NAME,
+				Issuer:       mockProvider.Issuer(),
+				ClientId:     MOCK_OIDC_CLIENT_ID,
+				ClientSecret: MOCK_OIDC_CLIENT_SECRET,
+			},
+			httpClient: mockProvider.HttpClient(),
+		}
+	}
+
+	for name, provider := range providers {
+		if provider.configuration.RedirectUrl == "" {
+			provider.configuration.RedirectUrl = PublicBaseUrl() + "/auth/oidc/" + name + "/callback"
+			providers[name] = provider
+		}
+	}
+
+	return &OidcAuthenticator{
+		providers:  providers,
+		discovered: map[string]*oidc.Provider{},
+	}
+}
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Tells personal access tokens apart from the access tokens issued at login
+const personalAccessTokenPrefix = "pat_"
+
+// Generates a random token meant to be handed out once, along with the hash that should
+// be stored in its place
+func GenerateOpaqueToken() (string, string, error) {
+	bytes := make([]byte, 32)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+
+	return token, HashOpaqueToken(token), nil
+}
+
+// Opaque tokens have enough entropy that a fast hash is sufficient, unlike passwords
+func HashOpaqueToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Generates a random identifier, meant to tell tokens apart rather than to be kept secret
+func GenerateTokenId() (string, error) {
+	bytes := make([]byte, 16)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// Generates a personal access token along with the hash that should be stored in its place
+func GeneratePersonalAccessToken() (string, string, error) {
+	token, _, err := GenerateOpaqueToken()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	token = personalAccessTokenPrefix + token
+
+	return token, HashOpaqueToken(token), nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
@@ -0,0 +1,209 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"example.com/config"
+	"example.com/models"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpPeriod = 30
+	// Codes of the previous and next periods are accepted as well, to allow for clock drift
+	totpSkew       = 1
+	totpQrCodeSize = 256
+	// Marks secrets encrypted before they were stored, the base32 alphabet of secrets has no colon
+	sealedTotpSecretPrefix = "v1:"
+)
+
+var totpOptions = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// Generates and checks RFC 6238 codes, with the settings every common authenticator app supports
+type TotpAuthenticator struct{}
+
+// Generates a secret along with the otpauth uri and the QR code authenticator apps scan
+func (authenticator *TotpAuthenticator) GenerateKey(accountName string) (*models.TotpKey, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      config.AppConfiguration().TotpIssuer(),
+		AccountName: accountName,
+		Period:      totpPeriod,
+		Digits:      totpOptions.Digits,
+		Algorithm:   totpOptions.Algorithm,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := key.Image(totpQrCodeSize, totpQrCodeSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var encodedImage bytes.Buffer
+
+	err = png.Encode(&encodedImage, image)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sealedSecret, err := sealTotpSecret(key.Secret())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TotpKey{
+		Secret:       key.Secret(),
+		SealedSecret: sealedSecret,
+		Uri:          key.URL(),
+		QrCode:       "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodedImage.Bytes()),
+	}, nil
+}
+
+// Returns the time step the code belongs to and whether it is valid, the step lets callers refuse
+// codes that were already used. Expects the secret as it is stored
+func (authenticator *TotpAuthenticator) ValidateCode(storedSecret, code string, now time.Time) (int64, bool) {
+	secret, err := openTotpSecret(storedSecret)
+
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	currentStep := now.Unix() / totpPeriod
+
+	for step := currentStep - totpSkew; step <= currentStep+totpSkew; step++ {
+		expectedCode, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totpOptions)
+
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expectedCode)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Encrypts the secret with AES-GCM, so a leaked database does not let anyone generate codes
+func sealTotpSecret(secret string) (string, error) {
+	aead, err := totpSecretCipher()
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(nonce)
+
+	if err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+
+	return sealedTotpSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypts a secret sealed by sealTotpSecret, secrets stored in plain text are returned as they are
+func openTotpSecret(storedSecret string) (string, error) {
+	encoded, sealed := strings.CutPrefix(storedSecret, sealedTotpSecretPrefix)
+
+	if !sealed {
+		return storedSecret, nil
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := totpSecretCipher()
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return "", errors.New("sealed totp secret is too short")
+	}
+
+	secret, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+// The configured key is hashed into an AES-256 key, so it can be any string
+func totpSecretCipher() (cipher.AEAD, error) {
+	encryptionKey, err := config.AppConfiguration().TotpEncryptionKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256([]byte(encryptionKey))
+
+	block, err := aes.NewCipher(key[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Generates a recovery code formatted in groups, along with the hash that should be stored in its place
+func GenerateRecoveryCode() (string, string, error) {
+	randomBytes := make([]byte, 10)
+
+	_, err := rand.Read(randomBytes)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(randomBytes))
+
+	code := strings.Join([]string{encoded[0:4], encoded[4:8], encoded[8:12], encoded[12:16]}, "-")
+
+	return code, HashRecoveryCode(code), nil
+}
+
+// Recovery codes are compared regardless of case and separators, since users type them by hand
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	return HashOpaqueToken(normalized)
+}
+
+func NewTotpAuthenticator() *TotpAuthenticator {
+	return &TotpAuthenticator{}
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/test_utils"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/suite"
+)
+
+type TotpAuthenticatorUnitTestSuite struct {
+	suite.Suite
+	authenticator *TotpAuthenticator
+}
+
+func TestTotpAuthenticatorUnitTestSuite(t *testing.T) {
+	suite.Run(t, &TotpAuthenticatorUnitTestSuite{})
+}
+
+func (suite *TotpAuthenticatorUnitTestSuite) SetupTest() {
+	test_utils.LoadConfiguration(suite.T(), map[string]string{
+		"TOKEN_SECRET":        "token secret",
+		"TOTP_ENCRYPTION_KEY": "totp encryption key",
+	})
+
+	suite.authenticator = NewTotpAuthenticator()
+}
+
+// Only the encrypted secret is stored, it still validates the codes of the plain one
+func (suite *TotpAuthenticatorUnitTestSuite) TestGenerateKey_SealsTheSecret() {
+
+	key, err := suite.authenticator.GenerateKey("test@test.com")
+
+	suite.Require().Nil(err)
+	suite.True(strings.HasPrefix(key.SealedSecret, sealedTotpSecretPrefix))
+	suite.NotContains(key.SealedSecret, key.Secret)
+
+	now := time.Now()
+
+	code, err := totp.GenerateCodeCustom(key.Secret, now, totpOptions)
+
+	suite.Require().Nil(err)
+
+	_, valid := suite.authenticator.ValidateCode(key.SealedSecret, code, now)
+
+	suite.True(valid)
+}
+
+// Secrets stored before they were encrypted keep working
+func (suite *TotpAuthenticatorUnitTestSuite) TestValidateCodePlainSecret_ValidatesTheCode() {
+
+	key, err := suite.authenticator.GenerateKey("test@test.com")
+
+	suite.Require().Nil(err)
+
+	now := time.Now()
+
+	code, err := totp.GenerateCodeCustom(key.Secret, now, totpOptions)
+
+	suite.Require().Nil(err)
+
+	_, valid := suite.authenticator.ValidateCode(key.Secret, code, now)
+
+	suite.True(valid)
+}
+
+// A secret sealed with another key cannot be opened
+func (suite *TotpAuthenticatorUnitTestSuite) TestValidateCodeOtherEncryptionKey_RefusesTheCode() {
+
+	key, err := suite.authenticator.GenerateKey("test@test.com")
+
+	suite.Require().Nil(err)
+
+	test_utils.LoadConfiguration(suite.T(), map[string]string{
+		"TOKEN_SECRET":        "token secret",
+		"TOTP_ENCRYPTION_KEY": "another key",
+	})
+
+	now := time.Now()
+
+	code, err := totp.GenerateCodeCustom(key.Secret, now, totpOptions)
+
+	suite.Require().Nil(err)
+
+	_, valid := suite.authenticator.ValidateCode(key.SealedSecret, code, now)
+
+	suite.False(valid)
+}
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/config"
+	"example.com/models"
+)
+
+// Prefixed to the signed payload so the signature cannot be confused with other uses of the secret
+const verificationTokenPurpose = "email_verification."
+
+// Signs the claims of email verification links, so they can be checked without being stored
+type VerificationTokenSigner struct{}
+
+func (signer *VerificationTokenSigner) Sign(claims models.EmailVerificationClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := signVerificationPayload(encodedPayload)
+
+	if err != nil {
+		return "", err
+	}
+
+	return encodedPayload + "." + signature, nil
+}
+
+// Returns the claims of the token, refusing tokens with an invalid signature or that expired
+func (signer *VerificationTokenSigner) Verify(token string) (*models.EmailVerificationClaims, error) {
+	encodedPayload, signature, found := strings.Cut(token, ".")
+
+	if !found {
+		return nil, errors.New("malformed verification token")
+	}
+
+	expectedSignature, err := signVerificationPayload(encodedPayload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return nil, errors.New("invalid verification token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+
+	if err != nil {
+		return nil, errors.New("malformed verification token")
+	}
+
+	var claims models.EmailVerificationClaims
+
+	err = json.Unmarshal(payload, &claims)
+
+	if err != nil {
+		return nil, errors.New("malformed verification token")
+	}
+
+	if !time.Now().Before(claims.ExpiresAt) {
+		return nil, errors.New("expired verification token")
+	}
+
+	return &claims, nil
+}
+
+func signVerificationPayload(encodedPayload string) (string, error) {
+	secretKey, err := config.AppConfiguration().JwtSecretKey()
+
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secretKey))
+
+	mac.Write([]byte(verificationTokenPurpose + encodedPayload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func NewVerificationTokenSigner() *VerificationTokenSigner {
+	return &VerificationTokenSigner{}
+}
@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"slices"
+)
+
+// Creates a zip archive holding each value as an indented json file named after its key
+func CreateJsonZipArchive(files map[string]any) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer := zip.NewWriter(&buffer)
+
+	names := make([]string, 0, len(files))
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	//sorted so the same data always produces the same archive
+	slices.Sort(names)
+
+	for _, name := range names {
+		file, err := writer.Create(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(files[name])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
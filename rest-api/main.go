@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"example.com/config"
 )
@@ -14,6 +15,11 @@ func main() {
 		panic(fmt.Sprintf("Unable to load configuration, error: %v\n", err.Error()))
 	}
 
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:])
+		return
+	}
+
 	app, err := BuildServer()
 
 	if err != nil {
@@ -34,3 +40,18 @@ func main() {
 		panic(fmt.Sprintf("Unable to run server, error: %v\n", err.Error()))
 	}
 }
+
+func runCommand(args []string) {
+	commands, err := BuildCommands()
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to build commands, error: %v\n", err.Error()))
+	}
+
+	err = commands.Run(args)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+
+	interfaces "example.com/interfaces/services"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Set by the middlewares refusing a request, so the audit log tells why it was refused
+const auditReasonKey = "auditReason"
+
+type AuditTrail struct {
+	auditLogService interfaces.IAuditLogService
+}
+
+// Records the outcome of a privileged endpoint once it responded, including the requests refused by
+// the middlewares running after it. Has to run after the authentication middleware
+func (auditTrail AuditTrail) RecordPrivilegedAction(action string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Next()
+
+		status := context.Writer.Status()
+
+		outcome := models.SUCCESS_AUDIT_OUTCOME
+		reason := context.GetString(auditReasonKey)
+
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			outcome = models.DENIED_AUDIT_OUTCOME
+		} else if status >= http.StatusBadRequest {
+			outcome = models.FAILURE_AUDIT_OUTCOME
+		}
+
+		if outcome != models.SUCCESS_AUDIT_OUTCOME && reason == "" {
+			reason = http.StatusText(status)
+		}
+
+		recordAuditEntry(auditTrail.auditLogService, context, action, outcome, reason)
+	}
+}
+
+// Failing to record the entry does not fail the request, the service reports it
+func recordAuditEntry(auditLogService interfaces.IAuditLogService, context *gin.Context, action, outcome, reason string) {
+	entry := models.NewAuditLogEntry(
+		action,
+		outcome,
+		models.NewSessionClient(context.ClientIP(), context.Request.UserAgent()),
+		context.Request.Method+" "+context.Request.URL.Path)
+
+	entry.ActorId = context.GetInt64("userId")
+	entry.Reason = reason
+
+	auditLogService.Record(entry)
+}
+
+func NewAuditTrail(auditLogService interfaces.IAuditLogService) *AuditTrail {
+	return &AuditTrail{
+		auditLogService: auditLogService,
+	}
+}
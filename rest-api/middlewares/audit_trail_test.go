@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTrailUnitTestSuite struct {
+	suite.Suite
+	router              *gin.Engine
+	auditLogServiceMock mocks.IAuditLogService
+	mockResponseWriter  *httptest.ResponseRecorder
+	auditTrail          *AuditTrail
+}
+
+func TestAuditTrailUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AuditTrailUnitTestSuite{})
+}
+
+func (suite *AuditTrailUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.auditTrail = NewAuditTrail(&suite.auditLogServiceMock)
+
+	suite.router = gin.New()
+}
+
+// Runs a request through the audit trail, authenticated as the given permissions
+func (suite *AuditTrailUnitTestSuite) serve(permissions []string, handler gin.HandlerFunc) models.AuditLogEntry {
+
+	authenticate := func(context *gin.Context) {
+		context.Set("userId", int64(12))
+		context.Set("tokenClaims", models.AccessTokenClaims{UserId: 12, Permissions: permissions})
+	}
+
+	suite.router.PATCH("/admin/users/:userId",
+		authenticate,
+		suite.auditTrail.RecordPrivilegedAction(models.USER_DISABLE_AUDIT_ACTION),
+		RequirePermission(models.MANAGE_USERS_PERMISSION),
+		handler)
+
+	request := httptest.NewRequest(http.MethodPatch, "http://www.test.com/admin/users/4", nil)
+	request.Header.Set("User-Agent", "curl")
+
+	suite.router.ServeHTTP(suite.mockResponseWriter, request)
+
+	suite.auditLogServiceMock.AssertNumberOfCalls(suite.T(), "Record", 1)
+
+	return suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+}
+
+func (suite *AuditTrailUnitTestSuite) TestRecordPrivilegedAction_RecordsTheSuccess() {
+
+	entry := suite.serve(models.PermissionsForRole(models.ADMIN_ROLE), func(context *gin.Context) {
+		context.Status(http.StatusNoContent)
+	})
+
+	suite.Equal(http.StatusNoContent, suite.mockResponseWriter.Code)
+	suite.Equal(models.USER_DISABLE_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.SUCCESS_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(int64(12), entry.ActorId)
+	suite.Equal("PATCH /admin/users/4", entry.Resource)
+	suite.Equal("curl", entry.UserAgent)
+	suite.Empty(entry.Reason)
+}
+
+// Requests refused by the permission check are recorded along with the missing permission
+func (suite *AuditTrailUnitTestSuite) TestRecordPrivilegedActionWithoutPermission_RecordsTheDenial() {
+
+	entry := suite.serve([]string{models.READ_USERS_PERMISSION}, func(context *gin.Context) {
+		suite.Fail("the handler should not run")
+	})
+
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+	suite.Equal(models.DENIED_AUDIT_OUTCOME, entry.Outcome)
+	suite.Contains(entry.Reason, models.MANAGE_USERS_PERMISSION)
+}
+
+func (suite *AuditTrailUnitTestSuite) TestRecordPrivilegedActionFailing_RecordsTheFailure() {
+
+	entry := suite.serve(models.PermissionsForRole(models.ADMIN_ROLE), func(context *gin.Context) {
+		context.JSON(http.StatusNotFound, gin.H{"message": "User not found"})
+	})
+
+	suite.Equal(models.FAILURE_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal(http.StatusText(http.StatusNotFound), entry.Reason)
+}
@@ -1,30 +1,116 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
 
+	interfaces "example.com/interfaces/services"
 	"example.com/lib"
+	"example.com/models"
 	"github.com/gin-gonic/gin"
 )
 
-func Authenticate(context *gin.Context) {
-	authToken := context.Request.Header.Get("Authorization")
+const authenticationRealm = "events"
 
-	if authToken == "" {
-		context.AbortWithStatus(http.StatusUnauthorized)
+type Authenticator struct {
+	tokenService               interfaces.ITokenService
+	personalAccessTokenService interfaces.IPersonalAccessTokenService
+	auditLogService            interfaces.IAuditLogService
+}
+
+// Requires a valid access token, aborting the request otherwise
+func (authenticator Authenticator) Authenticate(context *gin.Context) {
+	authenticator.authenticate(context, false)
+}
+
+// Authenticates the user when credentials are provided, letting anonymous requests through so
+// public endpoints can still personalize their responses. Invalid credentials are refused
+// rather than ignored, so clients notice an expired token
+func (authenticator Authenticator) OptionalAuthenticate(context *gin.Context) {
+	authenticator.authenticate(context, true)
+}
+
+func (authenticator Authenticator) authenticate(context *gin.Context, optional bool) {
+	authorization := context.GetHeader("Authorization")
+
+	if authorization == "" {
+		if optional {
+			context.Next()
+			return
+		}
+
+		authenticator.recordDeniedAccess(context, "Missing access token")
+
+		context.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%v"`, authenticationRealm))
+		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Missing access token",
+		})
 		return
 	}
 
-	jwtAuthorizer := lib.NewJwtAuthorizer()
+	authToken, ok := lib.ExtractBearerToken(authorization)
 
-	userId, err := jwtAuthorizer.ValidateToken(authToken)
+	if !ok {
+		authenticator.recordDeniedAccess(context, "Malformed authorization header")
+		abortWithAuthenticationError(context, http.StatusBadRequest, "invalid_request", "Malformed authorization header, expected a Bearer token")
+		return
+	}
+
+	claims, err := authenticator.validateToken(authToken)
 
 	if err != nil {
-		context.AbortWithStatus(http.StatusUnauthorized)
+		authenticator.recordDeniedAccess(context, "Invalid, expired or revoked access token")
+		abortWithAuthenticationError(context, http.StatusUnauthorized, "invalid_token", "The access token is invalid, expired or revoked")
 		return
 	}
 
-	context.Set("userId", userId)
+	if !claims.HasScope(context.GetString(acceptedScopeKey)) {
+		//the token is valid, so the denied access can be attributed to its owner
+		context.Set("userId", claims.UserId)
+		authenticator.recordDeniedAccess(context, fmt.Sprintf("Personal access token without the %v scope", context.GetString(acceptedScopeKey)))
+		abortWithAuthenticationError(context, http.StatusForbidden, "insufficient_scope", "The personal access token is not allowed to use this endpoint")
+		return
+	}
+
+	context.Set("userId", claims.UserId)
+	context.Set("organizationId", claims.OrganizationId)
+	context.Set("tokenClaims", *claims)
 
 	context.Next()
 }
+
+func (authenticator Authenticator) validateToken(token string) (*models.AccessTokenClaims, error) {
+	if lib.IsPersonalAccessToken(token) {
+		return authenticator.personalAccessTokenService.ValidatePersonalAccessToken(token)
+	}
+
+	return authenticator.tokenService.ValidateAccessToken(token)
+}
+
+func (authenticator Authenticator) recordDeniedAccess(context *gin.Context, reason string) {
+	recordAuditEntry(authenticator.auditLogService, context, models.ACCESS_DENIED_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, reason)
+}
+
+// Reports the error in the WWW-Authenticate header as described by RFC 6750, along with the body
+func abortWithAuthenticationError(context *gin.Context, status int, errorCode, description string) {
+	context.Header("WWW-Authenticate", fmt.Sprintf(
+		`Bearer realm="%v", error="%v", error_description="%v"`,
+		authenticationRealm,
+		errorCode,
+		description))
+
+	context.AbortWithStatusJSON(status, gin.H{
+		"error": description,
+	})
+}
+
+func NewAuthenticator(
+	tokenService interfaces.ITokenService,
+	personalAccessTokenService interfaces.IPersonalAccessTokenService,
+	auditLogService interfaces.IAuditLogService) *Authenticator {
+	return &Authenticator{
+		tokenService:               tokenService,
+		personalAccessTokenService: personalAccessTokenService,
+		auditLogService:            auditLogService,
+	}
+}
@@ -0,0 +1,252 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthenticatorUnitTestSuite struct {
+	suite.Suite
+	mockContext         *gin.Context
+	tokenServiceMock    mocks.ITokenService
+	patServiceMock      mocks.IPersonalAccessTokenService
+	auditLogServiceMock mocks.IAuditLogService
+	mockResponseWriter  *httptest.ResponseRecorder
+	authenticator       *Authenticator
+}
+
+func TestAuthenticatorUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AuthenticatorUnitTestSuite{})
+}
+
+func (suite *AuthenticatorUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/events", nil)
+
+	suite.tokenServiceMock = mocks.ITokenService{}
+
+	suite.patServiceMock = mocks.IPersonalAccessTokenService{}
+
+	suite.auditLogServiceMock = mocks.IAuditLogService{}
+
+	suite.auditLogServiceMock.On("Record", mock.Anything).Return(nil)
+
+	suite.authenticator = NewAuthenticator(&suite.tokenServiceMock, &suite.patServiceMock, &suite.auditLogServiceMock)
+}
+
+// When no token is provided, return unauthorized along with the authentication scheme
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithoutToken_ReturnsUnauthorized() {
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.Equal(`Bearer realm="events"`, suite.mockResponseWriter.Header().Get("WWW-Authenticate"))
+}
+
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithBearerToken_SetsTheUser() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&claims, nil)
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "ValidateAccessToken", "some-token")
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(12), suite.mockContext.GetInt64("userId"))
+	suite.Equal(claims, suite.mockContext.MustGet("tokenClaims"))
+}
+
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithOrganizationToken_SetsTheOrganization() {
+
+	claims := models.AccessTokenClaims{UserId: 12, TokenId: "token id", OrganizationId: 4}
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&claims, nil)
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(4), suite.mockContext.GetInt64("organizationId"))
+}
+
+// Clients sending the token without a scheme keep working
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithRawToken_SetsTheUser() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.tokenServiceMock.AssertCalled(suite.T(), "ValidateAccessToken", "some-token")
+	suite.Equal(int64(12), suite.mockContext.GetInt64("userId"))
+}
+
+// When the header uses another scheme, return a bad request
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithAnotherScheme_ReturnsBadRequest() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusBadRequest, suite.mockResponseWriter.Code)
+	suite.Contains(suite.mockResponseWriter.Header().Get("WWW-Authenticate"), `error="invalid_request"`)
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "ValidateAccessToken", mock.Anything)
+}
+
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithInvalidToken_ReturnsUnauthorized() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+	suite.Contains(suite.mockResponseWriter.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+}
+
+// Refused tokens are recorded in the audit log
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithInvalidToken_RecordsTheDenial() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.ACCESS_DENIED_AUDIT_ACTION, entry.Action)
+	suite.Equal(models.DENIED_AUDIT_OUTCOME, entry.Outcome)
+	suite.Equal("GET /events", entry.Resource)
+	suite.Equal(int64(0), entry.ActorId)
+}
+
+// When no token is provided, the request continues anonymously
+func (suite *AuthenticatorUnitTestSuite) TestOptionalAuthenticateWithoutToken_ContinuesAnonymously() {
+
+	suite.authenticator.OptionalAuthenticate(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(0), suite.mockContext.GetInt64("userId"))
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "ValidateAccessToken", mock.Anything)
+	suite.auditLogServiceMock.AssertNotCalled(suite.T(), "Record", mock.Anything)
+}
+
+func (suite *AuthenticatorUnitTestSuite) TestOptionalAuthenticateWithToken_SetsTheUser() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+
+	suite.authenticator.OptionalAuthenticate(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(12), suite.mockContext.GetInt64("userId"))
+}
+
+// An expired token is refused rather than silently ignored
+func (suite *AuthenticatorUnitTestSuite) TestOptionalAuthenticateWithInvalidToken_ReturnsUnauthorized() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	suite.authenticator.OptionalAuthenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+// Tokens with the personal access token prefix are validated as such
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithPersonalAccessToken_SetsTheUser() {
+
+	claims := models.AccessTokenClaims{UserId: 12, Scopes: []string{models.EVENTS_READ_SCOPE}}
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer pat_some-token")
+	suite.patServiceMock.On("ValidatePersonalAccessToken", mock.Anything).Return(&claims, nil)
+
+	AcceptScope(models.EVENTS_READ_SCOPE)(suite.mockContext)
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.patServiceMock.AssertCalled(suite.T(), "ValidatePersonalAccessToken", "pat_some-token")
+	suite.tokenServiceMock.AssertNotCalled(suite.T(), "ValidateAccessToken", mock.Anything)
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(12), suite.mockContext.GetInt64("userId"))
+	suite.Equal(claims, suite.mockContext.MustGet("tokenClaims"))
+}
+
+// When the endpoint does not accept any scope of the token, return forbidden
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithPersonalAccessTokenMissingScope_ReturnsForbidden() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer pat_some-token")
+	suite.patServiceMock.On("ValidatePersonalAccessToken", mock.Anything).Return(&models.AccessTokenClaims{
+		UserId: 12,
+		Scopes: []string{models.EVENTS_READ_SCOPE},
+	}, nil)
+
+	AcceptScope(models.EVENTS_WRITE_SCOPE)(suite.mockContext)
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+	suite.Contains(suite.mockResponseWriter.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+
+	entry := suite.auditLogServiceMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(int64(12), entry.ActorId)
+	suite.Contains(entry.Reason, models.EVENTS_WRITE_SCOPE)
+}
+
+// Endpoints accepting no scope are reserved to the access tokens issued at login
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithPersonalAccessTokenWithoutAcceptedScope_ReturnsForbidden() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer pat_some-token")
+	suite.patServiceMock.On("ValidatePersonalAccessToken", mock.Anything).Return(&models.AccessTokenClaims{
+		UserId: 12,
+		Scopes: []string{models.EVENTS_READ_SCOPE, models.EVENTS_WRITE_SCOPE},
+	}, nil)
+
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithInvalidPersonalAccessToken_ReturnsUnauthorized() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer pat_some-token")
+	suite.patServiceMock.On("ValidatePersonalAccessToken", mock.Anything).Return(nil, errors.New("test"))
+
+	AcceptScope(models.EVENTS_READ_SCOPE)(suite.mockContext)
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+// Access tokens issued at login can use every endpoint, whatever scope it accepts
+func (suite *AuthenticatorUnitTestSuite) TestAuthenticateWithAccessTokenOnScopedEndpoint_SetsTheUser() {
+
+	suite.mockContext.Request.Header.Set("Authorization", "Bearer some-token")
+	suite.tokenServiceMock.On("ValidateAccessToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12}, nil)
+
+	AcceptScope(models.EVENTS_WRITE_SCOPE)(suite.mockContext)
+	suite.authenticator.Authenticate(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+	suite.Equal(int64(12), suite.mockContext.GetInt64("userId"))
+}
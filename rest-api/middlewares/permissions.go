@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Requires every one of the permissions to be granted by the access token, has to run after
+// the authentication middleware
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		value, authenticated := context.Get("tokenClaims")
+
+		claims, _ := value.(models.AccessTokenClaims)
+
+		if !authenticated {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Missing access token",
+			})
+			return
+		}
+
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				message := fmt.Sprintf("Missing the %v permission", permission)
+
+				context.Set(auditReasonKey, message)
+				context.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": message,
+				})
+				return
+			}
+		}
+
+		context.Next()
+	}
+}
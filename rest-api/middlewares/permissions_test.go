@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequirePermissionUnitTestSuite struct {
+	suite.Suite
+	mockContext        *gin.Context
+	mockResponseWriter *httptest.ResponseRecorder
+}
+
+func TestRequirePermissionUnitTestSuite(t *testing.T) {
+	suite.Run(t, &RequirePermissionUnitTestSuite{})
+}
+
+func (suite *RequirePermissionUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodGet, "http://www.test.com/admin/users", nil)
+}
+
+// When the request was not authenticated, return unauthorized
+func (suite *RequirePermissionUnitTestSuite) TestRequirePermissionWithoutClaims_ReturnsUnauthorized() {
+
+	RequirePermission(models.READ_USERS_PERMISSION)(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusUnauthorized, suite.mockResponseWriter.Code)
+}
+
+// When one of the permissions is not granted, return forbidden
+func (suite *RequirePermissionUnitTestSuite) TestRequirePermissionMissingPermission_ReturnsForbidden() {
+
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{
+		UserId:      12,
+		Permissions: []string{models.READ_USERS_PERMISSION},
+	})
+
+	RequirePermission(models.READ_USERS_PERMISSION, models.MANAGE_USERS_PERMISSION)(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+func (suite *RequirePermissionUnitTestSuite) TestRequirePermissionGranted_LetsTheRequestThrough() {
+
+	suite.mockContext.Set("tokenClaims", models.AccessTokenClaims{
+		UserId:      12,
+		Permissions: models.PermissionsForRole(models.ADMIN_ROLE),
+	})
+
+	RequirePermission(models.READ_USERS_PERMISSION, models.MANAGE_USERS_PERMISSION)(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameters carrying credentials, browsers opening a websocket cannot send them any other way
+var redactedQueryParameters = []string{"access_token"}
+
+// Logs requests the way gin's default logger does, with the values of credentials sent in the
+// query string replaced so they do not end up in the logs
+func RequestLogger(output io.Writer) gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Output: output,
+		Formatter: func(param gin.LogFormatterParams) string {
+			var statusColor, methodColor, resetColor string
+
+			if param.IsOutputColor() {
+				statusColor = param.StatusCodeColor()
+				methodColor = param.MethodColor()
+				resetColor = param.ResetColor()
+			}
+
+			if param.Latency > time.Minute {
+				param.Latency = param.Latency.Truncate(time.Second)
+			}
+
+			return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+				param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+				statusColor, param.StatusCode, resetColor,
+				param.Latency,
+				param.ClientIP,
+				methodColor, param.Method, resetColor,
+				redactQuery(param.Path),
+				param.ErrorMessage,
+			)
+		},
+	})
+}
+
+func redactQuery(path string) string {
+	path, rawQuery, found := strings.Cut(path, "?")
+
+	if !found {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+
+	//a query that cannot be parsed cannot be redacted either, so none of it is logged
+	if err != nil {
+		return path + "?REDACTED"
+	}
+
+	redacted := false
+
+	for _, parameter := range redactedQueryParameters {
+		if query.Has(parameter) {
+			query.Set(parameter, "REDACTED")
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return path + "?" + rawQuery
+	}
+
+	return path + "?" + query.Encode()
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLoggerUnitTestSuite struct {
+	suite.Suite
+	output *bytes.Buffer
+	server *gin.Engine
+}
+
+func TestRequestLoggerUnitTestSuite(t *testing.T) {
+	suite.Run(t, &RequestLoggerUnitTestSuite{})
+}
+
+func (suite *RequestLoggerUnitTestSuite) SetupTest() {
+
+	suite.output = &bytes.Buffer{}
+
+	suite.server = gin.New()
+	suite.server.Use(RequestLogger(suite.output))
+	suite.server.GET("/events/:id/channel", func(context *gin.Context) {
+		context.Status(http.StatusOK)
+	})
+}
+
+// The token used to open the event channel websocket does not end up in the log
+func (suite *RequestLoggerUnitTestSuite) TestRequestLoggerQueryToken_RedactsTheToken() {
+
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/channel?access_token=secret&since=4", nil)
+
+	suite.server.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.NotContains(suite.output.String(), "secret")
+	suite.Contains(suite.output.String(), "/events/1/channel?access_token=REDACTED&since=4")
+}
+
+func (suite *RequestLoggerUnitTestSuite) TestRequestLoggerWithoutCredentials_LogsTheQuery() {
+
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/channel?since=4", nil)
+
+	suite.server.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.Contains(suite.output.String(), "/events/1/channel?since=4")
+}
+
+func (suite *RequestLoggerUnitTestSuite) TestRequestLoggerUnparsableQuery_LeavesOutTheQuery() {
+
+	request := httptest.NewRequest(http.MethodGet, "http://www.test.com/events/1/channel?access_token=secret%zz", nil)
+
+	suite.server.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.NotContains(suite.output.String(), "secret")
+	suite.Contains(suite.output.String(), "/events/1/channel?REDACTED")
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import "github.com/gin-gonic/gin"
+
+const acceptedScopeKey = "acceptedScope"
+
+// Lets personal access tokens holding the scope use the endpoint, has to run before the
+// authentication middleware. Personal access tokens are refused by endpoints accepting no scope
+func AcceptScope(scope string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Set(acceptedScopeKey, scope)
+
+		context.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"example.com/config"
+	interfaces "example.com/interfaces/services"
+	"github.com/gin-gonic/gin"
+)
+
+type VerifiedEmailGuard struct {
+	emailVerificationService interfaces.IEmailVerificationService
+	requireVerification      bool
+}
+
+// Refuses requests from users who did not verify their email yet, unless verification was turned
+// off with REQUIRE_EMAIL_VERIFICATION. Has to run after the authentication middleware
+func (guard VerifiedEmailGuard) RequireVerifiedEmail(context *gin.Context) {
+	if !guard.requireVerification {
+		context.Next()
+		return
+	}
+
+	verified, err := guard.emailVerificationService.IsEmailVerified(context.GetInt64("userId"))
+
+	if err != nil {
+		context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Unexpected error occurred",
+		})
+		return
+	}
+
+	if !verified {
+		context.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "The email address has to be verified first",
+		})
+		return
+	}
+
+	context.Next()
+}
+
+func NewVerifiedEmailGuard(emailVerificationService interfaces.IEmailVerificationService) *VerifiedEmailGuard {
+	return &VerifiedEmailGuard{
+		emailVerificationService: emailVerificationService,
+		requireVerification:      config.AppConfiguration().RequireEmailVerification(),
+	}
+}
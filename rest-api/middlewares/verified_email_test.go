@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifiedEmailGuardUnitTestSuite struct {
+	suite.Suite
+	mockContext                  *gin.Context
+	emailVerificationServiceMock mocks.IEmailVerificationService
+	mockResponseWriter           *httptest.ResponseRecorder
+	guard                        *VerifiedEmailGuard
+}
+
+func TestVerifiedEmailGuardUnitTestSuite(t *testing.T) {
+	suite.Run(t, &VerifiedEmailGuardUnitTestSuite{})
+}
+
+func (suite *VerifiedEmailGuardUnitTestSuite) SetupTest() {
+
+	suite.mockResponseWriter = httptest.NewRecorder()
+
+	suite.mockContext, _ = gin.CreateTestContext(suite.mockResponseWriter)
+
+	suite.mockContext.Request = httptest.NewRequest(http.MethodPost, "http://www.test.com/events", nil)
+
+	suite.mockContext.Set("userId", int64(12))
+
+	suite.emailVerificationServiceMock = mocks.IEmailVerificationService{}
+
+	suite.guard = &VerifiedEmailGuard{
+		emailVerificationService: &suite.emailVerificationServiceMock,
+		requireVerification:      true,
+	}
+}
+
+// When verification is turned off, every user is let through
+func (suite *VerifiedEmailGuardUnitTestSuite) TestRequireVerifiedEmailNotRequired_LetsTheRequestThrough() {
+
+	suite.guard.requireVerification = false
+
+	suite.guard.RequireVerifiedEmail(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+	suite.emailVerificationServiceMock.AssertNotCalled(suite.T(), "IsEmailVerified", mock.Anything)
+}
+
+// When the user did not verify their email, return forbidden
+func (suite *VerifiedEmailGuardUnitTestSuite) TestRequireVerifiedEmailUnverified_ReturnsForbidden() {
+
+	suite.emailVerificationServiceMock.On("IsEmailVerified", mock.Anything).Return(false, nil)
+
+	suite.guard.RequireVerifiedEmail(suite.mockContext)
+
+	suite.emailVerificationServiceMock.AssertCalled(suite.T(), "IsEmailVerified", int64(12))
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusForbidden, suite.mockResponseWriter.Code)
+}
+
+// When failing to check the user, return internal server error
+func (suite *VerifiedEmailGuardUnitTestSuite) TestRequireVerifiedEmail_ReturnsInternalServerError() {
+
+	suite.emailVerificationServiceMock.On("IsEmailVerified", mock.Anything).Return(false, errors.New("test"))
+
+	suite.guard.RequireVerifiedEmail(suite.mockContext)
+
+	suite.True(suite.mockContext.IsAborted())
+	suite.Equal(http.StatusInternalServerError, suite.mockResponseWriter.Code)
+}
+
+func (suite *VerifiedEmailGuardUnitTestSuite) TestRequireVerifiedEmailVerified_LetsTheRequestThrough() {
+
+	suite.emailVerificationServiceMock.On("IsEmailVerified", mock.Anything).Return(true, nil)
+
+	suite.guard.RequireVerifiedEmail(suite.mockContext)
+
+	suite.False(suite.mockContext.IsAborted())
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+const (
+	LOGIN_AUDIT_ACTION                        = "login"
+	MFA_LOGIN_AUDIT_ACTION                    = "login.mfa"
+	OIDC_LOGIN_AUDIT_ACTION                   = "login.oidc"
+	SIGNUP_AUDIT_ACTION                       = "signup"
+	TOKEN_REFRESH_AUDIT_ACTION                = "token.refresh"
+	LOGOUT_AUDIT_ACTION                       = "logout"
+	LOGOUT_EVERYWHERE_AUDIT_ACTION            = "logout.everywhere"
+	PASSWORD_CHANGE_AUDIT_ACTION              = "password.change"
+	PASSWORD_RESET_AUDIT_ACTION               = "password.reset"
+	EMAIL_CHANGE_AUDIT_ACTION                 = "email.change_requested"
+	ACCOUNT_DELETION_AUDIT_ACTION             = "account.delete"
+	TWO_FACTOR_ENABLE_AUDIT_ACTION            = "mfa.enable"
+	TWO_FACTOR_DISABLE_AUDIT_ACTION           = "mfa.disable"
+	SESSION_REVOKE_AUDIT_ACTION               = "session.revoke"
+	PERSONAL_ACCESS_TOKEN_CREATE_AUDIT_ACTION = "personal_access_token.create"
+	PERSONAL_ACCESS_TOKEN_REVOKE_AUDIT_ACTION = "personal_access_token.revoke"
+	ORGANIZATION_ROLE_CHANGE_AUDIT_ACTION     = "organization.member.role_change"
+	// Requests refused by the authentication or permission middlewares
+	ACCESS_DENIED_AUDIT_ACTION    = "access.denied"
+	USER_DISABLE_AUDIT_ACTION     = "admin.user.disable"
+	USER_ENABLE_AUDIT_ACTION      = "admin.user.enable"
+	USER_UNLOCK_AUDIT_ACTION      = "admin.user.unlock"
+	EVENT_UPDATE_AUDIT_ACTION     = "admin.event.update"
+	EVENT_DELETE_AUDIT_ACTION     = "admin.event.delete"
+	USERS_READ_AUDIT_ACTION       = "admin.users.read"
+	AUDIT_LOG_READ_AUDIT_ACTION   = "admin.audit_log.read"
+	AUDIT_LOG_EXPORT_AUDIT_ACTION = "admin.audit_log.export"
+)
+
+const (
+	SUCCESS_AUDIT_OUTCOME = "success"
+	// The request was made but could not be completed, e.g. wrong credentials
+	FAILURE_AUDIT_OUTCOME = "failure"
+	// The caller was not allowed to make the request
+	DENIED_AUDIT_OUTCOME = "denied"
+)
+
+// Entry of the append-only security audit log. The actor is 0 for anonymous requests, the email is
+// kept for failed logins where no account could be matched
+type AuditLogEntry struct {
+	Id         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Action     string    `json:"action"`
+	Outcome    string    `json:"outcome"`
+	ActorId    int64     `json:"actor_id,omitempty"`
+	ActorEmail string    `json:"actor_email,omitempty"`
+	IpAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Resource   string    `json:"resource"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+func NewAuditLogEntry(action, outcome string, client SessionClient, resource string) AuditLogEntry {
+	return AuditLogEntry{
+		Action:    action,
+		Outcome:   outcome,
+		IpAddress: client.IpAddress,
+		UserAgent: client.UserAgent,
+		Resource:  resource,
+	}
+}
+
+// Criteria of the admin queries, zero values match every entry. Entries are returned oldest first
+// after AfterId, so a SIEM can resume from the last entry it ingested
+type AuditLogFilter struct {
+	Action  string
+	Outcome string
+	ActorId int64
+	From    *time.Time
+	To      *time.Time
+	AfterId int64
+	Limit   int
+}
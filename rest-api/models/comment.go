@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type Comment struct {
+	Id      int64 `json:"id"`
+	EventId int64 `json:"event_id"`
+	// 0 once the author deleted their account
+	UserId   int64  `json:"user_id,omitempty"`
+	ParentId *int64 `json:"parent_id,omitempty"`
+	// Top level comment of the thread, nil for top level comments themselves
+	RootId    *int64     `json:"-"`
+	Body      string     `json:"body"`
+	Pinned    bool       `json:"pinned"`
+	Deleted   bool       `json:"deleted"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	DeletedAt *time.Time `json:"-"`
+	Replies   []*Comment `json:"replies"`
+}
+
+type CommentRequest struct {
+	Body     string `json:"body" binding:"required,max=5000"`
+	ParentId *int64 `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+type CommentPage struct {
+	Comments []*Comment `json:"comments"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"page_size"`
+	// Number of top level comments, replies are always returned along with their thread
+	Total int64 `json:"total"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+const (
+	DATA_EXPORT_PENDING = "pending"
+	DATA_EXPORT_READY   = "ready"
+	DATA_EXPORT_FAILED  = "failed"
+)
+
+// Archive of the data of a user generated in the background, kept until it expires
+type DataExport struct {
+	Id          int64      `json:"id"`
+	UserId      int64      `json:"-"`
+	Status      string     `json:"status"`
+	Archive     []byte     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+}
+
+func (export DataExport) IsDownloadable(now time.Time) bool {
+	return export.Status == DATA_EXPORT_READY && now.Before(export.ExpiresAt)
+}
+
+// Either the archive, when it was small enough to be generated right away, or the pending export
+type DataExportResult struct {
+	Archive []byte
+	Export  *DataExport
+}
+
+type ExportedProfile struct {
+	Id                      int64                   `json:"id"`
+	Email                   string                  `json:"email"`
+	Role                    string                  `json:"role"`
+	VerifiedAt              *time.Time              `json:"verified_at"`
+	DisabledAt              *time.Time              `json:"disabled_at"`
+	TwoFactorEnabled        bool                    `json:"two_factor_enabled"`
+	DisplayName             string                  `json:"display_name"`
+	AvatarUrl               string                  `json:"avatar_url"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	UpdatedAt               *time.Time              `json:"updated_at"`
+}
+
+type ExportedEvent struct {
+	Id int64 `json:"id"`
+	Event
+}
+
+type ExportedComment struct {
+	Id        int64      `json:"id"`
+	EventId   int64      `json:"event_id"`
+	ParentId  *int64     `json:"parent_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// Login session, made of the refresh tokens of a family
+type ExportedSession struct {
+	Session         string     `json:"session"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastRefreshedAt time.Time  `json:"last_refreshed_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+}
+
+type ExportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Security related records kept about the account
+type ExportedAuditRecords struct {
+	Sessions             []ExportedSession     `json:"sessions"`
+	PersonalAccessTokens []PersonalAccessToken `json:"personal_access_tokens"`
+	LinkedIdentities     []ExportedIdentity    `json:"linked_identities"`
+	DataExports          []DataExport          `json:"data_exports"`
+	AuditLog             []AuditLogEntry       `json:"audit_log"`
+}
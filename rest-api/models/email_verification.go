@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Content of the signed token sent in verification links. The email is part of it, so a link
+// stops working once the user changes their email
+type EmailVerificationClaims struct {
+	UserId int64  `json:"user_id"`
+	Email  string `json:"email"`
+	// Set when the link confirms a change of email, the link only works while the user still
+	// has this email
+	PreviousEmail string    `json:"previous_email,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
@@ -5,10 +5,46 @@ import (
 )
 
 type Event struct {
-	Id          int64     `json:"-"`
-	Name        string    `json:"name" binding:"required"`
-	Description string    `json:"description" binding:"required"`
-	Location    string    `json:"location" binding:"required"`
-	Date        time.Time `json:"date" binding:"required"`
-	UserId      int64     `json:"-"`
+	Id          int64      `json:"-"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	Location    string     `json:"location" binding:"required"`
+	Date        time.Time  `json:"date" binding:"required"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	UserId      int64      `json:"-"`
+	Latitude    *float64   `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude   *float64   `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	Visibility  string     `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	// Set from the organization the token acts in, 0 for events of the personal space
+	OrganizationId int64 `json:"organization_id,omitempty"`
+}
+
+// Used to detect schedule conflicts for events created without an end date
+const DEFAULT_EVENT_DURATION = time.Hour
+
+func (event Event) EndsAt() time.Time {
+	if event.EndDate != nil {
+		return *event.EndDate
+	}
+
+	return event.Date.Add(DEFAULT_EVENT_DURATION)
+}
+
+// Whether both events take place at the same time, events ending when the other starts do not overlap
+func (event Event) Overlaps(other Event) bool {
+	return event.Date.Before(other.EndsAt()) && other.Date.Before(event.EndsAt())
+}
+
+type EventWithDistance struct {
+	Event
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// Coordinates delimiting a rectangular search area, when MinLongitude is greater than
+// MaxLongitude the area crosses the antimeridian
+type BoundingBox struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
 }
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	CHANNEL_ANNOUNCEMENT_MESSAGE = "announcement"
+	CHANNEL_ATTENDANCE_MESSAGE   = "attendance"
+	CHANNEL_PING_MESSAGE         = "ping"
+	CHANNEL_PONG_MESSAGE         = "pong"
+	CHANNEL_ERROR_MESSAGE        = "error"
+)
+
+// Message exchanged over an event's real-time channel, both from and to clients
+type EventChannelMessage struct {
+	Type              string    `json:"type"`
+	Message           string    `json:"message,omitempty"`
+	ConnectedCount    *int64    `json:"connected_count,omitempty"`
+	RegistrationCount *int64    `json:"registration_count,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+type Announcement struct {
+	Message string `json:"message" binding:"required,max=1000"`
+}
+
+// A connection to an event's channel, Messages is closed once the client has been
+// removed from the channel, either by leaving or by being unable to keep up
+type EventChannelClient struct {
+	EventId   int64
+	UserId    int64
+	Organizer bool
+	Messages  <-chan EventChannelMessage
+}
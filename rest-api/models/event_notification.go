@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	EVENT_CREATED_NOTIFICATION       = "created"
+	EVENT_UPDATED_NOTIFICATION       = "updated"
+	EVENT_DELETED_NOTIFICATION       = "deleted"
+	EVENT_REGISTRATIONS_NOTIFICATION = "registrations"
+)
+
+type EventNotification struct {
+	Id                uint64    `json:"-"`
+	Type              string    `json:"type"`
+	EventId           int64     `json:"event_id"`
+	Event             *Event    `json:"event,omitempty"`
+	RegistrationCount *int64    `json:"registration_count,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+	// Visibility of the event the notification is about, used to keep non public events out of public streams
+	Visibility string `json:"-"`
+	// Organization of the event, events of organizations are kept out of public streams as well
+	OrganizationId int64 `json:"-"`
+}
+
+// A live feed of notifications, Backlog holds the notifications missed since the
+// requested last notification id and should be sent before reading from Notifications
+type EventSubscription struct {
+	EventId       int64
+	Backlog       []EventNotification
+	Notifications <-chan EventNotification
+	// Set when the requested last notification id is no longer in the replay buffer,
+	// meaning the client missed notifications and should re-fetch its state
+	Incomplete bool
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type Invitation struct {
+	Id         int64      `json:"id"`
+	EventId    int64      `json:"event_id"`
+	Email      *string    `json:"email,omitempty"`
+	UserId     *int64     `json:"user_id,omitempty"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Whether the invitation can still be used to view the event or be accepted
+func (invitation Invitation) IsUsable(now time.Time) bool {
+	return invitation.Id != 0 && invitation.RevokedAt == nil && now.Before(invitation.ExpiresAt)
+}
+
+type InvitationRequest struct {
+	// Restricts the invitation to the user with this email, otherwise anyone with the link can accept it
+	Email          *string `json:"email" binding:"omitempty,email"`
+	ExpiresInHours int     `json:"expires_in_hours" binding:"omitempty,min=1,max=8760"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
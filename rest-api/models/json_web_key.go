@@ -0,0 +1,21 @@
+package models
+
+// Public key access tokens can be verified with, as described by RFC 7517
+type JsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyId     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// Curve of EC and OKP keys
+	Curve string `json:"crv,omitempty"`
+	// Modulus and exponent of RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Coordinates of EC keys, OKP keys only have x
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+}
+
+type JsonWebKeySet struct {
+	Keys []JsonWebKey `json:"keys"`
+}
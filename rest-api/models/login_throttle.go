@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Failed login attempts recorded for an account or a client ip, identified by the key
+type LoginThrottle struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	APPLIED_MIGRATION_STATE = "applied"
+	PENDING_MIGRATION_STATE = "pending"
+	// The migration file changed after the migration was applied
+	MODIFIED_MIGRATION_STATE = "modified"
+	// The database was migrated by a build shipping a migration this one does not know
+	UNKNOWN_MIGRATION_STATE = "unknown"
+)
+
+// Numbered schema change, Down reverts what Up did. The checksum of Up detects migrations edited
+// after being applied
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// The applied date is nil for pending migrations
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
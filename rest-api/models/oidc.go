@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Secrets of a login started with an OpenID Connect provider, kept until the provider redirects
+// back. Only the hash of the state handed to the provider is stored
+type OidcLoginState struct {
+	Id           int64
+	Provider     string
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// Whether the provider redirecting back can use the state
+func (state OidcLoginState) IsUsable(provider string, now time.Time) bool {
+	return state.Id != 0 && state.Provider == provider && now.Before(state.ExpiresAt)
+}
+
+// Login started with a provider. The state is also handed to the browser, so the login can only be
+// completed by the browser that started it
+type OidcLoginStart struct {
+	AuthorizationUrl string
+	State            string
+	ExpiresIn        int64
+}
+
+// Authorization request the user is redirected to
+type OidcAuthorizationRequest struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// Identity asserted by the id token of a provider
+type OidcIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// Account of a provider linked to a user, the subject identifies it since emails can change
+type UserIdentity struct {
+	Id        int64
+	UserId    int64
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
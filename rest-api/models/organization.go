@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+const (
+	ORGANIZATION_ADMIN_ROLE  = "admin"
+	ORGANIZATION_MEMBER_ROLE = "member"
+)
+
+// Tenant grouping members and the events they share. Events outside of any organization belong
+// to the personal space, which has an organization id of 0
+type Organization struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Organization of the user along with the role the user holds in it
+type OrganizationMembership struct {
+	OrganizationId int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+func (membership OrganizationMembership) IsAdmin() bool {
+	return membership.Role == ORGANIZATION_ADMIN_ROLE
+}
+
+type OrganizationMember struct {
+	UserId   int64     `json:"user_id"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type OrganizationInvitation struct {
+	Id             int64      `json:"id"`
+	OrganizationId int64      `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	TokenHash      string     `json:"-"`
+	InvitedBy      int64      `json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+}
+
+// Whether the invitation can still be accepted
+func (invitation OrganizationInvitation) IsUsable(now time.Time) bool {
+	return invitation.Id != 0 && invitation.AcceptedAt == nil && now.Before(invitation.ExpiresAt)
+}
+
+type OrganizationInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type OrganizationRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// Organization the following tokens act in, 0 switching back to the personal space
+type SwitchOrganizationRequest struct {
+	OrganizationId *int64 `json:"organization_id" binding:"required,min=0"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Single use token emailed to users who forgot their password, only its hash is stored
+type PasswordResetToken struct {
+	Id        int64
+	UserId    int64
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// Whether the token can still be used to reset the password
+func (token PasswordResetToken) IsUsable(now time.Time) bool {
+	return token.Id != 0 && token.UsedAt == nil && now.Before(token.ExpiresAt)
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
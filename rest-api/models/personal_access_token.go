@@ -0,0 +1,56 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// List and read events
+	EVENTS_READ_SCOPE = "events:read"
+	// Create, edit and delete the events of the user
+	EVENTS_WRITE_SCOPE = "events:write"
+	// Register for events and cancel registrations
+	REGISTRATIONS_WRITE_SCOPE = "registrations:write"
+)
+
+var personalAccessTokenScopes = []string{
+	EVENTS_READ_SCOPE,
+	EVENTS_WRITE_SCOPE,
+	REGISTRATIONS_WRITE_SCOPE,
+}
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(personalAccessTokenScopes, scope)
+}
+
+// Long lived token for scripts, limited to the endpoints its scopes allow. Only its hash is stored
+type PersonalAccessToken struct {
+	Id         int64      `json:"id"`
+	UserId     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// Whether the token can still authenticate requests
+func (token PersonalAccessToken) IsUsable(now time.Time) bool {
+	return token.Id != 0 && token.RevokedAt == nil && now.Before(token.ExpiresAt)
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// Tokens expire after 90 days unless specified
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// Returned once when the token is created, the token cannot be retrieved afterwards
+type CreatedPersonalAccessToken struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,40 @@
+package models
+
+const (
+	// Default role of every user signing up
+	USER_ROLE = "user"
+	// Allowed to manage users and any event
+	ADMIN_ROLE = "admin"
+)
+
+const (
+	// Edit or delete events organized by other users
+	MANAGE_ANY_EVENT_PERMISSION = "events:manage_any"
+	// List every user account
+	READ_USERS_PERMISSION = "users:read"
+	// Disable and enable user accounts
+	MANAGE_USERS_PERMISSION = "users:manage"
+	// Query and export the security audit log
+	READ_AUDIT_LOG_PERMISSION = "audit_log:read"
+)
+
+var rolePermissions = map[string][]string{
+	USER_ROLE: {},
+	ADMIN_ROLE: {
+		MANAGE_ANY_EVENT_PERMISSION,
+		READ_USERS_PERMISSION,
+		MANAGE_USERS_PERMISSION,
+		READ_AUDIT_LOG_PERMISSION,
+	},
+}
+
+func IsValidRole(role string) bool {
+	_, valid := rolePermissions[role]
+
+	return valid
+}
+
+// Permissions granted by the role, unknown roles grant none
+func PermissionsForRole(role string) []string {
+	return append([]string{}, rolePermissions[role]...)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Event along with its id and the end of its time window, as used to report schedule conflicts
+type ScheduledEvent struct {
+	Id int64 `json:"id"`
+	Event
+	EndsAt time.Time `json:"ends_at"`
+}
+
+type ScheduleConflict struct {
+	First  ScheduledEvent `json:"first"`
+	Second ScheduledEvent `json:"second"`
+}
+
+func NewScheduledEvent(event Event) ScheduledEvent {
+	return ScheduledEvent{
+		Id:     event.Id,
+		Event:  event,
+		EndsAt: event.EndsAt(),
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Login of a user on a device, made of the refresh tokens of a family. Its id is the id of the family
+type Session struct {
+	Id         string     `json:"id"`
+	UserId     int64      `json:"-"`
+	IpAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// Organization the tokens of the session act in, 0 for the personal space
+	OrganizationId int64 `json:"organization_id"`
+	// Whether the session is the one of the request listing the sessions
+	Current bool `json:"current"`
+}
+
+func (session Session) IsActive(now time.Time) bool {
+	return session.Id != "" && session.RevokedAt == nil && now.Before(session.ExpiresAt)
+}
+
+// Where a request comes from, recorded on the session it belongs to and in the audit log
+type SessionClient struct {
+	IpAddress string
+	UserAgent string
+}
+
+// User agents are set by the client, so they are truncated before being stored
+const maxUserAgentLength = 512
+
+func NewSessionClient(ipAddress, userAgent string) SessionClient {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return SessionClient{
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Rotating refresh token, only the hash of the token handed out to the client is stored.
+// Every token issued by rotating another one shares its family, so a reused token can
+// revoke every token descending from the same login
+type RefreshToken struct {
+	Id        int64
+	UserId    int64
+	FamilyId  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// Owner of the tokens, recorded in the audit log but never sent to the client
+	UserId int64 `json:"-"`
+}
+
+type AccessTokenClaims struct {
+	UserId      int64
+	Email       string
+	Role        string
+	Permissions []string
+	TokenId     string
+	// Login session the token was issued for, empty for personal access tokens and tokens issued
+	// before sessions were tracked
+	SessionId string
+	// Organization the token acts in, 0 for the personal space. The role is the one the user holds
+	// in the organization when the token is validated
+	OrganizationId   int64
+	OrganizationRole string
+	ExpiresAt        time.Time
+	// Set for personal access tokens only, which are limited to the endpoints accepting one of
+	// their scopes. Tokens issued at login have access to every endpoint
+	Scopes []string
+}
+
+func (claims AccessTokenClaims) HasPermission(permission string) bool {
+	for _, granted := range claims.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (claims AccessTokenClaims) IsPersonalAccessToken() bool {
+	return claims.Scopes != nil
+}
+
+func (claims AccessTokenClaims) HasScope(scope string) bool {
+	return !claims.IsPersonalAccessToken() || slices.Contains(claims.Scopes, scope)
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// TOTP secret of a user, two factor authentication is only enabled once the enrollment is confirmed
+// with a valid code. The last used time step is kept so a code cannot be replayed
+type TotpCredential struct {
+	UserId int64
+	// Encrypted with the TOTP encryption key, secrets stored before encryption was introduced
+	// are read as they are until the user enrolls again
+	Secret       string
+	LastUsedStep int64
+	CreatedAt    time.Time
+	ConfirmedAt  *time.Time
+}
+
+func (credential TotpCredential) IsConfirmed() bool {
+	return credential.UserId != 0 && credential.ConfirmedAt != nil
+}
+
+// Single use code letting users log in without their authenticator, only its hash is stored
+type RecoveryCode struct {
+	Id       int64
+	UserId   int64
+	CodeHash string
+	UsedAt   *time.Time
+}
+
+// Handed out after a valid password when two factor authentication is enabled, it has to be
+// exchanged along with a valid code for the access token. Only its hash is stored
+type MfaChallenge struct {
+	Id             int64
+	UserId         int64
+	TokenHash      string
+	FailedAttempts int
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+	UsedAt         *time.Time
+}
+
+// Whether the challenge can still be exchanged for tokens
+func (challenge MfaChallenge) IsUsable(now time.Time, maxFailedAttempts int) bool {
+	return challenge.Id != 0 &&
+		challenge.UsedAt == nil &&
+		challenge.FailedAttempts < maxFailedAttempts &&
+		now.Before(challenge.ExpiresAt)
+}
+
+type TotpKey struct {
+	Secret string
+	// The secret encrypted for storage
+	SealedSecret string
+	Uri          string
+	// PNG image of the uri encoded as a data url
+	QrCode string
+}
+
+type TotpEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+	QrCode     string `json:"qr_code"`
+}
+
+type MfaChallengeToken struct {
+	Token     string `json:"mfa_token"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// The code is either a code of the authenticator app or a recovery code
+type TotpCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
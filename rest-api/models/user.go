@@ -1,7 +1,52 @@
 package models
 
+import "time"
+
 type User struct {
-	Id       int64  `json:"-"`
-	Email    string `json:"email" binding:"required"`
+	Id         int64      `json:"-"`
+	Email      string     `json:"email" binding:"required"`
+	Password   string     `json:"password" binding:"required"`
+	Role       string     `json:"-"`
+	DisabledAt *time.Time `json:"-"`
+	VerifiedAt *time.Time `json:"-"`
+	// Last time a verification email was sent, used to throttle resending it
+	VerificationSentAt *time.Time `json:"-"`
+}
+
+func (user User) Permissions() []string {
+	return PermissionsForRole(user.Role)
+}
+
+func (user User) IsDisabled() bool {
+	return user.DisabledAt != nil
+}
+
+func (user User) IsVerified() bool {
+	return user.VerifiedAt != nil
+}
+
+type SignupRequest struct {
+	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
+
+// User as listed to administrators, without the password hash
+type UserSummary struct {
+	Id          int64      `json:"id"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Permissions []string   `json:"permissions"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+}
+
+func NewUserSummary(user User) UserSummary {
+	return UserSummary{
+		Id:          user.Id,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: user.Permissions(),
+		DisabledAt:  user.DisabledAt,
+		VerifiedAt:  user.VerifiedAt,
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Which notifications the user wants to receive, every notification is enabled by default
+type NotificationPreferences struct {
+	EventUpdates   bool `json:"event_updates"`
+	Announcements  bool `json:"announcements"`
+	CommentReplies bool `json:"comment_replies"`
+}
+
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		EventUpdates:   true,
+		Announcements:  true,
+		CommentReplies: true,
+	}
+}
+
+type UserProfile struct {
+	UserId                  int64                   `json:"-"`
+	Email                   string                  `json:"email"`
+	EmailVerified           bool                    `json:"email_verified"`
+	DisplayName             string                  `json:"display_name"`
+	AvatarUrl               string                  `json:"avatar_url"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	UpdatedAt               *time.Time              `json:"updated_at"`
+}
+
+// Fields left out of the request are kept as they are
+type UpdateProfileRequest struct {
+	DisplayName             *string                        `json:"display_name" binding:"omitempty,max=100"`
+	AvatarUrl               *string                        `json:"avatar_url" binding:"omitempty,http_url,max=2048"`
+	NotificationPreferences *UpdateNotificationPreferences `json:"notification_preferences"`
+}
+
+type UpdateNotificationPreferences struct {
+	EventUpdates   *bool `json:"event_updates"`
+	Announcements  *bool `json:"announcements"`
+	CommentReplies *bool `json:"comment_replies"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
@@ -0,0 +1,10 @@
+package models
+
+const (
+	// Listed and readable by anyone
+	PUBLIC_VISIBILITY = "public"
+	// Readable by anyone with the event id, but not listed
+	UNLISTED_VISIBILITY = "unlisted"
+	// Only readable by the organizer and invitees
+	PRIVATE_VISIBILITY = "private"
+)
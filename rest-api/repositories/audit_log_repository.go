@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+
+	"example.com/models"
+)
+
+const auditLogColumns = `id, occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason`
+
+// Only ever appends entries, the database refuses updates and deletions of the log
+type AuditLogRepository struct {
+	database *sql.DB
+}
+
+func (auditLogRepository AuditLogRepository) CreateEntry(entry models.AuditLogEntry) error {
+	createEntrySql := `
+	INSERT INTO AuditLog(occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	statement, err := auditLogRepository.database.Prepare(createEntrySql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(
+		entry.OccurredAt,
+		entry.Action,
+		entry.Outcome,
+		sql.NullInt64{Int64: entry.ActorId, Valid: entry.ActorId != 0},
+		sql.NullString{String: entry.ActorEmail, Valid: entry.ActorEmail != ""},
+		entry.IpAddress,
+		entry.UserAgent,
+		entry.Resource,
+		sql.NullString{String: entry.Reason, Valid: entry.Reason != ""})
+
+	return err
+}
+
+// Returns the entries matching the filter, oldest first
+func (auditLogRepository AuditLogRepository) GetEntries(filter models.AuditLogFilter) ([]models.AuditLogEntry, error) {
+	conditions := []string{"id > ?"}
+	args := []any{filter.AfterId}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+
+	if filter.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, filter.Outcome)
+	}
+
+	if filter.ActorId != 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorId)
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "occurred_at >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "occurred_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	entriesSql := `
+	SELECT ` + auditLogColumns + `
+	FROM AuditLog
+	WHERE ` + strings.Join(conditions, " AND ") + `
+	ORDER BY id
+	LIMIT ?`
+
+	statement, err := auditLogRepository.database.Prepare(entriesSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(append(args, filter.Limit)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []models.AuditLogEntry{}
+
+	for rows.Next() {
+		entry, err := scanAuditLogEntry(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanAuditLogEntry(scanner rowScanner) (models.AuditLogEntry, error) {
+	var entry models.AuditLogEntry
+	var actorId sql.NullInt64
+	var actorEmail, reason sql.NullString
+
+	err := scanner.Scan(
+		&entry.Id,
+		&entry.OccurredAt,
+		&entry.Action,
+		&entry.Outcome,
+		&actorId,
+		&actorEmail,
+		&entry.IpAddress,
+		&entry.UserAgent,
+		&entry.Resource,
+		&reason)
+
+	if err != nil {
+		return models.AuditLogEntry{}, err
+	}
+
+	entry.ActorId = actorId.Int64
+	entry.ActorEmail = actorEmail.String
+	entry.Reason = reason.String
+
+	return entry, nil
+}
+
+func NewAuditLogRepository(database *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *AuditLogRepository
+}
+
+func TestAuditLogRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AuditLogRepositoryUnitTestSuite{})
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewAuditLogRepository(db)
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+const createAuditLogEntrySql = `
+	INSERT INTO AuditLog(occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func (suite *AuditLogRepositoryUnitTestSuite) TestCreateEntry_PreparesTheSqlStatement() {
+
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(createAuditLogEntrySql).
+		ExpectExec().
+		WithArgs(occurredAt, models.LOGIN_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, int64(12), "test@test.com", "10.0.0.1", "curl", "POST /login", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.repository.CreateEntry(models.AuditLogEntry{
+		OccurredAt: occurredAt,
+		Action:     models.LOGIN_AUDIT_ACTION,
+		Outcome:    models.SUCCESS_AUDIT_OUTCOME,
+		ActorId:    12,
+		ActorEmail: "test@test.com",
+		IpAddress:  "10.0.0.1",
+		UserAgent:  "curl",
+		Resource:   "POST /login",
+	})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// Anonymous requests are stored without an actor
+func (suite *AuditLogRepositoryUnitTestSuite) TestCreateEntryOfAnAnonymousRequest_StoresNoActor() {
+
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(createAuditLogEntrySql).
+		ExpectExec().
+		WithArgs(occurredAt, models.ACCESS_DENIED_AUDIT_ACTION, models.DENIED_AUDIT_OUTCOME, nil, nil, "10.0.0.1", "curl", "GET /users/me", "Missing access token").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.repository.CreateEntry(models.AuditLogEntry{
+		OccurredAt: occurredAt,
+		Action:     models.ACCESS_DENIED_AUDIT_ACTION,
+		Outcome:    models.DENIED_AUDIT_OUTCOME,
+		IpAddress:  "10.0.0.1",
+		UserAgent:  "curl",
+		Resource:   "GET /users/me",
+		Reason:     "Missing access token",
+	})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) TestCreateEntry_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(createAuditLogEntrySql).WillReturnError(expectedError)
+
+	err := suite.repository.CreateEntry(models.AuditLogEntry{})
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) TestGetEntriesWithoutCriteria_ReturnsTheEntriesAfterTheCursor() {
+
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason
+	FROM AuditLog
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?`).
+		ExpectQuery().
+		WithArgs(int64(7), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "action", "outcome", "actor_id", "actor_email", "ip_address", "user_agent", "resource", "reason"}).
+			AddRow(8, occurredAt, models.LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, nil, "test@test.com", "10.0.0.1", "curl", "POST /login", "Invalid credentials").
+			AddRow(9, occurredAt, models.LOGOUT_AUDIT_ACTION, models.SUCCESS_AUDIT_OUTCOME, 12, nil, "10.0.0.1", "curl", "POST /logout", nil))
+
+	entries, err := suite.repository.GetEntries(models.AuditLogFilter{AfterId: 7, Limit: 100})
+
+	suite.Nil(err)
+	suite.Equal([]models.AuditLogEntry{
+		{
+			Id:         8,
+			OccurredAt: occurredAt,
+			Action:     models.LOGIN_AUDIT_ACTION,
+			Outcome:    models.FAILURE_AUDIT_OUTCOME,
+			ActorEmail: "test@test.com",
+			IpAddress:  "10.0.0.1",
+			UserAgent:  "curl",
+			Resource:   "POST /login",
+			Reason:     "Invalid credentials",
+		},
+		{
+			Id:         9,
+			OccurredAt: occurredAt,
+			Action:     models.LOGOUT_AUDIT_ACTION,
+			Outcome:    models.SUCCESS_AUDIT_OUTCOME,
+			ActorId:    12,
+			IpAddress:  "10.0.0.1",
+			UserAgent:  "curl",
+			Resource:   "POST /logout",
+		},
+	}, entries)
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) TestGetEntriesWithEveryCriteria_FiltersTheEntries() {
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason
+	FROM AuditLog
+	WHERE id > ? AND action = ? AND outcome = ? AND actor_id = ? AND occurred_at >= ? AND occurred_at < ?
+	ORDER BY id
+	LIMIT ?`).
+		ExpectQuery().
+		WithArgs(int64(0), models.LOGIN_AUDIT_ACTION, models.FAILURE_AUDIT_OUTCOME, int64(12), from, to, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "action", "outcome", "actor_id", "actor_email", "ip_address", "user_agent", "resource", "reason"}))
+
+	entries, err := suite.repository.GetEntries(models.AuditLogFilter{
+		Action:  models.LOGIN_AUDIT_ACTION,
+		Outcome: models.FAILURE_AUDIT_OUTCOME,
+		ActorId: 12,
+		From:    &from,
+		To:      &to,
+		Limit:   10,
+	})
+
+	suite.Nil(err)
+	suite.Empty(entries)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *AuditLogRepositoryUnitTestSuite) TestGetEntries_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, occurred_at, action, outcome, actor_id, actor_email, ip_address, user_agent, resource, reason
+	FROM AuditLog
+	WHERE id > ?
+	ORDER BY id
+	LIMIT ?`).
+		ExpectQuery().
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.GetEntries(models.AuditLogFilter{Limit: 10})
+
+	suite.Equal(expectedError, err)
+}
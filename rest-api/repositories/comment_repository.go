@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/models"
+)
+
+const commentColumns = `id, event_id, user_id, parent_id, root_id, body, pinned, created_at, updated_at, deleted_at`
+
+type CommentRepository struct {
+	database *sql.DB
+}
+
+func (commentRepository CommentRepository) CreateComment(comment *models.Comment) error {
+	createCommentSql := `
+	INSERT INTO Comments(event_id, user_id, parent_id, root_id, body, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	statement, err := commentRepository.database.Prepare(createCommentSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		comment.EventId,
+		comment.UserId,
+		comment.ParentId,
+		comment.RootId,
+		comment.Body,
+		comment.CreatedAt)
+
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	id, _ := result.LastInsertId()
+
+	comment.Id = id
+
+	return nil
+}
+
+// Returns a comment with an id of 0 when no comment exists for the id
+func (commentRepository CommentRepository) GetCommentById(id int64) (*models.Comment, error) {
+	commentByIdSql := `SELECT ` + commentColumns + ` FROM Comments WHERE id = ?`
+
+	statement, err := commentRepository.database.Prepare(commentByIdSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	comment, err := scanComment(statement.QueryRow(id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.Comment{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// Returns a page of the top level comments of the event, pinned comments first
+func (commentRepository CommentRepository) GetRootComments(eventId int64, limit, offset int) ([]models.Comment, error) {
+	rootCommentsSql := `
+	SELECT ` + commentColumns + ` FROM Comments
+	WHERE event_id = ? AND parent_id IS NULL
+	ORDER BY pinned DESC, created_at, id
+	LIMIT ? OFFSET ?`
+
+	statement, err := commentRepository.database.Prepare(rootCommentsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(eventId, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanComments(rows)
+}
+
+func (commentRepository CommentRepository) CountRootComments(eventId int64) (int64, error) {
+	countSql := `SELECT COUNT(*) FROM Comments WHERE event_id = ? AND parent_id IS NULL`
+
+	statement, err := commentRepository.database.Prepare(countSql)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(eventId).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Returns every reply within the threads of the given top level comments, oldest first
+func (commentRepository CommentRepository) GetReplies(rootIds []int64) ([]models.Comment, error) {
+	if len(rootIds) == 0 {
+		return make([]models.Comment, 0), nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(rootIds)), ",")
+
+	repliesSql := `SELECT ` + commentColumns + ` FROM Comments WHERE root_id IN (` + placeholders + `) ORDER BY created_at, id`
+
+	args := make([]any, len(rootIds))
+
+	for i, rootId := range rootIds {
+		args[i] = rootId
+	}
+
+	statement, err := commentRepository.database.Prepare(repliesSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanComments(rows)
+}
+
+// Returns every comment written by the user across events, deleted ones included
+func (commentRepository CommentRepository) GetCommentsByUserId(userId int64) ([]models.Comment, error) {
+	userCommentsSql := `SELECT ` + commentColumns + ` FROM Comments WHERE user_id = ? ORDER BY created_at, id`
+
+	statement, err := commentRepository.database.Prepare(userCommentsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanComments(rows)
+}
+
+func (commentRepository CommentRepository) UpdateCommentBody(id int64, body string, updatedAt time.Time) error {
+	updateCommentSql := `UPDATE Comments SET body = ?, updated_at = ? WHERE id = ?`
+
+	return commentRepository.exec(updateCommentSql, body, updatedAt, id)
+}
+
+// Comments are only marked as deleted and their body cleared, so replies keep their place in the thread
+func (commentRepository CommentRepository) SoftDeleteComment(id int64, deletedAt time.Time) error {
+	deleteCommentSql := `UPDATE Comments SET body = '', pinned = 0, deleted_at = ? WHERE id = ?`
+
+	return commentRepository.exec(deleteCommentSql, deletedAt, id)
+}
+
+func (commentRepository CommentRepository) SetCommentPinned(id int64, pinned bool) error {
+	pinCommentSql := `UPDATE Comments SET pinned = ? WHERE id = ?`
+
+	return commentRepository.exec(pinCommentSql, pinned, id)
+}
+
+func (commentRepository CommentRepository) exec(query string, args ...any) error {
+	statement, err := commentRepository.database.Prepare(query)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(args...)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanComments(rows *sql.Rows) ([]models.Comment, error) {
+	defer rows.Close()
+
+	comments := make([]models.Comment, 0)
+
+	for rows.Next() {
+		comment, err := scanComment(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+func scanComment(scanner rowScanner) (models.Comment, error) {
+	var comment models.Comment
+	var userId, parentId, rootId sql.NullInt64
+	var updatedAt, deletedAt sql.NullTime
+
+	err := scanner.Scan(
+		&comment.Id,
+		&comment.EventId,
+		&userId,
+		&parentId,
+		&rootId,
+		&comment.Body,
+		&comment.Pinned,
+		&comment.CreatedAt,
+		&updatedAt,
+		&deletedAt)
+
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	comment.UserId = userId.Int64
+
+	if parentId.Valid {
+		comment.ParentId = &parentId.Int64
+	}
+
+	if rootId.Valid {
+		comment.RootId = &rootId.Int64
+	}
+
+	if updatedAt.Valid {
+		comment.UpdatedAt = &updatedAt.Time
+	}
+
+	if deletedAt.Valid {
+		comment.DeletedAt = &deletedAt.Time
+		comment.Deleted = true
+	}
+
+	return comment, nil
+}
+
+func NewCommentRepository(database *sql.DB) *CommentRepository {
+	return &CommentRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *CommentRepository
+}
+
+func TestCommentRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &CommentRepositoryUnitTestSuite{})
+}
+
+func (suite *CommentRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewCommentRepository(db)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func commentRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"id",
+		"event_id",
+		"user_id",
+		"parent_id",
+		"root_id",
+		"body",
+		"pinned",
+		"created_at",
+		"updated_at",
+		"deleted_at",
+	})
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestCreateComment_SetsTheIdToTheDbId() {
+
+	parentId := int64(3)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	comment := models.Comment{
+		EventId:   1,
+		UserId:    12,
+		ParentId:  &parentId,
+		RootId:    &parentId,
+		Body:      "some body",
+		CreatedAt: now,
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Comments(event_id, user_id, parent_id, root_id, body, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(int64(1), int64(12), &parentId, &parentId, "some body", now).
+		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
+
+	err := suite.repository.CreateComment(&comment)
+
+	suite.Nil(err)
+	suite.Equal(int64(10), comment.Id)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *CommentRepositoryUnitTestSuite) TestCreateComment_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Comments(event_id, user_id, parent_id, root_id, body, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`).
+		WillReturnError(expectedError)
+
+	err := suite.repository.CreateComment(&models.Comment{})
+
+	suite.Equal(expectedError, err)
+}
+
+// When no comment exists for the id, an empty comment is returned
+func (suite *CommentRepositoryUnitTestSuite) TestGetCommentById_ReturnsEmptyComment() {
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + commentColumns + ` FROM Comments WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(int64(3)).
+		WillReturnRows(commentRows())
+
+	comment, err := suite.repository.GetCommentById(3)
+
+	suite.Nil(err)
+	suite.Equal(&models.Comment{}, comment)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestGetCommentById_MarksDeletedComments() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + commentColumns + ` FROM Comments WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(int64(3)).
+		WillReturnRows(commentRows().AddRow(3, 1, 12, nil, nil, "", false, now, nil, now))
+
+	comment, _ := suite.repository.GetCommentById(3)
+
+	suite.Equal(&models.Comment{
+		Id:        3,
+		EventId:   1,
+		UserId:    12,
+		CreatedAt: now,
+		DeletedAt: &now,
+		Deleted:   true,
+	}, comment)
+}
+
+// Comments of deleted accounts have no author
+func (suite *CommentRepositoryUnitTestSuite) TestGetCommentByIdOfDeletedAuthor_ReturnsNoAuthor() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + commentColumns + ` FROM Comments WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(int64(3)).
+		WillReturnRows(commentRows().AddRow(3, 1, nil, nil, nil, "", false, now, nil, now))
+
+	comment, err := suite.repository.GetCommentById(3)
+
+	suite.Nil(err)
+	suite.Equal(int64(0), comment.UserId)
+	suite.True(comment.Deleted)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestGetRootComments_PagesTheComments() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT `+commentColumns+` FROM Comments
+	WHERE event_id = ? AND parent_id IS NULL
+	ORDER BY pinned DESC, created_at, id
+	LIMIT ? OFFSET ?`).
+		ExpectQuery().
+		WithArgs(int64(1), 20, 40).
+		WillReturnRows(commentRows().AddRow(3, 1, 12, nil, nil, "some body", true, now, nil, nil))
+
+	comments, err := suite.repository.GetRootComments(1, 20, 40)
+
+	suite.Nil(err)
+	suite.Equal([]models.Comment{
+		{
+			Id:        3,
+			EventId:   1,
+			UserId:    12,
+			Body:      "some body",
+			Pinned:    true,
+			CreatedAt: now,
+		},
+	}, comments)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestCountRootComments_ReturnsTheCount() {
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM Comments WHERE event_id = ? AND parent_id IS NULL`).
+		ExpectQuery().
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	count, err := suite.repository.CountRootComments(1)
+
+	suite.Nil(err)
+	suite.Equal(int64(7), count)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestGetReplies_QueriesEveryThread() {
+
+	suite.dbMock.ExpectPrepare(`SELECT `+commentColumns+` FROM Comments WHERE root_id IN (?,?) ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(int64(3), int64(4)).
+		WillReturnRows(commentRows())
+
+	comments, err := suite.repository.GetReplies([]int64{3, 4})
+
+	suite.Nil(err)
+	suite.Empty(comments)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When there are no threads, the database should not be queried
+func (suite *CommentRepositoryUnitTestSuite) TestGetRepliesWithoutThreads_ReturnsEmptyArray() {
+
+	comments, err := suite.repository.GetReplies([]int64{})
+
+	suite.Nil(err)
+	suite.NotNil(comments)
+	suite.Empty(comments)
+}
+
+func (suite *CommentRepositoryUnitTestSuite) TestUpdateCommentBody_PreparesTheSqlStatement() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`UPDATE Comments SET body = ?, updated_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs("some body", now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.UpdateCommentBody(3, "some body", now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// Deleting a comment keeps the row, so replies keep their parent
+func (suite *CommentRepositoryUnitTestSuite) TestSoftDeleteComment_ClearsTheComment() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`UPDATE Comments SET body = '', pinned = 0, deleted_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.SoftDeleteComment(3, now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *CommentRepositoryUnitTestSuite) TestSetCommentPinned_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`UPDATE Comments SET pinned = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(true, int64(3)).
+		WillReturnError(expectedError)
+
+	err := suite.repository.SetCommentPinned(3, true)
+
+	suite.Equal(expectedError, err)
+}
+
+// Deleted comments are returned as well
+func (suite *CommentRepositoryUnitTestSuite) TestGetCommentsByUserId_ReturnsTheComments() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + commentColumns + ` FROM Comments WHERE user_id = ? ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(int64(12)).
+		WillReturnRows(commentRows().
+			AddRow(3, 1, 12, nil, nil, "Hello", false, now, nil, nil).
+			AddRow(4, 2, 12, 7, 7, "", false, now, nil, now))
+
+	comments, err := suite.repository.GetCommentsByUserId(12)
+
+	suite.Nil(err)
+	suite.Len(comments, 2)
+	suite.False(comments[0].Deleted)
+	suite.True(comments[1].Deleted)
+	suite.Equal(int64(7), *comments[1].ParentId)
+}
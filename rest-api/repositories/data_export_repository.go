@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+// The archive is left out, it is only read when downloaded
+const dataExportColumns = `id, user_id, status, created_at, completed_at, expires_at`
+
+type DataExportRepository struct {
+	database *sql.DB
+}
+
+func (dataExportRepository DataExportRepository) CreateDataExport(export *models.DataExport) error {
+	createExportSql := `
+	INSERT INTO DataExports(user_id, status, created_at, expires_at)
+	VALUES (?, ?, ?, ?)`
+
+	statement, err := dataExportRepository.database.Prepare(createExportSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(export.UserId, export.Status, export.CreatedAt, export.ExpiresAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	export.Id = id
+
+	return nil
+}
+
+// Returns an export with an id of 0 when the user has no export with the id
+func (dataExportRepository DataExportRepository) GetDataExport(id, userId int64) (*models.DataExport, error) {
+	exportSql := `SELECT ` + dataExportColumns + ` FROM DataExports WHERE id = ? AND user_id = ?`
+
+	return dataExportRepository.getDataExport(exportSql, id, userId)
+}
+
+// Returns the latest export of the user still being generated that was created after createdAfter,
+// or an export with an id of 0 when there is none
+func (dataExportRepository DataExportRepository) GetPendingDataExport(userId int64, createdAfter time.Time) (*models.DataExport, error) {
+	pendingExportSql := `
+	SELECT ` + dataExportColumns + `
+	FROM DataExports
+	WHERE user_id = ? AND status = ? AND created_at > ?
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1`
+
+	return dataExportRepository.getDataExport(pendingExportSql, userId, models.DATA_EXPORT_PENDING, createdAfter)
+}
+
+func (dataExportRepository DataExportRepository) GetDataExportsByUserId(userId int64) ([]models.DataExport, error) {
+	exportsByUserSql := `SELECT ` + dataExportColumns + ` FROM DataExports WHERE user_id = ? ORDER BY created_at, id`
+
+	statement, err := dataExportRepository.database.Prepare(exportsByUserSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	exports := make([]models.DataExport, 0)
+
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		exports = append(exports, export)
+	}
+
+	return exports, rows.Err()
+}
+
+// Returns nil when the export has no archive
+func (dataExportRepository DataExportRepository) GetDataExportArchive(id int64) ([]byte, error) {
+	archiveSql := `SELECT archive FROM DataExports WHERE id = ?`
+
+	statement, err := dataExportRepository.database.Prepare(archiveSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var archive []byte
+
+	err = statement.QueryRow(id).Scan(&archive)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+func (dataExportRepository DataExportRepository) CompleteDataExport(id int64, archive []byte, completedAt time.Time) error {
+	completeExportSql := `UPDATE DataExports SET status = ?, archive = ?, completed_at = ? WHERE id = ?`
+
+	return dataExportRepository.exec(completeExportSql, models.DATA_EXPORT_READY, archive, completedAt, id)
+}
+
+func (dataExportRepository DataExportRepository) FailDataExport(id int64, failedAt time.Time) error {
+	failExportSql := `UPDATE DataExports SET status = ?, completed_at = ? WHERE id = ?`
+
+	return dataExportRepository.exec(failExportSql, models.DATA_EXPORT_FAILED, failedAt, id)
+}
+
+func (dataExportRepository DataExportRepository) DeleteExpiredDataExports(now time.Time) error {
+	deleteExpiredSql := `DELETE FROM DataExports WHERE expires_at <= ?`
+
+	return dataExportRepository.exec(deleteExpiredSql, now)
+}
+
+// Counts the events, registrations and comments of the user, which make up most of an export
+func (dataExportRepository DataExportRepository) CountUserRecords(userId int64) (int64, error) {
+	countRecordsSql := `
+	SELECT
+		(SELECT COUNT(*) FROM Events WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM Registrations WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM Comments WHERE user_id = ?)`
+
+	statement, err := dataExportRepository.database.Prepare(countRecordsSql)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(userId, userId, userId).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (dataExportRepository DataExportRepository) getDataExport(query string, args ...any) (*models.DataExport, error) {
+	statement, err := dataExportRepository.database.Prepare(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	export, err := scanDataExport(statement.QueryRow(args...))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.DataExport{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (dataExportRepository DataExportRepository) exec(query string, args ...any) error {
+	statement, err := dataExportRepository.database.Prepare(query)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(args...)
+
+	return err
+}
+
+func scanDataExport(scanner rowScanner) (models.DataExport, error) {
+	var export models.DataExport
+	var completedAt sql.NullTime
+
+	err := scanner.Scan(
+		&export.Id,
+		&export.UserId,
+		&export.Status,
+		&export.CreatedAt,
+		&completedAt,
+		&export.ExpiresAt)
+
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+
+	return export, nil
+}
+
+func NewDataExportRepository(database *sql.DB) *DataExportRepository {
+	return &DataExportRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataExportRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *DataExportRepository
+}
+
+func TestDataExportRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &DataExportRepositoryUnitTestSuite{})
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewDataExportRepository(db)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func dataExportRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "user_id", "status", "created_at", "completed_at", "expires_at"})
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestCreateDataExport_SetsTheId() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO DataExports(user_id, status, created_at, expires_at)
+	VALUES (?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(12, models.DATA_EXPORT_PENDING, now, now).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	export := models.DataExport{
+		UserId:    12,
+		Status:    models.DATA_EXPORT_PENDING,
+		CreatedAt: now,
+		ExpiresAt: now,
+	}
+
+	err := suite.repository.CreateDataExport(&export)
+
+	suite.Nil(err)
+	suite.Equal(int64(3), export.Id)
+}
+
+// When the user has no export with the id, an export with an id of 0 is returned
+func (suite *DataExportRepositoryUnitTestSuite) TestGetDataExportOfAnotherUser_ReturnsEmptyExport() {
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, status, created_at, completed_at, expires_at FROM DataExports WHERE id = ? AND user_id = ?`).
+		ExpectQuery().
+		WithArgs(3, 12).
+		WillReturnRows(dataExportRows())
+
+	export, err := suite.repository.GetDataExport(3, 12)
+
+	suite.Nil(err)
+	suite.Equal(&models.DataExport{}, export)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestGetDataExport_ReturnsTheExport() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, status, created_at, completed_at, expires_at FROM DataExports WHERE id = ? AND user_id = ?`).
+		ExpectQuery().
+		WithArgs(3, 12).
+		WillReturnRows(dataExportRows().AddRow(3, 12, models.DATA_EXPORT_READY, now, now, now))
+
+	export, err := suite.repository.GetDataExport(3, 12)
+
+	suite.Nil(err)
+	suite.Equal(&models.DataExport{
+		Id:          3,
+		UserId:      12,
+		Status:      models.DATA_EXPORT_READY,
+		CreatedAt:   now,
+		CompletedAt: &now,
+		ExpiresAt:   now,
+	}, export)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestGetPendingDataExport_ReturnsTheLatestExport() {
+
+	now := time.Now()
+	createdAfter := now.Add(-time.Hour)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, status, created_at, completed_at, expires_at
+	FROM DataExports
+	WHERE user_id = ? AND status = ? AND created_at > ?
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1`).
+		ExpectQuery().
+		WithArgs(12, models.DATA_EXPORT_PENDING, createdAfter).
+		WillReturnRows(dataExportRows().AddRow(4, 12, models.DATA_EXPORT_PENDING, now, nil, now))
+
+	export, err := suite.repository.GetPendingDataExport(12, createdAfter)
+
+	suite.Nil(err)
+	suite.Equal(int64(4), export.Id)
+	suite.Nil(export.CompletedAt)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestGetDataExportsByUserId_ReturnsTheExports() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, status, created_at, completed_at, expires_at FROM DataExports WHERE user_id = ? ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(dataExportRows().
+			AddRow(3, 12, models.DATA_EXPORT_FAILED, now, now, now).
+			AddRow(4, 12, models.DATA_EXPORT_PENDING, now, nil, now))
+
+	exports, err := suite.repository.GetDataExportsByUserId(12)
+
+	suite.Nil(err)
+	suite.Len(exports, 2)
+	suite.Equal(models.DATA_EXPORT_FAILED, exports[0].Status)
+}
+
+// When reading the rows fails midway, pass that up to the caller
+func (suite *DataExportRepositoryUnitTestSuite) TestGetDataExportsByUserIdRowError_ReturnsTheError() {
+
+	now := time.Now()
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, status, created_at, completed_at, expires_at FROM DataExports WHERE user_id = ? ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(dataExportRows().
+			AddRow(3, 12, models.DATA_EXPORT_FAILED, now, now, now).
+			RowError(0, expectedError))
+
+	_, err := suite.repository.GetDataExportsByUserId(12)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestGetDataExportArchive_ReturnsTheArchive() {
+
+	suite.dbMock.ExpectPrepare(`SELECT archive FROM DataExports WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"archive"}).AddRow([]byte("archive")))
+
+	archive, err := suite.repository.GetDataExportArchive(3)
+
+	suite.Nil(err)
+	suite.Equal([]byte("archive"), archive)
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestCompleteDataExport_StoresTheArchive() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE DataExports SET status = ?, archive = ?, completed_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(models.DATA_EXPORT_READY, []byte("archive"), now, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.CompleteDataExport(3, []byte("archive"), now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestFailDataExport_UpdatesTheStatus() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE DataExports SET status = ?, completed_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(models.DATA_EXPORT_FAILED, now, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.FailDataExport(3, now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestDeleteExpiredDataExports_PreparesTheSqlStatement() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`DELETE FROM DataExports WHERE expires_at <= ?`).
+		ExpectExec().
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := suite.repository.DeleteExpiredDataExports(now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *DataExportRepositoryUnitTestSuite) TestCountUserRecords_ReturnsTheCount() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT
+		(SELECT COUNT(*) FROM Events WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM Registrations WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM Comments WHERE user_id = ?)`).
+		ExpectQuery().
+		WithArgs(12, 12, 12).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1500))
+
+	count, err := suite.repository.CountUserRecords(12)
+
+	suite.Nil(err)
+	suite.Equal(int64(1500), count)
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"errors"
+
+	"example.com/constants"
+)
+
+// Extended result codes of the sqlite driver for constraint violations
+const (
+	sqliteForeignKeyConstraintCode = 787
+	sqlitePrimaryKeyConstraintCode = 1555
+	sqliteUniqueConstraintCode     = 2067
+)
+
+// Errors of the sqlite driver expose their result code
+type codedDatabaseError interface {
+	Code() int
+}
+
+// Turns constraint violations into errors the services and controllers can tell apart, any other
+// error is returned as is
+func mapConstraintError(err error) error {
+	var databaseError codedDatabaseError
+
+	if !errors.As(err, &databaseError) {
+		return err
+	}
+
+	switch databaseError.Code() {
+	case sqliteUniqueConstraintCode, sqlitePrimaryKeyConstraintCode:
+		return errors.New(constants.DUPLICATE_RECORD_ERROR)
+	case sqliteForeignKeyConstraintCode:
+		return errors.New(constants.MISSING_REFERENCED_RECORD_ERROR)
+	default:
+		return err
+	}
+}
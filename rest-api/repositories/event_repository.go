@@ -2,14 +2,33 @@ package repositories
 
 import (
 	"database/sql"
+	"time"
 
 	"example.com/models"
 )
 
+const eventColumns = `id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id`
+
+// Restricts events to the ones of the tenant, an organization or the personal space when the
+// organization id is 0. Expects the organization id
+const eventTenantCondition = `COALESCE(organization_id, 0) = ?`
+
+// Restricts events to the ones the viewer is allowed to list: public events, the viewer's own
+// events and private events the viewer has been invited to. Expects the viewer id three times,
+// followed by the current time
+const visibleEventsCondition = `(
+	visibility = 'public'
+	OR user_id = ?
+	OR (visibility = 'private' AND ` + invitedCondition + `))`
+
 type EventRepository struct {
 	database *sql.DB
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
 func (eventRepository *EventRepository) AddEvent(event *models.Event) error {
 	saveSql := `
 	INSERT INTO Events (
@@ -17,8 +36,13 @@ func (eventRepository *EventRepository) AddEvent(event *models.Event) error {
 	description,
 	location,
 	date,
-	user_id
-	) VALUES (?,?,?,?,?)`
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`
 
 	statement, err := eventRepository.database.Prepare(saveSql)
 
@@ -33,7 +57,12 @@ func (eventRepository *EventRepository) AddEvent(event *models.Event) error {
 		event.Description,
 		event.Location,
 		event.Date,
-		event.UserId)
+		event.UserId,
+		event.Latitude,
+		event.Longitude,
+		event.Visibility,
+		event.EndDate,
+		nullableOrganizationId(event.OrganizationId))
 
 	if resultError != nil {
 		return resultError
@@ -46,10 +75,10 @@ func (eventRepository *EventRepository) AddEvent(event *models.Event) error {
 	return nil
 }
 
-func (eventRepository *EventRepository) GetEvents() ([]models.Event, error) {
-	eventsQuerySql := "SELECT * FROM Events"
+func (eventRepository *EventRepository) GetEvents(viewerId, organizationId int64) ([]models.Event, error) {
+	eventsQuerySql := "SELECT " + eventColumns + " FROM Events WHERE " + eventTenantCondition + " AND " + visibleEventsCondition
 
-	rows, err := eventRepository.database.Query(eventsQuerySql)
+	rows, err := eventRepository.database.Query(eventsQuerySql, organizationId, viewerId, viewerId, viewerId, time.Now().UTC())
 
 	if err != nil {
 		return nil, err
@@ -60,15 +89,7 @@ func (eventRepository *EventRepository) GetEvents() ([]models.Event, error) {
 	var events []models.Event
 
 	for rows.Next() {
-		var event models.Event
-
-		err = rows.Scan(
-			&event.Id,
-			&event.Name,
-			&event.Description,
-			&event.Location,
-			&event.Date,
-			&event.UserId)
+		event, err := scanEvent(rows)
 
 		if err != nil {
 			return nil, err
@@ -85,8 +106,9 @@ func (eventRepository *EventRepository) GetEvents() ([]models.Event, error) {
 	return events, nil
 }
 
-func (eventRepository *EventRepository) GetEventById(id int64) (*models.Event, error) {
-	eventByIdQuerySql := "SELECT * FROM Events WHERE ID = ?"
+// Returns an event with an id of 0 when the event does not exist or belongs to another tenant
+func (eventRepository *EventRepository) GetEventById(id, organizationId int64) (*models.Event, error) {
+	eventByIdQuerySql := "SELECT " + eventColumns + " FROM Events WHERE ID = ? AND " + eventTenantCondition
 
 	statement, err := eventRepository.database.Prepare(eventByIdQuerySql)
 
@@ -96,7 +118,7 @@ func (eventRepository *EventRepository) GetEventById(id int64) (*models.Event, e
 
 	defer statement.Close()
 
-	rows, queryResult := statement.Query(id)
+	rows, queryResult := statement.Query(id, organizationId)
 
 	if queryResult != nil {
 		return nil, queryResult
@@ -107,13 +129,7 @@ func (eventRepository *EventRepository) GetEventById(id int64) (*models.Event, e
 	var event models.Event
 
 	if rows.Next() {
-		err = rows.Scan(
-			&event.Id,
-			&event.Name,
-			&event.Description,
-			&event.Location,
-			&event.Date,
-			&event.UserId)
+		event, err = scanEvent(rows)
 
 		if err != nil {
 			return nil, err
@@ -123,11 +139,13 @@ func (eventRepository *EventRepository) GetEventById(id int64) (*models.Event, e
 	return &event, nil
 }
 
-func (eventRepository *EventRepository) UpdateEvent(id int64, event models.Event) error {
+// Events stay in their tenant and with their organizer, neither the organization nor the user of the
+// event can be changed
+func (eventRepository *EventRepository) UpdateEvent(id, organizationId int64, event models.Event) error {
 	updateEventSql := `
 	UPDATE Events
-	SET name = ?, description = ?, location = ?, date = ?, user_id = ?
-	WHERE ID = ?`
+	SET name = ?, description = ?, location = ?, date = ?, latitude = ?, longitude = ?, visibility = ?, end_date = ?
+	WHERE ID = ? AND ` + eventTenantCondition
 
 	statement, err := eventRepository.database.Prepare(updateEventSql)
 
@@ -142,8 +160,12 @@ func (eventRepository *EventRepository) UpdateEvent(id int64, event models.Event
 		event.Description,
 		event.Location,
 		event.Date,
-		event.UserId,
-		id)
+		event.Latitude,
+		event.Longitude,
+		event.Visibility,
+		event.EndDate,
+		id,
+		organizationId)
 
 	if updateError != nil {
 		return updateError
@@ -152,8 +174,8 @@ func (eventRepository *EventRepository) UpdateEvent(id int64, event models.Event
 	return nil
 }
 
-func (eventRepository *EventRepository) DeleteEvent(id int64) error {
-	deleteEventSql := `DELETE FROM Events WHERE ID = ?`
+func (eventRepository *EventRepository) DeleteEvent(id, organizationId int64) error {
+	deleteEventSql := `DELETE FROM Events WHERE ID = ? AND ` + eventTenantCondition
 
 	statement, err := eventRepository.database.Prepare(deleteEventSql)
 
@@ -163,7 +185,7 @@ func (eventRepository *EventRepository) DeleteEvent(id int64) error {
 
 	defer statement.Close()
 
-	_, deleteError := statement.Exec(id)
+	_, deleteError := statement.Exec(id, organizationId)
 
 	if deleteError != nil {
 		return deleteError
@@ -172,6 +194,212 @@ func (eventRepository *EventRepository) DeleteEvent(id int64) error {
 	return nil
 }
 
+func (eventRepository *EventRepository) GetEventsWithinBounds(bounds models.BoundingBox, viewerId, organizationId int64) ([]models.Event, error) {
+	longitudeCondition := "longitude BETWEEN ? AND ?"
+
+	//an area crossing the antimeridian wraps around from the max longitude to the min longitude
+	if bounds.MinLongitude > bounds.MaxLongitude {
+		longitudeCondition = "(longitude >= ? OR longitude <= ?)"
+	}
+
+	eventsWithinBoundsSql := `
+	SELECT ` + eventColumns + ` FROM Events
+	WHERE latitude BETWEEN ? AND ? AND ` + longitudeCondition + ` AND ` + eventTenantCondition + ` AND ` + visibleEventsCondition
+
+	statement, err := eventRepository.database.Prepare(eventsWithinBoundsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(
+		bounds.MinLatitude,
+		bounds.MaxLatitude,
+		bounds.MinLongitude,
+		bounds.MaxLongitude,
+		organizationId,
+		viewerId,
+		viewerId,
+		viewerId,
+		time.Now().UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]models.Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Returns the events of the tenant the user is registered for, earliest first
+func (eventRepository *EventRepository) GetEventsByRegistrant(userId, organizationId int64) ([]models.Event, error) {
+	registeredEventsSql := `
+	SELECT ` + eventColumns + ` FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?) AND ` + eventTenantCondition + `
+	ORDER BY date, id`
+
+	statement, err := eventRepository.database.Prepare(registeredEventsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]models.Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Returns the events the user is registered for whatever their tenant, earliest first. A user can
+// only attend one event at a time, whichever organization it belongs to
+func (eventRepository *EventRepository) GetEventsByRegistrantInAllOrganizations(userId int64) ([]models.Event, error) {
+	registeredEventsSql := `
+	SELECT ` + eventColumns + ` FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?)
+	ORDER BY date, id`
+
+	statement, err := eventRepository.database.Prepare(registeredEventsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]models.Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Returns the events of the tenant created by the user, earliest first
+func (eventRepository *EventRepository) GetEventsByOrganizer(userId, organizationId int64) ([]models.Event, error) {
+	organizedEventsSql := `SELECT ` + eventColumns + ` FROM Events WHERE user_id = ? AND ` + eventTenantCondition + ` ORDER BY date, id`
+
+	statement, err := eventRepository.database.Prepare(organizedEventsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]models.Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func scanEvent(scanner rowScanner) (models.Event, error) {
+	var event models.Event
+	var latitude, longitude sql.NullFloat64
+	var endDate sql.NullTime
+	var organizationId sql.NullInt64
+
+	err := scanner.Scan(
+		&event.Id,
+		&event.Name,
+		&event.Description,
+		&event.Location,
+		&event.Date,
+		&event.UserId,
+		&latitude,
+		&longitude,
+		&event.Visibility,
+		&endDate,
+		&organizationId)
+
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	if latitude.Valid && longitude.Valid {
+		event.Latitude = &latitude.Float64
+		event.Longitude = &longitude.Float64
+	}
+
+	if endDate.Valid {
+		event.EndDate = &endDate.Time
+	}
+
+	event.OrganizationId = organizationId.Int64
+
+	return event, nil
+}
+
+// Events of the personal space are stored without an organization
+func nullableOrganizationId(organizationId int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: organizationId, Valid: organizationId != 0}
+}
+
 func NewEventRepository(database *sql.DB) *EventRepository {
 	return &EventRepository{
 		database: database,
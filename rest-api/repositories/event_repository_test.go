@@ -64,8 +64,13 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_PreparesTheSqlStatement(
 	description,
 	location,
 	date,
-	user_id
-	) VALUES (?,?,?,?,?)`).
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
@@ -73,6 +78,11 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_PreparesTheSqlStatement(
 			expectedEvent.Location,
 			expectedEvent.Date,
 			expectedEvent.UserId,
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			sql.NullInt64{},
 		).
 		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
 
@@ -100,8 +110,13 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_ReturnsError() {
 	description,
 	location,
 	date,
-	user_id
-	) VALUES (?,?,?,?,?)`).
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
@@ -109,6 +124,11 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_ReturnsError() {
 			expectedEvent.Location,
 			expectedEvent.Date,
 			expectedEvent.UserId,
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			sql.NullInt64{},
 		).WillReturnError(expectedError)
 
 	err := suite.repository.AddEvent(&expectedEvent)
@@ -138,8 +158,13 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_SetsTheIdToTheDbId() {
 	description,
 	location,
 	date,
-	user_id
-	) VALUES (?,?,?,?,?)`).
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
@@ -147,6 +172,11 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_SetsTheIdToTheDbId() {
 			expectedEvent.Location,
 			expectedEvent.Date,
 			expectedEvent.UserId,
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			sql.NullInt64{},
 		).
 		WillReturnResult(sqlmock.NewResult(expectedId, int64(1)))
 
@@ -176,8 +206,13 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_ReturnsNil() {
 	description,
 	location,
 	date,
-	user_id
-	) VALUES (?,?,?,?,?)`).
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
@@ -185,6 +220,11 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_ReturnsNil() {
 			expectedEvent.Location,
 			expectedEvent.Date,
 			expectedEvent.UserId,
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			sql.NullInt64{},
 		).
 		WillReturnResult(sqlmock.NewResult(expectedId, int64(1)))
 
@@ -196,10 +236,22 @@ func (suite *EventRepositoryUnitTestSuite) TestAddEvent_ReturnsNil() {
 
 func (suite *EventRepositoryUnitTestSuite) TestGetEvents_PreparesTheSqlStatement() {
 
-	suite.dbMock.ExpectQuery("SELECT * FROM Events").
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE " + eventTenantCondition + " AND " + visibleEventsCondition).
 		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
 
-	suite.repository.GetEvents()
+	suite.repository.GetEvents(0, 0)
+}
+
+// The viewer id is used to include the viewer's own events and the private events they were invited to
+func (suite *EventRepositoryUnitTestSuite) TestGetEvents_FiltersByVisibilityForTheViewer() {
+
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE "+eventTenantCondition+" AND "+visibleEventsCondition).
+		WithArgs(int64(3), int64(7), int64(7), int64(7), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+
+	suite.repository.GetEvents(7, 3)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
 }
 
 // When an error occurs when preparing / executing the sql, will return the error
@@ -207,10 +259,10 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEvents_ReturnsError() {
 
 	expectedError := errors.New("test")
 
-	suite.dbMock.ExpectQuery("SELECT * FROM Events").
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE " + eventTenantCondition + " AND " + visibleEventsCondition).
 		WillReturnError(expectedError)
 
-	_, err := suite.repository.GetEvents()
+	_, err := suite.repository.GetEvents(0, 0)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -220,10 +272,10 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEvents_ReturnsError() {
 // When no events exist, default to an empty array
 func (suite *EventRepositoryUnitTestSuite) TestGetEvents_ReturnsEmptyArray() {
 
-	suite.dbMock.ExpectQuery("SELECT * FROM Events").
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE " + eventTenantCondition + " AND " + visibleEventsCondition).
 		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
 
-	rows, _ := suite.repository.GetEvents()
+	rows, _ := suite.repository.GetEvents(0, 0)
 
 	suite.Equal(len(rows), 0)
 
@@ -240,6 +292,7 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEvents_ReturnsEvents() {
 		Location:    "some location",
 		Date:        expectedDate,
 		UserId:      1,
+		Visibility:  models.PUBLIC_VISIBILITY,
 	}
 
 	mockResult := sqlmock.NewRows([]string{
@@ -249,18 +302,28 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEvents_ReturnsEvents() {
 		"location",
 		"date",
 		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
 	}).AddRow(
 		expectedEvent.Id,
 		expectedEvent.Name,
 		expectedEvent.Description,
 		expectedEvent.Location,
 		expectedEvent.Date,
-		expectedEvent.UserId)
-
-	suite.dbMock.ExpectQuery("SELECT * FROM Events").
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		nil,
+		nil)
+
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE " + eventTenantCondition + " AND " + visibleEventsCondition).
 		WillReturnRows(mockResult)
 
-	rows, _ := suite.repository.GetEvents()
+	rows, _ := suite.repository.GetEvents(0, 0)
 
 	suite.NotNil(rows)
 	suite.Equal(1, len(rows))
@@ -272,11 +335,11 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEventById_PreparesTheSqlStatem
 
 	var expectedId int64 = 123
 
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Events WHERE ID = ?`).
+	suite.dbMock.ExpectPrepare(`SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectQuery().
-		WithArgs(expectedId).
+		WithArgs(expectedId, int64(0)).
 		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
-	suite.repository.GetEventById(expectedId)
+	suite.repository.GetEventById(expectedId, 0)
 }
 
 // When an error occurs when preparing / executing the sql, will return the error
@@ -284,12 +347,12 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEventById_ReturnsError() {
 
 	expectedError := errors.New("test")
 
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Events WHERE ID = ?`).
+	suite.dbMock.ExpectPrepare(`SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectQuery().
-		WithArgs(int64(123)).
+		WithArgs(int64(123), int64(0)).
 		WillReturnError(expectedError)
 
-	_, err := suite.repository.GetEventById(int64(123))
+	_, err := suite.repository.GetEventById(int64(123), 0)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -307,6 +370,7 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEventById_ReturnsTheEvent() {
 		Location:    "some location",
 		Date:        expectedDate,
 		UserId:      1,
+		Visibility:  models.PUBLIC_VISIBILITY,
 	}
 
 	mockResult := sqlmock.NewRows([]string{
@@ -316,20 +380,30 @@ func (suite *EventRepositoryUnitTestSuite) TestGetEventById_ReturnsTheEvent() {
 		"location",
 		"date",
 		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
 	}).AddRow(
 		expectedEvent.Id,
 		expectedEvent.Name,
 		expectedEvent.Description,
 		expectedEvent.Location,
 		expectedEvent.Date,
-		expectedEvent.UserId)
-
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Events WHERE ID = ?`).
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		nil,
+		nil)
+
+	suite.dbMock.ExpectPrepare(`SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectQuery().
-		WithArgs(int64(123)).
+		WithArgs(int64(123), int64(0)).
 		WillReturnRows(mockResult)
 
-	event, _ := suite.repository.GetEventById(int64(123))
+	event, _ := suite.repository.GetEventById(int64(123), 0)
 
 	suite.NotNil(event)
 	suite.Equal(&expectedEvent, event)
@@ -352,18 +426,22 @@ func (suite *EventRepositoryUnitTestSuite) TestUpdateEvent_PreparesTheSqlStateme
 	}
 
 	suite.dbMock.ExpectPrepare(`UPDATE Events
-	SET name = ?, description = ?, location = ?, date = ?, user_id = ?
-	WHERE ID = ?`).
+	SET name = ?, description = ?, location = ?, date = ?, latitude = ?, longitude = ?, visibility = ?, end_date = ?
+	WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
-			expectedEvent.Date,
+			expectedEvent.Description,
 			expectedEvent.Location,
 			expectedEvent.Date,
-			expectedEvent.UserId,
-			expectedId).
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			expectedId,
+			int64(0)).
 		WillReturnResult(sqlmock.NewResult(int64(12), int64(1)))
-	suite.repository.UpdateEvent(expectedId, expectedEvent)
+	suite.repository.UpdateEvent(expectedId, 0, expectedEvent)
 }
 
 // When an error occurs when preparing / executing the sql, will return the error
@@ -385,18 +463,22 @@ func (suite *EventRepositoryUnitTestSuite) TestUpdateEvent_ReturnsError() {
 	}
 
 	suite.dbMock.ExpectPrepare(`UPDATE Events
-	SET name = ?, description = ?, location = ?, date = ?, user_id = ?
-	WHERE ID = ?`).
+	SET name = ?, description = ?, location = ?, date = ?, latitude = ?, longitude = ?, visibility = ?, end_date = ?
+	WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
 			expectedEvent.Description,
 			expectedEvent.Location,
 			expectedEvent.Date,
-			expectedEvent.UserId,
-			expectedId).
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			expectedId,
+			int64(0)).
 		WillReturnError(expectedError)
-	err := suite.repository.UpdateEvent(expectedId, expectedEvent)
+	err := suite.repository.UpdateEvent(expectedId, 0, expectedEvent)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -419,18 +501,22 @@ func (suite *EventRepositoryUnitTestSuite) TestUpdateEvent_ReturnsNil() {
 	}
 
 	suite.dbMock.ExpectPrepare(`UPDATE Events
-	SET name = ?, description = ?, location = ?, date = ?, user_id = ?
-	WHERE ID = ?`).
+	SET name = ?, description = ?, location = ?, date = ?, latitude = ?, longitude = ?, visibility = ?, end_date = ?
+	WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEvent.Name,
 			expectedEvent.Description,
 			expectedEvent.Location,
 			expectedEvent.Date,
-			expectedEvent.UserId,
-			expectedId).
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			expectedId,
+			int64(0)).
 		WillReturnResult(sqlmock.NewResult(int64(123), int64(2)))
-	err := suite.repository.UpdateEvent(expectedId, expectedEvent)
+	err := suite.repository.UpdateEvent(expectedId, 0, expectedEvent)
 
 	suite.Nil(err)
 
@@ -440,12 +526,13 @@ func (suite *EventRepositoryUnitTestSuite) TestDeleteEvent_PreparesTheSqlStateme
 
 	var expectedId int64 = 123
 
-	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ?`).
+	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedId).
+			expectedId,
+			int64(0)).
 		WillReturnResult(sqlmock.NewResult(int64(12), int64(1)))
-	suite.repository.DeleteEvent(expectedId)
+	suite.repository.DeleteEvent(expectedId, 0)
 }
 
 // When an error occurs when preparing / executing the sql, will return the error
@@ -455,13 +542,14 @@ func (suite *EventRepositoryUnitTestSuite) TestDeleteEvent_ReturnsError() {
 
 	var expectedId int64 = 123
 
-	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ?`).
+	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedId).
+			expectedId,
+			int64(0)).
 		WillReturnError(expectedError)
 
-	err := suite.repository.DeleteEvent(expectedId)
+	err := suite.repository.DeleteEvent(expectedId, 0)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -471,14 +559,428 @@ func (suite *EventRepositoryUnitTestSuite) TestDeleteEvent_ReturnsNil() {
 
 	var expectedId int64 = 123
 
-	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ?`).
+	suite.dbMock.ExpectPrepare(`DELETE FROM Events WHERE ID = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedId).
+			expectedId,
+			int64(0)).
 		WillReturnResult(sqlmock.NewResult(int64(12), int64(1)))
 
-	err := suite.repository.DeleteEvent(expectedId)
+	err := suite.repository.DeleteEvent(expectedId, 0)
 
 	suite.Nil(err)
 
 }
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsWithinBounds_PreparesTheSqlStatement() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? AND `+eventTenantCondition+` AND `+visibleEventsCondition).
+		ExpectQuery().
+		WithArgs(1.0, 2.0, 3.0, 4.0, int64(0), int64(5), int64(5), int64(5), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+
+	suite.repository.GetEventsWithinBounds(models.BoundingBox{
+		MinLatitude:  1,
+		MaxLatitude:  2,
+		MinLongitude: 3,
+		MaxLongitude: 4,
+	}, 5, 0)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When the area crosses the antimeridian, the longitude range has to wrap around
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsWithinBoundsAcrossAntimeridian_WrapsTheLongitude() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE latitude BETWEEN ? AND ? AND (longitude >= ? OR longitude <= ?) AND `+eventTenantCondition+` AND `+visibleEventsCondition).
+		ExpectQuery().
+		WithArgs(1.0, 2.0, 179.0, -179.0, int64(0), int64(0), int64(0), int64(0), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+
+	suite.repository.GetEventsWithinBounds(models.BoundingBox{
+		MinLatitude:  1,
+		MaxLatitude:  2,
+		MinLongitude: 179,
+		MaxLongitude: -179,
+	}, 0, 0)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsWithinBounds_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? AND ` + eventTenantCondition + ` AND ` + visibleEventsCondition).
+		ExpectQuery().
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.GetEventsWithinBounds(models.BoundingBox{}, 0, 0)
+
+	suite.NotNil(err)
+	suite.Equal(expectedError, err)
+}
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsWithinBounds_ReturnsEventsWithCoordinates() {
+
+	expectedDate, _ := time.Parse(time.RFC3339, "1990-01-01T00:00:00.000Z")
+
+	latitude, longitude := 52.52, 13.405
+
+	expectedEvent := models.Event{
+		Id:          123,
+		Name:        "some name",
+		Description: "some description",
+		Location:    "Berlin",
+		Date:        expectedDate,
+		UserId:      1,
+		Latitude:    &latitude,
+		Longitude:   &longitude,
+		Visibility:  models.PUBLIC_VISIBILITY,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"name",
+		"description",
+		"location",
+		"date",
+		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
+	}).AddRow(
+		expectedEvent.Id,
+		expectedEvent.Name,
+		expectedEvent.Description,
+		expectedEvent.Location,
+		expectedEvent.Date,
+		expectedEvent.UserId,
+		latitude,
+		longitude,
+		expectedEvent.Visibility,
+		nil,
+		nil)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? AND ` + eventTenantCondition + ` AND ` + visibleEventsCondition).
+		ExpectQuery().
+		WillReturnRows(mockResult)
+
+	events, err := suite.repository.GetEventsWithinBounds(models.BoundingBox{}, 0, 0)
+
+	suite.Nil(err)
+	suite.Equal([]models.Event{expectedEvent}, events)
+}
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsByRegistrant_ReturnsTheRegisteredEvents() {
+
+	expectedDate := time.Now()
+	expectedEndDate := expectedDate.Add(2 * time.Hour)
+
+	expectedEvent := models.Event{
+		Id:          1,
+		Name:        "Test",
+		Description: "Test",
+		Location:    "Test",
+		Date:        expectedDate,
+		EndDate:     &expectedEndDate,
+		UserId:      1,
+		Visibility:  models.PUBLIC_VISIBILITY,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"name",
+		"description",
+		"location",
+		"date",
+		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
+	}).AddRow(
+		expectedEvent.Id,
+		expectedEvent.Name,
+		expectedEvent.Description,
+		expectedEvent.Location,
+		expectedEvent.Date,
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		expectedEndDate,
+		nil)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?) AND `+eventTenantCondition+`
+	ORDER BY date, id`).
+		ExpectQuery().
+		WithArgs(int64(12), int64(0)).
+		WillReturnRows(mockResult)
+
+	events, err := suite.repository.GetEventsByRegistrant(12, 0)
+
+	suite.Nil(err)
+	suite.Equal([]models.Event{expectedEvent}, events)
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsByRegistrant_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?) AND ` + eventTenantCondition + `
+	ORDER BY date, id`).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.GetEventsByRegistrant(12, 0)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsByRegistrantInAllOrganizations_ReturnsTheRegisteredEvents() {
+
+	expectedDate := time.Now()
+
+	expectedEvent := models.Event{
+		Id:             1,
+		Name:           "Test",
+		Description:    "Test",
+		Location:       "Test",
+		Date:           expectedDate,
+		UserId:         1,
+		Visibility:     models.PUBLIC_VISIBILITY,
+		OrganizationId: 4,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"name",
+		"description",
+		"location",
+		"date",
+		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
+	}).AddRow(
+		expectedEvent.Id,
+		expectedEvent.Name,
+		expectedEvent.Description,
+		expectedEvent.Location,
+		expectedEvent.Date,
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		nil,
+		expectedEvent.OrganizationId)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?)
+	ORDER BY date, id`).
+		ExpectQuery().
+		WithArgs(int64(12)).
+		WillReturnRows(mockResult)
+
+	events, err := suite.repository.GetEventsByRegistrantInAllOrganizations(12)
+
+	suite.Nil(err)
+	suite.Equal([]models.Event{expectedEvent}, events)
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsByRegistrantInAllOrganizations_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE id IN (SELECT event_id FROM Registrations WHERE user_id = ?)
+	ORDER BY date, id`).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.GetEventsByRegistrantInAllOrganizations(12)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsByOrganizer_ReturnsTheOrganizedEvents() {
+
+	expectedEvent := models.Event{
+		Id:          1,
+		Name:        "Test",
+		Description: "Test",
+		Location:    "Test",
+		Date:        time.Now(),
+		UserId:      12,
+		Visibility:  models.PRIVATE_VISIBILITY,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"name",
+		"description",
+		"location",
+		"date",
+		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
+	}).AddRow(
+		expectedEvent.Id,
+		expectedEvent.Name,
+		expectedEvent.Description,
+		expectedEvent.Location,
+		expectedEvent.Date,
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		nil,
+		nil)
+
+	suite.dbMock.ExpectPrepare(`SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE user_id = ? AND `+eventTenantCondition+` ORDER BY date, id`).
+		ExpectQuery().
+		WithArgs(int64(12), int64(0)).
+		WillReturnRows(mockResult)
+
+	events, err := suite.repository.GetEventsByOrganizer(12, 0)
+
+	suite.Nil(err)
+	suite.Equal([]models.Event{expectedEvent}, events)
+}
+
+// Events of an organization are stored with it, so they stay out of the personal space
+func (suite *EventRepositoryUnitTestSuite) TestAddEventOfAnOrganization_StoresTheOrganization() {
+
+	expectedEvent := models.Event{
+		Name:           "some name",
+		Description:    "some description",
+		Location:       "some location",
+		Date:           time.Now(),
+		UserId:         1,
+		OrganizationId: 4,
+	}
+
+	suite.dbMock.ExpectPrepare(`INSERT INTO Events (
+	name,
+	description,
+	location,
+	date,
+	user_id,
+	latitude,
+	longitude,
+	visibility,
+	end_date,
+	organization_id
+	) VALUES (?,?,?,?,?,?,?,?,?,?)`).
+		ExpectExec().
+		WithArgs(
+			expectedEvent.Name,
+			expectedEvent.Description,
+			expectedEvent.Location,
+			expectedEvent.Date,
+			expectedEvent.UserId,
+			expectedEvent.Latitude,
+			expectedEvent.Longitude,
+			expectedEvent.Visibility,
+			expectedEvent.EndDate,
+			sql.NullInt64{Int64: 4, Valid: true},
+		).
+		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
+
+	err := suite.repository.AddEvent(&expectedEvent)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// The events of an organization are only listed within that organization
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsOfAnOrganization_ReturnsTheOrganizationEvents() {
+
+	expectedEvent := models.Event{
+		Id:             123,
+		Name:           "some name",
+		Description:    "some description",
+		Location:       "some location",
+		Date:           time.Now(),
+		UserId:         1,
+		Visibility:     models.PUBLIC_VISIBILITY,
+		OrganizationId: 4,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"name",
+		"description",
+		"location",
+		"date",
+		"user_id",
+		"latitude",
+		"longitude",
+		"visibility",
+		"end_date",
+		"organization_id",
+	}).AddRow(
+		expectedEvent.Id,
+		expectedEvent.Name,
+		expectedEvent.Description,
+		expectedEvent.Location,
+		expectedEvent.Date,
+		expectedEvent.UserId,
+		nil,
+		nil,
+		expectedEvent.Visibility,
+		nil,
+		int64(4))
+
+	suite.dbMock.ExpectQuery("SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events WHERE "+eventTenantCondition+" AND "+visibleEventsCondition).
+		WithArgs(int64(4), int64(7), int64(7), int64(7), sqlmock.AnyArg()).
+		WillReturnRows(mockResult)
+
+	events, err := suite.repository.GetEvents(7, 4)
+
+	suite.Nil(err)
+	suite.Equal([]models.Event{expectedEvent}, events)
+}
+
+func (suite *EventRepositoryUnitTestSuite) TestGetEventsWithinBoundsOfAnOrganization_IsScopedToTheOrganization() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, name, description, location, date, user_id, latitude, longitude, visibility, end_date, organization_id FROM Events
+	WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? AND `+eventTenantCondition+` AND `+visibleEventsCondition).
+		ExpectQuery().
+		WithArgs(1.0, 2.0, 3.0, 4.0, int64(4), int64(5), int64(5), int64(5), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(make([]string, 0)))
+
+	suite.repository.GetEventsWithinBounds(models.BoundingBox{
+		MinLatitude:  1,
+		MaxLatitude:  2,
+		MinLongitude: 3,
+		MaxLongitude: 4,
+	}, 5, 4)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
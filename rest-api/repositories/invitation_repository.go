@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/constants"
+	"example.com/models"
+)
+
+const invitationColumns = `id, event_id, email, user_id, token_hash, expires_at, created_at, accepted_at, revoked_at`
+
+// Matches events where the user accepted an invitation, or was invited by email and the
+// invitation has not expired yet. Expects the user id twice, followed by the current time
+const invitedCondition = `EXISTS (
+	SELECT 1 FROM Invitations
+	WHERE Invitations.event_id = Events.id
+	AND Invitations.revoked_at IS NULL
+	AND (
+		Invitations.user_id = ?
+		OR (Invitations.email = (SELECT email FROM Users WHERE Users.id = ?) AND Invitations.expires_at > ?)
+	))`
+
+type InvitationRepository struct {
+	database *sql.DB
+}
+
+func (invitationRepository InvitationRepository) CreateInvitation(invitation *models.Invitation) error {
+	createInvitationSql := `
+	INSERT INTO Invitations(event_id, email, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	statement, err := invitationRepository.database.Prepare(createInvitationSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		invitation.EventId,
+		invitation.Email,
+		invitation.TokenHash,
+		invitation.ExpiresAt,
+		invitation.CreatedAt)
+
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	id, _ := result.LastInsertId()
+
+	invitation.Id = id
+
+	return nil
+}
+
+func (invitationRepository InvitationRepository) GetInvitationsByEventId(eventId int64) ([]models.Invitation, error) {
+	invitationsByEventSql := `SELECT ` + invitationColumns + ` FROM Invitations WHERE event_id = ? ORDER BY id`
+
+	statement, err := invitationRepository.database.Prepare(invitationsByEventSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(eventId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	invitations := make([]models.Invitation, 0)
+
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+// Returns an invitation with an id of 0 when no invitation matches the token
+func (invitationRepository InvitationRepository) GetInvitationByTokenHash(eventId int64, tokenHash string) (*models.Invitation, error) {
+	invitationByTokenSql := `SELECT ` + invitationColumns + ` FROM Invitations WHERE event_id = ? AND token_hash = ?`
+
+	statement, err := invitationRepository.database.Prepare(invitationByTokenSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	invitation, err := scanInvitation(statement.QueryRow(eventId, tokenHash))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.Invitation{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (invitationRepository InvitationRepository) AcceptInvitation(id, userId int64, acceptedAt time.Time) error {
+	acceptInvitationSql := `
+	UPDATE Invitations
+	SET user_id = ?, accepted_at = ?
+	WHERE id = ?`
+
+	statement, err := invitationRepository.database.Prepare(acceptInvitationSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(userId, acceptedAt, id)
+
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+func (invitationRepository InvitationRepository) RevokeInvitation(eventId, id int64, revokedAt time.Time) error {
+	revokeInvitationSql := `
+	UPDATE Invitations
+	SET revoked_at = ?
+	WHERE id = ? AND event_id = ? AND revoked_at IS NULL`
+
+	statement, err := invitationRepository.database.Prepare(revokeInvitationSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(revokedAt, id, eventId)
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return errors.New(constants.NO_INVITATION_FOR_ID_ERROR)
+	}
+
+	return nil
+}
+
+func (invitationRepository InvitationRepository) IsInvited(eventId, userId int64) (bool, error) {
+	isInvitedSql := `SELECT COUNT(*) FROM Events WHERE Events.id = ? AND ` + invitedCondition
+
+	statement, err := invitationRepository.database.Prepare(isInvitedSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(eventId, userId, userId, time.Now().UTC()).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func scanInvitation(scanner rowScanner) (models.Invitation, error) {
+	var invitation models.Invitation
+	var email sql.NullString
+	var userId sql.NullInt64
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&invitation.Id,
+		&invitation.EventId,
+		&email,
+		&userId,
+		&invitation.TokenHash,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+		&acceptedAt,
+		&revokedAt)
+
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	if email.Valid {
+		invitation.Email = &email.String
+	}
+
+	if userId.Valid {
+		invitation.UserId = &userId.Int64
+	}
+
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+
+	if revokedAt.Valid {
+		invitation.RevokedAt = &revokedAt.Time
+	}
+
+	return invitation, nil
+}
+
+func NewInvitationRepository(database *sql.DB) *InvitationRepository {
+	return &InvitationRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type InvitationRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *InvitationRepository
+}
+
+func TestInvitationRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &InvitationRepositoryUnitTestSuite{})
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewInvitationRepository(db)
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TestCreateInvitation_SetsTheIdToTheDbId() {
+
+	email := "invitee@test.com"
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	invitation := models.Invitation{
+		EventId:   1,
+		Email:     &email,
+		TokenHash: "some hash",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Invitations(event_id, email, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(int64(1), &email, "some hash", now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
+
+	err := suite.repository.CreateInvitation(&invitation)
+
+	suite.Nil(err)
+	suite.Equal(int64(10), invitation.Id)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *InvitationRepositoryUnitTestSuite) TestCreateInvitation_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Invitations(event_id, email, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WillReturnError(expectedError)
+
+	err := suite.repository.CreateInvitation(&models.Invitation{})
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TestGetInvitationsByEventId_ReturnsTheInvitations() {
+
+	userId := int64(12)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"event_id",
+		"email",
+		"user_id",
+		"token_hash",
+		"expires_at",
+		"created_at",
+		"accepted_at",
+		"revoked_at",
+	}).AddRow(3, 1, nil, userId, "some hash", now, now, now, nil)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + invitationColumns + ` FROM Invitations WHERE event_id = ? ORDER BY id`).
+		ExpectQuery().
+		WithArgs(int64(1)).
+		WillReturnRows(mockResult)
+
+	invitations, err := suite.repository.GetInvitationsByEventId(1)
+
+	suite.Nil(err)
+	suite.Equal([]models.Invitation{
+		{
+			Id:         3,
+			EventId:    1,
+			UserId:     &userId,
+			TokenHash:  "some hash",
+			ExpiresAt:  now,
+			CreatedAt:  now,
+			AcceptedAt: &now,
+		},
+	}, invitations)
+}
+
+// When no invitation matches the token, an empty invitation is returned
+func (suite *InvitationRepositoryUnitTestSuite) TestGetInvitationByTokenHash_ReturnsEmptyInvitation() {
+
+	suite.dbMock.ExpectPrepare(`SELECT `+invitationColumns+` FROM Invitations WHERE event_id = ? AND token_hash = ?`).
+		ExpectQuery().
+		WithArgs(int64(1), "some hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	invitation, err := suite.repository.GetInvitationByTokenHash(1, "some hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.Invitation{}, invitation)
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TestAcceptInvitation_PreparesTheSqlStatement() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Invitations
+	SET user_id = ?, accepted_at = ?
+	WHERE id = ?`).
+		ExpectExec().
+		WithArgs(int64(12), now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.AcceptInvitation(3, 12, now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When no invitation was revoked, either it does not exist for the event or it was already revoked
+func (suite *InvitationRepositoryUnitTestSuite) TestRevokeInvitationNotFound_ReturnsError() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Invitations
+	SET revoked_at = ?
+	WHERE id = ? AND event_id = ? AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, int64(3), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repository.RevokeInvitation(1, 3, now)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_INVITATION_FOR_ID_ERROR, err.Error())
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TestRevokeInvitation_ReturnsNil() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Invitations
+	SET revoked_at = ?
+	WHERE id = ? AND event_id = ? AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, int64(3), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.RevokeInvitation(1, 3, now)
+
+	suite.Nil(err)
+}
+
+func (suite *InvitationRepositoryUnitTestSuite) TestIsInvited_ReturnsWhetherAnInvitationExists() {
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM Events WHERE Events.id = ? AND `+invitedCondition).
+		ExpectQuery().
+		WithArgs(int64(1), int64(12), int64(12), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	invited, err := suite.repository.IsInvited(1, 12)
+
+	suite.Nil(err)
+	suite.True(invited)
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *InvitationRepositoryUnitTestSuite) TestIsInvited_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM Events WHERE Events.id = ? AND ` + invitedCondition).
+		ExpectQuery().
+		WillReturnError(expectedError)
+
+	invited, err := suite.repository.IsInvited(1, 12)
+
+	suite.False(invited)
+	suite.Equal(expectedError, err)
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+type LoginThrottleRepository struct {
+	database *sql.DB
+}
+
+// Returns a throttle without failures when none were recorded for the key
+func (loginThrottleRepository LoginThrottleRepository) GetLoginThrottle(key string) (*models.LoginThrottle, error) {
+	loginThrottleSql := `SELECT key, failures, last_failure_at, locked_until FROM LoginThrottles WHERE key = ?`
+
+	statement, err := loginThrottleRepository.database.Prepare(loginThrottleSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	throttle, err := scanLoginThrottle(statement.QueryRow(key))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.LoginThrottle{Key: key}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+// Counts a failed attempt in a single statement, so concurrent attempts cannot be lost. Failures
+// recorded before windowStart are forgotten and counting starts over
+func (loginThrottleRepository LoginThrottleRepository) RecordLoginFailure(key string, failedAt, windowStart time.Time) (*models.LoginThrottle, error) {
+	recordFailureSql := `
+	INSERT INTO LoginThrottles(key, failures, last_failure_at)
+	VALUES (?, 1, ?)
+	ON CONFLICT(key) DO UPDATE SET
+		failures = CASE WHEN LoginThrottles.last_failure_at < ? THEN 1 ELSE LoginThrottles.failures + 1 END,
+		last_failure_at = excluded.last_failure_at
+	RETURNING key, failures, last_failure_at, locked_until`
+
+	statement, err := loginThrottleRepository.database.Prepare(recordFailureSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	throttle, err := scanLoginThrottle(statement.QueryRow(key, failedAt, windowStart))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+// Locks the key until the date, failures start over once the lock expires
+func (loginThrottleRepository LoginThrottleRepository) LockLogin(key string, lockedUntil time.Time) error {
+	return loginThrottleRepository.exec(`UPDATE LoginThrottles SET failures = 0, locked_until = ? WHERE key = ?`, lockedUntil, key)
+}
+
+func (loginThrottleRepository LoginThrottleRepository) DeleteLoginThrottle(key string) error {
+	return loginThrottleRepository.exec(`DELETE FROM LoginThrottles WHERE key = ?`, key)
+}
+
+func (loginThrottleRepository LoginThrottleRepository) exec(query string, args ...any) error {
+	statement, err := loginThrottleRepository.database.Prepare(query)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(args...)
+
+	return err
+}
+
+func scanLoginThrottle(scanner rowScanner) (models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	var lockedUntil sql.NullTime
+
+	err := scanner.Scan(
+		&throttle.Key,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&lockedUntil)
+
+	if err != nil {
+		return models.LoginThrottle{}, err
+	}
+
+	if lockedUntil.Valid {
+		throttle.LockedUntil = &lockedUntil.Time
+	}
+
+	return throttle, nil
+}
+
+func NewLoginThrottleRepository(database *sql.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{
+		database: database,
+	}
+}
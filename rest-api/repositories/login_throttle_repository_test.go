@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoginThrottleRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *LoginThrottleRepository
+}
+
+func TestLoginThrottleRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &LoginThrottleRepositoryUnitTestSuite{})
+}
+
+func (suite *LoginThrottleRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewLoginThrottleRepository(db)
+}
+
+func (suite *LoginThrottleRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// When no failure was recorded for the key, a throttle without failures is returned
+func (suite *LoginThrottleRepositoryUnitTestSuite) TestGetLoginThrottle_ReturnsEmptyThrottle() {
+
+	suite.dbMock.ExpectPrepare(`SELECT key, failures, last_failure_at, locked_until FROM LoginThrottles WHERE key = ?`).
+		ExpectQuery().
+		WithArgs("account:test@test.com").
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	throttle, err := suite.repository.GetLoginThrottle("account:test@test.com")
+
+	suite.Nil(err)
+	suite.Equal(&models.LoginThrottle{Key: "account:test@test.com"}, throttle)
+}
+
+func (suite *LoginThrottleRepositoryUnitTestSuite) TestRecordLoginFailure_ReturnsTheCountedFailures() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	windowStart := now.Add(-time.Hour)
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO LoginThrottles(key, failures, last_failure_at)
+	VALUES (?, 1, ?)
+	ON CONFLICT(key) DO UPDATE SET
+		failures = CASE WHEN LoginThrottles.last_failure_at < ? THEN 1 ELSE LoginThrottles.failures + 1 END,
+		last_failure_at = excluded.last_failure_at
+	RETURNING key, failures, last_failure_at, locked_until`).
+		ExpectQuery().
+		WithArgs("ip:127.0.0.1", now, windowStart).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failure_at", "locked_until"}).
+			AddRow("ip:127.0.0.1", 4, now, nil))
+
+	throttle, err := suite.repository.RecordLoginFailure("ip:127.0.0.1", now, windowStart)
+
+	suite.Nil(err)
+	suite.Equal(4, throttle.Failures)
+	suite.Nil(throttle.LockedUntil)
+}
+
+func (suite *LoginThrottleRepositoryUnitTestSuite) TestLockLogin_ResetsTheFailures() {
+
+	lockedUntil := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE LoginThrottles SET failures = 0, locked_until = ? WHERE key = ?`).
+		ExpectExec().
+		WithArgs(lockedUntil, "account:test@test.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.LockLogin("account:test@test.com", lockedUntil)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/constants"
+	"example.com/models"
+)
+
+// The initial schema adopts the databases created at startup before migrations were introduced
+const initialSchemaVersion = 1
+
+// Columns added to existing tables at startup before migrations were introduced. CREATE TABLE IF NOT
+// EXISTS leaves older tables as they are, so the initial schema is preceded by adding the missing ones
+var legacyColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"Users", "role", "TEXT NOT NULL DEFAULT 'user'"},
+	{"Users", "disabled_at", "DATETIME"},
+	{"Users", "verified_at", "DATETIME"},
+	{"Users", "verification_sent_at", "DATETIME"},
+	{"Events", "latitude", "REAL"},
+	{"Events", "longitude", "REAL"},
+	{"Events", "visibility", "TEXT NOT NULL DEFAULT 'public'"},
+	{"Events", "end_date", "DATETIME"},
+	{"Events", "organization_id", "INTEGER REFERENCES Organizations(id)"},
+	{"Sessions", "organization_id", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+type MigrationRepository struct {
+	database *sql.DB
+}
+
+// Creates the bookkeeping tables of the migrations, the lock table holds at most one row, present
+// while a process migrates the database
+func (migrationRepository MigrationRepository) CreateMigrationTables() error {
+	createSchemaMigrationsTableSql := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`
+
+	_, err := migrationRepository.database.Exec(createSchemaMigrationsTableSql)
+
+	if err != nil {
+		return err
+	}
+
+	createSchemaMigrationsLockTableSql := `
+	CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		locked_at DATETIME NOT NULL
+	)`
+
+	_, err = migrationRepository.database.Exec(createSchemaMigrationsLockTableSql)
+
+	return err
+}
+
+// Takes the lock, unless another process holds it since staleBefore or later. Older locks were
+// left behind by a process that died while migrating and are taken over
+func (migrationRepository MigrationRepository) AcquireMigrationLock(lockedAt time.Time, staleBefore time.Time) error {
+	transaction, err := migrationRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	_, err = transaction.Exec(`DELETE FROM schema_migrations_lock WHERE locked_at < ?`, staleBefore)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := transaction.Exec(
+		`INSERT INTO schema_migrations_lock(id, locked_at) VALUES (1, ?) ON CONFLICT(id) DO NOTHING`,
+		lockedAt)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New(constants.MIGRATION_LOCKED_ERROR)
+	}
+
+	return transaction.Commit()
+}
+
+// Releases the lock taken at lockedAt. A lock taken over by another process since then is left to
+// that process
+func (migrationRepository MigrationRepository) ReleaseMigrationLock(lockedAt time.Time) error {
+	_, err := migrationRepository.database.Exec(`DELETE FROM schema_migrations_lock WHERE locked_at = ?`, lockedAt)
+
+	return err
+}
+
+// Ordered by version
+func (migrationRepository MigrationRepository) GetAppliedMigrations() ([]models.AppliedMigration, error) {
+	appliedMigrationsSql := `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`
+
+	statement, err := migrationRepository.database.Prepare(appliedMigrationsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var migrations []models.AppliedMigration
+
+	for rows.Next() {
+		var migration models.AppliedMigration
+
+		err = rows.Scan(&migration.Version, &migration.Name, &migration.Checksum, &migration.AppliedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, rows.Err()
+}
+
+// Runs the migration and records it in the same transaction, a failing migration leaves the schema
+// untouched
+func (migrationRepository MigrationRepository) ApplyMigration(migration models.Migration, appliedAt time.Time) error {
+	transaction, err := migrationRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	if migration.Version == initialSchemaVersion {
+		err = addLegacyColumns(transaction)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = transaction.Exec(migration.Up)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = transaction.Exec(
+		`INSERT INTO schema_migrations(version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		migration.Version,
+		migration.Name,
+		migration.Checksum,
+		appliedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+func (migrationRepository MigrationRepository) RevertMigration(migration models.Migration) error {
+	transaction, err := migrationRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	_, err = transaction.Exec(migration.Down)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = transaction.Exec(`DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+
+	if err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+// Adds the legacy columns missing from the tables of an existing database, tables that do not exist
+// yet are left to the initial schema
+func addLegacyColumns(transaction *sql.Tx) error {
+	for _, legacyColumn := range legacyColumns {
+		columns, err := getColumns(transaction, legacyColumn.table)
+
+		if err != nil {
+			return err
+		}
+
+		if len(columns) == 0 || columns[legacyColumn.column] {
+			continue
+		}
+
+		_, err = transaction.Exec(fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v %v", legacyColumn.table, legacyColumn.column, legacyColumn.definition))
+
+		if err != nil {
+			return err
+		}
+
+		//users who signed up before verification existed are trusted rather than locked out
+		if legacyColumn.table == "Users" && legacyColumn.column == "verified_at" {
+			_, err = transaction.Exec(`UPDATE Users SET verified_at = CURRENT_TIMESTAMP`)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Names of the columns of the table, none when the table does not exist
+func getColumns(transaction *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := transaction.Query(`SELECT name FROM pragma_table_info(?)`, table)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+
+	for rows.Next() {
+		var name string
+
+		err = rows.Scan(&name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		columns[name] = true
+	}
+
+	return columns, rows.Err()
+}
+
+func NewMigrationRepository(database *sql.DB) *MigrationRepository {
+	return &MigrationRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrationRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *MigrationRepository
+}
+
+func TestMigrationRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &MigrationRepositoryUnitTestSuite{})
+}
+
+func (suite *MigrationRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewMigrationRepository(db)
+}
+
+func (suite *MigrationRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+const (
+	deleteStaleMigrationLockSql = `DELETE FROM schema_migrations_lock WHERE locked_at < ?`
+	insertMigrationLockSql      = `INSERT INTO schema_migrations_lock(id, locked_at) VALUES (1, ?) ON CONFLICT(id) DO NOTHING`
+)
+
+func (suite *MigrationRepositoryUnitTestSuite) TestAcquireMigrationLock_TakesTheLock() {
+
+	lockedAt := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+	staleBefore := lockedAt.Add(-time.Hour)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(deleteStaleMigrationLockSql).
+		WithArgs(staleBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(insertMigrationLockSql).
+		WithArgs(lockedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.AcquireMigrationLock(lockedAt, staleBefore)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When another process holds the lock, return a locked error
+func (suite *MigrationRepositoryUnitTestSuite) TestAcquireMigrationLockHeld_ReturnsAnError() {
+
+	lockedAt := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+	staleBefore := lockedAt.Add(-time.Hour)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(deleteStaleMigrationLockSql).
+		WithArgs(staleBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(insertMigrationLockSql).
+		WithArgs(lockedAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.AcquireMigrationLock(lockedAt, staleBefore)
+
+	suite.Equal(constants.MIGRATION_LOCKED_ERROR, err.Error())
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *MigrationRepositoryUnitTestSuite) TestReleaseMigrationLock_DeletesTheLockTakenAtThatTime() {
+
+	lockedAt := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectExec(`DELETE FROM schema_migrations_lock WHERE locked_at = ?`).
+		WithArgs(lockedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.ReleaseMigrationLock(lockedAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *MigrationRepositoryUnitTestSuite) TestGetAppliedMigrations_ReturnsTheMigrations() {
+
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`).
+		ExpectQuery().
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"}).
+			AddRow(1, "initial_schema", "checksum", appliedAt))
+
+	migrations, err := suite.repository.GetAppliedMigrations()
+
+	suite.Nil(err)
+	suite.Equal([]models.AppliedMigration{
+		{Version: 1, Name: "initial_schema", Checksum: "checksum", AppliedAt: appliedAt},
+	}, migrations)
+}
+
+// The migration and its record are committed together
+func (suite *MigrationRepositoryUnitTestSuite) TestApplyMigration_RecordsTheMigration() {
+
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE TABLE Test (id INTEGER)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(`INSERT INTO schema_migrations(version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`).
+		WithArgs(int64(2), "test", "checksum", appliedAt).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.ApplyMigration(models.Migration{
+		Version:  2,
+		Name:     "test",
+		Up:       `CREATE TABLE Test (id INTEGER)`,
+		Checksum: "checksum",
+	}, appliedAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When the migration fails, nothing is recorded
+func (suite *MigrationRepositoryUnitTestSuite) TestApplyMigrationFailing_RollsBack() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE TABLE Test (id INTEGER)`).WillReturnError(expectedError)
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.ApplyMigration(models.Migration{
+		Version: 2,
+		Name:    "test",
+		Up:      `CREATE TABLE Test (id INTEGER)`,
+	}, time.Now())
+
+	suite.Equal(expectedError, err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *MigrationRepositoryUnitTestSuite) TestRevertMigration_RemovesTheRecord() {
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`DROP TABLE Test`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(`DELETE FROM schema_migrations WHERE version = ?`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.RevertMigration(models.Migration{
+		Version: 2,
+		Name:    "test",
+		Down:    `DROP TABLE Test`,
+	})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+type OidcRepository struct {
+	database *sql.DB
+}
+
+func (oidcRepository OidcRepository) CreateOidcLoginState(state *models.OidcLoginState) error {
+	createStateSql := `
+	INSERT INTO OidcLoginStates(provider, state_hash, nonce, code_verifier, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	statement, err := oidcRepository.database.Prepare(createStateSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(state.Provider, state.StateHash, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	state.Id = id
+
+	return nil
+}
+
+// Deletes the state while reading it, so a state can only be used once. Returns a state with an
+// id of 0 when no state matches the hash
+func (oidcRepository OidcRepository) ConsumeOidcLoginState(stateHash string) (*models.OidcLoginState, error) {
+	consumeStateSql := `
+	DELETE FROM OidcLoginStates
+	WHERE state_hash = ?
+	RETURNING id, provider, state_hash, nonce, code_verifier, expires_at, created_at`
+
+	statement, err := oidcRepository.database.Prepare(consumeStateSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var state models.OidcLoginState
+
+	err = statement.QueryRow(stateHash).Scan(
+		&state.Id,
+		&state.Provider,
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.CreatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.OidcLoginState{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Removes the states of logins that were abandoned
+func (oidcRepository OidcRepository) DeleteExpiredOidcLoginStates(now time.Time) error {
+	deleteExpiredSql := `DELETE FROM OidcLoginStates WHERE expires_at <= ?`
+
+	statement, err := oidcRepository.database.Prepare(deleteExpiredSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(now)
+
+	return err
+}
+
+// Returns an identity with an id of 0 when the account of the provider is not linked to a user
+func (oidcRepository OidcRepository) GetUserIdentity(provider, subject string) (*models.UserIdentity, error) {
+	identitySql := `
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM UserIdentities
+	WHERE provider = ? AND subject = ?`
+
+	statement, err := oidcRepository.database.Prepare(identitySql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var identity models.UserIdentity
+
+	err = statement.QueryRow(provider, subject).Scan(
+		&identity.Id,
+		&identity.UserId,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.UserIdentity{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (oidcRepository OidcRepository) GetUserIdentitiesByUserId(userId int64) ([]models.UserIdentity, error) {
+	userIdentitiesSql := `
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM UserIdentities
+	WHERE user_id = ?
+	ORDER BY created_at, id`
+
+	statement, err := oidcRepository.database.Prepare(userIdentitiesSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	identities := make([]models.UserIdentity, 0)
+
+	for rows.Next() {
+		var identity models.UserIdentity
+
+		err = rows.Scan(
+			&identity.Id,
+			&identity.UserId,
+			&identity.Provider,
+			&identity.Subject,
+			&identity.Email,
+			&identity.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	return identities, nil
+}
+
+func (oidcRepository OidcRepository) CreateUserIdentity(identity *models.UserIdentity) error {
+	createIdentitySql := `
+	INSERT INTO UserIdentities(user_id, provider, subject, email, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	statement, err := oidcRepository.database.Prepare(createIdentitySql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(identity.UserId, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	identity.Id = id
+
+	return nil
+}
+
+func NewOidcRepository(database *sql.DB) *OidcRepository {
+	return &OidcRepository{
+		database: database,
+	}
+}
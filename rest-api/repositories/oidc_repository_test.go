@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OidcRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *OidcRepository
+}
+
+func TestOidcRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OidcRepositoryUnitTestSuite{})
+}
+
+func (suite *OidcRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewOidcRepository(db)
+}
+
+func (suite *OidcRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// When no state matches the hash, a state with an id of 0 is returned
+func (suite *OidcRepositoryUnitTestSuite) TestConsumeOidcLoginStateUnknownState_ReturnsEmptyState() {
+
+	suite.dbMock.ExpectPrepare(`
+	DELETE FROM OidcLoginStates
+	WHERE state_hash = ?
+	RETURNING id, provider, state_hash, nonce, code_verifier, expires_at, created_at`).
+		ExpectQuery().
+		WithArgs("state hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	state, err := suite.repository.ConsumeOidcLoginState("state hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.OidcLoginState{}, state)
+}
+
+func (suite *OidcRepositoryUnitTestSuite) TestConsumeOidcLoginState_ReturnsTheState() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	DELETE FROM OidcLoginStates
+	WHERE state_hash = ?
+	RETURNING id, provider, state_hash, nonce, code_verifier, expires_at, created_at`).
+		ExpectQuery().
+		WithArgs("state hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider", "state_hash", "nonce", "code_verifier", "expires_at", "created_at"}).
+			AddRow(1, "company", "state hash", "nonce", "verifier", now, now))
+
+	state, err := suite.repository.ConsumeOidcLoginState("state hash")
+
+	suite.Nil(err)
+	suite.Equal("nonce", state.Nonce)
+	suite.Equal("verifier", state.CodeVerifier)
+}
+
+// When the account of the provider is not linked, an identity with an id of 0 is returned
+func (suite *OidcRepositoryUnitTestSuite) TestGetUserIdentityNotLinked_ReturnsEmptyIdentity() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM UserIdentities
+	WHERE provider = ? AND subject = ?`).
+		ExpectQuery().
+		WithArgs("company", "subject").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	identity, err := suite.repository.GetUserIdentity("company", "subject")
+
+	suite.Nil(err)
+	suite.Equal(int64(0), identity.Id)
+}
+
+func (suite *OidcRepositoryUnitTestSuite) TestCreateUserIdentity_SetsTheId() {
+
+	identity := models.UserIdentity{
+		UserId:    12,
+		Provider:  "company",
+		Subject:   "subject",
+		Email:     "test@test.com",
+		CreatedAt: time.Now(),
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO UserIdentities(user_id, provider, subject, email, created_at)
+	VALUES (?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(identity.UserId, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	err := suite.repository.CreateUserIdentity(&identity)
+
+	suite.Nil(err)
+	suite.Equal(int64(3), identity.Id)
+}
+
+func (suite *OidcRepositoryUnitTestSuite) TestGetUserIdentitiesByUserId_ReturnsTheIdentities() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM UserIdentities
+	WHERE user_id = ?
+	ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "provider", "subject", "email", "created_at"}).
+			AddRow(3, 12, "company", "subject", "test@test.com", now))
+
+	identities, err := suite.repository.GetUserIdentitiesByUserId(12)
+
+	suite.Nil(err)
+	suite.Equal([]models.UserIdentity{{
+		Id:        3,
+		UserId:    12,
+		Provider:  "company",
+		Subject:   "subject",
+		Email:     "test@test.com",
+		CreatedAt: now,
+	}}, identities)
+}
@@ -0,0 +1,361 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+const organizationInvitationColumns = `id, organization_id, email, role, token_hash, invited_by, expires_at, created_at, accepted_at`
+
+type OrganizationRepository struct {
+	database *sql.DB
+}
+
+// Creates the organization along with the membership of its creator, who becomes its first admin
+func (organizationRepository OrganizationRepository) CreateOrganization(organization *models.Organization, creatorId int64) error {
+	transaction, err := organizationRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(
+		`INSERT INTO Organizations(name, created_at) VALUES (?, ?)`,
+		organization.Name,
+		organization.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = transaction.Exec(
+		`INSERT INTO OrganizationMembers(organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+		id,
+		creatorId,
+		models.ORGANIZATION_ADMIN_ROLE,
+		organization.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	err = transaction.Commit()
+
+	if err != nil {
+		return err
+	}
+
+	organization.Id = id
+
+	return nil
+}
+
+// Returns a membership with an organization id of 0 when the user is not a member
+func (organizationRepository OrganizationRepository) GetMembership(organizationId, userId int64) (*models.OrganizationMembership, error) {
+	membershipSql := `
+	SELECT Organizations.id, Organizations.name, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Organizations ON Organizations.id = OrganizationMembers.organization_id
+	WHERE OrganizationMembers.organization_id = ? AND OrganizationMembers.user_id = ?`
+
+	statement, err := organizationRepository.database.Prepare(membershipSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var membership models.OrganizationMembership
+
+	err = statement.QueryRow(organizationId, userId).Scan(
+		&membership.OrganizationId,
+		&membership.Name,
+		&membership.Role,
+		&membership.JoinedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.OrganizationMembership{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (organizationRepository OrganizationRepository) GetMembershipsByUserId(userId int64) ([]models.OrganizationMembership, error) {
+	membershipsSql := `
+	SELECT Organizations.id, Organizations.name, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Organizations ON Organizations.id = OrganizationMembers.organization_id
+	WHERE OrganizationMembers.user_id = ?
+	ORDER BY Organizations.name, Organizations.id`
+
+	statement, err := organizationRepository.database.Prepare(membershipsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	memberships := make([]models.OrganizationMembership, 0)
+
+	for rows.Next() {
+		var membership models.OrganizationMembership
+
+		err = rows.Scan(&membership.OrganizationId, &membership.Name, &membership.Role, &membership.JoinedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, rows.Err()
+}
+
+func (organizationRepository OrganizationRepository) GetMembers(organizationId int64) ([]models.OrganizationMember, error) {
+	membersSql := `
+	SELECT Users.id, Users.email, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Users ON Users.id = OrganizationMembers.user_id
+	WHERE OrganizationMembers.organization_id = ?
+	ORDER BY OrganizationMembers.joined_at, Users.id`
+
+	statement, err := organizationRepository.database.Prepare(membersSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := make([]models.OrganizationMember, 0)
+
+	for rows.Next() {
+		var member models.OrganizationMember
+
+		err = rows.Scan(&member.UserId, &member.Email, &member.Role, &member.JoinedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (organizationRepository OrganizationRepository) CountAdmins(organizationId int64) (int64, error) {
+	countAdminsSql := `SELECT COUNT(*) FROM OrganizationMembers WHERE organization_id = ? AND role = ?`
+
+	statement, err := organizationRepository.database.Prepare(countAdminsSql)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(organizationId, models.ORGANIZATION_ADMIN_ROLE).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (organizationRepository OrganizationRepository) UpdateMemberRole(organizationId, userId int64, role string) error {
+	return organizationRepository.exec(
+		`UPDATE OrganizationMembers SET role = ? WHERE organization_id = ? AND user_id = ?`,
+		role,
+		organizationId,
+		userId)
+}
+
+func (organizationRepository OrganizationRepository) RemoveMember(organizationId, userId int64) error {
+	return organizationRepository.exec(
+		`DELETE FROM OrganizationMembers WHERE organization_id = ? AND user_id = ?`,
+		organizationId,
+		userId)
+}
+
+func (organizationRepository OrganizationRepository) CreateInvitation(invitation *models.OrganizationInvitation) error {
+	createInvitationSql := `
+	INSERT INTO OrganizationInvitations(organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	statement, err := organizationRepository.database.Prepare(createInvitationSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		invitation.OrganizationId,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		invitation.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	invitation.Id = id
+
+	return nil
+}
+
+// Returns an invitation with an id of 0 when no invitation of the organization matches the hash
+func (organizationRepository OrganizationRepository) GetInvitationByTokenHash(organizationId int64, tokenHash string) (*models.OrganizationInvitation, error) {
+	invitationSql := `
+	SELECT ` + organizationInvitationColumns + ` FROM OrganizationInvitations
+	WHERE organization_id = ? AND token_hash = ?`
+
+	statement, err := organizationRepository.database.Prepare(invitationSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var invitation models.OrganizationInvitation
+	var acceptedAt sql.NullTime
+
+	err = statement.QueryRow(organizationId, tokenHash).Scan(
+		&invitation.Id,
+		&invitation.OrganizationId,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+		&acceptedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.OrganizationInvitation{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+
+	return &invitation, nil
+}
+
+// Uses up the invitation and adds the user to the organization with the role of the invitation.
+// Members accepting an invitation keep their current role. Returns false when the invitation was
+// accepted in the meantime
+func (organizationRepository OrganizationRepository) AcceptInvitation(invitation models.OrganizationInvitation, userId int64, acceptedAt time.Time) (bool, error) {
+	transaction, err := organizationRepository.database.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(
+		`UPDATE OrganizationInvitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL`,
+		acceptedAt,
+		invitation.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if affectedRows == 0 {
+		return false, nil
+	}
+
+	_, err = transaction.Exec(`
+	INSERT INTO OrganizationMembers(organization_id, user_id, role, joined_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(organization_id, user_id) DO NOTHING`,
+		invitation.OrganizationId,
+		userId,
+		invitation.Role,
+		acceptedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, transaction.Commit()
+}
+
+func (organizationRepository OrganizationRepository) exec(query string, args ...any) error {
+	statement, err := organizationRepository.database.Prepare(query)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(args...)
+
+	return err
+}
+
+func NewOrganizationRepository(database *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrganizationRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *OrganizationRepository
+}
+
+func TestOrganizationRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OrganizationRepositoryUnitTestSuite{})
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewOrganizationRepository(db)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// The creator of the organization becomes its first admin
+func (suite *OrganizationRepositoryUnitTestSuite) TestCreateOrganization_AddsTheCreatorAsAdmin() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`INSERT INTO Organizations(name, created_at) VALUES (?, ?)`).
+		WithArgs("Acme", now).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	suite.dbMock.ExpectExec(`INSERT INTO OrganizationMembers(organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`).
+		WithArgs(4, 12, models.ORGANIZATION_ADMIN_ROLE, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	organization := models.Organization{Name: "Acme", CreatedAt: now}
+
+	err := suite.repository.CreateOrganization(&organization, 12)
+
+	suite.Nil(err)
+	suite.Equal(int64(4), organization.Id)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when executing the sql, will return the error and roll back
+func (suite *OrganizationRepositoryUnitTestSuite) TestCreateOrganization_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`INSERT INTO Organizations(name, created_at) VALUES (?, ?)`).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	suite.dbMock.ExpectExec(`INSERT INTO OrganizationMembers(organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`).
+		WillReturnError(expectedError)
+	suite.dbMock.ExpectRollback()
+
+	organization := models.Organization{Name: "Acme"}
+
+	err := suite.repository.CreateOrganization(&organization, 12)
+
+	suite.Equal(expectedError, err)
+	suite.Equal(int64(0), organization.Id)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetMembership_ReturnsTheMembership() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT Organizations.id, Organizations.name, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Organizations ON Organizations.id = OrganizationMembers.organization_id
+	WHERE OrganizationMembers.organization_id = ? AND OrganizationMembers.user_id = ?`).
+		ExpectQuery().
+		WithArgs(4, 12).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role", "joined_at"}).
+			AddRow(4, "Acme", models.ORGANIZATION_MEMBER_ROLE, now))
+
+	membership, err := suite.repository.GetMembership(4, 12)
+
+	suite.Nil(err)
+	suite.Equal(&models.OrganizationMembership{
+		OrganizationId: 4,
+		Name:           "Acme",
+		Role:           models.ORGANIZATION_MEMBER_ROLE,
+		JoinedAt:       now,
+	}, membership)
+}
+
+// When the user is not a member, return a membership with an organization id of 0
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetMembershipOfANonMember_ReturnsAnEmptyMembership() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT Organizations.id, Organizations.name, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Organizations ON Organizations.id = OrganizationMembers.organization_id
+	WHERE OrganizationMembers.organization_id = ? AND OrganizationMembers.user_id = ?`).
+		ExpectQuery().
+		WithArgs(4, 12).
+		WillReturnError(sql.ErrNoRows)
+
+	membership, err := suite.repository.GetMembership(4, 12)
+
+	suite.Nil(err)
+	suite.Equal(int64(0), membership.OrganizationId)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetMembershipsByUserId_ReturnsTheMemberships() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT Organizations.id, Organizations.name, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Organizations ON Organizations.id = OrganizationMembers.organization_id
+	WHERE OrganizationMembers.user_id = ?
+	ORDER BY Organizations.name, Organizations.id`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role", "joined_at"}).
+			AddRow(4, "Acme", models.ORGANIZATION_ADMIN_ROLE, now).
+			AddRow(5, "Globex", models.ORGANIZATION_MEMBER_ROLE, now))
+
+	memberships, err := suite.repository.GetMembershipsByUserId(12)
+
+	suite.Nil(err)
+	suite.Len(memberships, 2)
+	suite.True(memberships[0].IsAdmin())
+	suite.Equal(int64(5), memberships[1].OrganizationId)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetMembers_ReturnsTheMembers() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT Users.id, Users.email, OrganizationMembers.role, OrganizationMembers.joined_at
+	FROM OrganizationMembers
+	JOIN Users ON Users.id = OrganizationMembers.user_id
+	WHERE OrganizationMembers.organization_id = ?
+	ORDER BY OrganizationMembers.joined_at, Users.id`).
+		ExpectQuery().
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role", "joined_at"}).
+			AddRow(12, "test@test.com", models.ORGANIZATION_ADMIN_ROLE, now))
+
+	members, err := suite.repository.GetMembers(4)
+
+	suite.Nil(err)
+	suite.Equal([]models.OrganizationMember{{
+		UserId:   12,
+		Email:    "test@test.com",
+		Role:     models.ORGANIZATION_ADMIN_ROLE,
+		JoinedAt: now,
+	}}, members)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestCountAdmins_ReturnsTheCount() {
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM OrganizationMembers WHERE organization_id = ? AND role = ?`).
+		ExpectQuery().
+		WithArgs(4, models.ORGANIZATION_ADMIN_ROLE).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := suite.repository.CountAdmins(4)
+
+	suite.Nil(err)
+	suite.Equal(int64(2), count)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestUpdateMemberRole_PreparesTheSqlStatement() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE OrganizationMembers SET role = ? WHERE organization_id = ? AND user_id = ?`).
+		ExpectExec().
+		WithArgs(models.ORGANIZATION_ADMIN_ROLE, 4, 13).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.UpdateMemberRole(4, 13, models.ORGANIZATION_ADMIN_ROLE)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestRemoveMember_PreparesTheSqlStatement() {
+
+	suite.dbMock.ExpectPrepare(`DELETE FROM OrganizationMembers WHERE organization_id = ? AND user_id = ?`).
+		ExpectExec().
+		WithArgs(4, 13).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.RemoveMember(4, 13)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestCreateInvitation_SetsTheIdToTheDbId() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	invitation := models.OrganizationInvitation{
+		OrganizationId: 4,
+		Email:          "invitee@test.com",
+		Role:           models.ORGANIZATION_MEMBER_ROLE,
+		TokenHash:      "hash",
+		InvitedBy:      12,
+		ExpiresAt:      now.Add(time.Hour),
+		CreatedAt:      now,
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO OrganizationInvitations(organization_id, email, role, token_hash, invited_by, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(4, "invitee@test.com", models.ORGANIZATION_MEMBER_ROLE, "hash", 12, now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	err := suite.repository.CreateInvitation(&invitation)
+
+	suite.Nil(err)
+	suite.Equal(int64(7), invitation.Id)
+}
+
+// Invitations are looked up within their organization, a token of another organization matches nothing
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetInvitationByTokenHashOfAnotherOrganization_ReturnsAnEmptyInvitation() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT `+organizationInvitationColumns+` FROM OrganizationInvitations
+	WHERE organization_id = ? AND token_hash = ?`).
+		ExpectQuery().
+		WithArgs(5, "hash").
+		WillReturnError(sql.ErrNoRows)
+
+	invitation, err := suite.repository.GetInvitationByTokenHash(5, "hash")
+
+	suite.Nil(err)
+	suite.Equal(int64(0), invitation.Id)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestGetInvitationByTokenHash_ReturnsTheInvitation() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT `+organizationInvitationColumns+` FROM OrganizationInvitations
+	WHERE organization_id = ? AND token_hash = ?`).
+		ExpectQuery().
+		WithArgs(4, "hash").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"organization_id",
+			"email",
+			"role",
+			"token_hash",
+			"invited_by",
+			"expires_at",
+			"created_at",
+			"accepted_at",
+		}).AddRow(7, 4, "invitee@test.com", models.ORGANIZATION_MEMBER_ROLE, "hash", 12, now.Add(time.Hour), now, nil))
+
+	invitation, err := suite.repository.GetInvitationByTokenHash(4, "hash")
+
+	suite.Nil(err)
+	suite.Equal(int64(7), invitation.Id)
+	suite.Equal("invitee@test.com", invitation.Email)
+	suite.Nil(invitation.AcceptedAt)
+}
+
+func (suite *OrganizationRepositoryUnitTestSuite) TestAcceptInvitation_AddsTheMember() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`UPDATE OrganizationInvitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL`).
+		WithArgs(now, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`
+	INSERT INTO OrganizationMembers(organization_id, user_id, role, joined_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(organization_id, user_id) DO NOTHING`).
+		WithArgs(4, 13, models.ORGANIZATION_MEMBER_ROLE, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	accepted, err := suite.repository.AcceptInvitation(models.OrganizationInvitation{
+		Id:             7,
+		OrganizationId: 4,
+		Role:           models.ORGANIZATION_MEMBER_ROLE,
+	}, 13, now)
+
+	suite.Nil(err)
+	suite.True(accepted)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When the invitation was accepted in the meantime, nobody is added
+func (suite *OrganizationRepositoryUnitTestSuite) TestAcceptInvitationAlreadyAccepted_ReturnsFalse() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`UPDATE OrganizationInvitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL`).
+		WithArgs(now, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+
+	accepted, err := suite.repository.AcceptInvitation(models.OrganizationInvitation{Id: 7, OrganizationId: 4}, 13, now)
+
+	suite.Nil(err)
+	suite.False(accepted)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
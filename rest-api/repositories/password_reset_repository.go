@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+type PasswordResetRepository struct {
+	database *sql.DB
+}
+
+func (passwordResetRepository PasswordResetRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	createTokenSql := `
+	INSERT INTO PasswordResetTokens(user_id, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?)`
+
+	statement, err := passwordResetRepository.database.Prepare(createTokenSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(token.UserId, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	token.Id = id
+
+	return nil
+}
+
+// Returns a token with an id of 0 when no token matches the hash
+func (passwordResetRepository PasswordResetRepository) GetPasswordResetTokenByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	tokenByHashSql := `
+	SELECT id, user_id, token_hash, expires_at, created_at, used_at
+	FROM PasswordResetTokens
+	WHERE token_hash = ?`
+
+	statement, err := passwordResetRepository.database.Prepare(tokenByHashSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var token models.PasswordResetToken
+	var usedAt sql.NullTime
+
+	err = statement.QueryRow(tokenHash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.PasswordResetToken{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+// Consumes the token, stores the new password hash and invalidates the other unused tokens of the
+// user in a single transaction, so a failure leaves the token usable. Returns false when the token
+// was already used, concurrent resets with the same token cannot both succeed
+func (passwordResetRepository PasswordResetRepository) ResetPassword(tokenId, userId int64, password string, usedAt time.Time) (bool, error) {
+	transaction, err := passwordResetRepository.database.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(`UPDATE PasswordResetTokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, usedAt, tokenId)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if affectedRows == 0 {
+		return false, nil
+	}
+
+	_, err = transaction.Exec(`UPDATE Users SET password = ? WHERE id = ?`, password, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	//older emails cannot be used anymore
+	_, err = transaction.Exec(`UPDATE PasswordResetTokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`, usedAt, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, transaction.Commit()
+}
+
+func NewPasswordResetRepository(database *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordResetRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *PasswordResetRepository
+}
+
+func TestPasswordResetRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PasswordResetRepositoryUnitTestSuite{})
+}
+
+func (suite *PasswordResetRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewPasswordResetRepository(db)
+}
+
+func (suite *PasswordResetRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func (suite *PasswordResetRepositoryUnitTestSuite) TestCreatePasswordResetToken_SetsTheIdToTheDbId() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	token := models.PasswordResetToken{
+		UserId:    12,
+		TokenHash: "hash",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO PasswordResetTokens(user_id, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(int64(12), "hash", now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
+
+	err := suite.repository.CreatePasswordResetToken(&token)
+
+	suite.Nil(err)
+	suite.Equal(int64(10), token.Id)
+}
+
+// When no token matches the hash, an empty token is returned
+func (suite *PasswordResetRepositoryUnitTestSuite) TestGetPasswordResetTokenByHash_ReturnsEmptyToken() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, token_hash, expires_at, created_at, used_at
+	FROM PasswordResetTokens
+	WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	token, err := suite.repository.GetPasswordResetTokenByHash("hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.PasswordResetToken{}, token)
+}
+
+func (suite *PasswordResetRepositoryUnitTestSuite) TestGetPasswordResetTokenByHash_ReturnsTheToken() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, token_hash, expires_at, created_at, used_at
+	FROM PasswordResetTokens
+	WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "created_at", "used_at"}).
+			AddRow(int64(3), int64(12), "hash", now.Add(time.Hour), now, now))
+
+	token, err := suite.repository.GetPasswordResetTokenByHash("hash")
+
+	suite.Nil(err)
+	suite.Equal(int64(3), token.Id)
+	suite.Equal(int64(12), token.UserId)
+	suite.Equal(&now, token.UsedAt)
+}
+
+// When the token was already used, neither the password nor the other tokens are updated
+func (suite *PasswordResetRepositoryUnitTestSuite) TestResetPasswordTokenAlreadyUsed_ReturnsFalse() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`UPDATE PasswordResetTokens SET used_at = ? WHERE id = ? AND used_at IS NULL`).
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+
+	reset, err := suite.repository.ResetPassword(3, 12, "hashed password", now)
+
+	suite.Nil(err)
+	suite.False(reset)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// The token, the password and the other tokens are updated together
+func (suite *PasswordResetRepositoryUnitTestSuite) TestResetPassword_CommitsEveryUpdate() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`UPDATE PasswordResetTokens SET used_at = ? WHERE id = ? AND used_at IS NULL`).
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`UPDATE Users SET password = ? WHERE id = ?`).
+		WithArgs("hashed password", int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`UPDATE PasswordResetTokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`).
+		WithArgs(now, int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectCommit()
+
+	reset, err := suite.repository.ResetPassword(3, 12, "hashed password", now)
+
+	suite.Nil(err)
+	suite.True(reset)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When storing the password fails, the token is left unused
+func (suite *PasswordResetRepositoryUnitTestSuite) TestResetPasswordFailing_RollsBack() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`UPDATE PasswordResetTokens SET used_at = ? WHERE id = ? AND used_at IS NULL`).
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`UPDATE Users SET password = ? WHERE id = ?`).
+		WillReturnError(errors.New("test"))
+	suite.dbMock.ExpectRollback()
+
+	_, err := suite.repository.ResetPassword(3, 12, "hashed password", now)
+
+	suite.NotNil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
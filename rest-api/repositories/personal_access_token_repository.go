@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/models"
+)
+
+const personalAccessTokenColumns = `id, user_id, name, scopes, token_hash, expires_at, created_at, last_used_at, revoked_at`
+
+type PersonalAccessTokenRepository struct {
+	database *sql.DB
+}
+
+// Scopes are stored separated by spaces, as OAuth does
+func (personalAccessTokenRepository PersonalAccessTokenRepository) CreatePersonalAccessToken(token *models.PersonalAccessToken) error {
+	createTokenSql := `
+	INSERT INTO PersonalAccessTokens(user_id, name, scopes, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+
+	statement, err := personalAccessTokenRepository.database.Prepare(createTokenSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		token.UserId,
+		token.Name,
+		strings.Join(token.Scopes, " "),
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	token.Id = id
+
+	return nil
+}
+
+// Returns a token with an id of 0 when no token matches the hash
+func (personalAccessTokenRepository PersonalAccessTokenRepository) GetPersonalAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	tokenByHashSql := `SELECT ` + personalAccessTokenColumns + ` FROM PersonalAccessTokens WHERE token_hash = ?`
+
+	statement, err := personalAccessTokenRepository.database.Prepare(tokenByHashSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	token, err := scanPersonalAccessToken(statement.QueryRow(tokenHash))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.PersonalAccessToken{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Returns the tokens of the user that were not revoked, expired ones included so users can see
+// which tokens have to be replaced
+func (personalAccessTokenRepository PersonalAccessTokenRepository) GetPersonalAccessTokensByUserId(userId int64) ([]models.PersonalAccessToken, error) {
+	tokensByUserSql := `
+	SELECT ` + personalAccessTokenColumns + `
+	FROM PersonalAccessTokens
+	WHERE user_id = ? AND revoked_at IS NULL
+	ORDER BY created_at DESC`
+
+	statement, err := personalAccessTokenRepository.database.Prepare(tokensByUserSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Revokes the token when it belongs to the user, returns false when no such token is active
+func (personalAccessTokenRepository PersonalAccessTokenRepository) RevokePersonalAccessToken(id, userId int64, revokedAt time.Time) (bool, error) {
+	revokeTokenSql := `
+	UPDATE PersonalAccessTokens
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	statement, err := personalAccessTokenRepository.database.Prepare(revokeTokenSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(revokedAt, id, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affectedRows == 1, nil
+}
+
+// Records when the token was used, unless it was already recorded after throttledBefore, so busy
+// scripts do not write on every request
+func (personalAccessTokenRepository PersonalAccessTokenRepository) MarkPersonalAccessTokenUsed(id int64, usedAt, throttledBefore time.Time) error {
+	markUsedSql := `
+	UPDATE PersonalAccessTokens
+	SET last_used_at = ?
+	WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`
+
+	statement, err := personalAccessTokenRepository.database.Prepare(markUsedSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(usedAt, id, throttledBefore)
+
+	return err
+}
+
+func scanPersonalAccessToken(scanner rowScanner) (models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Name,
+		&scopes,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&lastUsedAt,
+		&revokedAt)
+
+	if err != nil {
+		return models.PersonalAccessToken{}, err
+	}
+
+	token.Scopes = strings.Fields(scopes)
+
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return token, nil
+}
+
+func NewPersonalAccessTokenRepository(database *sql.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		database: database,
+	}
+}
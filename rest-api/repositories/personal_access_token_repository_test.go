@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersonalAccessTokenRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *PersonalAccessTokenRepository
+}
+
+func TestPersonalAccessTokenRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PersonalAccessTokenRepositoryUnitTestSuite{})
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewPersonalAccessTokenRepository(db)
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// Scopes are stored separated by spaces
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestCreatePersonalAccessToken_SetsTheId() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO PersonalAccessTokens(user_id, name, scopes, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(12, "deploy script", "events:read events:write", "token hash", now, now).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	token := models.PersonalAccessToken{
+		UserId:    12,
+		Name:      "deploy script",
+		Scopes:    []string{models.EVENTS_READ_SCOPE, models.EVENTS_WRITE_SCOPE},
+		TokenHash: "token hash",
+		ExpiresAt: now,
+		CreatedAt: now,
+	}
+
+	err := suite.repository.CreatePersonalAccessToken(&token)
+
+	suite.Nil(err)
+	suite.Equal(int64(3), token.Id)
+}
+
+// When no token matches the hash, a token with an id of 0 is returned
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestGetPersonalAccessTokenByUnknownHash_ReturnsEmptyToken() {
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, name, scopes, token_hash, expires_at, created_at, last_used_at, revoked_at FROM PersonalAccessTokens WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("token hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	token, err := suite.repository.GetPersonalAccessTokenByHash("token hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.PersonalAccessToken{}, token)
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestGetPersonalAccessTokenByHash_ReturnsTheToken() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`SELECT id, user_id, name, scopes, token_hash, expires_at, created_at, last_used_at, revoked_at FROM PersonalAccessTokens WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("token hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "scopes", "token_hash", "expires_at", "created_at", "last_used_at", "revoked_at"}).
+			AddRow(3, 12, "deploy script", "events:read events:write", "token hash", now, now, now, nil))
+
+	token, err := suite.repository.GetPersonalAccessTokenByHash("token hash")
+
+	suite.Nil(err)
+	suite.Equal(int64(3), token.Id)
+	suite.Equal([]string{models.EVENTS_READ_SCOPE, models.EVENTS_WRITE_SCOPE}, token.Scopes)
+	suite.Equal(&now, token.LastUsedAt)
+	suite.Nil(token.RevokedAt)
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestGetPersonalAccessTokensByUserId_ReturnsTheTokens() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT id, user_id, name, scopes, token_hash, expires_at, created_at, last_used_at, revoked_at
+	FROM PersonalAccessTokens
+	WHERE user_id = ? AND revoked_at IS NULL
+	ORDER BY created_at DESC`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "scopes", "token_hash", "expires_at", "created_at", "last_used_at", "revoked_at"}).
+			AddRow(4, 12, "backup", "events:read", "hash", now, now, nil, nil).
+			AddRow(3, 12, "deploy script", "events:write", "other hash", now, now, now, nil))
+
+	tokens, err := suite.repository.GetPersonalAccessTokensByUserId(12)
+
+	suite.Nil(err)
+	suite.Len(tokens, 2)
+	suite.Equal("backup", tokens[0].Name)
+	suite.Nil(tokens[0].LastUsedAt)
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestRevokePersonalAccessToken_ReturnsTrue() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE PersonalAccessTokens
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, 3, 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	revoked, err := suite.repository.RevokePersonalAccessToken(3, 12, now)
+
+	suite.Nil(err)
+	suite.True(revoked)
+}
+
+// When the token belongs to another user or was already revoked, nothing is updated
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestRevokeMissingPersonalAccessToken_ReturnsFalse() {
+
+	now := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE PersonalAccessTokens
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, 3, 12).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	revoked, err := suite.repository.RevokePersonalAccessToken(3, 12, now)
+
+	suite.Nil(err)
+	suite.False(revoked)
+}
+
+func (suite *PersonalAccessTokenRepositoryUnitTestSuite) TestMarkPersonalAccessTokenUsed_UpdatesTheToken() {
+
+	now := time.Now()
+	throttledBefore := now.Add(-time.Minute)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE PersonalAccessTokens
+	SET last_used_at = ?
+	WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`).
+		ExpectExec().
+		WithArgs(now, 3, throttledBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.MarkPersonalAccessTokenUsed(3, now, throttledBefore)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
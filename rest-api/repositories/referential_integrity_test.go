@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
+	"example.com/models"
+	"example.com/services"
+	"github.com/stretchr/testify/suite"
+)
+
+// Checks the constraints enforced by an actual SQLite database, including on the rows written
+// before 0002_referential_integrity was applied
+type ReferentialIntegrityTestSuite struct {
+	suite.Suite
+	database *sql.DB
+}
+
+func TestReferentialIntegrityTestSuite(t *testing.T) {
+	suite.Run(t, &ReferentialIntegrityTestSuite{})
+}
+
+func (suite *ReferentialIntegrityTestSuite) SetupTest() {
+	suite.database = openMigratedSqliteDatabase(suite.T())
+}
+
+func (suite *ReferentialIntegrityTestSuite) TearDownTest() {
+	suite.database.Close()
+}
+
+// Migration files of the SQLite databases up to the version, included
+func sqliteMigrationFilesUpTo(t *testing.T, version string) fs.FS {
+	files := fstest.MapFS{}
+	migrationFiles := config.DriverMigrationFiles(config.SQLITE_DATABASE_DRIVER)
+
+	entries, err := fs.ReadDir(migrationFiles, ".")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name()[:len(version)] > version {
+			continue
+		}
+
+		content, err := fs.ReadFile(migrationFiles, entry.Name())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[entry.Name()] = &fstest.MapFile{Data: content}
+	}
+
+	return files
+}
+
+func (suite *ReferentialIntegrityTestSuite) TestOpenDatabase_EnforcesForeignKeys() {
+
+	var foreignKeys int
+
+	err := suite.database.QueryRow(`PRAGMA foreign_keys`).Scan(&foreignKeys)
+
+	suite.Nil(err)
+	suite.Equal(1, foreignKeys)
+}
+
+// When the event does not exist, return a missing referenced record error
+func (suite *ReferentialIntegrityTestSuite) TestCreateCommentOnMissingEvent_ReturnsMissingReferencedRecord() {
+
+	user := models.User{Email: "author@test.com", Password: "hash"}
+
+	suite.Require().Nil(NewUserRepository(suite.database).CreateUser(&user))
+
+	err := NewCommentRepository(suite.database).CreateComment(&models.Comment{
+		EventId:   42,
+		UserId:    user.Id,
+		Body:      "some body",
+		CreatedAt: time.Now().UTC(),
+	})
+
+	suite.NotNil(err)
+	suite.Equal(constants.MISSING_REFERENCED_RECORD_ERROR, err.Error())
+}
+
+// When the event does not exist, return a missing referenced record error
+func (suite *ReferentialIntegrityTestSuite) TestCreateInvitationForMissingEvent_ReturnsMissingReferencedRecord() {
+
+	err := NewInvitationRepository(suite.database).CreateInvitation(&models.Invitation{
+		EventId:   42,
+		TokenHash: "token hash",
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		CreatedAt: time.Now().UTC(),
+	})
+
+	suite.NotNil(err)
+	suite.Equal(constants.MISSING_REFERENCED_RECORD_ERROR, err.Error())
+}
+
+// The organizer is not part of the update, events sent without one keep theirs
+func (suite *ReferentialIntegrityTestSuite) TestUpdateEventWithoutUser_KeepsTheOrganizer() {
+
+	user := models.User{Email: "organizer@test.com", Password: "hash"}
+
+	suite.Require().Nil(NewUserRepository(suite.database).CreateUser(&user))
+
+	eventRepository := NewEventRepository(suite.database)
+
+	event := models.Event{
+		Name:        "Meetup",
+		Description: "Monthly meetup",
+		Location:    "Lyon",
+		Date:        time.Date(2030, 5, 1, 18, 0, 0, 0, time.UTC),
+		UserId:      user.Id,
+		Visibility:  models.PUBLIC_VISIBILITY,
+	}
+
+	suite.Require().Nil(eventRepository.AddEvent(&event))
+
+	//the update request does not carry the organizer
+	event.Name = "Renamed"
+	event.UserId = 0
+
+	err := eventRepository.UpdateEvent(event.Id, 0, event)
+
+	suite.Nil(err)
+
+	storedEvent, err := eventRepository.GetEventById(event.Id, 0)
+
+	suite.Nil(err)
+	suite.Equal("Renamed", storedEvent.Name)
+	suite.Equal(user.Id, storedEvent.UserId)
+}
+
+// Registrations written twice or left behind by deleted events, while nothing prevented it, are
+// removed by 0002_referential_integrity
+func (suite *ReferentialIntegrityTestSuite) TestMigrateUp_RemovesDuplicateAndOrphanedRegistrations() {
+
+	database, err := config.OpenDatabase(config.SQLITE_DATABASE_DRIVER, filepath.Join(suite.T().TempDir(), "api.db"))
+
+	suite.Require().Nil(err)
+
+	defer database.Close()
+
+	_, err = services.NewMigrationService(NewMigrationRepository(database), sqliteMigrationFilesUpTo(suite.T(), "0001")).MigrateUp()
+
+	suite.Require().Nil(err)
+
+	//the rows are written the way they were before foreign keys were enforced
+	_, err = database.Exec(`
+	PRAGMA foreign_keys = OFF;
+	INSERT INTO Users(id, email, password) VALUES (1, 'member@test.com', 'hash');
+	INSERT INTO Events(id, name, description, location, date, user_id) VALUES (1, 'Meetup', 'Monthly meetup', 'Lyon', '2030-05-01 18:00:00', 1);
+	INSERT INTO Registrations(event_id, user_id) VALUES (1, 1), (1, 1), (2, 1);
+	PRAGMA foreign_keys = ON;`)
+
+	suite.Require().Nil(err)
+
+	_, err = services.NewMigrationService(NewMigrationRepository(database), config.DriverMigrationFiles(config.SQLITE_DATABASE_DRIVER)).MigrateUp()
+
+	suite.Require().Nil(err)
+
+	var eventIds []int64
+
+	rows, err := database.Query(`SELECT event_id FROM Registrations`)
+
+	suite.Require().Nil(err)
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventId int64
+
+		suite.Require().Nil(rows.Scan(&eventId))
+
+		eventIds = append(eventIds, eventId)
+	}
+
+	suite.Nil(rows.Err())
+	suite.Equal([]int64{1}, eventIds)
+
+	_, err = database.Exec(`INSERT INTO Registrations(event_id, user_id) VALUES (1, 1)`)
+
+	suite.NotNil(err)
+}
@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at`
+
+type RefreshTokenRepository struct {
+	database *sql.DB
+}
+
+func (refreshTokenRepository RefreshTokenRepository) CreateRefreshToken(refreshToken *models.RefreshToken) error {
+	createRefreshTokenSql := `
+	INSERT INTO RefreshTokens(user_id, family_id, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)`
+
+	statement, err := refreshTokenRepository.database.Prepare(createRefreshTokenSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(
+		refreshToken.UserId,
+		refreshToken.FamilyId,
+		refreshToken.TokenHash,
+		refreshToken.ExpiresAt,
+		refreshToken.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	refreshToken.Id = id
+
+	return nil
+}
+
+// Returns a refresh token with an id of 0 when no refresh token matches the hash
+func (refreshTokenRepository RefreshTokenRepository) GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	refreshTokenByHashSql := `SELECT ` + refreshTokenColumns + ` FROM RefreshTokens WHERE token_hash = ?`
+
+	statement, err := refreshTokenRepository.database.Prepare(refreshTokenByHashSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	refreshToken, err := scanRefreshToken(statement.QueryRow(tokenHash))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.RefreshToken{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &refreshToken, nil
+}
+
+// Returns every refresh token issued to the user, used and revoked ones included
+func (refreshTokenRepository RefreshTokenRepository) GetRefreshTokensByUserId(userId int64) ([]models.RefreshToken, error) {
+	userRefreshTokensSql := `SELECT ` + refreshTokenColumns + ` FROM RefreshTokens WHERE user_id = ? ORDER BY created_at, id`
+
+	statement, err := refreshTokenRepository.database.Prepare(userRefreshTokensSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	refreshTokens := make([]models.RefreshToken, 0)
+
+	for rows.Next() {
+		refreshToken, err := scanRefreshToken(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		refreshTokens = append(refreshTokens, refreshToken)
+	}
+
+	return refreshTokens, nil
+}
+
+// Marks the refresh token as rotated, returns false when it was already used or revoked,
+// so concurrent refreshes with the same token cannot both succeed
+func (refreshTokenRepository RefreshTokenRepository) MarkRefreshTokenUsed(id int64, usedAt time.Time) (bool, error) {
+	markUsedSql := `
+	UPDATE RefreshTokens
+	SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`
+
+	statement, err := refreshTokenRepository.database.Prepare(markUsedSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(usedAt, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affectedRows == 1, nil
+}
+
+// Revokes the tokens of the family and ends the session they belong to
+func (refreshTokenRepository RefreshTokenRepository) RevokeRefreshTokenFamily(familyId string, revokedAt time.Time) error {
+	return refreshTokenRepository.revoke(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE family_id = ? AND revoked_at IS NULL`, `
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND revoked_at IS NULL`, revokedAt, familyId)
+}
+
+// Revokes every token of the user and ends all of their sessions
+func (refreshTokenRepository RefreshTokenRepository) RevokeRefreshTokensByUserId(userId int64, revokedAt time.Time) error {
+	return refreshTokenRepository.revoke(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE user_id = ? AND revoked_at IS NULL`, `
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE user_id = ? AND revoked_at IS NULL`, revokedAt, userId)
+}
+
+// Sessions are ended along with their refresh tokens within a transaction, so access tokens of a
+// session never outlive its refresh tokens
+func (refreshTokenRepository RefreshTokenRepository) revoke(refreshTokensSql, sessionsSql string, args ...any) error {
+	transaction, err := refreshTokenRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	_, err = transaction.Exec(refreshTokensSql, args...)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = transaction.Exec(sessionsSql, args...)
+
+	if err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+func scanRefreshToken(scanner rowScanner) (models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&refreshToken.Id,
+		&refreshToken.UserId,
+		&refreshToken.FamilyId,
+		&refreshToken.TokenHash,
+		&refreshToken.ExpiresAt,
+		&refreshToken.CreatedAt,
+		&usedAt,
+		&revokedAt)
+
+	if err != nil {
+		return refreshToken, err
+	}
+
+	if usedAt.Valid {
+		refreshToken.UsedAt = &usedAt.Time
+	}
+
+	if revokedAt.Valid {
+		refreshToken.RevokedAt = &revokedAt.Time
+	}
+
+	return refreshToken, nil
+}
+
+func NewRefreshTokenRepository(database *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RefreshTokenRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *RefreshTokenRepository
+}
+
+func TestRefreshTokenRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &RefreshTokenRepositoryUnitTestSuite{})
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewRefreshTokenRepository(db)
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestCreateRefreshToken_SetsTheIdToTheDbId() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	refreshToken := models.RefreshToken{
+		UserId:    12,
+		FamilyId:  "family",
+		TokenHash: "hash",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+	}
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO RefreshTokens(user_id, family_id, token_hash, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?)`).
+		ExpectExec().
+		WithArgs(int64(12), "family", "hash", now.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
+
+	err := suite.repository.CreateRefreshToken(&refreshToken)
+
+	suite.Nil(err)
+	suite.Equal(int64(10), refreshToken.Id)
+}
+
+// When no refresh token matches the hash, an empty refresh token is returned
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestGetRefreshTokenByHash_ReturnsEmptyRefreshToken() {
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + refreshTokenColumns + ` FROM RefreshTokens WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	refreshToken, err := suite.repository.GetRefreshTokenByHash("hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.RefreshToken{}, refreshToken)
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestGetRefreshTokenByHash_ReturnsTheRefreshToken() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + refreshTokenColumns + ` FROM RefreshTokens WHERE token_hash = ?`).
+		ExpectQuery().
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"user_id",
+			"family_id",
+			"token_hash",
+			"expires_at",
+			"created_at",
+			"used_at",
+			"revoked_at",
+		}).AddRow(3, 12, "family", "hash", now, now, now, nil))
+
+	refreshToken, err := suite.repository.GetRefreshTokenByHash("hash")
+
+	suite.Nil(err)
+	suite.Equal(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		TokenHash: "hash",
+		ExpiresAt: now,
+		CreatedAt: now,
+		UsedAt:    &now,
+	}, refreshToken)
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestMarkRefreshTokenUsed_ReturnsTrue() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE RefreshTokens
+	SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rotated, err := suite.repository.MarkRefreshTokenUsed(3, now)
+
+	suite.Nil(err)
+	suite.True(rotated)
+}
+
+// When the refresh token was already used, nothing is updated
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestMarkRefreshTokenUsedTwice_ReturnsFalse() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE RefreshTokens
+	SET used_at = ?
+	WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`).
+		ExpectExec().
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rotated, err := suite.repository.MarkRefreshTokenUsed(3, now)
+
+	suite.Nil(err)
+	suite.False(rotated)
+}
+
+// The session of the family is ended along with its tokens
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestRevokeRefreshTokenFamily_RevokesTheSession() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE family_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectExec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND revoked_at IS NULL`).
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.RevokeRefreshTokenFamily("family", now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestRevokeRefreshTokensByUserId_RevokesTheSessions() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE user_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, 12).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectExec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE user_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, 12).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.RevokeRefreshTokensByUserId(12, now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when executing the sql, the transaction is rolled back and the error returned
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestRevokeRefreshTokensByUserId_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE user_id = ? AND revoked_at IS NULL`).
+		WillReturnError(expectedError)
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.RevokeRefreshTokensByUserId(12, time.Now())
+
+	suite.Equal(expectedError, err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *RefreshTokenRepositoryUnitTestSuite) TestGetRefreshTokensByUserId_ReturnsTheRefreshTokens() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + refreshTokenColumns + ` FROM RefreshTokens WHERE user_id = ? ORDER BY created_at, id`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"user_id",
+			"family_id",
+			"token_hash",
+			"expires_at",
+			"created_at",
+			"used_at",
+			"revoked_at",
+		}).
+			AddRow(3, 12, "family", "hash", now, now, now, nil).
+			AddRow(4, 12, "family", "other hash", now, now, nil, now))
+
+	refreshTokens, err := suite.repository.GetRefreshTokensByUserId(12)
+
+	suite.Nil(err)
+	suite.Len(refreshTokens, 2)
+	suite.Equal(&now, refreshTokens[0].UsedAt)
+	suite.Nil(refreshTokens[1].UsedAt)
+	suite.Equal(&now, refreshTokens[1].RevokedAt)
+}
@@ -2,14 +2,20 @@ package repositories
 
 import "database/sql"
 
+// Restricts registrations to the ones of events of the tenant. Expects the organization id
+const registrationTenantCondition = `event_id IN (SELECT id FROM Events WHERE ` + eventTenantCondition + `)`
+
 type RegistrationRepository struct {
 	database *sql.DB
 }
 
-func (registrationRepository RegistrationRepository) CreateRegistration(eventId, userId int64) error {
+// Registers the user, unless the event belongs to another tenant. A user registered already gets a
+// duplicate record error
+func (registrationRepository RegistrationRepository) CreateRegistration(eventId, userId, organizationId int64) error {
 	createRegistrationSql := `
 	INSERT INTO Registrations(event_id, user_id)
-	VALUES (?, ?)`
+	SELECT id, ? FROM Events
+	WHERE id = ? AND ` + eventTenantCondition
 
 	statement, err := registrationRepository.database.Prepare(createRegistrationSql)
 
@@ -19,19 +25,19 @@ func (registrationRepository RegistrationRepository) CreateRegistration(eventId,
 
 	defer statement.Close()
 
-	_, resultError := statement.Exec(eventId, userId)
+	_, resultError := statement.Exec(userId, eventId, organizationId)
 
 	if resultError != nil {
-		return resultError
+		return mapConstraintError(resultError)
 	}
 
 	return nil
 }
 
-func (registrationRepository RegistrationRepository) DeleteRegistration(eventId, userId int64) error {
+func (registrationRepository RegistrationRepository) DeleteRegistration(eventId, userId, organizationId int64) error {
 	createRegistrationSql := `
 	DELETE FROM Registrations
-	WHERE event_id = ? AND user_id = ?`
+	WHERE event_id = ? AND user_id = ? AND ` + registrationTenantCondition
 
 	statement, err := registrationRepository.database.Prepare(createRegistrationSql)
 
@@ -41,7 +47,7 @@ func (registrationRepository RegistrationRepository) DeleteRegistration(eventId,
 
 	defer statement.Close()
 
-	_, resultError := statement.Exec(eventId, userId)
+	_, resultError := statement.Exec(eventId, userId, organizationId)
 
 	if resultError != nil {
 		return resultError
@@ -50,6 +56,54 @@ func (registrationRepository RegistrationRepository) DeleteRegistration(eventId,
 	return nil
 }
 
+func (registrationRepository RegistrationRepository) CountRegistrations(eventId, organizationId int64) (int64, error) {
+	countRegistrationsSql := `
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND ` + registrationTenantCondition
+
+	statement, err := registrationRepository.database.Prepare(countRegistrationsSql)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(eventId, organizationId).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (registrationRepository RegistrationRepository) IsRegistered(eventId, userId, organizationId int64) (bool, error) {
+	isRegisteredSql := `
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND user_id = ? AND ` + registrationTenantCondition
+
+	statement, err := registrationRepository.database.Prepare(isRegisteredSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(eventId, userId, organizationId).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func NewRegistrationRepository(database *sql.DB) *RegistrationRepository {
 	return &RegistrationRepository{
 		database: database,
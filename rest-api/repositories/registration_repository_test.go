@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"example.com/constants"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/suite"
 )
@@ -53,15 +54,17 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestCreateRegistration_Prepare
 
 	suite.dbMock.ExpectPrepare(`
 	INSERT INTO Registrations(event_id, user_id)
-	VALUES (?, ?)`).
+	SELECT id, ? FROM Events
+	WHERE id = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedEventId,
 			expectedUserId,
+			expectedEventId,
+			int64(0),
 		).
 		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
 
-	suite.repository.CreateRegistration(expectedEventId, expectedUserId)
+	suite.repository.CreateRegistration(expectedEventId, expectedUserId, 0)
 }
 
 // When a db error occurs, pass that up to the caller
@@ -75,15 +78,17 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestCreateRegistration_Returns
 
 	suite.dbMock.ExpectPrepare(`
 	INSERT INTO Registrations(event_id, user_id)
-	VALUES (?, ?)`).
+	SELECT id, ? FROM Events
+	WHERE id = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedEventId,
 			expectedUserId,
+			expectedEventId,
+			int64(0),
 		).
 		WillReturnError(expectedError)
 
-	err := suite.repository.CreateRegistration(expectedEventId, expectedUserId)
+	err := suite.repository.CreateRegistration(expectedEventId, expectedUserId, 0)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -98,19 +103,64 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestCreateRegistration_Returns
 
 	suite.dbMock.ExpectPrepare(`
 	INSERT INTO Registrations(event_id, user_id)
-	VALUES (?, ?)`).
+	SELECT id, ? FROM Events
+	WHERE id = ? AND `+eventTenantCondition).
 		ExpectExec().
 		WithArgs(
-			expectedEventId,
 			expectedUserId,
+			expectedEventId,
+			int64(0),
 		).
 		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
 
-	err := suite.repository.CreateRegistration(expectedEventId, expectedUserId)
+	err := suite.repository.CreateRegistration(expectedEventId, expectedUserId, 0)
 
 	suite.Nil(err)
 }
 
+// Stands for the errors of the sqlite driver, which carry the result code
+type constraintError struct {
+	code int
+}
+
+func (err constraintError) Error() string {
+	return "constraint failed"
+}
+
+func (err constraintError) Code() int {
+	return err.code
+}
+
+// When the user is registered already, return a duplicate record error
+func (suite *RegistrationRepositoryUnitTestSuite) TestCreateRegistrationTwice_ReturnsDuplicateRecord() {
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Registrations(event_id, user_id)
+	SELECT id, ? FROM Events
+	WHERE id = ? AND ` + eventTenantCondition).
+		ExpectExec().
+		WillReturnError(constraintError{code: sqliteUniqueConstraintCode})
+
+	err := suite.repository.CreateRegistration(12, 13, 0)
+
+	suite.Equal(constants.DUPLICATE_RECORD_ERROR, err.Error())
+}
+
+// When the user was deleted meanwhile, return a missing referenced record error
+func (suite *RegistrationRepositoryUnitTestSuite) TestCreateRegistrationOfDeletedUser_ReturnsMissingReferencedRecord() {
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Registrations(event_id, user_id)
+	SELECT id, ? FROM Events
+	WHERE id = ? AND ` + eventTenantCondition).
+		ExpectExec().
+		WillReturnError(constraintError{code: sqliteForeignKeyConstraintCode})
+
+	err := suite.repository.CreateRegistration(12, 13, 0)
+
+	suite.Equal(constants.MISSING_REFERENCED_RECORD_ERROR, err.Error())
+}
+
 func (suite *RegistrationRepositoryUnitTestSuite) TestDeleteRegistration_PreparesTheQuery() {
 
 	var (
@@ -119,15 +169,16 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestDeleteRegistration_Prepare
 	)
 
 	suite.dbMock.ExpectPrepare(`DELETE FROM Registrations
-	WHERE event_id = ? AND user_id = ?`).
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEventId,
 			expectedUserId,
+			int64(0),
 		).
 		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
 
-	suite.repository.DeleteRegistration(expectedEventId, expectedUserId)
+	suite.repository.DeleteRegistration(expectedEventId, expectedUserId, 0)
 }
 
 // When a db error occurs, pass that up to the caller
@@ -140,15 +191,16 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestDeleteRegistration_Returns
 	)
 
 	suite.dbMock.ExpectPrepare(`DELETE FROM Registrations
-	WHERE event_id = ? AND user_id = ?`).
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEventId,
 			expectedUserId,
+			int64(0),
 		).
 		WillReturnError(expectedError)
 
-	err := suite.repository.DeleteRegistration(expectedEventId, expectedUserId)
+	err := suite.repository.DeleteRegistration(expectedEventId, expectedUserId, 0)
 
 	suite.NotNil(err)
 	suite.Equal(expectedError, err)
@@ -162,15 +214,117 @@ func (suite *RegistrationRepositoryUnitTestSuite) TestDeleteRegistration_Returns
 	)
 
 	suite.dbMock.ExpectPrepare(`DELETE FROM Registrations
-	WHERE event_id = ? AND user_id = ?`).
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
 		ExpectExec().
 		WithArgs(
 			expectedEventId,
 			expectedUserId,
+			int64(0),
 		).
 		WillReturnResult(sqlmock.NewResult(int64(10), int64(1)))
 
-	err := suite.repository.DeleteRegistration(expectedEventId, expectedUserId)
+	err := suite.repository.DeleteRegistration(expectedEventId, expectedUserId, 0)
+
+	suite.Nil(err)
+}
+
+func (suite *RegistrationRepositoryUnitTestSuite) TestCountRegistrations_PreparesTheQuery() {
+
+	var expectedEventId int64 = 12
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(expectedEventId, int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	suite.repository.CountRegistrations(expectedEventId, 0)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When a db error occurs, pass that up to the caller
+func (suite *RegistrationRepositoryUnitTestSuite) TestCountRegistrations_ReturnsTheError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(int64(12), int64(0)).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.CountRegistrations(12, 0)
+
+	suite.NotNil(err)
+	suite.Equal(expectedError, err)
+}
+
+func (suite *RegistrationRepositoryUnitTestSuite) TestCountRegistrations_ReturnsTheCount() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(int64(12), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := suite.repository.CountRegistrations(12, 0)
+
+	suite.Nil(err)
+	suite.Equal(int64(3), count)
+}
+
+func (suite *RegistrationRepositoryUnitTestSuite) TestIsRegistered_PreparesTheQuery() {
+
+	var (
+		expectedEventId int64 = 12
+		expectedUserId  int64 = 13
+	)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(expectedEventId, expectedUserId, int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	suite.repository.IsRegistered(expectedEventId, expectedUserId, 0)
+
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When a db error occurs, pass that up to the caller
+func (suite *RegistrationRepositoryUnitTestSuite) TestIsRegistered_ReturnsTheError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(int64(12), int64(13), int64(0)).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.IsRegistered(12, 13, 0)
+
+	suite.NotNil(err)
+	suite.Equal(expectedError, err)
+}
+
+func (suite *RegistrationRepositoryUnitTestSuite) TestIsRegisteredWithoutRegistration_ReturnsFalse() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT COUNT(*) FROM Registrations
+	WHERE event_id = ? AND user_id = ? AND `+registrationTenantCondition).
+		ExpectQuery().
+		WithArgs(int64(12), int64(13), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	registered, err := suite.repository.IsRegistered(12, 13, 0)
 
 	suite.Nil(err)
+	suite.False(registered)
 }
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Keeps the ids of access tokens revoked before their expiration. Entries are only needed
+// until the token would have expired on its own
+type RevokedTokenRepository struct {
+	database *sql.DB
+}
+
+func (revokedTokenRepository RevokedTokenRepository) RevokeAccessToken(tokenId string, expiresAt time.Time) error {
+	revokeAccessTokenSql := `
+	INSERT INTO RevokedAccessTokens(token_id, expires_at)
+	VALUES (?, ?)
+	ON CONFLICT(token_id) DO NOTHING`
+
+	statement, err := revokedTokenRepository.database.Prepare(revokeAccessTokenSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(tokenId, expiresAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (revokedTokenRepository RevokedTokenRepository) IsAccessTokenRevoked(tokenId string) (bool, error) {
+	isRevokedSql := `SELECT COUNT(*) FROM RevokedAccessTokens WHERE token_id = ?`
+
+	statement, err := revokedTokenRepository.database.Prepare(isRevokedSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	var count int64
+
+	err = statement.QueryRow(tokenId).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (revokedTokenRepository RevokedTokenRepository) DeleteExpiredAccessTokens(now time.Time) error {
+	deleteExpiredSql := `DELETE FROM RevokedAccessTokens WHERE expires_at <= ?`
+
+	statement, err := revokedTokenRepository.database.Prepare(deleteExpiredSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(now)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func NewRevokedTokenRepository(database *sql.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RevokedTokenRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *RevokedTokenRepository
+}
+
+func TestRevokedTokenRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &RevokedTokenRepositoryUnitTestSuite{})
+}
+
+func (suite *RevokedTokenRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewRevokedTokenRepository(db)
+}
+
+func (suite *RevokedTokenRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func (suite *RevokedTokenRepositoryUnitTestSuite) TestRevokeAccessToken_PreparesTheSqlStatement() {
+
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO RevokedAccessTokens(token_id, expires_at)
+	VALUES (?, ?)
+	ON CONFLICT(token_id) DO NOTHING`).
+		ExpectExec().
+		WithArgs("token id", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.RevokeAccessToken("token id", expiresAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *RevokedTokenRepositoryUnitTestSuite) TestIsAccessTokenRevoked_ReturnsTrue() {
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM RevokedAccessTokens WHERE token_id = ?`).
+		ExpectQuery().
+		WithArgs("token id").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := suite.repository.IsAccessTokenRevoked("token id")
+
+	suite.Nil(err)
+	suite.True(revoked)
+}
+
+// When an error occurs when preparing / executing the sql, will return the error
+func (suite *RevokedTokenRepositoryUnitTestSuite) TestIsAccessTokenRevoked_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`SELECT COUNT(*) FROM RevokedAccessTokens WHERE token_id = ?`).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.IsAccessTokenRevoked("token id")
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *RevokedTokenRepositoryUnitTestSuite) TestDeleteExpiredAccessTokens_PreparesTheSqlStatement() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`DELETE FROM RevokedAccessTokens WHERE expires_at <= ?`).
+		ExpectExec().
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := suite.repository.DeleteExpiredAccessTokens(now)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
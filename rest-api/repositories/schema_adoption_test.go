@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"example.com/config"
+	"example.com/services"
+	"github.com/stretchr/testify/suite"
+)
+
+// Migrates an actual SQLite database created by the first release, before migrations and the
+// columns added since existed
+type SchemaAdoptionTestSuite struct {
+	suite.Suite
+	database *sql.DB
+}
+
+func TestSchemaAdoptionTestSuite(t *testing.T) {
+	suite.Run(t, &SchemaAdoptionTestSuite{})
+}
+
+// Schema and rows of a database created by the first release
+const baselineSchemaSql = `
+CREATE TABLE Users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT NOT NULL UNIQUE,
+	password TEXT NOT NULL
+);
+
+CREATE TABLE Events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	location TEXT NOT NULL,
+	date DATETIME NOT NULL,
+	user_id INTEGER,
+	FOREIGN KEY(user_id) REFERENCES Users(id)
+);
+
+CREATE TABLE Registrations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER,
+	user_id INTEGER,
+	FOREIGN KEY(event_id) REFERENCES Events(id)
+	FOREIGN KEY(user_id) REFERENCES User(id)
+);
+
+INSERT INTO Users(id, email, password) VALUES (1, 'early@test.com', 'hash');
+INSERT INTO Events(id, name, description, location, date, user_id) VALUES (1, 'Meetup', 'Monthly meetup', 'Lyon', '2030-05-01 18:00:00', 1);
+INSERT INTO Registrations(event_id, user_id) VALUES (1, 1);
+`
+
+func (suite *SchemaAdoptionTestSuite) SetupTest() {
+	databaseFile := filepath.Join(suite.T().TempDir(), "api.db")
+
+	baselineDatabase, err := sql.Open(config.SQLITE_DATABASE_DRIVER, databaseFile)
+
+	suite.Require().Nil(err)
+
+	_, err = baselineDatabase.Exec(baselineSchemaSql)
+
+	suite.Require().Nil(err)
+	suite.Require().Nil(baselineDatabase.Close())
+
+	suite.database, err = config.OpenDatabase(config.SQLITE_DATABASE_DRIVER, databaseFile)
+
+	suite.Require().Nil(err)
+
+	migrationService := services.NewMigrationService(NewMigrationRepository(suite.database), config.DriverMigrationFiles(config.SQLITE_DATABASE_DRIVER))
+
+	_, err = migrationService.MigrateUp()
+
+	suite.Require().Nil(err)
+}
+
+func (suite *SchemaAdoptionTestSuite) TearDownTest() {
+	suite.database.Close()
+}
+
+// Users who signed up before verification existed keep being able to log in
+func (suite *SchemaAdoptionTestSuite) TestMigrateUp_AddsTheMissingUserColumns() {
+
+	user, err := NewUserRepository(suite.database).GetUserByEmail("early@test.com")
+
+	suite.Nil(err)
+	suite.Equal(int64(1), user.Id)
+	suite.Equal("user", user.Role)
+	suite.NotNil(user.VerifiedAt)
+}
+
+func (suite *SchemaAdoptionTestSuite) TestMigrateUp_AddsTheMissingEventColumns() {
+
+	events, err := NewEventRepository(suite.database).GetEventsByOrganizer(1, 0)
+
+	suite.Nil(err)
+	suite.Equal([]int64{1}, eventIds(events))
+	suite.Equal("public", events[0].Visibility)
+	suite.Nil(events[0].Latitude)
+}
+
+func (suite *SchemaAdoptionTestSuite) TestMigrateUp_KeepsTheRegistrations() {
+
+	registered, err := NewRegistrationRepository(suite.database).IsRegistered(1, 1, 0)
+
+	suite.Nil(err)
+	suite.True(registered)
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+const sessionColumns = `id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at, organization_id`
+
+type SessionRepository struct {
+	database *sql.DB
+}
+
+// Creates the session, or records the latest client, expiry and organization of an existing one
+// when its tokens are refreshed
+func (sessionRepository SessionRepository) SaveSession(session models.Session) error {
+	saveSessionSql := `
+	INSERT INTO Sessions(id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, organization_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		ip_address = excluded.ip_address,
+		user_agent = excluded.user_agent,
+		last_seen_at = excluded.last_seen_at,
+		expires_at = excluded.expires_at,
+		organization_id = excluded.organization_id`
+
+	statement, err := sessionRepository.database.Prepare(saveSessionSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(
+		session.Id,
+		session.UserId,
+		session.IpAddress,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+		session.OrganizationId)
+
+	return err
+}
+
+// Switches the organization the following tokens of the session act in
+func (sessionRepository SessionRepository) UpdateSessionOrganization(id string, organizationId int64) error {
+	updateOrganizationSql := `UPDATE Sessions SET organization_id = ? WHERE id = ?`
+
+	statement, err := sessionRepository.database.Prepare(updateOrganizationSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(organizationId, id)
+
+	return err
+}
+
+// Returns a session with an empty id when no session matches the id
+func (sessionRepository SessionRepository) GetSession(id string) (*models.Session, error) {
+	sessionSql := `SELECT ` + sessionColumns + ` FROM Sessions WHERE id = ?`
+
+	statement, err := sessionRepository.database.Prepare(sessionSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	session, err := scanSession(statement.QueryRow(id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.Session{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Returns the sessions of the user that were neither revoked nor expired, most recently seen first
+func (sessionRepository SessionRepository) GetActiveSessionsByUserId(userId int64, now time.Time) ([]models.Session, error) {
+	activeSessionsSql := `
+	SELECT ` + sessionColumns + `
+	FROM Sessions
+	WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	ORDER BY last_seen_at DESC`
+
+	statement, err := sessionRepository.database.Prepare(activeSessionsSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query(userId, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []models.Session{}
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Records when the session was last used, unless it was already recorded after throttledBefore, so
+// requests do not all write to the database
+func (sessionRepository SessionRepository) MarkSessionSeen(id string, seenAt, throttledBefore time.Time) error {
+	markSeenSql := `
+	UPDATE Sessions
+	SET last_seen_at = ?
+	WHERE id = ? AND last_seen_at < ?`
+
+	statement, err := sessionRepository.database.Prepare(markSeenSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(seenAt, id, throttledBefore)
+
+	return err
+}
+
+// Ends the session when it belongs to the user, along with the refresh tokens of its family.
+// Returns false when the user has no such active session
+func (sessionRepository SessionRepository) RevokeSession(id string, userId int64, revokedAt time.Time) (bool, error) {
+	transaction, err := sessionRepository.database.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	result, err := transaction.Exec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, revokedAt, id, userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if affectedRows == 0 {
+		return false, nil
+	}
+
+	_, err = transaction.Exec(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE family_id = ? AND revoked_at IS NULL`, revokedAt, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, transaction.Commit()
+}
+
+func scanSession(scanner rowScanner) (models.Session, error) {
+	var session models.Session
+	var revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&session.Id,
+		&session.UserId,
+		&session.IpAddress,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&revokedAt,
+		&session.OrganizationId)
+
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+
+	return session, nil
+}
+
+func NewSessionRepository(database *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		database: database,
+	}
+}
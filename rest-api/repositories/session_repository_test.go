@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *SessionRepository
+}
+
+func TestSessionRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &SessionRepositoryUnitTestSuite{})
+}
+
+func (suite *SessionRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewSessionRepository(db)
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TestSaveSession_PreparesTheSqlStatement() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO Sessions(id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, organization_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(id) DO UPDATE SET
+		ip_address = excluded.ip_address,
+		user_agent = excluded.user_agent,
+		last_seen_at = excluded.last_seen_at,
+		expires_at = excluded.expires_at,
+		organization_id = excluded.organization_id`).
+		ExpectExec().
+		WithArgs("session", 12, "10.0.0.1", "curl", now, now, expiresAt, 4).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.repository.SaveSession(models.Session{
+		Id:         "session",
+		UserId:     12,
+		IpAddress:  "10.0.0.1",
+		UserAgent:  "curl",
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+		//the organization the session acts in is kept across refreshes
+		OrganizationId: 4,
+	})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TestGetSession_ReturnsTheSession() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + sessionColumns + ` FROM Sessions WHERE id = ?`).
+		ExpectQuery().
+		WithArgs("session").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"user_id",
+			"ip_address",
+			"user_agent",
+			"created_at",
+			"last_seen_at",
+			"expires_at",
+			"revoked_at",
+			"organization_id",
+		}).AddRow("session", 12, "10.0.0.1", "curl", now, now, now.Add(time.Hour), now, 4))
+
+	session, err := suite.repository.GetSession("session")
+
+	suite.Nil(err)
+	suite.Equal("session", session.Id)
+	suite.Equal(int64(12), session.UserId)
+	suite.Equal("curl", session.UserAgent)
+	suite.Equal(now, *session.RevokedAt)
+	suite.Equal(int64(4), session.OrganizationId)
+}
+
+// When no session matches, return a session with an empty id
+func (suite *SessionRepositoryUnitTestSuite) TestGetSessionUnknownId_ReturnsAnEmptySession() {
+
+	suite.dbMock.ExpectPrepare(`SELECT ` + sessionColumns + ` FROM Sessions WHERE id = ?`).
+		ExpectQuery().
+		WithArgs("session").
+		WillReturnError(sql.ErrNoRows)
+
+	session, err := suite.repository.GetSession("session")
+
+	suite.Nil(err)
+	suite.Equal("", session.Id)
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TestGetActiveSessionsByUserId_ReturnsTheSessions() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT `+sessionColumns+`
+	FROM Sessions
+	WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	ORDER BY last_seen_at DESC`).
+		ExpectQuery().
+		WithArgs(12, now).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"user_id",
+			"ip_address",
+			"user_agent",
+			"created_at",
+			"last_seen_at",
+			"expires_at",
+			"revoked_at",
+			"organization_id",
+		}).
+			AddRow("first", 12, "10.0.0.1", "curl", now, now, now.Add(time.Hour), nil, 0).
+			AddRow("second", 12, "10.0.0.2", "firefox", now, now, now.Add(time.Hour), nil, 0))
+
+	sessions, err := suite.repository.GetActiveSessionsByUserId(12, now)
+
+	suite.Nil(err)
+	suite.Len(sessions, 2)
+	suite.Equal("second", sessions[1].Id)
+	suite.Nil(sessions[1].RevokedAt)
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TestMarkSessionSeen_PreparesTheSqlStatement() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttledBefore := now.Add(-time.Minute)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Sessions
+	SET last_seen_at = ?
+	WHERE id = ? AND last_seen_at < ?`).
+		ExpectExec().
+		WithArgs(now, "session", throttledBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.MarkSessionSeen("session", now, throttledBefore)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// The refresh tokens of the session are revoked along with it
+func (suite *SessionRepositoryUnitTestSuite) TestRevokeSession_RevokesTheRefreshTokens() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, "session", 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`
+	UPDATE RefreshTokens
+	SET revoked_at = ?
+	WHERE family_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, "session").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	revoked, err := suite.repository.RevokeSession("session", 12, now)
+
+	suite.Nil(err)
+	suite.True(revoked)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When the user has no such active session, nothing is revoked
+func (suite *SessionRepositoryUnitTestSuite) TestRevokeSessionOfAnotherUser_ReturnsFalse() {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`).
+		WithArgs(now, "session", 12).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+
+	revoked, err := suite.repository.RevokeSession("session", 12, now)
+
+	suite.Nil(err)
+	suite.False(revoked)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When an error occurs when executing the sql, will return the error
+func (suite *SessionRepositoryUnitTestSuite) TestRevokeSession_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`
+	UPDATE Sessions
+	SET revoked_at = ?
+	WHERE id = ? AND user_id = ? AND revoked_at IS NULL`).
+		WillReturnError(expectedError)
+	suite.dbMock.ExpectRollback()
+
+	_, err := suite.repository.RevokeSession("session", 12, time.Now())
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *SessionRepositoryUnitTestSuite) TestUpdateSessionOrganization_PreparesTheSqlStatement() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE Sessions SET organization_id = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(4, "session").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.UpdateSessionOrganization("session", 4)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,600 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+	"example.com/services"
+	"github.com/stretchr/testify/suite"
+)
+
+// Runs the repositories against an actual SQLite database, through the queries the sql mocks only
+// compare as text
+type SqliteDatabaseTestSuite struct {
+	suite.Suite
+	database               *sql.DB
+	migrationService       *services.MigrationService
+	eventRepository        repositoryInterfaces.IEventRepository
+	userRepository         repositoryInterfaces.IUserRepository
+	registrationRepository repositoryInterfaces.IRegistrationRepository
+}
+
+func TestSqliteDatabaseTestSuite(t *testing.T) {
+	suite.Run(t, &SqliteDatabaseTestSuite{})
+}
+
+func (suite *SqliteDatabaseTestSuite) SetupTest() {
+	database, err := config.OpenDatabase(config.SQLITE_DATABASE_DRIVER, filepath.Join(suite.T().TempDir(), "api.db"))
+
+	suite.Require().Nil(err)
+
+	suite.database = database
+	suite.eventRepository = NewEventRepository(suite.database)
+	suite.userRepository = NewUserRepository(suite.database)
+	suite.registrationRepository = NewRegistrationRepository(suite.database)
+	suite.migrationService = services.NewMigrationService(NewMigrationRepository(suite.database), config.DriverMigrationFiles(config.SQLITE_DATABASE_DRIVER))
+
+	_, err = suite.migrationService.MigrateUp()
+
+	suite.Require().Nil(err)
+}
+
+func (suite *SqliteDatabaseTestSuite) TearDownTest() {
+	suite.database.Close()
+}
+
+// Runs a statement without parameters
+func (suite *SqliteDatabaseTestSuite) exec(query string) {
+	_, err := suite.database.Exec(query)
+
+	suite.Require().Nil(err)
+}
+
+func (suite *SqliteDatabaseTestSuite) createUser(email string) int64 {
+	user := models.User{Email: email, Password: "hash"}
+
+	suite.Require().Nil(suite.userRepository.CreateUser(&user))
+
+	return user.Id
+}
+
+func (suite *SqliteDatabaseTestSuite) createOrganization(name string) int64 {
+	suite.exec(fmt.Sprintf(`INSERT INTO Organizations(name, created_at) VALUES ('%v', '2024-01-01 00:00:00')`, name))
+
+	var id int64
+
+	suite.Require().Nil(suite.database.QueryRow(fmt.Sprintf(`SELECT id FROM Organizations WHERE name = '%v'`, name)).Scan(&id))
+
+	return id
+}
+
+func (suite *SqliteDatabaseTestSuite) addEvent(event models.Event) models.Event {
+	if event.Name == "" {
+		event.Name = "Meetup"
+	}
+
+	if event.Date.IsZero() {
+		event.Date = time.Date(2030, 5, 1, 18, 0, 0, 0, time.UTC)
+	}
+
+	if event.Visibility == "" {
+		event.Visibility = models.PUBLIC_VISIBILITY
+	}
+
+	event.Description = "Monthly meetup"
+	event.Location = "Lyon"
+
+	suite.Require().Nil(suite.eventRepository.AddEvent(&event))
+
+	return event
+}
+
+func eventIds(events []models.Event) []int64 {
+	ids := []int64{}
+
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+
+	return ids
+}
+
+// A process outliving the lock timeout does not release the lock of the process that took it over
+func (suite *SqliteDatabaseTestSuite) TestReleaseMigrationLockTakenOver_KeepsTheLock() {
+
+	migrationRepository := NewMigrationRepository(suite.database)
+	lockedAt := time.Now().UTC()
+	takenOverAt := lockedAt.Add(10 * time.Minute)
+
+	suite.Require().Nil(migrationRepository.AcquireMigrationLock(lockedAt, lockedAt.Add(-5*time.Minute)))
+	suite.Require().Nil(migrationRepository.AcquireMigrationLock(takenOverAt, takenOverAt.Add(-5*time.Minute)))
+
+	suite.Nil(migrationRepository.ReleaseMigrationLock(lockedAt))
+
+	err := migrationRepository.AcquireMigrationLock(takenOverAt, takenOverAt.Add(-5*time.Minute))
+
+	suite.NotNil(err)
+	suite.Equal(constants.MIGRATION_LOCKED_ERROR, err.Error())
+
+	suite.Nil(migrationRepository.ReleaseMigrationLock(takenOverAt))
+	suite.Nil(migrationRepository.AcquireMigrationLock(takenOverAt, takenOverAt.Add(-5*time.Minute)))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestMigrateDown_RevertsEveryMigration() {
+
+	statuses, err := suite.migrationService.GetStatus()
+
+	suite.Require().Nil(err)
+
+	reverted, err := suite.migrationService.MigrateDown(len(statuses))
+
+	suite.Nil(err)
+	suite.Len(reverted, len(statuses))
+
+	applied, err := suite.migrationService.MigrateUp()
+
+	suite.Nil(err)
+	suite.Len(applied, len(statuses))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestCreateUser_AssignsAnId() {
+
+	firstId := suite.createUser("first@test.com")
+	secondId := suite.createUser("second@test.com")
+
+	user, err := suite.userRepository.GetUserByEmail("second@test.com")
+
+	suite.Nil(err)
+	suite.NotZero(firstId)
+	suite.NotEqual(firstId, secondId)
+	suite.Equal(secondId, user.Id)
+	suite.Equal("hash", user.Password)
+	suite.Equal(models.USER_ROLE, user.Role)
+	suite.Nil(user.VerifiedAt)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestCreateUserWithTakenEmail_ReturnsAnError() {
+
+	suite.createUser("taken@test.com")
+
+	err := suite.userRepository.CreateUser(&models.User{Email: "taken@test.com", Password: "hash"})
+
+	suite.NotNil(err)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetUserByUnknownEmail_ReturnsNoUserForEmail() {
+
+	_, err := suite.userRepository.GetUserByEmail("unknown@test.com")
+
+	suite.Equal(constants.NO_USER_FOR_EMAIL_ERROR, err.Error())
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetUserByUnknownId_ReturnsAnEmptyUser() {
+
+	user, err := suite.userRepository.GetUserById(404)
+
+	suite.Nil(err)
+	suite.Zero(user.Id)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetUsers_ReturnsTheUsersById() {
+
+	firstId := suite.createUser("first@test.com")
+	secondId := suite.createUser("second@test.com")
+
+	users, err := suite.userRepository.GetUsers()
+
+	suite.Nil(err)
+	suite.Len(users, 2)
+	suite.Equal(firstId, users[0].Id)
+	suite.Equal(secondId, users[1].Id)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestUpdateUserRole_ChangesTheRole() {
+
+	id := suite.createUser("admin@test.com")
+
+	err := suite.userRepository.UpdateUserRole(id, models.ADMIN_ROLE)
+
+	user, _ := suite.userRepository.GetUserById(id)
+
+	suite.Nil(err)
+	suite.Equal(models.ADMIN_ROLE, user.Role)
+}
+
+// A password changed since the hash was read is not overwritten
+func (suite *SqliteDatabaseTestSuite) TestReplaceOutdatedPasswordHash_ReturnsNoUserForId() {
+
+	id := suite.createUser("user@test.com")
+
+	suite.Require().Nil(suite.userRepository.UpdateUserPassword(id, "changed"))
+
+	err := suite.userRepository.ReplaceUserPasswordHash(id, "hash", "rehashed")
+
+	user, _ := suite.userRepository.GetUserById(id)
+
+	suite.Equal(constants.NO_USER_FOR_ID_ERROR, err.Error())
+	suite.Equal("changed", user.Password)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestSetUserDisabledAt_DisablesAndEnablesTheUser() {
+
+	id := suite.createUser("user@test.com")
+	disabledAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := suite.userRepository.SetUserDisabledAt(id, &disabledAt)
+
+	user, _ := suite.userRepository.GetUserById(id)
+
+	suite.Nil(err)
+	suite.Require().NotNil(user.DisabledAt)
+	suite.True(disabledAt.Equal(*user.DisabledAt))
+
+	err = suite.userRepository.SetUserDisabledAt(id, nil)
+
+	user, _ = suite.userRepository.GetUserById(id)
+
+	suite.Nil(err)
+	suite.Nil(user.DisabledAt)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestUpdateUnknownUser_ReturnsNoUserForId() {
+
+	err := suite.userRepository.SetUserVerifiedAt(404, time.Now())
+
+	suite.Equal(constants.NO_USER_FOR_ID_ERROR, err.Error())
+}
+
+func (suite *SqliteDatabaseTestSuite) TestUpdateUserEmail_VerifiesTheNewEmail() {
+
+	id := suite.createUser("old@test.com")
+	verifiedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := suite.userRepository.UpdateUserEmail(id, "new@test.com", verifiedAt)
+
+	user, _ := suite.userRepository.GetUserByEmail("new@test.com")
+
+	suite.Nil(err)
+	suite.Equal(id, user.Id)
+	suite.Require().NotNil(user.VerifiedAt)
+	suite.True(verifiedAt.Equal(*user.VerifiedAt))
+}
+
+// A second email within the throttling window is not sent
+func (suite *SqliteDatabaseTestSuite) TestMarkVerificationEmailSent_ThrottlesRecentEmails() {
+
+	id := suite.createUser("user@test.com")
+	sentAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := suite.userRepository.MarkVerificationEmailSent(id, sentAt, sentAt.Add(-time.Minute))
+
+	suite.Nil(err)
+	suite.True(first)
+
+	second, err := suite.userRepository.MarkVerificationEmailSent(id, sentAt.Add(time.Second), sentAt.Add(-time.Minute))
+
+	suite.Nil(err)
+	suite.False(second)
+
+	later, err := suite.userRepository.MarkVerificationEmailSent(id, sentAt.Add(2*time.Minute), sentAt.Add(time.Minute))
+
+	suite.Nil(err)
+	suite.True(later)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestAnonymizeUser_KeepsTheEventsAndRegistrations() {
+
+	id := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: id})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, id, 0))
+
+	err := suite.userRepository.AnonymizeUser(id, "deleted-user@invalid", time.Now().UTC())
+
+	user, _ := suite.userRepository.GetUserById(id)
+	storedEvent, _ := suite.eventRepository.GetEventById(event.Id, 0)
+	registered, _ := suite.registrationRepository.IsRegistered(event.Id, id, 0)
+
+	suite.Nil(err)
+	suite.Equal("deleted-user@invalid", user.Email)
+	suite.Empty(user.Password)
+	suite.NotNil(user.DisabledAt)
+	suite.Equal(event.Id, storedEvent.Id)
+	suite.True(registered)
+}
+
+// The events of the user go along with their registrations, including the ones of other users
+func (suite *SqliteDatabaseTestSuite) TestDeleteUser_DeletesTheEventsAndRegistrations() {
+
+	id := suite.createUser("user@test.com")
+	otherId := suite.createUser("other@test.com")
+	event := suite.addEvent(models.Event{UserId: id})
+	otherEvent := suite.addEvent(models.Event{UserId: otherId})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, otherId, 0))
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(otherEvent.Id, id, 0))
+
+	err := suite.userRepository.DeleteUser(id, time.Now().UTC())
+
+	user, _ := suite.userRepository.GetUserById(id)
+	storedEvent, _ := suite.eventRepository.GetEventById(event.Id, 0)
+	count, _ := suite.registrationRepository.CountRegistrations(otherEvent.Id, 0)
+	otherEvents, _ := suite.eventRepository.GetEventsByRegistrant(otherId, 0)
+
+	suite.Nil(err)
+	suite.Zero(user.Id)
+	suite.Zero(storedEvent.Id)
+	suite.Zero(count)
+	suite.Empty(otherEvents)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestDeleteUnknownUser_ReturnsNoUserForId() {
+
+	err := suite.userRepository.DeleteUser(404, time.Now().UTC())
+
+	suite.Equal(constants.NO_USER_FOR_ID_ERROR, err.Error())
+}
+
+func (suite *SqliteDatabaseTestSuite) TestAddEvent_StoresTheEvent() {
+
+	userId := suite.createUser("user@test.com")
+	latitude, longitude := 45.76, 4.83
+	endDate := time.Date(2030, 5, 1, 21, 0, 0, 0, time.UTC)
+
+	event := suite.addEvent(models.Event{
+		UserId:    userId,
+		Latitude:  &latitude,
+		Longitude: &longitude,
+		EndDate:   &endDate,
+	})
+
+	storedEvent, err := suite.eventRepository.GetEventById(event.Id, 0)
+
+	suite.Nil(err)
+	suite.NotZero(event.Id)
+	suite.Equal(event.Name, storedEvent.Name)
+	suite.Equal(event.Description, storedEvent.Description)
+	suite.Equal(event.Location, storedEvent.Location)
+	suite.True(event.Date.Equal(storedEvent.Date))
+	suite.Require().NotNil(storedEvent.EndDate)
+	suite.True(endDate.Equal(*storedEvent.EndDate))
+	suite.Equal(userId, storedEvent.UserId)
+	suite.Equal(&latitude, storedEvent.Latitude)
+	suite.Equal(&longitude, storedEvent.Longitude)
+	suite.Equal(models.PUBLIC_VISIBILITY, storedEvent.Visibility)
+	suite.Zero(storedEvent.OrganizationId)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetEventById_StaysInTheTenant() {
+
+	userId := suite.createUser("user@test.com")
+	organizationId := suite.createOrganization("Acme")
+	event := suite.addEvent(models.Event{UserId: userId, OrganizationId: organizationId})
+
+	organizationEvent, err := suite.eventRepository.GetEventById(event.Id, organizationId)
+
+	suite.Nil(err)
+	suite.Equal(event.Id, organizationEvent.Id)
+	suite.Equal(organizationId, organizationEvent.OrganizationId)
+
+	personalEvent, err := suite.eventRepository.GetEventById(event.Id, 0)
+
+	suite.Nil(err)
+	suite.Zero(personalEvent.Id)
+}
+
+// Private events are listed to their organizer and the invited users only
+func (suite *SqliteDatabaseTestSuite) TestGetEvents_ReturnsTheVisibleEvents() {
+
+	organizerId := suite.createUser("organizer@test.com")
+	invitedId := suite.createUser("invited@test.com")
+	viewerId := suite.createUser("viewer@test.com")
+	publicEvent := suite.addEvent(models.Event{UserId: organizerId})
+	privateEvent := suite.addEvent(models.Event{UserId: organizerId, Visibility: models.PRIVATE_VISIBILITY})
+
+	suite.exec(fmt.Sprintf(`
+	INSERT INTO Invitations(event_id, user_id, token_hash, expires_at, created_at)
+	VALUES (%v, %v, 'token', '2100-01-01 00:00:00', '2024-01-01 00:00:00')`, privateEvent.Id, invitedId))
+
+	organizerEvents, err := suite.eventRepository.GetEvents(organizerId, 0)
+
+	suite.Nil(err)
+	suite.ElementsMatch([]int64{publicEvent.Id, privateEvent.Id}, eventIds(organizerEvents))
+
+	invitedEvents, err := suite.eventRepository.GetEvents(invitedId, 0)
+
+	suite.Nil(err)
+	suite.ElementsMatch([]int64{publicEvent.Id, privateEvent.Id}, eventIds(invitedEvents))
+
+	viewerEvents, err := suite.eventRepository.GetEvents(viewerId, 0)
+
+	suite.Nil(err)
+	suite.Equal([]int64{publicEvent.Id}, eventIds(viewerEvents))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetEventsOfEmptyTenant_ReturnsAnEmptyArray() {
+
+	events, err := suite.eventRepository.GetEvents(1, 0)
+
+	suite.Nil(err)
+	suite.NotNil(events)
+	suite.Empty(events)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetEventsWithinBounds_WrapsAroundTheAntimeridian() {
+
+	userId := suite.createUser("user@test.com")
+	latitude, fijiLongitude, samoaLongitude, lyonLongitude := -15.0, 178.0, -172.0, 4.83
+
+	fijiEvent := suite.addEvent(models.Event{UserId: userId, Latitude: &latitude, Longitude: &fijiLongitude})
+	samoaEvent := suite.addEvent(models.Event{UserId: userId, Latitude: &latitude, Longitude: &samoaLongitude})
+	suite.addEvent(models.Event{UserId: userId, Latitude: &latitude, Longitude: &lyonLongitude})
+
+	events, err := suite.eventRepository.GetEventsWithinBounds(models.BoundingBox{
+		MinLatitude:  -20,
+		MaxLatitude:  -10,
+		MinLongitude: 170,
+		MaxLongitude: -170,
+	}, userId, 0)
+
+	suite.Nil(err)
+	suite.ElementsMatch([]int64{fijiEvent.Id, samoaEvent.Id}, eventIds(events))
+
+	events, err = suite.eventRepository.GetEventsWithinBounds(models.BoundingBox{
+		MinLatitude:  -20,
+		MaxLatitude:  -10,
+		MinLongitude: 175,
+		MaxLongitude: 180,
+	}, userId, 0)
+
+	suite.Nil(err)
+	suite.Equal([]int64{fijiEvent.Id}, eventIds(events))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestUpdateEvent_ReplacesTheEvent() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	event.Name = "Renamed"
+	event.Visibility = models.PRIVATE_VISIBILITY
+
+	err := suite.eventRepository.UpdateEvent(event.Id, 0, event)
+
+	storedEvent, _ := suite.eventRepository.GetEventById(event.Id, 0)
+
+	suite.Nil(err)
+	suite.Equal("Renamed", storedEvent.Name)
+	suite.Equal(models.PRIVATE_VISIBILITY, storedEvent.Visibility)
+}
+
+// Events of another tenant are left as they are
+func (suite *SqliteDatabaseTestSuite) TestUpdateEventOfAnotherTenant_ChangesNothing() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	event.Name = "Renamed"
+
+	err := suite.eventRepository.UpdateEvent(event.Id, suite.createOrganization("Acme"), event)
+
+	storedEvent, _ := suite.eventRepository.GetEventById(event.Id, 0)
+
+	suite.Nil(err)
+	suite.Equal("Meetup", storedEvent.Name)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestDeleteEvent_DeletesItsRegistrations() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, userId, 0))
+
+	err := suite.eventRepository.DeleteEvent(event.Id, 0)
+
+	storedEvent, _ := suite.eventRepository.GetEventById(event.Id, 0)
+	registeredEvents, _ := suite.eventRepository.GetEventsByRegistrant(userId, 0)
+
+	suite.Nil(err)
+	suite.Zero(storedEvent.Id)
+	suite.Empty(registeredEvents)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestGetEventsByOrganizer_ReturnsTheEarliestFirst() {
+
+	userId := suite.createUser("user@test.com")
+	otherId := suite.createUser("other@test.com")
+	laterEvent := suite.addEvent(models.Event{UserId: userId, Date: time.Date(2030, 6, 1, 18, 0, 0, 0, time.UTC)})
+	earlierEvent := suite.addEvent(models.Event{UserId: userId, Date: time.Date(2030, 4, 1, 18, 0, 0, 0, time.UTC)})
+	suite.addEvent(models.Event{UserId: otherId})
+
+	events, err := suite.eventRepository.GetEventsByOrganizer(userId, 0)
+
+	suite.Nil(err)
+	suite.Equal([]int64{earlierEvent.Id, laterEvent.Id}, eventIds(events))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestCreateRegistration_RegistersTheUser() {
+
+	userId := suite.createUser("user@test.com")
+	otherId := suite.createUser("other@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, userId, 0))
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, otherId, 0))
+
+	registered, err := suite.registrationRepository.IsRegistered(event.Id, otherId, 0)
+
+	suite.Nil(err)
+	suite.True(registered)
+
+	count, err := suite.registrationRepository.CountRegistrations(event.Id, 0)
+
+	suite.Nil(err)
+	suite.Equal(int64(2), count)
+
+	registeredEvents, err := suite.eventRepository.GetEventsByRegistrant(otherId, 0)
+
+	suite.Nil(err)
+	suite.Equal([]int64{event.Id}, eventIds(registeredEvents))
+}
+
+func (suite *SqliteDatabaseTestSuite) TestCreateRegistrationTwice_ReturnsDuplicateRecord() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, userId, 0))
+
+	err := suite.registrationRepository.CreateRegistration(event.Id, userId, 0)
+
+	suite.Equal(constants.DUPLICATE_RECORD_ERROR, err.Error())
+}
+
+func (suite *SqliteDatabaseTestSuite) TestCreateRegistrationOfUnknownUser_ReturnsMissingReferencedRecord() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	err := suite.registrationRepository.CreateRegistration(event.Id, 404, 0)
+
+	suite.Equal(constants.MISSING_REFERENCED_RECORD_ERROR, err.Error())
+}
+
+// Events of another tenant cannot be registered for
+func (suite *SqliteDatabaseTestSuite) TestCreateRegistrationInAnotherTenant_RegistersNobody() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+	organizationId := suite.createOrganization("Acme")
+
+	err := suite.registrationRepository.CreateRegistration(event.Id, userId, organizationId)
+
+	registered, _ := suite.registrationRepository.IsRegistered(event.Id, userId, 0)
+
+	suite.Nil(err)
+	suite.False(registered)
+}
+
+func (suite *SqliteDatabaseTestSuite) TestDeleteRegistration_UnregistersTheUser() {
+
+	userId := suite.createUser("user@test.com")
+	event := suite.addEvent(models.Event{UserId: userId})
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(event.Id, userId, 0))
+
+	err := suite.registrationRepository.DeleteRegistration(event.Id, userId, 0)
+
+	registered, _ := suite.registrationRepository.IsRegistered(event.Id, userId, 0)
+
+	suite.Nil(err)
+	suite.False(registered)
+}
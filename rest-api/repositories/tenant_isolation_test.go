@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/config"
+	"example.com/models"
+	"example.com/services"
+	"github.com/stretchr/testify/suite"
+)
+
+// Runs the tenant scoped statements against an actual SQLite database seeded with two organizations,
+// the records of one organization must never be reached from the other
+type TenantIsolationTestSuite struct {
+	suite.Suite
+	database               *sql.DB
+	eventRepository        *EventRepository
+	registrationRepository *RegistrationRepository
+	organizationRepository *OrganizationRepository
+	userId                 int64
+	acmeId                 int64
+	globexId               int64
+	acmeEvent              models.Event
+	globexEvent            models.Event
+}
+
+func TestTenantIsolationTestSuite(t *testing.T) {
+	suite.Run(t, &TenantIsolationTestSuite{})
+}
+
+// Opens a SQLite database in a temporary directory with every migration applied
+func openMigratedSqliteDatabase(t *testing.T) *sql.DB {
+	database, err := config.OpenDatabase(config.SQLITE_DATABASE_DRIVER, filepath.Join(t.TempDir(), "api.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrationService := services.NewMigrationService(NewMigrationRepository(database), config.DriverMigrationFiles(config.SQLITE_DATABASE_DRIVER))
+
+	_, err = migrationService.MigrateUp()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return database
+}
+
+func (suite *TenantIsolationTestSuite) SetupTest() {
+	suite.database = openMigratedSqliteDatabase(suite.T())
+	suite.eventRepository = NewEventRepository(suite.database)
+	suite.registrationRepository = NewRegistrationRepository(suite.database)
+	suite.organizationRepository = NewOrganizationRepository(suite.database)
+
+	user := models.User{Email: "member@test.com", Password: "hash"}
+
+	suite.Require().Nil(NewUserRepository(suite.database).CreateUser(&user))
+
+	suite.userId = user.Id
+	suite.acmeId = suite.createOrganization("Acme")
+	suite.globexId = suite.createOrganization("Globex")
+	suite.acmeEvent = suite.addEvent("Acme meetup", suite.acmeId)
+	suite.globexEvent = suite.addEvent("Globex meetup", suite.globexId)
+
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(suite.acmeEvent.Id, suite.userId, suite.acmeId))
+	suite.Require().Nil(suite.registrationRepository.CreateRegistration(suite.globexEvent.Id, suite.userId, suite.globexId))
+}
+
+func (suite *TenantIsolationTestSuite) TearDownTest() {
+	suite.database.Close()
+}
+
+// The user is a member of the organization, so both organizations share the same user
+func (suite *TenantIsolationTestSuite) createOrganization(name string) int64 {
+	organization := models.Organization{Name: name, CreatedAt: time.Now().UTC()}
+
+	suite.Require().Nil(suite.organizationRepository.CreateOrganization(&organization, suite.userId))
+
+	return organization.Id
+}
+
+func (suite *TenantIsolationTestSuite) addEvent(name string, organizationId int64) models.Event {
+	event := models.Event{
+		Name:           name,
+		Description:    "Monthly meetup",
+		Location:       "Lyon",
+		Date:           time.Date(2030, 5, 1, 18, 0, 0, 0, time.UTC),
+		UserId:         suite.userId,
+		Visibility:     models.PUBLIC_VISIBILITY,
+		OrganizationId: organizationId,
+	}
+
+	suite.Require().Nil(suite.eventRepository.AddEvent(&event))
+
+	return event
+}
+
+// An event of another tenant is reported as missing, the personal space included
+func (suite *TenantIsolationTestSuite) TestGetEventByIdOfAnotherTenant_ReturnsAnEmptyEvent() {
+
+	event, err := suite.eventRepository.GetEventById(suite.globexEvent.Id, suite.acmeId)
+
+	suite.Nil(err)
+	suite.Zero(event.Id)
+
+	event, err = suite.eventRepository.GetEventById(suite.globexEvent.Id, 0)
+
+	suite.Nil(err)
+	suite.Zero(event.Id)
+
+	event, err = suite.eventRepository.GetEventById(suite.globexEvent.Id, suite.globexId)
+
+	suite.Nil(err)
+	suite.Equal(suite.globexEvent.Id, event.Id)
+}
+
+func (suite *TenantIsolationTestSuite) TestGetEvents_ListsTheEventsOfTheTenantOnly() {
+
+	events, err := suite.eventRepository.GetEvents(suite.userId, suite.acmeId)
+
+	suite.Nil(err)
+	suite.Equal([]int64{suite.acmeEvent.Id}, eventIds(events))
+
+	organized, err := suite.eventRepository.GetEventsByOrganizer(suite.userId, suite.acmeId)
+
+	suite.Nil(err)
+	suite.Equal([]int64{suite.acmeEvent.Id}, eventIds(organized))
+
+	registered, err := suite.eventRepository.GetEventsByRegistrant(suite.userId, suite.acmeId)
+
+	suite.Nil(err)
+	suite.Equal([]int64{suite.acmeEvent.Id}, eventIds(registered))
+}
+
+// Schedule conflicts are checked against the registrations of every tenant
+func (suite *TenantIsolationTestSuite) TestGetEventsByRegistrantInAllOrganizations_ListsEveryTenant() {
+
+	registered, err := suite.eventRepository.GetEventsByRegistrantInAllOrganizations(suite.userId)
+
+	suite.Nil(err)
+	suite.Equal([]int64{suite.acmeEvent.Id, suite.globexEvent.Id}, eventIds(registered))
+}
+
+// Updating an event of another tenant changes nothing
+func (suite *TenantIsolationTestSuite) TestUpdateEventOfAnotherTenant_ChangesNothing() {
+
+	event := suite.globexEvent
+	event.Name = "Renamed"
+
+	err := suite.eventRepository.UpdateEvent(suite.globexEvent.Id, suite.acmeId, event)
+
+	storedEvent, _ := suite.eventRepository.GetEventById(suite.globexEvent.Id, suite.globexId)
+
+	suite.Nil(err)
+	suite.Equal("Globex meetup", storedEvent.Name)
+}
+
+// Deleting an event of another tenant deletes neither the event nor its registrations
+func (suite *TenantIsolationTestSuite) TestDeleteEventOfAnotherTenant_DeletesNothing() {
+
+	err := suite.eventRepository.DeleteEvent(suite.globexEvent.Id, suite.acmeId)
+
+	storedEvent, _ := suite.eventRepository.GetEventById(suite.globexEvent.Id, suite.globexId)
+	count, _ := suite.registrationRepository.CountRegistrations(suite.globexEvent.Id, suite.globexId)
+
+	suite.Nil(err)
+	suite.Equal(suite.globexEvent.Id, storedEvent.Id)
+	suite.Equal(int64(1), count)
+}
+
+// Registering for an event of another tenant registers nobody
+func (suite *TenantIsolationTestSuite) TestCreateRegistrationForAnotherTenant_RegistersNobody() {
+
+	otherEvent := suite.addEvent("Globex workshop", suite.globexId)
+
+	err := suite.registrationRepository.CreateRegistration(otherEvent.Id, suite.userId, suite.acmeId)
+
+	registered, _ := suite.registrationRepository.IsRegistered(otherEvent.Id, suite.userId, suite.globexId)
+
+	suite.Nil(err)
+	suite.False(registered)
+}
+
+// Registrations of events of another tenant are neither counted nor reported
+func (suite *TenantIsolationTestSuite) TestCountAndIsRegisteredForAnotherTenant_ReportNothing() {
+
+	count, err := suite.registrationRepository.CountRegistrations(suite.globexEvent.Id, suite.acmeId)
+
+	suite.Nil(err)
+	suite.Zero(count)
+
+	registered, err := suite.registrationRepository.IsRegistered(suite.globexEvent.Id, suite.userId, suite.acmeId)
+
+	suite.Nil(err)
+	suite.False(registered)
+}
+
+// Unregistering from an event of another tenant leaves the registration in place
+func (suite *TenantIsolationTestSuite) TestDeleteRegistrationOfAnotherTenant_KeepsTheRegistration() {
+
+	err := suite.registrationRepository.DeleteRegistration(suite.globexEvent.Id, suite.userId, suite.acmeId)
+
+	registered, _ := suite.registrationRepository.IsRegistered(suite.globexEvent.Id, suite.userId, suite.globexId)
+
+	suite.Nil(err)
+	suite.True(registered)
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"example.com/models"
+)
+
+type TwoFactorRepository struct {
+	database *sql.DB
+}
+
+// Returns a credential with a user id of 0 when the user never enrolled
+func (twoFactorRepository TwoFactorRepository) GetTotpCredential(userId int64) (*models.TotpCredential, error) {
+	credentialSql := `
+	SELECT user_id, secret, last_used_step, created_at, confirmed_at
+	FROM TotpCredentials
+	WHERE user_id = ?`
+
+	statement, err := twoFactorRepository.database.Prepare(credentialSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var credential models.TotpCredential
+	var confirmedAt sql.NullTime
+
+	err = statement.QueryRow(userId).Scan(
+		&credential.UserId,
+		&credential.Secret,
+		&credential.LastUsedStep,
+		&credential.CreatedAt,
+		&confirmedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.TotpCredential{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedAt.Valid {
+		credential.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return &credential, nil
+}
+
+// Saves the secret of a new enrollment, replacing any enrollment that was not confirmed
+func (twoFactorRepository TwoFactorRepository) SaveTotpCredential(credential *models.TotpCredential) error {
+	saveCredentialSql := `
+	INSERT INTO TotpCredentials(user_id, secret, last_used_step, created_at)
+	VALUES (?, ?, 0, ?)
+	ON CONFLICT(user_id) DO UPDATE SET
+		secret = excluded.secret,
+		last_used_step = 0,
+		created_at = excluded.created_at,
+		confirmed_at = NULL`
+
+	return twoFactorRepository.exec(saveCredentialSql, credential.UserId, credential.Secret, credential.CreatedAt)
+}
+
+func (twoFactorRepository TwoFactorRepository) ConfirmTotpCredential(userId int64, confirmedAt time.Time) error {
+	return twoFactorRepository.exec(`UPDATE TotpCredentials SET confirmed_at = ? WHERE user_id = ?`, confirmedAt, userId)
+}
+
+// Records the time step of a used code, returns false when a code of this step or a later one
+// was already used, so concurrent requests cannot both use the same code
+func (twoFactorRepository TwoFactorRepository) UseTotpStep(userId int64, step int64) (bool, error) {
+	return twoFactorRepository.execUpdate(
+		`UPDATE TotpCredentials SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`,
+		step, userId, step)
+}
+
+func (twoFactorRepository TwoFactorRepository) DeleteTotpCredential(userId int64) error {
+	return twoFactorRepository.exec(`DELETE FROM TotpCredentials WHERE user_id = ?`, userId)
+}
+
+// Replaces every recovery code of the user, in a transaction so codes are never partially replaced
+func (twoFactorRepository TwoFactorRepository) ReplaceRecoveryCodes(userId int64, codeHashes []string) error {
+	transaction, err := twoFactorRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	_, err = transaction.Exec(`DELETE FROM RecoveryCodes WHERE user_id = ?`, userId)
+
+	if err != nil {
+		return err
+	}
+
+	statement, err := transaction.Prepare(`INSERT INTO RecoveryCodes(user_id, code_hash) VALUES (?, ?)`)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	for _, codeHash := range codeHashes {
+		_, err = statement.Exec(userId, codeHash)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return transaction.Commit()
+}
+
+// Marks the matching unused code of the user as used, returns false when there is none
+func (twoFactorRepository TwoFactorRepository) UseRecoveryCode(userId int64, codeHash string, usedAt time.Time) (bool, error) {
+	return twoFactorRepository.execUpdate(
+		`UPDATE RecoveryCodes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`,
+		usedAt, userId, codeHash)
+}
+
+func (twoFactorRepository TwoFactorRepository) DeleteRecoveryCodes(userId int64) error {
+	return twoFactorRepository.exec(`DELETE FROM RecoveryCodes WHERE user_id = ?`, userId)
+}
+
+func (twoFactorRepository TwoFactorRepository) CreateMfaChallenge(challenge *models.MfaChallenge) error {
+	createChallengeSql := `
+	INSERT INTO MfaChallenges(user_id, token_hash, failed_attempts, expires_at, created_at)
+	VALUES (?, ?, 0, ?, ?)`
+
+	statement, err := twoFactorRepository.database.Prepare(createChallengeSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(challenge.UserId, challenge.TokenHash, challenge.ExpiresAt, challenge.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	id, _ := result.LastInsertId()
+
+	challenge.Id = id
+
+	return nil
+}
+
+// Returns a challenge with an id of 0 when no challenge matches the hash
+func (twoFactorRepository TwoFactorRepository) GetMfaChallengeByHash(tokenHash string) (*models.MfaChallenge, error) {
+	challengeByHashSql := `
+	SELECT id, user_id, token_hash, failed_attempts, expires_at, created_at, used_at
+	FROM MfaChallenges
+	WHERE token_hash = ?`
+
+	statement, err := twoFactorRepository.database.Prepare(challengeByHashSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var challenge models.MfaChallenge
+	var usedAt sql.NullTime
+
+	err = statement.QueryRow(tokenHash).Scan(
+		&challenge.Id,
+		&challenge.UserId,
+		&challenge.TokenHash,
+		&challenge.FailedAttempts,
+		&challenge.ExpiresAt,
+		&challenge.CreatedAt,
+		&usedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.MfaChallenge{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		challenge.UsedAt = &usedAt.Time
+	}
+
+	return &challenge, nil
+}
+
+// Marks the challenge as used, returns false when it was already used
+func (twoFactorRepository TwoFactorRepository) MarkMfaChallengeUsed(id int64, usedAt time.Time) (bool, error) {
+	return twoFactorRepository.execUpdate(`UPDATE MfaChallenges SET used_at = ? WHERE id = ? AND used_at IS NULL`, usedAt, id)
+}
+
+func (twoFactorRepository TwoFactorRepository) RecordMfaChallengeFailure(id int64) error {
+	return twoFactorRepository.exec(`UPDATE MfaChallenges SET failed_attempts = failed_attempts + 1 WHERE id = ?`, id)
+}
+
+func (twoFactorRepository TwoFactorRepository) exec(query string, args ...any) error {
+	_, err := twoFactorRepository.execUpdate(query, args...)
+
+	return err
+}
+
+// Executes the statement, returning whether it affected a row
+func (twoFactorRepository TwoFactorRepository) execUpdate(query string, args ...any) (bool, error) {
+	statement, err := twoFactorRepository.database.Prepare(query)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(args...)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affectedRows > 0, nil
+}
+
+func NewTwoFactorRepository(database *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *TwoFactorRepository
+}
+
+func TestTwoFactorRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &TwoFactorRepositoryUnitTestSuite{})
+}
+
+func (suite *TwoFactorRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewTwoFactorRepository(db)
+}
+
+func (suite *TwoFactorRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// When the user never enrolled, a credential with a user id of 0 is returned
+func (suite *TwoFactorRepositoryUnitTestSuite) TestGetTotpCredentialNotEnrolled_ReturnsEmptyCredential() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT user_id, secret, last_used_step, created_at, confirmed_at
+	FROM TotpCredentials
+	WHERE user_id = ?`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	credential, err := suite.repository.GetTotpCredential(12)
+
+	suite.Nil(err)
+	suite.Equal(int64(0), credential.UserId)
+	suite.False(credential.IsConfirmed())
+}
+
+// When a code of the step or a later one was already used, no row is updated
+func (suite *TwoFactorRepositoryUnitTestSuite) TestUseTotpStepAlreadyUsed_ReturnsFalse() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE TotpCredentials SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`).
+		ExpectExec().
+		WithArgs(100, 12, 100).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	used, err := suite.repository.UseTotpStep(12, 100)
+
+	suite.Nil(err)
+	suite.False(used)
+}
+
+func (suite *TwoFactorRepositoryUnitTestSuite) TestReplaceRecoveryCodes_CommitsEveryCode() {
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`DELETE FROM RecoveryCodes WHERE user_id = ?`).
+		WithArgs(12).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	insertStatement := suite.dbMock.ExpectPrepare(`INSERT INTO RecoveryCodes(user_id, code_hash) VALUES (?, ?)`)
+	insertStatement.ExpectExec().WithArgs(12, "first hash").WillReturnResult(sqlmock.NewResult(1, 1))
+	insertStatement.ExpectExec().WithArgs(12, "second hash").WillReturnResult(sqlmock.NewResult(2, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.ReplaceRecoveryCodes(12, []string{"first hash", "second hash"})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When storing a code fails, the previous codes are kept
+func (suite *TwoFactorRepositoryUnitTestSuite) TestReplaceRecoveryCodesFailure_RollsBack() {
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`DELETE FROM RecoveryCodes WHERE user_id = ?`).
+		WithArgs(12).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectPrepare(`INSERT INTO RecoveryCodes(user_id, code_hash) VALUES (?, ?)`).
+		ExpectExec().
+		WillReturnError(errors.New("test"))
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.ReplaceRecoveryCodes(12, []string{"first hash"})
+
+	suite.NotNil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *TwoFactorRepositoryUnitTestSuite) TestUseRecoveryCode_ReturnsTrue() {
+
+	usedAt := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE RecoveryCodes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`).
+		ExpectExec().
+		WithArgs(usedAt, 12, "code hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	used, err := suite.repository.UseRecoveryCode(12, "code hash", usedAt)
+
+	suite.Nil(err)
+	suite.True(used)
+}
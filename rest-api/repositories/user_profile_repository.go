@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"example.com/models"
+)
+
+type UserProfileRepository struct {
+	database *sql.DB
+}
+
+// Returns the default profile when the user never updated theirs
+func (userProfileRepository UserProfileRepository) GetUserProfile(userId int64) (*models.UserProfile, error) {
+	profileSql := `
+	SELECT user_id, display_name, avatar_url, notify_event_updates, notify_announcements, notify_comment_replies, updated_at
+	FROM UserProfiles
+	WHERE user_id = ?`
+
+	statement, err := userProfileRepository.database.Prepare(profileSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	var profile models.UserProfile
+	var updatedAt sql.NullTime
+
+	err = statement.QueryRow(userId).Scan(
+		&profile.UserId,
+		&profile.DisplayName,
+		&profile.AvatarUrl,
+		&profile.NotificationPreferences.EventUpdates,
+		&profile.NotificationPreferences.Announcements,
+		&profile.NotificationPreferences.CommentReplies,
+		&updatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.UserProfile{
+			UserId:                  userId,
+			NotificationPreferences: models.DefaultNotificationPreferences(),
+		}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedAt.Valid {
+		profile.UpdatedAt = &updatedAt.Time
+	}
+
+	return &profile, nil
+}
+
+func (userProfileRepository UserProfileRepository) SaveUserProfile(profile models.UserProfile) error {
+	saveProfileSql := `
+	INSERT INTO UserProfiles(user_id, display_name, avatar_url, notify_event_updates, notify_announcements, notify_comment_replies, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(user_id) DO UPDATE SET
+		display_name = excluded.display_name,
+		avatar_url = excluded.avatar_url,
+		notify_event_updates = excluded.notify_event_updates,
+		notify_announcements = excluded.notify_announcements,
+		notify_comment_replies = excluded.notify_comment_replies,
+		updated_at = excluded.updated_at`
+
+	statement, err := userProfileRepository.database.Prepare(saveProfileSql)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.Exec(
+		profile.UserId,
+		profile.DisplayName,
+		profile.AvatarUrl,
+		profile.NotificationPreferences.EventUpdates,
+		profile.NotificationPreferences.Announcements,
+		profile.NotificationPreferences.CommentReplies,
+		profile.UpdatedAt)
+
+	return err
+}
+
+func NewUserProfileRepository(database *sql.DB) *UserProfileRepository {
+	return &UserProfileRepository{
+		database: database,
+	}
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserProfileRepositoryUnitTestSuite struct {
+	suite.Suite
+	//Database mock "connection", do not use for interacting with the db, use "dbMock"
+	database *sql.DB
+	//Mock of the database that should be used to assert and interact with the database
+	dbMock     sqlmock.Sqlmock
+	repository *UserProfileRepository
+}
+
+func TestUserProfileRepositoryUnitTestSuite(t *testing.T) {
+	suite.Run(t, &UserProfileRepositoryUnitTestSuite{})
+}
+
+func (suite *UserProfileRepositoryUnitTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create database, tests cannot proceed, error: %v\n", err.Error()))
+	}
+
+	suite.database = db
+
+	suite.dbMock = mock
+
+	suite.repository = NewUserProfileRepository(db)
+}
+
+func (suite *UserProfileRepositoryUnitTestSuite) TearDownTest() {
+
+	//manually closing db connection, since using defer will close the connection
+	//prior to starting the test
+	suite.database.Close()
+}
+
+// When the user never saved their profile, the default profile is returned
+func (suite *UserProfileRepositoryUnitTestSuite) TestGetUserProfileMissing_ReturnsTheDefaultProfile() {
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT user_id, display_name, avatar_url, notify_event_updates, notify_announcements, notify_comment_replies, updated_at
+	FROM UserProfiles
+	WHERE user_id = ?`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	profile, err := suite.repository.GetUserProfile(12)
+
+	suite.Nil(err)
+	suite.Equal(&models.UserProfile{
+		UserId:                  12,
+		NotificationPreferences: models.DefaultNotificationPreferences(),
+	}, profile)
+}
+
+func (suite *UserProfileRepositoryUnitTestSuite) TestGetUserProfile_ReturnsTheProfile() {
+
+	updatedAt := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	SELECT user_id, display_name, avatar_url, notify_event_updates, notify_announcements, notify_comment_replies, updated_at
+	FROM UserProfiles
+	WHERE user_id = ?`).
+		ExpectQuery().
+		WithArgs(12).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "display_name", "avatar_url", "notify_event_updates", "notify_announcements", "notify_comment_replies", "updated_at"}).
+			AddRow(12, "Test", "https://test.com/avatar.png", true, false, true, updatedAt))
+
+	profile, err := suite.repository.GetUserProfile(12)
+
+	suite.Nil(err)
+	suite.Equal("Test", profile.DisplayName)
+	suite.False(profile.NotificationPreferences.Announcements)
+	suite.Equal(&updatedAt, profile.UpdatedAt)
+}
+
+func (suite *UserProfileRepositoryUnitTestSuite) TestSaveUserProfile_UpsertsTheProfile() {
+
+	updatedAt := time.Now()
+
+	suite.dbMock.ExpectPrepare(`
+	INSERT INTO UserProfiles(user_id, display_name, avatar_url, notify_event_updates, notify_announcements, notify_comment_replies, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(user_id) DO UPDATE SET
+		display_name = excluded.display_name,
+		avatar_url = excluded.avatar_url,
+		notify_event_updates = excluded.notify_event_updates,
+		notify_announcements = excluded.notify_announcements,
+		notify_comment_replies = excluded.notify_comment_replies,
+		updated_at = excluded.updated_at`).
+		ExpectExec().
+		WithArgs(12, "Test", "", true, false, true, &updatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.SaveUserProfile(models.UserProfile{
+		UserId:      12,
+		DisplayName: "Test",
+		NotificationPreferences: models.NotificationPreferences{
+			EventUpdates:   true,
+			CommentReplies: true,
+		},
+		UpdatedAt: &updatedAt,
+	})
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
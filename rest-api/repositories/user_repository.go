@@ -3,10 +3,31 @@ package repositories
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
+	"example.com/constants"
 	"example.com/models"
 )
 
+const userColumns = `id, email, password, role, disabled_at, verified_at, verification_sent_at`
+
+// Tables holding the credentials, sessions, settings, data exports and memberships of users,
+// cleared whatever the deletion policy
+var userCredentialTables = []string{
+	"RefreshTokens",
+	"PasswordResetTokens",
+	"TotpCredentials",
+	"RecoveryCodes",
+	"MfaChallenges",
+	"UserIdentities",
+	"PersonalAccessTokens",
+	"UserProfiles",
+	"DataExports",
+	"Sessions",
+	"OrganizationMembers",
+}
+
 type UserRepository struct {
 	database *sql.DB
 }
@@ -33,12 +54,13 @@ func (userRepository UserRepository) CreateUser(user *models.User) error {
 	id, _ := result.LastInsertId()
 
 	user.Id = id
+	user.Role = models.USER_ROLE
 
 	return nil
 }
 
 func (userRepository UserRepository) GetUserByEmail(email string) (*models.User, error) {
-	selectUserByEmailSql := `SELECT * FROM Users WHERE email = ?`
+	selectUserByEmailSql := `SELECT ` + userColumns + ` FROM Users WHERE email = ?`
 
 	statement, err := userRepository.database.Prepare(selectUserByEmailSql)
 
@@ -57,22 +79,280 @@ func (userRepository UserRepository) GetUserByEmail(email string) (*models.User,
 	defer rows.Close()
 
 	if !rows.Next() {
-		return nil, errors.New("no user found associated with provided email address")
+		return nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR)
+	}
+
+	user, err := scanUser(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Returns a user with an id of 0 when no user exists for the id
+func (userRepository UserRepository) GetUserById(id int64) (*models.User, error) {
+	selectUserByIdSql := `SELECT ` + userColumns + ` FROM Users WHERE id = ?`
+
+	statement, err := userRepository.database.Prepare(selectUserByIdSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	user, err := scanUser(statement.QueryRow(id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &models.User{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (userRepository UserRepository) GetUsers() ([]models.User, error) {
+	selectUsersSql := `SELECT ` + userColumns + ` FROM Users ORDER BY id`
+
+	statement, err := userRepository.database.Prepare(selectUsersSql)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	rows, err := statement.Query()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := []models.User{}
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (userRepository UserRepository) UpdateUserRole(id int64, role string) error {
+	return userRepository.updateUser(`UPDATE Users SET role = ? WHERE id = ?`, role, id)
+}
+
+func (userRepository UserRepository) UpdateUserPassword(id int64, password string) error {
+	return userRepository.updateUser(`UPDATE Users SET password = ? WHERE id = ?`, password, id)
+}
+
+// Replaces the hash only while it is still the current one, so a password changed meanwhile is not
+// overwritten. Reports no user for the id otherwise
+func (userRepository UserRepository) ReplaceUserPasswordHash(id int64, currentHash, newHash string) error {
+	return userRepository.updateUser(`UPDATE Users SET password = ? WHERE id = ? AND password = ?`, newHash, id, currentHash)
+}
+
+// Disables the user when a date is provided, enables it back otherwise
+func (userRepository UserRepository) SetUserDisabledAt(id int64, disabledAt *time.Time) error {
+	return userRepository.updateUser(`UPDATE Users SET disabled_at = ? WHERE id = ?`, disabledAt, id)
+}
+
+func (userRepository UserRepository) SetUserVerifiedAt(id int64, verifiedAt time.Time) error {
+	return userRepository.updateUser(`UPDATE Users SET verified_at = ? WHERE id = ?`, verifiedAt, id)
+}
+
+// Records that a verification email is being sent, unless one was already sent after
+// throttledBefore. Returns false when throttled, so concurrent requests cannot both send one
+func (userRepository UserRepository) MarkVerificationEmailSent(id int64, sentAt, throttledBefore time.Time) (bool, error) {
+	markSentSql := `
+	UPDATE Users
+	SET verification_sent_at = ?
+	WHERE id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)`
+
+	statement, err := userRepository.database.Prepare(markSentSql)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(sentAt, id, throttledBefore)
+
+	if err != nil {
+		return false, err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affectedRows == 1, nil
+}
+
+// Replaces the email of the user, the new email being verified by the link the user opened
+func (userRepository UserRepository) UpdateUserEmail(id int64, email string, verifiedAt time.Time) error {
+	return userRepository.updateUser(`UPDATE Users SET email = ?, verified_at = ? WHERE id = ?`, email, verifiedAt, id)
+}
+
+// Scrubs the personal data of the user and disables the account. The user row is kept so the
+// events and registrations of the user stay consistent, attributed to an anonymous account
+func (userRepository UserRepository) AnonymizeUser(id int64, anonymizedEmail string, deletedAt time.Time) error {
+	return userRepository.deleteUserAccount(id, func(transaction *sql.Tx) (sql.Result, error) {
+		_, err := transaction.Exec(`DELETE FROM Invitations WHERE accepted_at IS NULL AND email = (SELECT email FROM Users WHERE id = ?)`, id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return transaction.Exec(`
+	UPDATE Users
+	SET email = ?, password = '', role = ?, disabled_at = ?, verified_at = NULL, verification_sent_at = NULL
+	WHERE id = ?`, anonymizedEmail, models.USER_ROLE, deletedAt, id)
+	})
+}
+
+// Deletes the user along with their events and registrations. Comments the user left on other
+// events are deleted the way users delete them, so the replies keep their thread, and lose their
+// author along with the account
+func (userRepository UserRepository) DeleteUser(id int64, deletedAt time.Time) error {
+	return userRepository.deleteUserAccount(id, func(transaction *sql.Tx) (sql.Result, error) {
+		cascadeStatements := []struct {
+			query     string
+			arguments []any
+		}{
+			{`DELETE FROM Registrations WHERE user_id = ? OR event_id IN (SELECT id FROM Events WHERE user_id = ?)`, []any{id, id}},
+			{`DELETE FROM Invitations WHERE user_id = ? OR event_id IN (SELECT id FROM Events WHERE user_id = ?)`, []any{id, id}},
+			{`DELETE FROM Invitations WHERE accepted_at IS NULL AND email = (SELECT email FROM Users WHERE id = ?)`, []any{id}},
+			{`DELETE FROM Comments WHERE event_id IN (SELECT id FROM Events WHERE user_id = ?)`, []any{id}},
+			{`UPDATE Comments SET body = '', pinned = 0, deleted_at = ? WHERE user_id = ? AND deleted_at IS NULL`, []any{deletedAt, id}},
+			{`DELETE FROM Events WHERE user_id = ?`, []any{id}},
+		}
+
+		for _, statement := range cascadeStatements {
+			_, err := transaction.Exec(statement.query, statement.arguments...)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return transaction.Exec(`DELETE FROM Users WHERE id = ?`, id)
+	})
+}
+
+// Clears the credentials and settings of the user, then applies the deletion policy, within a
+// single transaction so a failure leaves the account untouched
+func (userRepository UserRepository) deleteUserAccount(id int64, deleteUser func(transaction *sql.Tx) (sql.Result, error)) error {
+	transaction, err := userRepository.database.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	//does nothing once the transaction is committed
+	defer transaction.Rollback()
+
+	for _, table := range userCredentialTables {
+		_, err = transaction.Exec(fmt.Sprintf(`DELETE FROM %v WHERE user_id = ?`, table), id)
+
+		if err != nil {
+			return err
+		}
 	}
 
+	result, err := deleteUser(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	return transaction.Commit()
+}
+
+func (userRepository UserRepository) updateUser(query string, args ...any) error {
+	statement, err := userRepository.database.Prepare(query)
+
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.Exec(args...)
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	return nil
+}
+
+func scanUser(scanner rowScanner) (models.User, error) {
 	var user models.User
+	var disabledAt, verifiedAt, verificationSentAt sql.NullTime
 
-	err = rows.Scan(
+	err := scanner.Scan(
 		&user.Id,
 		&user.Email,
 		&user.Password,
+		&user.Role,
+		&disabledAt,
+		&verifiedAt,
+		&verificationSentAt,
 	)
 
 	if err != nil {
-		return nil, err
+		return models.User{}, err
 	}
 
-	return &user, nil
+	if disabledAt.Valid {
+		user.DisabledAt = &disabledAt.Time
+	}
+
+	if verifiedAt.Valid {
+		user.VerifiedAt = &verifiedAt.Time
+	}
+
+	if verificationSentAt.Valid {
+		user.VerificationSentAt = &verificationSentAt.Time
+	}
+
+	return user, nil
 }
 
 func NewUserRepository(database *sql.DB) *UserRepository {
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"example.com/constants"
 	"example.com/models"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/suite"
@@ -145,7 +147,7 @@ func (suite *UserRepositoryUnitTestSuite) TestGetUserByEmail_ShouldPrepareTheQue
 
 	expectedEmail := "some email"
 
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Users WHERE email = ?`).
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE email = ?`).
 		ExpectQuery().
 		WithArgs(
 			expectedEmail,
@@ -161,7 +163,7 @@ func (suite *UserRepositoryUnitTestSuite) TestGetUserByEmail_ReturnsTheError() {
 	expectedEmail := "some email"
 	expectedError := errors.New("test")
 
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Users WHERE email = ?`).
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE email = ?`).
 		ExpectQuery().
 		WithArgs(
 			expectedEmail,
@@ -180,19 +182,28 @@ func (suite *UserRepositoryUnitTestSuite) TestGetUserByEmail_ReturnsTheUser() {
 		Id:       123,
 		Email:    "some email",
 		Password: "some password",
+		Role:     models.USER_ROLE,
 	}
 
 	mockResult := sqlmock.NewRows([]string{
 		"id",
 		"email",
 		"password",
+		"role",
+		"disabled_at",
+		"verified_at",
+		"verification_sent_at",
 	}).AddRow(
 		expectedUser.Id,
 		expectedUser.Email,
 		expectedUser.Password,
+		expectedUser.Role,
+		nil,
+		nil,
+		nil,
 	)
 
-	suite.dbMock.ExpectPrepare(`SELECT * FROM Users WHERE email = ?`).
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE email = ?`).
 		ExpectQuery().
 		WithArgs(
 			expectedUser.Email,
@@ -203,3 +214,316 @@ func (suite *UserRepositoryUnitTestSuite) TestGetUserByEmail_ReturnsTheUser() {
 
 	suite.Equal(&expectedUser, user)
 }
+
+// When an error occurs during db interaction, pass it up
+func (suite *UserRepositoryUnitTestSuite) TestGetUserById_ReturnsTheError() {
+
+	expectedError := errors.New("test")
+
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(int64(123)).
+		WillReturnError(expectedError)
+
+	_, err := suite.repository.GetUserById(123)
+
+	suite.NotNil(err)
+	suite.Equal(expectedError, err)
+}
+
+// When no user exists for the id, an empty user is returned
+func (suite *UserRepositoryUnitTestSuite) TestGetUserById_ReturnsEmptyUser() {
+
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(int64(123)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "role", "disabled_at", "verified_at", "verification_sent_at"}))
+
+	user, err := suite.repository.GetUserById(123)
+
+	suite.Nil(err)
+	suite.Equal(&models.User{}, user)
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestGetUserById_ReturnsTheUser() {
+
+	expectedUser := models.User{
+		Id:       123,
+		Email:    "some email",
+		Password: "some password",
+		Role:     models.USER_ROLE,
+	}
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"email",
+		"password",
+		"role",
+		"disabled_at",
+		"verified_at",
+		"verification_sent_at",
+	}).AddRow(
+		expectedUser.Id,
+		expectedUser.Email,
+		expectedUser.Password,
+		expectedUser.Role,
+		nil,
+		nil,
+		nil,
+	)
+
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users WHERE id = ?`).
+		ExpectQuery().
+		WithArgs(expectedUser.Id).
+		WillReturnRows(mockResult)
+
+	user, _ := suite.repository.GetUserById(expectedUser.Id)
+
+	suite.Equal(&expectedUser, user)
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestGetUsers_ReturnsTheUsers() {
+
+	disabledAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockResult := sqlmock.NewRows([]string{
+		"id",
+		"email",
+		"password",
+		"role",
+		"disabled_at",
+		"verified_at",
+		"verification_sent_at",
+	}).
+		AddRow(int64(1), "admin email", "some password", models.ADMIN_ROLE, nil, disabledAt, nil).
+		AddRow(int64(2), "user email", "some password", models.USER_ROLE, disabledAt, nil, nil)
+
+	suite.dbMock.ExpectPrepare(`SELECT id, email, password, role, disabled_at, verified_at, verification_sent_at FROM Users ORDER BY id`).
+		ExpectQuery().
+		WillReturnRows(mockResult)
+
+	users, err := suite.repository.GetUsers()
+
+	suite.Nil(err)
+	suite.Len(users, 2)
+	suite.Equal(models.ADMIN_ROLE, users[0].Role)
+	suite.Nil(users[0].DisabledAt)
+	suite.Equal(&disabledAt, users[0].VerifiedAt)
+	suite.Equal(&disabledAt, users[1].DisabledAt)
+	suite.Nil(users[1].VerifiedAt)
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestUpdateUserRole_UpdatesTheRole() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE Users SET role = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(models.ADMIN_ROLE, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.UpdateUserRole(123, models.ADMIN_ROLE)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When no user exists for the id, return an error
+func (suite *UserRepositoryUnitTestSuite) TestUpdateUserRoleUnknownUser_ReturnsAnError() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE Users SET role = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(models.ADMIN_ROLE, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repository.UpdateUserRole(123, models.ADMIN_ROLE)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_USER_FOR_ID_ERROR, err.Error())
+}
+
+// The hash is only replaced while it is still the current one
+func (suite *UserRepositoryUnitTestSuite) TestReplaceUserPasswordHash_ChecksTheCurrentHash() {
+
+	suite.dbMock.ExpectPrepare(`UPDATE Users SET password = ? WHERE id = ? AND password = ?`).
+		ExpectExec().
+		WithArgs("new hash", int64(123), "current hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.ReplaceUserPasswordHash(123, "current hash", "new hash")
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestSetUserDisabledAt_UpdatesTheUser() {
+
+	disabledAt := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE Users SET disabled_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs(&disabledAt, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.SetUserDisabledAt(123, &disabledAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When a verification email was sent too recently, no row is updated
+func (suite *UserRepositoryUnitTestSuite) TestMarkVerificationEmailSentThrottled_ReturnsFalse() {
+
+	now := time.Now()
+	throttledBefore := now.Add(-time.Minute)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Users
+	SET verification_sent_at = ?
+	WHERE id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)`).
+		ExpectExec().
+		WithArgs(now, int64(123), throttledBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	marked, err := suite.repository.MarkVerificationEmailSent(123, now, throttledBefore)
+
+	suite.Nil(err)
+	suite.False(marked)
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestMarkVerificationEmailSent_ReturnsTrue() {
+
+	now := time.Now()
+	throttledBefore := now.Add(-time.Minute)
+
+	suite.dbMock.ExpectPrepare(`
+	UPDATE Users
+	SET verification_sent_at = ?
+	WHERE id = ? AND (verification_sent_at IS NULL OR verification_sent_at <= ?)`).
+		ExpectExec().
+		WithArgs(now, int64(123), throttledBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	marked, err := suite.repository.MarkVerificationEmailSent(123, now, throttledBefore)
+
+	suite.Nil(err)
+	suite.True(marked)
+}
+
+func (suite *UserRepositoryUnitTestSuite) TestUpdateUserEmail_UpdatesTheUser() {
+
+	verifiedAt := time.Now()
+
+	suite.dbMock.ExpectPrepare(`UPDATE Users SET email = ?, verified_at = ? WHERE id = ?`).
+		ExpectExec().
+		WithArgs("new@test.com", verifiedAt, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repository.UpdateUserEmail(123, "new@test.com", verifiedAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositoryUnitTestSuite) expectCredentialsDeleted(id int64) {
+	for _, table := range userCredentialTables {
+		suite.dbMock.ExpectExec(fmt.Sprintf(`DELETE FROM %v WHERE user_id = ?`, table)).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
+// The credentials are removed and the user row is scrubbed, within one transaction
+func (suite *UserRepositoryUnitTestSuite) TestAnonymizeUser_CommitsTheChanges() {
+
+	deletedAt := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.expectCredentialsDeleted(123)
+	suite.dbMock.ExpectExec(`DELETE FROM Invitations WHERE accepted_at IS NULL AND email = (SELECT email FROM Users WHERE id = ?)`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`
+	UPDATE Users
+	SET email = ?, password = '', role = ?, disabled_at = ?, verified_at = NULL, verification_sent_at = NULL
+	WHERE id = ?`).
+		WithArgs("deleted-123@deleted.invalid", models.USER_ROLE, deletedAt, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.AnonymizeUser(123, "deleted-123@deleted.invalid", deletedAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When no user exists for the id, nothing is committed
+func (suite *UserRepositoryUnitTestSuite) TestAnonymizeUnknownUser_RollsBack() {
+
+	deletedAt := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.expectCredentialsDeleted(123)
+	suite.dbMock.ExpectExec(`DELETE FROM Invitations WHERE accepted_at IS NULL AND email = (SELECT email FROM Users WHERE id = ?)`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(`
+	UPDATE Users
+	SET email = ?, password = '', role = ?, disabled_at = ?, verified_at = NULL, verification_sent_at = NULL
+	WHERE id = ?`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.AnonymizeUser(123, "deleted-123@deleted.invalid", deletedAt)
+
+	suite.EqualError(err, constants.NO_USER_FOR_ID_ERROR)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// The events of the user are deleted along with everything attached to them
+func (suite *UserRepositoryUnitTestSuite) TestDeleteUser_CommitsTheChanges() {
+
+	deletedAt := time.Now()
+
+	suite.dbMock.ExpectBegin()
+	suite.expectCredentialsDeleted(123)
+	suite.dbMock.ExpectExec(`DELETE FROM Registrations WHERE user_id = ? OR event_id IN (SELECT id FROM Events WHERE user_id = ?)`).
+		WithArgs(int64(123), int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.dbMock.ExpectExec(`DELETE FROM Invitations WHERE user_id = ? OR event_id IN (SELECT id FROM Events WHERE user_id = ?)`).
+		WithArgs(int64(123), int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(`DELETE FROM Invitations WHERE accepted_at IS NULL AND email = (SELECT email FROM Users WHERE id = ?)`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(`DELETE FROM Comments WHERE event_id IN (SELECT id FROM Events WHERE user_id = ?)`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	suite.dbMock.ExpectExec(`UPDATE Comments SET body = '', pinned = 0, deleted_at = ? WHERE user_id = ? AND deleted_at IS NULL`).
+		WithArgs(deletedAt, int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`DELETE FROM Events WHERE user_id = ?`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`DELETE FROM Users WHERE id = ?`).
+		WithArgs(int64(123)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+
+	err := suite.repository.DeleteUser(123, deletedAt)
+
+	suite.Nil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
+
+// When a statement fails, the account is left untouched
+func (suite *UserRepositoryUnitTestSuite) TestDeleteUserFailure_RollsBack() {
+
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`DELETE FROM RefreshTokens WHERE user_id = ?`).
+		WillReturnError(errors.New("test"))
+	suite.dbMock.ExpectRollback()
+
+	err := suite.repository.DeleteUser(123, time.Now())
+
+	suite.NotNil(err)
+	suite.Nil(suite.dbMock.ExpectationsWereMet())
+}
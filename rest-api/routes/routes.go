@@ -2,17 +2,31 @@ package routes
 
 import (
 	interfaces "example.com/interfaces/controllers"
+	middlewareInterfaces "example.com/interfaces/middlewares"
+	"example.com/lib"
 	"example.com/middlewares"
+	"example.com/models"
 	"github.com/gin-gonic/gin"
 )
 
+// Same as gin's default engine, with a logger that keeps credentials out of the logged urls
 func NewHttpServer() *gin.Engine {
-	return gin.Default()
+	server := gin.New()
+
+	server.Use(middlewares.RequestLogger(gin.DefaultWriter), gin.Recovery())
+
+	return server
 }
 
-func RegisterEventRoutes(server *gin.Engine, eventsController interfaces.IEventsController) {
+func RegisterEventRoutes(
+	server *gin.Engine,
+	eventsController interfaces.IEventsController,
+	authenticator middlewareInterfaces.IAuthenticator,
+	verifiedEmailGuard middlewareInterfaces.IVerifiedEmailGuard) {
 	unauthenticatedEventEndpoints := server.Group("/events")
 	{
+		//anonymous requests are allowed, authenticated users also see the private events they can access
+		unauthenticatedEventEndpoints.Use(middlewares.AcceptScope(models.EVENTS_READ_SCOPE), authenticator.OptionalAuthenticate)
 		unauthenticatedEventEndpoints.GET("", eventsController.GetEvents)
 
 		unauthenticatedEventEndpoints.GET(":id", eventsController.GetEventById)
@@ -20,23 +34,241 @@ func RegisterEventRoutes(server *gin.Engine, eventsController interfaces.IEvents
 
 	authtenticatedEventEndpoints := server.Group("/events")
 	{
-		authtenticatedEventEndpoints.Use(middlewares.Authenticate)
-		authtenticatedEventEndpoints.POST("", eventsController.AddEvent)
+		authtenticatedEventEndpoints.Use(middlewares.AcceptScope(models.EVENTS_WRITE_SCOPE), authenticator.Authenticate)
+		authtenticatedEventEndpoints.POST("", verifiedEmailGuard.RequireVerifiedEmail, eventsController.AddEvent)
 		authtenticatedEventEndpoints.PUT(":id", eventsController.UpdateEvent)
 		authtenticatedEventEndpoints.DELETE(":id", eventsController.DeleteEvent)
 	}
 }
 
-func RegisterUserRoutes(server *gin.Engine, userController interfaces.IUsersController) {
+func RegisterEventStreamRoutes(server *gin.Engine, eventStreamController interfaces.IEventStreamController, authenticator middlewareInterfaces.IAuthenticator) {
+	server.GET("/events/stream", eventStreamController.StreamEvents)
+	//organizers and invited users can stream private events without an invitation token
+	server.GET("/events/:id/stream",
+		middlewares.AcceptScope(models.EVENTS_READ_SCOPE),
+		authenticator.OptionalAuthenticate,
+		eventStreamController.StreamEvent)
+}
+
+func RegisterEventChannelRoutes(server *gin.Engine, eventChannelController interfaces.IEventChannelController, authenticator middlewareInterfaces.IAuthenticator) {
+	//authenticates on its own, since browsers cannot send the authorization header when opening a websocket
+	server.GET("/events/:id/channel", eventChannelController.Connect)
+
+	announcementRoutes := server.Group("/events/:id")
+	{
+		announcementRoutes.Use(authenticator.Authenticate)
+		announcementRoutes.POST("/announcements", eventChannelController.Announce)
+	}
+}
+
+func RegisterInvitationRoutes(server *gin.Engine, invitationsController interfaces.IInvitationsController, authenticator middlewareInterfaces.IAuthenticator) {
+	invitationRoutes := server.Group("/events/:id/invitations")
+	{
+		invitationRoutes.Use(authenticator.Authenticate)
+		invitationRoutes.POST("", invitationsController.CreateInvitation)
+		invitationRoutes.GET("", invitationsController.GetInvitations)
+		invitationRoutes.DELETE(":invitationId", invitationsController.RevokeInvitation)
+		invitationRoutes.POST("accept", invitationsController.AcceptInvitation)
+	}
+}
+
+func RegisterCommentRoutes(server *gin.Engine, commentsController interfaces.ICommentsController, authenticator middlewareInterfaces.IAuthenticator) {
+	server.GET("/events/:id/comments", authenticator.OptionalAuthenticate, commentsController.GetComments)
+
+	commentRoutes := server.Group("/events/:id/comments")
+	{
+		commentRoutes.Use(authenticator.Authenticate)
+		commentRoutes.POST("", commentsController.CreateComment)
+		commentRoutes.PUT(":commentId", commentsController.UpdateComment)
+		commentRoutes.DELETE(":commentId", commentsController.DeleteComment)
+		commentRoutes.POST(":commentId/pin", commentsController.PinComment)
+		commentRoutes.DELETE(":commentId/pin", commentsController.UnpinComment)
+	}
+}
+
+func RegisterUserRoutes(server *gin.Engine, userController interfaces.IUsersController, authenticator middlewareInterfaces.IAuthenticator) {
 	server.POST("/signup", userController.CreateUser)
 	server.POST("/login", userController.Login)
+	server.POST("/login/mfa", userController.CompleteMfaLogin)
+	server.POST("/token/refresh", userController.RefreshToken)
+
+	logoutRoutes := server.Group("/logout")
+	{
+		logoutRoutes.Use(authenticator.Authenticate)
+		logoutRoutes.POST("", userController.Logout)
+		logoutRoutes.POST("/all", userController.LogoutEverywhere)
+	}
+
+	accountRoutes := server.Group("/users/me")
+	{
+		accountRoutes.Use(authenticator.Authenticate)
+		accountRoutes.GET("", userController.GetProfile)
+		accountRoutes.PATCH("", userController.UpdateProfile)
+		accountRoutes.POST("/password", userController.ChangePassword)
+		accountRoutes.POST("/email", userController.ChangeEmail)
+		accountRoutes.DELETE("", userController.DeleteAccount)
+	}
+}
+
+func RegisterEmailVerificationRoutes(server *gin.Engine, emailVerificationController interfaces.IEmailVerificationController, authenticator middlewareInterfaces.IAuthenticator) {
+	server.GET("/email/verify", emailVerificationController.VerifyEmail)
+	server.POST("/email/verification/resend", authenticator.Authenticate, emailVerificationController.ResendVerificationEmail)
+}
+
+func RegisterTwoFactorRoutes(server *gin.Engine, twoFactorController interfaces.ITwoFactorController, authenticator middlewareInterfaces.IAuthenticator) {
+	twoFactorRoutes := server.Group("/users/me/2fa")
+	{
+		twoFactorRoutes.Use(authenticator.Authenticate)
+		twoFactorRoutes.POST("/enroll", twoFactorController.StartEnrollment)
+		twoFactorRoutes.POST("/confirm", twoFactorController.ConfirmEnrollment)
+		twoFactorRoutes.POST("/disable", twoFactorController.DisableTwoFactor)
+	}
+}
+
+// Tokens can only be managed with the access tokens issued at login, so a leaked personal access
+// token cannot be used to create more of them
+func RegisterPersonalAccessTokenRoutes(server *gin.Engine, personalAccessTokensController interfaces.IPersonalAccessTokensController, authenticator middlewareInterfaces.IAuthenticator) {
+	tokenRoutes := server.Group("/users/me/tokens")
+	{
+		tokenRoutes.Use(authenticator.Authenticate)
+		tokenRoutes.GET("", personalAccessTokensController.GetPersonalAccessTokens)
+		tokenRoutes.POST("", personalAccessTokensController.CreatePersonalAccessToken)
+		tokenRoutes.DELETE(":tokenId", personalAccessTokensController.RevokePersonalAccessToken)
+	}
+}
+
+func RegisterSessionRoutes(server *gin.Engine, sessionsController interfaces.ISessionsController, authenticator middlewareInterfaces.IAuthenticator) {
+	sessionRoutes := server.Group("/users/me/sessions")
+	{
+		sessionRoutes.Use(authenticator.Authenticate)
+		sessionRoutes.GET("", sessionsController.GetSessions)
+		sessionRoutes.DELETE(":sessionId", sessionsController.RevokeSession)
+	}
+}
+
+func RegisterOrganizationRoutes(server *gin.Engine, organizationsController interfaces.IOrganizationsController, authenticator middlewareInterfaces.IAuthenticator) {
+	organizationRoutes := server.Group("/organizations")
+	{
+		organizationRoutes.Use(authenticator.Authenticate)
+		organizationRoutes.GET("", organizationsController.GetOrganizations)
+		organizationRoutes.POST("", organizationsController.CreateOrganization)
+		organizationRoutes.GET(":organizationId/members", organizationsController.GetMembers)
+		organizationRoutes.PUT(":organizationId/members/:userId", organizationsController.UpdateMemberRole)
+		organizationRoutes.DELETE(":organizationId/members/:userId", organizationsController.RemoveMember)
+		organizationRoutes.POST(":organizationId/invitations", organizationsController.InviteMember)
+		organizationRoutes.POST(":organizationId/invitations/accept", organizationsController.AcceptInvitation)
+	}
+
+	server.PUT("/users/me/organization", authenticator.Authenticate, organizationsController.SwitchOrganization)
+}
+
+func RegisterDataExportRoutes(server *gin.Engine, dataExportsController interfaces.IDataExportsController, authenticator middlewareInterfaces.IAuthenticator) {
+	server.GET("/users/me/export", authenticator.Authenticate, dataExportsController.ExportData)
+
+	exportRoutes := server.Group("/users/me/exports")
+	{
+		exportRoutes.Use(authenticator.Authenticate)
+		exportRoutes.GET(":exportId", dataExportsController.GetDataExport)
+		exportRoutes.GET(":exportId/download", dataExportsController.DownloadDataExport)
+	}
+}
+
+func RegisterJsonWebKeyRoutes(server *gin.Engine, jsonWebKeysController interfaces.IJsonWebKeysController) {
+	server.GET("/.well-known/jwks.json", jsonWebKeysController.GetJsonWebKeySet)
+}
+
+func RegisterOidcRoutes(server *gin.Engine, oidcController interfaces.IOidcController) {
+	oidcRoutes := server.Group("/auth/oidc/:provider")
+	{
+		oidcRoutes.GET("/login", oidcController.StartLogin)
+		oidcRoutes.GET("/callback", oidcController.Callback)
+	}
 }
 
-func RegisterRegistrationRoutes(server *gin.Engine, registrationsController interfaces.IRegistrationsController) {
+// Serves the in-process provider when it is enabled
+func RegisterMockOidcProviderRoutes(server *gin.Engine, mockOidcProvider *lib.MockOidcProvider) {
+	if mockOidcProvider == nil {
+		return
+	}
+
+	server.Any(lib.MOCK_OIDC_PATH+"/*path", gin.WrapH(mockOidcProvider))
+}
+
+func RegisterPasswordResetRoutes(server *gin.Engine, passwordResetController interfaces.IPasswordResetController) {
+	server.POST("/password/forgot", passwordResetController.ForgotPassword)
+	server.POST("/password/reset", passwordResetController.ResetPassword)
+}
+
+func RegisterRegistrationRoutes(server *gin.Engine, registrationsController interfaces.IRegistrationsController, authenticator middlewareInterfaces.IAuthenticator) {
 	registationRoutes := server.Group("/events/:id")
 	{
-		registationRoutes.Use(middlewares.Authenticate)
+		registationRoutes.Use(middlewares.AcceptScope(models.REGISTRATIONS_WRITE_SCOPE), authenticator.Authenticate)
 		registationRoutes.POST("/register", registrationsController.RegisterForEvent)
 		registationRoutes.DELETE("/unregister", registrationsController.CancelEventRegistration)
 	}
+
+	currentUserRoutes := server.Group("/users/me")
+	{
+		currentUserRoutes.Use(authenticator.Authenticate)
+		currentUserRoutes.GET("/conflicts", registrationsController.GetScheduleConflicts)
+	}
+}
+
+// Every admin request is recorded in the audit log, including the ones refused for lack of permission
+func RegisterAdminRoutes(
+	server *gin.Engine,
+	adminController interfaces.IAdminController,
+	eventsController interfaces.IEventsController,
+	authenticator middlewareInterfaces.IAuthenticator,
+	auditTrail middlewareInterfaces.IAuditTrail) {
+	adminRoutes := server.Group("/admin")
+	{
+		adminRoutes.Use(authenticator.Authenticate)
+		adminRoutes.GET("/users",
+			auditTrail.RecordPrivilegedAction(models.USERS_READ_AUDIT_ACTION),
+			middlewares.RequirePermission(models.READ_USERS_PERMISSION),
+			adminController.GetUsers)
+		adminRoutes.POST("/users/:id/disable",
+			auditTrail.RecordPrivilegedAction(models.USER_DISABLE_AUDIT_ACTION),
+			middlewares.RequirePermission(models.MANAGE_USERS_PERMISSION),
+			adminController.DisableUser)
+		adminRoutes.POST("/users/:id/enable",
+			auditTrail.RecordPrivilegedAction(models.USER_ENABLE_AUDIT_ACTION),
+			middlewares.RequirePermission(models.MANAGE_USERS_PERMISSION),
+			adminController.EnableUser)
+		adminRoutes.POST("/users/:id/unlock",
+			auditTrail.RecordPrivilegedAction(models.USER_UNLOCK_AUDIT_ACTION),
+			middlewares.RequirePermission(models.MANAGE_USERS_PERMISSION),
+			adminController.UnlockUser)
+
+		//the event handlers let users granted the permission manage events organized by others
+		adminRoutes.PUT("/events/:id",
+			auditTrail.RecordPrivilegedAction(models.EVENT_UPDATE_AUDIT_ACTION),
+			middlewares.RequirePermission(models.MANAGE_ANY_EVENT_PERMISSION),
+			eventsController.UpdateEvent)
+		adminRoutes.DELETE("/events/:id",
+			auditTrail.RecordPrivilegedAction(models.EVENT_DELETE_AUDIT_ACTION),
+			middlewares.RequirePermission(models.MANAGE_ANY_EVENT_PERMISSION),
+			eventsController.DeleteEvent)
+	}
+}
+
+// Reading the log is recorded in it as well
+func RegisterAuditLogRoutes(
+	server *gin.Engine,
+	auditLogController interfaces.IAuditLogController,
+	authenticator middlewareInterfaces.IAuthenticator,
+	auditTrail middlewareInterfaces.IAuditTrail) {
+	auditLogRoutes := server.Group("/admin/audit-log")
+	{
+		auditLogRoutes.Use(authenticator.Authenticate)
+		auditLogRoutes.GET("",
+			auditTrail.RecordPrivilegedAction(models.AUDIT_LOG_READ_AUDIT_ACTION),
+			middlewares.RequirePermission(models.READ_AUDIT_LOG_PERMISSION),
+			auditLogController.GetAuditLog)
+		auditLogRoutes.GET("/export",
+			auditTrail.RecordPrivilegedAction(models.AUDIT_LOG_EXPORT_AUDIT_ACTION),
+			middlewares.RequirePermission(models.READ_AUDIT_LOG_PERMISSION),
+			auditLogController.ExportAuditLog)
+	}
 }
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+)
+
+// Number of entries read at once while exporting, so large logs are never loaded in memory
+const auditLogExportBatchSize = 500
+
+type AuditLogService struct {
+	auditLogRepository repositoryInterfaces.IAuditLogRepository
+}
+
+// Appends the entry to the log. Callers are not expected to fail the request when the entry cannot
+// be stored, so it is logged instead of being lost
+func (auditLogService AuditLogService) Record(entry models.AuditLogEntry) error {
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = time.Now().UTC()
+	}
+
+	err := auditLogService.auditLogRepository.CreateEntry(entry)
+
+	if err != nil {
+		serializedEntry, _ := json.Marshal(entry)
+
+		log.Printf("Unable to store audit log entry %s: %v", serializedEntry, err)
+	}
+
+	return err
+}
+
+func (auditLogService AuditLogService) GetEntries(filter models.AuditLogFilter) ([]models.AuditLogEntry, error) {
+	return auditLogService.auditLogRepository.GetEntries(filter)
+}
+
+// Writes every entry matching the filter as newline delimited JSON, its limit is ignored
+func (auditLogService AuditLogService) ExportEntries(filter models.AuditLogFilter, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+
+	filter.Limit = auditLogExportBatchSize
+
+	for {
+		entries, err := auditLogService.auditLogRepository.GetEntries(filter)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			err = encoder.Encode(entry)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < auditLogExportBatchSize {
+			return nil
+		}
+
+		filter.AfterId = entries[len(entries)-1].Id
+	}
+}
+
+func NewAuditLogService(auditLogRepository repositoryInterfaces.IAuditLogRepository) *AuditLogService {
+	return &AuditLogService{
+		auditLogRepository: auditLogRepository,
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogServiceUnitTestSuite struct {
+	suite.Suite
+	auditLogRepositoryMock mocks.IAuditLogRepository
+	service                *AuditLogService
+}
+
+func TestAuditLogServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &AuditLogServiceUnitTestSuite{})
+}
+
+func (suite *AuditLogServiceUnitTestSuite) SetupTest() {
+	suite.auditLogRepositoryMock = mocks.IAuditLogRepository{}
+
+	suite.service = NewAuditLogService(&suite.auditLogRepositoryMock)
+}
+
+func (suite *AuditLogServiceUnitTestSuite) TestRecord_StampsTheEntry() {
+
+	suite.auditLogRepositoryMock.On("CreateEntry", mock.Anything).Return(nil)
+
+	err := suite.service.Record(models.AuditLogEntry{Action: models.LOGIN_AUDIT_ACTION})
+
+	suite.Nil(err)
+
+	entry := suite.auditLogRepositoryMock.Calls[0].Arguments.Get(0).(models.AuditLogEntry)
+
+	suite.Equal(models.LOGIN_AUDIT_ACTION, entry.Action)
+	suite.WithinDuration(time.Now(), entry.OccurredAt, time.Minute)
+}
+
+// When an error occurs during db access, return the error
+func (suite *AuditLogServiceUnitTestSuite) TestRecord_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.auditLogRepositoryMock.On("CreateEntry", mock.Anything).Return(expectedError)
+
+	err := suite.service.Record(models.AuditLogEntry{Action: models.LOGIN_AUDIT_ACTION})
+
+	suite.Equal(expectedError, err)
+}
+
+// Each entry is written on its own line, the log is read in batches until a partial one
+func (suite *AuditLogServiceUnitTestSuite) TestExportEntries_WritesNewlineDelimitedJson() {
+
+	firstBatch := make([]models.AuditLogEntry, auditLogExportBatchSize)
+
+	for i := range firstBatch {
+		firstBatch[i] = models.AuditLogEntry{Id: int64(i + 1), Action: models.LOGIN_AUDIT_ACTION}
+	}
+
+	suite.auditLogRepositoryMock.On("GetEntries", mock.MatchedBy(func(filter models.AuditLogFilter) bool {
+		return filter.AfterId == 0
+	})).Return(firstBatch, nil)
+	suite.auditLogRepositoryMock.On("GetEntries", mock.MatchedBy(func(filter models.AuditLogFilter) bool {
+		return filter.AfterId == auditLogExportBatchSize
+	})).Return([]models.AuditLogEntry{{Id: auditLogExportBatchSize + 1, Action: models.LOGOUT_AUDIT_ACTION}}, nil)
+
+	var output bytes.Buffer
+
+	err := suite.service.ExportEntries(models.AuditLogFilter{Outcome: models.FAILURE_AUDIT_OUTCOME, Limit: 10}, &output)
+
+	suite.Nil(err)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+
+	suite.Len(lines, auditLogExportBatchSize+1)
+	suite.Contains(lines[0], `"id":1,`)
+	suite.Contains(lines[auditLogExportBatchSize], `"action":"logout"`)
+	suite.auditLogRepositoryMock.AssertNumberOfCalls(suite.T(), "GetEntries", 2)
+	suite.auditLogRepositoryMock.AssertCalled(suite.T(), "GetEntries", models.AuditLogFilter{
+		Outcome: models.FAILURE_AUDIT_OUTCOME,
+		AfterId: auditLogExportBatchSize,
+		Limit:   auditLogExportBatchSize,
+	})
+}
+
+func (suite *AuditLogServiceUnitTestSuite) TestExportEntries_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.auditLogRepositoryMock.On("GetEntries", mock.Anything).Return(nil, expectedError)
+
+	var output bytes.Buffer
+
+	err := suite.service.ExportEntries(models.AuditLogFilter{}, &output)
+
+	suite.Equal(expectedError, err)
+	suite.Empty(output.String())
+}
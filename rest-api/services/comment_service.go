@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+)
+
+type CommentService struct {
+	commentRepository repositoryInterfaces.ICommentRepository
+	eventRepository   repositoryInterfaces.IEventRepository
+	markdownSanitizer libInterfaces.IMarkdownSanitizer
+}
+
+// Returns a page of threads, each top level comment carrying its replies nested below it
+func (commentService CommentService) GetComments(eventId int64, page, pageSize int) (*models.CommentPage, error) {
+	total, err := commentService.commentRepository.CountRootComments(eventId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := commentService.commentRepository.GetRootComments(eventId, pageSize, (page-1)*pageSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rootIds := make([]int64, len(roots))
+
+	for i, root := range roots {
+		rootIds[i] = root.Id
+	}
+
+	replies, err := commentService.commentRepository.GetReplies(rootIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]*models.Comment, 0, len(roots))
+	commentsById := make(map[int64]*models.Comment, len(roots)+len(replies))
+
+	for i := range roots {
+		root := presentComment(&roots[i])
+		commentsById[root.Id] = root
+		threads = append(threads, root)
+	}
+
+	//replies are ordered oldest first, so a parent is always added before its replies
+	for i := range replies {
+		reply := presentComment(&replies[i])
+		commentsById[reply.Id] = reply
+
+		parent, exists := commentsById[*reply.ParentId]
+
+		if !exists {
+			continue
+		}
+
+		parent.Replies = append(parent.Replies, reply)
+	}
+
+	return &models.CommentPage{
+		Comments: threads,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+func (commentService CommentService) CreateComment(eventId, userId int64, request models.CommentRequest) (*models.Comment, error) {
+	body := commentService.markdownSanitizer.Sanitize(request.Body)
+
+	if body == "" {
+		return nil, errors.New(constants.EMPTY_COMMENT_ERROR)
+	}
+
+	comment := models.Comment{
+		EventId:   eventId,
+		UserId:    userId,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if request.ParentId != nil {
+		parent, err := commentService.commentRepository.GetCommentById(*request.ParentId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.Id == 0 || parent.EventId != eventId || parent.Deleted {
+			return nil, errors.New(constants.INVALID_PARENT_COMMENT_ERROR)
+		}
+
+		rootId := parent.Id
+
+		if parent.RootId != nil {
+			rootId = *parent.RootId
+		}
+
+		comment.ParentId = &parent.Id
+		comment.RootId = &rootId
+	}
+
+	err := commentService.commentRepository.CreateComment(&comment)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return presentComment(&comment), nil
+}
+
+func (commentService CommentService) UpdateComment(
+	eventId, commentId, userId int64,
+	request models.UpdateCommentRequest) (*models.Comment, error) {
+	comment, err := commentService.getComment(eventId, commentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.Deleted {
+		return nil, errors.New(constants.NO_COMMENT_FOR_ID_ERROR)
+	}
+
+	if comment.UserId != userId {
+		return nil, errors.New(constants.NOT_COMMENT_AUTHOR_ERROR)
+	}
+
+	body := commentService.markdownSanitizer.Sanitize(request.Body)
+
+	if body == "" {
+		return nil, errors.New(constants.EMPTY_COMMENT_ERROR)
+	}
+
+	updatedAt := time.Now().UTC()
+
+	err = commentService.commentRepository.UpdateCommentBody(commentId, body, updatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Body = body
+	comment.UpdatedAt = &updatedAt
+
+	return presentComment(comment), nil
+}
+
+// Comments can be deleted by their author, or moderated by the organizer of the event
+func (commentService CommentService) DeleteComment(eventId, commentId, userId, organizationId int64) error {
+	comment, err := commentService.getComment(eventId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.Deleted {
+		return nil
+	}
+
+	if comment.UserId != userId {
+		err = commentService.ensureOrganizer(eventId, userId, organizationId)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return commentService.commentRepository.SoftDeleteComment(commentId, time.Now().UTC())
+}
+
+func (commentService CommentService) PinComment(eventId, commentId, userId, organizationId int64, pinned bool) error {
+	err := commentService.ensureOrganizer(eventId, userId, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	comment, err := commentService.getComment(eventId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.Deleted {
+		return errors.New(constants.NO_COMMENT_FOR_ID_ERROR)
+	}
+
+	if comment.ParentId != nil {
+		return errors.New(constants.PIN_REPLY_ERROR)
+	}
+
+	return commentService.commentRepository.SetCommentPinned(commentId, pinned)
+}
+
+func (commentService CommentService) getComment(eventId, commentId int64) (*models.Comment, error) {
+	comment, err := commentService.commentRepository.GetCommentById(commentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.Id == 0 || comment.EventId != eventId {
+		return nil, errors.New(constants.NO_COMMENT_FOR_ID_ERROR)
+	}
+
+	return comment, nil
+}
+
+func (commentService CommentService) ensureOrganizer(eventId, userId, organizationId int64) error {
+	event, err := commentService.eventRepository.GetEventById(eventId, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	if event.Id == 0 {
+		return errors.New(constants.NO_EVENT_FOR_ID_ERROR)
+	}
+
+	if event.UserId != userId {
+		return errors.New(constants.NOT_EVENT_ORGANIZER_ERROR)
+	}
+
+	return nil
+}
+
+// Deleted comments stay in the thread as placeholders, without their author or body
+func presentComment(comment *models.Comment) *models.Comment {
+	if comment.Deleted {
+		comment.UserId = 0
+		comment.Body = ""
+		comment.UpdatedAt = nil
+	}
+
+	if comment.Replies == nil {
+		comment.Replies = make([]*models.Comment, 0)
+	}
+
+	return comment
+}
+
+func NewCommentService(
+	commentRepository repositoryInterfaces.ICommentRepository,
+	eventRepository repositoryInterfaces.IEventRepository,
+	markdownSanitizer libInterfaces.IMarkdownSanitizer) *CommentService {
+	return &CommentService{
+		commentRepository: commentRepository,
+		eventRepository:   eventRepository,
+		markdownSanitizer: markdownSanitizer,
+	}
+}
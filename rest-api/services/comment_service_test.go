@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentServiceUnitTestSuite struct {
+	suite.Suite
+	commentRepositoryMock mocks.ICommentRepository
+	eventRepositoryMock   mocks.IEventRepository
+	markdownSanitizerMock mocks.IMarkdownSanitizer
+	service               *CommentService
+}
+
+func TestCommentServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &CommentServiceUnitTestSuite{})
+}
+
+func (suite *CommentServiceUnitTestSuite) SetupTest() {
+	suite.commentRepositoryMock = mocks.ICommentRepository{}
+	suite.eventRepositoryMock = mocks.IEventRepository{}
+	suite.markdownSanitizerMock = mocks.IMarkdownSanitizer{}
+
+	suite.service = NewCommentService(
+		&suite.commentRepositoryMock,
+		&suite.eventRepositoryMock,
+		&suite.markdownSanitizerMock)
+}
+
+func int64Pointer(value int64) *int64 {
+	return &value
+}
+
+func (suite *CommentServiceUnitTestSuite) TestGetComments_NestsRepliesUnderTheirParents() {
+
+	deletedAt := time.Now()
+
+	suite.commentRepositoryMock.On("CountRootComments", mock.Anything).Return(int64(1), nil)
+	suite.commentRepositoryMock.On("GetRootComments", mock.Anything, mock.Anything, mock.Anything).Return([]models.Comment{
+		{Id: 1, EventId: 1, UserId: 3, Body: "question"},
+	}, nil)
+	suite.commentRepositoryMock.On("GetReplies", mock.Anything).Return([]models.Comment{
+		{Id: 2, EventId: 1, UserId: 12, ParentId: int64Pointer(1), RootId: int64Pointer(1), Deleted: true, DeletedAt: &deletedAt},
+		{Id: 3, EventId: 1, UserId: 3, ParentId: int64Pointer(2), RootId: int64Pointer(1), Body: "answer"},
+	}, nil)
+
+	page, err := suite.service.GetComments(1, 3, 20)
+
+	suite.Nil(err)
+	suite.commentRepositoryMock.AssertCalled(suite.T(), "GetRootComments", int64(1), 20, 40)
+	suite.commentRepositoryMock.AssertCalled(suite.T(), "GetReplies", []int64{1})
+	suite.Equal(int64(1), page.Total)
+	suite.Len(page.Comments, 1)
+
+	deletedReply := page.Comments[0].Replies[0]
+
+	suite.True(deletedReply.Deleted)
+	suite.Equal(int64(0), deletedReply.UserId)
+	suite.Equal("answer", deletedReply.Replies[0].Body)
+}
+
+// When fetching the comments returns an error, pass that error up
+func (suite *CommentServiceUnitTestSuite) TestGetComments_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.commentRepositoryMock.On("CountRootComments", mock.Anything).Return(int64(0), expectedError)
+
+	_, err := suite.service.GetComments(1, 1, 20)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *CommentServiceUnitTestSuite) TestCreateComment_StoresTheSanitizedBody() {
+
+	suite.markdownSanitizerMock.On("Sanitize", mock.Anything).Return("clean body")
+	suite.commentRepositoryMock.On("CreateComment", mock.Anything).Return(nil)
+
+	comment, err := suite.service.CreateComment(1, 12, models.CommentRequest{Body: "dirty body"})
+
+	suite.Nil(err)
+	suite.markdownSanitizerMock.AssertCalled(suite.T(), "Sanitize", "dirty body")
+	suite.Equal("clean body", comment.Body)
+	suite.Nil(comment.RootId)
+}
+
+// When nothing is left once sanitized, the comment is refused
+func (suite *CommentServiceUnitTestSuite) TestCreateEmptyComment_ReturnsAnError() {
+
+	suite.markdownSanitizerMock.On("Sanitize", mock.Anything).Return("")
+
+	_, err := suite.service.CreateComment(1, 12, models.CommentRequest{Body: "<b></b>"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.EMPTY_COMMENT_ERROR, err.Error())
+	suite.commentRepositoryMock.AssertNotCalled(suite.T(), "CreateComment", mock.Anything)
+}
+
+// Replies to replies belong to the thread of the top level comment
+func (suite *CommentServiceUnitTestSuite) TestCreateReply_KeepsTheThreadRoot() {
+
+	suite.markdownSanitizerMock.On("Sanitize", mock.Anything).Return("reply")
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{
+		Id:       2,
+		EventId:  1,
+		ParentId: int64Pointer(1),
+		RootId:   int64Pointer(1),
+	}, nil)
+	suite.commentRepositoryMock.On("CreateComment", mock.Anything).Return(nil)
+
+	comment, err := suite.service.CreateComment(1, 12, models.CommentRequest{Body: "reply", ParentId: int64Pointer(2)})
+
+	suite.Nil(err)
+	suite.Equal(int64(2), *comment.ParentId)
+	suite.Equal(int64(1), *comment.RootId)
+}
+
+// When the parent belongs to another event, the reply is refused
+func (suite *CommentServiceUnitTestSuite) TestCreateReplyToAnotherEvent_ReturnsAnError() {
+
+	suite.markdownSanitizerMock.On("Sanitize", mock.Anything).Return("reply")
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 5}, nil)
+
+	_, err := suite.service.CreateComment(1, 12, models.CommentRequest{Body: "reply", ParentId: int64Pointer(2)})
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_PARENT_COMMENT_ERROR, err.Error())
+}
+
+// When the user is not the author, the comment cannot be edited, not even by the organizer
+func (suite *CommentServiceUnitTestSuite) TestUpdateCommentNotTheAuthor_ReturnsAnError() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1, UserId: 12}, nil)
+
+	_, err := suite.service.UpdateComment(1, 2, 3, models.UpdateCommentRequest{Body: "edited"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_COMMENT_AUTHOR_ERROR, err.Error())
+}
+
+func (suite *CommentServiceUnitTestSuite) TestUpdateComment_UpdatesTheBody() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1, UserId: 12}, nil)
+	suite.markdownSanitizerMock.On("Sanitize", mock.Anything).Return("edited")
+	suite.commentRepositoryMock.On("UpdateCommentBody", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	comment, err := suite.service.UpdateComment(1, 2, 12, models.UpdateCommentRequest{Body: "edited"})
+
+	suite.Nil(err)
+	suite.commentRepositoryMock.AssertCalled(suite.T(), "UpdateCommentBody", int64(2), "edited", mock.Anything)
+	suite.NotNil(comment.UpdatedAt)
+}
+
+func (suite *CommentServiceUnitTestSuite) TestDeleteComment_AuthorCanDelete() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1, UserId: 12}, nil)
+	suite.commentRepositoryMock.On("SoftDeleteComment", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.DeleteComment(1, 2, 12, 0)
+
+	suite.Nil(err)
+	suite.commentRepositoryMock.AssertCalled(suite.T(), "SoftDeleteComment", int64(2), mock.Anything)
+	suite.eventRepositoryMock.AssertNotCalled(suite.T(), "GetEventById", mock.Anything, mock.Anything)
+}
+
+func (suite *CommentServiceUnitTestSuite) TestDeleteComment_OrganizerCanModerate() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1, UserId: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.commentRepositoryMock.On("SoftDeleteComment", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.DeleteComment(1, 2, 3, 0)
+
+	suite.Nil(err)
+}
+
+// When the user is neither the author nor the organizer, return an error
+func (suite *CommentServiceUnitTestSuite) TestDeleteCommentByAnotherUser_ReturnsAnError() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1, UserId: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	err := suite.service.DeleteComment(1, 2, 7, 0)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_EVENT_ORGANIZER_ERROR, err.Error())
+	suite.commentRepositoryMock.AssertNotCalled(suite.T(), "SoftDeleteComment", mock.Anything, mock.Anything)
+}
+
+// When the comment belongs to another event, it is reported as missing
+func (suite *CommentServiceUnitTestSuite) TestDeleteCommentOfAnotherEvent_ReturnsAnError() {
+
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 5, UserId: 12}, nil)
+
+	err := suite.service.DeleteComment(1, 2, 12, 0)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_COMMENT_FOR_ID_ERROR, err.Error())
+}
+
+// Only top level comments can be pinned
+func (suite *CommentServiceUnitTestSuite) TestPinReply_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{
+		Id:       2,
+		EventId:  1,
+		ParentId: int64Pointer(1),
+	}, nil)
+
+	err := suite.service.PinComment(1, 2, 3, 0, true)
+
+	suite.NotNil(err)
+	suite.Equal(constants.PIN_REPLY_ERROR, err.Error())
+}
+
+func (suite *CommentServiceUnitTestSuite) TestPinCommentNotTheOrganizer_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	err := suite.service.PinComment(1, 2, 12, 0, true)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_EVENT_ORGANIZER_ERROR, err.Error())
+}
+
+func (suite *CommentServiceUnitTestSuite) TestPinComment_PinsTheComment() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.commentRepositoryMock.On("GetCommentById", mock.Anything).Return(&models.Comment{Id: 2, EventId: 1}, nil)
+	suite.commentRepositoryMock.On("SetCommentPinned", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.PinComment(1, 2, 3, 0, true)
+
+	suite.Nil(err)
+	suite.commentRepositoryMock.AssertCalled(suite.T(), "SetCommentPinned", int64(2), true)
+}
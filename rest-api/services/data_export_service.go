@@ -0,0 +1,417 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+// Accounts with more events, registrations and comments than this get their export generated in
+// the background rather than during the request
+const dataExportSynchronousRecordLimit = 1000
+
+const dataExportLifetime = time.Hour * 24 * 7
+
+// Pending exports older than this were interrupted, by a restart for instance, and are not waited for
+const dataExportGenerationTimeout = time.Hour
+
+// Audit log entries of the user are read in pages of this size
+const dataExportAuditLogPageSize = 500
+
+type DataExportService struct {
+	userRepository                repositoryInterfaces.IUserRepository
+	userProfileRepository         repositoryInterfaces.IUserProfileRepository
+	twoFactorRepository           repositoryInterfaces.ITwoFactorRepository
+	eventRepository               repositoryInterfaces.IEventRepository
+	commentRepository             repositoryInterfaces.ICommentRepository
+	refreshTokenRepository        repositoryInterfaces.IRefreshTokenRepository
+	oidcRepository                repositoryInterfaces.IOidcRepository
+	personalAccessTokenRepository repositoryInterfaces.IPersonalAccessTokenRepository
+	dataExportRepository          repositoryInterfaces.IDataExportRepository
+	organizationRepository        repositoryInterfaces.IOrganizationRepository
+	auditLogRepository            repositoryInterfaces.IAuditLogRepository
+	runInBackground               func(task func())
+}
+
+// Returns the archive right away for small accounts. Larger ones get an export generated in the
+// background, reusing the one already being generated if any
+func (dataExportService DataExportService) RequestDataExport(userId int64) (*models.DataExportResult, error) {
+	now := time.Now().UTC()
+
+	err := dataExportService.dataExportRepository.DeleteExpiredDataExports(now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recordCount, err := dataExportService.dataExportRepository.CountUserRecords(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if recordCount <= dataExportSynchronousRecordLimit {
+		archive, err := dataExportService.createArchive(userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.DataExportResult{Archive: archive}, nil
+	}
+
+	pendingExport, err := dataExportService.dataExportRepository.GetPendingDataExport(userId, now.Add(-dataExportGenerationTimeout))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingExport.Id != 0 {
+		return &models.DataExportResult{Export: pendingExport}, nil
+	}
+
+	export := &models.DataExport{
+		UserId:    userId,
+		Status:    models.DATA_EXPORT_PENDING,
+		CreatedAt: now,
+		ExpiresAt: now.Add(dataExportLifetime),
+	}
+
+	err = dataExportService.dataExportRepository.CreateDataExport(export)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dataExportService.runInBackground(func() {
+		dataExportService.generateDataExport(*export)
+	})
+
+	return &models.DataExportResult{Export: export}, nil
+}
+
+func (dataExportService DataExportService) GetDataExport(id, userId int64) (*models.DataExport, error) {
+	export, err := dataExportService.dataExportRepository.GetDataExport(id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Id == 0 || !time.Now().UTC().Before(export.ExpiresAt) {
+		return nil, errors.New(constants.NO_DATA_EXPORT_FOR_ID_ERROR)
+	}
+
+	return export, nil
+}
+
+func (dataExportService DataExportService) GetDataExportArchive(id, userId int64) ([]byte, error) {
+	export, err := dataExportService.GetDataExport(id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !export.IsDownloadable(time.Now().UTC()) {
+		return nil, errors.New(constants.DATA_EXPORT_NOT_READY_ERROR)
+	}
+
+	return dataExportService.dataExportRepository.GetDataExportArchive(export.Id)
+}
+
+// Nobody waits for the result, so failures are recorded on the export for the user to retry
+func (dataExportService DataExportService) generateDataExport(export models.DataExport) {
+	archive, err := dataExportService.createArchive(export.UserId)
+
+	if err != nil {
+		dataExportService.dataExportRepository.FailDataExport(export.Id, time.Now().UTC())
+		return
+	}
+
+	err = dataExportService.dataExportRepository.CompleteDataExport(export.Id, archive, time.Now().UTC())
+
+	if err != nil {
+		dataExportService.dataExportRepository.FailDataExport(export.Id, time.Now().UTC())
+	}
+}
+
+func (dataExportService DataExportService) createArchive(userId int64) ([]byte, error) {
+	profile, err := dataExportService.exportProfile(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	organizedEvents, registeredEvents, err := dataExportService.exportEvents(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := dataExportService.exportComments(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditRecords, err := dataExportService.exportAuditRecords(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lib.CreateJsonZipArchive(map[string]any{
+		"profile.json":       profile,
+		"events.json":        organizedEvents,
+		"registrations.json": registeredEvents,
+		"comments.json":      comments,
+		"audit.json":         auditRecords,
+	})
+}
+
+func (dataExportService DataExportService) exportProfile(userId int64) (*models.ExportedProfile, error) {
+	user, err := dataExportService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	profile, err := dataExportService.userProfileRepository.GetUserProfile(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := dataExportService.twoFactorRepository.GetTotpCredential(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ExportedProfile{
+		Id:                      user.Id,
+		Email:                   user.Email,
+		Role:                    user.Role,
+		VerifiedAt:              user.VerifiedAt,
+		DisabledAt:              user.DisabledAt,
+		TwoFactorEnabled:        credential.IsConfirmed(),
+		DisplayName:             profile.DisplayName,
+		AvatarUrl:               profile.AvatarUrl,
+		NotificationPreferences: profile.NotificationPreferences,
+		UpdatedAt:               profile.UpdatedAt,
+	}, nil
+}
+
+// Collects the events the user organized and registered for in the personal space and in each
+// organization the user is a member of, events of organizations the user left belong to them
+func (dataExportService DataExportService) exportEvents(userId int64) ([]models.ExportedEvent, []models.ExportedEvent, error) {
+	memberships, err := dataExportService.organizationRepository.GetMembershipsByUserId(userId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organizationIds := []int64{0}
+
+	for _, membership := range memberships {
+		organizationIds = append(organizationIds, membership.OrganizationId)
+	}
+
+	organizedEvents := make([]models.ExportedEvent, 0)
+	registeredEvents := make([]models.ExportedEvent, 0)
+
+	for _, organizationId := range organizationIds {
+		organized, err := dataExportService.eventRepository.GetEventsByOrganizer(userId, organizationId)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		registered, err := dataExportService.eventRepository.GetEventsByRegistrant(userId, organizationId)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		organizedEvents = append(organizedEvents, exportEvents(organized)...)
+		registeredEvents = append(registeredEvents, exportEvents(registered)...)
+	}
+
+	return organizedEvents, registeredEvents, nil
+}
+
+func (dataExportService DataExportService) exportComments(userId int64) ([]models.ExportedComment, error) {
+	comments, err := dataExportService.commentRepository.GetCommentsByUserId(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exportedComments := make([]models.ExportedComment, 0, len(comments))
+
+	for _, comment := range comments {
+		exportedComments = append(exportedComments, models.ExportedComment{
+			Id:        comment.Id,
+			EventId:   comment.EventId,
+			ParentId:  comment.ParentId,
+			Body:      comment.Body,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+			DeletedAt: comment.DeletedAt,
+		})
+	}
+
+	return exportedComments, nil
+}
+
+func (dataExportService DataExportService) exportAuditRecords(userId int64) (*models.ExportedAuditRecords, error) {
+	refreshTokens, err := dataExportService.refreshTokenRepository.GetRefreshTokensByUserId(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessTokens, err := dataExportService.personalAccessTokenRepository.GetPersonalAccessTokensByUserId(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := dataExportService.oidcRepository.GetUserIdentitiesByUserId(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dataExports, err := dataExportService.dataExportRepository.GetDataExportsByUserId(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogEntries, err := dataExportService.exportAuditLogEntries(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	linkedIdentities := make([]models.ExportedIdentity, 0, len(identities))
+
+	for _, identity := range identities {
+		linkedIdentities = append(linkedIdentities, models.ExportedIdentity{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	return &models.ExportedAuditRecords{
+		Sessions:             exportSessions(refreshTokens),
+		PersonalAccessTokens: personalAccessTokens,
+		LinkedIdentities:     linkedIdentities,
+		DataExports:          dataExports,
+		AuditLog:             auditLogEntries,
+	}, nil
+}
+
+// Collects the entries of the audit log the user is the actor of, oldest first
+func (dataExportService DataExportService) exportAuditLogEntries(userId int64) ([]models.AuditLogEntry, error) {
+	entries := make([]models.AuditLogEntry, 0)
+	filter := models.AuditLogFilter{ActorId: userId, Limit: dataExportAuditLogPageSize}
+
+	for {
+		page, err := dataExportService.auditLogRepository.GetEntries(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, page...)
+
+		if len(page) < dataExportAuditLogPageSize {
+			return entries, nil
+		}
+
+		filter.AfterId = page[len(page)-1].Id
+	}
+}
+
+func exportEvents(events []models.Event) []models.ExportedEvent {
+	exportedEvents := make([]models.ExportedEvent, 0, len(events))
+
+	for _, event := range events {
+		exportedEvents = append(exportedEvents, models.ExportedEvent{Id: event.Id, Event: event})
+	}
+
+	return exportedEvents
+}
+
+// Groups the refresh tokens by family, each family being a login session. Tokens are expected
+// oldest first
+func exportSessions(refreshTokens []models.RefreshToken) []models.ExportedSession {
+	sessions := make([]models.ExportedSession, 0)
+	sessionIndexes := map[string]int{}
+
+	for _, refreshToken := range refreshTokens {
+		index, found := sessionIndexes[refreshToken.FamilyId]
+
+		if !found {
+			sessionIndexes[refreshToken.FamilyId] = len(sessions)
+			sessions = append(sessions, models.ExportedSession{
+				Session:         refreshToken.FamilyId,
+				CreatedAt:       refreshToken.CreatedAt,
+				LastRefreshedAt: refreshToken.CreatedAt,
+				ExpiresAt:       refreshToken.ExpiresAt,
+				RevokedAt:       refreshToken.RevokedAt,
+			})
+			continue
+		}
+
+		session := &sessions[index]
+		session.LastRefreshedAt = refreshToken.CreatedAt
+		session.ExpiresAt = refreshToken.ExpiresAt
+
+		if refreshToken.RevokedAt != nil {
+			session.RevokedAt = refreshToken.RevokedAt
+		}
+	}
+
+	return sessions
+}
+
+func NewDataExportService(
+	userRepository repositoryInterfaces.IUserRepository,
+	userProfileRepository repositoryInterfaces.IUserProfileRepository,
+	twoFactorRepository repositoryInterfaces.ITwoFactorRepository,
+	eventRepository repositoryInterfaces.IEventRepository,
+	commentRepository repositoryInterfaces.ICommentRepository,
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository,
+	oidcRepository repositoryInterfaces.IOidcRepository,
+	personalAccessTokenRepository repositoryInterfaces.IPersonalAccessTokenRepository,
+	dataExportRepository repositoryInterfaces.IDataExportRepository,
+	organizationRepository repositoryInterfaces.IOrganizationRepository,
+	auditLogRepository repositoryInterfaces.IAuditLogRepository) *DataExportService {
+	return &DataExportService{
+		userRepository:                userRepository,
+		userProfileRepository:         userProfileRepository,
+		twoFactorRepository:           twoFactorRepository,
+		eventRepository:               eventRepository,
+		commentRepository:             commentRepository,
+		refreshTokenRepository:        refreshTokenRepository,
+		oidcRepository:                oidcRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		dataExportRepository:          dataExportRepository,
+		organizationRepository:        organizationRepository,
+		auditLogRepository:            auditLogRepository,
+		runInBackground: func(task func()) {
+			go task()
+		},
+	}
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataExportServiceUnitTestSuite struct {
+	suite.Suite
+	userRepositoryMock                mocks.IUserRepository
+	userProfileRepositoryMock         mocks.IUserProfileRepository
+	twoFactorRepositoryMock           mocks.ITwoFactorRepository
+	eventRepositoryMock               mocks.IEventRepository
+	commentRepositoryMock             mocks.ICommentRepository
+	refreshTokenRepositoryMock        mocks.IRefreshTokenRepository
+	oidcRepositoryMock                mocks.IOidcRepository
+	personalAccessTokenRepositoryMock mocks.IPersonalAccessTokenRepository
+	dataExportRepositoryMock          mocks.IDataExportRepository
+	organizationRepositoryMock        mocks.IOrganizationRepository
+	auditLogRepositoryMock            mocks.IAuditLogRepository
+	service                           *DataExportService
+}
+
+func TestDataExportServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &DataExportServiceUnitTestSuite{})
+}
+
+func (suite *DataExportServiceUnitTestSuite) SetupTest() {
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.userProfileRepositoryMock = mocks.IUserProfileRepository{}
+	suite.twoFactorRepositoryMock = mocks.ITwoFactorRepository{}
+	suite.eventRepositoryMock = mocks.IEventRepository{}
+	suite.commentRepositoryMock = mocks.ICommentRepository{}
+	suite.refreshTokenRepositoryMock = mocks.IRefreshTokenRepository{}
+	suite.oidcRepositoryMock = mocks.IOidcRepository{}
+	suite.personalAccessTokenRepositoryMock = mocks.IPersonalAccessTokenRepository{}
+	suite.dataExportRepositoryMock = mocks.IDataExportRepository{}
+	suite.organizationRepositoryMock = mocks.IOrganizationRepository{}
+	suite.auditLogRepositoryMock = mocks.IAuditLogRepository{}
+
+	suite.service = NewDataExportService(
+		&suite.userRepositoryMock,
+		&suite.userProfileRepositoryMock,
+		&suite.twoFactorRepositoryMock,
+		&suite.eventRepositoryMock,
+		&suite.commentRepositoryMock,
+		&suite.refreshTokenRepositoryMock,
+		&suite.oidcRepositoryMock,
+		&suite.personalAccessTokenRepositoryMock,
+		&suite.dataExportRepositoryMock,
+		&suite.organizationRepositoryMock,
+		&suite.auditLogRepositoryMock)
+
+	//exports are generated before returning, so tests can assert on the result
+	suite.service.runInBackground = func(task func()) {
+		task()
+	}
+}
+
+func (suite *DataExportServiceUnitTestSuite) expectUserData() {
+	now := time.Now()
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{
+		Id:         12,
+		Email:      "test@test.com",
+		Password:   "hash",
+		Role:       models.USER_ROLE,
+		VerifiedAt: &now,
+	}, nil)
+	suite.userProfileRepositoryMock.On("GetUserProfile", int64(12)).Return(&models.UserProfile{
+		UserId:                  12,
+		DisplayName:             "Test",
+		NotificationPreferences: models.DefaultNotificationPreferences(),
+	}, nil)
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", int64(12)).Return(&models.TotpCredential{
+		UserId:      12,
+		Secret:      "secret",
+		ConfirmedAt: &now,
+	}, nil)
+	suite.organizationRepositoryMock.On("GetMembershipsByUserId", int64(12)).Return([]models.OrganizationMembership{{OrganizationId: 4}}, nil)
+	suite.eventRepositoryMock.On("GetEventsByOrganizer", int64(12), int64(0)).Return([]models.Event{{Id: 1, Name: "Organized", UserId: 12}}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrant", int64(12), int64(0)).Return([]models.Event{{Id: 2, Name: "Registered", UserId: 5}}, nil)
+	suite.eventRepositoryMock.On("GetEventsByOrganizer", int64(12), int64(4)).Return([]models.Event{{Id: 5, Name: "Organized in organization", UserId: 12, OrganizationId: 4}}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrant", int64(12), int64(4)).Return([]models.Event{}, nil)
+	suite.commentRepositoryMock.On("GetCommentsByUserId", int64(12)).Return([]models.Comment{{Id: 3, EventId: 2, UserId: 12, Body: "Hello"}}, nil)
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokensByUserId", int64(12)).Return([]models.RefreshToken{{Id: 4, UserId: 12, FamilyId: "family", TokenHash: "hash"}}, nil)
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokensByUserId", int64(12)).Return([]models.PersonalAccessToken{}, nil)
+	suite.oidcRepositoryMock.On("GetUserIdentitiesByUserId", int64(12)).Return([]models.UserIdentity{}, nil)
+	suite.dataExportRepositoryMock.On("GetDataExportsByUserId", int64(12)).Return([]models.DataExport{}, nil)
+	suite.auditLogRepositoryMock.On("GetEntries", mock.Anything).Return([]models.AuditLogEntry{{
+		Id:      6,
+		Action:  models.LOGIN_AUDIT_ACTION,
+		Outcome: models.SUCCESS_AUDIT_OUTCOME,
+		ActorId: 12,
+	}}, nil)
+}
+
+func readArchive(archive []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+
+	if err != nil {
+		panic(err)
+	}
+
+	files := map[string]string{}
+
+	for _, file := range reader.File {
+		content, _ := file.Open()
+		data, _ := io.ReadAll(content)
+		files[file.Name] = string(data)
+	}
+
+	return files
+}
+
+// Small accounts get their archive right away, without storing an export
+func (suite *DataExportServiceUnitTestSuite) TestRequestDataExportSmallAccount_ReturnsTheArchive() {
+
+	suite.dataExportRepositoryMock.On("DeleteExpiredDataExports", mock.Anything).Return(nil)
+	suite.dataExportRepositoryMock.On("CountUserRecords", int64(12)).Return(int64(3), nil)
+	suite.expectUserData()
+
+	result, err := suite.service.RequestDataExport(12)
+
+	suite.Nil(err)
+	suite.Nil(result.Export)
+	suite.dataExportRepositoryMock.AssertNotCalled(suite.T(), "CreateDataExport", mock.Anything)
+
+	files := readArchive(result.Archive)
+
+	suite.Len(files, 5)
+
+	var profile models.ExportedProfile
+
+	suite.Nil(json.Unmarshal([]byte(files["profile.json"]), &profile))
+	suite.Equal("test@test.com", profile.Email)
+	suite.Equal("Test", profile.DisplayName)
+	suite.True(profile.TwoFactorEnabled)
+
+	//credentials never end up in the archive
+	for _, content := range files {
+		suite.NotContains(content, "hash")
+		suite.NotContains(content, "secret")
+	}
+
+	suite.Contains(files["events.json"], `"name": "Organized"`)
+	suite.Contains(files["registrations.json"], `"name": "Registered"`)
+	//events of the organizations of the user are exported along with the personal ones
+	suite.Contains(files["events.json"], `"name": "Organized in organization"`)
+	suite.Contains(files["comments.json"], `"body": "Hello"`)
+	suite.Contains(files["audit.json"], `"session": "family"`)
+	suite.Contains(files["audit.json"], `"action": "login"`)
+	suite.auditLogRepositoryMock.AssertCalled(suite.T(), "GetEntries", models.AuditLogFilter{ActorId: 12, Limit: dataExportAuditLogPageSize})
+}
+
+// Audit log entries are read page after page until a page is not full
+func (suite *DataExportServiceUnitTestSuite) TestExportAuditLogEntries_ReadsEveryPage() {
+
+	fullPage := make([]models.AuditLogEntry, dataExportAuditLogPageSize)
+
+	for index := range fullPage {
+		fullPage[index] = models.AuditLogEntry{Id: int64(index + 1), ActorId: 12}
+	}
+
+	suite.auditLogRepositoryMock.On("GetEntries", models.AuditLogFilter{ActorId: 12, Limit: dataExportAuditLogPageSize}).Return(fullPage, nil)
+	suite.auditLogRepositoryMock.On("GetEntries", models.AuditLogFilter{ActorId: 12, AfterId: dataExportAuditLogPageSize, Limit: dataExportAuditLogPageSize}).
+		Return([]models.AuditLogEntry{{Id: dataExportAuditLogPageSize + 1, ActorId: 12}}, nil)
+
+	entries, err := suite.service.exportAuditLogEntries(12)
+
+	suite.Nil(err)
+	suite.Len(entries, dataExportAuditLogPageSize+1)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestRequestDataExportLargeAccount_GeneratesTheExportInTheBackground() {
+
+	suite.dataExportRepositoryMock.On("DeleteExpiredDataExports", mock.Anything).Return(nil)
+	suite.dataExportRepositoryMock.On("CountUserRecords", int64(12)).Return(int64(dataExportSynchronousRecordLimit+1), nil)
+	suite.dataExportRepositoryMock.On("GetPendingDataExport", int64(12), mock.Anything).Return(&models.DataExport{}, nil)
+	suite.dataExportRepositoryMock.On("CreateDataExport", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.DataExport).Id = 7
+	}).Return(nil)
+	suite.dataExportRepositoryMock.On("CompleteDataExport", int64(7), mock.Anything, mock.Anything).Return(nil)
+	suite.expectUserData()
+
+	result, err := suite.service.RequestDataExport(12)
+
+	suite.Nil(err)
+	suite.Nil(result.Archive)
+	suite.Equal(int64(7), result.Export.Id)
+	suite.Equal(models.DATA_EXPORT_PENDING, result.Export.Status)
+	suite.WithinDuration(time.Now().Add(dataExportLifetime), result.Export.ExpiresAt, time.Minute)
+	suite.dataExportRepositoryMock.AssertCalled(suite.T(), "CompleteDataExport", int64(7), mock.Anything, mock.Anything)
+}
+
+// Requesting an export again while one is being generated returns the same export
+func (suite *DataExportServiceUnitTestSuite) TestRequestDataExportWhilePending_ReturnsThePendingExport() {
+
+	pendingExport := &models.DataExport{Id: 7, UserId: 12, Status: models.DATA_EXPORT_PENDING}
+
+	suite.dataExportRepositoryMock.On("DeleteExpiredDataExports", mock.Anything).Return(nil)
+	suite.dataExportRepositoryMock.On("CountUserRecords", int64(12)).Return(int64(dataExportSynchronousRecordLimit+1), nil)
+	suite.dataExportRepositoryMock.On("GetPendingDataExport", int64(12), mock.Anything).Return(pendingExport, nil)
+
+	result, err := suite.service.RequestDataExport(12)
+
+	suite.Nil(err)
+	suite.Equal(pendingExport, result.Export)
+	suite.dataExportRepositoryMock.AssertNotCalled(suite.T(), "CreateDataExport", mock.Anything)
+}
+
+// When generating the archive fails in the background, the export is marked as failed
+func (suite *DataExportServiceUnitTestSuite) TestRequestDataExportGenerationFails_MarksTheExportAsFailed() {
+
+	suite.dataExportRepositoryMock.On("DeleteExpiredDataExports", mock.Anything).Return(nil)
+	suite.dataExportRepositoryMock.On("CountUserRecords", int64(12)).Return(int64(dataExportSynchronousRecordLimit+1), nil)
+	suite.dataExportRepositoryMock.On("GetPendingDataExport", int64(12), mock.Anything).Return(&models.DataExport{}, nil)
+	suite.dataExportRepositoryMock.On("CreateDataExport", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.DataExport).Id = 7
+	}).Return(nil)
+	suite.dataExportRepositoryMock.On("FailDataExport", int64(7), mock.Anything).Return(nil)
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(nil, errors.New("test"))
+
+	result, err := suite.service.RequestDataExport(12)
+
+	suite.Nil(err)
+	suite.Equal(int64(7), result.Export.Id)
+	suite.dataExportRepositoryMock.AssertCalled(suite.T(), "FailDataExport", int64(7), mock.Anything)
+	suite.dataExportRepositoryMock.AssertNotCalled(suite.T(), "CompleteDataExport", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestRequestDataExport_ReturnsError() {
+
+	expectedError := errors.New("test")
+
+	suite.dataExportRepositoryMock.On("DeleteExpiredDataExports", mock.Anything).Return(nil)
+	suite.dataExportRepositoryMock.On("CountUserRecords", int64(12)).Return(int64(0), expectedError)
+
+	_, err := suite.service.RequestDataExport(12)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestGetExpiredDataExport_ReturnsError() {
+
+	suite.dataExportRepositoryMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{
+		Id:        7,
+		UserId:    12,
+		Status:    models.DATA_EXPORT_READY,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := suite.service.GetDataExport(7, 12)
+
+	suite.EqualError(err, constants.NO_DATA_EXPORT_FOR_ID_ERROR)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestGetMissingDataExport_ReturnsError() {
+
+	suite.dataExportRepositoryMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{}, nil)
+
+	_, err := suite.service.GetDataExport(7, 12)
+
+	suite.EqualError(err, constants.NO_DATA_EXPORT_FOR_ID_ERROR)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestGetPendingDataExportArchive_ReturnsError() {
+
+	suite.dataExportRepositoryMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{
+		Id:        7,
+		UserId:    12,
+		Status:    models.DATA_EXPORT_PENDING,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	_, err := suite.service.GetDataExportArchive(7, 12)
+
+	suite.EqualError(err, constants.DATA_EXPORT_NOT_READY_ERROR)
+	suite.dataExportRepositoryMock.AssertNotCalled(suite.T(), "GetDataExportArchive", mock.Anything)
+}
+
+func (suite *DataExportServiceUnitTestSuite) TestGetDataExportArchive_ReturnsTheArchive() {
+
+	suite.dataExportRepositoryMock.On("GetDataExport", int64(7), int64(12)).Return(&models.DataExport{
+		Id:        7,
+		UserId:    12,
+		Status:    models.DATA_EXPORT_READY,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.dataExportRepositoryMock.On("GetDataExportArchive", int64(7)).Return([]byte("archive"), nil)
+
+	archive, err := suite.service.GetDataExportArchive(7, 12)
+
+	suite.Nil(err)
+	suite.Equal([]byte("archive"), archive)
+}
+
+// Refresh tokens of the same family make up a single session
+func (suite *DataExportServiceUnitTestSuite) TestExportSessions_GroupsTheTokensByFamily() {
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	refreshed := start.Add(time.Hour)
+
+	sessions := exportSessions([]models.RefreshToken{
+		{FamilyId: "first", CreatedAt: start, ExpiresAt: start.Add(24 * time.Hour)},
+		{FamilyId: "second", CreatedAt: start, ExpiresAt: start.Add(24 * time.Hour), RevokedAt: &start},
+		{FamilyId: "first", CreatedAt: refreshed, ExpiresAt: refreshed.Add(24 * time.Hour)},
+	})
+
+	suite.Equal([]models.ExportedSession{
+		{Session: "first", CreatedAt: start, LastRefreshedAt: refreshed, ExpiresAt: refreshed.Add(24 * time.Hour)},
+		{Session: "second", CreatedAt: start, LastRefreshedAt: start, ExpiresAt: start.Add(24 * time.Hour), RevokedAt: &start},
+	}, sessions)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+)
+
+const (
+	verificationTokenLifetime = time.Hour * 24
+	// Minimum delay between two verification emails sent to the same user
+	verificationEmailInterval = time.Minute
+)
+
+type EmailVerificationService struct {
+	userRepository          repositoryInterfaces.IUserRepository
+	verificationTokenSigner libInterfaces.IVerificationTokenSigner
+	mailSender              libInterfaces.IMailSender
+}
+
+// Emails a verification link to the user, unless one was sent less than a minute ago
+func (emailVerificationService EmailVerificationService) SendVerificationEmail(user models.User) error {
+	return emailVerificationService.sendVerificationLink(
+		user,
+		models.EmailVerificationClaims{UserId: user.Id, Email: user.Email},
+		"Verify your email address",
+		"Open the following link to verify your email address")
+}
+
+// Emails a link confirming the new email to that address, the email of the user only changes
+// once the link is opened. Shares the throttling of the verification emails
+func (emailVerificationService EmailVerificationService) SendEmailChangeVerification(user models.User, newEmail string) error {
+	return emailVerificationService.sendVerificationLink(
+		user,
+		models.EmailVerificationClaims{UserId: user.Id, Email: newEmail, PreviousEmail: user.Email},
+		"Confirm your new email address",
+		"Open the following link to use this email address for your account")
+}
+
+// Sends another verification email to the user, verified users do not need one
+func (emailVerificationService EmailVerificationService) ResendVerificationEmail(userId int64) (bool, error) {
+	user, err := emailVerificationService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	if user.Id == 0 {
+		return false, errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	if user.IsVerified() {
+		return false, nil
+	}
+
+	return true, emailVerificationService.SendVerificationEmail(*user)
+}
+
+// Marks the email of the user as verified. Verifying an already verified email succeeds, so
+// opening the link twice is harmless
+func (emailVerificationService EmailVerificationService) VerifyEmail(token string) error {
+	claims, err := emailVerificationService.verificationTokenSigner.Verify(token)
+
+	if err != nil {
+		return errors.New(constants.INVALID_VERIFICATION_TOKEN_ERROR)
+	}
+
+	user, err := emailVerificationService.userRepository.GetUserById(claims.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	if user.Id == 0 {
+		return errors.New(constants.INVALID_VERIFICATION_TOKEN_ERROR)
+	}
+
+	if claims.PreviousEmail != "" && user.Email != claims.Email {
+		return emailVerificationService.changeEmail(*user, *claims)
+	}
+
+	if user.Email != claims.Email {
+		return errors.New(constants.INVALID_VERIFICATION_TOKEN_ERROR)
+	}
+
+	if user.IsVerified() {
+		return nil
+	}
+
+	return emailVerificationService.userRepository.SetUserVerifiedAt(user.Id, time.Now().UTC())
+}
+
+func (emailVerificationService EmailVerificationService) IsEmailVerified(userId int64) (bool, error) {
+	user, err := emailVerificationService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return user.IsVerified(), nil
+}
+
+func (emailVerificationService EmailVerificationService) sendVerificationLink(user models.User, claims models.EmailVerificationClaims, subject, instructions string) error {
+	now := time.Now().UTC()
+
+	marked, err := emailVerificationService.userRepository.MarkVerificationEmailSent(user.Id, now, now.Add(-verificationEmailInterval))
+
+	if err != nil {
+		return err
+	}
+
+	if !marked {
+		return errors.New(constants.VERIFICATION_EMAIL_THROTTLED_ERROR)
+	}
+
+	claims.ExpiresAt = now.Add(verificationTokenLifetime)
+
+	token, err := emailVerificationService.verificationTokenSigner.Sign(claims)
+
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%v/email/verify?token=%v", config.AppConfiguration().PublicUrl(), url.QueryEscape(token))
+
+	return emailVerificationService.mailSender.Send(models.MailMessage{
+		To:      claims.Email,
+		Subject: subject,
+		Body: fmt.Sprintf(
+			"%v, it expires in %v:\n\n%v",
+			instructions,
+			verificationTokenLifetime,
+			link),
+	})
+}
+
+// Switches the user to the email of the link, as long as the user did not change their email
+// since the link was sent. The previous address is told about the change
+func (emailVerificationService EmailVerificationService) changeEmail(user models.User, claims models.EmailVerificationClaims) error {
+	if user.Email != claims.PreviousEmail {
+		return errors.New(constants.INVALID_VERIFICATION_TOKEN_ERROR)
+	}
+
+	existingUser, err := emailVerificationService.userRepository.GetUserByEmail(claims.Email)
+
+	if err != nil && err.Error() != constants.NO_USER_FOR_EMAIL_ERROR {
+		return err
+	}
+
+	if err == nil && existingUser.Id != 0 {
+		return errors.New(constants.EMAIL_ALREADY_IN_USE_ERROR)
+	}
+
+	err = emailVerificationService.userRepository.UpdateUserEmail(user.Id, claims.Email, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	//the email is changed at this point, failing to notify the previous address does not undo it
+	emailVerificationService.mailSender.Send(models.MailMessage{
+		To:      claims.PreviousEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf(
+			"The email address of your account was changed to %v. If you did not request this change, reset your password and contact us.",
+			claims.Email),
+	})
+
+	return nil
+}
+
+func NewEmailVerificationService(
+	userRepository repositoryInterfaces.IUserRepository,
+	verificationTokenSigner libInterfaces.IVerificationTokenSigner,
+	mailSender libInterfaces.IMailSender) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepository:          userRepository,
+		verificationTokenSigner: verificationTokenSigner,
+		mailSender:              mailSender,
+	}
+}
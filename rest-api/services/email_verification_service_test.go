@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailVerificationServiceUnitTestSuite struct {
+	suite.Suite
+	userRepositoryMock          mocks.IUserRepository
+	verificationTokenSignerMock mocks.IVerificationTokenSigner
+	mailSenderMock              mocks.IMailSender
+	service                     *EmailVerificationService
+}
+
+func TestEmailVerificationServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &EmailVerificationServiceUnitTestSuite{})
+}
+
+func (suite *EmailVerificationServiceUnitTestSuite) SetupTest() {
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.verificationTokenSignerMock = mocks.IVerificationTokenSigner{}
+	suite.mailSenderMock = mocks.IMailSender{}
+
+	suite.service = NewEmailVerificationService(
+		&suite.userRepositoryMock,
+		&suite.verificationTokenSignerMock,
+		&suite.mailSenderMock)
+}
+
+func (suite *EmailVerificationServiceUnitTestSuite) TestSendVerificationEmail_EmailsTheLink() {
+
+	suite.userRepositoryMock.On("MarkVerificationEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	suite.verificationTokenSignerMock.On("Sign", mock.Anything).Return("signed-token", nil)
+	suite.mailSenderMock.On("Send", mock.Anything).Return(nil)
+
+	err := suite.service.SendVerificationEmail(models.User{Id: 12, Email: "test@test.com"})
+
+	suite.Nil(err)
+
+	claims := suite.verificationTokenSignerMock.Calls[0].Arguments.Get(0).(models.EmailVerificationClaims)
+	message := suite.mailSenderMock.Calls[0].Arguments.Get(0).(models.MailMessage)
+
+	suite.Equal(int64(12), claims.UserId)
+	suite.Equal("test@test.com", claims.Email)
+	suite.True(claims.ExpiresAt.After(time.Now()))
+	suite.Equal("test@test.com", message.To)
+	suite.Contains(message.Body, "/email/verify?token=signed-token")
+}
+
+// When an email was sent too recently, nothing is sent
+func (suite *EmailVerificationServiceUnitTestSuite) TestSendVerificationEmailThrottled_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("MarkVerificationEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := suite.service.SendVerificationEmail(models.User{Id: 12, Email: "test@test.com"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.VERIFICATION_EMAIL_THROTTLED_ERROR, err.Error())
+	suite.mailSenderMock.AssertNotCalled(suite.T(), "Send", mock.Anything)
+}
+
+// When the user is already verified, no email is sent
+func (suite *EmailVerificationServiceUnitTestSuite) TestResendVerificationEmailAlreadyVerified_ReturnsFalse() {
+
+	verifiedAt := time.Now()
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, VerifiedAt: &verifiedAt}, nil)
+
+	sent, err := suite.service.ResendVerificationEmail(12)
+
+	suite.Nil(err)
+	suite.False(sent)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "MarkVerificationEmailSent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmailInvalidToken_ReturnsAnError() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(nil, errors.New("test"))
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_VERIFICATION_TOKEN_ERROR, err.Error())
+}
+
+// When the user changed their email since the link was sent, the token is refused
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmailChangedEmail_ReturnsAnError() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(&models.EmailVerificationClaims{UserId: 12, Email: "old@test.com"}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "new@test.com"}, nil)
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_VERIFICATION_TOKEN_ERROR, err.Error())
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "SetUserVerifiedAt", mock.Anything, mock.Anything)
+}
+
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmail_MarksTheUserVerified() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(&models.EmailVerificationClaims{UserId: 12, Email: "test@test.com"}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.userRepositoryMock.On("SetUserVerifiedAt", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "SetUserVerifiedAt", int64(12), mock.Anything)
+}
+
+// The link is sent to the new email, along with the current one so it only works once
+func (suite *EmailVerificationServiceUnitTestSuite) TestSendEmailChangeVerification_EmailsTheNewAddress() {
+
+	suite.userRepositoryMock.On("MarkVerificationEmailSent", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	suite.verificationTokenSignerMock.On("Sign", mock.Anything).Return("signed-token", nil)
+	suite.mailSenderMock.On("Send", mock.Anything).Return(nil)
+
+	err := suite.service.SendEmailChangeVerification(models.User{Id: 12, Email: "old@test.com"}, "new@test.com")
+
+	suite.Nil(err)
+
+	claims := suite.verificationTokenSignerMock.Calls[0].Arguments.Get(0).(models.EmailVerificationClaims)
+	message := suite.mailSenderMock.Calls[0].Arguments.Get(0).(models.MailMessage)
+
+	suite.Equal("new@test.com", claims.Email)
+	suite.Equal("old@test.com", claims.PreviousEmail)
+	suite.Equal("new@test.com", message.To)
+	suite.Contains(message.Body, "/email/verify?token=signed-token")
+}
+
+// Opening an email change link switches the user to the new email and tells the previous one
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmailChange_UpdatesTheEmail() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(&models.EmailVerificationClaims{
+		UserId:        12,
+		Email:         "new@test.com",
+		PreviousEmail: "old@test.com",
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "old@test.com"}, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", "new@test.com").Return(nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR))
+	suite.userRepositoryMock.On("UpdateUserEmail", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.mailSenderMock.On("Send", mock.Anything).Return(nil)
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "UpdateUserEmail", int64(12), "new@test.com", mock.Anything)
+
+	message := suite.mailSenderMock.Calls[0].Arguments.Get(0).(models.MailMessage)
+
+	suite.Equal("old@test.com", message.To)
+}
+
+// When the user changed their email again since the link was sent, the link is refused
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmailChangeOutdated_ReturnsAnError() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(&models.EmailVerificationClaims{
+		UserId:        12,
+		Email:         "new@test.com",
+		PreviousEmail: "old@test.com",
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "other@test.com"}, nil)
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.EqualError(err, constants.INVALID_VERIFICATION_TOKEN_ERROR)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "UpdateUserEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When another account took the email since the link was sent, the email is not changed
+func (suite *EmailVerificationServiceUnitTestSuite) TestVerifyEmailChangeEmailInUse_ReturnsAnError() {
+
+	suite.verificationTokenSignerMock.On("Verify", mock.Anything).Return(&models.EmailVerificationClaims{
+		UserId:        12,
+		Email:         "new@test.com",
+		PreviousEmail: "old@test.com",
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "old@test.com"}, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", "new@test.com").Return(&models.User{Id: 13}, nil)
+
+	err := suite.service.VerifyEmail("token")
+
+	suite.EqualError(err, constants.EMAIL_ALREADY_IN_USE_ERROR)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "UpdateUserEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *EmailVerificationServiceUnitTestSuite) TestIsEmailVerified_ReturnsTheVerificationStatus() {
+
+	verifiedAt := time.Now()
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, VerifiedAt: &verifiedAt}, nil)
+
+	verified, err := suite.service.IsEmailVerified(12)
+
+	suite.Nil(err)
+	suite.True(verified)
+}
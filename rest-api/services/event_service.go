@@ -1,26 +1,52 @@
 package services
 
 import (
-	interfaces "example.com/interfaces/repositories"
+	"sort"
+	"time"
+
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
 	"example.com/models"
 )
 
 type EventService struct {
-	eventRepository interfaces.IEventRepository
+	eventRepository      repositoryInterfaces.IEventRepository
+	invitationRepository repositoryInterfaces.IInvitationRepository
+	eventBroadcaster     libInterfaces.IEventBroadcaster
+	geocoder             libInterfaces.IGeocoder
 }
 
 func (eventService EventService) SaveEvent(event *models.Event) error {
+	eventService.resolveCoordinates(event)
+
+	if event.Visibility == "" {
+		event.Visibility = models.PUBLIC_VISIBILITY
+	}
+
 	err := eventService.eventRepository.AddEvent(event)
 
 	if err != nil {
 		return err
 	}
 
+	//copying the event so later changes made by the caller are not broadcasted
+	createdEvent := *event
+
+	eventService.eventBroadcaster.Publish(models.EventNotification{
+		Type:           models.EVENT_CREATED_NOTIFICATION,
+		EventId:        createdEvent.Id,
+		Event:          &createdEvent,
+		Visibility:     createdEvent.Visibility,
+		OrganizationId: createdEvent.OrganizationId,
+	})
+
 	return nil
 }
 
-func (eventService EventService) GetEvents() ([]models.Event, error) {
-	events, err := eventService.eventRepository.GetEvents()
+// Returns the events of the tenant listed for the viewer, a viewer id of 0 means an anonymous viewer
+func (eventService EventService) GetEvents(viewerId, organizationId int64) ([]models.Event, error) {
+	events, err := eventService.eventRepository.GetEvents(viewerId, organizationId)
 
 	if err != nil {
 		return nil, err
@@ -29,8 +55,8 @@ func (eventService EventService) GetEvents() ([]models.Event, error) {
 	return events, nil
 }
 
-func (eventService EventService) GetEventById(id int64) (*models.Event, error) {
-	event, err := eventService.eventRepository.GetEventById(id)
+func (eventService EventService) GetEventById(id, organizationId int64) (*models.Event, error) {
+	event, err := eventService.eventRepository.GetEventById(id, organizationId)
 
 	if err != nil {
 		return nil, err
@@ -39,28 +65,155 @@ func (eventService EventService) GetEventById(id int64) (*models.Event, error) {
 	return event, nil
 }
 
-func (eventService EventService) UpdateEvent(id int64, event models.Event) error {
-	err := eventService.eventRepository.UpdateEvent(id, event)
+// Returns the event when the viewer is allowed to see it, otherwise an event with an id of 0 is
+// returned so hidden events cannot be told apart from missing ones. Events are only visible within
+// their tenant, private events to their organizer, invitees and anyone holding a valid invitation token
+func (eventService EventService) GetVisibleEventById(id, viewerId, organizationId int64, invitationToken string) (*models.Event, error) {
+	event, err := eventService.eventRepository.GetEventById(id, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Id == 0 || event.Visibility != models.PRIVATE_VISIBILITY || event.UserId == viewerId {
+		return event, nil
+	}
+
+	if viewerId != 0 {
+		invited, err := eventService.invitationRepository.IsInvited(id, viewerId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if invited {
+			return event, nil
+		}
+	}
+
+	if invitationToken != "" {
+		invitation, err := eventService.invitationRepository.GetInvitationByTokenHash(id, lib.HashOpaqueToken(invitationToken))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if invitation.IsUsable(time.Now()) {
+			return event, nil
+		}
+	}
+
+	return &models.Event{}, nil
+}
+
+func (eventService EventService) UpdateEvent(id, organizationId int64, event models.Event) error {
+	eventService.resolveCoordinates(&event)
+
+	err := eventService.eventRepository.UpdateEvent(id, organizationId, event)
 
 	if err != nil {
 		return err
 	}
 
+	event.Id = id
+	event.OrganizationId = organizationId
+
+	eventService.eventBroadcaster.Publish(models.EventNotification{
+		Type:           models.EVENT_UPDATED_NOTIFICATION,
+		EventId:        id,
+		Event:          &event,
+		Visibility:     event.Visibility,
+		OrganizationId: organizationId,
+	})
+
 	return nil
 }
 
-func (eventService EventService) DeleteEvent(id int64) error {
-	err := eventService.eventRepository.DeleteEvent(id)
+func (eventService EventService) DeleteEvent(id, organizationId int64) error {
+	//fetched beforehand, so the notification can be kept out of public streams when needed
+	event, err := eventService.eventRepository.GetEventById(id, organizationId)
 
 	if err != nil {
 		return err
 	}
 
+	err = eventService.eventRepository.DeleteEvent(id, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	eventService.eventBroadcaster.Publish(models.EventNotification{
+		Type:           models.EVENT_DELETED_NOTIFICATION,
+		EventId:        id,
+		Visibility:     event.Visibility,
+		OrganizationId: organizationId,
+	})
+
 	return nil
 }
 
-func NewEventService(eventRepository interfaces.IEventRepository) *EventService {
+// Returns the events of the tenant within radiusKm of the coordinates, closest first
+func (eventService EventService) GetEventsNear(latitude, longitude, radiusKm float64, viewerId, organizationId int64) ([]models.EventWithDistance, error) {
+	candidates, err := eventService.eventRepository.GetEventsWithinBounds(
+		lib.BoundingBoxAround(latitude, longitude, radiusKm),
+		viewerId,
+		organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//the bounding box includes its corners, which are further away than the radius
+	events := make([]models.EventWithDistance, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		distance := lib.HaversineDistance(latitude, longitude, *candidate.Latitude, *candidate.Longitude)
+
+		if distance > radiusKm {
+			continue
+		}
+
+		events = append(events, models.EventWithDistance{
+			Event:      candidate,
+			DistanceKm: distance,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].DistanceKm < events[j].DistanceKm
+	})
+
+	return events, nil
+}
+
+// Events created without coordinates are placed using their location when it is a known place
+func (eventService EventService) resolveCoordinates(event *models.Event) {
+	if event.Latitude != nil && event.Longitude != nil {
+		return
+	}
+
+	latitude, longitude, found := eventService.geocoder.Geocode(event.Location)
+
+	if !found {
+		event.Latitude = nil
+		event.Longitude = nil
+		return
+	}
+
+	event.Latitude = &latitude
+	event.Longitude = &longitude
+}
+
+func NewEventService(
+	eventRepository repositoryInterfaces.IEventRepository,
+	invitationRepository repositoryInterfaces.IInvitationRepository,
+	eventBroadcaster libInterfaces.IEventBroadcaster,
+	geocoder libInterfaces.IGeocoder) *EventService {
 	return &EventService{
-		eventRepository: eventRepository,
+		eventRepository:      eventRepository,
+		invitationRepository: invitationRepository,
+		eventBroadcaster:     eventBroadcaster,
+		geocoder:             geocoder,
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"example.com/lib"
 	"example.com/mocks"
 	"example.com/models"
 	"github.com/stretchr/testify/mock"
@@ -13,8 +15,11 @@ import (
 
 type EventServiceUnitTestSuite struct {
 	suite.Suite
-	eventRepositoryMock mocks.IEventRepository
-	service             *EventService
+	eventRepositoryMock      mocks.IEventRepository
+	invitationRepositoryMock mocks.IInvitationRepository
+	eventBroadcasterMock     mocks.IEventBroadcaster
+	geocoderMock             mocks.IGeocoder
+	service                  *EventService
 }
 
 func TestEventServiceUnitTestSuite(t *testing.T) {
@@ -23,8 +28,18 @@ func TestEventServiceUnitTestSuite(t *testing.T) {
 
 func (suite *EventServiceUnitTestSuite) SetupTest() {
 	suite.eventRepositoryMock = mocks.IEventRepository{}
-
-	suite.service = NewEventService(&suite.eventRepositoryMock)
+	suite.invitationRepositoryMock = mocks.IInvitationRepository{}
+	suite.eventBroadcasterMock = mocks.IEventBroadcaster{}
+	suite.geocoderMock = mocks.IGeocoder{}
+
+	suite.eventBroadcasterMock.On("Publish", mock.Anything).Return()
+	suite.geocoderMock.On("Geocode", mock.Anything).Return(0.0, 0.0, false)
+
+	suite.service = NewEventService(
+		&suite.eventRepositoryMock,
+		&suite.invitationRepositoryMock,
+		&suite.eventBroadcasterMock,
+		&suite.geocoderMock)
 }
 
 func (suite *EventServiceUnitTestSuite) TestSaveEvent_AttemptToCreateAnEvent() {
@@ -63,11 +78,11 @@ func (suite *EventServiceUnitTestSuite) TestSaveEvent_ReturnsNil() {
 
 func (suite *EventServiceUnitTestSuite) TestGetEvents_AttemptToCreateAnEvent() {
 
-	suite.eventRepositoryMock.On("GetEvents").Return(nil, errors.New("test"))
+	suite.eventRepositoryMock.On("GetEvents", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
-	suite.service.GetEvents()
+	suite.service.GetEvents(0, 0)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEvents")
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEvents", int64(0), int64(0))
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "GetEvents", 1)
 
 }
@@ -77,9 +92,9 @@ func (suite *EventServiceUnitTestSuite) TestGetEvents_ReturnsError() {
 
 	mockError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEvents").Return(nil, mockError)
+	suite.eventRepositoryMock.On("GetEvents", mock.Anything, mock.Anything).Return(nil, mockError)
 
-	_, err := suite.service.GetEvents()
+	_, err := suite.service.GetEvents(0, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err.Error(), mockError.Error())
@@ -96,9 +111,9 @@ func (suite *EventServiceUnitTestSuite) TestGetEvents_ReturnsExistingEvents() {
 		},
 	}
 
-	suite.eventRepositoryMock.On("GetEvents", mock.Anything).Return(mockEvents, nil)
+	suite.eventRepositoryMock.On("GetEvents", mock.Anything, mock.Anything).Return(mockEvents, nil)
 
-	result, _ := suite.service.GetEvents()
+	result, _ := suite.service.GetEvents(0, 0)
 
 	suite.NotNil(result)
 	suite.Equal(result, mockEvents)
@@ -108,11 +123,11 @@ func (suite *EventServiceUnitTestSuite) TestGetEventById_AttemptsToFetchEventByI
 
 	var expectedEventId int64 = 1
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
-	suite.service.GetEventById(expectedEventId)
+	suite.service.GetEventById(expectedEventId, 0)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId, int64(0))
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
 }
 
@@ -121,9 +136,9 @@ func (suite *EventServiceUnitTestSuite) TestGetEventById_ReturnsAnError() {
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, expectedError)
 
-	_, err := suite.service.GetEventById(1)
+	_, err := suite.service.GetEventById(1, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -137,9 +152,9 @@ func (suite *EventServiceUnitTestSuite) TestGetEventById_ReturnsTheEvent() {
 		Location: "some location",
 	}
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(&mockEvent, nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&mockEvent, nil)
 
-	result, _ := suite.service.GetEventById(1)
+	result, _ := suite.service.GetEventById(1, 0)
 
 	suite.NotNil(result)
 	suite.Equal(*result, mockEvent)
@@ -155,11 +170,11 @@ func (suite *EventServiceUnitTestSuite) TestUpdateEvent_AttemptsToUpdateTheEvent
 		Location: "some location",
 	}
 
-	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
 
-	suite.service.UpdateEvent(expectedEventId, expectedEvent)
+	suite.service.UpdateEvent(expectedEventId, 0, expectedEvent)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "UpdateEvent", expectedEventId, expectedEvent)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "UpdateEvent", expectedEventId, int64(0), expectedEvent)
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "UpdateEvent", 1)
 }
 
@@ -168,9 +183,9 @@ func (suite *EventServiceUnitTestSuite) TestUpdateEvent_ReturnsAnError() {
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(expectedError)
+	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(expectedError)
 
-	err := suite.service.UpdateEvent(int64(1), models.Event{})
+	err := suite.service.UpdateEvent(int64(1), 0, models.Event{})
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -178,9 +193,9 @@ func (suite *EventServiceUnitTestSuite) TestUpdateEvent_ReturnsAnError() {
 
 func (suite *EventServiceUnitTestSuite) TestUpdateEvent_ReturnsNil() {
 
-	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything).Return(nil)
+	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	err := suite.service.UpdateEvent(int64(1), models.Event{})
+	err := suite.service.UpdateEvent(int64(1), 0, models.Event{})
 
 	suite.Nil(err)
 }
@@ -189,11 +204,12 @@ func (suite *EventServiceUnitTestSuite) TestDeleteEvent_AttemptsToDeleteTheEvent
 
 	var expectedEventId int64 = 1
 
-	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything).Return(errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
 
-	suite.service.DeleteEvent(expectedEventId)
+	suite.service.DeleteEvent(expectedEventId, 0)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "DeleteEvent", expectedEventId)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "DeleteEvent", expectedEventId, int64(0))
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "DeleteEvent", 1)
 }
 
@@ -202,9 +218,10 @@ func (suite *EventServiceUnitTestSuite) TestDeleteEvent_ReturnsAnError() {
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything).Return(expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(expectedError)
 
-	err := suite.service.DeleteEvent(1)
+	err := suite.service.DeleteEvent(1, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -212,9 +229,301 @@ func (suite *EventServiceUnitTestSuite) TestDeleteEvent_ReturnsAnError() {
 
 func (suite *EventServiceUnitTestSuite) TestDeleteEvent_ReturnsNil() {
 
-	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything).Return(nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.DeleteEvent(1, 0)
+
+	suite.Nil(err)
+}
+
+func (suite *EventServiceUnitTestSuite) TestSaveEvent_PublishesCreatedNotification() {
+
+	suite.eventRepositoryMock.On("AddEvent", mock.Anything).Return(nil)
+
+	suite.service.SaveEvent(&models.Event{Id: 3, Name: "some name"})
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", mock.MatchedBy(func(notification models.EventNotification) bool {
+		return notification.Type == models.EVENT_CREATED_NOTIFICATION &&
+			notification.EventId == 3 &&
+			notification.Event.Name == "some name"
+	}))
+}
+
+// When the event fails to save, nothing should be broadcasted
+func (suite *EventServiceUnitTestSuite) TestSaveEventWhenUnableToSave_DoesNotPublish() {
+
+	suite.eventRepositoryMock.On("AddEvent", mock.Anything).Return(errors.New("test"))
+
+	suite.service.SaveEvent(&models.Event{})
+
+	suite.eventBroadcasterMock.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *EventServiceUnitTestSuite) TestUpdateEvent_PublishesUpdatedNotification() {
+
+	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.UpdateEvent(int64(4), 0, models.Event{Name: "some name"})
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", mock.MatchedBy(func(notification models.EventNotification) bool {
+		return notification.Type == models.EVENT_UPDATED_NOTIFICATION &&
+			notification.EventId == 4 &&
+			notification.Event.Id == 4
+	}))
+}
+
+func (suite *EventServiceUnitTestSuite) TestDeleteEvent_PublishesDeletedNotification() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.DeleteEvent(5, 0)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", models.EventNotification{
+		Type:    models.EVENT_DELETED_NOTIFICATION,
+		EventId: 5,
+	})
+}
+
+// When the event fails to delete, nothing should be broadcasted
+func (suite *EventServiceUnitTestSuite) TestDeleteEventWhenUnableToDelete_DoesNotPublish() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(errors.New("test"))
+
+	suite.service.DeleteEvent(5, 0)
+
+	suite.eventBroadcasterMock.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+// When no coordinates are provided, the location should be geocoded
+func (suite *EventServiceUnitTestSuite) TestSaveEventWithoutCoordinates_GeocodesTheLocation() {
+
+	suite.geocoderMock = mocks.IGeocoder{}
+	suite.geocoderMock.On("Geocode", mock.Anything).Return(52.52, 13.405, true)
+	suite.eventRepositoryMock.On("AddEvent", mock.Anything).Return(nil)
+
+	event := models.Event{Location: "Berlin"}
+
+	suite.service.SaveEvent(&event)
+
+	suite.geocoderMock.AssertCalled(suite.T(), "Geocode", "Berlin")
+	suite.Equal(52.52, *event.Latitude)
+	suite.Equal(13.405, *event.Longitude)
+}
+
+func (suite *EventServiceUnitTestSuite) TestSaveEventWithCoordinates_DoesNotGeocode() {
+
+	latitude, longitude := 1.0, 2.0
+
+	suite.eventRepositoryMock.On("AddEvent", mock.Anything).Return(nil)
+
+	suite.service.SaveEvent(&models.Event{Location: "Berlin", Latitude: &latitude, Longitude: &longitude})
+
+	suite.geocoderMock.AssertNotCalled(suite.T(), "Geocode", mock.Anything)
+}
+
+func (suite *EventServiceUnitTestSuite) TestGetEventsNear_QueriesTheBoundingBox() {
+
+	suite.eventRepositoryMock.On("GetEventsWithinBounds", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("test"))
+
+	suite.service.GetEventsNear(0, 0, 111.19, 9, 0)
+
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventsWithinBounds", mock.MatchedBy(func(bounds models.BoundingBox) bool {
+		return bounds.MinLatitude > -1.01 && bounds.MinLatitude < -0.99 &&
+			bounds.MaxLongitude > 0.99 && bounds.MaxLongitude < 1.01
+	}), int64(9), int64(0))
+}
+
+// When an error occurs during db access, return the error
+func (suite *EventServiceUnitTestSuite) TestGetEventsNear_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.eventRepositoryMock.On("GetEventsWithinBounds", mock.Anything, mock.Anything, mock.Anything).Return(nil, expectedError)
+
+	_, err := suite.service.GetEventsNear(0, 0, 10, 0, 0)
+
+	suite.Equal(expectedError, err)
+}
+
+// Candidates in the corners of the bounding box are further than the radius and get filtered out
+func (suite *EventServiceUnitTestSuite) TestGetEventsNear_ReturnsEventsWithinTheRadiusClosestFirst() {
+
+	berlinLatitude, berlinLongitude := 52.52, 13.405
+	potsdamLatitude, potsdamLongitude := 52.3906, 13.0645
+	hamburgLatitude, hamburgLongitude := 53.5511, 9.9937
+
+	suite.eventRepositoryMock.On("GetEventsWithinBounds", mock.Anything, mock.Anything, mock.Anything).Return([]models.Event{
+		{Id: 1, Latitude: &hamburgLatitude, Longitude: &hamburgLongitude},
+		{Id: 2, Latitude: &potsdamLatitude, Longitude: &potsdamLongitude},
+		{Id: 3, Latitude: &berlinLatitude, Longitude: &berlinLongitude},
+	}, nil)
+
+	events, err := suite.service.GetEventsNear(berlinLatitude, berlinLongitude, 50, 0, 0)
+
+	suite.Nil(err)
+	suite.Equal(2, len(events))
+	suite.Equal(int64(3), events[0].Id)
+	suite.Equal(0.0, events[0].DistanceKm)
+	suite.Equal(int64(2), events[1].Id)
+	suite.InDelta(27.1, events[1].DistanceKm, 0.5)
+}
+
+// When no visibility is provided, events default to public
+func (suite *EventServiceUnitTestSuite) TestSaveEventWithoutVisibility_DefaultsToPublic() {
+
+	suite.eventRepositoryMock.On("AddEvent", mock.Anything).Return(nil)
+
+	event := models.Event{}
+
+	suite.service.SaveEvent(&event)
+
+	suite.Equal(models.PUBLIC_VISIBILITY, event.Visibility)
+}
+
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdForPublicEvent_ReturnsTheEvent() {
+
+	mockEvent := models.Event{Id: 1, UserId: 3, Visibility: models.PUBLIC_VISIBILITY}
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&mockEvent, nil)
+
+	result, err := suite.service.GetVisibleEventById(1, 0, 0, "")
+
+	suite.Nil(err)
+	suite.Equal(&mockEvent, result)
+	suite.invitationRepositoryMock.AssertNotCalled(suite.T(), "IsInvited", mock.Anything, mock.Anything)
+}
+
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdForOrganizer_ReturnsThePrivateEvent() {
+
+	mockEvent := models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&mockEvent, nil)
+
+	result, _ := suite.service.GetVisibleEventById(1, 3, 0, "")
+
+	suite.Equal(&mockEvent, result)
+}
+
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdForInvitee_ReturnsThePrivateEvent() {
+
+	mockEvent := models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&mockEvent, nil)
+	suite.invitationRepositoryMock.On("IsInvited", mock.Anything, mock.Anything).Return(true, nil)
+
+	result, _ := suite.service.GetVisibleEventById(1, 12, 0, "")
+
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "IsInvited", int64(1), int64(12))
+	suite.Equal(&mockEvent, result)
+}
+
+// When the viewer holds a usable invitation token, the private event is visible even anonymously
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdWithInvitationToken_ReturnsThePrivateEvent() {
+
+	mockEvent := models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&mockEvent, nil)
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	result, _ := suite.service.GetVisibleEventById(1, 0, 0, "some token")
+
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "GetInvitationByTokenHash", int64(1), lib.HashOpaqueToken("some token"))
+	suite.Equal(&mockEvent, result)
+}
+
+// When the invitation has expired, the private event is reported as missing
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdWithExpiredInvitation_ReturnsEmptyEvent() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}, nil)
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}, nil)
+
+	result, _ := suite.service.GetVisibleEventById(1, 0, 0, "some token")
+
+	suite.Equal(int64(0), result.Id)
+}
 
-	err := suite.service.DeleteEvent(1)
+// When the viewer is neither the organizer nor invited, the private event is reported as missing
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdNotInvited_ReturnsEmptyEvent() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}, nil)
+	suite.invitationRepositoryMock.On("IsInvited", mock.Anything, mock.Anything).Return(false, nil)
+
+	result, err := suite.service.GetVisibleEventById(1, 12, 0, "")
 
 	suite.Nil(err)
+	suite.Equal(int64(0), result.Id)
+}
+
+func (suite *EventServiceUnitTestSuite) TestDeleteEvent_PublishesTheVisibilityOfTheEvent() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5, Visibility: models.PRIVATE_VISIBILITY}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.DeleteEvent(5, 0)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", models.EventNotification{
+		Type:       models.EVENT_DELETED_NOTIFICATION,
+		EventId:    5,
+		Visibility: models.PRIVATE_VISIBILITY,
+	})
+}
+
+// Events are looked up within the tenant, so events of another organization are reported as missing
+func (suite *EventServiceUnitTestSuite) TestGetVisibleEventByIdOfAnotherTenant_ReturnsEmptyEvent() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{}, nil)
+
+	result, err := suite.service.GetVisibleEventById(1, 12, 4, "")
+
+	suite.Nil(err)
+	suite.Equal(int64(0), result.Id)
+	suite.eventRepositoryMock.AssertNotCalled(suite.T(), "GetEventById", int64(1), int64(0))
+}
+
+func (suite *EventServiceUnitTestSuite) TestGetEventsOfAnOrganization_ListsTheOrganizationEvents() {
+
+	suite.eventRepositoryMock.On("GetEvents", mock.Anything, mock.Anything).Return([]models.Event{}, nil)
+
+	suite.service.GetEvents(12, 4)
+
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEvents", int64(12), int64(4))
+}
+
+// Notifications carry the organization of the event, so they can be kept out of public streams
+func (suite *EventServiceUnitTestSuite) TestUpdateEventOfAnOrganization_PublishesTheOrganization() {
+
+	suite.eventRepositoryMock.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.UpdateEvent(5, 4, models.Event{Name: "some name"})
+
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "UpdateEvent", int64(5), int64(4), mock.Anything)
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", mock.MatchedBy(func(notification models.EventNotification) bool {
+		return notification.EventId == 5 &&
+			notification.OrganizationId == 4 &&
+			notification.Event.OrganizationId == 4
+	}))
+}
+
+func (suite *EventServiceUnitTestSuite) TestDeleteEventOfAnOrganization_IsScopedToTheOrganization() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 5, OrganizationId: 4}, nil)
+	suite.eventRepositoryMock.On("DeleteEvent", mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.DeleteEvent(5, 4)
+
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "DeleteEvent", int64(5), int64(4))
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", models.EventNotification{
+		Type:           models.EVENT_DELETED_NOTIFICATION,
+		EventId:        5,
+		OrganizationId: 4,
+	})
 }
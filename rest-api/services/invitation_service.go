@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const defaultInvitationLifetime = time.Hour * 24 * 7
+
+type InvitationService struct {
+	invitationRepository repositoryInterfaces.IInvitationRepository
+	eventRepository      repositoryInterfaces.IEventRepository
+	userRepository       repositoryInterfaces.IUserRepository
+}
+
+// Creates an invitation to the event, returning the invitation along with its token. Only the
+// hash of the token is stored, so the token cannot be retrieved again afterwards
+func (invitationService InvitationService) CreateInvitation(
+	eventId, organizerId, organizationId int64,
+	request models.InvitationRequest) (*models.Invitation, string, error) {
+	err := invitationService.ensureOrganizer(eventId, organizerId, organizationId)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, tokenHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	lifetime := defaultInvitationLifetime
+
+	if request.ExpiresInHours > 0 {
+		lifetime = time.Hour * time.Duration(request.ExpiresInHours)
+	}
+
+	now := time.Now().UTC()
+
+	invitation := models.Invitation{
+		EventId:   eventId,
+		Email:     request.Email,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(lifetime),
+		CreatedAt: now,
+	}
+
+	err = invitationService.invitationRepository.CreateInvitation(&invitation)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &invitation, token, nil
+}
+
+func (invitationService InvitationService) GetInvitations(eventId, organizerId, organizationId int64) ([]models.Invitation, error) {
+	err := invitationService.ensureOrganizer(eventId, organizerId, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return invitationService.invitationRepository.GetInvitationsByEventId(eventId)
+}
+
+func (invitationService InvitationService) RevokeInvitation(eventId, invitationId, organizerId, organizationId int64) error {
+	err := invitationService.ensureOrganizer(eventId, organizerId, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	return invitationService.invitationRepository.RevokeInvitation(eventId, invitationId, time.Now().UTC())
+}
+
+// Makes the user an invitee of the event. Invitations restricted to an email can only be
+// accepted by the user owning that email, and only events of the tenant can be joined
+func (invitationService InvitationService) AcceptInvitation(eventId, userId, organizationId int64, token string) error {
+	event, err := invitationService.eventRepository.GetEventById(eventId, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	if event.Id == 0 {
+		return errors.New(constants.NO_EVENT_FOR_ID_ERROR)
+	}
+
+	invitation, err := invitationService.invitationRepository.GetInvitationByTokenHash(eventId, lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return err
+	}
+
+	if !invitation.IsUsable(time.Now()) {
+		return errors.New(constants.INVALID_INVITATION_ERROR)
+	}
+
+	//already accepted invitations act as single use links
+	if invitation.UserId != nil {
+		if *invitation.UserId == userId {
+			return nil
+		}
+
+		return errors.New(constants.INVALID_INVITATION_ERROR)
+	}
+
+	if invitation.Email != nil {
+		user, err := invitationService.userRepository.GetUserById(userId)
+
+		if err != nil {
+			return err
+		}
+
+		if user.Email != *invitation.Email {
+			return errors.New(constants.INVALID_INVITATION_ERROR)
+		}
+	}
+
+	return invitationService.invitationRepository.AcceptInvitation(invitation.Id, userId, time.Now().UTC())
+}
+
+func (invitationService InvitationService) ensureOrganizer(eventId, userId, organizationId int64) error {
+	event, err := invitationService.eventRepository.GetEventById(eventId, organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	if event.Id == 0 {
+		return errors.New(constants.NO_EVENT_FOR_ID_ERROR)
+	}
+
+	if event.UserId != userId {
+		return errors.New(constants.NOT_EVENT_ORGANIZER_ERROR)
+	}
+
+	return nil
+}
+
+func NewInvitationService(
+	invitationRepository repositoryInterfaces.IInvitationRepository,
+	eventRepository repositoryInterfaces.IEventRepository,
+	userRepository repositoryInterfaces.IUserRepository) *InvitationService {
+	return &InvitationService{
+		invitationRepository: invitationRepository,
+		eventRepository:      eventRepository,
+		userRepository:       userRepository,
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type InvitationServiceUnitTestSuite struct {
+	suite.Suite
+	invitationRepositoryMock mocks.IInvitationRepository
+	eventRepositoryMock      mocks.IEventRepository
+	userRepositoryMock       mocks.IUserRepository
+	service                  *InvitationService
+}
+
+func TestInvitationServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &InvitationServiceUnitTestSuite{})
+}
+
+func (suite *InvitationServiceUnitTestSuite) SetupTest() {
+	suite.invitationRepositoryMock = mocks.IInvitationRepository{}
+	suite.eventRepositoryMock = mocks.IEventRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+
+	suite.service = NewInvitationService(
+		&suite.invitationRepositoryMock,
+		&suite.eventRepositoryMock,
+		&suite.userRepositoryMock)
+}
+
+// When there is no event for the provided id, return an error
+func (suite *InvitationServiceUnitTestSuite) TestCreateInvitationWhenNoEventFound_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{}, nil)
+
+	_, _, err := suite.service.CreateInvitation(1, 3, 0, models.InvitationRequest{})
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_EVENT_FOR_ID_ERROR, err.Error())
+}
+
+// When the user is not the organizer of the event, return an error
+func (suite *InvitationServiceUnitTestSuite) TestCreateInvitationNotTheOrganizer_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	_, _, err := suite.service.CreateInvitation(1, 12, 0, models.InvitationRequest{})
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_EVENT_ORGANIZER_ERROR, err.Error())
+	suite.invitationRepositoryMock.AssertNotCalled(suite.T(), "CreateInvitation", mock.Anything)
+}
+
+// Only the hash of the returned token should be stored
+func (suite *InvitationServiceUnitTestSuite) TestCreateInvitation_StoresTheHashOfTheToken() {
+
+	email := "invitee@test.com"
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.invitationRepositoryMock.On("CreateInvitation", mock.Anything).Return(nil)
+
+	invitation, token, err := suite.service.CreateInvitation(1, 3, 0, models.InvitationRequest{
+		Email:          &email,
+		ExpiresInHours: 2,
+	})
+
+	suite.Nil(err)
+	suite.NotEmpty(token)
+	suite.Equal(lib.HashOpaqueToken(token), invitation.TokenHash)
+	suite.Equal(&email, invitation.Email)
+	suite.WithinDuration(time.Now().Add(time.Hour*2), invitation.ExpiresAt, time.Minute)
+}
+
+// When no expiry is provided, invitations expire after a week
+func (suite *InvitationServiceUnitTestSuite) TestCreateInvitationWithoutExpiry_DefaultsToAWeek() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.invitationRepositoryMock.On("CreateInvitation", mock.Anything).Return(nil)
+
+	invitation, _, _ := suite.service.CreateInvitation(1, 3, 0, models.InvitationRequest{})
+
+	suite.WithinDuration(time.Now().Add(time.Hour*24*7), invitation.ExpiresAt, time.Minute)
+}
+
+func (suite *InvitationServiceUnitTestSuite) TestGetInvitationsNotTheOrganizer_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+
+	_, err := suite.service.GetInvitations(1, 12, 0)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_EVENT_ORGANIZER_ERROR, err.Error())
+}
+
+func (suite *InvitationServiceUnitTestSuite) TestRevokeInvitation_RevokesTheInvitation() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3}, nil)
+	suite.invitationRepositoryMock.On("RevokeInvitation", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.RevokeInvitation(1, 2, 3, 0)
+
+	suite.Nil(err)
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "RevokeInvitation", int64(1), int64(2), mock.Anything)
+}
+
+// When fetching the invitation returns an error, pass that error up
+func (suite *InvitationServiceUnitTestSuite) TestAcceptInvitation_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	expectedError := errors.New("test")
+
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(nil, expectedError)
+
+	err := suite.service.AcceptInvitation(1, 12, 4, "some token")
+
+	suite.Equal(expectedError, err)
+}
+
+// When the event belongs to another tenant, it cannot be joined whatever the token
+func (suite *InvitationServiceUnitTestSuite) TestAcceptInvitationOfAnotherTenant_ReturnsNoEventForId() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(5)).Return(&models.Event{}, nil)
+
+	err := suite.service.AcceptInvitation(1, 12, 5, "some token")
+
+	suite.Equal(constants.NO_EVENT_FOR_ID_ERROR, err.Error())
+	suite.invitationRepositoryMock.AssertNotCalled(suite.T(), "GetInvitationByTokenHash", mock.Anything, mock.Anything)
+}
+
+// When the invitation is revoked, it can no longer be accepted
+func (suite *InvitationServiceUnitTestSuite) TestAcceptRevokedInvitation_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	revokedAt := time.Now()
+
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	err := suite.service.AcceptInvitation(1, 12, 4, "some token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_INVITATION_ERROR, err.Error())
+}
+
+// When the invitation is restricted to an email, it can only be accepted by the owner of the email
+func (suite *InvitationServiceUnitTestSuite) TestAcceptInvitationForAnotherEmail_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	email := "invitee@test.com"
+
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		Email:     &email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "someone@test.com"}, nil)
+
+	err := suite.service.AcceptInvitation(1, 12, 4, "some token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_INVITATION_ERROR, err.Error())
+	suite.invitationRepositoryMock.AssertNotCalled(suite.T(), "AcceptInvitation", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the invitation was accepted by someone else, the link cannot be reused
+func (suite *InvitationServiceUnitTestSuite) TestAcceptInvitationAcceptedByAnotherUser_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	userId := int64(7)
+
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		UserId:    &userId,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	err := suite.service.AcceptInvitation(1, 12, 4, "some token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_INVITATION_ERROR, err.Error())
+}
+
+func (suite *InvitationServiceUnitTestSuite) TestAcceptInvitation_AcceptsTheInvitation() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	email := "invitee@test.com"
+
+	suite.invitationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.Invitation{
+		Id:        2,
+		Email:     &email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: email}, nil)
+	suite.invitationRepositoryMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.AcceptInvitation(1, 12, 4, "some token")
+
+	suite.Nil(err)
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "GetInvitationByTokenHash", int64(1), lib.HashOpaqueToken("some token"))
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "AcceptInvitation", int64(2), int64(12), mock.Anything)
+}
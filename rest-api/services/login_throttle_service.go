@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+)
+
+const (
+	// Failures after which an account gets locked
+	accountLockoutThreshold = 10
+	accountLockoutDuration  = time.Minute * 15
+	// Failures allowed before attempts get delayed, accounts are targeted individually
+	// while a single ip can legitimately be shared by many users
+	accountFreeAttempts = 3
+	ipFreeAttempts      = 20
+	// Delay after the first throttled failure, doubled with every failure after it
+	loginBackoffBase = time.Second
+	loginBackoffMax  = time.Minute * 15
+	// Failures older than this are forgotten
+	loginFailureWindow = time.Hour * 24
+)
+
+type LoginThrottleService struct {
+	loginThrottleRepository repositoryInterfaces.ILoginThrottleRepository
+	userRepository          repositoryInterfaces.IUserRepository
+}
+
+// Returns how long the client has to wait before attempting to log in, 0 when it can try right away.
+// Attempts are tracked by email whether an account exists or not, so throttling does not reveal it.
+// Without an email, as before an external provider named the user, only the ip is checked
+func (loginThrottleService LoginThrottleService) CheckLogin(email, ip string) (time.Duration, error) {
+	now := time.Now().UTC()
+
+	accountThrottle := &models.LoginThrottle{}
+
+	if email != "" {
+		var err error
+
+		accountThrottle, err = loginThrottleService.loginThrottleRepository.GetLoginThrottle(accountThrottleKey(email))
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	ipThrottle, err := loginThrottleService.loginThrottleRepository.GetLoginThrottle(ipThrottleKey(ip))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return max(
+		retryAfter(*accountThrottle, accountFreeAttempts, now),
+		retryAfter(*ipThrottle, ipFreeAttempts, now)), nil
+}
+
+// Counts a failed attempt for both the account and the ip, locking the account once it reaches
+// the threshold. Without an email, only the ip is counted
+func (loginThrottleService LoginThrottleService) RecordFailedLogin(email, ip string) error {
+	now := time.Now().UTC()
+
+	_, err := loginThrottleService.loginThrottleRepository.RecordLoginFailure(ipThrottleKey(ip), now, now.Add(-loginFailureWindow))
+
+	if err != nil || email == "" {
+		return err
+	}
+
+	accountThrottle, err := loginThrottleService.loginThrottleRepository.RecordLoginFailure(accountThrottleKey(email), now, now.Add(-loginFailureWindow))
+
+	if err != nil {
+		return err
+	}
+
+	if accountThrottle.Failures >= accountLockoutThreshold {
+		return loginThrottleService.loginThrottleRepository.LockLogin(accountThrottle.Key, now.Add(accountLockoutDuration))
+	}
+
+	return nil
+}
+
+// Forgets the failures of the account. Those of the ip are kept, otherwise logging into their own
+// account would let an attacker keep guessing the passwords of others
+func (loginThrottleService LoginThrottleService) RecordSuccessfulLogin(email string) error {
+	return loginThrottleService.loginThrottleRepository.DeleteLoginThrottle(accountThrottleKey(email))
+}
+
+// Lifts the lock of the account along with its failures
+func (loginThrottleService LoginThrottleService) UnlockAccount(userId int64) error {
+	user, err := loginThrottleService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return err
+	}
+
+	if user.Id == 0 {
+		return errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	return loginThrottleService.loginThrottleRepository.DeleteLoginThrottle(accountThrottleKey(user.Email))
+}
+
+func retryAfter(throttle models.LoginThrottle, freeAttempts int, now time.Time) time.Duration {
+	var wait time.Duration
+
+	if throttle.LockedUntil != nil {
+		wait = throttle.LockedUntil.Sub(now)
+	}
+
+	if throttle.Failures < freeAttempts {
+		return max(wait, 0)
+	}
+
+	backoff := loginBackoffMax
+
+	//shifting is only safe while the delay stays below the maximum
+	if exponent := throttle.Failures - freeAttempts; exponent < 32 {
+		backoff = min(loginBackoffBase<<exponent, loginBackoffMax)
+	}
+
+	return max(wait, throttle.LastFailureAt.Add(backoff).Sub(now), 0)
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func NewLoginThrottleService(
+	loginThrottleRepository repositoryInterfaces.ILoginThrottleRepository,
+	userRepository repositoryInterfaces.IUserRepository) *LoginThrottleService {
+	return &LoginThrottleService{
+		loginThrottleRepository: loginThrottleRepository,
+		userRepository:          userRepository,
+	}
+}
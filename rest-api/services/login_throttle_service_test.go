@@ -0,0 +1,176 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoginThrottleServiceUnitTestSuite struct {
+	suite.Suite
+	loginThrottleRepositoryMock mocks.ILoginThrottleRepository
+	userRepositoryMock          mocks.IUserRepository
+	service                     *LoginThrottleService
+}
+
+func TestLoginThrottleServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &LoginThrottleServiceUnitTestSuite{})
+}
+
+func (suite *LoginThrottleServiceUnitTestSuite) SetupTest() {
+	suite.loginThrottleRepositoryMock = mocks.ILoginThrottleRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+
+	suite.service = NewLoginThrottleService(&suite.loginThrottleRepositoryMock, &suite.userRepositoryMock)
+}
+
+// Without recorded failures, logging in is allowed right away
+func (suite *LoginThrottleServiceUnitTestSuite) TestCheckLoginWithoutFailures_ReturnsZero() {
+
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", mock.Anything).Return(&models.LoginThrottle{}, nil)
+
+	retryAfter, err := suite.service.CheckLogin("Test@Test.com ", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.Equal(time.Duration(0), retryAfter)
+	suite.loginThrottleRepositoryMock.AssertCalled(suite.T(), "GetLoginThrottle", "account:test@test.com")
+	suite.loginThrottleRepositoryMock.AssertCalled(suite.T(), "GetLoginThrottle", "ip:127.0.0.1")
+}
+
+// Every failure past the free attempts doubles the delay
+func (suite *LoginThrottleServiceUnitTestSuite) TestCheckLoginAfterFailures_BacksOffExponentially() {
+
+	now := time.Now().UTC()
+
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "account:test@test.com").Return(&models.LoginThrottle{
+		Failures:      accountFreeAttempts + 3,
+		LastFailureAt: now,
+	}, nil)
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "ip:127.0.0.1").Return(&models.LoginThrottle{}, nil)
+
+	retryAfter, err := suite.service.CheckLogin("test@test.com", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.InDelta(float64(loginBackoffBase*8), float64(retryAfter), float64(time.Second))
+}
+
+// While the account is locked, the remaining lock duration is returned
+func (suite *LoginThrottleServiceUnitTestSuite) TestCheckLoginLockedAccount_ReturnsTheLockDuration() {
+
+	lockedUntil := time.Now().UTC().Add(time.Minute * 10)
+
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "account:test@test.com").Return(&models.LoginThrottle{
+		LockedUntil: &lockedUntil,
+	}, nil)
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "ip:127.0.0.1").Return(&models.LoginThrottle{}, nil)
+
+	retryAfter, err := suite.service.CheckLogin("test@test.com", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.InDelta(float64(time.Minute*10), float64(retryAfter), float64(time.Second))
+}
+
+// Failures of the ip throttle logins to every account
+func (suite *LoginThrottleServiceUnitTestSuite) TestCheckLoginThrottledIp_ReturnsTheDelay() {
+
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "account:test@test.com").Return(&models.LoginThrottle{}, nil)
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "ip:127.0.0.1").Return(&models.LoginThrottle{
+		Failures:      ipFreeAttempts,
+		LastFailureAt: time.Now().UTC(),
+	}, nil)
+
+	retryAfter, err := suite.service.CheckLogin("test@test.com", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.True(retryAfter > 0)
+}
+
+func (suite *LoginThrottleServiceUnitTestSuite) TestRecordFailedLoginReachingTheThreshold_LocksTheAccount() {
+
+	suite.loginThrottleRepositoryMock.On("RecordLoginFailure", "account:test@test.com", mock.Anything, mock.Anything).Return(&models.LoginThrottle{
+		Key:      "account:test@test.com",
+		Failures: accountLockoutThreshold,
+	}, nil)
+	suite.loginThrottleRepositoryMock.On("RecordLoginFailure", "ip:127.0.0.1", mock.Anything, mock.Anything).Return(&models.LoginThrottle{}, nil)
+	suite.loginThrottleRepositoryMock.On("LockLogin", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.RecordFailedLogin("test@test.com", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.loginThrottleRepositoryMock.AssertCalled(suite.T(), "LockLogin", "account:test@test.com", mock.Anything)
+}
+
+func (suite *LoginThrottleServiceUnitTestSuite) TestRecordFailedLoginBelowTheThreshold_DoesNotLock() {
+
+	suite.loginThrottleRepositoryMock.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(&models.LoginThrottle{
+		Failures: 1,
+	}, nil)
+
+	err := suite.service.RecordFailedLogin("test@test.com", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.loginThrottleRepositoryMock.AssertNumberOfCalls(suite.T(), "RecordLoginFailure", 2)
+	suite.loginThrottleRepositoryMock.AssertNotCalled(suite.T(), "LockLogin", mock.Anything, mock.Anything)
+}
+
+// Without an email, only the ip is counted
+func (suite *LoginThrottleServiceUnitTestSuite) TestRecordFailedLoginWithoutEmail_CountsTheIpOnly() {
+
+	suite.loginThrottleRepositoryMock.On("RecordLoginFailure", "ip:127.0.0.1", mock.Anything, mock.Anything).Return(&models.LoginThrottle{}, nil)
+
+	err := suite.service.RecordFailedLogin("", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.loginThrottleRepositoryMock.AssertNumberOfCalls(suite.T(), "RecordLoginFailure", 1)
+}
+
+// Without an email, only the ip is checked
+func (suite *LoginThrottleServiceUnitTestSuite) TestCheckLoginWithoutEmail_ChecksTheIpOnly() {
+
+	suite.loginThrottleRepositoryMock.On("GetLoginThrottle", "ip:127.0.0.1").Return(&models.LoginThrottle{}, nil)
+
+	retryAfter, err := suite.service.CheckLogin("", "127.0.0.1")
+
+	suite.Nil(err)
+	suite.Equal(time.Duration(0), retryAfter)
+	suite.loginThrottleRepositoryMock.AssertNumberOfCalls(suite.T(), "GetLoginThrottle", 1)
+}
+
+// Only the failures of the account are forgotten after logging in
+func (suite *LoginThrottleServiceUnitTestSuite) TestRecordSuccessfulLogin_ResetsTheAccount() {
+
+	suite.loginThrottleRepositoryMock.On("DeleteLoginThrottle", mock.Anything).Return(nil)
+
+	err := suite.service.RecordSuccessfulLogin("test@test.com")
+
+	suite.Nil(err)
+	suite.loginThrottleRepositoryMock.AssertCalled(suite.T(), "DeleteLoginThrottle", "account:test@test.com")
+	suite.loginThrottleRepositoryMock.AssertNumberOfCalls(suite.T(), "DeleteLoginThrottle", 1)
+}
+
+// When no user exists for the id, return an error
+func (suite *LoginThrottleServiceUnitTestSuite) TestUnlockAccountUnknownUser_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{}, nil)
+
+	err := suite.service.UnlockAccount(12)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_USER_FOR_ID_ERROR, err.Error())
+}
+
+func (suite *LoginThrottleServiceUnitTestSuite) TestUnlockAccount_DeletesTheAccountThrottle() {
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.loginThrottleRepositoryMock.On("DeleteLoginThrottle", mock.Anything).Return(nil)
+
+	err := suite.service.UnlockAccount(12)
+
+	suite.Nil(err)
+	suite.loginThrottleRepositoryMock.AssertCalled(suite.T(), "DeleteLoginThrottle", "account:test@test.com")
+}
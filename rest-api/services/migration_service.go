@@ -0,0 +1,308 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
+)
+
+// Locks held for longer were left behind by a process that died while migrating. Should a slow
+// migration outlive it, the process taking over fails to record the same version and rolls back
+const migrationLockTimeout = 5 * time.Minute
+
+var migrationFileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type MigrationService struct {
+	migrationRepository repositoryInterfaces.IMigrationRepository
+	migrationFiles      fs.FS
+}
+
+// Applies every pending migration in version order and returns them. Refuses to run when an applied
+// migration was modified or is unknown to this build, since the schema would not be the expected one
+func (migrationService MigrationService) MigrateUp() ([]models.Migration, error) {
+	migrations, err := migrationService.loadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	lockedAt, err := migrationService.lock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer migrationService.migrationRepository.ReleaseMigrationLock(lockedAt)
+
+	appliedMigrations, err := migrationService.getVerifiedAppliedMigrations(migrations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var newlyApplied []models.Migration
+
+	for _, migration := range migrations {
+		if _, applied := appliedMigrations[migration.Version]; applied {
+			continue
+		}
+
+		err = migrationService.migrationRepository.ApplyMigration(migration, time.Now().UTC())
+
+		if err != nil {
+			return newlyApplied, fmt.Errorf("unable to apply migration %v: %w", migrationLabel(migration.Version, migration.Name), err)
+		}
+
+		newlyApplied = append(newlyApplied, migration)
+	}
+
+	return newlyApplied, nil
+}
+
+// Reverts the latest steps applied migrations, newest first, and returns them
+func (migrationService MigrationService) MigrateDown(steps int) ([]models.Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("the number of migrations to revert has to be at least 1")
+	}
+
+	migrations, err := migrationService.loadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	lockedAt, err := migrationService.lock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer migrationService.migrationRepository.ReleaseMigrationLock(lockedAt)
+
+	appliedMigrations, err := migrationService.getVerifiedAppliedMigrations(migrations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []models.Migration
+
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := migrations[i]
+
+		if _, applied := appliedMigrations[migration.Version]; !applied {
+			continue
+		}
+
+		err = migrationService.migrationRepository.RevertMigration(migration)
+
+		if err != nil {
+			return reverted, fmt.Errorf("unable to revert migration %v: %w", migrationLabel(migration.Version, migration.Name), err)
+		}
+
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+// State of the migrations shipped with this build along with the applied migrations it does not
+// know, ordered by version
+func (migrationService MigrationService) GetStatus() ([]models.MigrationStatus, error) {
+	migrations, err := migrationService.loadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = migrationService.migrationRepository.CreateMigrationTables()
+
+	if err != nil {
+		return nil, err
+	}
+
+	appliedMigrations, err := migrationService.migrationRepository.GetAppliedMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	appliedByVersion := make(map[int64]models.AppliedMigration)
+
+	for _, appliedMigration := range appliedMigrations {
+		appliedByVersion[appliedMigration.Version] = appliedMigration
+	}
+
+	var statuses []models.MigrationStatus
+
+	for _, migration := range migrations {
+		status := models.MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			State:   models.PENDING_MIGRATION_STATE,
+		}
+
+		if appliedMigration, applied := appliedByVersion[migration.Version]; applied {
+			status.State = models.APPLIED_MIGRATION_STATE
+			status.AppliedAt = &appliedMigration.AppliedAt
+
+			if appliedMigration.Checksum != migration.Checksum {
+				status.State = models.MODIFIED_MIGRATION_STATE
+			}
+
+			delete(appliedByVersion, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, appliedMigration := range appliedByVersion {
+		statuses = append(statuses, models.MigrationStatus{
+			Version:   appliedMigration.Version,
+			Name:      appliedMigration.Name,
+			State:     models.UNKNOWN_MIGRATION_STATE,
+			AppliedAt: &appliedMigration.AppliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Returns when the lock was taken, which identifies it when releasing it
+func (migrationService MigrationService) lock() (time.Time, error) {
+	err := migrationService.migrationRepository.CreateMigrationTables()
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now().UTC()
+
+	return now, migrationService.migrationRepository.AcquireMigrationLock(now, now.Add(-migrationLockTimeout))
+}
+
+// Applied migrations by version, fails when one of them is not the migration shipped with this build
+func (migrationService MigrationService) getVerifiedAppliedMigrations(migrations []models.Migration) (map[int64]models.AppliedMigration, error) {
+	appliedMigrations, err := migrationService.migrationRepository.GetAppliedMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int64]models.Migration)
+
+	for _, migration := range migrations {
+		migrationsByVersion[migration.Version] = migration
+	}
+
+	appliedByVersion := make(map[int64]models.AppliedMigration)
+
+	for _, appliedMigration := range appliedMigrations {
+		migration, known := migrationsByVersion[appliedMigration.Version]
+
+		if !known {
+			return nil, fmt.Errorf("the database was migrated to %v, which this build does not know", migrationLabel(appliedMigration.Version, appliedMigration.Name))
+		}
+
+		if migration.Checksum != appliedMigration.Checksum {
+			return nil, fmt.Errorf("migration %v was modified after being applied", migrationLabel(migration.Version, migration.Name))
+		}
+
+		appliedByVersion[appliedMigration.Version] = appliedMigration
+	}
+
+	return appliedByVersion, nil
+}
+
+// Reads the up and down file of every migration, ordered by version
+func (migrationService MigrationService) loadMigrations() ([]models.Migration, error) {
+	entries, err := fs.ReadDir(migrationService.migrationFiles, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsByVersion := make(map[int64]*models.Migration)
+
+	for _, entry := range entries {
+		match := migrationFileNamePattern.FindStringSubmatch(entry.Name())
+
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %v, expected <version>_<name>.up.sql or <version>_<name>.down.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid version in migration file %v", entry.Name())
+		}
+
+		content, err := fs.ReadFile(migrationService.migrationFiles, entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := migrationsByVersion[version]
+
+		if !exists {
+			migration = &models.Migration{Version: version, Name: match[2]}
+			migrationsByVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration files of %v and %v share the same version", migrationLabel(version, migration.Name), entry.Name())
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var migrations []models.Migration
+
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %v needs both an up and a down file", migrationLabel(migration.Version, migration.Name))
+		}
+
+		checksum := sha256.Sum256([]byte(migration.Up))
+
+		migration.Checksum = hex.EncodeToString(checksum[:])
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Version and name the way the migration files are named
+func migrationLabel(version int64, name string) string {
+	return fmt.Sprintf("%04d_%v", version, name)
+}
+
+func NewMigrationService(migrationRepository repositoryInterfaces.IMigrationRepository, migrationFiles fs.FS) *MigrationService {
+	return &MigrationService{
+		migrationRepository: migrationRepository,
+		migrationFiles:      migrationFiles,
+	}
+}
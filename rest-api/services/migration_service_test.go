@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"example.com/constants"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// Recorded for a migration whose file changed since it was applied
+const outdatedChecksum = "outdated checksum"
+
+type MigrationServiceUnitTestSuite struct {
+	suite.Suite
+	migrationRepositoryMock mocks.IMigrationRepository
+	migrationFiles          fstest.MapFS
+	service                 *MigrationService
+}
+
+func TestMigrationServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &MigrationServiceUnitTestSuite{})
+}
+
+func (suite *MigrationServiceUnitTestSuite) SetupTest() {
+	suite.migrationRepositoryMock = mocks.IMigrationRepository{}
+
+	suite.migrationRepositoryMock.On("CreateMigrationTables").Return(nil)
+	suite.migrationRepositoryMock.On("AcquireMigrationLock", mock.Anything, mock.Anything).Return(nil)
+	suite.migrationRepositoryMock.On("ReleaseMigrationLock", mock.Anything).Return(nil)
+
+	suite.migrationFiles = fstest.MapFS{
+		"0001_initial_schema.up.sql":   {Data: []byte("CREATE TABLE Users (id INTEGER);")},
+		"0001_initial_schema.down.sql": {Data: []byte("DROP TABLE Users;")},
+		"0002_add_events.up.sql":       {Data: []byte("CREATE TABLE Events (id INTEGER);")},
+		"0002_add_events.down.sql":     {Data: []byte("DROP TABLE Events;")},
+	}
+
+	suite.service = NewMigrationService(&suite.migrationRepositoryMock, suite.migrationFiles)
+}
+
+// Applied record of a migration shipped with the build
+func (suite *MigrationServiceUnitTestSuite) appliedMigration(version int64) models.AppliedMigration {
+	migrations, err := suite.service.loadMigrations()
+
+	suite.Require().Nil(err)
+
+	migration := migrations[version-1]
+
+	return models.AppliedMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		Checksum:  migration.Checksum,
+		AppliedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUp_AppliesThePendingMigrationsInOrder() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{suite.appliedMigration(1)}, nil)
+	suite.migrationRepositoryMock.On("ApplyMigration", mock.Anything, mock.Anything).Return(nil)
+
+	migrations, err := suite.service.MigrateUp()
+
+	suite.Nil(err)
+	suite.Len(migrations, 1)
+	suite.Equal(int64(2), migrations[0].Version)
+	suite.Equal("add_events", migrations[0].Name)
+	suite.Equal("CREATE TABLE Events (id INTEGER);", migrations[0].Up)
+	suite.Equal("DROP TABLE Events;", migrations[0].Down)
+	suite.Len(migrations[0].Checksum, 64)
+	suite.migrationRepositoryMock.AssertNumberOfCalls(suite.T(), "ApplyMigration", 1)
+	suite.migrationRepositoryMock.AssertCalled(suite.T(), "ReleaseMigrationLock", mock.Anything)
+}
+
+// When another process is migrating, nothing is applied
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpLocked_ReturnsAnError() {
+
+	suite.migrationRepositoryMock.ExpectedCalls = nil
+	suite.migrationRepositoryMock.On("CreateMigrationTables").Return(nil)
+	suite.migrationRepositoryMock.On("AcquireMigrationLock", mock.Anything, mock.Anything).Return(errors.New(constants.MIGRATION_LOCKED_ERROR))
+
+	_, err := suite.service.MigrateUp()
+
+	suite.Equal(constants.MIGRATION_LOCKED_ERROR, err.Error())
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "ApplyMigration", mock.Anything, mock.Anything)
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "ReleaseMigrationLock", mock.Anything)
+}
+
+// Stale locks are those taken more than the lock timeout ago
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUp_TakesOverStaleLocks() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return(nil, nil)
+	suite.migrationRepositoryMock.On("ApplyMigration", mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.MigrateUp()
+
+	call := suite.migrationRepositoryMock.Calls[1]
+
+	suite.Equal("AcquireMigrationLock", call.Method)
+	suite.Equal(migrationLockTimeout, call.Arguments.Get(0).(time.Time).Sub(call.Arguments.Get(1).(time.Time)))
+}
+
+// Only the lock taken by this process is released, not one another process took over since
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUp_ReleasesTheLockItTook() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return(nil, nil)
+	suite.migrationRepositoryMock.On("ApplyMigration", mock.Anything, mock.Anything).Return(nil)
+
+	suite.service.MigrateUp()
+
+	lockedAt := suite.migrationRepositoryMock.Calls[1].Arguments.Get(0).(time.Time)
+
+	suite.migrationRepositoryMock.AssertCalled(suite.T(), "ReleaseMigrationLock", lockedAt)
+}
+
+// When an applied migration was edited, refuse to migrate
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpModifiedMigration_ReturnsAnError() {
+
+	applied := suite.appliedMigration(1)
+	applied.Checksum = outdatedChecksum
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{applied}, nil)
+
+	_, err := suite.service.MigrateUp()
+
+	suite.EqualError(err, "migration 0001_initial_schema was modified after being applied")
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "ApplyMigration", mock.Anything, mock.Anything)
+	suite.migrationRepositoryMock.AssertCalled(suite.T(), "ReleaseMigrationLock", mock.Anything)
+}
+
+// When the database was migrated by a newer build, refuse to migrate
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpUnknownMigration_ReturnsAnError() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{
+		suite.appliedMigration(1),
+		{Version: 3, Name: "future"},
+	}, nil)
+
+	_, err := suite.service.MigrateUp()
+
+	suite.EqualError(err, "the database was migrated to 0003_future, which this build does not know")
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "ApplyMigration", mock.Anything, mock.Anything)
+}
+
+// When a migration fails, the following ones are not applied
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpFailing_StopsAtTheFailingMigration() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return(nil, nil)
+	suite.migrationRepositoryMock.On("ApplyMigration", mock.MatchedBy(func(migration models.Migration) bool {
+		return migration.Version == 1
+	}), mock.Anything).Return(errors.New("test"))
+
+	migrations, err := suite.service.MigrateUp()
+
+	suite.EqualError(err, "unable to apply migration 0001_initial_schema: test")
+	suite.Empty(migrations)
+	suite.migrationRepositoryMock.AssertNumberOfCalls(suite.T(), "ApplyMigration", 1)
+}
+
+// Migration files need a version, a name and a direction
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpUnexpectedFile_ReturnsAnError() {
+
+	suite.migrationFiles["notes.txt"] = &fstest.MapFile{Data: []byte("notes")}
+
+	_, err := suite.service.MigrateUp()
+
+	suite.ErrorContains(err, "unexpected migration file notes.txt")
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "AcquireMigrationLock", mock.Anything, mock.Anything)
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpMissingDownFile_ReturnsAnError() {
+
+	delete(suite.migrationFiles, "0002_add_events.down.sql")
+
+	_, err := suite.service.MigrateUp()
+
+	suite.EqualError(err, "migration 0002_add_events needs both an up and a down file")
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestMigrateUpDuplicateVersion_ReturnsAnError() {
+
+	suite.migrationFiles["0002_add_comments.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE Comments (id INTEGER);")}
+
+	_, err := suite.service.MigrateUp()
+
+	suite.ErrorContains(err, "share the same version")
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestMigrateDown_RevertsTheLatestMigrations() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{
+		suite.appliedMigration(1),
+		suite.appliedMigration(2),
+	}, nil)
+	suite.migrationRepositoryMock.On("RevertMigration", mock.Anything).Return(nil)
+
+	migrations, err := suite.service.MigrateDown(1)
+
+	suite.Nil(err)
+	suite.Len(migrations, 1)
+	suite.Equal(int64(2), migrations[0].Version)
+	suite.migrationRepositoryMock.AssertNumberOfCalls(suite.T(), "RevertMigration", 1)
+	suite.migrationRepositoryMock.AssertCalled(suite.T(), "ReleaseMigrationLock", mock.Anything)
+}
+
+// Reverting more migrations than applied reverts every applied one, newest first
+func (suite *MigrationServiceUnitTestSuite) TestMigrateDownMoreStepsThanApplied_RevertsEveryMigration() {
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{
+		suite.appliedMigration(1),
+		suite.appliedMigration(2),
+	}, nil)
+	suite.migrationRepositoryMock.On("RevertMigration", mock.Anything).Return(nil)
+
+	migrations, err := suite.service.MigrateDown(5)
+
+	suite.Nil(err)
+	suite.Len(migrations, 2)
+	suite.Equal(int64(2), migrations[0].Version)
+	suite.Equal(int64(1), migrations[1].Version)
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestMigrateDownWithoutSteps_ReturnsAnError() {
+
+	_, err := suite.service.MigrateDown(0)
+
+	suite.NotNil(err)
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "AcquireMigrationLock", mock.Anything, mock.Anything)
+}
+
+func (suite *MigrationServiceUnitTestSuite) TestGetStatus_ReturnsTheStateOfEveryMigration() {
+
+	modified := suite.appliedMigration(2)
+	modified.Checksum = outdatedChecksum
+
+	suite.migrationRepositoryMock.On("GetAppliedMigrations").Return([]models.AppliedMigration{
+		modified,
+		{Version: 4, Name: "future", AppliedAt: modified.AppliedAt},
+	}, nil)
+
+	suite.migrationFiles["0003_add_comments.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE Comments (id INTEGER);")}
+	suite.migrationFiles["0003_add_comments.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE Comments;")}
+
+	statuses, err := suite.service.GetStatus()
+
+	suite.Nil(err)
+	suite.Equal([]models.MigrationStatus{
+		{Version: 1, Name: "initial_schema", State: models.PENDING_MIGRATION_STATE},
+		{Version: 2, Name: "add_events", State: models.MODIFIED_MIGRATION_STATE, AppliedAt: &modified.AppliedAt},
+		{Version: 3, Name: "add_comments", State: models.PENDING_MIGRATION_STATE},
+		{Version: 4, Name: "future", State: models.UNKNOWN_MIGRATION_STATE, AppliedAt: &modified.AppliedAt},
+	}, statuses)
+	suite.migrationRepositoryMock.AssertNotCalled(suite.T(), "AcquireMigrationLock", mock.Anything, mock.Anything)
+}
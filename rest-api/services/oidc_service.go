@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+	"golang.org/x/oauth2"
+)
+
+const oidcLoginStateLifetime = time.Minute * 10
+
+type OidcService struct {
+	oidcRepository         repositoryInterfaces.IOidcRepository
+	userRepository         repositoryInterfaces.IUserRepository
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository
+	oidcAuthenticator      libInterfaces.IOidcAuthenticator
+}
+
+// Stores the state, nonce and PKCE verifier of a new login, returning the url of the provider
+// the user has to be redirected to along with the state the browser has to present on its return
+func (oidcService OidcService) StartLogin(provider string) (*models.OidcLoginStart, error) {
+	state, stateHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := lib.GenerateTokenId()
+
+	if err != nil {
+		return nil, err
+	}
+
+	request := models.OidcAuthorizationRequest{
+		Provider:     provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+
+	authorizationUrl, err := oidcService.oidcAuthenticator.AuthorizationUrl(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	err = oidcService.oidcRepository.CreateOidcLoginState(&models.OidcLoginState{
+		Provider:     provider,
+		StateHash:    stateHash,
+		Nonce:        request.Nonce,
+		CodeVerifier: request.CodeVerifier,
+		ExpiresAt:    now.Add(oidcLoginStateLifetime),
+		CreatedAt:    now,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	//the login is stored at this point, failing to clean up only leaves abandoned logins behind
+	oidcService.oidcRepository.DeleteExpiredOidcLoginStates(now)
+
+	return &models.OidcLoginStart{
+		AuthorizationUrl: authorizationUrl,
+		State:            state,
+		ExpiresIn:        int64(oidcLoginStateLifetime.Seconds()),
+	}, nil
+}
+
+// Completes the login the provider redirected back from, returning the user linked to the account
+// of the provider. Accounts are linked to the user with the same email the first time, as long as
+// the provider verified it, and users are created for emails that are not registered yet
+func (oidcService OidcService) CompleteLogin(provider, state, code string) (*models.User, error) {
+	savedState, err := oidcService.oidcRepository.ConsumeOidcLoginState(lib.HashOpaqueToken(state))
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if !savedState.IsUsable(provider, now) {
+		return nil, errors.New(constants.INVALID_OIDC_STATE_ERROR)
+	}
+
+	identity, err := oidcService.oidcAuthenticator.Authenticate(models.OidcAuthorizationRequest{
+		Provider:     provider,
+		State:        state,
+		Nonce:        savedState.Nonce,
+		CodeVerifier: savedState.CodeVerifier,
+	}, code)
+
+	if err != nil {
+		return nil, errors.New(constants.OIDC_AUTHENTICATION_ERROR)
+	}
+
+	linkedIdentity, err := oidcService.oidcRepository.GetUserIdentity(provider, identity.Subject)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+
+	if linkedIdentity.Id != 0 {
+		user, err = oidcService.userRepository.GetUserById(linkedIdentity.UserId)
+	} else {
+		user, err = oidcService.linkUser(*identity, now)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDisabled() {
+		return nil, errors.New(constants.ACCOUNT_DISABLED_ERROR)
+	}
+
+	return user, nil
+}
+
+func (oidcService OidcService) linkUser(identity models.OidcIdentity, now time.Time) (*models.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errors.New(constants.OIDC_EMAIL_NOT_VERIFIED_ERROR)
+	}
+
+	user, err := oidcService.userRepository.GetUserByEmail(identity.Email)
+
+	if err != nil && err.Error() == constants.NO_USER_FOR_EMAIL_ERROR {
+		user, err = oidcService.createUser(identity.Email, now)
+	} else if err == nil && !user.IsVerified() {
+		err = oidcService.claimUnverifiedUser(user, now)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = oidcService.oidcRepository.CreateUserIdentity(&models.UserIdentity{
+		UserId:    user.Id,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: now,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Users signing in with a provider have no password, so none of them is stored in Users
+func (oidcService OidcService) createUser(email string, now time.Time) (*models.User, error) {
+	user := models.User{Email: email}
+
+	err := oidcService.userRepository.CreateUser(&user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = oidcService.userRepository.SetUserVerifiedAt(user.Id, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user.VerifiedAt = &now
+
+	return &user, nil
+}
+
+// Anyone can sign up with an email they do not own, so the password of an account that was never
+// verified is dropped along with its sessions before the owner of the email takes it over
+func (oidcService OidcService) claimUnverifiedUser(user *models.User, now time.Time) error {
+	err := oidcService.userRepository.UpdateUserPassword(user.Id, "")
+
+	if err != nil {
+		return err
+	}
+
+	err = oidcService.refreshTokenRepository.RevokeRefreshTokensByUserId(user.Id, now)
+
+	if err != nil {
+		return err
+	}
+
+	err = oidcService.userRepository.SetUserVerifiedAt(user.Id, now)
+
+	if err != nil {
+		return err
+	}
+
+	user.VerifiedAt = &now
+
+	return nil
+}
+
+func NewOidcService(
+	oidcRepository repositoryInterfaces.IOidcRepository,
+	userRepository repositoryInterfaces.IUserRepository,
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository,
+	oidcAuthenticator libInterfaces.IOidcAuthenticator) *OidcService {
+	return &OidcService{
+		oidcRepository:         oidcRepository,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		oidcAuthenticator:      oidcAuthenticator,
+	}
+}
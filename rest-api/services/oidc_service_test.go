@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OidcServiceUnitTestSuite struct {
+	suite.Suite
+	oidcRepositoryMock         mocks.IOidcRepository
+	userRepositoryMock         mocks.IUserRepository
+	refreshTokenRepositoryMock mocks.IRefreshTokenRepository
+	oidcAuthenticatorMock      mocks.IOidcAuthenticator
+	service                    *OidcService
+}
+
+func TestOidcServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OidcServiceUnitTestSuite{})
+}
+
+func (suite *OidcServiceUnitTestSuite) SetupTest() {
+	suite.oidcRepositoryMock = mocks.IOidcRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.refreshTokenRepositoryMock = mocks.IRefreshTokenRepository{}
+	suite.oidcAuthenticatorMock = mocks.IOidcAuthenticator{}
+
+	suite.service = NewOidcService(
+		&suite.oidcRepositoryMock,
+		&suite.userRepositoryMock,
+		&suite.refreshTokenRepositoryMock,
+		&suite.oidcAuthenticatorMock)
+}
+
+func (suite *OidcServiceUnitTestSuite) mockValidState() {
+	suite.oidcRepositoryMock.On("ConsumeOidcLoginState", mock.Anything).Return(&models.OidcLoginState{
+		Id:           1,
+		Provider:     "company",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}, nil)
+}
+
+func (suite *OidcServiceUnitTestSuite) mockIdentity(emailVerified bool) {
+	suite.oidcAuthenticatorMock.On("Authenticate", mock.Anything, mock.Anything).Return(&models.OidcIdentity{
+		Provider:      "company",
+		Subject:       "subject",
+		Email:         "test@test.com",
+		EmailVerified: emailVerified,
+	}, nil)
+}
+
+// The state handed to the provider is stored hashed, along with the nonce and verifier used
+func (suite *OidcServiceUnitTestSuite) TestStartLogin_StoresTheState() {
+
+	var request models.OidcAuthorizationRequest
+
+	suite.oidcAuthenticatorMock.On("AuthorizationUrl", mock.Anything).Run(func(args mock.Arguments) {
+		request = args.Get(0).(models.OidcAuthorizationRequest)
+	}).Return("https://provider/authorize", nil)
+	suite.oidcRepositoryMock.On("CreateOidcLoginState", mock.Anything).Return(nil)
+	suite.oidcRepositoryMock.On("DeleteExpiredOidcLoginStates", mock.Anything).Return(nil)
+
+	loginStart, err := suite.service.StartLogin("company")
+
+	suite.Nil(err)
+	suite.Equal("https://provider/authorize", loginStart.AuthorizationUrl)
+	suite.Equal(request.State, loginStart.State)
+	suite.NotEmpty(request.Nonce)
+	suite.NotEmpty(request.CodeVerifier)
+	suite.oidcRepositoryMock.AssertCalled(suite.T(), "CreateOidcLoginState", mock.MatchedBy(func(state *models.OidcLoginState) bool {
+		return state.Provider == "company" &&
+			state.StateHash == lib.HashOpaqueToken(request.State) &&
+			state.Nonce == request.Nonce &&
+			state.CodeVerifier == request.CodeVerifier
+	}))
+}
+
+// When the provider is not configured, nothing is stored
+func (suite *OidcServiceUnitTestSuite) TestStartLoginUnknownProvider_ReturnsAnError() {
+
+	suite.oidcAuthenticatorMock.On("AuthorizationUrl", mock.Anything).Return("", errors.New(constants.UNKNOWN_OIDC_PROVIDER_ERROR))
+
+	_, err := suite.service.StartLogin("unknown")
+
+	suite.Equal(constants.UNKNOWN_OIDC_PROVIDER_ERROR, err.Error())
+	suite.oidcRepositoryMock.AssertNotCalled(suite.T(), "CreateOidcLoginState", mock.Anything)
+}
+
+// When the state was started for another provider, the login is refused
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginStateOfAnotherProvider_ReturnsAnError() {
+
+	suite.mockValidState()
+
+	user, err := suite.service.CompleteLogin("other", "state", "code")
+
+	suite.Nil(user)
+	suite.Equal(constants.INVALID_OIDC_STATE_ERROR, err.Error())
+	suite.oidcAuthenticatorMock.AssertNotCalled(suite.T(), "Authenticate", mock.Anything, mock.Anything)
+}
+
+// The nonce and verifier stored with the state are used to validate the provider response
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginFailedAuthentication_ReturnsAnError() {
+
+	suite.mockValidState()
+	suite.oidcAuthenticatorMock.On("Authenticate", mock.Anything, mock.Anything).Return(nil, errors.New("nonce mismatch"))
+
+	_, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Equal(constants.OIDC_AUTHENTICATION_ERROR, err.Error())
+	suite.oidcRepositoryMock.AssertCalled(suite.T(), "ConsumeOidcLoginState", lib.HashOpaqueToken("state"))
+	suite.oidcAuthenticatorMock.AssertCalled(suite.T(), "Authenticate", models.OidcAuthorizationRequest{
+		Provider:     "company",
+		State:        "state",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+	}, "code")
+}
+
+// An account that is already linked logs into its user, whatever its email is now
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginLinkedIdentity_ReturnsTheLinkedUser() {
+
+	suite.mockValidState()
+	suite.mockIdentity(false)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{Id: 1, UserId: 12}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12}, nil)
+
+	user, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), user.Id)
+	suite.oidcRepositoryMock.AssertNotCalled(suite.T(), "CreateUserIdentity", mock.Anything)
+}
+
+// When the provider did not verify the email, it is not used to find the user
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginUnverifiedEmail_ReturnsAnError() {
+
+	suite.mockValidState()
+	suite.mockIdentity(false)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{}, nil)
+
+	_, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Equal(constants.OIDC_EMAIL_NOT_VERIFIED_ERROR, err.Error())
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "GetUserByEmail", mock.Anything)
+}
+
+// When no user has the email, one is created without a password
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginUnknownEmail_CreatesAUser() {
+
+	suite.mockValidState()
+	suite.mockIdentity(true)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{}, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR))
+	suite.userRepositoryMock.On("CreateUser", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).Id = 12
+	}).Return(nil)
+	suite.userRepositoryMock.On("SetUserVerifiedAt", mock.Anything, mock.Anything).Return(nil)
+	suite.oidcRepositoryMock.On("CreateUserIdentity", mock.Anything).Return(nil)
+
+	user, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Nil(err)
+	suite.True(user.IsVerified())
+	suite.userRepositoryMock.AssertCalled(suite.T(), "CreateUser", mock.MatchedBy(func(created *models.User) bool {
+		return created.Email == "test@test.com" && created.Password == ""
+	}))
+	suite.oidcRepositoryMock.AssertCalled(suite.T(), "CreateUserIdentity", mock.MatchedBy(func(identity *models.UserIdentity) bool {
+		return identity.UserId == 12 && identity.Provider == "company" && identity.Subject == "subject"
+	}))
+}
+
+// When a verified user has the email, the account is linked to it
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginVerifiedUser_LinksTheAccount() {
+
+	verifiedAt := time.Now()
+
+	suite.mockValidState()
+	suite.mockIdentity(true)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{}, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{Id: 12, VerifiedAt: &verifiedAt}, nil)
+	suite.oidcRepositoryMock.On("CreateUserIdentity", mock.Anything).Return(nil)
+
+	user, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), user.Id)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+// The password of an unverified user may have been chosen by someone else, so it is dropped
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginUnverifiedUser_DropsThePassword() {
+
+	suite.mockValidState()
+	suite.mockIdentity(true)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{}, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.userRepositoryMock.On("UpdateUserPassword", mock.Anything, mock.Anything).Return(nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+	suite.userRepositoryMock.On("SetUserVerifiedAt", mock.Anything, mock.Anything).Return(nil)
+	suite.oidcRepositoryMock.On("CreateUserIdentity", mock.Anything).Return(nil)
+
+	user, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Nil(err)
+	suite.True(user.IsVerified())
+	suite.userRepositoryMock.AssertCalled(suite.T(), "UpdateUserPassword", int64(12), "")
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+}
+
+func (suite *OidcServiceUnitTestSuite) TestCompleteLoginDisabledUser_ReturnsAnError() {
+
+	disabledAt := time.Now()
+
+	suite.mockValidState()
+	suite.mockIdentity(true)
+	suite.oidcRepositoryMock.On("GetUserIdentity", mock.Anything, mock.Anything).Return(&models.UserIdentity{Id: 1, UserId: 12}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, DisabledAt: &disabledAt}, nil)
+
+	user, err := suite.service.CompleteLogin("company", "state", "code")
+
+	suite.Nil(user)
+	suite.Equal(constants.ACCOUNT_DISABLED_ERROR, err.Error())
+}
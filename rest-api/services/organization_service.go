@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const organizationInvitationLifetime = time.Hour * 24 * 7
+
+type OrganizationService struct {
+	organizationRepository repositoryInterfaces.IOrganizationRepository
+	userRepository         repositoryInterfaces.IUserRepository
+}
+
+// Creates the organization, making its creator its first admin
+func (organizationService OrganizationService) CreateOrganization(name string, creatorId int64) (*models.Organization, error) {
+	organization := models.Organization{
+		Name:      strings.TrimSpace(name),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := organizationService.organizationRepository.CreateOrganization(&organization, creatorId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+func (organizationService OrganizationService) GetOrganizations(userId int64) ([]models.OrganizationMembership, error) {
+	return organizationService.organizationRepository.GetMembershipsByUserId(userId)
+}
+
+// Lists the members of the organization, to its members only
+func (organizationService OrganizationService) GetMembers(organizationId, userId int64) ([]models.OrganizationMember, error) {
+	_, err := organizationService.ensureMember(organizationId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return organizationService.organizationRepository.GetMembers(organizationId)
+}
+
+// Invites the owner of the email to join the organization, returning the invitation along with its
+// token. Only the hash of the token is stored, so the token cannot be retrieved again afterwards
+func (organizationService OrganizationService) InviteMember(
+	organizationId, adminId int64,
+	request models.OrganizationInvitationRequest) (*models.OrganizationInvitation, string, error) {
+	err := organizationService.ensureAdmin(organizationId, adminId)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, tokenHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := request.Role
+
+	if role == "" {
+		role = models.ORGANIZATION_MEMBER_ROLE
+	}
+
+	now := time.Now().UTC()
+
+	invitation := models.OrganizationInvitation{
+		OrganizationId: organizationId,
+		Email:          request.Email,
+		Role:           role,
+		TokenHash:      tokenHash,
+		InvitedBy:      adminId,
+		ExpiresAt:      now.Add(organizationInvitationLifetime),
+		CreatedAt:      now,
+	}
+
+	err = organizationService.organizationRepository.CreateInvitation(&invitation)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &invitation, token, nil
+}
+
+// Adds the user to the organization. Invitations can only be accepted once, by the user owning the
+// email they were sent to
+func (organizationService OrganizationService) AcceptInvitation(organizationId, userId int64, token string) error {
+	invitation, err := organizationService.organizationRepository.GetInvitationByTokenHash(organizationId, lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return err
+	}
+
+	if !invitation.IsUsable(time.Now()) {
+		return errors.New(constants.INVALID_ORGANIZATION_INVITATION_ERROR)
+	}
+
+	user, err := organizationService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return err
+	}
+
+	if user.Id == 0 || !strings.EqualFold(user.Email, invitation.Email) {
+		return errors.New(constants.INVALID_ORGANIZATION_INVITATION_ERROR)
+	}
+
+	accepted, err := organizationService.organizationRepository.AcceptInvitation(*invitation, userId, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	if !accepted {
+		return errors.New(constants.INVALID_ORGANIZATION_INVITATION_ERROR)
+	}
+
+	return nil
+}
+
+func (organizationService OrganizationService) UpdateMemberRole(organizationId, adminId, memberId int64, role string) error {
+	err := organizationService.ensureAdmin(organizationId, adminId)
+
+	if err != nil {
+		return err
+	}
+
+	member, err := organizationService.ensureRemovableMember(organizationId, memberId)
+
+	if err != nil {
+		return err
+	}
+
+	if member.IsAdmin() && role != models.ORGANIZATION_ADMIN_ROLE {
+		err = organizationService.ensureOtherAdmin(organizationId)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return organizationService.organizationRepository.UpdateMemberRole(organizationId, memberId, role)
+}
+
+// Admins remove members, members remove themselves to leave the organization
+func (organizationService OrganizationService) RemoveMember(organizationId, userId, memberId int64) error {
+	if userId != memberId {
+		err := organizationService.ensureAdmin(organizationId, userId)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	member, err := organizationService.ensureRemovableMember(organizationId, memberId)
+
+	if err != nil {
+		return err
+	}
+
+	if member.IsAdmin() {
+		err = organizationService.ensureOtherAdmin(organizationId)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return organizationService.organizationRepository.RemoveMember(organizationId, memberId)
+}
+
+// Organizations are reported as missing to users who are not members, so their existence is not
+// disclosed
+func (organizationService OrganizationService) ensureMember(organizationId, userId int64) (*models.OrganizationMembership, error) {
+	membership, err := organizationService.organizationRepository.GetMembership(organizationId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.OrganizationId == 0 {
+		return nil, errors.New(constants.NO_ORGANIZATION_FOR_ID_ERROR)
+	}
+
+	return membership, nil
+}
+
+func (organizationService OrganizationService) ensureAdmin(organizationId, userId int64) error {
+	membership, err := organizationService.ensureMember(organizationId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if !membership.IsAdmin() {
+		return errors.New(constants.NOT_ORGANIZATION_ADMIN_ERROR)
+	}
+
+	return nil
+}
+
+func (organizationService OrganizationService) ensureRemovableMember(organizationId, memberId int64) (*models.OrganizationMembership, error) {
+	member, err := organizationService.organizationRepository.GetMembership(organizationId, memberId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if member.OrganizationId == 0 {
+		return nil, errors.New(constants.NO_ORGANIZATION_MEMBER_FOR_ID_ERROR)
+	}
+
+	return member, nil
+}
+
+// Organizations always keep an admin, otherwise nobody could manage them anymore
+func (organizationService OrganizationService) ensureOtherAdmin(organizationId int64) error {
+	admins, err := organizationService.organizationRepository.CountAdmins(organizationId)
+
+	if err != nil {
+		return err
+	}
+
+	if admins <= 1 {
+		return errors.New(constants.LAST_ORGANIZATION_ADMIN_ERROR)
+	}
+
+	return nil
+}
+
+func NewOrganizationService(
+	organizationRepository repositoryInterfaces.IOrganizationRepository,
+	userRepository repositoryInterfaces.IUserRepository) *OrganizationService {
+	return &OrganizationService{
+		organizationRepository: organizationRepository,
+		userRepository:         userRepository,
+	}
+}
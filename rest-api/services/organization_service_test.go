@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrganizationServiceUnitTestSuite struct {
+	suite.Suite
+	organizationRepositoryMock mocks.IOrganizationRepository
+	userRepositoryMock         mocks.IUserRepository
+	service                    *OrganizationService
+}
+
+func TestOrganizationServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &OrganizationServiceUnitTestSuite{})
+}
+
+func (suite *OrganizationServiceUnitTestSuite) SetupTest() {
+	suite.organizationRepositoryMock = mocks.IOrganizationRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+
+	suite.service = NewOrganizationService(
+		&suite.organizationRepositoryMock,
+		&suite.userRepositoryMock)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) expectMembership(organizationId, userId int64, role string) {
+	suite.organizationRepositoryMock.On("GetMembership", organizationId, userId).Return(&models.OrganizationMembership{
+		OrganizationId: organizationId,
+		Role:           role,
+	}, nil)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) expectNoMembership(organizationId, userId int64) {
+	suite.organizationRepositoryMock.On("GetMembership", organizationId, userId).Return(&models.OrganizationMembership{}, nil)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestCreateOrganization_CreatesTheOrganizationForItsCreator() {
+
+	suite.organizationRepositoryMock.On("CreateOrganization", mock.Anything, mock.Anything).Return(nil)
+
+	organization, err := suite.service.CreateOrganization("  Acme ", 12)
+
+	suite.Nil(err)
+	suite.Equal("Acme", organization.Name)
+	suite.organizationRepositoryMock.AssertCalled(suite.T(), "CreateOrganization", organization, int64(12))
+}
+
+// When an error occurs during db access, return the error
+func (suite *OrganizationServiceUnitTestSuite) TestCreateOrganization_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.organizationRepositoryMock.On("CreateOrganization", mock.Anything, mock.Anything).Return(expectedError)
+
+	_, err := suite.service.CreateOrganization("Acme", 12)
+
+	suite.Equal(expectedError, err)
+}
+
+// Organizations are reported as missing to users outside of them
+func (suite *OrganizationServiceUnitTestSuite) TestGetMembersNotAMember_ReturnsAnError() {
+
+	suite.expectNoMembership(4, 12)
+
+	_, err := suite.service.GetMembers(4, 12)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_ORGANIZATION_FOR_ID_ERROR, err.Error())
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "GetMembers", mock.Anything)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestGetMembers_ReturnsTheMembers() {
+
+	expectedMembers := []models.OrganizationMember{{UserId: 12}, {UserId: 13}}
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_MEMBER_ROLE)
+	suite.organizationRepositoryMock.On("GetMembers", int64(4)).Return(expectedMembers, nil)
+
+	members, err := suite.service.GetMembers(4, 12)
+
+	suite.Nil(err)
+	suite.Equal(expectedMembers, members)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestInviteMemberNotAnAdmin_ReturnsAnError() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_MEMBER_ROLE)
+
+	_, _, err := suite.service.InviteMember(4, 12, models.OrganizationInvitationRequest{Email: "invitee@test.com"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_ORGANIZATION_ADMIN_ERROR, err.Error())
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "CreateInvitation", mock.Anything)
+}
+
+// Only the hash of the returned token is stored, invitees join as members unless told otherwise
+func (suite *OrganizationServiceUnitTestSuite) TestInviteMember_StoresTheHashOfTheToken() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.organizationRepositoryMock.On("CreateInvitation", mock.Anything).Return(nil)
+
+	invitation, token, err := suite.service.InviteMember(4, 12, models.OrganizationInvitationRequest{Email: "invitee@test.com"})
+
+	suite.Nil(err)
+	suite.NotEmpty(token)
+	suite.Equal(lib.HashOpaqueToken(token), invitation.TokenHash)
+	suite.Equal(models.ORGANIZATION_MEMBER_ROLE, invitation.Role)
+	suite.Equal(int64(12), invitation.InvitedBy)
+	suite.WithinDuration(time.Now().Add(organizationInvitationLifetime), invitation.ExpiresAt, time.Minute)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestAcceptInvitation_AddsTheMember() {
+
+	invitation := models.OrganizationInvitation{
+		Id:             7,
+		OrganizationId: 4,
+		Email:          "Invitee@test.com",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+
+	suite.organizationRepositoryMock.On("GetInvitationByTokenHash", int64(4), lib.HashOpaqueToken("token")).Return(&invitation, nil)
+	suite.userRepositoryMock.On("GetUserById", int64(13)).Return(&models.User{Id: 13, Email: "invitee@test.com"}, nil)
+	suite.organizationRepositoryMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	err := suite.service.AcceptInvitation(4, 13, "token")
+
+	suite.Nil(err)
+	suite.organizationRepositoryMock.AssertCalled(suite.T(), "AcceptInvitation", invitation, int64(13), mock.Anything)
+}
+
+// Invitations can only be accepted by the owner of the email they were sent to
+func (suite *OrganizationServiceUnitTestSuite) TestAcceptInvitationForAnotherEmail_ReturnsAnError() {
+
+	suite.organizationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.OrganizationInvitation{
+		Id:             7,
+		OrganizationId: 4,
+		Email:          "invitee@test.com",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", int64(13)).Return(&models.User{Id: 13, Email: "someone@test.com"}, nil)
+
+	err := suite.service.AcceptInvitation(4, 13, "token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_ORGANIZATION_INVITATION_ERROR, err.Error())
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "AcceptInvitation", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestAcceptExpiredInvitation_ReturnsAnError() {
+
+	suite.organizationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.OrganizationInvitation{
+		Id:        7,
+		Email:     "invitee@test.com",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}, nil)
+
+	err := suite.service.AcceptInvitation(4, 13, "token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_ORGANIZATION_INVITATION_ERROR, err.Error())
+}
+
+// When the invitation was accepted in the meantime, return an error
+func (suite *OrganizationServiceUnitTestSuite) TestAcceptInvitationAlreadyAccepted_ReturnsAnError() {
+
+	suite.organizationRepositoryMock.On("GetInvitationByTokenHash", mock.Anything, mock.Anything).Return(&models.OrganizationInvitation{
+		Id:        7,
+		Email:     "invitee@test.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", int64(13)).Return(&models.User{Id: 13, Email: "invitee@test.com"}, nil)
+	suite.organizationRepositoryMock.On("AcceptInvitation", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := suite.service.AcceptInvitation(4, 13, "token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_ORGANIZATION_INVITATION_ERROR, err.Error())
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestUpdateMemberRole_UpdatesTheRole() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.expectMembership(4, 13, models.ORGANIZATION_MEMBER_ROLE)
+	suite.organizationRepositoryMock.On("UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.UpdateMemberRole(4, 12, 13, models.ORGANIZATION_ADMIN_ROLE)
+
+	suite.Nil(err)
+	suite.organizationRepositoryMock.AssertCalled(suite.T(), "UpdateMemberRole", int64(4), int64(13), models.ORGANIZATION_ADMIN_ROLE)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestUpdateMemberRoleOfANonMember_ReturnsAnError() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.expectNoMembership(4, 13)
+
+	err := suite.service.UpdateMemberRole(4, 12, 13, models.ORGANIZATION_ADMIN_ROLE)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_ORGANIZATION_MEMBER_FOR_ID_ERROR, err.Error())
+}
+
+// Demoting the only admin would leave the organization without anyone to manage it
+func (suite *OrganizationServiceUnitTestSuite) TestUpdateMemberRoleOfTheLastAdmin_ReturnsAnError() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.organizationRepositoryMock.On("CountAdmins", int64(4)).Return(int64(1), nil)
+
+	err := suite.service.UpdateMemberRole(4, 12, 12, models.ORGANIZATION_MEMBER_ROLE)
+
+	suite.NotNil(err)
+	suite.Equal(constants.LAST_ORGANIZATION_ADMIN_ERROR, err.Error())
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "UpdateMemberRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestRemoveMemberNotAnAdmin_ReturnsAnError() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_MEMBER_ROLE)
+
+	err := suite.service.RemoveMember(4, 12, 13)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_ORGANIZATION_ADMIN_ERROR, err.Error())
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "RemoveMember", mock.Anything, mock.Anything)
+}
+
+// Members can leave the organization without being an admin
+func (suite *OrganizationServiceUnitTestSuite) TestRemoveMemberThemselves_RemovesTheMember() {
+
+	suite.expectMembership(4, 13, models.ORGANIZATION_MEMBER_ROLE)
+	suite.organizationRepositoryMock.On("RemoveMember", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.RemoveMember(4, 13, 13)
+
+	suite.Nil(err)
+	suite.organizationRepositoryMock.AssertCalled(suite.T(), "RemoveMember", int64(4), int64(13))
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestRemoveMemberAdminWithOtherAdmins_RemovesTheAdmin() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.expectMembership(4, 13, models.ORGANIZATION_ADMIN_ROLE)
+	suite.organizationRepositoryMock.On("CountAdmins", int64(4)).Return(int64(2), nil)
+	suite.organizationRepositoryMock.On("RemoveMember", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.RemoveMember(4, 12, 13)
+
+	suite.Nil(err)
+	suite.organizationRepositoryMock.AssertCalled(suite.T(), "RemoveMember", int64(4), int64(13))
+}
+
+func (suite *OrganizationServiceUnitTestSuite) TestRemoveMemberLastAdmin_ReturnsAnError() {
+
+	suite.expectMembership(4, 12, models.ORGANIZATION_ADMIN_ROLE)
+	suite.organizationRepositoryMock.On("CountAdmins", int64(4)).Return(int64(1), nil)
+
+	err := suite.service.RemoveMember(4, 12, 12)
+
+	suite.NotNil(err)
+	suite.Equal(constants.LAST_ORGANIZATION_ADMIN_ERROR, err.Error())
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const passwordResetTokenLifetime = time.Hour
+
+type PasswordResetService struct {
+	userRepository          repositoryInterfaces.IUserRepository
+	passwordResetRepository repositoryInterfaces.IPasswordResetRepository
+	refreshTokenRepository  repositoryInterfaces.IRefreshTokenRepository
+	passwordHasher          libInterfaces.IHasher
+	mailSender              libInterfaces.IMailSender
+}
+
+// Emails a reset token to the user. Unknown emails and disabled accounts are silently ignored,
+// so the outcome cannot be used to find out which emails are registered
+func (passwordResetService PasswordResetService) RequestPasswordReset(email string) error {
+	user, err := passwordResetService.userRepository.GetUserByEmail(email)
+
+	if err != nil && err.Error() == constants.NO_USER_FOR_EMAIL_ERROR {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if user.IsDisabled() {
+		return nil
+	}
+
+	token, tokenHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	err = passwordResetService.passwordResetRepository.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserId:    user.Id,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(passwordResetTokenLifetime),
+		CreatedAt: now,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return passwordResetService.mailSender.Send(models.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Use the following token to choose a new password, it expires in %v and can only be used once:\n\n%v\n\n"+
+				"If you did not ask to reset your password, you can ignore this email.",
+			passwordResetTokenLifetime,
+			token),
+	})
+}
+
+// Consumes the token and replaces the password, returning the id of the user. Every refresh token
+// of the user is revoked, so sessions opened with the old password cannot be renewed
+func (passwordResetService PasswordResetService) ResetPassword(token, password string) (int64, error) {
+	savedToken, err := passwordResetService.passwordResetRepository.GetPasswordResetTokenByHash(lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+
+	if !savedToken.IsUsable(now) {
+		return 0, errors.New(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR)
+	}
+
+	//hashed before the token is consumed, a failure leaves the token usable
+	hashedPassword, err := passwordResetService.passwordHasher.HashPassword(password)
+
+	if err != nil {
+		return 0, err
+	}
+
+	consumed, err := passwordResetService.passwordResetRepository.ResetPassword(savedToken.Id, savedToken.UserId, hashedPassword, now)
+
+	if err != nil {
+		return 0, err
+	}
+
+	//another request used the token in the meantime
+	if !consumed {
+		return 0, errors.New(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR)
+	}
+
+	err = passwordResetService.refreshTokenRepository.RevokeRefreshTokensByUserId(savedToken.UserId, now)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return savedToken.UserId, nil
+}
+
+func NewPasswordResetService(
+	userRepository repositoryInterfaces.IUserRepository,
+	passwordResetRepository repositoryInterfaces.IPasswordResetRepository,
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository,
+	passwordHasher libInterfaces.IHasher,
+	mailSender libInterfaces.IMailSender) *PasswordResetService {
+	return &PasswordResetService{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		refreshTokenRepository:  refreshTokenRepository,
+		passwordHasher:          passwordHasher,
+		mailSender:              mailSender,
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordResetServiceUnitTestSuite struct {
+	suite.Suite
+	userRepositoryMock          mocks.IUserRepository
+	passwordResetRepositoryMock mocks.IPasswordResetRepository
+	refreshTokenRepositoryMock  mocks.IRefreshTokenRepository
+	passwordHasherMock          mocks.IHasher
+	mailSenderMock              mocks.IMailSender
+	service                     *PasswordResetService
+}
+
+func TestPasswordResetServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PasswordResetServiceUnitTestSuite{})
+}
+
+func (suite *PasswordResetServiceUnitTestSuite) SetupTest() {
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.passwordResetRepositoryMock = mocks.IPasswordResetRepository{}
+	suite.refreshTokenRepositoryMock = mocks.IRefreshTokenRepository{}
+	suite.passwordHasherMock = mocks.IHasher{}
+	suite.mailSenderMock = mocks.IMailSender{}
+
+	suite.service = NewPasswordResetService(
+		&suite.userRepositoryMock,
+		&suite.passwordResetRepositoryMock,
+		&suite.refreshTokenRepositoryMock,
+		&suite.passwordHasherMock,
+		&suite.mailSenderMock)
+}
+
+// When no user is registered with the email, nothing is sent and no error is reported
+func (suite *PasswordResetServiceUnitTestSuite) TestRequestPasswordResetUnknownEmail_ReturnsNil() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR))
+
+	err := suite.service.RequestPasswordReset("unknown@test.com")
+
+	suite.Nil(err)
+	suite.passwordResetRepositoryMock.AssertNotCalled(suite.T(), "CreatePasswordResetToken", mock.Anything)
+	suite.mailSenderMock.AssertNotCalled(suite.T(), "Send", mock.Anything)
+}
+
+// When failing to fetch the user, pass up the error
+func (suite *PasswordResetServiceUnitTestSuite) TestRequestPasswordReset_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(nil, expectedError)
+
+	err := suite.service.RequestPasswordReset("test@test.com")
+
+	suite.Equal(expectedError, err)
+}
+
+// The emailed token is never stored, only its hash is
+func (suite *PasswordResetServiceUnitTestSuite) TestRequestPasswordReset_EmailsTheToken() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.passwordResetRepositoryMock.On("CreatePasswordResetToken", mock.Anything).Return(nil)
+	suite.mailSenderMock.On("Send", mock.Anything).Return(nil)
+
+	err := suite.service.RequestPasswordReset("test@test.com")
+
+	suite.Nil(err)
+
+	savedToken := suite.passwordResetRepositoryMock.Calls[0].Arguments.Get(0).(*models.PasswordResetToken)
+	message := suite.mailSenderMock.Calls[0].Arguments.Get(0).(models.MailMessage)
+
+	suite.Equal(int64(12), savedToken.UserId)
+	suite.True(savedToken.ExpiresAt.After(time.Now()))
+	suite.Equal("test@test.com", message.To)
+	suite.NotContains(message.Body, savedToken.TokenHash)
+}
+
+func (suite *PasswordResetServiceUnitTestSuite) TestResetPasswordUnknownToken_ReturnsAnError() {
+
+	suite.passwordResetRepositoryMock.On("GetPasswordResetTokenByHash", mock.Anything).Return(&models.PasswordResetToken{}, nil)
+
+	_, err := suite.service.ResetPassword("token", "new password")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR, err.Error())
+	suite.passwordResetRepositoryMock.AssertCalled(suite.T(), "GetPasswordResetTokenByHash", lib.HashOpaqueToken("token"))
+}
+
+func (suite *PasswordResetServiceUnitTestSuite) TestResetPasswordExpiredToken_ReturnsAnError() {
+
+	suite.passwordResetRepositoryMock.On("GetPasswordResetTokenByHash", mock.Anything).Return(&models.PasswordResetToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := suite.service.ResetPassword("token", "new password")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR, err.Error())
+	suite.passwordResetRepositoryMock.AssertNotCalled(suite.T(), "ResetPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When another request used the token first, the password is left untouched
+func (suite *PasswordResetServiceUnitTestSuite) TestResetPasswordConcurrentUse_ReturnsAnError() {
+
+	suite.passwordResetRepositoryMock.On("GetPasswordResetTokenByHash", mock.Anything).Return(&models.PasswordResetToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("hashed password", nil)
+	suite.passwordResetRepositoryMock.On("ResetPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := suite.service.ResetPassword("token", "new password")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_PASSWORD_RESET_TOKEN_ERROR, err.Error())
+	suite.refreshTokenRepositoryMock.AssertNotCalled(suite.T(), "RevokeRefreshTokensByUserId", mock.Anything, mock.Anything)
+}
+
+func (suite *PasswordResetServiceUnitTestSuite) TestResetPassword_UpdatesThePasswordAndRevokesTheSessions() {
+
+	suite.passwordResetRepositoryMock.On("GetPasswordResetTokenByHash", mock.Anything).Return(&models.PasswordResetToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("hashed password", nil)
+	suite.passwordResetRepositoryMock.On("ResetPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+
+	userId, err := suite.service.ResetPassword("token", "new password")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), userId)
+	suite.passwordHasherMock.AssertCalled(suite.T(), "HashPassword", "new password")
+	suite.passwordResetRepositoryMock.AssertCalled(suite.T(), "ResetPassword", int64(3), int64(12), "hashed password", mock.Anything)
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+}
+
+// When hashing the new password fails, the token is not consumed and can be used again
+func (suite *PasswordResetServiceUnitTestSuite) TestResetPasswordHashingFailure_KeepsTheToken() {
+
+	suite.passwordResetRepositoryMock.On("GetPasswordResetTokenByHash", mock.Anything).Return(&models.PasswordResetToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("", errors.New("test"))
+
+	_, err := suite.service.ResetPassword("token", "new password")
+
+	suite.NotNil(err)
+	suite.passwordResetRepositoryMock.AssertNotCalled(suite.T(), "ResetPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
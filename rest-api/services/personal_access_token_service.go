@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"example.com/constants"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const defaultPersonalAccessTokenLifetimeDays = 90
+
+// Last used timestamps are only refreshed once per interval, to not write on every request
+const personalAccessTokenUsageInterval = time.Minute
+
+type PersonalAccessTokenService struct {
+	personalAccessTokenRepository repositoryInterfaces.IPersonalAccessTokenRepository
+	userRepository                repositoryInterfaces.IUserRepository
+}
+
+// Creates a token for the user, the token itself is only returned here
+func (personalAccessTokenService PersonalAccessTokenService) CreatePersonalAccessToken(userId int64, request models.CreatePersonalAccessTokenRequest) (*models.CreatedPersonalAccessToken, error) {
+	for _, scope := range request.Scopes {
+		if !models.IsValidScope(scope) {
+			return nil, errors.New(constants.INVALID_SCOPE_ERROR)
+		}
+	}
+
+	lifetimeDays := request.ExpiresInDays
+
+	if lifetimeDays == 0 {
+		lifetimeDays = defaultPersonalAccessTokenLifetimeDays
+	}
+
+	token, tokenHash, err := lib.GeneratePersonalAccessToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	personalAccessToken := models.PersonalAccessToken{
+		UserId:    userId,
+		Name:      request.Name,
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(request.Scopes))),
+		TokenHash: tokenHash,
+		ExpiresAt: now.AddDate(0, 0, lifetimeDays),
+		CreatedAt: now,
+	}
+
+	err = personalAccessTokenService.personalAccessTokenRepository.CreatePersonalAccessToken(&personalAccessToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedPersonalAccessToken{
+		PersonalAccessToken: personalAccessToken,
+		Token:               token,
+	}, nil
+}
+
+func (personalAccessTokenService PersonalAccessTokenService) GetPersonalAccessTokens(userId int64) ([]models.PersonalAccessToken, error) {
+	return personalAccessTokenService.personalAccessTokenRepository.GetPersonalAccessTokensByUserId(userId)
+}
+
+func (personalAccessTokenService PersonalAccessTokenService) RevokePersonalAccessToken(id, userId int64) error {
+	revoked, err := personalAccessTokenService.personalAccessTokenRepository.RevokePersonalAccessToken(id, userId, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	//tokens of other users are reported as missing, to not confirm they exist
+	if !revoked {
+		return errors.New(constants.NO_PERSONAL_ACCESS_TOKEN_FOR_ID_ERROR)
+	}
+
+	return nil
+}
+
+// Validates the token and returns claims limited to its scopes. Role permissions are not granted
+// to personal access tokens, so a leaked token cannot be used to administrate the application
+func (personalAccessTokenService PersonalAccessTokenService) ValidatePersonalAccessToken(token string) (*models.AccessTokenClaims, error) {
+	savedToken, err := personalAccessTokenService.personalAccessTokenRepository.GetPersonalAccessTokenByHash(lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if !savedToken.IsUsable(now) {
+		return nil, errors.New(constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+	}
+
+	user, err := personalAccessTokenService.userRepository.GetUserById(savedToken.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 || user.IsDisabled() {
+		return nil, errors.New(constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+	}
+
+	//the token is valid at this point, failing to record its usage should not refuse the request
+	personalAccessTokenService.personalAccessTokenRepository.MarkPersonalAccessTokenUsed(
+		savedToken.Id,
+		now,
+		now.Add(-personalAccessTokenUsageInterval))
+
+	return &models.AccessTokenClaims{
+		UserId:      user.Id,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: []string{},
+		ExpiresAt:   savedToken.ExpiresAt,
+		Scopes:      savedToken.Scopes,
+	}, nil
+}
+
+func NewPersonalAccessTokenService(
+	personalAccessTokenRepository repositoryInterfaces.IPersonalAccessTokenRepository,
+	userRepository repositoryInterfaces.IUserRepository) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		userRepository:                userRepository,
+	}
+}
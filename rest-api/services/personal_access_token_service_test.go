@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersonalAccessTokenServiceUnitTestSuite struct {
+	suite.Suite
+	personalAccessTokenRepositoryMock mocks.IPersonalAccessTokenRepository
+	userRepositoryMock                mocks.IUserRepository
+	service                           *PersonalAccessTokenService
+}
+
+func TestPersonalAccessTokenServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &PersonalAccessTokenServiceUnitTestSuite{})
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) SetupTest() {
+	suite.personalAccessTokenRepositoryMock = mocks.IPersonalAccessTokenRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+
+	suite.service = NewPersonalAccessTokenService(&suite.personalAccessTokenRepositoryMock, &suite.userRepositoryMock)
+}
+
+// Only the hash of the token is stored, the token itself is returned once
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestCreatePersonalAccessToken_StoresTheTokenHash() {
+
+	suite.personalAccessTokenRepositoryMock.On("CreatePersonalAccessToken", mock.Anything).Return(nil)
+
+	token, err := suite.service.CreatePersonalAccessToken(12, models.CreatePersonalAccessTokenRequest{
+		Name:   "deploy script",
+		Scopes: []string{models.EVENTS_WRITE_SCOPE, models.EVENTS_READ_SCOPE, models.EVENTS_READ_SCOPE},
+	})
+
+	suite.Nil(err)
+	suite.True(strings.HasPrefix(token.Token, "pat_"))
+
+	savedToken := suite.personalAccessTokenRepositoryMock.Calls[0].Arguments.Get(0).(*models.PersonalAccessToken)
+
+	suite.Equal(int64(12), savedToken.UserId)
+	suite.Equal("deploy script", savedToken.Name)
+	suite.Equal([]string{models.EVENTS_READ_SCOPE, models.EVENTS_WRITE_SCOPE}, savedToken.Scopes)
+	suite.Equal(lib.HashOpaqueToken(token.Token), savedToken.TokenHash)
+	suite.WithinDuration(time.Now().AddDate(0, 0, 90), savedToken.ExpiresAt, time.Minute)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestCreatePersonalAccessTokenWithExpiry_UsesTheExpiry() {
+
+	suite.personalAccessTokenRepositoryMock.On("CreatePersonalAccessToken", mock.Anything).Return(nil)
+
+	token, err := suite.service.CreatePersonalAccessToken(12, models.CreatePersonalAccessTokenRequest{
+		Name:          "deploy script",
+		Scopes:        []string{models.EVENTS_READ_SCOPE},
+		ExpiresInDays: 7,
+	})
+
+	suite.Nil(err)
+	suite.WithinDuration(time.Now().AddDate(0, 0, 7), token.ExpiresAt, time.Minute)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestCreatePersonalAccessTokenWithUnknownScope_ReturnsError() {
+
+	_, err := suite.service.CreatePersonalAccessToken(12, models.CreatePersonalAccessTokenRequest{
+		Name:   "deploy script",
+		Scopes: []string{models.EVENTS_READ_SCOPE, "users:write"},
+	})
+
+	suite.EqualError(err, constants.INVALID_SCOPE_ERROR)
+	suite.personalAccessTokenRepositoryMock.AssertNotCalled(suite.T(), "CreatePersonalAccessToken", mock.Anything)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestRevokePersonalAccessToken_RevokesTheTokenOfTheUser() {
+
+	suite.personalAccessTokenRepositoryMock.On("RevokePersonalAccessToken", int64(3), int64(12), mock.Anything).Return(true, nil)
+
+	err := suite.service.RevokePersonalAccessToken(3, 12)
+
+	suite.Nil(err)
+}
+
+// When the token does not exist or belongs to another user, return an error
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestRevokeMissingPersonalAccessToken_ReturnsError() {
+
+	suite.personalAccessTokenRepositoryMock.On("RevokePersonalAccessToken", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := suite.service.RevokePersonalAccessToken(3, 12)
+
+	suite.EqualError(err, constants.NO_PERSONAL_ACCESS_TOKEN_FOR_ID_ERROR)
+}
+
+// The claims are limited to the scopes of the token, without the permissions of the role
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidatePersonalAccessToken_ReturnsScopedClaims() {
+
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", lib.HashOpaqueToken("pat_token")).Return(&models.PersonalAccessToken{
+		Id:        3,
+		UserId:    12,
+		Scopes:    []string{models.EVENTS_READ_SCOPE},
+		ExpiresAt: expiresAt,
+	}, nil)
+	suite.personalAccessTokenRepositoryMock.On("MarkPersonalAccessTokenUsed", int64(3), mock.Anything, mock.Anything).Return(nil)
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Email: "test@test.com", Role: models.ADMIN_ROLE}, nil)
+
+	claims, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), claims.UserId)
+	suite.Equal([]string{models.EVENTS_READ_SCOPE}, claims.Scopes)
+	suite.Empty(claims.Permissions)
+	suite.Equal(expiresAt, claims.ExpiresAt)
+	suite.personalAccessTokenRepositoryMock.AssertCalled(suite.T(), "MarkPersonalAccessTokenUsed", int64(3), mock.Anything, mock.Anything)
+}
+
+// Failing to record the usage does not refuse the request
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidatePersonalAccessTokenFailingToMarkUsed_ReturnsClaims() {
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", mock.Anything).Return(&models.PersonalAccessToken{
+		Id:        3,
+		UserId:    12,
+		Scopes:    []string{models.EVENTS_READ_SCOPE},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.personalAccessTokenRepositoryMock.On("MarkPersonalAccessTokenUsed", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12}, nil)
+
+	claims, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), claims.UserId)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidateUnknownPersonalAccessToken_ReturnsError() {
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", mock.Anything).Return(&models.PersonalAccessToken{}, nil)
+
+	_, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.EqualError(err, constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidateExpiredPersonalAccessToken_ReturnsError() {
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", mock.Anything).Return(&models.PersonalAccessToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.EqualError(err, constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "GetUserById", mock.Anything)
+}
+
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidateRevokedPersonalAccessToken_ReturnsError() {
+
+	revokedAt := time.Now().Add(-time.Minute)
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", mock.Anything).Return(&models.PersonalAccessToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	_, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.EqualError(err, constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+}
+
+// Tokens of disabled users stop working along with their sessions
+func (suite *PersonalAccessTokenServiceUnitTestSuite) TestValidatePersonalAccessTokenOfDisabledUser_ReturnsError() {
+
+	disabledAt := time.Now().Add(-time.Minute)
+
+	suite.personalAccessTokenRepositoryMock.On("GetPersonalAccessTokenByHash", mock.Anything).Return(&models.PersonalAccessToken{
+		Id:        3,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, DisabledAt: &disabledAt}, nil)
+
+	_, err := suite.service.ValidatePersonalAccessToken("pat_token")
+
+	suite.EqualError(err, constants.INVALID_PERSONAL_ACCESS_TOKEN_ERROR)
+	suite.personalAccessTokenRepositoryMock.AssertNotCalled(suite.T(), "MarkPersonalAccessTokenUsed", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -4,35 +4,67 @@ import (
 	"errors"
 
 	"example.com/constants"
-	interfaces "example.com/interfaces/repositories"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/models"
 )
 
 type RegistrationService struct {
-	registrationRepository interfaces.IRegistrationRepository
-	eventRepository        interfaces.IEventRepository
+	registrationRepository repositoryInterfaces.IRegistrationRepository
+	eventRepository        repositoryInterfaces.IEventRepository
+	invitationRepository   repositoryInterfaces.IInvitationRepository
+	eventBroadcaster       libInterfaces.IEventBroadcaster
 }
 
-func (registrationService RegistrationService) CreateRegistration(eventId, userId int64) error {
-	event, err := registrationService.eventRepository.GetEventById(eventId)
+// Returns the events overlapping with the new registration along with an error, unless conflicts are
+// allowed. Only events of the tenant can be registered for, while registrations of every tenant are
+// checked for conflicts
+func (registrationService RegistrationService) CreateRegistration(eventId, userId, organizationId int64, allowConflict bool) ([]models.Event, error) {
+	event, err := registrationService.eventRepository.GetEventById(eventId, organizationId)
 
 	if err != nil {
-		return err
+		return nil, err
 	} else if event.Id == 0 {
-		return errors.New(constants.NO_EVENT_FOR_ID_ERROR)
+		return nil, errors.New(constants.NO_EVENT_FOR_ID_ERROR)
+	}
+
+	if event.Visibility == models.PRIVATE_VISIBILITY && event.UserId != userId {
+		invited, err := registrationService.invitationRepository.IsInvited(eventId, userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !invited {
+			return nil, errors.New(constants.NOT_INVITED_ERROR)
+		}
 	}
 
-	err = registrationService.registrationRepository.CreateRegistration(eventId, userId)
+	if !allowConflict {
+		conflicts, err := registrationService.getOverlappingEvents(*event, userId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(conflicts) > 0 {
+			return conflicts, errors.New(constants.SCHEDULE_CONFLICT_ERROR)
+		}
+	}
+
+	err = registrationService.registrationRepository.CreateRegistration(eventId, userId, organizationId)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
-}
+	registrationService.publishRegistrationCount(event)
 
-func (registrationService RegistrationService) DeleteRegistration(eventId, userId int64) error {
+	return nil, nil
+}
 
-	event, err := registrationService.eventRepository.GetEventById(eventId)
+func (registrationService RegistrationService) DeleteRegistration(eventId, userId, organizationId int64) error {
+	event, err := registrationService.eventRepository.GetEventById(eventId, organizationId)
 
 	if err != nil {
 		return err
@@ -40,21 +72,103 @@ func (registrationService RegistrationService) DeleteRegistration(eventId, userI
 		return errors.New(constants.NO_EVENT_FOR_ID_ERROR)
 	}
 
-	err = registrationService.registrationRepository.DeleteRegistration(eventId, userId)
+	err = registrationService.registrationRepository.DeleteRegistration(eventId, userId, organizationId)
 
 	if err != nil {
 		return err
 	}
 
+	registrationService.publishRegistrationCount(event)
+
 	return nil
+}
+
+func (registrationService RegistrationService) IsRegistered(eventId, userId, organizationId int64) (bool, error) {
+	return registrationService.registrationRepository.IsRegistered(eventId, userId, organizationId)
+}
+
+// Lists every pair of events of the tenant the user is registered for that take place at the same time
+func (registrationService RegistrationService) GetScheduleConflicts(userId, organizationId int64) ([]models.ScheduleConflict, error) {
+	events, err := registrationService.eventRepository.GetEventsByRegistrant(userId, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]models.ScheduleConflict, 0)
+
+	for i := range events {
+		for j := i + 1; j < len(events); j++ {
+			if events[i].Overlaps(events[j]) {
+				conflicts = append(conflicts, models.ScheduleConflict{
+					First:  models.NewScheduledEvent(events[i]),
+					Second: models.NewScheduledEvent(events[j]),
+				})
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// Events of other tenants only keep their id, schedule and organization, the details are not
+// shared with the tenant the registration is made in
+func (registrationService RegistrationService) getOverlappingEvents(event models.Event, userId int64) ([]models.Event, error) {
+	registeredEvents, err := registrationService.eventRepository.GetEventsByRegistrantInAllOrganizations(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	overlapping := make([]models.Event, 0)
+
+	for _, registeredEvent := range registeredEvents {
+		if registeredEvent.Id == event.Id || !registeredEvent.Overlaps(event) {
+			continue
+		}
+
+		if registeredEvent.OrganizationId != event.OrganizationId {
+			registeredEvent = models.Event{
+				Id:             registeredEvent.Id,
+				Date:           registeredEvent.Date,
+				EndDate:        registeredEvent.EndDate,
+				OrganizationId: registeredEvent.OrganizationId,
+			}
+		}
+
+		overlapping = append(overlapping, registeredEvent)
+	}
+
+	return overlapping, nil
+}
+
+// The registration itself already succeeded at this point, so failing to count
+// registrations only skips the live update instead of failing the request
+func (registrationService RegistrationService) publishRegistrationCount(event *models.Event) {
+	count, err := registrationService.registrationRepository.CountRegistrations(event.Id, event.OrganizationId)
+
+	if err != nil {
+		return
+	}
 
+	registrationService.eventBroadcaster.Publish(models.EventNotification{
+		Type:              models.EVENT_REGISTRATIONS_NOTIFICATION,
+		EventId:           event.Id,
+		RegistrationCount: &count,
+		Visibility:        event.Visibility,
+		OrganizationId:    event.OrganizationId,
+	})
 }
 
 func NewRegistrationService(
-	registrationRepository interfaces.IRegistrationRepository,
-	eventRepository interfaces.IEventRepository) *RegistrationService {
+	registrationRepository repositoryInterfaces.IRegistrationRepository,
+	eventRepository repositoryInterfaces.IEventRepository,
+	invitationRepository repositoryInterfaces.IInvitationRepository,
+	eventBroadcaster libInterfaces.IEventBroadcaster) *RegistrationService {
 	return &RegistrationService{
 		registrationRepository: registrationRepository,
 		eventRepository:        eventRepository,
+		invitationRepository:   invitationRepository,
+		eventBroadcaster:       eventBroadcaster,
 	}
 }
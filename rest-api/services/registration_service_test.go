@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"example.com/constants"
 	"example.com/mocks"
@@ -15,31 +16,39 @@ type RegistrationServiceUnitTestSuite struct {
 	suite.Suite
 	registrationRepositoryMock mocks.IRegistrationRepository
 	eventRepositoryMock        mocks.IEventRepository
+	invitationRepositoryMock   mocks.IInvitationRepository
+	eventBroadcasterMock       mocks.IEventBroadcaster
 	service                    *RegistrationService
 }
 
 func TestRegistrationServiceUnitTestSuite(t *testing.T) {
-	suite.Run(t, &EventServiceUnitTestSuite{})
+	suite.Run(t, &RegistrationServiceUnitTestSuite{})
 }
 
 func (suite *RegistrationServiceUnitTestSuite) SetupTest() {
 	suite.eventRepositoryMock = mocks.IEventRepository{}
 	suite.registrationRepositoryMock = mocks.IRegistrationRepository{}
+	suite.invitationRepositoryMock = mocks.IInvitationRepository{}
+	suite.eventBroadcasterMock = mocks.IEventBroadcaster{}
+
+	suite.eventBroadcasterMock.On("Publish", mock.Anything).Return()
 
 	suite.service = NewRegistrationService(
 		&suite.registrationRepositoryMock,
-		&suite.eventRepositoryMock)
+		&suite.eventRepositoryMock,
+		&suite.invitationRepositoryMock,
+		&suite.eventBroadcasterMock)
 }
 
 func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_AttemptsToGetEventById() {
 
 	var expectedEventId, expectedUserId int64 = 1, 12
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
-	suite.service.CreateRegistration(expectedEventId, expectedUserId)
+	suite.service.CreateRegistration(expectedEventId, expectedUserId, 0, false)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId, int64(0))
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
 }
 
@@ -48,9 +57,9 @@ func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_ReturnsAnE
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, expectedError)
 
-	err := suite.service.CreateRegistration(1, 1)
+	_, err := suite.service.CreateRegistration(1, 1, 0, false)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -59,9 +68,9 @@ func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_ReturnsAnE
 // When there is no event for the provided id, return an error
 func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWhenNoEventFound_ReturnsAnError() {
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{}, nil)
 
-	err := suite.service.CreateRegistration(1, 1)
+	_, err := suite.service.CreateRegistration(1, 1, 0, false)
 
 	suite.NotNil(err)
 	suite.Equal(err.Error(), constants.NO_EVENT_FOR_ID_ERROR)
@@ -71,12 +80,13 @@ func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_AttemptsTo
 
 	var expectedEventId, expectedUserId int64 = 1, 12
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
 
-	suite.service.CreateRegistration(expectedEventId, expectedUserId)
+	suite.service.CreateRegistration(expectedEventId, expectedUserId, 0, false)
 
-	suite.registrationRepositoryMock.AssertCalled(suite.T(), "CreateRegistration", expectedEventId, expectedUserId)
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "CreateRegistration", expectedEventId, expectedUserId, int64(0))
 	suite.registrationRepositoryMock.AssertNumberOfCalls(suite.T(), "CreateRegistration", 1)
 }
 
@@ -85,10 +95,11 @@ func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWhenUnableT
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(expectedError)
 
-	err := suite.service.CreateRegistration(1, 12)
+	_, err := suite.service.CreateRegistration(1, 12, 0, false)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -96,10 +107,12 @@ func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWhenUnableT
 
 func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_ReturnsNil() {
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything).Return(nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(1), nil)
 
-	err := suite.service.CreateRegistration(1, 12)
+	_, err := suite.service.CreateRegistration(1, 12, 0, false)
 
 	suite.Nil(err)
 }
@@ -108,11 +121,11 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_AttemptsTo
 
 	var expectedEventId, expectedUserId int64 = 1, 12
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, errors.New("test"))
 
-	suite.service.DeleteRegistration(expectedEventId, expectedUserId)
+	suite.service.DeleteRegistration(expectedEventId, expectedUserId, 0)
 
-	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventById", expectedEventId, int64(0))
 	suite.eventRepositoryMock.AssertNumberOfCalls(suite.T(), "GetEventById", 1)
 }
 
@@ -121,9 +134,9 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_ReturnsAnE
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(nil, expectedError)
 
-	err := suite.service.DeleteRegistration(1, 1)
+	err := suite.service.DeleteRegistration(1, 1, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -132,9 +145,9 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_ReturnsAnE
 // When there is no event for the provided id, return an error
 func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistrationWhenNoEventFound_ReturnsAnError() {
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(nil, nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{}, nil)
 
-	err := suite.service.DeleteRegistration(1, 1)
+	err := suite.service.DeleteRegistration(1, 1, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err.Error(), constants.NO_EVENT_FOR_ID_ERROR)
@@ -144,12 +157,12 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_AttemptsTo
 
 	var expectedEventId, expectedUserId int64 = 1, 12
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(errors.New("test"))
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("test"))
 
-	suite.service.DeleteRegistration(expectedEventId, expectedUserId)
+	suite.service.DeleteRegistration(expectedEventId, expectedUserId, 0)
 
-	suite.registrationRepositoryMock.AssertCalled(suite.T(), "DeleteRegistration", expectedEventId, expectedUserId)
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "DeleteRegistration", expectedEventId, expectedUserId, int64(0))
 	suite.registrationRepositoryMock.AssertNumberOfCalls(suite.T(), "DeleteRegistration", 1)
 }
 
@@ -158,10 +171,10 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistrationWhenUnableT
 
 	expectedError := errors.New("test")
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(expectedError)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(expectedError)
 
-	err := suite.service.DeleteRegistration(1, 12)
+	err := suite.service.DeleteRegistration(1, 12, 0)
 
 	suite.NotNil(err)
 	suite.Equal(err, expectedError)
@@ -169,10 +182,239 @@ func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistrationWhenUnableT
 
 func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_ReturnsNil() {
 
-	suite.eventRepositoryMock.On("GetEventById", mock.Anything).Return(models.Event{Id: 12}, nil)
-	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything).Return(nil)
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	err := suite.service.DeleteRegistration(1, 12, 0)
+
+	suite.Nil(err)
+}
+
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistration_PublishesTheRegistrationCount() {
+
+	var expectedCount int64 = 7
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(expectedCount, nil)
+
+	suite.service.CreateRegistration(12, 1, 0, false)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", models.EventNotification{
+		Type:              models.EVENT_REGISTRATIONS_NOTIFICATION,
+		EventId:           12,
+		RegistrationCount: &expectedCount,
+	})
+}
+
+// When counting the registrations fails, the registration should still succeed without a notification
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWhenUnableToCount_ReturnsNil() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(0), errors.New("test"))
+
+	_, err := suite.service.CreateRegistration(12, 1, 0, false)
+
+	suite.Nil(err)
+	suite.eventBroadcasterMock.AssertNotCalled(suite.T(), "Publish", mock.Anything)
+}
+
+func (suite *RegistrationServiceUnitTestSuite) TestDeleteRegistration_PublishesTheRegistrationCount() {
+
+	var expectedCount int64 = 6
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 12}, nil)
+	suite.registrationRepositoryMock.On("DeleteRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(expectedCount, nil)
+
+	suite.service.DeleteRegistration(12, 1, 0)
+
+	suite.eventBroadcasterMock.AssertCalled(suite.T(), "Publish", models.EventNotification{
+		Type:              models.EVENT_REGISTRATIONS_NOTIFICATION,
+		EventId:           12,
+		RegistrationCount: &expectedCount,
+	})
+}
+
+func (suite *RegistrationServiceUnitTestSuite) TestIsRegistered_ReturnsTheRepositoryResult() {
+
+	suite.registrationRepositoryMock.On("IsRegistered", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	registered, err := suite.service.IsRegistered(12, 1, 0)
+
+	suite.Nil(err)
+	suite.True(registered)
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "IsRegistered", int64(12), int64(1), int64(0))
+}
+
+// When the event is private and the user was not invited, the registration is refused
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationForPrivateEventNotInvited_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}, nil)
+	suite.invitationRepositoryMock.On("IsInvited", mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := suite.service.CreateRegistration(1, 12, 0, false)
+
+	suite.invitationRepositoryMock.AssertCalled(suite.T(), "IsInvited", int64(1), int64(12))
+	suite.NotNil(err)
+	suite.Equal(constants.NOT_INVITED_ERROR, err.Error())
+	suite.registrationRepositoryMock.AssertNotCalled(suite.T(), "CreateRegistration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationForPrivateEventInvited_CreatesTheRegistration() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, UserId: 3, Visibility: models.PRIVATE_VISIBILITY}, nil)
+	suite.invitationRepositoryMock.On("IsInvited", mock.Anything, mock.Anything).Return(true, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	_, err := suite.service.CreateRegistration(1, 12, 0, false)
+
+	suite.Nil(err)
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "CreateRegistration", int64(1), int64(12), int64(0))
+}
+
+// When the event overlaps with another registration, return the overlapping events without registering
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWithConflicts_ReturnsTheConflicts() {
+
+	date := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	endDate := date.Add(3 * time.Hour)
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, Date: date, EndDate: &endDate}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{
+		{Id: 1, Date: date, EndDate: &endDate},
+		{Id: 2, Date: date.Add(-2 * time.Hour)},
+		{Id: 3, Date: date.Add(2 * time.Hour)},
+		{Id: 4, Date: endDate},
+	}, nil)
+
+	conflicts, err := suite.service.CreateRegistration(1, 12, 0, false)
+
+	suite.NotNil(err)
+	suite.Equal(constants.SCHEDULE_CONFLICT_ERROR, err.Error())
+	suite.Equal([]models.Event{{Id: 3, Date: date.Add(2 * time.Hour)}}, conflicts)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventsByRegistrantInAllOrganizations", int64(12))
+	suite.registrationRepositoryMock.AssertNotCalled(suite.T(), "CreateRegistration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Registrations of other organizations conflict too, without revealing their details
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWithConflictsInOtherOrganizations_RedactsTheirEvents() {
+
+	date := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	endDate := date.Add(3 * time.Hour)
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, Date: date, EndDate: &endDate, OrganizationId: 4}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{
+		{Id: 2, Name: "Same organization", Location: "Lyon", Date: date, OrganizationId: 4},
+		{Id: 3, Name: "Other organization", Description: "Board meeting", Location: "Paris", Date: date, EndDate: &endDate, UserId: 7, Visibility: models.PRIVATE_VISIBILITY, OrganizationId: 5},
+		{Id: 5, Name: "Personal space", Location: "Home", Date: date.Add(time.Hour)},
+	}, nil)
+
+	conflicts, err := suite.service.CreateRegistration(1, 12, 4, false)
+
+	suite.NotNil(err)
+	suite.Equal(constants.SCHEDULE_CONFLICT_ERROR, err.Error())
+	suite.Equal([]models.Event{
+		{Id: 2, Name: "Same organization", Location: "Lyon", Date: date, OrganizationId: 4},
+		{Id: 3, Date: date, EndDate: &endDate, OrganizationId: 5},
+		{Id: 5, Date: date.Add(time.Hour)},
+	}, conflicts)
+	suite.registrationRepositoryMock.AssertNotCalled(suite.T(), "CreateRegistration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When conflicts are allowed, the registrations are not checked at all
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationAllowingConflicts_CreatesTheRegistration() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	conflicts, err := suite.service.CreateRegistration(1, 12, 0, true)
+
+	suite.Nil(err)
+	suite.Nil(conflicts)
+	suite.eventRepositoryMock.AssertNotCalled(suite.T(), "GetEventsByRegistrantInAllOrganizations", mock.Anything)
+}
+
+// When fetching the registered events fails, pass that error up
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationWhenUnableToCheckConflicts_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return(nil, expectedError)
+
+	_, err := suite.service.CreateRegistration(1, 12, 0, false)
+
+	suite.Equal(expectedError, err)
+}
+
+func (suite *RegistrationServiceUnitTestSuite) TestGetScheduleConflicts_ReturnsEveryOverlappingPair() {
+
+	date := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	endDate := date.Add(3 * time.Hour)
+
+	suite.eventRepositoryMock.On("GetEventsByRegistrant", mock.Anything, mock.Anything).Return([]models.Event{
+		{Id: 1, Date: date, EndDate: &endDate},
+		{Id: 2, Date: date.Add(time.Hour)},
+		{Id: 3, Date: date.Add(90 * time.Minute)},
+		{Id: 4, Date: endDate},
+	}, nil)
+
+	conflicts, err := suite.service.GetScheduleConflicts(12, 0)
+
+	suite.Nil(err)
+	suite.Len(conflicts, 3)
+	suite.Equal(int64(1), conflicts[0].First.Id)
+	suite.Equal(int64(2), conflicts[0].Second.Id)
+	suite.Equal(endDate, conflicts[0].First.EndsAt)
+	suite.Equal(int64(3), conflicts[1].Second.Id)
+	suite.Equal(int64(2), conflicts[2].First.Id)
+	suite.Equal(int64(3), conflicts[2].Second.Id)
+}
+
+// When fetching the registered events fails, pass that error up
+func (suite *RegistrationServiceUnitTestSuite) TestGetScheduleConflicts_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.eventRepositoryMock.On("GetEventsByRegistrant", mock.Anything, mock.Anything).Return(nil, expectedError)
+
+	_, err := suite.service.GetScheduleConflicts(12, 0)
+
+	suite.Equal(expectedError, err)
+}
+
+// Events of another tenant are reported as missing and cannot be registered for
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationForAnotherTenant_ReturnsAnError() {
+
+	suite.eventRepositoryMock.On("GetEventById", int64(1), int64(4)).Return(&models.Event{}, nil)
+
+	_, err := suite.service.CreateRegistration(1, 12, 4, false)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_EVENT_FOR_ID_ERROR, err.Error())
+	suite.registrationRepositoryMock.AssertNotCalled(suite.T(), "CreateRegistration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// The registrations of every organization are checked for conflicts, the registration is made within
+// the organization of the event
+func (suite *RegistrationServiceUnitTestSuite) TestCreateRegistrationForAnOrganization_ChecksConflictsInEveryOrganization() {
+
+	suite.eventRepositoryMock.On("GetEventById", mock.Anything, mock.Anything).Return(&models.Event{Id: 1, OrganizationId: 4}, nil)
+	suite.eventRepositoryMock.On("GetEventsByRegistrantInAllOrganizations", mock.Anything).Return([]models.Event{}, nil)
+	suite.registrationRepositoryMock.On("CreateRegistration", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.registrationRepositoryMock.On("CountRegistrations", mock.Anything, mock.Anything).Return(int64(1), nil)
 
-	err := suite.service.DeleteRegistration(1, 12)
+	_, err := suite.service.CreateRegistration(1, 12, 4, false)
 
 	suite.Nil(err)
+	suite.eventRepositoryMock.AssertCalled(suite.T(), "GetEventsByRegistrantInAllOrganizations", int64(12))
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "CreateRegistration", int64(1), int64(12), int64(4))
+	suite.registrationRepositoryMock.AssertCalled(suite.T(), "CountRegistrations", int64(1), int64(4))
 }
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const refreshTokenLifetime = time.Hour * 24 * 30
+
+// How often the last seen time of a session gets recorded as it is used
+const sessionSeenInterval = time.Minute
+
+type TokenService struct {
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository
+	revokedTokenRepository repositoryInterfaces.IRevokedTokenRepository
+	userRepository         repositoryInterfaces.IUserRepository
+	sessionRepository      repositoryInterfaces.ISessionRepository
+	organizationRepository repositoryInterfaces.IOrganizationRepository
+	jwtAuthorizer          libInterfaces.IJwtAuthorizer
+}
+
+// Issues an access token along with a refresh token starting a new token family, which is the
+// login session of the client. Sessions start in the personal space
+func (tokenService TokenService) IssueTokens(user models.User, client models.SessionClient) (*models.TokenPair, error) {
+	familyId, err := lib.GenerateTokenId()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	err = tokenService.sessionRepository.SaveSession(models.Session{
+		Id:         familyId,
+		UserId:     user.Id,
+		IpAddress:  client.IpAddress,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(refreshTokenLifetime),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenService.issueTokens(user, familyId, models.OrganizationMembership{}, now)
+}
+
+// Exchanges a refresh token for a new pair of tokens. Each refresh token can only be used once,
+// presenting it again means it leaked, so every token of its family gets revoked
+func (tokenService TokenService) RefreshTokens(refreshToken string, client models.SessionClient) (*models.TokenPair, error) {
+	savedToken, err := tokenService.refreshTokenRepository.GetRefreshTokenByHash(lib.HashOpaqueToken(refreshToken))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if savedToken.Id == 0 {
+		return nil, errors.New(constants.INVALID_REFRESH_TOKEN_ERROR)
+	}
+
+	now := time.Now().UTC()
+
+	if savedToken.UsedAt != nil || savedToken.RevokedAt != nil {
+		return nil, tokenService.revokeFamily(savedToken.FamilyId, now)
+	}
+
+	if !now.Before(savedToken.ExpiresAt) {
+		return nil, errors.New(constants.INVALID_REFRESH_TOKEN_ERROR)
+	}
+
+	rotated, err := tokenService.refreshTokenRepository.MarkRefreshTokenUsed(savedToken.Id, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//another request rotated the token in the meantime
+	if !rotated {
+		return nil, tokenService.revokeFamily(savedToken.FamilyId, now)
+	}
+
+	user, err := tokenService.userRepository.GetUserById(savedToken.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//disabling a user revokes their tokens, this covers tokens rotated while it happened
+	if user.Id == 0 || user.IsDisabled() {
+		return nil, errors.New(constants.INVALID_REFRESH_TOKEN_ERROR)
+	}
+
+	session, err := tokenService.sessionRepository.GetSession(savedToken.FamilyId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//users removed from the organization of the session go back to the personal space
+	membership, err := tokenService.getMembership(session.OrganizationId, user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//also creates the session of families started before sessions were tracked
+	err = tokenService.sessionRepository.SaveSession(models.Session{
+		Id:             savedToken.FamilyId,
+		UserId:         user.Id,
+		IpAddress:      client.IpAddress,
+		UserAgent:      client.UserAgent,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(refreshTokenLifetime),
+		OrganizationId: membership.OrganizationId,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenService.issueTokens(*user, savedToken.FamilyId, *membership, now)
+}
+
+// Validates the access token, refusing tokens revoked by logging out, tokens of sessions that were
+// signed out of and tokens acting in an organization the user was removed from
+func (tokenService TokenService) ValidateAccessToken(token string) (*models.AccessTokenClaims, error) {
+	claims, err := tokenService.jwtAuthorizer.ValidateToken(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenId == "" {
+		return claims, nil
+	}
+
+	revoked, err := tokenService.revokedTokenRepository.IsAccessTokenRevoked(claims.TokenId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, errors.New(constants.REVOKED_ACCESS_TOKEN_ERROR)
+	}
+
+	if claims.OrganizationId != 0 {
+		membership, err := tokenService.organizationRepository.GetMembership(claims.OrganizationId, claims.UserId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if membership.OrganizationId == 0 {
+			return nil, errors.New(constants.REVOKED_ACCESS_TOKEN_ERROR)
+		}
+
+		//the role may have changed since the token was issued
+		claims.OrganizationRole = membership.Role
+	}
+
+	if claims.SessionId == "" {
+		return claims, nil
+	}
+
+	session, err := tokenService.sessionRepository.GetSession(claims.SessionId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if !session.IsActive(now) || session.UserId != claims.UserId {
+		return nil, errors.New(constants.REVOKED_ACCESS_TOKEN_ERROR)
+	}
+
+	err = tokenService.sessionRepository.MarkSessionSeen(session.Id, now, now.Add(-sessionSeenInterval))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Revokes the access token used for the request, along with the family of the refresh token
+// when one is provided
+func (tokenService TokenService) Logout(claims models.AccessTokenClaims, refreshToken string) error {
+	if refreshToken != "" {
+		savedToken, err := tokenService.refreshTokenRepository.GetRefreshTokenByHash(lib.HashOpaqueToken(refreshToken))
+
+		if err != nil {
+			return err
+		}
+
+		//tokens of other users are ignored rather than reported, to not confirm they exist
+		if savedToken.Id != 0 && savedToken.UserId == claims.UserId {
+			err = tokenService.refreshTokenRepository.RevokeRefreshTokenFamily(savedToken.FamilyId, time.Now().UTC())
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if claims.SessionId != "" {
+		_, err := tokenService.sessionRepository.RevokeSession(claims.SessionId, claims.UserId, time.Now().UTC())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return tokenService.revokeAccessToken(claims)
+}
+
+// Revokes every refresh token of the user along with the access token used for the request.
+// Ending the sessions also refuses the access tokens issued to them
+func (tokenService TokenService) LogoutEverywhere(claims models.AccessTokenClaims) error {
+	err := tokenService.refreshTokenRepository.RevokeRefreshTokensByUserId(claims.UserId, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	return tokenService.revokeAccessToken(claims)
+}
+
+// Moves the session to the organization, or back to the personal space with an organization id of
+// 0, returning an access token acting in it. Tokens refreshed afterwards keep acting in it, the
+// access token used for the request is revoked so it stops acting in the previous one
+func (tokenService TokenService) SwitchOrganization(claims models.AccessTokenClaims, organizationId int64) (*models.TokenPair, error) {
+	if claims.SessionId == "" {
+		return nil, errors.New(constants.NO_SESSION_FOR_ID_ERROR)
+	}
+
+	membership, err := tokenService.getMembership(organizationId, claims.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if membership.OrganizationId != organizationId {
+		return nil, errors.New(constants.NO_ORGANIZATION_FOR_ID_ERROR)
+	}
+
+	user, err := tokenService.userRepository.GetUserById(claims.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	err = tokenService.sessionRepository.UpdateSessionOrganization(claims.SessionId, organizationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := tokenService.jwtAuthorizer.GenerateToken(*user, claims.SessionId, *membership)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tokenService.revokeAccessToken(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(lib.ACCESS_TOKEN_LIFETIME.Seconds()),
+	}, nil
+}
+
+// Active sessions of the user, flagging the one the request was made from
+func (tokenService TokenService) GetSessions(userId int64, currentSessionId string) ([]models.Session, error) {
+	sessions, err := tokenService.sessionRepository.GetActiveSessionsByUserId(userId, time.Now().UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range sessions {
+		sessions[index].Current = sessions[index].Id == currentSessionId
+	}
+
+	return sessions, nil
+}
+
+// Signs the user out of the session right away, its access tokens are refused from then on and its
+// refresh tokens revoked
+func (tokenService TokenService) RevokeSession(id string, userId int64) error {
+	revoked, err := tokenService.sessionRepository.RevokeSession(id, userId, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return errors.New(constants.NO_SESSION_FOR_ID_ERROR)
+	}
+
+	return nil
+}
+
+// Public keys other services can verify access tokens with
+func (tokenService TokenService) GetJsonWebKeySet() models.JsonWebKeySet {
+	return tokenService.jwtAuthorizer.JsonWebKeySet()
+}
+
+func (tokenService TokenService) issueTokens(
+	user models.User,
+	familyId string,
+	membership models.OrganizationMembership,
+	now time.Time) (*models.TokenPair, error) {
+	accessToken, err := tokenService.jwtAuthorizer.GenerateToken(user, familyId, membership)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshTokenHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tokenService.refreshTokenRepository.CreateRefreshToken(&models.RefreshToken{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: now.Add(refreshTokenLifetime),
+		CreatedAt: now,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(lib.ACCESS_TOKEN_LIFETIME.Seconds()),
+		UserId:       user.Id,
+	}, nil
+}
+
+// Returns an empty membership for the personal space, or when the user is not a member of the
+// organization
+func (tokenService TokenService) getMembership(organizationId, userId int64) (*models.OrganizationMembership, error) {
+	if organizationId == 0 {
+		return &models.OrganizationMembership{}, nil
+	}
+
+	return tokenService.organizationRepository.GetMembership(organizationId, userId)
+}
+
+func (tokenService TokenService) revokeFamily(familyId string, now time.Time) error {
+	err := tokenService.refreshTokenRepository.RevokeRefreshTokenFamily(familyId, now)
+
+	if err != nil {
+		return err
+	}
+
+	return errors.New(constants.REFRESH_TOKEN_REUSE_ERROR)
+}
+
+func (tokenService TokenService) revokeAccessToken(claims models.AccessTokenClaims) error {
+	if claims.TokenId == "" {
+		return nil
+	}
+
+	err := tokenService.revokedTokenRepository.RevokeAccessToken(claims.TokenId, claims.ExpiresAt)
+
+	if err != nil {
+		return err
+	}
+
+	//the token is already revoked at this point, failing to clean up only leaves expired entries behind
+	tokenService.revokedTokenRepository.DeleteExpiredAccessTokens(time.Now().UTC())
+
+	return nil
+}
+
+func NewTokenService(
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository,
+	revokedTokenRepository repositoryInterfaces.IRevokedTokenRepository,
+	userRepository repositoryInterfaces.IUserRepository,
+	sessionRepository repositoryInterfaces.ISessionRepository,
+	organizationRepository repositoryInterfaces.IOrganizationRepository,
+	jwtAuthorizer libInterfaces.IJwtAuthorizer) *TokenService {
+	return &TokenService{
+		refreshTokenRepository: refreshTokenRepository,
+		revokedTokenRepository: revokedTokenRepository,
+		userRepository:         userRepository,
+		sessionRepository:      sessionRepository,
+		organizationRepository: organizationRepository,
+		jwtAuthorizer:          jwtAuthorizer,
+	}
+}
@@ -0,0 +1,607 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenServiceUnitTestSuite struct {
+	suite.Suite
+	refreshTokenRepositoryMock mocks.IRefreshTokenRepository
+	revokedTokenRepositoryMock mocks.IRevokedTokenRepository
+	userRepositoryMock         mocks.IUserRepository
+	sessionRepositoryMock      mocks.ISessionRepository
+	organizationRepositoryMock mocks.IOrganizationRepository
+	jwtAuthorizerMock          mocks.IJwtAuthorizer
+	service                    *TokenService
+}
+
+func TestTokenServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &TokenServiceUnitTestSuite{})
+}
+
+func (suite *TokenServiceUnitTestSuite) SetupTest() {
+	suite.refreshTokenRepositoryMock = mocks.IRefreshTokenRepository{}
+	suite.revokedTokenRepositoryMock = mocks.IRevokedTokenRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.sessionRepositoryMock = mocks.ISessionRepository{}
+	suite.organizationRepositoryMock = mocks.IOrganizationRepository{}
+	suite.jwtAuthorizerMock = mocks.IJwtAuthorizer{}
+
+	suite.service = NewTokenService(
+		&suite.refreshTokenRepositoryMock,
+		&suite.revokedTokenRepositoryMock,
+		&suite.userRepositoryMock,
+		&suite.sessionRepositoryMock,
+		&suite.organizationRepositoryMock,
+		&suite.jwtAuthorizerMock)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestIssueTokens_StoresTheRefreshTokenHash() {
+
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	user := models.User{Id: 12, Email: "test@test.com", Role: models.ADMIN_ROLE}
+
+	tokens, err := suite.service.IssueTokens(user, models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.Nil(err)
+	suite.Equal("access token", tokens.AccessToken)
+	suite.NotEmpty(tokens.RefreshToken)
+
+	savedToken := suite.refreshTokenRepositoryMock.Calls[0].Arguments.Get(0).(*models.RefreshToken)
+
+	suite.Equal(int64(12), savedToken.UserId)
+	suite.NotEmpty(savedToken.FamilyId)
+	suite.Equal(lib.HashOpaqueToken(tokens.RefreshToken), savedToken.TokenHash)
+	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", user, savedToken.FamilyId, models.OrganizationMembership{})
+}
+
+// The token family is the session of the client, which the access token is tied to
+func (suite *TokenServiceUnitTestSuite) TestIssueTokens_StartsASession() {
+
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	_, err := suite.service.IssueTokens(models.User{Id: 12}, models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.Nil(err)
+
+	session := suite.sessionRepositoryMock.Calls[0].Arguments.Get(0).(models.Session)
+	savedToken := suite.refreshTokenRepositoryMock.Calls[0].Arguments.Get(0).(*models.RefreshToken)
+
+	suite.Equal(savedToken.FamilyId, session.Id)
+	suite.Equal(int64(12), session.UserId)
+	suite.Equal("10.0.0.1", session.IpAddress)
+	suite.Equal("curl", session.UserAgent)
+	suite.Equal(savedToken.ExpiresAt, session.ExpiresAt)
+}
+
+// When the session cannot be saved, no token is issued
+func (suite *TokenServiceUnitTestSuite) TestIssueTokens_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(expectedError)
+
+	_, err := suite.service.IssueTokens(models.User{Id: 12}, models.SessionClient{})
+
+	suite.Equal(expectedError, err)
+	suite.jwtAuthorizerMock.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything, mock.Anything, mock.Anything)
+	suite.refreshTokenRepositoryMock.AssertNotCalled(suite.T(), "CreateRefreshToken", mock.Anything)
+}
+
+// When no refresh token matches, return an error
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensUnknownToken_ReturnsAnError() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{}, nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_REFRESH_TOKEN_ERROR, err.Error())
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "GetRefreshTokenByHash", lib.HashOpaqueToken("refresh token"))
+}
+
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensExpiredToken_ReturnsAnError() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_REFRESH_TOKEN_ERROR, err.Error())
+	suite.refreshTokenRepositoryMock.AssertNotCalled(suite.T(), "MarkRefreshTokenUsed", mock.Anything, mock.Anything)
+}
+
+// When a rotated refresh token is presented again, the whole family is revoked
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensReusedToken_RevokesTheFamily() {
+
+	usedAt := time.Now().Add(-time.Minute)
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokenFamily", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.REFRESH_TOKEN_REUSE_ERROR, err.Error())
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokenFamily", "family", mock.Anything)
+	suite.jwtAuthorizerMock.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When another request rotated the token first, the family is revoked as well
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensConcurrentRotation_RevokesTheFamily() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(false, nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokenFamily", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.REFRESH_TOKEN_REUSE_ERROR, err.Error())
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokenFamily", "family", mock.Anything)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokens_RotatesTheToken() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{Id: "family", UserId: 12}, nil)
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	tokens, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.Nil(err)
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "MarkRefreshTokenUsed", int64(3), mock.Anything)
+	suite.Equal("access token", tokens.AccessToken)
+	suite.NotEqual("refresh token", tokens.RefreshToken)
+	suite.Equal(int64(12), tokens.UserId)
+
+	savedToken := suite.refreshTokenRepositoryMock.Calls[2].Arguments.Get(0).(*models.RefreshToken)
+
+	suite.Equal("family", savedToken.FamilyId)
+	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", mock.Anything, "family", models.OrganizationMembership{})
+}
+
+// Refreshing records the client the session was last used from
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokens_UpdatesTheSession() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{Id: "family", UserId: 12}, nil)
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.2", UserAgent: "firefox"})
+
+	suite.Nil(err)
+
+	session := suite.sessionRepositoryMock.Calls[1].Arguments.Get(0).(models.Session)
+
+	suite.Equal("family", session.Id)
+	suite.Equal(int64(12), session.UserId)
+	suite.Equal("10.0.0.2", session.IpAddress)
+	suite.Equal("firefox", session.UserAgent)
+}
+
+// When the user was disabled, the refresh token is refused
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensDisabledUser_ReturnsAnError() {
+
+	disabledAt := time.Now()
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, DisabledAt: &disabledAt}, nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_REFRESH_TOKEN_ERROR, err.Error())
+	suite.jwtAuthorizerMock.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenRevoked_ReturnsAnError() {
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12, TokenId: "token id"}, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(true, nil)
+
+	_, err := suite.service.ValidateAccessToken("access token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.REVOKED_ACCESS_TOKEN_ERROR, err.Error())
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "IsAccessTokenRevoked", "token id")
+}
+
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessToken_ReturnsTheClaims() {
+
+	expectedClaims := &models.AccessTokenClaims{UserId: 12, TokenId: "token id"}
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(expectedClaims, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+
+	claims, err := suite.service.ValidateAccessToken("access token")
+
+	suite.Nil(err)
+	suite.Equal(expectedClaims, claims)
+}
+
+// When the session was signed out of, its access tokens are refused
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenRevokedSession_ReturnsAnError() {
+
+	revokedAt := time.Now()
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12, TokenId: "token id", SessionId: "session"}, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{
+		Id:        "session",
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}, nil)
+
+	_, err := suite.service.ValidateAccessToken("access token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.REVOKED_ACCESS_TOKEN_ERROR, err.Error())
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "GetSession", "session")
+	suite.sessionRepositoryMock.AssertNotCalled(suite.T(), "MarkSessionSeen", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the session does not exist, the token is refused
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenUnknownSession_ReturnsAnError() {
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(&models.AccessTokenClaims{UserId: 12, TokenId: "token id", SessionId: "session"}, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{}, nil)
+
+	_, err := suite.service.ValidateAccessToken("access token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.REVOKED_ACCESS_TOKEN_ERROR, err.Error())
+}
+
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenWithSession_MarksTheSessionSeen() {
+
+	expectedClaims := &models.AccessTokenClaims{UserId: 12, TokenId: "token id", SessionId: "session"}
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(expectedClaims, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{
+		Id:        "session",
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.sessionRepositoryMock.On("MarkSessionSeen", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	claims, err := suite.service.ValidateAccessToken("access token")
+
+	suite.Nil(err)
+	suite.Equal(expectedClaims, claims)
+
+	seenAt := suite.sessionRepositoryMock.Calls[1].Arguments.Get(1).(time.Time)
+	throttledBefore := suite.sessionRepositoryMock.Calls[1].Arguments.Get(2).(time.Time)
+
+	suite.Equal("session", suite.sessionRepositoryMock.Calls[1].Arguments.Get(0))
+	suite.Equal(sessionSeenInterval, seenAt.Sub(throttledBefore))
+}
+
+func (suite *TokenServiceUnitTestSuite) TestLogout_RevokesTheAccessTokenAndTheFamily() {
+
+	expiresAt := time.Now().Add(time.Minute)
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{Id: 3, UserId: 12, FamilyId: "family"}, nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokenFamily", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("RevokeAccessToken", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("DeleteExpiredAccessTokens", mock.Anything).Return(nil)
+
+	err := suite.service.Logout(models.AccessTokenClaims{UserId: 12, TokenId: "token id", ExpiresAt: expiresAt}, "refresh token")
+
+	suite.Nil(err)
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokenFamily", "family", mock.Anything)
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "RevokeAccessToken", "token id", expiresAt)
+}
+
+// The refresh token of another user is left untouched
+func (suite *TokenServiceUnitTestSuite) TestLogoutWithTokenOfAnotherUser_OnlyRevokesTheAccessToken() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{Id: 3, UserId: 7, FamilyId: "family"}, nil)
+	suite.revokedTokenRepositoryMock.On("RevokeAccessToken", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("DeleteExpiredAccessTokens", mock.Anything).Return(nil)
+
+	err := suite.service.Logout(models.AccessTokenClaims{UserId: 12, TokenId: "token id"}, "refresh token")
+
+	suite.Nil(err)
+	suite.refreshTokenRepositoryMock.AssertNotCalled(suite.T(), "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "RevokeAccessToken", "token id", mock.Anything)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestLogoutEverywhere_RevokesEveryRefreshToken() {
+
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("RevokeAccessToken", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("DeleteExpiredAccessTokens", mock.Anything).Return(errors.New("test"))
+
+	err := suite.service.LogoutEverywhere(models.AccessTokenClaims{UserId: 12, TokenId: "token id"})
+
+	suite.Nil(err)
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "RevokeAccessToken", "token id", mock.Anything)
+}
+
+// When revoking the refresh tokens fails, pass that error up
+func (suite *TokenServiceUnitTestSuite) TestLogoutEverywhere_ReturnsAnError() {
+
+	expectedError := errors.New("test")
+
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(expectedError)
+
+	err := suite.service.LogoutEverywhere(models.AccessTokenClaims{UserId: 12, TokenId: "token id"})
+
+	suite.Equal(expectedError, err)
+	suite.revokedTokenRepositoryMock.AssertNotCalled(suite.T(), "RevokeAccessToken", mock.Anything, mock.Anything)
+}
+
+// The session of the access token is ended even when no refresh token is provided
+func (suite *TokenServiceUnitTestSuite) TestLogoutWithSession_RevokesTheSession() {
+
+	suite.sessionRepositoryMock.On("RevokeSession", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	suite.revokedTokenRepositoryMock.On("RevokeAccessToken", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("DeleteExpiredAccessTokens", mock.Anything).Return(nil)
+
+	err := suite.service.Logout(models.AccessTokenClaims{UserId: 12, TokenId: "token id", SessionId: "session"}, "")
+
+	suite.Nil(err)
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "RevokeSession", "session", int64(12), mock.Anything)
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "RevokeAccessToken", "token id", mock.Anything)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestGetSessions_FlagsTheCurrentSession() {
+
+	suite.sessionRepositoryMock.On("GetActiveSessionsByUserId", mock.Anything, mock.Anything).Return([]models.Session{
+		{Id: "other", UserId: 12},
+		{Id: "current", UserId: 12},
+	}, nil)
+
+	sessions, err := suite.service.GetSessions(12, "current")
+
+	suite.Nil(err)
+	suite.Len(sessions, 2)
+	suite.False(sessions[0].Current)
+	suite.True(sessions[1].Current)
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "GetActiveSessionsByUserId", int64(12), mock.Anything)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestRevokeSession_RevokesTheSession() {
+
+	suite.sessionRepositoryMock.On("RevokeSession", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	err := suite.service.RevokeSession("session", 12)
+
+	suite.Nil(err)
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "RevokeSession", "session", int64(12), mock.Anything)
+}
+
+// When the user has no such active session, return an error
+func (suite *TokenServiceUnitTestSuite) TestRevokeSessionUnknownSession_ReturnsAnError() {
+
+	suite.sessionRepositoryMock.On("RevokeSession", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := suite.service.RevokeSession("session", 12)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_SESSION_FOR_ID_ERROR, err.Error())
+}
+
+func (suite *TokenServiceUnitTestSuite) TestGetJsonWebKeySet_ReturnsThePublicKeys() {
+
+	keySet := models.JsonWebKeySet{Keys: []models.JsonWebKey{{KeyType: "OKP", KeyId: "key id", Algorithm: "EdDSA"}}}
+
+	suite.jwtAuthorizerMock.On("JsonWebKeySet").Return(keySet)
+
+	suite.Equal(keySet, suite.service.GetJsonWebKeySet())
+}
+
+// Refreshed tokens keep acting in the organization the session was switched to
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensInAnOrganization_KeepsTheOrganization() {
+
+	membership := models.OrganizationMembership{OrganizationId: 4, Name: "Acme", Role: models.ORGANIZATION_ADMIN_ROLE}
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12}, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{Id: "family", UserId: 12, OrganizationId: 4}, nil)
+	suite.organizationRepositoryMock.On("GetMembership", int64(4), int64(12)).Return(&membership, nil)
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{})
+
+	suite.Nil(err)
+	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", mock.Anything, "family", membership)
+
+	session := suite.sessionRepositoryMock.Calls[1].Arguments.Get(0).(models.Session)
+
+	suite.Equal(int64(4), session.OrganizationId)
+}
+
+// When the user was removed from the organization of the session, tokens go back to the personal space
+func (suite *TokenServiceUnitTestSuite) TestRefreshTokensAfterLeavingTheOrganization_ReturnsToThePersonalSpace() {
+
+	suite.refreshTokenRepositoryMock.On("GetRefreshTokenByHash", mock.Anything).Return(&models.RefreshToken{
+		Id:        3,
+		UserId:    12,
+		FamilyId:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	suite.refreshTokenRepositoryMock.On("MarkRefreshTokenUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12}, nil)
+	suite.sessionRepositoryMock.On("GetSession", mock.Anything).Return(&models.Session{Id: "family", UserId: 12, OrganizationId: 4}, nil)
+	suite.organizationRepositoryMock.On("GetMembership", int64(4), int64(12)).Return(&models.OrganizationMembership{}, nil)
+	suite.sessionRepositoryMock.On("SaveSession", mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.refreshTokenRepositoryMock.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	_, err := suite.service.RefreshTokens("refresh token", models.SessionClient{})
+
+	suite.Nil(err)
+	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", mock.Anything, "family", models.OrganizationMembership{})
+
+	session := suite.sessionRepositoryMock.Calls[1].Arguments.Get(0).(models.Session)
+
+	suite.Equal(int64(0), session.OrganizationId)
+}
+
+// When the user was removed from the organization the token acts in, the token is refused
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenOfAFormerMember_ReturnsAnError() {
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(&models.AccessTokenClaims{
+		UserId:           12,
+		TokenId:          "token id",
+		OrganizationId:   4,
+		OrganizationRole: models.ORGANIZATION_ADMIN_ROLE,
+	}, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	suite.organizationRepositoryMock.On("GetMembership", int64(4), int64(12)).Return(&models.OrganizationMembership{}, nil)
+
+	_, err := suite.service.ValidateAccessToken("access token")
+
+	suite.NotNil(err)
+	suite.Equal(constants.REVOKED_ACCESS_TOKEN_ERROR, err.Error())
+}
+
+// The role in the organization is read from the membership, so demoted admins lose their rights right away
+func (suite *TokenServiceUnitTestSuite) TestValidateAccessTokenInAnOrganization_UsesTheCurrentRole() {
+
+	suite.jwtAuthorizerMock.On("ValidateToken", mock.Anything).Return(&models.AccessTokenClaims{
+		UserId:           12,
+		TokenId:          "token id",
+		OrganizationId:   4,
+		OrganizationRole: models.ORGANIZATION_ADMIN_ROLE,
+	}, nil)
+	suite.revokedTokenRepositoryMock.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	suite.organizationRepositoryMock.On("GetMembership", int64(4), int64(12)).Return(&models.OrganizationMembership{
+		OrganizationId: 4,
+		Role:           models.ORGANIZATION_MEMBER_ROLE,
+	}, nil)
+
+	claims, err := suite.service.ValidateAccessToken("access token")
+
+	suite.Nil(err)
+	suite.Equal(int64(4), claims.OrganizationId)
+	suite.Equal(models.ORGANIZATION_MEMBER_ROLE, claims.OrganizationRole)
+}
+
+func (suite *TokenServiceUnitTestSuite) TestSwitchOrganization_IssuesATokenActingInTheOrganization() {
+
+	membership := models.OrganizationMembership{OrganizationId: 4, Role: models.ORGANIZATION_MEMBER_ROLE}
+	user := models.User{Id: 12}
+
+	suite.organizationRepositoryMock.On("GetMembership", int64(4), int64(12)).Return(&membership, nil)
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&user, nil)
+	suite.sessionRepositoryMock.On("UpdateSessionOrganization", mock.Anything, mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+	suite.revokedTokenRepositoryMock.On("RevokeAccessToken", mock.Anything, mock.Anything).Return(nil)
+	suite.revokedTokenRepositoryMock.On("DeleteExpiredAccessTokens", mock.Anything).Return(nil)
+
+	expiresAt := time.Now().Add(time.Minute)
+
+	tokens, err := suite.service.SwitchOrganization(models.AccessTokenClaims{UserId: 12, SessionId: "session", TokenId: "token id", ExpiresAt: expiresAt}, 4)
+
+	suite.Nil(err)
+	suite.Equal("access token", tokens.AccessToken)
+	suite.Empty(tokens.RefreshToken)
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "UpdateSessionOrganization", "session", int64(4))
+	suite.jwtAuthorizerMock.AssertCalled(suite.T(), "GenerateToken", user, "session", membership)
+	//the previous token would otherwise keep acting in the personal space until it expires
+	suite.revokedTokenRepositoryMock.AssertCalled(suite.T(), "RevokeAccessToken", "token id", expiresAt)
+}
+
+// Switching back to the personal space does not need a membership
+func (suite *TokenServiceUnitTestSuite) TestSwitchOrganizationToThePersonalSpace_IssuesAPersonalToken() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12}, nil)
+	suite.sessionRepositoryMock.On("UpdateSessionOrganization", mock.Anything, mock.Anything).Return(nil)
+	suite.jwtAuthorizerMock.On("GenerateToken", mock.Anything, mock.Anything, mock.Anything).Return("access token", nil)
+
+	_, err := suite.service.SwitchOrganization(models.AccessTokenClaims{UserId: 12, SessionId: "session"}, 0)
+
+	suite.Nil(err)
+	suite.organizationRepositoryMock.AssertNotCalled(suite.T(), "GetMembership", mock.Anything, mock.Anything)
+	suite.sessionRepositoryMock.AssertCalled(suite.T(), "UpdateSessionOrganization", "session", int64(0))
+}
+
+// Users cannot switch to organizations they are not a member of
+func (suite *TokenServiceUnitTestSuite) TestSwitchOrganizationNotAMember_ReturnsAnError() {
+
+	suite.organizationRepositoryMock.On("GetMembership", int64(5), int64(12)).Return(&models.OrganizationMembership{}, nil)
+
+	_, err := suite.service.SwitchOrganization(models.AccessTokenClaims{UserId: 12, SessionId: "session"}, 5)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_ORGANIZATION_FOR_ID_ERROR, err.Error())
+	suite.sessionRepositoryMock.AssertNotCalled(suite.T(), "UpdateSessionOrganization", mock.Anything, mock.Anything)
+}
+
+// Personal access tokens have no session to switch
+func (suite *TokenServiceUnitTestSuite) TestSwitchOrganizationWithoutSession_ReturnsAnError() {
+
+	_, err := suite.service.SwitchOrganization(models.AccessTokenClaims{UserId: 12}, 4)
+
+	suite.NotNil(err)
+	suite.Equal(constants.NO_SESSION_FOR_ID_ERROR, err.Error())
+}
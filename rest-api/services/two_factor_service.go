@@ -0,0 +1,338 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"example.com/constants"
+	libInterfaces "example.com/interfaces/lib"
+	repositoryInterfaces "example.com/interfaces/repositories"
+	"example.com/lib"
+	"example.com/models"
+)
+
+const (
+	recoveryCodeCount    = 10
+	mfaChallengeLifetime = time.Minute * 5
+	// Wrong codes accepted for a challenge, the password has to be entered again afterwards. Wrong
+	// codes are also counted as failed logins of the account, so opening new challenges does not
+	// allow guessing more codes than guessing passwords would
+	mfaChallengeMaxFailedAttempts = 5
+)
+
+type TwoFactorService struct {
+	twoFactorRepository repositoryInterfaces.ITwoFactorRepository
+	userRepository      repositoryInterfaces.IUserRepository
+	totpAuthenticator   libInterfaces.ITotpAuthenticator
+}
+
+func (twoFactorService TwoFactorService) IsTwoFactorEnabled(userId int64) (bool, error) {
+	credential, err := twoFactorService.twoFactorRepository.GetTotpCredential(userId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return credential.IsConfirmed(), nil
+}
+
+// Generates a new secret for the user, two factor authentication is only enabled once a code
+// generated with it is confirmed
+func (twoFactorService TwoFactorService) StartEnrollment(userId int64) (*models.TotpEnrollment, error) {
+	user, err := twoFactorService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	enabled, err := twoFactorService.IsTwoFactorEnabled(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return nil, errors.New(constants.TOTP_ALREADY_ENABLED_ERROR)
+	}
+
+	key, err := twoFactorService.totpAuthenticator.GenerateKey(user.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = twoFactorService.twoFactorRepository.SaveTotpCredential(&models.TotpCredential{
+		UserId:    userId,
+		Secret:    key.SealedSecret,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TotpEnrollment{
+		Secret:     key.Secret,
+		OtpauthUri: key.Uri,
+		QrCode:     key.QrCode,
+	}, nil
+}
+
+// Enables two factor authentication once the user proves their app generates valid codes,
+// returns the recovery codes which are not shown again
+func (twoFactorService TwoFactorService) ConfirmEnrollment(userId int64, code string) ([]string, error) {
+	credential, err := twoFactorService.twoFactorRepository.GetTotpCredential(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.UserId == 0 {
+		return nil, errors.New(constants.TOTP_NOT_ENROLLED_ERROR)
+	}
+
+	if credential.IsConfirmed() {
+		return nil, errors.New(constants.TOTP_ALREADY_ENABLED_ERROR)
+	}
+
+	//recovery codes do not exist yet, only codes of the app are accepted
+	validCode, err := twoFactorService.useTotpCode(*credential, code, time.Now().UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !validCode {
+		return nil, errors.New(constants.INVALID_TOTP_CODE_ERROR)
+	}
+
+	recoveryCodes, err := twoFactorService.replaceRecoveryCodes(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = twoFactorService.twoFactorRepository.ConfirmTotpCredential(userId, time.Now().UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// Turns two factor authentication off, a valid code or recovery code is required so a stolen
+// access token is not enough
+func (twoFactorService TwoFactorService) DisableTwoFactor(userId int64, code string) error {
+	credential, err := twoFactorService.twoFactorRepository.GetTotpCredential(userId)
+
+	if err != nil {
+		return err
+	}
+
+	if !credential.IsConfirmed() {
+		return errors.New(constants.TOTP_NOT_ENABLED_ERROR)
+	}
+
+	validCode, err := twoFactorService.useCode(*credential, code, time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	if !validCode {
+		return errors.New(constants.INVALID_TOTP_CODE_ERROR)
+	}
+
+	err = twoFactorService.twoFactorRepository.DeleteTotpCredential(userId)
+
+	if err != nil {
+		return err
+	}
+
+	return twoFactorService.twoFactorRepository.DeleteRecoveryCodes(userId)
+}
+
+// Issues the short lived token exchanged along with a code for the access token, once the
+// password of a user with two factor authentication was validated
+func (twoFactorService TwoFactorService) CreateMfaChallenge(userId int64) (*models.MfaChallengeToken, error) {
+	token, tokenHash, err := lib.GenerateOpaqueToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	err = twoFactorService.twoFactorRepository.CreateMfaChallenge(&models.MfaChallenge{
+		UserId:    userId,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(mfaChallengeLifetime),
+		CreatedAt: now,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MfaChallengeToken{
+		Token:     token,
+		ExpiresIn: int64(mfaChallengeLifetime.Seconds()),
+	}, nil
+}
+
+// Returns the user a challenge that can still be completed belongs to, so the failed attempts of
+// the account can be checked before the code is
+func (twoFactorService TwoFactorService) GetMfaChallengeUser(token string) (*models.User, error) {
+	challenge, err := twoFactorService.twoFactorRepository.GetMfaChallengeByHash(lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !challenge.IsUsable(time.Now().UTC(), mfaChallengeMaxFailedAttempts) {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	user, err := twoFactorService.userRepository.GetUserById(challenge.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	return user, nil
+}
+
+// Consumes the challenge when the code is valid, returning the user tokens can be issued to
+func (twoFactorService TwoFactorService) CompleteMfaChallenge(token, code string) (*models.User, error) {
+	challenge, err := twoFactorService.twoFactorRepository.GetMfaChallengeByHash(lib.HashOpaqueToken(token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if !challenge.IsUsable(now, mfaChallengeMaxFailedAttempts) {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	credential, err := twoFactorService.twoFactorRepository.GetTotpCredential(challenge.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//two factor authentication was disabled since the password was validated
+	if !credential.IsConfirmed() {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	validCode, err := twoFactorService.useCode(*credential, code, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !validCode {
+		err = twoFactorService.twoFactorRepository.RecordMfaChallengeFailure(challenge.Id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New(constants.INVALID_TOTP_CODE_ERROR)
+	}
+
+	consumed, err := twoFactorService.twoFactorRepository.MarkMfaChallengeUsed(challenge.Id, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//another request used the challenge in the meantime
+	if !consumed {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	user, err := twoFactorService.userRepository.GetUserById(challenge.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.INVALID_MFA_TOKEN_ERROR)
+	}
+
+	if user.IsDisabled() {
+		return nil, errors.New(constants.ACCOUNT_DISABLED_ERROR)
+	}
+
+	return user, nil
+}
+
+// Accepts a code of the app or an unused recovery code
+func (twoFactorService TwoFactorService) useCode(credential models.TotpCredential, code string, now time.Time) (bool, error) {
+	validCode, err := twoFactorService.useTotpCode(credential, code, now)
+
+	if err != nil || validCode {
+		return validCode, err
+	}
+
+	return twoFactorService.twoFactorRepository.UseRecoveryCode(credential.UserId, lib.HashRecoveryCode(code), now)
+}
+
+// Codes are refused once a code of the same period was used, so an intercepted code cannot be replayed
+func (twoFactorService TwoFactorService) useTotpCode(credential models.TotpCredential, code string, now time.Time) (bool, error) {
+	step, validCode := twoFactorService.totpAuthenticator.ValidateCode(credential.Secret, code, now)
+
+	if !validCode {
+		return false, nil
+	}
+
+	return twoFactorService.twoFactorRepository.UseTotpStep(credential.UserId, step)
+}
+
+func (twoFactorService TwoFactorService) replaceRecoveryCodes(userId int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	codeHashes := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		code, codeHash, err := lib.GenerateRecoveryCode()
+
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, codeHash)
+	}
+
+	err := twoFactorService.twoFactorRepository.ReplaceRecoveryCodes(userId, codeHashes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func NewTwoFactorService(
+	twoFactorRepository repositoryInterfaces.ITwoFactorRepository,
+	userRepository repositoryInterfaces.IUserRepository,
+	totpAuthenticator libInterfaces.ITotpAuthenticator) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepository: twoFactorRepository,
+		userRepository:      userRepository,
+		totpAuthenticator:   totpAuthenticator,
+	}
+}
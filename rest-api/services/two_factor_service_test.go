@@ -0,0 +1,256 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"example.com/constants"
+	"example.com/lib"
+	"example.com/mocks"
+	"example.com/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TwoFactorServiceUnitTestSuite struct {
+	suite.Suite
+	twoFactorRepositoryMock mocks.ITwoFactorRepository
+	userRepositoryMock      mocks.IUserRepository
+	totpAuthenticatorMock   mocks.ITotpAuthenticator
+	service                 *TwoFactorService
+}
+
+func TestTwoFactorServiceUnitTestSuite(t *testing.T) {
+	suite.Run(t, &TwoFactorServiceUnitTestSuite{})
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) SetupTest() {
+	suite.twoFactorRepositoryMock = mocks.ITwoFactorRepository{}
+	suite.userRepositoryMock = mocks.IUserRepository{}
+	suite.totpAuthenticatorMock = mocks.ITotpAuthenticator{}
+
+	suite.service = NewTwoFactorService(&suite.twoFactorRepositoryMock, &suite.userRepositoryMock, &suite.totpAuthenticatorMock)
+}
+
+func confirmedCredential() *models.TotpCredential {
+	confirmedAt := time.Now()
+
+	return &models.TotpCredential{
+		UserId:      12,
+		Secret:      "SECRET",
+		ConfirmedAt: &confirmedAt,
+	}
+}
+
+// When two factor authentication is already enabled, enrolling again is refused
+func (suite *TwoFactorServiceUnitTestSuite) TestStartEnrollmentAlreadyEnabled_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(confirmedCredential(), nil)
+
+	enrollment, err := suite.service.StartEnrollment(12)
+
+	suite.Nil(enrollment)
+	suite.Equal(constants.TOTP_ALREADY_ENABLED_ERROR, err.Error())
+	suite.twoFactorRepositoryMock.AssertNotCalled(suite.T(), "SaveTotpCredential", mock.Anything)
+}
+
+// The user is shown the secret, only its encrypted form is stored
+func (suite *TwoFactorServiceUnitTestSuite) TestStartEnrollment_SavesTheSealedSecret() {
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(&models.TotpCredential{}, nil)
+	suite.totpAuthenticatorMock.On("GenerateKey", mock.Anything).Return(&models.TotpKey{
+		Secret:       "SECRET",
+		SealedSecret: "v1:sealed",
+		Uri:          "otpauth://totp/test",
+		QrCode:       "qr code",
+	}, nil)
+	suite.twoFactorRepositoryMock.On("SaveTotpCredential", mock.Anything).Return(nil)
+
+	enrollment, err := suite.service.StartEnrollment(12)
+
+	suite.Nil(err)
+	suite.Equal("otpauth://totp/test", enrollment.OtpauthUri)
+	suite.Equal("SECRET", enrollment.Secret)
+	suite.totpAuthenticatorMock.AssertCalled(suite.T(), "GenerateKey", "test@test.com")
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "SaveTotpCredential", mock.MatchedBy(func(credential *models.TotpCredential) bool {
+		return credential.UserId == 12 && credential.Secret == "v1:sealed" && credential.ConfirmedAt == nil
+	}))
+}
+
+// When the code is invalid, the enrollment stays unconfirmed
+func (suite *TwoFactorServiceUnitTestSuite) TestConfirmEnrollmentInvalidCode_ReturnsAnError() {
+
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(&models.TotpCredential{UserId: 12, Secret: "SECRET"}, nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), false)
+
+	recoveryCodes, err := suite.service.ConfirmEnrollment(12, "123456")
+
+	suite.Nil(recoveryCodes)
+	suite.Equal(constants.INVALID_TOTP_CODE_ERROR, err.Error())
+	suite.twoFactorRepositoryMock.AssertNotCalled(suite.T(), "ConfirmTotpCredential", mock.Anything, mock.Anything)
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) TestConfirmEnrollmentNotStarted_ReturnsAnError() {
+
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(&models.TotpCredential{}, nil)
+
+	_, err := suite.service.ConfirmEnrollment(12, "123456")
+
+	suite.Equal(constants.TOTP_NOT_ENROLLED_ERROR, err.Error())
+}
+
+// Confirming stores the hashes of the recovery codes and returns the codes
+func (suite *TwoFactorServiceUnitTestSuite) TestConfirmEnrollment_ReturnsRecoveryCodes() {
+
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(&models.TotpCredential{UserId: 12, Secret: "SECRET"}, nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(100), true)
+	suite.twoFactorRepositoryMock.On("UseTotpStep", mock.Anything, mock.Anything).Return(true, nil)
+	suite.twoFactorRepositoryMock.On("ReplaceRecoveryCodes", mock.Anything, mock.Anything).Return(nil)
+	suite.twoFactorRepositoryMock.On("ConfirmTotpCredential", mock.Anything, mock.Anything).Return(nil)
+
+	recoveryCodes, err := suite.service.ConfirmEnrollment(12, "123456")
+
+	suite.Nil(err)
+	suite.Len(recoveryCodes, recoveryCodeCount)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "UseTotpStep", int64(12), int64(100))
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "ReplaceRecoveryCodes", int64(12), mock.MatchedBy(func(codeHashes []string) bool {
+		return len(codeHashes) == recoveryCodeCount && codeHashes[0] == lib.HashRecoveryCode(recoveryCodes[0])
+	}))
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "ConfirmTotpCredential", int64(12), mock.Anything)
+}
+
+// A code of a period that was already used is refused
+func (suite *TwoFactorServiceUnitTestSuite) TestDisableTwoFactorReplayedCode_ReturnsAnError() {
+
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(confirmedCredential(), nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(100), true)
+	suite.twoFactorRepositoryMock.On("UseTotpStep", mock.Anything, mock.Anything).Return(false, nil)
+	suite.twoFactorRepositoryMock.On("UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := suite.service.DisableTwoFactor(12, "123456")
+
+	suite.Equal(constants.INVALID_TOTP_CODE_ERROR, err.Error())
+	suite.twoFactorRepositoryMock.AssertNotCalled(suite.T(), "DeleteTotpCredential", mock.Anything)
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) TestDisableTwoFactorWithRecoveryCode_DeletesTheCredential() {
+
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(confirmedCredential(), nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), false)
+	suite.twoFactorRepositoryMock.On("UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	suite.twoFactorRepositoryMock.On("DeleteTotpCredential", mock.Anything).Return(nil)
+	suite.twoFactorRepositoryMock.On("DeleteRecoveryCodes", mock.Anything).Return(nil)
+
+	err := suite.service.DisableTwoFactor(12, "ABCD-efgh-ijkl-mnop")
+
+	suite.Nil(err)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "UseRecoveryCode", int64(12), lib.HashRecoveryCode("abcdefghijklmnop"), mock.Anything)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "DeleteTotpCredential", int64(12))
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "DeleteRecoveryCodes", int64(12))
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) TestCreateMfaChallenge_StoresTheTokenHash() {
+
+	suite.twoFactorRepositoryMock.On("CreateMfaChallenge", mock.Anything).Return(nil)
+
+	challenge, err := suite.service.CreateMfaChallenge(12)
+
+	suite.Nil(err)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "CreateMfaChallenge", mock.MatchedBy(func(saved *models.MfaChallenge) bool {
+		return saved.UserId == 12 && saved.TokenHash == lib.HashOpaqueToken(challenge.Token)
+	}))
+}
+
+// A challenge that cannot be completed anymore does not reveal its user
+func (suite *TwoFactorServiceUnitTestSuite) TestGetMfaChallengeUserExpiredChallenge_ReturnsAnError() {
+
+	suite.twoFactorRepositoryMock.On("GetMfaChallengeByHash", mock.Anything).Return(&models.MfaChallenge{
+		Id:        1,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	user, err := suite.service.GetMfaChallengeUser("mfa token")
+
+	suite.Nil(user)
+	suite.Equal(constants.INVALID_MFA_TOKEN_ERROR, err.Error())
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "GetUserById", mock.Anything)
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) TestGetMfaChallengeUser_ReturnsTheUser() {
+
+	suite.twoFactorRepositoryMock.On("GetMfaChallengeByHash", mock.Anything).Return(&models.MfaChallenge{
+		Id:        1,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+
+	user, err := suite.service.GetMfaChallengeUser("mfa token")
+
+	suite.Nil(err)
+	suite.Equal("test@test.com", user.Email)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "GetMfaChallengeByHash", lib.HashOpaqueToken("mfa token"))
+	suite.userRepositoryMock.AssertCalled(suite.T(), "GetUserById", int64(12))
+}
+
+// When the challenge already failed too many times, it is refused without checking the code
+func (suite *TwoFactorServiceUnitTestSuite) TestCompleteMfaChallengeTooManyFailures_ReturnsAnError() {
+
+	suite.twoFactorRepositoryMock.On("GetMfaChallengeByHash", mock.Anything).Return(&models.MfaChallenge{
+		Id:             1,
+		UserId:         12,
+		FailedAttempts: mfaChallengeMaxFailedAttempts,
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}, nil)
+
+	user, err := suite.service.CompleteMfaChallenge("mfa token", "123456")
+
+	suite.Nil(user)
+	suite.Equal(constants.INVALID_MFA_TOKEN_ERROR, err.Error())
+	suite.totpAuthenticatorMock.AssertNotCalled(suite.T(), "ValidateCode", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// When the code is invalid, the failure is counted against the challenge
+func (suite *TwoFactorServiceUnitTestSuite) TestCompleteMfaChallengeInvalidCode_RecordsTheFailure() {
+
+	suite.twoFactorRepositoryMock.On("GetMfaChallengeByHash", mock.Anything).Return(&models.MfaChallenge{
+		Id:        1,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(confirmedCredential(), nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(0), false)
+	suite.twoFactorRepositoryMock.On("UseRecoveryCode", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	suite.twoFactorRepositoryMock.On("RecordMfaChallengeFailure", mock.Anything).Return(nil)
+
+	user, err := suite.service.CompleteMfaChallenge("mfa token", "123456")
+
+	suite.Nil(user)
+	suite.Equal(constants.INVALID_TOTP_CODE_ERROR, err.Error())
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "RecordMfaChallengeFailure", int64(1))
+	suite.twoFactorRepositoryMock.AssertNotCalled(suite.T(), "MarkMfaChallengeUsed", mock.Anything, mock.Anything)
+}
+
+func (suite *TwoFactorServiceUnitTestSuite) TestCompleteMfaChallenge_ReturnsTheUser() {
+
+	suite.twoFactorRepositoryMock.On("GetMfaChallengeByHash", mock.Anything).Return(&models.MfaChallenge{
+		Id:        1,
+		UserId:    12,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	suite.twoFactorRepositoryMock.On("GetTotpCredential", mock.Anything).Return(confirmedCredential(), nil)
+	suite.totpAuthenticatorMock.On("ValidateCode", mock.Anything, mock.Anything, mock.Anything).Return(int64(100), true)
+	suite.twoFactorRepositoryMock.On("UseTotpStep", mock.Anything, mock.Anything).Return(true, nil)
+	suite.twoFactorRepositoryMock.On("MarkMfaChallengeUsed", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+
+	user, err := suite.service.CompleteMfaChallenge("mfa token", "123456")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), user.Id)
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "GetMfaChallengeByHash", lib.HashOpaqueToken("mfa token"))
+	suite.twoFactorRepositoryMock.AssertCalled(suite.T(), "MarkMfaChallengeUsed", int64(1), mock.Anything)
+}
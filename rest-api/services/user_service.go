@@ -1,14 +1,24 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/config"
+	"example.com/constants"
 	libInterfaces "example.com/interfaces/lib"
 	repositoryInterfaces "example.com/interfaces/repositories"
 	"example.com/models"
 )
 
 type UserService struct {
-	userRepository repositoryInterfaces.IUserRepository
-	passwordHasher libInterfaces.IHasher
+	userRepository         repositoryInterfaces.IUserRepository
+	userProfileRepository  repositoryInterfaces.IUserProfileRepository
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository
+	passwordHasher         libInterfaces.IHasher
+	accountDeletionPolicy  string
 }
 
 func (userService UserService) CreateUser(user *models.User) error {
@@ -16,7 +26,7 @@ func (userService UserService) CreateUser(user *models.User) error {
 	hashedPassword, err := userService.passwordHasher.HashPassword(user.Password)
 
 	if err != nil {
-		return nil
+		return err
 	}
 
 	user.Password = hashedPassword
@@ -30,28 +40,288 @@ func (userService UserService) CreateUser(user *models.User) error {
 	return nil
 }
 
+// Unknown emails are reported as invalid credentials, after comparing the password against
+// a dummy hash so they take as long as a wrong password
 func (userService UserService) ValidateCredentials(user *models.User) (bool, error) {
 
 	savedUser, err := userService.userRepository.GetUserByEmail(user.Email)
 
+	if err != nil && err.Error() == constants.NO_USER_FOR_EMAIL_ERROR {
+		userService.passwordHasher.ValidatePasswordHash(user.Password, userService.passwordHasher.DummyPasswordHash())
+		return false, nil
+	}
+
 	if err != nil {
 		return false, err
 	}
 
 	if savedUser.Id == 0 {
+		userService.passwordHasher.ValidatePasswordHash(user.Password, userService.passwordHasher.DummyPasswordHash())
 		return false, nil
 	}
 
 	user.Id = savedUser.Id
+	user.Role = savedUser.Role
 
-	return userService.passwordHasher.ValidatePasswordHash(
+	validPassword := userService.passwordHasher.ValidatePasswordHash(
 		user.Password,
-		savedUser.Password), nil
+		savedUser.Password)
+
+	//only reported along with a valid password, so it does not reveal which accounts are disabled
+	if validPassword && savedUser.IsDisabled() {
+		return false, errors.New(constants.ACCOUNT_DISABLED_ERROR)
+	}
+
+	if validPassword && userService.passwordHasher.NeedsRehash(savedUser.Password) {
+		userService.rehashPassword(*savedUser, user.Password)
+	}
+
+	return validPassword, nil
+}
+
+func (userService UserService) GetUsers() ([]models.User, error) {
+	return userService.userRepository.GetUsers()
+}
+
+// Disabled users cannot log in nor refresh their tokens, their refresh tokens are revoked right away
+// while access tokens already issued stay valid until they expire
+func (userService UserService) SetUserDisabled(id int64, disabled bool) error {
+	if !disabled {
+		return userService.userRepository.SetUserDisabledAt(id, nil)
+	}
+
+	now := time.Now().UTC()
+
+	err := userService.userRepository.SetUserDisabledAt(id, &now)
+
+	if err != nil {
+		return err
+	}
+
+	return userService.refreshTokenRepository.RevokeRefreshTokensByUserId(id, now)
+}
+
+// Assigns the role to the user with the email. Refresh tokens are revoked so the user logs in again
+// and gets an access token carrying the permissions of the new role
+func (userService UserService) AssignRole(email, role string) error {
+	if !models.IsValidRole(role) {
+		return errors.New(constants.INVALID_ROLE_ERROR)
+	}
+
+	user, err := userService.userRepository.GetUserByEmail(email)
+
+	if err != nil {
+		return err
+	}
+
+	err = userService.userRepository.UpdateUserRole(user.Id, role)
+
+	if err != nil {
+		return err
+	}
+
+	return userService.refreshTokenRepository.RevokeRefreshTokensByUserId(user.Id, time.Now().UTC())
+}
+
+func (userService UserService) GetProfile(userId int64) (*models.UserProfile, error) {
+	user, err := userService.getUser(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userService.getProfile(*user)
+}
+
+// Updates the fields provided in the request, keeping the others as they are
+func (userService UserService) UpdateProfile(userId int64, request models.UpdateProfileRequest) (*models.UserProfile, error) {
+	user, err := userService.getUser(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := userService.getProfile(*user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if request.DisplayName != nil {
+		profile.DisplayName = strings.TrimSpace(*request.DisplayName)
+	}
+
+	if request.AvatarUrl != nil {
+		profile.AvatarUrl = *request.AvatarUrl
+	}
+
+	if preferences := request.NotificationPreferences; preferences != nil {
+		if preferences.EventUpdates != nil {
+			profile.NotificationPreferences.EventUpdates = *preferences.EventUpdates
+		}
+
+		if preferences.Announcements != nil {
+			profile.NotificationPreferences.Announcements = *preferences.Announcements
+		}
+
+		if preferences.CommentReplies != nil {
+			profile.NotificationPreferences.CommentReplies = *preferences.CommentReplies
+		}
+	}
+
+	now := time.Now().UTC()
+
+	profile.UpdatedAt = &now
+
+	err = userService.userProfileRepository.SaveUserProfile(*profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Changes the password once the current one is confirmed. Every refresh token of the user is
+// revoked, so other sessions have to log in with the new password
+func (userService UserService) ChangePassword(userId int64, currentPassword, newPassword string) (*models.User, error) {
+	user, err := userService.reauthenticate(userId, currentPassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := userService.passwordHasher.HashPassword(newPassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = userService.userRepository.UpdateUserPassword(user.Id, hashedPassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = userService.refreshTokenRepository.RevokeRefreshTokensByUserId(user.Id, time.Now().UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Checks the password and that no other account uses the new email. The email itself only
+// changes once the user confirms it from the link sent to the new address
+func (userService UserService) RequestEmailChange(userId int64, password, newEmail string) (*models.User, error) {
+	user, err := userService.reauthenticate(userId, password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existingUser, err := userService.userRepository.GetUserByEmail(newEmail)
+
+	if err != nil && err.Error() != constants.NO_USER_FOR_EMAIL_ERROR {
+		return nil, err
+	}
+
+	if err == nil && existingUser.Id != 0 {
+		return nil, errors.New(constants.EMAIL_ALREADY_IN_USE_ERROR)
+	}
+
+	return user, nil
+}
+
+// Deletes the account once the password is confirmed. Credentials and settings are always removed,
+// the events and registrations of the user are anonymized or deleted depending on the configured
+// ACCOUNT_DELETION_POLICY
+func (userService UserService) DeleteAccount(userId int64, password string) error {
+	user, err := userService.reauthenticate(userId, password)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	if userService.accountDeletionPolicy == config.CASCADE_ACCOUNT_DELETION {
+		return userService.userRepository.DeleteUser(user.Id, now)
+	}
+
+	return userService.userRepository.AnonymizeUser(user.Id, fmt.Sprintf("deleted-%v@deleted.invalid", user.Id), now)
+}
+
+// Upgrades a hash created with outdated settings while the password is at hand. The login goes
+// on with the old hash when this fails, it is upgraded on a later login instead
+func (userService UserService) rehashPassword(user models.User, password string) {
+	hashedPassword, err := userService.passwordHasher.HashPassword(password)
+
+	if err != nil {
+		return
+	}
+
+	userService.userRepository.ReplaceUserPasswordHash(user.Id, user.Password, hashedPassword)
+}
+
+func (userService UserService) getUser(userId int64) (*models.User, error) {
+	user, err := userService.userRepository.GetUserById(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == 0 {
+		return nil, errors.New(constants.NO_USER_FOR_ID_ERROR)
+	}
+
+	return user, nil
+}
+
+func (userService UserService) getProfile(user models.User) (*models.UserProfile, error) {
+	profile, err := userService.userProfileRepository.GetUserProfile(user.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Email = user.Email
+	profile.EmailVerified = user.IsVerified()
+
+	return profile, nil
+}
+
+// Confirms the password of the user before sensitive changes. Users who only ever logged in
+// through an identity provider have no password to confirm
+func (userService UserService) reauthenticate(userId int64, password string) (*models.User, error) {
+	user, err := userService.getUser(userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Password == "" {
+		return nil, errors.New(constants.PASSWORD_NOT_SET_ERROR)
+	}
+
+	if !userService.passwordHasher.ValidatePasswordHash(password, user.Password) {
+		return nil, errors.New(constants.INVALID_PASSWORD_ERROR)
+	}
+
+	return user, nil
 }
 
-func NewUserService(userRepository repositoryInterfaces.IUserRepository, passwordHasher libInterfaces.IHasher) *UserService {
+func NewUserService(
+	userRepository repositoryInterfaces.IUserRepository,
+	userProfileRepository repositoryInterfaces.IUserProfileRepository,
+	refreshTokenRepository repositoryInterfaces.IRefreshTokenRepository,
+	passwordHasher libInterfaces.IHasher) *UserService {
 	return &UserService{
-		userRepository: userRepository,
-		passwordHasher: passwordHasher,
+		userRepository:         userRepository,
+		userProfileRepository:  userProfileRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		passwordHasher:         passwordHasher,
+		accountDeletionPolicy:  config.AppConfiguration().AccountDeletionPolicy(),
 	}
 }
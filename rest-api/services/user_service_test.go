@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"example.com/config"
+	"example.com/constants"
 	"example.com/mocks"
 	"example.com/models"
 	"github.com/stretchr/testify/mock"
@@ -13,21 +16,29 @@ import (
 
 type UserServiceUnitTestSuite struct {
 	suite.Suite
-	passwordHasherMock mocks.IHasher
-	userRepositoryMock mocks.IUserRepository
-	service            *UserService
+	passwordHasherMock         mocks.IHasher
+	userRepositoryMock         mocks.IUserRepository
+	refreshTokenRepositoryMock mocks.IRefreshTokenRepository
+	userProfileRepositoryMock  mocks.IUserProfileRepository
+	service                    *UserService
 }
 
 func TestUserServiceUnitTestSuite(t *testing.T) {
-	suite.Run(t, &EventServiceUnitTestSuite{})
+	suite.Run(t, &UserServiceUnitTestSuite{})
 }
 
 func (suite *UserServiceUnitTestSuite) SetupTest() {
 	suite.userRepositoryMock = mocks.IUserRepository{}
 	suite.passwordHasherMock = mocks.IHasher{}
+	suite.refreshTokenRepositoryMock = mocks.IRefreshTokenRepository{}
+	suite.userProfileRepositoryMock = mocks.IUserProfileRepository{}
+
+	suite.passwordHasherMock.On("DummyPasswordHash").Return("dummy hash")
 
 	suite.service = NewUserService(
 		&suite.userRepositoryMock,
+		&suite.userProfileRepositoryMock,
+		&suite.refreshTokenRepositoryMock,
 		&suite.passwordHasherMock)
 }
 
@@ -35,14 +46,14 @@ func (suite *UserServiceUnitTestSuite) TestCreateUser_AttemptsToHashTheRawPasswo
 
 	expectedPassword := "some password"
 
-	suite.userRepositoryMock.On("HashPassword", mock.Anything).Return(nil, errors.New("test"))
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("", errors.New("test"))
 
 	suite.service.CreateUser(&models.User{
 		Password: expectedPassword,
 	})
 
-	suite.userRepositoryMock.AssertCalled(suite.T(), "HashPassword", expectedPassword)
-	suite.userRepositoryMock.AssertNumberOfCalls(suite.T(), "HashPassword", 1)
+	suite.passwordHasherMock.AssertCalled(suite.T(), "HashPassword", expectedPassword)
+	suite.passwordHasherMock.AssertNumberOfCalls(suite.T(), "HashPassword", 1)
 }
 
 // When failing to hash the password, pass up the error
@@ -50,7 +61,7 @@ func (suite *UserServiceUnitTestSuite) TestCreateUser_ReturnsAnError() {
 
 	expectedError := errors.New("test")
 
-	suite.userRepositoryMock.On("HashPassword", mock.Anything).Return(nil, expectedError)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("", expectedError)
 
 	err := suite.service.CreateUser(&models.User{
 		Password: "some password",
@@ -62,7 +73,7 @@ func (suite *UserServiceUnitTestSuite) TestCreateUser_ReturnsAnError() {
 
 func (suite *UserServiceUnitTestSuite) TestCreateUser_AttemptsToCreateTheUser() {
 
-	suite.userRepositoryMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
 	suite.userRepositoryMock.On("CreateUser", mock.Anything).Return(errors.New("test"))
 
 	suite.service.CreateUser(&models.User{
@@ -78,7 +89,7 @@ func (suite *UserServiceUnitTestSuite) TestCreateUserWhenFailingToCreateTheUser_
 
 	expectedError := errors.New("test")
 
-	suite.userRepositoryMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
 	suite.userRepositoryMock.On("CreateUser", mock.Anything).Return(expectedError)
 
 	err := suite.service.CreateUser(&models.User{
@@ -91,7 +102,7 @@ func (suite *UserServiceUnitTestSuite) TestCreateUserWhenFailingToCreateTheUser_
 
 func (suite *UserServiceUnitTestSuite) TestCreateUser_ReturnsNil() {
 
-	suite.userRepositoryMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("some hashed password", nil)
 	suite.userRepositoryMock.On("CreateUser", mock.Anything).Return(nil)
 
 	err := suite.service.CreateUser(&models.User{
@@ -134,7 +145,8 @@ func (suite *UserServiceUnitTestSuite) TestValidateCredentials_ReturnsAnError()
 
 func (suite *UserServiceUnitTestSuite) TestValidateCredentialsWhenNoUserIsFound_ReturnsNil() {
 
-	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(nil, nil)
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(false)
 
 	validCredentials, err := suite.service.ValidateCredentials(&models.User{
 		Password: "some password",
@@ -177,6 +189,7 @@ func (suite *UserServiceUnitTestSuite) TestValidateCredentials_ReturnsTheValidat
 	}, nil)
 
 	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(expectedValidationResult)
+	suite.passwordHasherMock.On("NeedsRehash", mock.Anything).Return(false)
 
 	validationResult, err := suite.service.ValidateCredentials(&models.User{
 		Password: "some password",
@@ -186,3 +199,342 @@ func (suite *UserServiceUnitTestSuite) TestValidateCredentials_ReturnsTheValidat
 	suite.Nil(err)
 	suite.Equal(expectedValidationResult, validationResult)
 }
+
+// Unknown emails cannot be told apart from wrong passwords, a hash is compared in both cases
+func (suite *UserServiceUnitTestSuite) TestValidateCredentialsUnknownEmail_ComparesADummyHash() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR))
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(false)
+
+	validCredentials, err := suite.service.ValidateCredentials(&models.User{
+		Password: "some password",
+		Email:    "some email",
+	})
+
+	suite.False(validCredentials)
+	suite.Nil(err)
+	suite.passwordHasherMock.AssertCalled(suite.T(), "ValidatePasswordHash", "some password", "dummy hash")
+}
+
+// When the password is valid but the account is disabled, return an error
+func (suite *UserServiceUnitTestSuite) TestValidateCredentialsDisabledUser_ReturnsAnError() {
+
+	disabledAt := time.Now()
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{
+		Id:         1,
+		Email:      "some email",
+		Password:   "some password",
+		DisabledAt: &disabledAt,
+	}, nil)
+
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+
+	validationResult, err := suite.service.ValidateCredentials(&models.User{
+		Password: "some password",
+		Email:    "some email",
+	})
+
+	suite.False(validationResult)
+	suite.NotNil(err)
+	suite.Equal(constants.ACCOUNT_DISABLED_ERROR, err.Error())
+}
+
+// Hashes created with outdated settings are replaced with a hash of the password just validated
+func (suite *UserServiceUnitTestSuite) TestValidateCredentialsOutdatedHash_RehashesThePassword() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{
+		Id:       1,
+		Email:    "some email",
+		Password: "bcrypt hash",
+	}, nil)
+	suite.userRepositoryMock.On("ReplaceUserPasswordHash", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.passwordHasherMock.On("NeedsRehash", "bcrypt hash").Return(true)
+	suite.passwordHasherMock.On("HashPassword", "some password").Return("argon2id hash", nil)
+
+	validationResult, err := suite.service.ValidateCredentials(&models.User{
+		Password: "some password",
+		Email:    "some email",
+	})
+
+	suite.Nil(err)
+	suite.True(validationResult)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "ReplaceUserPasswordHash", int64(1), "bcrypt hash", "argon2id hash")
+}
+
+// Failing to upgrade the hash does not prevent logging in
+func (suite *UserServiceUnitTestSuite) TestValidateCredentialsRehashFails_ReturnsTheValidationResult() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{
+		Id:       1,
+		Email:    "some email",
+		Password: "bcrypt hash",
+	}, nil)
+
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.passwordHasherMock.On("NeedsRehash", mock.Anything).Return(true)
+	suite.passwordHasherMock.On("HashPassword", mock.Anything).Return("", errors.New("test"))
+
+	validationResult, err := suite.service.ValidateCredentials(&models.User{
+		Password: "some password",
+		Email:    "some email",
+	})
+
+	suite.Nil(err)
+	suite.True(validationResult)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "ReplaceUserPasswordHash", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Wrong passwords never lead to a new hash
+func (suite *UserServiceUnitTestSuite) TestValidateCredentialsInvalidPassword_DoesNotRehash() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{
+		Id:       1,
+		Email:    "some email",
+		Password: "bcrypt hash",
+	}, nil)
+
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(false)
+
+	validationResult, _ := suite.service.ValidateCredentials(&models.User{
+		Password: "wrong password",
+		Email:    "some email",
+	})
+
+	suite.False(validationResult)
+	suite.passwordHasherMock.AssertNotCalled(suite.T(), "NeedsRehash", mock.Anything)
+	suite.passwordHasherMock.AssertNotCalled(suite.T(), "HashPassword", mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestValidateCredentials_SetsTheRole() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{
+		Id:       1,
+		Email:    "some email",
+		Password: "some password",
+		Role:     models.ADMIN_ROLE,
+	}, nil)
+
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.passwordHasherMock.On("NeedsRehash", mock.Anything).Return(false)
+
+	user := models.User{
+		Password: "some password",
+		Email:    "some email",
+	}
+
+	suite.service.ValidateCredentials(&user)
+
+	suite.Equal(int64(1), user.Id)
+	suite.Equal(models.ADMIN_ROLE, user.Role)
+}
+
+func (suite *UserServiceUnitTestSuite) TestSetUserDisabled_RevokesTheRefreshTokens() {
+
+	suite.userRepositoryMock.On("SetUserDisabledAt", mock.Anything, mock.Anything).Return(nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.SetUserDisabled(12, true)
+
+	suite.Nil(err)
+	suite.NotNil(suite.userRepositoryMock.Calls[0].Arguments.Get(1))
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+}
+
+// When enabling the user back, the disabled date is cleared
+func (suite *UserServiceUnitTestSuite) TestSetUserEnabled_ClearsTheDisabledDate() {
+
+	suite.userRepositoryMock.On("SetUserDisabledAt", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.SetUserDisabled(12, false)
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "SetUserDisabledAt", int64(12), (*time.Time)(nil))
+	suite.refreshTokenRepositoryMock.AssertNotCalled(suite.T(), "RevokeRefreshTokensByUserId", mock.Anything, mock.Anything)
+}
+
+// When the role does not exist, return an error without updating the user
+func (suite *UserServiceUnitTestSuite) TestAssignRoleUnknownRole_ReturnsAnError() {
+
+	err := suite.service.AssignRole("some email", "superuser")
+
+	suite.NotNil(err)
+	suite.Equal(constants.INVALID_ROLE_ERROR, err.Error())
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "UpdateUserRole", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestAssignRole_UpdatesTheRole() {
+
+	suite.userRepositoryMock.On("GetUserByEmail", mock.Anything).Return(&models.User{Id: 12}, nil)
+	suite.userRepositoryMock.On("UpdateUserRole", mock.Anything, mock.Anything).Return(nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.AssignRole("some email", models.ADMIN_ROLE)
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "UpdateUserRole", int64(12), models.ADMIN_ROLE)
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestGetProfile_IncludesTheEmail() {
+
+	verifiedAt := time.Now()
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Email: "test@test.com", VerifiedAt: &verifiedAt}, nil)
+	suite.userProfileRepositoryMock.On("GetUserProfile", int64(12)).Return(&models.UserProfile{UserId: 12, DisplayName: "Test"}, nil)
+
+	profile, err := suite.service.GetProfile(12)
+
+	suite.Nil(err)
+	suite.Equal("test@test.com", profile.Email)
+	suite.True(profile.EmailVerified)
+	suite.Equal("Test", profile.DisplayName)
+}
+
+func (suite *UserServiceUnitTestSuite) TestGetProfileUnknownUser_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", mock.Anything).Return(&models.User{}, nil)
+
+	_, err := suite.service.GetProfile(12)
+
+	suite.EqualError(err, constants.NO_USER_FOR_ID_ERROR)
+}
+
+// Fields left out of the request keep their value
+func (suite *UserServiceUnitTestSuite) TestUpdateProfile_UpdatesTheProvidedFields() {
+
+	displayName := "  New name "
+	announcements := false
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Email: "test@test.com"}, nil)
+	suite.userProfileRepositoryMock.On("GetUserProfile", int64(12)).Return(&models.UserProfile{
+		UserId:                  12,
+		DisplayName:             "Old name",
+		AvatarUrl:               "https://test.com/avatar.png",
+		NotificationPreferences: models.DefaultNotificationPreferences(),
+	}, nil)
+	suite.userProfileRepositoryMock.On("SaveUserProfile", mock.Anything).Return(nil)
+
+	profile, err := suite.service.UpdateProfile(12, models.UpdateProfileRequest{
+		DisplayName: &displayName,
+		NotificationPreferences: &models.UpdateNotificationPreferences{
+			Announcements: &announcements,
+		},
+	})
+
+	suite.Nil(err)
+
+	savedProfile := suite.userProfileRepositoryMock.Calls[1].Arguments.Get(0).(models.UserProfile)
+
+	suite.Equal("New name", savedProfile.DisplayName)
+	suite.Equal("https://test.com/avatar.png", savedProfile.AvatarUrl)
+	suite.False(savedProfile.NotificationPreferences.Announcements)
+	suite.True(savedProfile.NotificationPreferences.EventUpdates)
+	suite.NotNil(savedProfile.UpdatedAt)
+	suite.Equal("test@test.com", profile.Email)
+}
+
+// The new password is stored and every session of the user is revoked
+func (suite *UserServiceUnitTestSuite) TestChangePassword_RevokesTheRefreshTokens() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "current hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", "current", "current hash").Return(true)
+	suite.passwordHasherMock.On("HashPassword", "new").Return("new hash", nil)
+	suite.userRepositoryMock.On("UpdateUserPassword", mock.Anything, mock.Anything).Return(nil)
+	suite.refreshTokenRepositoryMock.On("RevokeRefreshTokensByUserId", mock.Anything, mock.Anything).Return(nil)
+
+	user, err := suite.service.ChangePassword(12, "current", "new")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), user.Id)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "UpdateUserPassword", int64(12), "new hash")
+	suite.refreshTokenRepositoryMock.AssertCalled(suite.T(), "RevokeRefreshTokensByUserId", int64(12), mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestChangePasswordInvalidPassword_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "current hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(false)
+
+	_, err := suite.service.ChangePassword(12, "wrong", "new")
+
+	suite.EqualError(err, constants.INVALID_PASSWORD_ERROR)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "UpdateUserPassword", mock.Anything, mock.Anything)
+}
+
+// Users who only logged in through an identity provider have no password to confirm
+func (suite *UserServiceUnitTestSuite) TestChangePasswordWithoutPassword_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12}, nil)
+
+	_, err := suite.service.ChangePassword(12, "", "new")
+
+	suite.EqualError(err, constants.PASSWORD_NOT_SET_ERROR)
+	suite.passwordHasherMock.AssertNotCalled(suite.T(), "ValidatePasswordHash", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestRequestEmailChange_ReturnsTheUser() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.userRepositoryMock.On("GetUserByEmail", "new@test.com").Return(nil, errors.New(constants.NO_USER_FOR_EMAIL_ERROR))
+
+	user, err := suite.service.RequestEmailChange(12, "password", "new@test.com")
+
+	suite.Nil(err)
+	suite.Equal(int64(12), user.Id)
+}
+
+// When another account uses the email, return an error
+func (suite *UserServiceUnitTestSuite) TestRequestEmailChangeEmailInUse_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.userRepositoryMock.On("GetUserByEmail", "new@test.com").Return(&models.User{Id: 13}, nil)
+
+	_, err := suite.service.RequestEmailChange(12, "password", "new@test.com")
+
+	suite.EqualError(err, constants.EMAIL_ALREADY_IN_USE_ERROR)
+}
+
+// By default the account is anonymized rather than deleted
+func (suite *UserServiceUnitTestSuite) TestDeleteAccount_AnonymizesTheUser() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.userRepositoryMock.On("AnonymizeUser", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.DeleteAccount(12, "password")
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "AnonymizeUser", int64(12), "deleted-12@deleted.invalid", mock.Anything)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "DeleteUser", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestDeleteAccountCascadePolicy_DeletesTheUser() {
+
+	suite.service.accountDeletionPolicy = config.CASCADE_ACCOUNT_DELETION
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(true)
+	suite.userRepositoryMock.On("DeleteUser", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.service.DeleteAccount(12, "password")
+
+	suite.Nil(err)
+	suite.userRepositoryMock.AssertCalled(suite.T(), "DeleteUser", int64(12), mock.Anything)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "AnonymizeUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceUnitTestSuite) TestDeleteAccountInvalidPassword_ReturnsAnError() {
+
+	suite.userRepositoryMock.On("GetUserById", int64(12)).Return(&models.User{Id: 12, Password: "hash"}, nil)
+	suite.passwordHasherMock.On("ValidatePasswordHash", mock.Anything, mock.Anything).Return(false)
+
+	err := suite.service.DeleteAccount(12, "wrong")
+
+	suite.EqualError(err, constants.INVALID_PASSWORD_ERROR)
+	suite.userRepositoryMock.AssertNotCalled(suite.T(), "AnonymizeUser", mock.Anything, mock.Anything, mock.Anything)
+}
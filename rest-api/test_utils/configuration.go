@@ -0,0 +1,45 @@
+package test_utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"example.com/config"
+)
+
+// Loads the configuration the way the api does, with the variables set for the duration of the test
+// and an empty .env file
+func LoadConfiguration(t *testing.T, variables map[string]string) {
+	workingDirectory, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configurationDirectory := t.TempDir()
+
+	err = os.WriteFile(filepath.Join(configurationDirectory, ".env"), []byte{}, 0600)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, value := range variables {
+		t.Setenv(name, value)
+	}
+
+	err = os.Chdir(configurationDirectory)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chdir(workingDirectory)
+
+	err = config.LoadConfiguration()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
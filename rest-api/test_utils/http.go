@@ -25,7 +25,7 @@ func GetHttpResponse(responseRecorder *httptest.ResponseRecorder) *HttpResponse
 	data, err := io.ReadAll(result.Body)
 
 	if err != nil {
-		fmt.Sprintf("Unexpected error trying to parse response body: %v\n", err.Error())
+		fmt.Printf("Unexpected error trying to parse response body: %v\n", err.Error())
 		panic("invalid http response")
 	}
 
@@ -8,9 +8,11 @@ import (
 	"example.com/controllers"
 	controllerInterfaces "example.com/interfaces/controllers"
 	libInterfaces "example.com/interfaces/lib"
+	middlewareInterfaces "example.com/interfaces/middlewares"
 	repositoryInterfaces "example.com/interfaces/repositories"
 	serviceInterfaces "example.com/interfaces/services"
 	"example.com/lib"
+	"example.com/middlewares"
 	"example.com/repositories"
 	"example.com/routes"
 	"example.com/services"
@@ -28,11 +30,58 @@ func BuildServer() (*App, error) {
 		wire.Bind(new(repositoryInterfaces.IRegistrationRepository), new(*repositories.RegistrationRepository)),
 		repositories.NewUserRepository,
 		wire.Bind(new(repositoryInterfaces.IUserRepository), new(*repositories.UserRepository)),
+		repositories.NewUserProfileRepository,
+		wire.Bind(new(repositoryInterfaces.IUserProfileRepository), new(*repositories.UserProfileRepository)),
+		repositories.NewInvitationRepository,
+		wire.Bind(new(repositoryInterfaces.IInvitationRepository), new(*repositories.InvitationRepository)),
+		repositories.NewCommentRepository,
+		wire.Bind(new(repositoryInterfaces.ICommentRepository), new(*repositories.CommentRepository)),
+		repositories.NewRefreshTokenRepository,
+		wire.Bind(new(repositoryInterfaces.IRefreshTokenRepository), new(*repositories.RefreshTokenRepository)),
+		repositories.NewRevokedTokenRepository,
+		wire.Bind(new(repositoryInterfaces.IRevokedTokenRepository), new(*repositories.RevokedTokenRepository)),
+		repositories.NewPasswordResetRepository,
+		wire.Bind(new(repositoryInterfaces.IPasswordResetRepository), new(*repositories.PasswordResetRepository)),
+		repositories.NewLoginThrottleRepository,
+		wire.Bind(new(repositoryInterfaces.ILoginThrottleRepository), new(*repositories.LoginThrottleRepository)),
+		repositories.NewTwoFactorRepository,
+		wire.Bind(new(repositoryInterfaces.ITwoFactorRepository), new(*repositories.TwoFactorRepository)),
+		repositories.NewOidcRepository,
+		wire.Bind(new(repositoryInterfaces.IOidcRepository), new(*repositories.OidcRepository)),
+		repositories.NewPersonalAccessTokenRepository,
+		wire.Bind(new(repositoryInterfaces.IPersonalAccessTokenRepository), new(*repositories.PersonalAccessTokenRepository)),
+		repositories.NewDataExportRepository,
+		wire.Bind(new(repositoryInterfaces.IDataExportRepository), new(*repositories.DataExportRepository)),
+		repositories.NewSessionRepository,
+		wire.Bind(new(repositoryInterfaces.ISessionRepository), new(*repositories.SessionRepository)),
+		repositories.NewOrganizationRepository,
+		wire.Bind(new(repositoryInterfaces.IOrganizationRepository), new(*repositories.OrganizationRepository)),
+		repositories.NewAuditLogRepository,
+		wire.Bind(new(repositoryInterfaces.IAuditLogRepository), new(*repositories.AuditLogRepository)),
+		repositories.NewMigrationRepository,
+		wire.Bind(new(repositoryInterfaces.IMigrationRepository), new(*repositories.MigrationRepository)),
 		//util registration
 		lib.NewHasher,
 		wire.Bind(new(libInterfaces.IHasher), new(*lib.Hasher)),
 		lib.NewJwtAuthorizer,
 		wire.Bind(new(libInterfaces.IJwtAuthorizer), new(*lib.JwtAuthorizer)),
+		lib.NewEventBroadcaster,
+		wire.Bind(new(libInterfaces.IEventBroadcaster), new(*lib.EventBroadcaster)),
+		lib.NewEventChannelHub,
+		wire.Bind(new(libInterfaces.IEventChannelHub), new(*lib.EventChannelHub)),
+		lib.NewGeocoder,
+		wire.Bind(new(libInterfaces.IGeocoder), new(*lib.Geocoder)),
+		lib.NewMarkdownSanitizer,
+		wire.Bind(new(libInterfaces.IMarkdownSanitizer), new(*lib.MarkdownSanitizer)),
+		lib.NewLogMailSender,
+		wire.Bind(new(libInterfaces.IMailSender), new(*lib.LogMailSender)),
+		lib.NewVerificationTokenSigner,
+		wire.Bind(new(libInterfaces.IVerificationTokenSigner), new(*lib.VerificationTokenSigner)),
+		lib.NewTotpAuthenticator,
+		wire.Bind(new(libInterfaces.ITotpAuthenticator), new(*lib.TotpAuthenticator)),
+		lib.NewMockOidcProvider,
+		lib.NewOidcAuthenticator,
+		wire.Bind(new(libInterfaces.IOidcAuthenticator), new(*lib.OidcAuthenticator)),
 		//service registration
 		services.NewEventService,
 		wire.Bind(new(serviceInterfaces.IEventService), new(*services.EventService)),
@@ -40,6 +89,40 @@ func BuildServer() (*App, error) {
 		wire.Bind(new(serviceInterfaces.IUserService), new(*services.UserService)),
 		services.NewRegistrationService,
 		wire.Bind(new(serviceInterfaces.IRegistrationService), new(*services.RegistrationService)),
+		services.NewInvitationService,
+		wire.Bind(new(serviceInterfaces.IInvitationService), new(*services.InvitationService)),
+		services.NewCommentService,
+		wire.Bind(new(serviceInterfaces.ICommentService), new(*services.CommentService)),
+		services.NewTokenService,
+		wire.Bind(new(serviceInterfaces.ITokenService), new(*services.TokenService)),
+		services.NewPasswordResetService,
+		wire.Bind(new(serviceInterfaces.IPasswordResetService), new(*services.PasswordResetService)),
+		services.NewEmailVerificationService,
+		wire.Bind(new(serviceInterfaces.IEmailVerificationService), new(*services.EmailVerificationService)),
+		services.NewLoginThrottleService,
+		wire.Bind(new(serviceInterfaces.ILoginThrottleService), new(*services.LoginThrottleService)),
+		services.NewTwoFactorService,
+		wire.Bind(new(serviceInterfaces.ITwoFactorService), new(*services.TwoFactorService)),
+		services.NewOidcService,
+		wire.Bind(new(serviceInterfaces.IOidcService), new(*services.OidcService)),
+		services.NewPersonalAccessTokenService,
+		wire.Bind(new(serviceInterfaces.IPersonalAccessTokenService), new(*services.PersonalAccessTokenService)),
+		services.NewDataExportService,
+		wire.Bind(new(serviceInterfaces.IDataExportService), new(*services.DataExportService)),
+		services.NewOrganizationService,
+		wire.Bind(new(serviceInterfaces.IOrganizationService), new(*services.OrganizationService)),
+		services.NewAuditLogService,
+		wire.Bind(new(serviceInterfaces.IAuditLogService), new(*services.AuditLogService)),
+		config.MigrationFiles,
+		services.NewMigrationService,
+		wire.Bind(new(serviceInterfaces.IMigrationService), new(*services.MigrationService)),
+		//middleware registration
+		middlewares.NewAuthenticator,
+		wire.Bind(new(middlewareInterfaces.IAuthenticator), new(*middlewares.Authenticator)),
+		middlewares.NewVerifiedEmailGuard,
+		wire.Bind(new(middlewareInterfaces.IVerifiedEmailGuard), new(*middlewares.VerifiedEmailGuard)),
+		middlewares.NewAuditTrail,
+		wire.Bind(new(middlewareInterfaces.IAuditTrail), new(*middlewares.AuditTrail)),
 		//controller registration
 		controllers.NewEventsController,
 		wire.Bind(new(controllerInterfaces.IEventsController), new(*controllers.EventsController)),
@@ -47,6 +130,36 @@ func BuildServer() (*App, error) {
 		wire.Bind(new(controllerInterfaces.IUsersController), new(*controllers.UsersController)),
 		controllers.NewRegistrationsController,
 		wire.Bind(new(controllerInterfaces.IRegistrationsController), new(*controllers.RegistrationsController)),
+		controllers.NewEventStreamController,
+		wire.Bind(new(controllerInterfaces.IEventStreamController), new(*controllers.EventStreamController)),
+		controllers.NewEventChannelController,
+		wire.Bind(new(controllerInterfaces.IEventChannelController), new(*controllers.EventChannelController)),
+		controllers.NewInvitationsController,
+		wire.Bind(new(controllerInterfaces.IInvitationsController), new(*controllers.InvitationsController)),
+		controllers.NewCommentsController,
+		wire.Bind(new(controllerInterfaces.ICommentsController), new(*controllers.CommentsController)),
+		controllers.NewAdminController,
+		wire.Bind(new(controllerInterfaces.IAdminController), new(*controllers.AdminController)),
+		controllers.NewPasswordResetController,
+		wire.Bind(new(controllerInterfaces.IPasswordResetController), new(*controllers.PasswordResetController)),
+		controllers.NewEmailVerificationController,
+		wire.Bind(new(controllerInterfaces.IEmailVerificationController), new(*controllers.EmailVerificationController)),
+		controllers.NewTwoFactorController,
+		wire.Bind(new(controllerInterfaces.ITwoFactorController), new(*controllers.TwoFactorController)),
+		controllers.NewOidcController,
+		wire.Bind(new(controllerInterfaces.IOidcController), new(*controllers.OidcController)),
+		controllers.NewPersonalAccessTokensController,
+		wire.Bind(new(controllerInterfaces.IPersonalAccessTokensController), new(*controllers.PersonalAccessTokensController)),
+		controllers.NewDataExportsController,
+		wire.Bind(new(controllerInterfaces.IDataExportsController), new(*controllers.DataExportsController)),
+		controllers.NewJsonWebKeysController,
+		wire.Bind(new(controllerInterfaces.IJsonWebKeysController), new(*controllers.JsonWebKeysController)),
+		controllers.NewSessionsController,
+		wire.Bind(new(controllerInterfaces.ISessionsController), new(*controllers.SessionsController)),
+		controllers.NewOrganizationsController,
+		wire.Bind(new(controllerInterfaces.IOrganizationsController), new(*controllers.OrganizationsController)),
+		controllers.NewAuditLogController,
+		wire.Bind(new(controllerInterfaces.IAuditLogController), new(*controllers.AuditLogController)),
 		routes.NewHttpServer,
 		NewHTTPHandlers,
 		NewApp,
@@ -54,3 +167,27 @@ func BuildServer() (*App, error) {
 
 	return &App{}, nil
 }
+
+func BuildCommands() (*Commands, error) {
+	wire.Build(
+		config.InitializeDatabase,
+		repositories.NewUserRepository,
+		wire.Bind(new(repositoryInterfaces.IUserRepository), new(*repositories.UserRepository)),
+		repositories.NewUserProfileRepository,
+		wire.Bind(new(repositoryInterfaces.IUserProfileRepository), new(*repositories.UserProfileRepository)),
+		repositories.NewRefreshTokenRepository,
+		wire.Bind(new(repositoryInterfaces.IRefreshTokenRepository), new(*repositories.RefreshTokenRepository)),
+		lib.NewHasher,
+		wire.Bind(new(libInterfaces.IHasher), new(*lib.Hasher)),
+		services.NewUserService,
+		wire.Bind(new(serviceInterfaces.IUserService), new(*services.UserService)),
+		repositories.NewMigrationRepository,
+		wire.Bind(new(repositoryInterfaces.IMigrationRepository), new(*repositories.MigrationRepository)),
+		config.MigrationFiles,
+		services.NewMigrationService,
+		wire.Bind(new(serviceInterfaces.IMigrationService), new(*services.MigrationService)),
+		NewCommands,
+	)
+
+	return &Commands{}, nil
+}
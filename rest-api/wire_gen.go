@@ -10,6 +10,7 @@ import (
 	"example.com/config"
 	"example.com/controllers"
 	"example.com/lib"
+	"example.com/middlewares"
 	"example.com/repositories"
 	"example.com/routes"
 	"example.com/services"
@@ -21,17 +22,99 @@ func BuildServer() (*App, error) {
 	engine := routes.NewHttpServer()
 	db := config.InitializeDatabase()
 	eventRepository := repositories.NewEventRepository(db)
-	eventService := services.NewEventService(eventRepository)
+	invitationRepository := repositories.NewInvitationRepository(db)
+	eventBroadcaster := lib.NewEventBroadcaster()
+	geocoder := lib.NewGeocoder()
+	eventService := services.NewEventService(eventRepository, invitationRepository, eventBroadcaster, geocoder)
 	eventsController := controllers.NewEventsController(eventService)
 	userRepository := repositories.NewUserRepository(db)
-	hasher := lib.NewHasher()
-	userService := services.NewUserService(userRepository, hasher)
-	jwtAuthorizer := lib.NewJwtAuthorizer()
-	usersController := controllers.NewUsersController(userService, jwtAuthorizer)
+	userProfileRepository := repositories.NewUserProfileRepository(db)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+	hasher, err := lib.NewHasher()
+	if err != nil {
+		return nil, err
+	}
+	userService := services.NewUserService(userRepository, userProfileRepository, refreshTokenRepository, hasher)
+	revokedTokenRepository := repositories.NewRevokedTokenRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	organizationRepository := repositories.NewOrganizationRepository(db)
+	jwtAuthorizer, err := lib.NewJwtAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	tokenService := services.NewTokenService(refreshTokenRepository, revokedTokenRepository, userRepository, sessionRepository, organizationRepository, jwtAuthorizer)
+	verificationTokenSigner := lib.NewVerificationTokenSigner()
+	logMailSender := lib.NewLogMailSender()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, verificationTokenSigner, logMailSender)
+	loginThrottleRepository := repositories.NewLoginThrottleRepository(db)
+	loginThrottleService := services.NewLoginThrottleService(loginThrottleRepository, userRepository)
+	twoFactorRepository := repositories.NewTwoFactorRepository(db)
+	totpAuthenticator := lib.NewTotpAuthenticator()
+	twoFactorService := services.NewTwoFactorService(twoFactorRepository, userRepository, totpAuthenticator)
+	auditLogRepository := repositories.NewAuditLogRepository(db)
+	auditLogService := services.NewAuditLogService(auditLogRepository)
+	usersController := controllers.NewUsersController(userService, tokenService, emailVerificationService, loginThrottleService, twoFactorService, auditLogService)
 	registrationRepository := repositories.NewRegistrationRepository(db)
-	registrationService := services.NewRegistrationService(registrationRepository, eventRepository)
+	registrationService := services.NewRegistrationService(registrationRepository, eventRepository, invitationRepository, eventBroadcaster)
 	registrationsController := controllers.NewRegistrationsController(registrationService)
-	httpHandlers := NewHTTPHandlers(eventsController, usersController, registrationsController)
-	app := NewApp(engine, httpHandlers)
+	eventStreamController := controllers.NewEventStreamController(eventService, eventBroadcaster)
+	eventChannelHub := lib.NewEventChannelHub()
+	eventChannelController := controllers.NewEventChannelController(eventService, registrationService, tokenService, eventChannelHub, eventBroadcaster)
+	invitationService := services.NewInvitationService(invitationRepository, eventRepository, userRepository)
+	invitationsController := controllers.NewInvitationsController(invitationService)
+	commentRepository := repositories.NewCommentRepository(db)
+	markdownSanitizer := lib.NewMarkdownSanitizer()
+	commentService := services.NewCommentService(commentRepository, eventRepository, markdownSanitizer)
+	commentsController := controllers.NewCommentsController(eventService, commentService)
+	adminController := controllers.NewAdminController(userService, loginThrottleService)
+	passwordResetRepository := repositories.NewPasswordResetRepository(db)
+	passwordResetService := services.NewPasswordResetService(userRepository, passwordResetRepository, refreshTokenRepository, hasher, logMailSender)
+	passwordResetController := controllers.NewPasswordResetController(passwordResetService, auditLogService)
+	emailVerificationController := controllers.NewEmailVerificationController(emailVerificationService)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService, userService, loginThrottleService, auditLogService)
+	oidcRepository := repositories.NewOidcRepository(db)
+	mockOidcProvider, err := lib.NewMockOidcProvider()
+	if err != nil {
+		return nil, err
+	}
+	oidcAuthenticator := lib.NewOidcAuthenticator(mockOidcProvider)
+	oidcService := services.NewOidcService(oidcRepository, userRepository, refreshTokenRepository, oidcAuthenticator)
+	oidcController := controllers.NewOidcController(oidcService, tokenService, twoFactorService, loginThrottleService, auditLogService)
+	personalAccessTokenRepository := repositories.NewPersonalAccessTokenRepository(db)
+	personalAccessTokenService := services.NewPersonalAccessTokenService(personalAccessTokenRepository, userRepository)
+	personalAccessTokensController := controllers.NewPersonalAccessTokensController(personalAccessTokenService, auditLogService)
+	dataExportRepository := repositories.NewDataExportRepository(db)
+	dataExportService := services.NewDataExportService(userRepository, userProfileRepository, twoFactorRepository, eventRepository, commentRepository, refreshTokenRepository, oidcRepository, personalAccessTokenRepository, dataExportRepository, organizationRepository, auditLogRepository)
+	dataExportsController := controllers.NewDataExportsController(dataExportService)
+	jsonWebKeysController := controllers.NewJsonWebKeysController(tokenService)
+	sessionsController := controllers.NewSessionsController(tokenService, auditLogService)
+	organizationService := services.NewOrganizationService(organizationRepository, userRepository)
+	organizationsController := controllers.NewOrganizationsController(organizationService, tokenService, auditLogService)
+	auditLogController := controllers.NewAuditLogController(auditLogService)
+	httpHandlers := NewHTTPHandlers(eventsController, usersController, registrationsController, eventStreamController, eventChannelController, invitationsController, commentsController, adminController, passwordResetController, emailVerificationController, twoFactorController, oidcController, personalAccessTokensController, dataExportsController, jsonWebKeysController, sessionsController, organizationsController, auditLogController)
+	authenticator := middlewares.NewAuthenticator(tokenService, personalAccessTokenService, auditLogService)
+	verifiedEmailGuard := middlewares.NewVerifiedEmailGuard(emailVerificationService)
+	auditTrail := middlewares.NewAuditTrail(auditLogService)
+	migrationRepository := repositories.NewMigrationRepository(db)
+	fs := config.MigrationFiles()
+	migrationService := services.NewMigrationService(migrationRepository, fs)
+	app := NewApp(engine, httpHandlers, authenticator, verifiedEmailGuard, auditTrail, migrationService, mockOidcProvider)
 	return app, nil
 }
+
+func BuildCommands() (*Commands, error) {
+	db := config.InitializeDatabase()
+	userRepository := repositories.NewUserRepository(db)
+	userProfileRepository := repositories.NewUserProfileRepository(db)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+	hasher, err := lib.NewHasher()
+	if err != nil {
+		return nil, err
+	}
+	userService := services.NewUserService(userRepository, userProfileRepository, refreshTokenRepository, hasher)
+	migrationRepository := repositories.NewMigrationRepository(db)
+	fs := config.MigrationFiles()
+	migrationService := services.NewMigrationService(migrationRepository, fs)
+	commands := NewCommands(userService, migrationService)
+	return commands, nil
+}